@@ -14,6 +14,7 @@
 # limitations under the License.
 
 set -eo pipefail  # Exit on error or pipe failure
+script_dir=$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)
 # Initialize default values for the flags/variables
 stage=""
 tfcommand="init"
@@ -26,7 +27,7 @@ NC='\033[0m'
 valid_stages="all organization networking networking/ncc networking/firewallendpoint networking/CloudDNS/DNSManagedZones networking/CloudDNS/CloudDNSResponsePolicy security/firewall/firewallpolicy security/securityprofile security/certificates/compute-ssl-certs/google-managed security/alloydb security/mrc security/cloudsql security/gce security/mig security/workbench producer/alloydb producer/mrc producer/cloudsql producer/gke producer/vectorsearch producer/onlineendpoint producer/bigquery producer-connectivity consumer/gce consumer/serverless/cloudrun/job consumer/serverless/cloudrun/service consumer/serverless/appengine/standard consumer/serverless/appengine/flexible consumer/mig consumer/workbench consumer/umig load-balancing/application/external load-balancing/network/passthrough/internal load-balancing/network/passthrough/external network-security-integration/outofband network-security-integration/securityprofile network-security-integration/packetmirroringrule"
 
 # Define valid Terraform commands to be accepted by the -tf or --tfcommand flag
-valid_tf_commands="init apply apply-auto-approve destroy destroy-auto-approve init-apply init-apply-auto-approve"
+valid_tf_commands="init apply apply-auto-approve destroy destroy-auto-approve init-apply init-apply-auto-approve drift"
 
 # Define stage to path mapping (excluding "all")
 # shellcheck disable=SC2034
@@ -174,6 +175,7 @@ tfcommand_wise_description_map=(
     "destroy-auto-approve=Destroy previously-created infrastructure, skips user input."
     "init-apply=Prepares working directory and creates/updates infrastructure."
     "init-apply-auto-approve=Prepares working directory and creates/updates infrastructure, skips user input."
+    "drift=Reports the resources changed outside of Terraform in stages with state, applies nothing."
 )
 
 # Function to get the value associated with a key present in the *_map variables created
@@ -200,6 +202,186 @@ function check_yaml_exists {
     fi
 }
 
+# Secret references (env:NAME, file:PATH, secretmanager:projects/P/secrets/S)
+# in the stage tfvars or YAML files are resolved by tools/cmd/resolve-secrets
+# right before Terraform runs. The resolved tfvars are passed through a pipe
+# and resolved YAML files live in a private in-memory folder removed on exit,
+# so secret values are never written to disk. Extra schemes are added with
+# SECRET_PROVIDERS="scheme=command;..." and SECRETS_RESOLVER overrides the
+# resolver command (by default "go run" in the tools module).
+secret_schemes="env file secretmanager"
+secret_tfvars=""
+
+# Function to check if the tfvars file or the YAML files of the stage config folder hold secret references.
+function has_secret_references {
+    local tfvars="$1"
+    local schemes pattern config_dir provider
+    local -a providers
+    schemes="${secret_schemes// /|}"
+    IFS=';' read -r -a providers <<< "${SECRET_PROVIDERS:-}"
+    for provider in "${providers[@]}"; do
+        schemes="${schemes}|${provider%%=*}"
+    done
+    # A reference is a value: it follows "=", ":", "-", "[" or "," but not free text such as comments.
+    pattern="(^|[-=:,[])[[:space:]]*[\"']?(${schemes}):[^[:space:]]"
+    grep -qE "$pattern" "$tfvars" 2>/dev/null && return 0
+    config_dir=$(sed -n 's/^[[:space:]]*config_folder_path[[:space:]]*=[[:space:]]*"\(.*\)".*/\1/p' "$tfvars" | head -n 1)
+    [[ -n "$config_dir" ]] && compgen -G "$config_dir/[!_]*.yaml" > /dev/null && grep -qE "$pattern" "$config_dir"/[!_]*.yaml
+}
+
+# Function to resolve the secret references of the stage run from the current directory into secret_tfvars.
+function resolve_secrets {
+    local tfvars="$1"
+    local secrets_dir provider
+    local -a resolver providers provider_args
+    secret_tfvars=""
+    if ! has_secret_references "$tfvars"; then
+        return 0
+    fi
+    if [[ -n "${SECRETS_RESOLVER:-}" ]]; then
+        read -r -a resolver <<< "$SECRETS_RESOLVER"
+    elif command -v go > /dev/null; then
+        resolver=(go -C "$script_dir/tools" run ./cmd/resolve-secrets)
+    else
+        echo -e "${RED}Error: secret references found in $tfvars, Go is required to resolve them${NC}" >&2
+        return 1
+    fi
+    IFS=';' read -r -a providers <<< "${SECRET_PROVIDERS:-}"
+    for provider in "${providers[@]}"; do
+        provider_args+=(-provider "$provider")
+    done
+    if [[ ! -d /dev/shm ]]; then
+        echo -e "${RED}Error: secret references require an in-memory /dev/shm folder${NC}" >&2
+        return 1
+    fi
+    secrets_dir=$(mktemp -d /dev/shm/cncs-secrets.XXXXXX) || return 1
+    # shellcheck disable=SC2064
+    trap "rm -rf '$secrets_dir'" EXIT
+    echo "Resolving secret references of ${tfvars}"
+    secret_tfvars=$("${resolver[@]}" -tfvars "$(realpath "$tfvars")" -stage-dir "$PWD" -yaml-dir "$secrets_dir" "${provider_args[@]}")
+}
+
+# Function to run terraform, passing the resolved secrets as a last -var-file read from a pipe so they take precedence.
+function terraform_with_secrets {
+    if [[ -n "$secret_tfvars" ]]; then
+        terraform "$@" -var-file=<(printf '%s\n' "$secret_tfvars")
+    else
+        terraform "$@"
+    fi
+}
+
+# With POLICY_CHECK=true, apply first writes the plan of the stage and checks
+# its resource changes against the policy rules of tools/cmd/policy-check,
+# and only applies that plan when no rule is violated. POLICY_RULES overrides
+# the rule files (a comma-separated list, tools/policy/rules by default),
+# POLICY_PARAMS sets rule parameters ("environment=prod cost_label=team") and
+# POLICY_CHECKER overrides the checker command. The stage parameter is the
+# stage path. The plan holds the resolved secrets, so it is written to the
+# in-memory /dev/shm folder when there is one, and removed after the apply.
+policy_check="${POLICY_CHECK:-false}"
+
+# Function to check a saved plan of the stage run from the current directory against the policy rules.
+function check_policy {
+    local plan_file="$1"
+    local param
+    local -a checker params
+    if [[ -n "${POLICY_CHECKER:-}" ]]; then
+        read -r -a checker <<< "$POLICY_CHECKER"
+    elif command -v go > /dev/null; then
+        checker=(go -C "$script_dir/tools" run ./cmd/policy-check)
+    else
+        echo -e "${RED}Error: POLICY_CHECK is set, Go is required to check the plan${NC}" >&2
+        return 1
+    fi
+    params=(-param "stage=$stage_path")
+    for param in ${POLICY_PARAMS:-}; do
+        params+=(-param "$param")
+    done
+    echo "Checking the plan against the policy rules"
+    terraform show -json "$plan_file" | "${checker[@]}" -rules "${POLICY_RULES:-$script_dir/tools/policy/rules}" "${params[@]}" -
+}
+
+# Function to run terraform apply, checking the plan against the policy rules first when POLICY_CHECK is true.
+function terraform_apply {
+    local auto_approve=false arg plan_dir status=0 answer
+    local -a args
+    if [[ "$policy_check" != true ]]; then
+        terraform_with_secrets apply "$@"
+        return
+    fi
+    for arg in "$@"; do
+        if [[ "$arg" == "--auto-approve" ]]; then
+            auto_approve=true
+        else
+            args+=("$arg")
+        fi
+    done
+    if [[ -d /dev/shm ]]; then
+        plan_dir=$(mktemp -d /dev/shm/cncs-plan.XXXXXX) || return 1
+    else
+        plan_dir=$(mktemp -d) || return 1
+    fi
+    {
+        terraform_with_secrets plan -input=false -out="$plan_dir/tfplan" "${args[@]}" &&
+        check_policy "$plan_dir/tfplan" &&
+        if [[ "$auto_approve" != true ]]; then
+            read -r -p "Do you want to apply this plan? Please answer y or n. (y/n) " answer
+            [[ "$answer" == [Yy]* ]] || { echo "Apply cancelled."; false; }
+        fi &&
+        terraform apply -input=false "$plan_dir/tfplan"
+    } || status=$?
+    rm -rf "$plan_dir"
+    return "$status"
+}
+
+# The drift command runs a refresh-only plan with -detailed-exitcode in every
+# stage with state, and lists the resources changed outside of Terraform and
+# their changed attributes with tools/cmd/drift-report in DRIFT_REPORT
+# (drift-report.txt in this folder by default), without applying anything.
+# Stages without state or not initialized are skipped. DRIFT_REPORTER
+# overrides the reporter command. Like for the policy check, the plan is
+# written to /dev/shm when there is one and removed after the report.
+# The script exits with 2 when any stage drifted and with 1 on errors, so CI
+# jobs can fail on drift. The stages run in subshells, so they list their
+# drift in the drift_found file.
+drift_report=$(realpath -m "${DRIFT_REPORT:-$script_dir/drift-report.txt}")
+
+# Function to report the drift of the stage run from the current directory.
+function terraform_drift {
+    local plan_dir status=0
+    local -a reporter
+    if [[ -z "$(terraform state list 2>/dev/null)" ]]; then
+        echo "Skipping $stage_path: no state or not initialized."
+        return 0
+    fi
+    if [[ -n "${DRIFT_REPORTER:-}" ]]; then
+        read -r -a reporter <<< "$DRIFT_REPORTER"
+    elif command -v go > /dev/null; then
+        reporter=(go -C "$script_dir/tools" run ./cmd/drift-report)
+    else
+        echo -e "${RED}Error: Go is required to report the drift${NC}" >&2
+        return 1
+    fi
+    if [[ -d /dev/shm ]]; then
+        plan_dir=$(mktemp -d /dev/shm/cncs-plan.XXXXXX) || return 1
+    else
+        plan_dir=$(mktemp -d) || return 1
+    fi
+    # -detailed-exitcode exits with 2 when the refresh found changes.
+    terraform_with_secrets plan -refresh-only -detailed-exitcode -input=false -out="$plan_dir/tfplan" "$@" || status=$?
+    if [[ "$status" -eq 2 ]]; then
+        echo "$stage_path" >> "$drift_found"
+    fi
+    if [[ "$status" -eq 0 || "$status" -eq 2 ]]; then
+        status=0
+        terraform show -json "$plan_dir/tfplan" | "${reporter[@]}" -stage "$stage_path" - | tee -a "$drift_report" || status=1
+    else
+        status=1
+    fi
+    rm -rf "$plan_dir"
+    return "$status"
+}
+
 # Displays the table formatting.
 tableprint() {
     printf "\t\t "
@@ -280,6 +462,13 @@ if [ -z "$stage" ] || [ -z "$tfcommand" ]; then
   exit 1
 fi
 
+# Start a new drift report, the stages append their drift to it
+if [[ $tfcommand == drift ]]; then
+  : > "$drift_report"
+  drift_found=$(mktemp)
+  trap 'rm -f "$drift_found"' EXIT
+fi
+
 # Execute Terraform commands based on the stage and tfcommand
 if [[ $stage == "all" ]]; then
   # Handles execution of all stages one by one when stage="all" is specified.
@@ -331,14 +520,16 @@ if [[ $stage == "all" ]]; then
          tfvar_file_path=$(get_value "$stage_path" "stagewise_tfvar_path_map")
          echo "tfvars file path : ${tfvar_file_path}"
          (cd "$stage_path" &&
+           resolve_secrets "$tfvar_file_path" &&
            case "$tfcommand" in
-               init) terraform init -var-file="$tfvar_file_path" ;;
-               apply) terraform apply -var-file="$tfvar_file_path" ;;
-               apply-auto-approve) terraform apply --auto-approve -var-file="$tfvar_file_path" ;;
-               destroy) terraform destroy -var-file="$tfvar_file_path" ;;
-               destroy-auto-approve) terraform destroy -var-file="$tfvar_file_path" --auto-approve ;;
-               init-apply) terraform init && terraform apply -var-file="$tfvar_file_path" ;;
-               init-apply-auto-approve) terraform init && terraform apply -var-file="$tfvar_file_path" --auto-approve ;;
+               init) terraform_with_secrets init -var-file="$tfvar_file_path" ;;
+               apply) terraform_apply -var-file="$tfvar_file_path" ;;
+               apply-auto-approve) terraform_apply --auto-approve -var-file="$tfvar_file_path" ;;
+               destroy) terraform_with_secrets destroy -var-file="$tfvar_file_path" ;;
+               destroy-auto-approve) terraform_with_secrets destroy -var-file="$tfvar_file_path" --auto-approve ;;
+               init-apply) terraform init && terraform_apply -var-file="$tfvar_file_path" ;;
+               init-apply-auto-approve) terraform init && terraform_apply -var-file="$tfvar_file_path" --auto-approve ;;
+               drift) terraform_drift -var-file="$tfvar_file_path" ;;
                *) echo "${RED}Error: Invalid tfcommand '$tfcommand'${NC}" >&2; exit 1 ;;
            esac)
       fi
@@ -356,16 +547,26 @@ else
   else
     echo "Executing Terraform command(s) in $stage_path..."
     (cd "$stage_path" &&
+      resolve_secrets "$tfvar_file_path" &&
       case "$tfcommand" in
-          init) terraform init -var-file="$tfvar_file_path";;
-          apply) terraform apply -var-file="$tfvar_file_path";;
-          apply-auto-approve) terraform apply -var-file="$tfvar_file_path" --auto-approve ;;
-          destroy) terraform destroy -var-file="$tfvar_file_path";;
-          destroy-auto-approve) terraform destroy -var-file="$tfvar_file_path" --auto-approve;;
-          init-apply) terraform init && terraform apply -var-file="$tfvar_file_path";;
-          init-apply-auto-approve) terraform init && terraform apply -var-file="$tfvar_file_path" --auto-approve ;;
+          init) terraform_with_secrets init -var-file="$tfvar_file_path";;
+          apply) terraform_apply -var-file="$tfvar_file_path";;
+          apply-auto-approve) terraform_apply -var-file="$tfvar_file_path" --auto-approve ;;
+          destroy) terraform_with_secrets destroy -var-file="$tfvar_file_path";;
+          destroy-auto-approve) terraform_with_secrets destroy -var-file="$tfvar_file_path" --auto-approve;;
+          init-apply) terraform init && terraform_apply -var-file="$tfvar_file_path";;
+          init-apply-auto-approve) terraform init && terraform_apply -var-file="$tfvar_file_path" --auto-approve ;;
+          drift) terraform_drift -var-file="$tfvar_file_path" ;;
           *) echo "${RED}Error: Invalid tfcommand '$tfcommand'${NC}" >&2; exit 1 ;;
       esac
     )
   fi
 fi
+
+if [[ $tfcommand == drift ]]; then
+  echo "Drift report written to ${drift_report}"
+  if [[ -s "$drift_found" ]]; then
+    echo -e "${RED}Drift found in: $(paste -sd ' ' "$drift_found")${NC}" >&2
+    exit 2
+  fi
+fi
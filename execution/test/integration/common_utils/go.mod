@@ -2,11 +2,13 @@ module github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_ut
 
 go 1.24.4
 
-require github.com/gruntwork-io/terratest v0.50.0
+require (
+	github.com/gruntwork-io/terratest v0.50.0
+	github.com/stretchr/testify v1.10.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
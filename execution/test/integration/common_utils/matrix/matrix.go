@@ -0,0 +1,374 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package matrix runs an integration test once per cell of a matrix of
+// axes, such as the engine version, PSA or PSC connectivity, the region and
+// HA, declared by the suite in a YAML file:
+//
+//	axes:
+//	  - name: database_version
+//	    values: [POSTGRES_15, MYSQL_8_0]
+//	  - name: ha
+//	    values:
+//	      - value: "false"
+//	        config: {availability_type: ZONAL}
+//	      - value: "true"
+//	        config: {availability_type: REGIONAL}
+//	config:
+//	  edition: ENTERPRISE
+//	exclude:
+//	  - {database_version: MYSQL_8_0, ha: "true"}
+//
+// Every combination of the axis values is a cell, except the ones matching
+// an exclude entry. A cell carries its axis values and its config: the
+// matrix config overlaid with the config of each of its values, which the
+// test decodes into the stage configuration it writes.
+//
+// Run runs one named subtest per cell, such as
+// TestCreateCloudSQL/database_version=POSTGRES_15,ha=false. The
+// TEST_MATRIX_FILTER environment variable selects cells by axis value, e.g.
+// "database_version=POSTGRES_*,ha=false|true". Cells share the fixtures
+// they get from Fixture, which are set up once and torn down after the last
+// cell.
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FilterEnv is the environment variable holding the cell filter of Run.
+const FilterEnv = "TEST_MATRIX_FILTER"
+
+// Value is a value of an axis. In YAML it is either a scalar or a mapping
+// with the value and the config of the cells having it.
+type Value struct {
+	Value  string         `yaml:"value"`
+	Config map[string]any `yaml:"config,omitempty"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (v *Value) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		v.Value = node.Value
+		return nil
+	}
+	type plain Value
+	var p plain
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	*v = Value(p)
+	return nil
+}
+
+// Axis is a dimension of the matrix.
+type Axis struct {
+	Name   string  `yaml:"name"`
+	Values []Value `yaml:"values"`
+}
+
+// Matrix is the set of axes of a suite.
+type Matrix struct {
+	Axes []Axis `yaml:"axes"`
+	// Config is the base config of every cell.
+	Config map[string]any `yaml:"config,omitempty"`
+	// Exclude lists axis values whose combination is not a cell.
+	Exclude []map[string]string `yaml:"exclude,omitempty"`
+}
+
+var nameRE = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Load reads and validates the matrix of a YAML file.
+func Load(file string) (*Matrix, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return m, nil
+}
+
+// Parse decodes and validates a matrix.
+func Parse(data []byte) (*Matrix, error) {
+	var m Matrix
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (m *Matrix) validate() error {
+	if len(m.Axes) == 0 {
+		return errors.New("no axis")
+	}
+	values := map[string]map[string]bool{}
+	for _, a := range m.Axes {
+		if !nameRE.MatchString(a.Name) {
+			return fmt.Errorf("invalid axis name %q: use lowercase letters, digits and underscores", a.Name)
+		}
+		if values[a.Name] != nil {
+			return fmt.Errorf("duplicate axis %s", a.Name)
+		}
+		if len(a.Values) == 0 {
+			return fmt.Errorf("axis %s has no value", a.Name)
+		}
+		values[a.Name] = map[string]bool{}
+		for _, v := range a.Values {
+			if v.Value == "" || strings.ContainsAny(v.Value, ",=/| ") {
+				return fmt.Errorf("axis %s: invalid value %q", a.Name, v.Value)
+			}
+			if values[a.Name][v.Value] {
+				return fmt.Errorf("axis %s: duplicate value %s", a.Name, v.Value)
+			}
+			values[a.Name][v.Value] = true
+		}
+	}
+	for i, ex := range m.Exclude {
+		if len(ex) == 0 {
+			return fmt.Errorf("exclude %d is empty", i)
+		}
+		for axis, v := range ex {
+			if values[axis] == nil {
+				return fmt.Errorf("exclude %d: unknown axis %s", i, axis)
+			}
+			if !values[axis][v] {
+				return fmt.Errorf("exclude %d: axis %s has no value %s", i, axis, v)
+			}
+		}
+	}
+	return nil
+}
+
+// Cell is a combination of one value per axis.
+type Cell struct {
+	axes   []string
+	values map[string]string
+	// Config is the matrix config overlaid with the config of the values
+	// of the cell, in axis order.
+	Config map[string]any
+}
+
+// Get returns the value of the cell for an axis.
+func (c Cell) Get(axis string) string {
+	return c.values[axis]
+}
+
+// Bool returns the value of the cell for an axis as a boolean, false when
+// it is not one.
+func (c Cell) Bool(axis string) bool {
+	b, _ := strconv.ParseBool(c.values[axis])
+	return b
+}
+
+// Name returns the subtest name of the cell, its axis=value pairs in axis
+// order.
+func (c Cell) Name() string {
+	parts := make([]string, len(c.axes))
+	for i, a := range c.axes {
+		parts[i] = a + "=" + c.values[a]
+	}
+	return strings.Join(parts, ",")
+}
+
+// Suffix returns a short suffix derived from the name of the cell, to make
+// the names of the resources of a cell unique.
+func (c Cell) Suffix() string {
+	h := fnv.New32a()
+	h.Write([]byte(c.Name()))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// Decode overlays the config of the cell on v, a struct with yaml tags
+// such as the configuration a test writes for a stage. Keys of the config
+// missing from v are ignored.
+func (c Cell) Decode(v any) error {
+	if len(c.Config) == 0 {
+		return nil
+	}
+	data, err := yaml.Marshal(c.Config)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(data, v)
+}
+
+// Cells returns the cells of the matrix, the first axis varying slowest.
+func (m *Matrix) Cells() []Cell {
+	axes := make([]string, len(m.Axes))
+	for i, a := range m.Axes {
+		axes[i] = a.Name
+	}
+	var cells []Cell
+	var walk func(i int, values map[string]string, config map[string]any)
+	walk = func(i int, values map[string]string, config map[string]any) {
+		if i == len(m.Axes) {
+			c := Cell{axes: axes, values: maps.Clone(values), Config: config}
+			if !m.excluded(c) {
+				cells = append(cells, c)
+			}
+			return
+		}
+		a := m.Axes[i]
+		for _, v := range a.Values {
+			values[a.Name] = v.Value
+			walk(i+1, values, merge(config, v.Config))
+		}
+		delete(values, a.Name)
+	}
+	walk(0, map[string]string{}, merge(nil, m.Config))
+	return cells
+}
+
+func (m *Matrix) excluded(c Cell) bool {
+	for _, ex := range m.Exclude {
+		match := true
+		for axis, v := range ex {
+			if c.values[axis] != v {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// merge returns a copy of base overlaid with over. Nested maps are merged,
+// other values replaced.
+func merge(base, over map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range over {
+		if bm, ok := out[k].(map[string]any); ok {
+			if om, ok := v.(map[string]any); ok {
+				out[k] = merge(bm, om)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Filter selects cells by axis value: a cell matches when, for every axis
+// of the filter, its value matches one of the path.Match patterns.
+type Filter map[string][]string
+
+// ParseFilter parses a filter such as "region=us-central1,ha=false|true".
+// An empty string is an empty filter, which every cell matches.
+func ParseFilter(s string) (Filter, error) {
+	f := Filter{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		axis, patterns, ok := strings.Cut(part, "=")
+		if !ok || axis == "" || patterns == "" {
+			return nil, fmt.Errorf("invalid filter %q, want axis=value", part)
+		}
+		for _, p := range strings.Split(patterns, "|") {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q of axis %s: %w", p, axis, err)
+			}
+			f[axis] = append(f[axis], p)
+		}
+	}
+	return f, nil
+}
+
+// Match reports whether the cell matches the filter.
+func (f Filter) Match(c Cell) bool {
+	for axis, patterns := range f {
+		matched := false
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, c.values[axis]); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Select returns the cells of the matrix matching the filter, or an error
+// when the filter names an axis the matrix does not have.
+func (m *Matrix) Select(f Filter) ([]Cell, error) {
+	for axis := range f {
+		known := false
+		for _, a := range m.Axes {
+			known = known || a.Name == axis
+		}
+		if !known {
+			return nil, fmt.Errorf("filter on unknown axis %s", axis)
+		}
+	}
+	var out []Cell
+	for _, c := range m.Cells() {
+		if f.Match(c) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// Run runs fn in one subtest per cell selected by FilterEnv. The cells
+// share s, whose fixtures are torn down once every cell has run. fn may
+// call t.Parallel when the cells do not share Terraform state.
+func (m *Matrix) Run(t *testing.T, fn func(t *testing.T, c Cell, s *Shared)) {
+	t.Helper()
+	f, err := ParseFilter(os.Getenv(FilterEnv))
+	if err != nil {
+		t.Fatalf("Invalid %s: %v", FilterEnv, err)
+	}
+	cells, err := m.Select(f)
+	if err != nil {
+		t.Fatalf("Invalid %s: %v", FilterEnv, err)
+	}
+	if len(cells) == 0 {
+		t.Skipf("No cell of the matrix matches %s=%q", FilterEnv, os.Getenv(FilterEnv))
+	}
+	s := NewShared(t)
+	for _, c := range cells {
+		t.Run(c.Name(), func(t *testing.T) {
+			fn(t, c, s)
+		})
+	}
+}
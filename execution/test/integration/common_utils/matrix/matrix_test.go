@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testMatrix = `
+axes:
+  - name: database_version
+    values: [POSTGRES_15, MYSQL_8_0]
+  - name: connectivity
+    values:
+      - value: psa
+        config:
+          network_config: {connectivity: {psa_config: {private_network: vpc}}}
+      - value: psc
+        config:
+          network_config: {connectivity: {psc_allowed_consumer_projects: [p]}}
+  - name: ha
+    values:
+      - value: "false"
+      - value: "true"
+        config: {availability_type: REGIONAL}
+config:
+  availability_type: ZONAL
+  network_config: {connectivity: {public_ipv4: false}}
+exclude:
+  - {database_version: MYSQL_8_0, connectivity: psc}
+`
+
+func TestCells(t *testing.T) {
+	m, err := Parse([]byte(testMatrix))
+	if err != nil {
+		t.Fatalf("Failed to parse matrix: %v", err)
+	}
+	var names []string
+	for _, c := range m.Cells() {
+		names = append(names, c.Name())
+	}
+	want := []string{
+		"database_version=POSTGRES_15,connectivity=psa,ha=false",
+		"database_version=POSTGRES_15,connectivity=psa,ha=true",
+		"database_version=POSTGRES_15,connectivity=psc,ha=false",
+		"database_version=POSTGRES_15,connectivity=psc,ha=true",
+		"database_version=MYSQL_8_0,connectivity=psa,ha=false",
+		"database_version=MYSQL_8_0,connectivity=psa,ha=true",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Cells() = %q, want %q", names, want)
+	}
+
+	c := m.Cells()[3]
+	if c.Get("database_version") != "POSTGRES_15" || c.Get("connectivity") != "psc" || !c.Bool("ha") || c.Bool("connectivity") {
+		t.Errorf("Cell %s has values %v", c.Name(), c.values)
+	}
+	wantConfig := map[string]any{
+		"availability_type": "REGIONAL",
+		"network_config": map[string]any{"connectivity": map[string]any{
+			"public_ipv4":                   false,
+			"psc_allowed_consumer_projects": []any{"p"},
+		}},
+	}
+	if !reflect.DeepEqual(c.Config, wantConfig) {
+		t.Errorf("Config of %s = %v, want %v", c.Name(), c.Config, wantConfig)
+	}
+	if base := m.Cells()[0].Config["availability_type"]; base != "ZONAL" {
+		t.Errorf("Config of the first cell has availability_type %v, want ZONAL", base)
+	}
+
+	type connectivity struct {
+		PublicIPV4 bool     `yaml:"public_ipv4"`
+		PSC        []string `yaml:"psc_allowed_consumer_projects,omitempty"`
+	}
+	type instance struct {
+		Name             string `yaml:"name"`
+		AvailabilityType string `yaml:"availability_type"`
+		NetworkConfig    struct {
+			Connectivity connectivity `yaml:"connectivity"`
+		} `yaml:"network_config"`
+	}
+	got := instance{Name: "sql-1"}
+	got.NetworkConfig.Connectivity.PublicIPV4 = true
+	if err := c.Decode(&got); err != nil {
+		t.Fatalf("Failed to decode config: %v", err)
+	}
+	wantInstance := instance{Name: "sql-1", AvailabilityType: "REGIONAL"}
+	wantInstance.NetworkConfig.Connectivity = connectivity{PublicIPV4: false, PSC: []string{"p"}}
+	if !reflect.DeepEqual(got, wantInstance) {
+		t.Errorf("Decode() = %+v, want %+v", got, wantInstance)
+	}
+
+	suffixes := map[string]bool{}
+	for _, c := range m.Cells() {
+		if len(c.Suffix()) != 8 || suffixes[c.Suffix()] {
+			t.Errorf("Suffix of %s = %q, want 8 unique characters", c.Name(), c.Suffix())
+		}
+		suffixes[c.Suffix()] = true
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"no axis", "axes: []", "no axis"},
+		{"unknown field", "axis: []", "field axis not found"},
+		{"axis name", "axes: [{name: Region, values: [a]}]", `invalid axis name "Region"`},
+		{"duplicate axis", "axes: [{name: a, values: [x]}, {name: a, values: [y]}]", "duplicate axis a"},
+		{"no value", "axes: [{name: a}]", "axis a has no value"},
+		{"bad value", "axes: [{name: a, values: [x=y]}]", `axis a: invalid value "x=y"`},
+		{"duplicate value", "axes: [{name: a, values: [x, x]}]", "axis a: duplicate value x"},
+		{"exclude axis", "axes: [{name: a, values: [x]}]\nexclude: [{b: x}]", "exclude 0: unknown axis b"},
+		{"exclude value", "axes: [{name: a, values: [x]}]\nexclude: [{a: y}]", "exclude 0: axis a has no value y"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.yaml))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Parse() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	m, err := Parse([]byte(testMatrix))
+	if err != nil {
+		t.Fatalf("Failed to parse matrix: %v", err)
+	}
+	tests := []struct {
+		filter string
+		want   int
+		err    string
+	}{
+		{filter: "", want: 6},
+		{filter: "connectivity=psc", want: 2},
+		{filter: "database_version=POSTGRES_*, ha=true", want: 2},
+		{filter: "database_version=MYSQL_8_0|POSTGRES_15,connectivity=psa", want: 4},
+		{filter: "ha=maybe", want: 0},
+		{filter: "region=us-central1", err: "filter on unknown axis region"},
+		{filter: "ha", err: `invalid filter "ha", want axis=value`},
+		{filter: "ha=[", err: `invalid pattern "[" of axis ha`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.filter, func(t *testing.T) {
+			f, err := ParseFilter(tc.filter)
+			var cells []Cell
+			if err == nil {
+				cells, err = m.Select(f)
+			}
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("Select(%q) error = %v, want %q", tc.filter, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to select cells: %v", err)
+			}
+			if len(cells) != tc.want {
+				t.Errorf("Select(%q) returned %d cells, want %d", tc.filter, len(cells), tc.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	m, err := Parse([]byte(testMatrix))
+	if err != nil {
+		t.Fatalf("Failed to parse matrix: %v", err)
+	}
+	t.Setenv(FilterEnv, "database_version=POSTGRES_15")
+
+	var mu sync.Mutex
+	var ran, events []string
+	setups := 0
+	t.Run("suite", func(t *testing.T) {
+		m.Run(t, func(t *testing.T, c Cell, s *Shared) {
+			t.Parallel()
+			vpc, err := Fixture(s, "vpc", func() (string, func(*testing.T), error) {
+				mu.Lock()
+				defer mu.Unlock()
+				setups++
+				events = append(events, "create vpc")
+				return "vpc-1", func(*testing.T) { events = append(events, "delete vpc") }, nil
+			})
+			if err != nil || vpc != "vpc-1" {
+				t.Errorf("Fixture(vpc) = %q, %v", vpc, err)
+			}
+			psa, err := Fixture(s, "psa-"+vpc, func() (int, func(*testing.T), error) {
+				mu.Lock()
+				defer mu.Unlock()
+				setups++
+				events = append(events, "create psa")
+				return 24, func(*testing.T) { events = append(events, "delete psa") }, nil
+			})
+			if err != nil || psa != 24 {
+				t.Errorf("Fixture(psa) = %d, %v", psa, err)
+			}
+			mu.Lock()
+			ran = append(ran, c.Name())
+			mu.Unlock()
+		})
+	})
+
+	if len(ran) != 4 {
+		t.Errorf("Run() ran %d cells, want 4: %q", len(ran), ran)
+	}
+	for _, name := range ran {
+		if !strings.HasPrefix(name, "database_version=POSTGRES_15,") {
+			t.Errorf("Run() ran filtered out cell %s", name)
+		}
+	}
+	want := []string{"create vpc", "create psa", "delete psa", "delete vpc"}
+	if setups != 2 || !reflect.DeepEqual(events, want) {
+		t.Errorf("Fixture events = %q, want %q", events, want)
+	}
+}
+
+func TestFixtureError(t *testing.T) {
+	s := NewShared(t)
+	calls := 0
+	setup := func() (string, func(*testing.T), error) {
+		calls++
+		return "", nil, errors.New("quota exceeded")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Fixture(s, "vpc", setup); err == nil || err.Error() != "fixture vpc: quota exceeded" {
+			t.Errorf("Fixture() error = %v, want the setup error", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Setup called %d times, want 1", calls)
+	}
+	if _, err := Fixture(s, "vpc", func() (int, func(*testing.T), error) { return 0, nil, nil }); err == nil {
+		t.Errorf("Fixture() of a failed fixture returned no error")
+	}
+
+	if _, err := Fixture(s, "name", func() (string, func(*testing.T), error) { return "n", nil, nil }); err != nil {
+		t.Fatalf("Failed to set up fixture: %v", err)
+	}
+	if _, err := Fixture(s, "name", func() (int, func(*testing.T), error) { return 0, nil, nil }); err == nil || err.Error() != "fixture name is a string, not a int" {
+		t.Errorf("Fixture() with another type returned error %v", err)
+	}
+}
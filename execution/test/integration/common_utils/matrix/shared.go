@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Shared holds the fixtures shared by the cells of a matrix, such as a VPC
+// with a PSA range, which are too slow to create for every cell.
+type Shared struct {
+	t        *testing.T
+	mu       sync.Mutex
+	fixtures map[string]*fixture
+}
+
+type fixture struct {
+	once  sync.Once
+	value any
+	err   error
+}
+
+// NewShared returns the fixtures of the cells run as subtests of t. The
+// fixtures are torn down when t completes, after its subtests.
+func NewShared(t *testing.T) *Shared {
+	return &Shared{t: t, fixtures: map[string]*fixture{}}
+}
+
+// Fixture returns the fixture stored under key, calling setup the first
+// time the key is used. setup returns the fixture, a teardown function,
+// which may be nil, and an error which every cell using the key then gets.
+// The teardown outlives the cell which set the fixture up, so it gets the
+// test running the cells, for helpers such as common_utils.DeleteVPCSubnets.
+// Keys derived from axis values, such as "vpc-" + c.Get("region"), share a
+// fixture between the cells having these values. Teardowns run in reverse
+// order of setup. Fixture is safe for use by parallel cells.
+func Fixture[T any](s *Shared, key string, setup func() (T, func(t *testing.T), error)) (T, error) {
+	s.mu.Lock()
+	f := s.fixtures[key]
+	if f == nil {
+		f = &fixture{}
+		s.fixtures[key] = f
+	}
+	s.mu.Unlock()
+
+	f.once.Do(func() {
+		// A setup failing its cell with t.FailNow does not return.
+		f.err = errors.New("setup did not complete")
+		v, teardown, err := setup()
+		if teardown != nil {
+			s.t.Cleanup(func() { teardown(s.t) })
+		}
+		f.value, f.err = v, err
+	})
+	var zero T
+	if f.err != nil {
+		return zero, fmt.Errorf("fixture %s: %w", key, f.err)
+	}
+	v, ok := f.value.(T)
+	if !ok {
+		return zero, fmt.Errorf("fixture %s is a %T, not a %T", key, f.value, zero)
+	}
+	return v, nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy checks the plan of a test against the organization
+// guardrails, the policy rules of the policy-check command of
+// execution/tools, so that a test fails on a resource change run.sh would
+// refuse to apply with POLICY_CHECK=true:
+//
+//	planJSON := terraform.InitAndPlanAndShow(t, terraformOptions)
+//	policy.Check(t, planJSON, policy.Options{
+//		Params: map[string]string{"stage": "04-producer/CloudSQL", "environment": "prod"},
+//	})
+//
+// The rules are evaluated by the command, run with go run from the tools
+// module, or by the command of CheckerEnv.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// CheckerEnv is the environment variable overriding the policy-check command,
+// e.g. the path of a prebuilt binary, to avoid building it in every test
+// package.
+const CheckerEnv = "TEST_POLICY_CHECKER"
+
+var severities = []string{"info", "warning", "error"}
+
+// Options select the rules a plan is checked against.
+type Options struct {
+	// Rules are the rule files or directories of rule files, the starter
+	// rule pack of execution/tools/policy/rules by default.
+	Rules []string
+	// RuleIDs are the ids of the rules to check, every rule by default.
+	RuleIDs []string
+	// Params are the policy parameters, such as stage or environment.
+	Params map[string]string
+	// FailOn is the severity of the violations failing the test, info,
+	// warning or error. Error by default.
+	FailOn string
+}
+
+// Violation is a resource change violating a rule.
+type Violation struct {
+	Severity string `json:"severity"`
+	// Check is the id of the rule.
+	Check string `json:"check"`
+	// Resource is the address of the resource.
+	Resource string `json:"resource"`
+	Message  string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", strings.ToUpper(v.Severity), v.Resource, v.Message, v.Check)
+}
+
+// Check checks the plan, the output of terraform show -json, and fails the
+// test with the violations at least as severe as opts.FailOn.
+func Check(t testing.TB, planJSON string, opts Options) {
+	t.Helper()
+	threshold := rank(opts.FailOn)
+	if opts.FailOn == "" {
+		threshold = rank("error")
+	}
+	if threshold < 0 {
+		t.Fatalf("Failed to check the plan: unknown severity %q", opts.FailOn)
+	}
+	violations, err := Evaluate(planJSON, opts)
+	if err != nil {
+		t.Fatalf("Failed to check the plan: %v", err)
+	}
+	for _, v := range violations {
+		if rank(v.Severity) >= threshold {
+			t.Errorf("Policy violation: %s", v)
+		}
+	}
+}
+
+// Evaluate checks the plan, the output of terraform show -json, and returns
+// every violation.
+func Evaluate(planJSON string, opts Options) ([]Violation, error) {
+	command, err := checker()
+	if err != nil {
+		return nil, err
+	}
+	rules := opts.Rules
+	if len(rules) == 0 {
+		tools, err := toolsDir()
+		if err != nil {
+			return nil, err
+		}
+		rules = []string{filepath.Join(tools, "policy", "rules")}
+	}
+	args := append(append([]string{}, command[1:]...), "-format", "json", "-fail-on", "error", "-rules", strings.Join(rules, ","))
+	if len(opts.RuleIDs) > 0 {
+		args = append(args, "-rule", strings.Join(opts.RuleIDs, ","))
+	}
+	var names []string
+	for name := range opts.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-param", name+"="+opts.Params[name])
+	}
+	args = append(args, "-")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command[0], args...)
+	cmd.Stdin = strings.NewReader(planJSON)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// The command exits with 1 when a rule is violated.
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, fmt.Errorf("%s: %w: %s", strings.Join(command, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	var out struct {
+		Findings []Violation `json:"findings"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, fmt.Errorf("decoding the output of %s: %w", strings.Join(command, " "), err)
+	}
+	return out.Findings, nil
+}
+
+// checker returns the policy-check command: the one of CheckerEnv, or go
+// run in the tools module.
+func checker() ([]string, error) {
+	if c := strings.Fields(os.Getenv(CheckerEnv)); len(c) > 0 {
+		return c, nil
+	}
+	tools, err := toolsDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{"go", "-C", tools, "run", "./cmd/policy-check"}, nil
+}
+
+// toolsDir returns the tools directory of the closest execution directory
+// holding the working directory of the test.
+func toolsDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for dir := wd; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == "execution" {
+			return filepath.Join(dir, "tools"), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not under an execution directory, set %s and Options.Rules", wd, CheckerEnv)
+}
+
+// rank returns the rank of a severity name, -1 when unknown.
+func rank(severity string) int {
+	for i, s := range severities {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return -1
+}
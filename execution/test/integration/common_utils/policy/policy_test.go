@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const findings = `{
+  "findings": [
+    {"severity": "error", "check": "cloudsql-no-public-ipv4", "resource": "module.cloudsql.google_sql_database_instance.primary", "message": "public IPv4"},
+    {"severity": "warning", "check": "cost-center-label", "resource": "google_compute_instance.vm", "message": "no cost center label"}
+  ]
+}`
+
+// fakeChecker writes a policy-check command recording its arguments and
+// standard input into dir, printing output and exiting with code, and sets
+// CheckerEnv to it.
+func fakeChecker(t *testing.T, output string, code int) string {
+	t.Helper()
+	dir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %s/args\ncat > %s/stdin\ncat <<'EOF'\n%s\nEOF\nexit %d\n", dir, dir, output, code)
+	if err := os.WriteFile(filepath.Join(dir, "checker"), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write the fake checker: %v", err)
+	}
+	t.Setenv(CheckerEnv, filepath.Join(dir, "checker"))
+	return dir
+}
+
+func TestEvaluate(t *testing.T) {
+	dir := fakeChecker(t, findings, 1)
+	violations, err := Evaluate(`{"format_version":"1.2"}`, Options{
+		Rules:   []string{"a.yaml", "b"},
+		RuleIDs: []string{"cloudsql-no-public-ipv4", "cost-center-label"},
+		Params:  map[string]string{"stage": "04-producer/CloudSQL", "environment": "prod"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to evaluate the plan: %v", err)
+	}
+	want := []Violation{
+		{Severity: "error", Check: "cloudsql-no-public-ipv4", Resource: "module.cloudsql.google_sql_database_instance.primary", Message: "public IPv4"},
+		{Severity: "warning", Check: "cost-center-label", Resource: "google_compute_instance.vm", Message: "no cost center label"},
+	}
+	if !reflect.DeepEqual(violations, want) {
+		t.Errorf("Failed to evaluate the plan: got %+v, want %+v", violations, want)
+	}
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatalf("Failed to read the checker arguments: %v", err)
+	}
+	wantArgs := "-format json -fail-on error -rules a.yaml,b -rule cloudsql-no-public-ipv4,cost-center-label -param environment=prod -param stage=04-producer/CloudSQL -\n"
+	if string(args) != wantArgs {
+		t.Errorf("Failed to run the checker: got arguments %q, want %q", args, wantArgs)
+	}
+	if stdin, _ := os.ReadFile(filepath.Join(dir, "stdin")); string(stdin) != `{"format_version":"1.2"}` {
+		t.Errorf("Failed to pass the plan: got %q", stdin)
+	}
+}
+
+func TestEvaluateDefaultRules(t *testing.T) {
+	dir := fakeChecker(t, `{"findings": []}`, 0)
+	violations, err := Evaluate(`{}`, Options{})
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("Failed to evaluate the plan: got %v, %v", violations, err)
+	}
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatalf("Failed to read the checker arguments: %v", err)
+	}
+	wd, _ := os.Getwd()
+	rules := filepath.Join(wd, "..", "..", "..", "..", "tools", "policy", "rules")
+	if want := "-rules " + filepath.Clean(rules) + " -"; !strings.Contains(string(args), want) {
+		t.Errorf("Failed to default the rules: got arguments %q, want %q", args, want)
+	}
+}
+
+func TestEvaluateError(t *testing.T) {
+	fakeChecker(t, "", 2)
+	if _, err := Evaluate(`{}`, Options{Rules: []string{"rules"}}); err == nil || !strings.Contains(err.Error(), "exit status 2") {
+		t.Errorf("Failed to report the checker error: got %v", err)
+	}
+	fakeChecker(t, "not json", 0)
+	if _, err := Evaluate(`{}`, Options{Rules: []string{"rules"}}); err == nil || !strings.Contains(err.Error(), "decoding the output") {
+		t.Errorf("Failed to report the invalid output: got %v", err)
+	}
+}
+
+// recorder records the errors of a test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheck(t *testing.T) {
+	fakeChecker(t, findings, 1)
+	tests := []struct {
+		failOn string
+		want   []string
+	}{
+		{
+			want: []string{"Policy violation: ERROR: module.cloudsql.google_sql_database_instance.primary: public IPv4 [cloudsql-no-public-ipv4]"},
+		},
+		{
+			failOn: "warning",
+			want: []string{
+				"Policy violation: ERROR: module.cloudsql.google_sql_database_instance.primary: public IPv4 [cloudsql-no-public-ipv4]",
+				"Policy violation: WARNING: google_compute_instance.vm: no cost center label [cost-center-label]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("fail on "+tt.failOn, func(t *testing.T) {
+			r := &recorder{TB: t}
+			Check(r, `{}`, Options{Rules: []string{"rules"}, FailOn: tt.failOn})
+			if !reflect.DeepEqual(r.errors, tt.want) {
+				t.Errorf("Failed to check the plan: got %q, want %q", r.errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package probe checks network connectivity from inside a test VM.
+//
+// A test declares typed probes (TCP connect, HTTP expect-body, DNS resolve,
+// TLS handshake and SQL ping), renders them into a bash startup script with
+// Script.Render and creates a VM with it, for example with
+// common_utils.CreateGCEInstance. The script runs each probe, with retries,
+// and prints one result marker per probe:
+//
+//	PROBE_RESULT {"name":"lb-http","kind":"http","ok":true,"attempts":2,"detail":"HTTP 200"}
+//
+// followed by a PROBE_DONE marker. The startup script output reaches serial
+// port 1, and the script can also upload the markers to a GCS object. Parse
+// reads the markers back from the output of common_utils.GetSerialPortOutput
+// or from the object content; FromSerialPort, FromGCS and Await fetch and
+// poll them through a command.Runner, which tests replace to run offline.
+package probe
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a probe.
+type Kind string
+
+// Probe kinds.
+const (
+	KindTCP  Kind = "tcp"
+	KindHTTP Kind = "http"
+	KindDNS  Kind = "dns"
+	KindTLS  Kind = "tls"
+	KindSQL  Kind = "sql"
+)
+
+// SQL engines of a SQLPing probe.
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+)
+
+// SelfIP is replaced in the Body of an HTTPExpect probe with the primary
+// internal IP address of the VM, as read from the metadata server. It lets
+// a probe check a backend that echoes the client address.
+const SelfIP = "${PROBE_SELF_IP}"
+
+// DefaultTimeout is the timeout of a single probe attempt when the probe
+// does not set one.
+const DefaultTimeout = 10 * time.Second
+
+// Probe is a connectivity check run inside the VM.
+type Probe interface {
+	// ProbeName returns the name the result of the probe is reported under.
+	ProbeName() string
+	// Kind returns the type of the probe.
+	Kind() Kind
+	// validate reports an invalid probe before it is rendered.
+	validate() error
+	// command returns the body of the bash function running one attempt of
+	// the probe. The function prints a detail line and returns non-zero
+	// when the probe fails.
+	command() string
+	// tools returns the commands the probe needs, with the Debian package
+	// installing each.
+	tools() []tool
+}
+
+// tool is a command a probe needs and the package that installs it.
+type tool struct {
+	command, pkg string
+}
+
+// TCPConnect opens a TCP connection to Host:Port.
+type TCPConnect struct {
+	Name    string
+	Host    string
+	Port    int
+	Timeout time.Duration
+}
+
+// HTTPExpect fetches URL and checks the status code and, when Body is set,
+// that the response body contains Body. Status defaults to 200.
+type HTTPExpect struct {
+	Name    string
+	URL     string
+	Status  int
+	Body    string
+	Timeout time.Duration
+}
+
+// DNSResolve resolves Host with the resolver of the VM, or with Server when
+// set, and checks that the answer holds every address of Want. With no Want
+// the probe only checks that the name resolves.
+type DNSResolve struct {
+	Name    string
+	Host    string
+	Server  string
+	Want    []string
+	Timeout time.Duration
+}
+
+// TLSHandshake completes a TLS handshake with Host:Port, sending ServerName,
+// or Host when empty, as SNI. With Verify the server certificate must be
+// valid for the trust store of the VM.
+type TLSHandshake struct {
+	Name       string
+	Host       string
+	Port       int
+	ServerName string
+	Verify     bool
+	Timeout    time.Duration
+}
+
+// SQLPing checks that a MySQL or PostgreSQL server answers on Host:Port.
+// The probe does not authenticate: a server rejecting the user still
+// answers, so no password ends up in the startup script. Port defaults to
+// the default port of the engine.
+type SQLPing struct {
+	Name     string
+	Engine   string
+	Host     string
+	Port     int
+	User     string
+	Database string
+	Timeout  time.Duration
+}
+
+func (p TCPConnect) ProbeName() string   { return p.Name }
+func (p HTTPExpect) ProbeName() string   { return p.Name }
+func (p DNSResolve) ProbeName() string   { return p.Name }
+func (p TLSHandshake) ProbeName() string { return p.Name }
+func (p SQLPing) ProbeName() string      { return p.Name }
+
+func (TCPConnect) Kind() Kind   { return KindTCP }
+func (HTTPExpect) Kind() Kind   { return KindHTTP }
+func (DNSResolve) Kind() Kind   { return KindDNS }
+func (TLSHandshake) Kind() Kind { return KindTLS }
+func (SQLPing) Kind() Kind      { return KindSQL }
+
+var (
+	nameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	hostRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.:-]*$`)
+)
+
+func checkName(name string) error {
+	if !nameRE.MatchString(name) {
+		return fmt.Errorf("invalid probe name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+func checkHost(name, host string) error {
+	if !hostRE.MatchString(host) {
+		return fmt.Errorf("probe %s: invalid host %q", name, host)
+	}
+	return nil
+}
+
+func checkPort(name string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("probe %s: port %d out of range 1-65535", name, port)
+	}
+	return nil
+}
+
+func (p TCPConnect) validate() error {
+	if err := checkName(p.Name); err != nil {
+		return err
+	}
+	if err := checkHost(p.Name, p.Host); err != nil {
+		return err
+	}
+	return checkPort(p.Name, p.Port)
+}
+
+func (p HTTPExpect) validate() error {
+	if err := checkName(p.Name); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(p.URL, "http://") && !strings.HasPrefix(p.URL, "https://") {
+		return fmt.Errorf("probe %s: URL %q is not an http or https URL", p.Name, p.URL)
+	}
+	if p.Status != 0 && (p.Status < 100 || p.Status > 599) {
+		return fmt.Errorf("probe %s: invalid status %d", p.Name, p.Status)
+	}
+	return nil
+}
+
+func (p DNSResolve) validate() error {
+	if err := checkName(p.Name); err != nil {
+		return err
+	}
+	if err := checkHost(p.Name, p.Host); err != nil {
+		return err
+	}
+	if p.Server != "" {
+		return checkHost(p.Name, p.Server)
+	}
+	return nil
+}
+
+func (p TLSHandshake) validate() error {
+	if err := checkName(p.Name); err != nil {
+		return err
+	}
+	if err := checkHost(p.Name, p.Host); err != nil {
+		return err
+	}
+	if p.ServerName != "" {
+		if err := checkHost(p.Name, p.ServerName); err != nil {
+			return err
+		}
+	}
+	return checkPort(p.Name, p.Port)
+}
+
+func (p SQLPing) validate() error {
+	if err := checkName(p.Name); err != nil {
+		return err
+	}
+	if p.Engine != MySQL && p.Engine != Postgres {
+		return fmt.Errorf("probe %s: unknown engine %q, want %s or %s", p.Name, p.Engine, MySQL, Postgres)
+	}
+	if err := checkHost(p.Name, p.Host); err != nil {
+		return err
+	}
+	if p.Port != 0 {
+		return checkPort(p.Name, p.Port)
+	}
+	return nil
+}
+
+// seconds returns a timeout in whole seconds, at least one.
+func seconds(d time.Duration) string {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	s := int((d + time.Second - 1) / time.Second)
+	return strconv.Itoa(s)
+}
+
+func (p TCPConnect) command() string {
+	addr := fmt.Sprintf("%s:%d", p.Host, p.Port)
+	return fmt.Sprintf(`  if timeout %s bash -c 'exec 3<>"/dev/tcp/$0/$1"' %s %d 2>/dev/null; then
+    echo %s
+  else
+    echo %s
+    return 1
+  fi
+`, seconds(p.Timeout), quote(p.Host), p.Port, quote("connected to "+addr), quote("cannot connect to "+addr))
+}
+
+func (p HTTPExpect) command() string {
+	status := p.Status
+	if status == 0 {
+		status = 200
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `  local out code body
+  out=$(curl -sS -k -m %s -w '\n%%{http_code}' %s 2>&1) || { echo "$out"; return 1; }
+  code=${out##*$'\n'}
+  body=${out%%$'\n'*}
+  if [ "$code" != %d ]; then
+    echo "HTTP $code, want %d"
+    return 1
+  fi
+`, seconds(p.Timeout), quote(p.URL), status, status)
+	if p.Body != "" {
+		fmt.Fprintf(&b, `  local want=%s
+  case "$body" in
+    *"$want"*) ;;
+    *) echo "HTTP $code, body does not contain $want: $body"; return 1 ;;
+  esac
+`, expand(p.Body))
+	}
+	b.WriteString("  echo \"HTTP $code\"\n")
+	return b.String()
+}
+
+func (p DNSResolve) command() string {
+	var b strings.Builder
+	b.WriteString("  local addrs\n")
+	if p.Server != "" {
+		fmt.Fprintf(&b, "  addrs=$(dig +short +time=%s +tries=1 @%s %s 2>&1) || { echo \"$addrs\"; return 1; }\n",
+			seconds(p.Timeout), quote(p.Server), quote(p.Host))
+	} else {
+		fmt.Fprintf(&b, "  addrs=$(timeout %s getent ahosts %s | awk '{print $1}' | sort -u)\n",
+			seconds(p.Timeout), quote(p.Host))
+	}
+	fmt.Fprintf(&b, `  addrs=$(echo $addrs)
+  if [ -z "$addrs" ]; then
+    echo %s
+    return 1
+  fi
+`, quote(p.Host+" does not resolve"))
+	for _, w := range p.Want {
+		fmt.Fprintf(&b, `  case " $addrs " in
+    *" "%s" "*) ;;
+    *) echo "%s resolves to $addrs, missing %s"; return 1 ;;
+  esac
+`, quote(w), p.Host, w)
+	}
+	fmt.Fprintf(&b, "  echo \"%s resolves to $addrs\"\n", p.Host)
+	return b.String()
+}
+
+func (p TLSHandshake) command() string {
+	sni := p.ServerName
+	if sni == "" {
+		sni = p.Host
+	}
+	verify := ""
+	if p.Verify {
+		verify = " -verify_return_error"
+	}
+	return fmt.Sprintf(`  local out
+  if ! out=$(timeout %s openssl s_client -connect %s -servername %s%s </dev/null 2>&1); then
+    echo "$out" | tail -n 3
+    return 1
+  fi
+  echo "handshake complete" $(echo "$out" | grep -E '^ *(Protocol|Cipher) *:' | sort -u)
+`, seconds(p.Timeout), quote(fmt.Sprintf("%s:%d", p.Host, p.Port)), quote(sni), verify)
+}
+
+func (p SQLPing) command() string {
+	port := p.Port
+	if p.Engine == Postgres {
+		if port == 0 {
+			port = 5432
+		}
+		args := fmt.Sprintf("-h %s -p %d -t %s", quote(p.Host), port, seconds(p.Timeout))
+		if p.User != "" {
+			args += " -U " + quote(p.User)
+		}
+		if p.Database != "" {
+			args += " -d " + quote(p.Database)
+		}
+		// pg_isready exits 0 when the server accepts connections and 1 when
+		// it rejects them, both of which prove it answers.
+		return fmt.Sprintf(`  local out rc
+  out=$(pg_isready %s 2>&1); rc=$?
+  echo "$out"
+  [ $rc -le 1 ]
+`, args)
+	}
+	if port == 0 {
+		port = 3306
+	}
+	args := fmt.Sprintf("-h %s -P %d --connect-timeout=%s", quote(p.Host), port, seconds(p.Timeout))
+	if p.User != "" {
+		args += " -u " + quote(p.User)
+	}
+	// mysqladmin ping succeeds when the server answers, even when it denies
+	// access to the user.
+	return fmt.Sprintf(`  mysqladmin ping %s 2>&1
+`, args)
+}
+
+func (TCPConnect) tools() []tool { return nil }
+func (HTTPExpect) tools() []tool { return []tool{{"curl", "curl"}} }
+
+func (p DNSResolve) tools() []tool {
+	if p.Server != "" {
+		return []tool{{"dig", "dnsutils"}}
+	}
+	return nil
+}
+
+func (TLSHandshake) tools() []tool { return []tool{{"openssl", "openssl"}} }
+
+func (p SQLPing) tools() []tool {
+	if p.Engine == Postgres {
+		return []tool{{"pg_isready", "postgresql-client"}}
+	}
+	return []tool{{"mysqladmin", "default-mysql-client"}}
+}
+
+// quote returns s as a single-quoted bash word.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// expand returns s as a bash word in which SelfIP expands to the address
+// of the VM and everything else is literal.
+func expand(s string) string {
+	parts := strings.Split(s, SelfIP)
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = quote(p)
+		}
+	}
+	return strings.Join(parts, `"$PROBE_SELF_IP"`)
+}
@@ -0,0 +1,321 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		script Script
+		want   string
+	}{
+		{"no probe", Script{}, "no probe to render"},
+		{"bad name", Script{Probes: []Probe{TCPConnect{Name: "a b", Host: "h", Port: 80}}}, `invalid probe name "a b": use letters, digits, '.', '_' and '-'`},
+		{"bad host", Script{Probes: []Probe{TCPConnect{Name: "a", Host: "h;reboot", Port: 80}}}, `probe a: invalid host "h;reboot"`},
+		{"bad port", Script{Probes: []Probe{TLSHandshake{Name: "a", Host: "h"}}}, "probe a: port 0 out of range 1-65535"},
+		{"bad url", Script{Probes: []Probe{HTTPExpect{Name: "a", URL: "ftp://h"}}}, `probe a: URL "ftp://h" is not an http or https URL`},
+		{"bad status", Script{Probes: []Probe{HTTPExpect{Name: "a", URL: "http://h", Status: 42}}}, "probe a: invalid status 42"},
+		{"bad server", Script{Probes: []Probe{DNSResolve{Name: "a", Host: "h", Server: "@8.8.8.8"}}}, `probe a: invalid host "@8.8.8.8"`},
+		{"bad engine", Script{Probes: []Probe{SQLPing{Name: "a", Engine: "oracle", Host: "h"}}}, `probe a: unknown engine "oracle", want mysql or postgres`},
+		{"duplicate", Script{Probes: []Probe{TCPConnect{Name: "a", Host: "h", Port: 1}, DNSResolve{Name: "a", Host: "h"}}}, `duplicate probe name "a"`},
+		{"object", Script{Probes: []Probe{TCPConnect{Name: "a", Host: "h", Port: 1}}, Bucket: "b"}, "bucket set without object"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.script.Render()
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("Render() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	s := Script{
+		Probes: []Probe{
+			TCPConnect{Name: "tcp", Host: "10.0.0.2", Port: 22},
+			HTTPExpect{Name: "http", URL: "http://10.0.0.3:8080/", Body: "client " + SelfIP},
+			DNSResolve{Name: "dns", Host: "db.example.internal", Server: "10.0.0.53", Want: []string{"10.0.0.4"}},
+			TLSHandshake{Name: "tls", Host: "10.0.0.5", Port: 443, ServerName: "www.example.com", Verify: true},
+			SQLPing{Name: "mysql", Engine: MySQL, Host: "10.0.0.6", User: "probe"},
+			SQLPing{Name: "pg", Engine: Postgres, Host: "10.0.0.7", Database: "app"},
+		},
+		Delay:  20 * time.Second,
+		Bucket: "results",
+		Object: "vm-1.txt",
+	}
+	script, err := s.Render()
+	if err != nil {
+		t.Fatalf("Failed to render script: %v", err)
+	}
+	for _, want := range []string{
+		"#!/bin/bash\n",
+		"command -v curl >/dev/null 2>&1 || missing+=(curl)\n",
+		"command -v dig >/dev/null 2>&1 || missing+=(dnsutils)\n",
+		"command -v openssl >/dev/null 2>&1 || missing+=(openssl)\n",
+		"command -v mysqladmin >/dev/null 2>&1 || missing+=(default-mysql-client)\n",
+		"command -v pg_isready >/dev/null 2>&1 || missing+=(postgresql-client)\n",
+		"PROBE_SELF_IP=${PROBE_SELF_IP:-$(curl",
+		`bash -c 'exec 3<>"/dev/tcp/$0/$1"' '10.0.0.2' 22`,
+		`local want='client '"$PROBE_SELF_IP"`,
+		"dig +short +time=10 +tries=1 @'10.0.0.53' 'db.example.internal'",
+		"openssl s_client -connect '10.0.0.5:443' -servername 'www.example.com' -verify_return_error",
+		"mysqladmin ping -h '10.0.0.6' -P 3306 --connect-timeout=10 -u 'probe'",
+		"pg_isready -h '10.0.0.7' -p 5432 -t 10 -d 'app'",
+		"ATTEMPTS=3\nINTERVAL=10\n",
+		"sleep 20\n",
+		"run_probe tcp tcp probe_0\nrun_probe http http probe_1\nrun_probe dns dns probe_2\n",
+		`echo 'PROBE_DONE {"count":6}' | tee -a "$RESULTS"`,
+		`gcloud storage cp "$RESULTS" 'gs://results/vm-1.txt'`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Rendered script does not contain %q", want)
+		}
+	}
+	if bash, err := exec.LookPath("bash"); err == nil {
+		if out, err := exec.Command(bash, "-n", "-c", script).CombinedOutput(); err != nil {
+			t.Errorf("Rendered script is not valid bash: %v\n%s", err, out)
+		}
+	}
+
+	s.SkipInstall = true
+	script, err = s.Render()
+	if err != nil {
+		t.Fatalf("Failed to render script: %v", err)
+	}
+	if strings.Contains(script, "apt-get") {
+		t.Errorf("Rendered script installs packages with SkipInstall")
+	}
+}
+
+// TestRunScript runs a rendered script on the host against local servers.
+func TestRunScript(t *testing.T) {
+	for _, cmd := range []string{"bash", "curl", "getent", "timeout"} {
+		if _, err := exec.LookPath(cmd); err != nil {
+			t.Skipf("Skipping: %s not found", cmd)
+		}
+	}
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "client 10.1.2.3 \"quoted\"\\\n")
+	}))
+	defer echo.Close()
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+	echoPort := echo.Listener.Addr().(*net.TCPAddr).Port
+
+	probes := []Probe{
+		TCPConnect{Name: "tcp-open", Host: "127.0.0.1", Port: echoPort},
+		TCPConnect{Name: "tcp-closed", Host: "127.0.0.1", Port: closedPort, Timeout: time.Second},
+		HTTPExpect{Name: "http-echo", URL: echo.URL, Body: "client " + SelfIP},
+		HTTPExpect{Name: "http-body", URL: echo.URL, Body: "10.9.9.9"},
+		HTTPExpect{Name: "http-404", URL: missing.URL},
+		DNSResolve{Name: "dns", Host: "localhost"},
+		DNSResolve{Name: "dns-want", Host: "localhost", Want: []string{"192.0.2.1"}},
+	}
+	want := map[string]bool{
+		"tcp-open":   true,
+		"tcp-closed": false,
+		"http-echo":  true,
+		"http-body":  false,
+		"http-404":   false,
+		"dns":        true,
+		"dns-want":   false,
+	}
+	if _, err := exec.LookPath("openssl"); err == nil {
+		tlsServer := httptest.NewUnstartedServer(http.NotFoundHandler())
+		tlsServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+		tlsServer.StartTLS()
+		defer tlsServer.Close()
+		tlsPort := tlsServer.Listener.Addr().(*net.TCPAddr).Port
+		probes = append(probes,
+			TLSHandshake{Name: "tls", Host: "127.0.0.1", Port: tlsPort, ServerName: "example.com"},
+			TLSHandshake{Name: "tls-verify", Host: "127.0.0.1", Port: tlsPort, Verify: true},
+		)
+		want["tls"] = true
+		want["tls-verify"] = false
+	}
+
+	script, err := Script{Probes: probes, Attempts: 2, Interval: time.Second, SkipInstall: true}.Render()
+	if err != nil {
+		t.Fatalf("Failed to render script: %v", err)
+	}
+	results := filepath.Join(t.TempDir(), "results.txt")
+	cmd := exec.Command("bash", "-c", script)
+	cmd.Env = append(os.Environ(), "PROBE_SELF_IP=10.1.2.3", "PROBE_RESULTS="+results)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to run script: %v\n%s", err, out)
+	}
+
+	serial, err := Parse(string(out))
+	if err != nil {
+		t.Fatalf("Failed to parse script output: %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(results)
+	if err != nil {
+		t.Fatalf("Failed to read results file: %v", err)
+	}
+	file, err := Parse(string(data))
+	if err != nil {
+		t.Fatalf("Failed to parse results file: %v", err)
+	}
+	if !reflect.DeepEqual(serial, file) {
+		t.Errorf("Output and results file differ:\n%+v\n%+v", serial, file)
+	}
+	if !serial.Done {
+		t.Errorf("Report is not done")
+	}
+	if len(serial.Results) != len(probes) {
+		t.Fatalf("Got %d results, want %d:\n%s", len(serial.Results), len(probes), out)
+	}
+	for i, res := range serial.Results {
+		if res.Name != probes[i].ProbeName() || res.Kind != probes[i].Kind() {
+			t.Errorf("Result %d is %s %s, want %s %s", i, res.Kind, res.Name, probes[i].Kind(), probes[i].ProbeName())
+		}
+		if res.OK != want[res.Name] {
+			t.Errorf("Result of %s: ok = %v, want %v (%s)", res.Name, res.OK, want[res.Name], res.Detail)
+		}
+		wantAttempts := 1
+		if !res.OK {
+			wantAttempts = 2
+		}
+		if res.Attempts != wantAttempts {
+			t.Errorf("Result of %s: attempts = %d, want %d", res.Name, res.Attempts, wantAttempts)
+		}
+	}
+	if res, _ := serial.Result("http-body"); !strings.Contains(res.Detail, "client 10.1.2.3 \"quoted\"\\") {
+		t.Errorf("Detail of http-body = %q, want the response body", res.Detail)
+	}
+	if err := serial.Check(probes[0], probes[2]); err != nil {
+		t.Errorf("Check() of passing probes: %v", err)
+	}
+	if err := serial.Check(probes[1]); err == nil {
+		t.Errorf("Check() of a failing probe returned nil")
+	}
+}
+
+func TestParse(t *testing.T) {
+	serial := strings.Join([]string{
+		"[    0.000000] Linux version 6.1.0-18-cloud-amd64",
+		"Oct 19 10:00:01 vm-1 google_metadata_script_runner[812]: startup-script: PROBE_START",
+		`Oct 19 10:00:02 vm-1 google_metadata_script_runner[812]: startup-script: PROBE_RESULT {"name":"lb","kind":"http","ok":false,"attempts":3,"detail":"HTTP 503, want 200"}`,
+		"[   41.000000] reboot: Restarting system",
+		"Oct 19 10:02:01 vm-1 google_metadata_script_runner[790]: startup-script: PROBE_START\r",
+		`Oct 19 10:02:02 vm-1 google_metadata_script_runner[790]: startup-script: PROBE_RESULT {"name":"lb","kind":"http","ok":true,"attempts":1,"detail":"HTTP 200"}` + "\r",
+		`Oct 19 10:02:03 vm-1 google_metadata_script_runner[790]: startup-script: PROBE_RESULT {"name":"db","kind":"sql","ok":false,"attempts":3,"detail":"line 1\nline \"2\""}`,
+		`Oct 19 10:02:03 vm-1 google_metadata_script_runner[790]: startup-script: PROBE_DONE {"count":2}`,
+	}, "\n")
+	got, err := Parse(serial)
+	if err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	want := &Report{
+		Results: []Result{
+			{Name: "lb", Kind: KindHTTP, OK: true, Attempts: 1, Detail: "HTTP 200"},
+			{Name: "db", Kind: KindSQL, OK: false, Attempts: 3, Detail: "line 1\nline \"2\""},
+		},
+		Done: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+	if failed := got.Failed(); len(failed) != 1 || failed[0].Name != "db" {
+		t.Errorf("Failed() = %+v, want the db result", failed)
+	}
+	wantErr := "sql probe db failed after 3 attempt(s): line 1\nline \"2\"\ntcp probe cache has no result"
+	err = got.Check(TCPConnect{Name: "lb"}, SQLPing{Name: "db"}, TCPConnect{Name: "cache"})
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Check() = %v, want %q", err, wantErr)
+	}
+
+	got, err = Parse("PROBE_START\n" + `PROBE_RESULT {"name":"lb","kind":"http","ok":true,"attempts":1,"detail":""}`)
+	if err != nil {
+		t.Fatalf("Failed to parse output: %v", err)
+	}
+	if got.Done || len(got.Results) != 1 {
+		t.Errorf("Parse() of a running script = %+v, want one result and not done", got)
+	}
+
+	_, err = Parse("PROBE_START\nPROBE_RESULT {\"name\":\"lb\",\"ok\":tru")
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2: malformed PROBE_RESULT marker") {
+		t.Errorf("Parse() of a truncated marker returned error %v", err)
+	}
+}
+
+func TestSources(t *testing.T) {
+	output := "PROBE_START\n" + `PROBE_RESULT {"name":"lb","kind":"tcp","ok":true,"attempts":1,"detail":"connected"}` + "\nPROBE_DONE {\"count\":1}\n"
+	var calls []string
+	runs := 0
+	run := func(_ context.Context, _, name string, args ...string) ([]byte, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		runs++
+		if runs < 3 {
+			return nil, errors.New("not found")
+		}
+		return []byte(output), nil
+	}
+
+	r, err := Await(GCS(run, "bucket", "vm-1.txt"), time.Minute, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to await GCS results: %v", err)
+	}
+	if !r.Done || len(r.Results) != 1 {
+		t.Errorf("Await() = %+v, want one result", r)
+	}
+	if want := "gcloud storage cat gs://bucket/vm-1.txt"; len(calls) != 3 || calls[2] != want {
+		t.Errorf("Runner calls = %q, want 3 calls of %q", calls, want)
+	}
+
+	calls = nil
+	if _, err := SerialPort(run, "project", "vm-1", "us-central1-a")(); err != nil {
+		t.Fatalf("Failed to read serial port: %v", err)
+	}
+	if want := []string{"gcloud compute instances get-serial-port-output vm-1 --project=project --zone=us-central1-a --port=1"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Runner calls = %q, want %q", calls, want)
+	}
+
+	running := func() (*Report, error) { return Parse("PROBE_START\n") }
+	r, err = Await(running, 0, time.Millisecond)
+	if err == nil || r == nil || r.Done {
+		t.Errorf("Await() of an unfinished script = %+v, %v, want the partial report and an error", r, err)
+	}
+	failing := func() (*Report, error) { return nil, errors.New("permission denied") }
+	if _, err := Await(failing, 0, time.Millisecond); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Await() of a failing source returned error %v", err)
+	}
+}
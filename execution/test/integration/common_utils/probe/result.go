@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/command"
+)
+
+// Markers printed by the script. A marker may follow a prefix on its line,
+// such as the timestamp and program the serial console adds.
+const (
+	StartMarker  = "PROBE_START"
+	ResultMarker = "PROBE_RESULT"
+	DoneMarker   = "PROBE_DONE"
+)
+
+// Result is the outcome of a probe.
+type Result struct {
+	Name     string `json:"name"`
+	Kind     Kind   `json:"kind"`
+	OK       bool   `json:"ok"`
+	Attempts int    `json:"attempts"`
+	Detail   string `json:"detail"`
+}
+
+func (r Result) String() string {
+	status := "ok"
+	if !r.OK {
+		status = "failed"
+	}
+	return fmt.Sprintf("%s probe %s %s after %d attempt(s): %s", r.Kind, r.Name, status, r.Attempts, r.Detail)
+}
+
+// Report holds the results of the last run of a script.
+type Report struct {
+	// Results are in the order the probes ran.
+	Results []Result
+	// Done is set once every probe has run.
+	Done bool
+}
+
+// Parse reads the markers of output, the serial port output of a VM or the
+// content of the uploaded results object. A startup script runs again when
+// the VM restarts: only the markers of the last run are kept.
+func Parse(output string) (*Report, error) {
+	r := &Report{}
+	for i, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.Contains(line, ResultMarker+" "):
+			data := line[strings.Index(line, ResultMarker+" ")+len(ResultMarker)+1:]
+			var res Result
+			if err := json.Unmarshal([]byte(data), &res); err != nil {
+				return nil, fmt.Errorf("line %d: malformed %s marker: %w", i+1, ResultMarker, err)
+			}
+			r.Results = append(r.Results, res)
+		case strings.Contains(line, DoneMarker):
+			r.Done = true
+		case strings.Contains(line, StartMarker):
+			*r = Report{}
+		}
+	}
+	return r, nil
+}
+
+// Result returns the result of the named probe.
+func (r *Report) Result(name string) (Result, bool) {
+	for _, res := range r.Results {
+		if res.Name == name {
+			return res, true
+		}
+	}
+	return Result{}, false
+}
+
+// Failed returns the results of the probes which failed.
+func (r *Report) Failed() []Result {
+	var out []Result
+	for _, res := range r.Results {
+		if !res.OK {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Check returns an error listing the probes which failed or have no
+// result, or nil when every probe succeeded.
+func (r *Report) Check(probes ...Probe) error {
+	var errs []string
+	for _, p := range probes {
+		res, ok := r.Result(p.ProbeName())
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("%s probe %s has no result", p.Kind(), p.ProbeName()))
+		case !res.OK:
+			errs = append(errs, res.String())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// Source fetches and parses the markers of a script.
+type Source func() (*Report, error)
+
+// SerialPort reads the markers from serial port 1 of a VM, like
+// common_utils.GetSerialPortOutput.
+func SerialPort(run command.Runner, projectID, vmName, zone string) Source {
+	return func() (*Report, error) {
+		out, err := run(context.Background(), "", "gcloud", "compute", "instances", "get-serial-port-output", vmName,
+			"--project="+projectID, "--zone="+zone, "--port=1")
+		if err != nil {
+			return nil, err
+		}
+		return Parse(string(out))
+	}
+}
+
+// GCS reads the markers from the object a script uploaded.
+func GCS(run command.Runner, bucket, object string) Source {
+	return func() (*Report, error) {
+		out, err := run(context.Background(), "", "gcloud", "storage", "cat", fmt.Sprintf("gs://%s/%s", bucket, object))
+		if err != nil {
+			return nil, err
+		}
+		return Parse(string(out))
+	}
+}
+
+// Await polls src every interval until the script has run every probe, and
+// returns the report. After timeout it returns the last error, or the
+// partial report with an error.
+func Await(src Source, timeout, interval time.Duration) (*Report, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		r, err := src()
+		if err == nil && r.Done {
+			return r, nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return nil, fmt.Errorf("probes not done after %s: %w", timeout, err)
+			}
+			return r, fmt.Errorf("probes not done after %s: %d result(s) so far", timeout, len(r.Results))
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Defaults of a Script.
+const (
+	DefaultAttempts = 3
+	DefaultInterval = 10 * time.Second
+)
+
+// ResultsFile is the file in the VM the script appends the markers to, and
+// uploads when Script.Bucket is set. The PROBE_RESULTS environment variable
+// overrides it.
+const ResultsFile = "/tmp/probe-results.txt"
+
+// Script is a startup script running probes.
+type Script struct {
+	// Probes run in order, each under a unique name.
+	Probes []Probe
+	// Delay is the wait before the first probe, e.g. for a load balancer
+	// to program its backends.
+	Delay time.Duration
+	// Attempts is the number of times a failing probe is tried, and
+	// Interval the wait between two attempts.
+	Attempts int
+	Interval time.Duration
+	// Bucket and Object, when Bucket is set, are the GCS object the markers
+	// are uploaded to once every probe has run. The VM service account needs
+	// write access to the bucket.
+	Bucket string
+	Object string
+	// SkipInstall does not install the commands the probes need, for images
+	// which already have them.
+	SkipInstall bool
+}
+
+// Render returns the bash startup script running the probes.
+func (s Script) Render() (string, error) {
+	if len(s.Probes) == 0 {
+		return "", errors.New("no probe to render")
+	}
+	seen := map[string]bool{}
+	for _, p := range s.Probes {
+		if err := p.validate(); err != nil {
+			return "", err
+		}
+		if seen[p.ProbeName()] {
+			return "", fmt.Errorf("duplicate probe name %q", p.ProbeName())
+		}
+		seen[p.ProbeName()] = true
+	}
+	if s.Bucket != "" && s.Object == "" {
+		return "", errors.New("bucket set without object")
+	}
+	attempts := s.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	var b strings.Builder
+	b.WriteString(`#!/bin/bash
+# Connectivity probes rendered by the probe package. Every probe prints one
+# PROBE_RESULT marker, a JSON object, to the serial console.
+`)
+	fmt.Fprintf(&b, "RESULTS=${PROBE_RESULTS:-%s}\n: > \"$RESULTS\"\n", ResultsFile)
+	b.WriteString(helpers)
+
+	if !s.SkipInstall {
+		var tools []tool
+		for _, p := range s.Probes {
+			for _, t := range p.tools() {
+				if !slices.Contains(tools, t) {
+					tools = append(tools, t)
+				}
+			}
+		}
+		if s.usesSelfIP() && !slices.Contains(tools, tool{"curl", "curl"}) {
+			tools = append(tools, tool{"curl", "curl"})
+		}
+		if len(tools) > 0 {
+			b.WriteString("\nmissing=()\n")
+			for _, t := range tools {
+				fmt.Fprintf(&b, "command -v %s >/dev/null 2>&1 || missing+=(%s)\n", t.command, t.pkg)
+			}
+			b.WriteString(`if [ ${#missing[@]} -gt 0 ]; then
+  apt-get update -qq && DEBIAN_FRONTEND=noninteractive apt-get install -y -qq "${missing[@]}" >/dev/null 2>&1
+fi
+`)
+		}
+	}
+	if s.usesSelfIP() {
+		b.WriteString(`
+PROBE_SELF_IP=${PROBE_SELF_IP:-$(curl -s -m 5 -H 'Metadata-Flavor: Google' http://metadata.google.internal/computeMetadata/v1/instance/network-interfaces/0/ip)}
+`)
+	}
+
+	for i, p := range s.Probes {
+		fmt.Fprintf(&b, "\n# %s probe %s\nprobe_%d() {\n%s}\n", p.Kind(), p.ProbeName(), i, p.command())
+	}
+
+	fmt.Fprintf(&b, "\nATTEMPTS=%d\nINTERVAL=%s\n", attempts, seconds(interval))
+	fmt.Fprintf(&b, "echo %q | tee -a \"$RESULTS\"\n", StartMarker)
+	if s.Delay > 0 {
+		fmt.Fprintf(&b, "sleep %s\n", seconds(s.Delay))
+	}
+	for i, p := range s.Probes {
+		fmt.Fprintf(&b, "run_probe %s %s probe_%d\n", p.ProbeName(), p.Kind(), i)
+	}
+	fmt.Fprintf(&b, "echo '%s {\"count\":%d}' | tee -a \"$RESULTS\"\n", DoneMarker, len(s.Probes))
+	if s.Bucket != "" {
+		dest := fmt.Sprintf("gs://%s/%s", s.Bucket, s.Object)
+		fmt.Fprintf(&b, "gcloud storage cp \"$RESULTS\" %s || echo %s\n", quote(dest), quote("probe: upload to "+dest+" failed"))
+	}
+	return b.String(), nil
+}
+
+// usesSelfIP reports whether a probe expands SelfIP.
+func (s Script) usesSelfIP() bool {
+	for _, p := range s.Probes {
+		if h, ok := p.(HTTPExpect); ok && strings.Contains(h.Body, SelfIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// helpers are the bash functions running a probe and printing its marker.
+// json_escape keeps the detail of a result short and valid in a JSON string.
+const helpers = `
+json_escape() {
+  printf '%s' "$1" | head -c 512 | tr -d '\000-\010\013-\037' |
+    sed -e 's/\\/\\\\/g' -e 's/"/\\"/g' -e 's/\t/\\t/g' |
+    awk 'NR > 1 { printf "\\n" } { printf "%s", $0 }'
+}
+
+emit() {
+  echo "PROBE_RESULT {\"name\":\"$1\",\"kind\":\"$2\",\"ok\":$3,\"attempts\":$4,\"detail\":\"$(json_escape "$5")\"}" | tee -a "$RESULTS"
+}
+
+run_probe() {
+  local name=$1 kind=$2 fn=$3 attempt detail
+  for attempt in $(seq 1 "$ATTEMPTS"); do
+    if detail=$("$fn" 2>&1); then
+      emit "$name" "$kind" true "$attempt" "$detail"
+      return 0
+    fi
+    if [ "$attempt" -lt "$ATTEMPTS" ]; then
+      sleep "$INTERVAL"
+    fi
+  done
+  emit "$name" "$kind" false "$ATTEMPTS" "$detail"
+}
+`
@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package producer
+
+import "strings"
+
+// alloyDB is an AlloyDB cluster with PSC enabled and its primary instance,
+// named after the cluster, which the stage looks up by cluster and name.
+type alloyDB struct{}
+
+func init() { Register(alloyDB{}) }
+
+func (alloyDB) Name() string { return "alloydb" }
+
+// Create sets the password of the cluster to its name, as the suite never
+// connects to the database.
+func (alloyDB) Create(i Instance) []Command {
+	return []Command{
+		// The cluster is created synchronously, as its instance needs it.
+		{"gcloud", "alloydb", "clusters", "create", i.Name,
+			"--project=" + i.ProjectID, "--region=" + i.Region,
+			"--password=" + i.Name, "--enable-private-service-connect"},
+		{"gcloud", "alloydb", "instances", "create", i.Name,
+			"--cluster=" + i.Name, "--project=" + i.ProjectID, "--region=" + i.Region,
+			"--instance-type=PRIMARY", "--cpu-count=2", "--availability-type=ZONAL",
+			"--allowed-psc-projects=" + strings.Join(i.AllowedProjects, ","), "--async"},
+	}
+}
+
+func (alloyDB) Delete(i Instance) []Command {
+	return []Command{{"gcloud", "alloydb", "clusters", "delete", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--force", "--quiet"}}
+}
+
+func (alloyDB) State(i Instance) Command {
+	return Command{"gcloud", "alloydb", "instances", "describe", i.Name, "--cluster=" + i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--format=value(state)"}
+}
+
+func (alloyDB) ReadyState() string { return "READY" }
+
+func (alloyDB) Attachment(i Instance) Command {
+	return Command{"gcloud", "alloydb", "instances", "describe", i.Name, "--cluster=" + i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--format=value(pscInstanceConfig.serviceAttachmentLink)"}
+}
+
+func (alloyDB) Endpoint(i Instance, _ string) (string, any) {
+	return "producer_alloydb", map[string]any{"cluster_id": i.Name, "instance_name": i.Name}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package producer
+
+import "strings"
+
+// cloudSQL is a MySQL Cloud SQL instance with PSC enabled, which the stage
+// looks up by name.
+type cloudSQL struct{}
+
+func init() { Register(cloudSQL{}) }
+
+func (cloudSQL) Name() string { return "cloudsql" }
+
+func (cloudSQL) Create(i Instance) []Command {
+	return []Command{{"gcloud", "sql", "instances", "create", i.Name,
+		"--project=" + i.ProjectID, "--database-version=MYSQL_8_0", "--region=" + i.Region,
+		"--enable-private-service-connect", "--allowed-psc-projects=" + strings.Join(i.AllowedProjects, ","),
+		"--no-assign-ip", "--availability-type=REGIONAL", "--tier=db-n1-standard-1",
+		"--enable-bin-log", "--async",
+	}}
+}
+
+func (cloudSQL) Delete(i Instance) []Command {
+	return []Command{{"gcloud", "sql", "instances", "delete", i.Name, "--project=" + i.ProjectID, "--quiet"}}
+}
+
+func (cloudSQL) State(i Instance) Command {
+	return Command{"gcloud", "sql", "instances", "describe", i.Name, "--project=" + i.ProjectID, "--format=value(state)"}
+}
+
+func (cloudSQL) ReadyState() string { return "RUNNABLE" }
+
+func (cloudSQL) Attachment(i Instance) Command {
+	return Command{"gcloud", "sql", "instances", "describe", i.Name, "--project=" + i.ProjectID, "--format=value(pscServiceAttachmentLink)"}
+}
+
+func (cloudSQL) Endpoint(i Instance, _ string) (string, any) {
+	return "producer_cloudsql", map[string]any{"instance_name": i.Name}
+}
@@ -0,0 +1,255 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package producer describes the Private Service Connect producers the
+// producer-connectivity suite connects to, such as Cloud SQL or Vertex AI
+// online endpoints, as the gcloud commands creating, polling and deleting
+// them and the psc_endpoints entry pointing the 05-producer-connectivity
+// stage at them.
+//
+// A producer registers itself from an init function of its file:
+//
+//	func init() { Register(cloudSQL{}) }
+//
+// The suite runs every registered producer, and the conformance test of the
+// package checks every one against a fake command runner, so adding a
+// producer only takes implementing Producer.
+package producer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/command"
+)
+
+// Instance identifies the producer instance a test creates.
+type Instance struct {
+	// Name is the name of the instance, unique per test run, such as
+	// "test-cloudsql-psc-1234". Producers derive the names of their other
+	// resources from it.
+	Name string
+	// ProjectID and Region are where the instance is created.
+	ProjectID string
+	Region    string
+	// AllowedProjects are the consumer projects allowed to connect to the
+	// instance, where the stage creates the PSC endpoints.
+	AllowedProjects []string
+}
+
+// Command is a command line, its program first, such as
+// {"gcloud", "sql", "instances", "describe", ...}.
+type Command []string
+
+// String returns the command line, for logs.
+func (c Command) String() string {
+	return strings.Join(c, " ")
+}
+
+// Producer is a PSC producer the stage can create endpoints for.
+type Producer interface {
+	// Name is the short name of the producer, such as "cloudsql", used in
+	// subtest and instance names.
+	Name() string
+	// Create returns the commands creating the instance, run in order. The
+	// last one may return before the instance is ready.
+	Create(i Instance) []Command
+	// Delete returns the commands deleting the instance and every resource
+	// Create made. They all run, even when one fails.
+	Delete(i Instance) []Command
+	// State returns the command printing the state of the instance, which
+	// is ReadyState once endpoints can connect to it.
+	State(i Instance) Command
+	ReadyState() string
+	// Attachment returns the command printing the service attachment of the
+	// instance, empty until the producer publishes it.
+	Attachment(i Instance) Command
+	// Endpoint returns the attribute of a psc_endpoints entry of the stage
+	// selecting the instance, such as producer_cloudsql, and its value.
+	// Producers the stage does not look up by name return "target" and the
+	// service attachment.
+	Endpoint(i Instance, attachment string) (key string, value any)
+}
+
+// Target is the attribute of a psc_endpoints entry holding a service
+// attachment.
+const Target = "target"
+
+var (
+	registry = map[string]Producer{}
+	nameRE   = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+// Register makes a producer available to the suite. It panics when the name
+// is invalid or already registered.
+func Register(p Producer) {
+	name := p.Name()
+	if !nameRE.MatchString(name) {
+		panic(fmt.Sprintf("producer: invalid name %q", name))
+	}
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("producer: %s registered twice", name))
+	}
+	registry[name] = p
+}
+
+// Get returns the producer registered under name.
+func Get(name string) (Producer, bool) {
+	p, ok := registry[name]
+	return p, ok
+}
+
+// All returns the registered producers, sorted by name.
+func All() []Producer {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]Producer, len(names))
+	for i, name := range names {
+		out[i] = registry[name]
+	}
+	return out
+}
+
+// Select returns the producers named in a comma separated list, such as
+// "cloudsql,alloydb", or every producer when the list is empty.
+func Select(list string) ([]Producer, error) {
+	if strings.TrimSpace(list) == "" {
+		return All(), nil
+	}
+	var out []Producer
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		p, ok := Get(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown producer %q", name)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Retry returns a runner running failed commands again, up to attempts
+// times in total, waiting wait between two attempts.
+func Retry(run command.Runner, attempts int, wait time.Duration) command.Runner {
+	return func(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+		var out []byte
+		var err error
+		for i := 0; i < attempts; i++ {
+			if out, err = run(ctx, dir, name, args...); err == nil {
+				return out, nil
+			}
+			if i < attempts-1 {
+				log.Printf("Command failed, retrying in %s: %v", wait, err)
+				time.Sleep(wait)
+			}
+		}
+		return out, err
+	}
+}
+
+func (c Command) run(run command.Runner) (string, error) {
+	if len(c) == 0 {
+		return "", errors.New("empty command")
+	}
+	log.Printf("Running command: %s", c)
+	out, err := run(context.Background(), "", c[0], c[1:]...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// Create runs the commands creating the instance, stopping at the first
+// failure.
+func Create(run command.Runner, p Producer, i Instance) error {
+	for _, c := range p.Create(i) {
+		if _, err := c.run(run); err != nil {
+			return fmt.Errorf("create %s instance %s: %w", p.Name(), i.Name, err)
+		}
+	}
+	return nil
+}
+
+// Delete runs every command deleting the instance and returns their errors.
+func Delete(run command.Runner, p Producer, i Instance) error {
+	var errs []error
+	for _, c := range p.Delete(i) {
+		if _, err := c.run(run); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("delete %s instance %s: %w", p.Name(), i.Name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// WaitReady polls the state of the instance every interval until it is the
+// ready state of the producer, or timeout elapses.
+func WaitReady(run command.Runner, p Producer, i Instance, timeout, interval time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		state, err := p.State(i).run(run)
+		if err == nil && state == p.ReadyState() {
+			log.Printf("%s instance %s is %s", p.Name(), i.Name, state)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return fmt.Errorf("%s instance %s not %s after %s: %w", p.Name(), i.Name, p.ReadyState(), timeout, err)
+			}
+			return fmt.Errorf("%s instance %s not %s after %s, state %q", p.Name(), i.Name, p.ReadyState(), timeout, state)
+		}
+		log.Printf("%s instance %s not ready yet (state %q), waiting %s", p.Name(), i.Name, state, interval)
+		time.Sleep(interval)
+	}
+}
+
+// WaitAttachment polls the service attachment of the instance every
+// interval until the producer publishes it, or timeout elapses.
+func WaitAttachment(run command.Runner, p Producer, i Instance, timeout, interval time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		attachment, err := p.Attachment(i).run(run)
+		if err == nil && attachment != "" {
+			return attachment, nil
+		}
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = errors.New("empty service attachment")
+			}
+			return "", fmt.Errorf("service attachment of %s instance %s: %w", p.Name(), i.Name, err)
+		}
+		log.Printf("Service attachment of %s not yet available, waiting %s", i.Name, interval)
+		time.Sleep(interval)
+	}
+}
+
+// ForwardingRuleName returns the name the stage gives the forwarding rule
+// of the psc_endpoints entry at index, selecting the instance with the
+// attribute key and value returned by Producer.Endpoint.
+func ForwardingRuleName(key string, value any, index int) string {
+	if block, ok := value.(map[string]any); ok && key != Target {
+		if name, ok := block["instance_name"].(string); ok && name != "" {
+			return "psc-forwarding-rule-" + name
+		}
+	}
+	return fmt.Sprintf("psc-forwarding-rule-custom-%d", index)
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package producer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stageVariables is the file declaring the psc_endpoints attributes the
+// producers select their instance with.
+const stageVariables = "../../../../05-producer-connectivity/variables.tf"
+
+// fakeRunner records the commands it runs and answers them from outputs,
+// keyed by command line, the last output of a command being repeated.
+type fakeRunner struct {
+	outputs map[string][]string
+	fail    func(c Command) bool
+	calls   []Command
+}
+
+func (f *fakeRunner) run(_ context.Context, _, name string, args ...string) ([]byte, error) {
+	c := append(Command{name}, args...)
+	f.calls = append(f.calls, c)
+	if f.fail != nil && f.fail(c) {
+		return nil, errors.New("command failed")
+	}
+	out := f.outputs[c.String()]
+	if len(out) == 0 {
+		return nil, nil
+	}
+	if len(out) > 1 {
+		f.outputs[c.String()] = out[1:]
+	}
+	return []byte(out[0] + "\n"), nil
+}
+
+var testInstance = Instance{
+	Name:            "test-psc-1234",
+	ProjectID:       "producer-project",
+	Region:          "europe-west4",
+	AllowedProjects: []string{"consumer-project", "other-project"},
+}
+
+// TestConformance checks every registered producer against a fake runner.
+func TestConformance(t *testing.T) {
+	variables, err := os.ReadFile(stageVariables)
+	if err != nil {
+		t.Fatalf("Failed to read stage variables: %v", err)
+	}
+	if len(All()) < 5 {
+		t.Errorf("All() returned %d producers, want at least 5", len(All()))
+	}
+	i := testInstance
+	attachment := "projects/producer-project/regions/europe-west4/serviceAttachments/sa-1"
+
+	for _, p := range All() {
+		t.Run(p.Name(), func(t *testing.T) {
+			if got, ok := Get(p.Name()); !ok || got != p {
+				t.Errorf("Get(%q) did not return the producer", p.Name())
+			}
+
+			create, del := p.Create(i), p.Delete(i)
+			if len(create) == 0 || len(del) == 0 {
+				t.Fatalf("Producer has %d create and %d delete commands, want at least one of each", len(create), len(del))
+			}
+			commands := append(append(append([]Command{}, create...), del...), p.State(i), p.Attachment(i))
+			for _, c := range commands {
+				if len(c) < 2 || (c[0] != "gcloud" && c[0] != "bash") {
+					t.Errorf("Command %q does not run gcloud or bash", c)
+				}
+				if line := c.String(); !strings.Contains(line, i.Name) || !strings.Contains(line, i.ProjectID) {
+					t.Errorf("Command %q does not select instance %s of project %s", c, i.Name, i.ProjectID)
+				}
+			}
+			if !strings.Contains(joined(create), i.Region) {
+				t.Errorf("Create commands do not use region %s: %q", i.Region, create)
+			}
+			other := i
+			other.Name = "test-psc-5678"
+			if reflect.DeepEqual(p.Create(other), create) {
+				t.Errorf("Create commands do not depend on the instance name")
+			}
+
+			f := &fakeRunner{}
+			if err := Create(f.run, p, i); err != nil {
+				t.Fatalf("Failed to create instance: %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, create) {
+				t.Errorf("Create() ran %q, want %q", f.calls, create)
+			}
+			f = &fakeRunner{fail: func(Command) bool { return true }}
+			if err := Create(f.run, p, i); err == nil || len(f.calls) != 1 {
+				t.Errorf("Create() with a failing command returned %v after %d commands, want an error after 1", err, len(f.calls))
+			}
+			if err := Delete(f.run, p, i); err == nil || len(f.calls) != 1+len(del) {
+				t.Errorf("Delete() with failing commands returned %v after %d commands, want an error after all %d", err, len(f.calls)-1, len(del))
+			}
+
+			if p.ReadyState() == "" {
+				t.Fatalf("Producer has no ready state")
+			}
+			state := p.State(i).String()
+			f = &fakeRunner{outputs: map[string][]string{state: {"", "CREATING", p.ReadyState()}}}
+			if err := WaitReady(f.run, p, i, time.Hour, 0); err != nil || len(f.calls) != 3 {
+				t.Errorf("WaitReady() returned %v after %d polls, want success after 3", err, len(f.calls))
+			}
+			f = &fakeRunner{outputs: map[string][]string{state: {"CREATING"}}}
+			if err := WaitReady(f.run, p, i, 0, 0); err == nil || !strings.Contains(err.Error(), `state "CREATING"`) {
+				t.Errorf("WaitReady() of a pending instance returned %v", err)
+			}
+
+			f = &fakeRunner{outputs: map[string][]string{p.Attachment(i).String(): {"", attachment}}}
+			if got, err := WaitAttachment(f.run, p, i, time.Hour, 0); err != nil || got != attachment {
+				t.Errorf("WaitAttachment() = %q, %v, want %q", got, err, attachment)
+			}
+
+			key, value := p.Endpoint(i, attachment)
+			if !regexp.MustCompile(`(?m)^\s*` + regexp.QuoteMeta(key) + `\s*=`).Match(variables) {
+				t.Errorf("Endpoint() returned attribute %s, which psc_endpoints does not declare", key)
+			}
+			switch {
+			case key == Target:
+				if value != attachment {
+					t.Errorf("Endpoint() returned target %v, want the service attachment", value)
+				}
+				if got, want := ForwardingRuleName(key, value, 0), "psc-forwarding-rule-custom-0"; got != want {
+					t.Errorf("ForwardingRuleName() = %q, want %q", got, want)
+				}
+			default:
+				block, ok := value.(map[string]any)
+				if !ok || block["instance_name"] == nil {
+					t.Fatalf("Endpoint() returned %s = %v, want a block with an instance_name", key, value)
+				}
+				if got, want := ForwardingRuleName(key, value, 0), "psc-forwarding-rule-"+i.Name; got != want {
+					t.Errorf("ForwardingRuleName() = %q, want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func joined(commands []Command) string {
+	var lines []string
+	for _, c := range commands {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Producer
+		want string
+	}{
+		{"duplicate", cloudSQL{}, "producer: cloudsql registered twice"},
+		{"invalid name", named("Cloud SQL"), `producer: invalid name "Cloud SQL"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if got := recover(); got != tc.want {
+					t.Errorf("Register() panicked with %v, want %q", got, tc.want)
+				}
+			}()
+			Register(tc.p)
+		})
+	}
+}
+
+type named string
+
+func (n named) Name() string                          { return string(n) }
+func (named) Create(Instance) []Command               { return nil }
+func (named) Delete(Instance) []Command               { return nil }
+func (named) State(Instance) Command                  { return nil }
+func (named) ReadyState() string                      { return "" }
+func (named) Attachment(Instance) Command             { return nil }
+func (named) Endpoint(Instance, string) (string, any) { return Target, nil }
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+		err  string
+	}{
+		{list: "", want: []string{"alloydb", "cloudsql", "redis-cluster", "vector-search", "vertex-endpoint"}},
+		{list: "cloudsql, vertex-endpoint", want: []string{"cloudsql", "vertex-endpoint"}},
+		{list: "cloudsql,spanner", err: `unknown producer "spanner"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.list, func(t *testing.T) {
+			got, err := Select(tc.list)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Select(%q) error = %v, want %q", tc.list, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to select producers: %v", err)
+			}
+			var names []string
+			for _, p := range got {
+				names = append(names, p.Name())
+			}
+			if !reflect.DeepEqual(names, tc.want) {
+				t.Errorf("Select(%q) = %q, want %q", tc.list, names, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	run := Retry(func(context.Context, string, string, ...string) ([]byte, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("transient")
+		}
+		return []byte("ok"), nil
+	}, 5, 0)
+	if out, err := run(context.Background(), "", "gcloud", "version"); err != nil || string(out) != "ok" || calls != 3 {
+		t.Errorf("Retry() = %q, %v after %d calls, want ok after 3", out, err, calls)
+	}
+
+	calls = 0
+	if _, err := Retry(func(context.Context, string, string, ...string) ([]byte, error) {
+		calls++
+		return nil, errors.New("permanent")
+	}, 2, 0)(context.Background(), "", "gcloud"); err == nil || calls != 2 {
+		t.Errorf("Retry() returned %v after %d calls, want an error after 2", err, calls)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package producer
+
+// redisCluster is a Memorystore for Redis Cluster created without a network,
+// for user-created PSC connections, which the stage targets through the
+// service attachment of its discovery endpoint.
+type redisCluster struct{}
+
+func init() { Register(redisCluster{}) }
+
+func (redisCluster) Name() string { return "redis-cluster" }
+
+func (redisCluster) Create(i Instance) []Command {
+	return []Command{{"gcloud", "redis", "clusters", "create", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region,
+		"--shard-count=3", "--replica-count=0", "--async"}}
+}
+
+func (redisCluster) Delete(i Instance) []Command {
+	return []Command{{"gcloud", "redis", "clusters", "delete", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--quiet"}}
+}
+
+func (redisCluster) State(i Instance) Command {
+	return Command{"gcloud", "redis", "clusters", "describe", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--format=value(state)"}
+}
+
+func (redisCluster) ReadyState() string { return "ACTIVE" }
+
+func (redisCluster) Attachment(i Instance) Command {
+	return Command{"gcloud", "redis", "clusters", "describe", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--format=value(pscServiceAttachments[0].serviceAttachment)"}
+}
+
+func (redisCluster) Endpoint(_ Instance, attachment string) (string, any) {
+	return Target, attachment
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package producer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// vectorSearch is a Vector Search index endpoint with PSC enabled and a
+// small streaming index deployed to it, which the stage targets through the
+// service attachment of the deployed index. Vertex AI generates the IDs of
+// the index and the index endpoint, so the commands look them up by their
+// display name, Instance.Name.
+type vectorSearch struct{}
+
+func init() { Register(vectorSearch{}) }
+
+func (vectorSearch) Name() string { return "vector-search" }
+
+func (vectorSearch) Create(i Instance) []Command {
+	endpoint := map[string]any{
+		"displayName":                 i.Name,
+		"privateServiceConnectConfig": pscConfig(i),
+	}
+	index := map[string]any{
+		"displayName":       i.Name,
+		"indexUpdateMethod": "STREAM_UPDATE",
+		"metadata": map[string]any{"config": map[string]any{
+			"dimensions":                2,
+			"approximateNeighborsCount": 1,
+			"algorithmConfig":           map[string]any{"bruteForceConfig": map[string]any{}},
+		}},
+	}
+	// The index and the endpoint are listed once their creation completes.
+	deploy := fmt.Sprintf(`set -e
+for _ in $(seq 1 60); do
+  endpoint=%s
+  index=%s
+  if [ -n "$endpoint" ] && [ -n "$index" ]; then
+    break
+  fi
+  sleep 30
+done
+gcloud ai index-endpoints deploy-index "$endpoint" --deployed-index-id=%s --index="$index" --display-name=%s --project=%s --region=%s`,
+		lookup("index-endpoints", i), lookup("indexes", i), deployedIndexID(i), i.Name, i.ProjectID, i.Region)
+	return []Command{
+		aiRequest(i, "POST", "indexEndpoints", endpoint),
+		aiRequest(i, "POST", "indexes", index),
+		{"bash", "-c", deploy},
+	}
+}
+
+func (vectorSearch) Delete(i Instance) []Command {
+	endpoint := lookup("index-endpoints", i)
+	index := lookup("indexes", i)
+	flags := fmt.Sprintf("--project=%s --region=%s --quiet", i.ProjectID, i.Region)
+	return []Command{
+		{"bash", "-c", fmt.Sprintf("gcloud ai index-endpoints undeploy-index %s --deployed-index-id=%s %s", endpoint, deployedIndexID(i), flags)},
+		{"bash", "-c", fmt.Sprintf("gcloud ai index-endpoints delete %s %s", endpoint, flags)},
+		{"bash", "-c", fmt.Sprintf("gcloud ai indexes delete %s %s", index, flags)},
+	}
+}
+
+// State prints DEPLOYED once the deployed index has a service attachment.
+func (vectorSearch) State(i Instance) Command {
+	return Command{"bash", "-c", fmt.Sprintf(`attachment=$(%s); echo "${attachment:+DEPLOYED}"`, attachmentScript(i))}
+}
+
+func (vectorSearch) ReadyState() string { return "DEPLOYED" }
+
+func (vectorSearch) Attachment(i Instance) Command {
+	return Command{"bash", "-c", attachmentScript(i)}
+}
+
+func (vectorSearch) Endpoint(_ Instance, attachment string) (string, any) {
+	return Target, attachment
+}
+
+// attachmentScript prints the service attachment of the deployed index.
+func attachmentScript(i Instance) string {
+	return fmt.Sprintf("gcloud ai index-endpoints describe %s --project=%s --region=%s --format='value(deployedIndexes[0].privateEndpoints.serviceAttachment)'",
+		lookup("index-endpoints", i), i.ProjectID, i.Region)
+}
+
+// lookup returns the shell command substitution printing the ID of the
+// index or index endpoint of the instance.
+func lookup(kind string, i Instance) string {
+	return fmt.Sprintf("$(gcloud ai %s list --project=%s --region=%s --filter=displayName=%s --format='value(name.basename())')",
+		kind, i.ProjectID, i.Region, i.Name)
+}
+
+// deployedIndexID returns the ID of the deployed index, which allows
+// letters, digits and underscores.
+func deployedIndexID(i Instance) string {
+	return strings.ReplaceAll(i.Name, "-", "_")
+}
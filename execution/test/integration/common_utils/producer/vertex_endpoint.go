@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package producer
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// vertexEndpoint is a Vertex AI online prediction endpoint with PSC
+// enabled, which the stage targets through its service attachment. gcloud
+// does not set the PSC config of an endpoint, so it is created with the
+// REST API, under the ID Instance.Name.
+type vertexEndpoint struct{}
+
+func init() { Register(vertexEndpoint{}) }
+
+func (vertexEndpoint) Name() string { return "vertex-endpoint" }
+
+func (vertexEndpoint) Create(i Instance) []Command {
+	body := map[string]any{
+		"displayName":                 i.Name,
+		"privateServiceConnectConfig": pscConfig(i),
+	}
+	return []Command{aiRequest(i, "POST", "endpoints?endpointId="+i.Name, body)}
+}
+
+func (vertexEndpoint) Delete(i Instance) []Command {
+	return []Command{{"gcloud", "ai", "endpoints", "delete", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--quiet"}}
+}
+
+func (vertexEndpoint) State(i Instance) Command {
+	return Command{"gcloud", "ai", "endpoints", "describe", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--format=value(privateServiceConnectConfig.enablePrivateServiceConnect)"}
+}
+
+func (vertexEndpoint) ReadyState() string { return "True" }
+
+func (vertexEndpoint) Attachment(i Instance) Command {
+	return Command{"gcloud", "ai", "endpoints", "describe", i.Name,
+		"--project=" + i.ProjectID, "--region=" + i.Region, "--format=value(privateServiceConnectConfig.serviceAttachment)"}
+}
+
+func (vertexEndpoint) Endpoint(_ Instance, attachment string) (string, any) {
+	return Target, attachment
+}
+
+// pscConfig is the PSC config of a Vertex AI endpoint or index endpoint.
+func pscConfig(i Instance) map[string]any {
+	allowlist := i.AllowedProjects
+	if allowlist == nil {
+		allowlist = []string{}
+	}
+	return map[string]any{"enablePrivateServiceConnect": true, "projectAllowlist": allowlist}
+}
+
+// aiRequest returns the command sending a request to the Vertex AI API of
+// the region of the instance, path being relative to its location.
+func aiRequest(i Instance, method, path string, body any) Command {
+	data, err := json.Marshal(body)
+	if err != nil {
+		panic(err) // Bodies are maps of strings, booleans and slices.
+	}
+	url := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s/%s", i.Region, i.ProjectID, i.Region, path)
+	return Command{"bash", "-c", fmt.Sprintf(
+		`curl -sSf -X %s -H "Authorization: Bearer $(gcloud auth print-access-token)" -H 'Content-Type: application/json' -d '%s' '%s'`,
+		method, data, url)}
+}
@@ -0,0 +1,399 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package workspace gives every test a private copy of the Terraform stage it
+// plans, so that tests and packages can run in parallel. Tests planning the
+// stage directory itself share its .terraform directory, lock file and plan
+// file, and corrupt them when they run at once.
+//
+// A test plans the copy rather than the stage:
+//
+//	ws := workspace.New(t, terraformDirectoryPath)
+//	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
+//		TerraformDir: ws.Dir,
+//		Vars:         ws.Vars(tfVars),
+//		EnvVars:      ws.EnvVars(),
+//		PlanFilePath: "./plan",
+//	})
+//
+// The stage and the local modules it calls, such as ../../modules/net-vpc,
+// are copied into a temporary directory removed after the test, keeping
+// their relative layout so that module sources resolve unchanged. Providers
+// are downloaded once into a plugin cache shared by every workspace.
+package workspace
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ConfigFolderVar is the variable of the stages holding the folder of their
+// YAML configuration.
+const ConfigFolderVar = "config_folder_path"
+
+// PluginCacheEnv is the environment variable Terraform reads the plugin cache
+// directory from. When it is set for the tests, workspaces use its directory.
+const PluginCacheEnv = "TF_PLUGIN_CACHE_DIR"
+
+// CLIConfigEnv is the environment variable Terraform reads the path of its
+// CLI configuration from.
+const CLIConfigEnv = "TF_CLI_CONFIG_FILE"
+
+// MirrorEnv is the environment variable holding the directory of an offline
+// mirror of the providers and remote modules of the stages, built by the
+// provider-mirror command of execution/tools. When it is set, workspaces
+// install everything from the mirror and never from the network.
+const MirrorEnv = "TEST_TERRAFORM_MIRROR"
+
+// localSource matches the source of a module block calling a local module.
+var localSource = regexp.MustCompile(`(?m)^\s*source\s*=\s*"(\.\.?/[^"]*)"`)
+
+// initStage initializes the stage copy in dir with the environment env. Tests
+// of the package replace it to run without Terraform.
+var initStage = defaultInit
+
+func defaultInit(dir string, env []string) error {
+	cmd := exec.Command("terraform", "init", "-input=false", "-backend=false", "-no-color")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("terraform init: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Workspace is the private copy of a stage of a test.
+type Workspace struct {
+	// Dir is the directory of the stage copy, the TerraformDir of the test.
+	Dir string
+	// StageDir is the absolute path of the stage copied.
+	StageDir string
+	// PluginCache is the plugin cache directory shared by the workspaces.
+	PluginCache string
+	// CLIConfig is the Terraform CLI configuration installing the providers
+	// from the mirror of MirrorEnv, empty without a mirror.
+	CLIConfig string
+}
+
+// New copies the stage at stageDir with Copy and initializes the copy once,
+// holding the lock of the plugin cache, as Terraform does not support
+// concurrent writes to the cache. The init of the test then finds every
+// provider in the cache. With a mirror in MirrorEnv, the copy gets the lock
+// file and the remote modules of the stage from the mirror.
+func New(t testing.TB, stageDir string) *Workspace {
+	t.Helper()
+	abs, err := filepath.Abs(stageDir)
+	if err != nil {
+		t.Fatalf("Failed to resolve %s: %v", stageDir, err)
+	}
+	cache, err := PluginCacheDir()
+	if err != nil {
+		t.Fatalf("Failed to create the plugin cache: %v", err)
+	}
+	w := &Workspace{Dir: Copy(t, abs), StageDir: abs, PluginCache: cache}
+	// The validation-coverage command of execution/tools reads this line to
+	// resolve the rule locations of the diagnostics of the test.
+	t.Logf("Copied stage %s to %s", abs, w.Dir)
+	if mirror := os.Getenv(MirrorEnv); mirror != "" {
+		if err := w.useMirror(mirror, t.TempDir()); err != nil {
+			t.Fatalf("Failed to use the mirror of %s: %v", MirrorEnv, err)
+		}
+	}
+
+	unlock, err := lockCache(cache)
+	if err != nil {
+		t.Fatalf("Failed to lock the plugin cache: %v", err)
+	}
+	defer unlock()
+	var env []string
+	for name, value := range w.EnvVars() {
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	if err := initStage(w.Dir, env); err != nil {
+		t.Fatalf("Failed to initialize the copy of %s: %v", stageDir, err)
+	}
+	return w
+}
+
+// Vars returns a copy of the variables of a test with a relative
+// config_folder_path, which Terraform resolves from the stage directory,
+// made absolute. vars is left unchanged, so tests may share it.
+func (w *Workspace) Vars(vars map[string]any) map[string]any {
+	out := make(map[string]any, len(vars))
+	for name, value := range vars {
+		out[name] = value
+	}
+	if dir, ok := out[ConfigFolderVar].(string); ok && dir != "" && !filepath.IsAbs(dir) {
+		out[ConfigFolderVar] = filepath.Join(w.StageDir, dir)
+	}
+	return out
+}
+
+// EnvVars returns the environment of the Terraform commands of the test,
+// setting the plugin cache and, with a mirror, the CLI configuration.
+func (w *Workspace) EnvVars() map[string]string {
+	env := map[string]string{PluginCacheEnv: w.PluginCache}
+	if w.CLIConfig != "" {
+		env[CLIConfigEnv] = w.CLIConfig
+	}
+	return env
+}
+
+// useMirror copies the lock file and the remote modules of the stage from the
+// mirror at dir into the copy, where terraform init finds them installed, and
+// writes into tmp a CLI configuration installing the providers from the
+// mirror. The mirror keeps a stage under its path relative to execution/.
+func (w *Workspace) useMirror(dir, tmp string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	stage, err := stagePath(w.StageDir)
+	if err != nil {
+		return err
+	}
+	mirrored := filepath.Join(dir, "stages", stage)
+	if _, err := os.Stat(filepath.Join(mirrored, ".terraform.lock.hcl")); err != nil {
+		return fmt.Errorf("%s is not in the mirror %s, build it with provider-mirror -build: %w", stage, dir, err)
+	}
+	if err := copyFile(filepath.Join(mirrored, ".terraform.lock.hcl"), filepath.Join(w.Dir, ".terraform.lock.hcl")); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(mirrored, "modules")); err == nil {
+		if err := copyDir(filepath.Join(mirrored, "modules"), filepath.Join(w.Dir, ".terraform", "modules"), false); err != nil {
+			return err
+		}
+	}
+	w.CLIConfig = filepath.Join(tmp, "terraformrc")
+	config := fmt.Sprintf(`provider_installation {
+  filesystem_mirror {
+    path    = %q
+    include = ["*/*/*"]
+  }
+}
+`, filepath.ToSlash(filepath.Join(dir, "providers")))
+	return os.WriteFile(w.CLIConfig, []byte(config), 0644)
+}
+
+// stagePath returns the path of a stage directory relative to the closest
+// execution directory holding it, such as 04-producer/CloudSQL.
+func stagePath(stageDir string) (string, error) {
+	for dir := filepath.Dir(stageDir); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == "execution" {
+			return filepath.Rel(dir, stageDir)
+		}
+	}
+	return "", fmt.Errorf("%s is not under an execution directory", stageDir)
+}
+
+// Copy copies the stage at stageDir and the local modules it calls, and the
+// ones they call, into a temporary directory removed after the test, and
+// returns the path of the stage copy. The directories keep their layout
+// relative to their closest common parent.
+func Copy(t testing.TB, stageDir string) string {
+	t.Helper()
+	dir, err := copyStage(stageDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to copy %s: %v", stageDir, err)
+	}
+	return dir
+}
+
+// ConfigDir returns a private copy of the configuration folder src, empty
+// when src does not exist, removed after the test. Tests writing their YAML
+// configuration write it there rather than into a folder other tests read.
+func ConfigDir(t testing.TB, src string) string {
+	t.Helper()
+	dst := t.TempDir()
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return dst
+	}
+	if err := copyDir(src, dst, false); err != nil {
+		t.Fatalf("Failed to copy %s: %v", src, err)
+	}
+	return dst
+}
+
+// PluginCacheDir returns the plugin cache shared by the workspaces, the
+// directory of TF_PLUGIN_CACHE_DIR or terraform-plugin-cache under the user
+// cache directory, creating it.
+func PluginCacheDir() (string, error) {
+	dir := os.Getenv(PluginCacheEnv)
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		dir = filepath.Join(base, "terraform-plugin-cache")
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return dir, os.MkdirAll(dir, 0755)
+}
+
+// copyStage copies the stage and its local modules under root and returns
+// the path of the stage copy.
+func copyStage(stageDir, root string) (string, error) {
+	stageDir, err := filepath.Abs(stageDir)
+	if err != nil {
+		return "", err
+	}
+	dirs, err := modules(stageDir)
+	if err != nil {
+		return "", err
+	}
+	base := commonParent(dirs)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(base, dir)
+		if err != nil {
+			return "", err
+		}
+		if err := copyDir(dir, filepath.Join(root, rel), true); err != nil {
+			return "", err
+		}
+	}
+	rel, err := filepath.Rel(base, stageDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, rel), nil
+}
+
+// modules returns the directory of the stage and of every local module it
+// calls, directly or not, sorted.
+func modules(stageDir string) ([]string, error) {
+	seen := map[string]bool{}
+	var visit func(dir string) error
+	visit = func(dir string) error {
+		if seen[dir] {
+			return nil
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		seen[dir] = true
+		files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			for _, m := range localSource.FindAllStringSubmatch(string(src), -1) {
+				if err := visit(filepath.Join(dir, filepath.FromSlash(m[1]))); err != nil {
+					return fmt.Errorf("module of %s: %w", file, err)
+				}
+			}
+		}
+		return nil
+	}
+	if err := visit(filepath.Clean(stageDir)); err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0, len(seen))
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// commonParent returns the closest directory holding every one of dirs.
+func commonParent(dirs []string) string {
+	base := dirs[0]
+	for _, dir := range dirs[1:] {
+		for base != filepath.Dir(base) && dir != base && !strings.HasPrefix(dir, base+string(filepath.Separator)) {
+			base = filepath.Dir(base)
+		}
+	}
+	return base
+}
+
+// copyDir copies the files of src into dst. With module set, it leaves out
+// the state, the .terraform directory, and the subdirectories holding
+// Terraform files, which are other stages or modules copied on their own
+// when called.
+func copyDir(src, dst string, module bool) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if module && p != src {
+				if d.Name() == ".terraform" {
+					return filepath.SkipDir
+				}
+				if tf, _ := filepath.Glob(filepath.Join(p, "*.tf")); len(tf) > 0 {
+					return filepath.SkipDir
+				}
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		if module && strings.HasPrefix(d.Name(), "terraform.tfstate") {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(p, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// cacheMu serializes the inits of the tests of a package; lockCache also
+// locks a file of the cache against other packages where it can.
+var cacheMu sync.Mutex
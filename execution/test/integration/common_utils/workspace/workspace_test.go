@@ -0,0 +1,241 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return root
+}
+
+func listTree(t *testing.T, root string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		files = append(files, filepath.ToSlash(rel))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to list %s: %v", root, err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+var stageTree = map[string]string{
+	"execution/04-producer/CloudSQL/sql.tf": `module "vpc" {
+  source = "../../../modules/net"
+}
+
+terraform {
+  required_providers {
+    google = {
+      source = "hashicorp/google"
+    }
+  }
+}
+`,
+	"execution/04-producer/CloudSQL/.terraform.lock.hcl":       "# lock\n",
+	"execution/04-producer/CloudSQL/.terraform/modules/a.json": "{}",
+	"execution/04-producer/CloudSQL/terraform.tfstate":         "{}",
+	"execution/04-producer/CloudSQL/config/instance.yaml":      "name: sql-1\n",
+	"execution/04-producer/CloudSQL/Nested/main.tf":            "# another stage\n",
+	"modules/net/main.tf":                                      "module \"subnet\" {\n  source = \"./subnet\"\n}\n",
+	"modules/net/subnet/main.tf":                               "# subnet\n",
+	"modules/unused/main.tf":                                   "# unused\n",
+}
+
+func TestCopy(t *testing.T) {
+	root := writeTree(t, stageTree)
+	dir := Copy(t, filepath.Join(root, "execution/04-producer/CloudSQL"))
+	if !strings.HasSuffix(filepath.ToSlash(dir), "/execution/04-producer/CloudSQL") {
+		t.Errorf("Failed to keep the stage path: got %s", dir)
+	}
+	copyRoot := filepath.Join(dir, "../../..")
+	want := []string{
+		"execution/04-producer/CloudSQL/.terraform.lock.hcl",
+		"execution/04-producer/CloudSQL/config/instance.yaml",
+		"execution/04-producer/CloudSQL/sql.tf",
+		"modules/net/main.tf",
+		"modules/net/subnet/main.tf",
+	}
+	if got := listTree(t, copyRoot); !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to copy the stage:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestCopyMissingModule(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"stage/main.tf": "module \"m\" {\n  source = \"../missing\"\n}\n",
+	})
+	if _, err := copyStage(filepath.Join(root, "stage"), t.TempDir()); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Failed to report the missing module: got %v", err)
+	}
+}
+
+func TestCopyRepositoryStage(t *testing.T) {
+	dir := Copy(t, "../../../../02-networking")
+	for _, file := range []string{"vpc.tf", "../../modules/net-vpc/main.tf"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("Failed to copy %s: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "NCC")); !os.IsNotExist(err) {
+		t.Errorf("Failed to leave out the nested stages: got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv(PluginCacheEnv, cache)
+	t.Setenv(MirrorEnv, "")
+	var mu sync.Mutex
+	var inits []string
+	initStage = func(dir string, env []string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if want := []string{PluginCacheEnv + "=" + cache}; !reflect.DeepEqual(env, want) {
+			t.Errorf("Failed to set the plugin cache: got %q, want %q", env, want)
+		}
+		inits = append(inits, dir)
+		return nil
+	}
+	t.Cleanup(func() { initStage = defaultInit })
+
+	root := writeTree(t, stageTree)
+	stageDir := filepath.Join(root, "execution/04-producer/CloudSQL")
+	var workspaces [4]*Workspace
+	t.Run("parallel", func(t *testing.T) {
+		for i := range workspaces {
+			t.Run("", func(t *testing.T) {
+				t.Parallel()
+				workspaces[i] = New(t, stageDir)
+			})
+		}
+	})
+
+	seen := map[string]bool{}
+	for _, w := range workspaces {
+		if seen[w.Dir] {
+			t.Errorf("Failed to give every test its own copy: %s twice", w.Dir)
+		}
+		seen[w.Dir] = true
+		if _, err := os.Stat(w.Dir); !os.IsNotExist(err) {
+			t.Errorf("Failed to remove %s after the test: %v", w.Dir, err)
+		}
+	}
+	if len(inits) != len(workspaces) {
+		t.Errorf("Failed to initialize every copy: got %d inits", len(inits))
+	}
+
+	w := workspaces[0]
+	if got := w.EnvVars(); !reflect.DeepEqual(got, map[string]string{PluginCacheEnv: cache}) {
+		t.Errorf("Failed to set the plugin cache: got %v", got)
+	}
+	vars := map[string]any{ConfigFolderVar: "../../test/unit/config", "deletion_protection": false}
+	got := w.Vars(vars)
+	want := map[string]any{ConfigFolderVar: filepath.Join(root, "execution/test/unit/config"), "deletion_protection": false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to rewrite config_folder_path: got %v, want %v", got, want)
+	}
+	if vars[ConfigFolderVar] != "../../test/unit/config" {
+		t.Errorf("Failed to leave the shared variables unchanged: got %v", vars)
+	}
+	abs := map[string]any{ConfigFolderVar: "/configs/sql"}
+	if got := w.Vars(abs); got[ConfigFolderVar] != "/configs/sql" {
+		t.Errorf("Failed to keep an absolute config_folder_path: got %v", got[ConfigFolderVar])
+	}
+}
+
+func TestMirror(t *testing.T) {
+	root := writeTree(t, stageTree)
+	mirror := writeTree(t, map[string]string{
+		"stages/04-producer/CloudSQL/.terraform.lock.hcl":  "# mirrored lock\n",
+		"stages/04-producer/CloudSQL/modules/modules.json": `{"Modules":[{"Key":"","Source":"","Dir":"."}]}`,
+		"stages/04-producer/CloudSQL/modules/sql/main.tf":  "# remote module\n",
+	})
+	t.Setenv(PluginCacheEnv, t.TempDir())
+	t.Setenv(MirrorEnv, mirror)
+	var env []string
+	initStage = func(_ string, e []string) error {
+		env = e
+		return nil
+	}
+	t.Cleanup(func() { initStage = defaultInit })
+
+	w := New(t, filepath.Join(root, "execution/04-producer/CloudSQL"))
+	if got := listTree(t, filepath.Join(w.Dir, ".terraform")); !reflect.DeepEqual(got, []string{"modules/modules.json", "modules/sql/main.tf"}) {
+		t.Errorf("Failed to install the modules of the mirror: got %q", got)
+	}
+	if lock, err := os.ReadFile(filepath.Join(w.Dir, ".terraform.lock.hcl")); err != nil || string(lock) != "# mirrored lock\n" {
+		t.Errorf("Failed to use the lock file of the mirror: got %q, %v", lock, err)
+	}
+	if got := w.EnvVars()[CLIConfigEnv]; got != w.CLIConfig || got == "" {
+		t.Errorf("Failed to set %s: got %q", CLIConfigEnv, got)
+	}
+	config, err := os.ReadFile(w.CLIConfig)
+	if err != nil {
+		t.Fatalf("Failed to read the CLI configuration: %v", err)
+	}
+	if !strings.Contains(string(config), `"`+filepath.ToSlash(filepath.Join(mirror, "providers"))+`"`) {
+		t.Errorf("Failed to point the CLI configuration at the mirror:\n%s", config)
+	}
+	if len(env) != 2 || env[0] != CLIConfigEnv+"="+w.CLIConfig {
+		t.Errorf("Failed to initialize with the mirror: got env %q", env)
+	}
+
+	other := &Workspace{Dir: t.TempDir(), StageDir: filepath.Join(root, "execution/04-producer/AlloyDB")}
+	if err := other.useMirror(mirror, t.TempDir()); err == nil || !strings.Contains(err.Error(), "04-producer/AlloyDB is not in the mirror") {
+		t.Errorf("Failed to report a stage missing from the mirror: got %v", err)
+	}
+	outside := &Workspace{Dir: t.TempDir(), StageDir: filepath.Join(root, "stages/CloudSQL")}
+	if err := outside.useMirror(mirror, t.TempDir()); err == nil {
+		t.Errorf("Failed to report a stage outside of an execution directory")
+	}
+}
+
+func TestConfigDir(t *testing.T) {
+	root := writeTree(t, stageTree)
+	dir := ConfigDir(t, filepath.Join(root, "execution/04-producer/CloudSQL/config"))
+	if got := listTree(t, dir); !reflect.DeepEqual(got, []string{"instance.yaml"}) {
+		t.Errorf("Failed to copy the config folder: got %q", got)
+	}
+	if got := listTree(t, ConfigDir(t, filepath.Join(root, "missing"))); len(got) != 0 {
+		t.Errorf("Failed to create an empty config folder: got %q", got)
+	}
+}
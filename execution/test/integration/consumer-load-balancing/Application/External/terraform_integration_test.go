@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -109,15 +110,18 @@ Resources are cleaned up after the test to ensure no lingering infrastructure re
 */
 
 func TestCreateLoadBalancers(t *testing.T) {
-	createLoadBalancerYAML(t) // Create YAML configurations
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createLoadBalancerYAML(t, configDir) // Create YAML configurations
 
 	tfVars := map[string]interface{}{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -169,7 +173,7 @@ func TestCreateLoadBalancers(t *testing.T) {
 			}
 
 			t.Logf("Backend service '%s' exists.", backendServiceName)
-			verifyLoadBalancerConfiguration(t, lbName, lbNameToYaml, terraformOptions)
+			verifyLoadBalancerConfiguration(t, configDir, lbName, lbNameToYaml, terraformOptions)
 			break
 		}
 	}
@@ -236,10 +240,10 @@ func createManagedInstanceGroup(t *testing.T) {
 /*
 createLoadBalancerYAML generates YAML configuration files for health checks associated
 with a Managed Instance Group. It creates both minimal and maximal health check configurations
-and writes them to specified files. Errors during marshaling or file operations are logged.
+and writes them to files in configDir. Errors during marshaling or file operations are logged.
 */
 
-func createLoadBalancerYAML(t *testing.T) {
+func createLoadBalancerYAML(t *testing.T, configDir string) {
 	t.Log("========= YAML Files for Health Checks =========")
 
 	minimalHC := struct {
@@ -310,7 +314,6 @@ func createLoadBalancerYAML(t *testing.T) {
 		return
 	}
 
-	configDir := "config"
 	minimalFilePath := filepath.Join(configDir, "instance1.yaml")
 	maximalFilePath := filepath.Join(configDir, "instance2.yaml")
 
@@ -334,14 +337,14 @@ func createLoadBalancerYAML(t *testing.T) {
 
 /*
 verifyLoadBalancerConfiguration checks the configuration of a specified load balancer
-against expected values defined in YAML files. It reads the appropriate YAML file based
-on the load balancer name, unmarshals its content, and verifies the existence and
+against expected values defined in YAML files. It reads the appropriate YAML file of configDir
+based on the load balancer name, unmarshals its content, and verifies the existence and
 properties of the load balancer and its associated backend services in Terraform output.
 It also checks health checks, self-links, and Managed Instance Groups (MIGs) for
 correctness, logging any discrepancies found.
 */
 
-func verifyLoadBalancerConfiguration(t *testing.T, lbName string, lbNameToYaml map[string]string, terraformOptions *terraform.Options) {
+func verifyLoadBalancerConfiguration(t *testing.T, configDir, lbName string, lbNameToYaml map[string]string, terraformOptions *terraform.Options) {
 	// Determine which YAML file to use based on lbName
 	yamlFileName, ok := lbNameToYaml[lbName]
 	if !ok {
@@ -349,7 +352,7 @@ func verifyLoadBalancerConfiguration(t *testing.T, lbName string, lbNameToYaml m
 		return
 	}
 
-	yamlFilePath := filepath.Join(configFolderPath, yamlFileName)
+	yamlFilePath := filepath.Join(configDir, yamlFileName)
 	t.Logf("Reading the YAML for %s from file %s", lbName, yamlFilePath)
 
 	yamlFile, err := os.ReadFile(yamlFilePath)
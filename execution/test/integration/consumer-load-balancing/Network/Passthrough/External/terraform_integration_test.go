@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
@@ -169,15 +170,18 @@ func TestCreateNetworkLoadBalancer(t *testing.T) {
 		t.Fatal("TF_VAR_project_id must be set as an environment variable.")
 	}
 
-	createNetworkLoadBalancerYAML(t)
+	ws := workspace.New(t, nlbTerraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, nlbConfigFolderPath)
+	createNetworkLoadBalancerYAML(t, configDir)
 
 	tfVars := map[string]interface{}{
-		"config_folder_path": nlbConfigFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:         nlbTerraformDirectoryPath,
-		Vars:                 tfVars,
+		TerraformDir:         ws.Dir,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -253,7 +257,7 @@ func TestCreateNetworkLoadBalancer(t *testing.T) {
 			continue
 		}
 
-		verifyNetworkLoadBalancerConfiguration(t, lbNameFromOutput, yamlFileName, terraformOptions)
+		verifyNetworkLoadBalancerConfiguration(t, configDir, lbNameFromOutput, yamlFileName, terraformOptions)
 
 		lbFwdRuleIPs := gjson.Parse(nlbForwardingRuleAddresses).Get(lbNameFromOutput)
 		if !lbFwdRuleIPs.Exists() {
@@ -377,10 +381,10 @@ func TestCreateNetworkLoadBalancer(t *testing.T) {
 	}
 }
 
-func createNetworkLoadBalancerYAML(t *testing.T) {
+func createNetworkLoadBalancerYAML(t *testing.T, configDir string) {
 	t.Log("========= Generating YAML Files for Network Load Balancers =========")
-	if err := os.MkdirAll(nlbConfigFolderPath, 0755); err != nil {
-		t.Fatalf("Failed to create NLB config directory %s: %v", nlbConfigFolderPath, err)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("Failed to create NLB config directory %s: %v", configDir, err)
 	}
 
 	// 1. Lite NLB Configuration (Regional MIG)
@@ -400,7 +404,7 @@ func createNetworkLoadBalancerYAML(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error marshaling lite NLB config: %v", err)
 	}
-	minimalFilePath := filepath.Join(nlbConfigFolderPath, minimalNLBYamlFile)
+	minimalFilePath := filepath.Join(configDir, minimalNLBYamlFile)
 	if err := os.WriteFile(minimalFilePath, yamlMinimalData, 0644); err != nil {
 		t.Fatalf("Unable to write lite NLB config to %s: %v", minimalFilePath, err)
 	}
@@ -456,7 +460,7 @@ func createNetworkLoadBalancerYAML(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error marshaling expanded NLB config: %v", err)
 	}
-	maximalFilePath := filepath.Join(nlbConfigFolderPath, maximalNLBYamlFile)
+	maximalFilePath := filepath.Join(configDir, maximalNLBYamlFile)
 	if err := os.WriteFile(maximalFilePath, yamlMaximalData, 0644); err != nil {
 		t.Fatalf("Unable to write expanded NLB config to %s: %v", maximalFilePath, err)
 	}
@@ -495,17 +499,17 @@ func createNetworkLoadBalancerYAML(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error marshaling hybrid NLB config: %v", err)
 	}
-	hybridFilePath := filepath.Join(nlbConfigFolderPath, hybridNLBYamlFile)
+	hybridFilePath := filepath.Join(configDir, hybridNLBYamlFile)
 	if err := os.WriteFile(hybridFilePath, yamlHybridData, 0644); err != nil {
 		t.Fatalf("Unable to write hybrid NLB config to %s: %v", hybridFilePath, err)
 	}
 	t.Logf("Created Hybrid NLB YAML config at %s:\n%s", hybridFilePath, string(yamlHybridData))
 }
 
-func verifyNetworkLoadBalancerConfiguration(t *testing.T, lbNameFromOutput string, yamlFileName string, terraformOptions *terraform.Options) {
+func verifyNetworkLoadBalancerConfiguration(t *testing.T, configDir, lbNameFromOutput string, yamlFileName string, terraformOptions *terraform.Options) {
 	t.Logf("Verifying NLB configuration for: %s using YAML: %s", lbNameFromOutput, yamlFileName)
 
-	yamlFilePath := filepath.Join(nlbConfigFolderPath, yamlFileName)
+	yamlFilePath := filepath.Join(configDir, yamlFileName)
 	yamlFileContent, err := os.ReadFile(yamlFilePath)
 	if err != nil {
 		t.Errorf("Error reading YAML file %s for NLB %s: %v", yamlFilePath, lbNameFromOutput, err)
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/gcp"
 	"github.com/gruntwork-io/terratest/modules/retry"
 	"github.com/gruntwork-io/terratest/modules/shell"
@@ -44,15 +45,8 @@ var (
 
 	// This points to the Terraform code that will be EXECUTED.
 	ilbTerraformDirectoryPath = filepath.Join(projectRoot, "execution/07-consumer-load-balancing/Network/Passthrough/Internal")
-
-	// This points to the 'config' folder where this test's YAML files are GENERATED.
-	ilbConfigFolderPath = filepath.Join(projectRoot, "execution/test/integration/consumer-load-balancing/Network/Passthrough/Internal/config")
 )
 
-var tfVarsINLB = map[string]interface{}{
-	"config_folder_path": ilbConfigFolderPath,
-}
-
 var (
 	// ilbProjectID is set by the TF_VAR_project_id environment variable.
 	ilbProjectID = os.Getenv("TF_VAR_project_id")
@@ -137,13 +131,15 @@ for the Internal Network Load Balancer module with specified variables.
 It expects an exit code of 2, indicating that changes are planned.
 */
 func TestInitAndPlanRunWithTfVarsINLB(t *testing.T) {
-	createInternalLoadBalancerYAML(t)
+	ws := workspace.New(t, ilbTerraformDirectoryPath)
+	configDir := createInternalLoadBalancerYAML(t)
 	createVPC(t, ilbProjectID, ilbNetworkName, ilbRegion, ilbSubnetName, ilbSubnetCidr)
 	defer deleteVPC(t, ilbProjectID, ilbNetworkName, ilbRegion, ilbSubnetName)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: ilbTerraformDirectoryPath,
-		Vars:         tfVarsINLB,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(map[string]interface{}{"config_folder_path": configDir}),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan-inlb", // Use a distinct plan file name
@@ -176,13 +172,15 @@ Typically, this includes:
 Total = 3 resources per NLB instance.
 */
 func TestResourcesCountINLB(t *testing.T) {
-	createInternalLoadBalancerYAML(t)
+	ws := workspace.New(t, ilbTerraformDirectoryPath)
+	configDir := createInternalLoadBalancerYAML(t)
 	createVPC(t, ilbProjectID, ilbNetworkName, ilbRegion, ilbSubnetName, ilbSubnetCidr)
 	defer deleteVPC(t, ilbProjectID, ilbNetworkName, ilbRegion, ilbSubnetName)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: ilbTerraformDirectoryPath,
-		Vars:         tfVarsINLB,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(map[string]interface{}{"config_folder_path": configDir}),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan-inlb", // Use a distinct plan file name
@@ -216,7 +214,8 @@ Internal Load Balancer resources in the Terraform plan match the expected addres
 derived from YAML configuration files. It looks for module instances named 'module.inlb_passthrough'.
 */
 func TestTerraformModuleINLBResourceAddressListMatch(t *testing.T) {
-	createInternalLoadBalancerYAML(t)
+	ws := workspace.New(t, ilbTerraformDirectoryPath)
+	configDir := createInternalLoadBalancerYAML(t)
 	createVPC(t, ilbProjectID, ilbNetworkName, ilbRegion, ilbSubnetName, ilbSubnetCidr)
 	defer deleteVPC(t, ilbProjectID, ilbNetworkName, ilbRegion, ilbSubnetName)
 
@@ -228,8 +227,9 @@ func TestTerraformModuleINLBResourceAddressListMatch(t *testing.T) {
 
 	// Initialize Terraform and generate a plan.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: ilbTerraformDirectoryPath,
-		Vars:         tfVarsINLB,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(map[string]interface{}{"config_folder_path": configDir}),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan-inlb-addressmatch", // Use a distinct plan file name
@@ -268,7 +268,8 @@ func TestCreateInternalLoadBalancer(t *testing.T) {
 	}
 
 	// 1. SETUP: Generate dynamic YAML configs for different test cases.
-	createInternalLoadBalancerYAML(t)
+	ws := workspace.New(t, ilbTerraformDirectoryPath)
+	configDir := createInternalLoadBalancerYAML(t)
 
 	// SETUP: Create a GCS bucket for test results
 	gcp.CreateStorageBucketE(t, ilbProjectID, ilbTestBucketName, bucketAttrs)
@@ -293,8 +294,9 @@ func TestCreateInternalLoadBalancer(t *testing.T) {
 
 	// 3. EXECUTION: Run terraform init and apply.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir:         ilbTerraformDirectoryPath,
-		Vars:                 map[string]interface{}{"config_folder_path": ilbConfigFolderPath},
+		TerraformDir:         ws.Dir,
+		Vars:                 ws.Vars(map[string]interface{}{"config_folder_path": configDir}),
+		EnvVars:              ws.EnvVars(),
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -343,15 +345,12 @@ func TestCreateInternalLoadBalancer(t *testing.T) {
 }
 
 // YAML Generation Function
-// createInternalLoadBalancerYAML generates the YAML config files for the ILB tests.
-func createInternalLoadBalancerYAML(t *testing.T) {
+// createInternalLoadBalancerYAML generates the YAML config files for the ILB tests
+// into a private folder of the test, removed after it, and returns the folder.
+func createInternalLoadBalancerYAML(t *testing.T) string {
 	t.Log("========= Generating YAML Files for Internal Load Balancers =========")
 
-	err := os.RemoveAll(ilbConfigFolderPath)
-	assert.NoError(t, err, "Failed to remove existing ILB config directory")
-
-	err = os.MkdirAll(ilbConfigFolderPath, 0755)
-	assert.NoError(t, err, "Failed to create ILB config directory %s", ilbConfigFolderPath)
+	configDir := t.TempDir()
 
 	minimalILBCfg := NetworkLoadBalancerConfig{
 		Name:       ilbNamesToTest[0],
@@ -365,7 +364,7 @@ func createInternalLoadBalancerYAML(t *testing.T) {
 	}
 	yamlMinimalData, err := yaml.Marshal(&minimalILBCfg)
 	assert.NoError(t, err, "Error marshaling lite ILB config")
-	minimalFilePath := filepath.Join(ilbConfigFolderPath, minimalILBYamlFile)
+	minimalFilePath := filepath.Join(configDir, minimalILBYamlFile)
 	err = os.WriteFile(minimalFilePath, yamlMinimalData, 0644)
 	assert.NoError(t, err, "Unable to write lite ILB config")
 	t.Logf("Created Lite ILB YAML config at %s", minimalFilePath)
@@ -401,10 +400,11 @@ func createInternalLoadBalancerYAML(t *testing.T) {
 	}
 	yamlMaximalData, err := yaml.Marshal(&maximalILBCfg)
 	assert.NoError(t, err, "Error marshaling expanded ILB config")
-	maximalFilePath := filepath.Join(ilbConfigFolderPath, maximalILBYamlFile)
+	maximalFilePath := filepath.Join(configDir, maximalILBYamlFile)
 	err = os.WriteFile(maximalFilePath, yamlMaximalData, 0644)
 	assert.NoError(t, err, "Unable to write expanded ILB config")
 	t.Logf("Created Expanded ILB YAML config at %s", maximalFilePath)
+	return configDir
 }
 
 // Verification Functions
@@ -520,7 +520,7 @@ EOF
 nohup python3 /echo_server.py > /dev/null 2>&1 &`
 
 	scriptFileName := "startup-script.sh"
-	scriptPath := filepath.Join(ilbConfigFolderPath, scriptFileName)
+	scriptPath := filepath.Join(t.TempDir(), scriptFileName)
 	err := os.WriteFile(scriptPath, []byte(startupScript), 0755)
 	assert.NoError(t, err, "Failed to write startup script to file")
 
@@ -619,7 +619,7 @@ gcloud storage cp /tmp/result.txt gs://${BUCKET_NAME}/${RESULT_OBJECT_NAME}.txt
 `, lbIpToTest, bucketName, vmName, apachePort)
 
 	scriptFileName := fmt.Sprintf("startup-script-%s.sh", vmName)
-	scriptPath := filepath.Join(ilbConfigFolderPath, scriptFileName)
+	scriptPath := filepath.Join(t.TempDir(), scriptFileName)
 	err := os.WriteFile(scriptPath, []byte(startupScript), 0755)
 	assert.NoError(t, err, "Failed to write startup script to file")
 
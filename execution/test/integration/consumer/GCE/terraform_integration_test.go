@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -60,17 +61,20 @@ type VMInstanceConfig struct {
 }
 
 func TestCreateVMInstances(t *testing.T) {
-	createConfigYAML(t) // Use the updated createConfigYAML for GCE
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAML(t, configDir) // Use the updated createConfigYAML for GCE
 
 	// Terraform Variables (GCE-Specific)
 	tfVars := map[string]any{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	// Terraform Options
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -105,7 +109,7 @@ func TestCreateVMInstances(t *testing.T) {
 
 			if status == "RUNNING" {
 				// Verify Instance Configuration (against YAML)
-				yamlFile, err := os.ReadFile(filepath.Join(configFolderPath, "instance1.yaml"))
+				yamlFile, err := os.ReadFile(filepath.Join(configDir, "instance1.yaml"))
 				if err != nil {
 					t.Errorf("Error reading YAML file: %s", err)
 					break
@@ -240,9 +244,9 @@ func deleteVPC(t *testing.T, projectID string, networkName string) {
 
 /*
 createConfigYAML is a helper function which creates the configigration YAML file
-for an MRC instance.
+for a GCE instance in configDir.
 */
-func createConfigYAML(t *testing.T) {
+func createConfigYAML(t *testing.T, configDir string) {
 	t.Log("========= YAML File =========")
 
 	// Create a GCE-specific instance configuration
@@ -261,15 +265,8 @@ func createConfigYAML(t *testing.T) {
 		t.Errorf("Error while marshaling: %v", err)
 	}
 
-	// Specify a directory for config files (adjust if needed)
-	configDir := "config"
 	filePath := filepath.Join(configDir, "instance1.yaml") // Construct file path
 
-	// Create the config directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		t.Errorf("Failed to create config directory: %v", err)
-	}
-
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
 	err = os.WriteFile(filePath, []byte(yamlData), 0644) // Use 0644 for file permissions
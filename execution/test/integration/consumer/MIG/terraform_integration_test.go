@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -78,16 +79,19 @@ using Terraform. It verifies that the resources are correctly provisioned, check
 and ensures that configurations such as instance group names, zones, and autoscaler settings match expected values.
 */
 func TestMIGs(t *testing.T) {
-	createConfigYAML(t) // Use the updated createConfigYAML for MIG
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAML(t, configDir) // Use the updated createConfigYAML for MIG
 
 	tfVars := map[string]interface{}{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	// Terraform Options
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:         tfVars,
-		TerraformDir: terraformDirectoryPath,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
+		TerraformDir: ws.Dir,
 		Reconfigure:  true,
 		Lock:         true,
 		NoColor:      true,
@@ -141,7 +145,7 @@ func TestMIGs(t *testing.T) {
 					Args:    []string{"compute", "instance-groups", "managed", "describe", instanceName, "--region", region, "--project=" + projectID, "--format=json"},
 				})
 
-				yamlFilePath := filepath.Join(configFolderPath, yaml_file_name)
+				yamlFilePath := filepath.Join(configDir, yaml_file_name)
 				yamlFile, err := os.ReadFile(yamlFilePath)
 				if err != nil {
 					t.Fatalf("Error reading YAML file at %s: %s", yamlFilePath, err)
@@ -206,8 +210,8 @@ func TestMIGs(t *testing.T) {
 
 }
 
-// createConfigYAML creates the configuration YAML file for a MIG instance.
-func createConfigYAML(t *testing.T) {
+// createConfigYAML creates the configuration YAML file for a MIG instance in configDir.
+func createConfigYAML(t *testing.T, configDir string) {
 	t.Log("========= YAML File =========")
 
 	migInstance := MIGConfig{
@@ -229,15 +233,9 @@ func createConfigYAML(t *testing.T) {
 		t.Errorf("Error while marshaling: %v", err)
 	}
 
-	configDir := "config" // Specify a directory for config files (adjust if needed)
-
 	// Construct file path
 	filePath := filepath.Join(configDir, yaml_file_name)
 
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		t.Fatalf("Failed to create config directory: %v", err)
-	}
-
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
 	err = os.WriteFile(filePath, []byte(yamlData), 0644) // Use 0644 for file permissions
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -154,7 +155,7 @@ func getBaseAppEngineConfig(t *testing.T) AppEngineConfig {
 	}
 }
 
-func createConfigYAML(t *testing.T, currentSaEmail string, currentGcsSourceURL string) []AppEngineConfig {
+func createConfigYAML(t *testing.T, configDir string, currentSaEmail string, currentGcsSourceURL string) []AppEngineConfig {
 	t.Log("Generating YAML configuration for a single service, with dynamic SA and GCS URL...")
 	baseConfig := getBaseAppEngineConfig(t)
 	service1Config := baseConfig
@@ -181,18 +182,12 @@ func createConfigYAML(t *testing.T, currentSaEmail string, currentGcsSourceURL s
 		"specific-to": "service1",
 	}
 	servicesToCreate := []AppEngineConfig{service1Config}
-	if err := os.RemoveAll(configFolderPath); err != nil && !os.IsNotExist(err) {
-		t.Fatalf("Failed to clean config directory %s: %v", configFolderPath, err)
-	}
-	if err := os.MkdirAll(configFolderPath, 0755); err != nil {
-		t.Fatalf("Failed to create config directory %s: %v", configFolderPath, err)
-	}
 	serviceCfg := servicesToCreate[0]
 	yamlData, err := yaml.Marshal(&serviceCfg)
 	if err != nil {
 		t.Fatalf("Error marshaling YAML for service %s: %v", serviceCfg.Service, err)
 	}
-	filePath := filepath.Join(configFolderPath, fmt.Sprintf("%s.yaml", serviceCfg.Service))
+	filePath := filepath.Join(configDir, fmt.Sprintf("%s.yaml", serviceCfg.Service))
 	err = os.WriteFile(filePath, yamlData, 0644)
 	if err != nil {
 		t.Fatalf("Error writing YAML file for service %s at %s: %v", serviceCfg.Service, filePath, err)
@@ -238,14 +233,16 @@ func TestCreateAppEngine(t *testing.T) {
 	}
 	defer common_utils.DeleteGcsObjects(t, gcsBucketName, "")
 	t.Logf("App source uploaded to: %s", gcsSourceURL)
-	generatedConfigs := createConfigYAML(t, appEngineDefaultSA, gcsSourceURL)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	generatedConfigs := createConfigYAML(t, configDir, appEngineDefaultSA, gcsSourceURL)
 	if len(generatedConfigs) == 0 {
 		t.Fatal("No YAML configurations were generated.")
 	}
 
-	tfVars := map[string]interface{}{"config_folder_path": configFolderPath}
+	tfVars := map[string]interface{}{"config_folder_path": configDir}
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars: tfVars, TerraformDir: terraformDirectoryPath, Reconfigure: true, Lock: true, NoColor: true, SetVarsAfterVarFiles: true,
+		Vars: ws.Vars(tfVars), EnvVars: ws.EnvVars(), TerraformDir: ws.Dir, Reconfigure: true, Lock: true, NoColor: true, SetVarsAfterVarFiles: true,
 	})
 
 	common_utils.CreateVPCSubnets(t, projectID, networkName, subnetName, region)
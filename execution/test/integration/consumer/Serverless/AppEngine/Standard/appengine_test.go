@@ -27,6 +27,7 @@ import (
 
 	// Import the common_utils package
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
@@ -112,7 +113,8 @@ func TestAppEngineStandardIntegration(t *testing.T) {
 	if projectID == "" {
 		t.Fatal("TF_VAR_project_id environment variable must be set")
 	}
-	testConfigFolderPath := configFolderPath
+	ws := workspace.New(t, terraformDirectoryPath)
+	testConfigFolderPath := workspace.ConfigDir(t, configFolderPath)
 	testGcsObjectPathPrefix := fmt.Sprintf("app-test-%s", uniqueID)
 
 	t.Logf("Ensuring App Engine application exists in project '%s' for region '%s'...", projectID, defaultRegion)
@@ -135,8 +137,9 @@ func TestAppEngineStandardIntegration(t *testing.T) {
 
 	tfVars := map[string]interface{}{"config_folder_path": testConfigFolderPath}
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		NoColor:      true,
@@ -18,6 +18,7 @@ package integrationtest
 
 import (
 	"fmt"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v2"
@@ -59,17 +60,20 @@ type CloudRunServiceStruct struct {
 }
 
 func TestCreateCloudRunService(t *testing.T) {
-	createConfigYAML(t)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, "config")
+	createConfigYAML(t, configDir)
 	var (
 		tfVars = map[string]any{
-			"config_folder_path": configFolderPath,
+			"config_folder_path": configDir,
 		}
 	)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
 		// Set the path to the Terraform code that will be tested.
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -116,9 +120,9 @@ func TestCreateCloudRunService(t *testing.T) {
 }
 
 /*
-createConfigYAML is a helper function which creates the configuration YAML file.
+createConfigYAML is a helper function which creates the configuration YAML file in configDir.
 */
-func createConfigYAML(t *testing.T) {
+func createConfigYAML(t *testing.T, configDir string) {
 	t.Log("========= YAML File =========")
 
 	containerNameList := ContainerNameStruct{
@@ -138,7 +142,7 @@ func createConfigYAML(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error while marshallaing %v", err)
 	}
-	filePath := fmt.Sprintf("%s/%s", "config", "instance1.yaml")
+	filePath := fmt.Sprintf("%s/%s", configDir, "instance1.yaml")
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
 	err = os.WriteFile(filePath, []byte(yamlData), 0666)
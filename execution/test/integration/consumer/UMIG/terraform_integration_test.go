@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -76,21 +77,19 @@ using Terraform. It verifies that the resources are correctly provisioned, check
 and ensures that configurations such as instance group names, zones, and named ports match expected values.
 */
 func TestUMIGs(t *testing.T) {
-	// Ensure config folder exists
-	if err := os.MkdirAll(configFolderPath, 0755); err != nil {
-		t.Fatalf("Failed to create config directory at %s: %v", configFolderPath, err)
-	}
-
-	createConfigYAML(t) // Create the UMIG configuration YAML
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAML(t, configDir) // Create the UMIG configuration YAML
 
 	tfVars := map[string]interface{}{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	// Terraform Options
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:         tfVars,
-		TerraformDir: terraformDirectoryPath,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
+		TerraformDir: ws.Dir,
 		Reconfigure:  true,
 		Lock:         true,
 		NoColor:      true,
@@ -110,7 +109,6 @@ func TestUMIGs(t *testing.T) {
 	defer deleteSubnet(t, projectID, subnetName, region)
 	defer deleteVMInstances(t, projectID, zone, instanceNames)
 	defer terraform.Destroy(t, terraformOptions)
-	defer deleteUMIGConfigYAML(t)
 
 	// Apply Terraform
 	terraform.InitAndApply(t, terraformOptions)
@@ -138,7 +136,7 @@ func TestUMIGs(t *testing.T) {
 	t.Logf("Found UMIG module key: %s", umigModuleKey)
 
 	// Verify the created UMIG
-	yamlFilePath := filepath.Join(configFolderPath, yaml_file_name)
+	yamlFilePath := filepath.Join(configDir, yaml_file_name)
 	yamlFile, err := os.ReadFile(yamlFilePath)
 	if err != nil {
 		t.Fatalf("Error reading YAML file at %s: %s", yamlFilePath, err)
@@ -237,8 +235,8 @@ func TestUMIGs(t *testing.T) {
 	t.Log("Confirmed instances in UMIG output match.")
 }
 
-// createConfigYAML creates the configuration YAML file for a UMIG instance.
-func createConfigYAML(t *testing.T) {
+// createConfigYAML creates the configuration YAML file for a UMIG instance in configDir.
+func createConfigYAML(t *testing.T, configDir string) {
 	t.Log("========= Creating UMIG YAML File =========")
 
 	umigInstance := UMIGConfig{
@@ -260,7 +258,7 @@ func createConfigYAML(t *testing.T) {
 	}
 
 	// Construct file path
-	filePath := filepath.Join(configFolderPath, yaml_file_name)
+	filePath := filepath.Join(configDir, yaml_file_name)
 
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
@@ -270,16 +268,6 @@ func createConfigYAML(t *testing.T) {
 	}
 }
 
-// deleteUMIGConfigYAML deletes the generated UMIG configuration YAML file.
-func deleteUMIGConfigYAML(t *testing.T) {
-	filePath := filepath.Join(configFolderPath, yaml_file_name)
-	if err := os.Remove(filePath); err != nil {
-		t.Logf("Warning: Failed to delete UMIG config YAML file %s: %v", filePath, err)
-	} else {
-		t.Logf("Successfully deleted UMIG config YAML file: %s", filePath)
-	}
-}
-
 /*
 createVPC creates the VPC before the test execution.
 */
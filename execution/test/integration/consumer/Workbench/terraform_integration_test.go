@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -71,16 +72,19 @@ func TestWorkbenchWithBigQueryConnectivity(t *testing.T) {
 	if projectID == "" {
 		t.Fatal("TF_VAR_project_id environment variable is not set.")
 	}
-	createConfigYAML(t, filepath.Join(configFolderPath, yaml_file_name))
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAML(t, filepath.Join(configDir, yaml_file_name))
 
 	tfVars := map[string]interface{}{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	// Terraform Options
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:         tfVars,
-		TerraformDir: terraformDirectoryPath,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
+		TerraformDir: ws.Dir,
 		Reconfigure:  true,
 		Lock:         true,
 		NoColor:      true,
@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
@@ -79,10 +80,13 @@ func TestPacketMirroringIntegration(t *testing.T) {
 	associateFirewallPolicy(t, projectID, consumerVPC, consumerFwPolicyName)
 	defer deleteFirewallPolicyAssociation(t, projectID, consumerVPC, consumerFwPolicyName)
 	t.Log("Applying Packet Mirroring Terraform configuration...")
-	createTestYAML(t, projectID, instanceSuffix)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createTestYAML(t, configDir, projectID, instanceSuffix)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         map[string]any{"config_folder_path": configFolderPath},
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(map[string]any{"config_folder_path": configDir}),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		NoColor:      true,
 	})
@@ -136,7 +140,7 @@ func TestPacketMirroringIntegration(t *testing.T) {
 	t.Log("--- Overall Test Successful: All resources created and validated. ---")
 }
 
-func createTestYAML(t *testing.T, projectID, suffix string) {
+func createTestYAML(t *testing.T, configDir, projectID, suffix string) {
 	dgName := "integ-test-dg-" + suffix
 	egName := "integ-test-eg-" + suffix
 	depAName := "integ-test-dep-a-" + suffix
@@ -170,9 +174,7 @@ func createTestYAML(t *testing.T, projectID, suffix string) {
 	}
 	yamlData, err := yaml.Marshal(&config)
 	assert.NoError(t, err)
-	err = os.MkdirAll(configFolderPath, 0755)
-	assert.NoError(t, err)
-	filePath := filepath.Join(configFolderPath, "instance.yaml")
+	filePath := filepath.Join(configDir, "instance.yaml")
 	err = os.WriteFile(filePath, yamlData, 0644)
 	assert.NoError(t, err)
 	t.Logf("Created test YAML config file: %s", filePath)
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -77,13 +78,16 @@ func TestCreatePacketMirroringRule(t *testing.T) {
 	defer common_utils.DeleteFirewallPolicy(t, projectID, fwPolicyName)
 
 	securityProfileGroupPath := fmt.Sprintf("organizations/%s/locations/global/securityProfileGroups/%s", orgID, spgName)
-	createPMRuleConfigYAML(t, projectID, fwPolicyName, securityProfileGroupPath, direction, action, ruleName, priority, srcIPRanges, layer4Configs)
+	ws := workspace.New(t, terraformDirectoryPathPMR)
+	configDir := workspace.ConfigDir(t, configFolderPathPMR)
+	createPMRuleConfigYAML(t, configDir, projectID, fwPolicyName, securityProfileGroupPath, direction, action, ruleName, priority, srcIPRanges, layer4Configs)
 	tfVars := map[string]any{
-		"config_folder_path": configFolderPathPMR,
+		"config_folder_path": configDir,
 	}
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPMR,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		NoColor:      true,
 	})
@@ -105,10 +109,10 @@ func TestCreatePacketMirroringRule(t *testing.T) {
 
 /*
 createPMRuleConfigYAML is a helper function that dynamically generates the `instance.yaml`
-configuration file. This file contains the parameters for the packet mirroring rule
+configuration file in configDir. This file contains the parameters for the packet mirroring rule
 that the Terraform module will create.
 */
-func createPMRuleConfigYAML(t *testing.T, projectID, fwPolicyName, spgPath, direction, action, ruleName string, priority int, srcIPRanges []string, layer4Configs []any) {
+func createPMRuleConfigYAML(t *testing.T, configDir, projectID, fwPolicyName, spgPath, direction, action, ruleName string, priority int, srcIPRanges []string, layer4Configs []any) {
 	config := map[string]any{
 		"priority":               priority,
 		"rule_name":              ruleName,
@@ -125,9 +129,7 @@ func createPMRuleConfigYAML(t *testing.T, projectID, fwPolicyName, spgPath, dire
 	yamlData, err := yaml.Marshal(&config)
 	assert.NoError(t, err)
 
-	err = os.MkdirAll(configFolderPathPMR, 0755)
-	assert.NoError(t, err)
-	filePath := filepath.Join(configFolderPathPMR, "instance.yaml")
+	filePath := filepath.Join(configDir, "instance.yaml")
 	err = os.WriteFile(filePath, yamlData, 0644)
 	assert.NoError(t, err)
 	t.Logf("Created test YAML config file: %s", filePath)
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/integration/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/integration/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -94,17 +95,20 @@ func TestCloudDNSResponsePolicy(t *testing.T) {
 	common_utils.CreateVPCSubnets(t, projectID, gcpNetworkName, gcpSubnetworkName, region)
 	defer common_utils.DeleteVPCSubnets(t, projectID, gcpNetworkName, gcpSubnetworkName, region)
 
-	// Setup: Create YAML config file
-	createConfigYAMLResponsePolicy(t, responsePolicyName, projectID, gcpNetworkName, dnsNameRule1, dnsNameRule2, localIP)
+	// Setup: Create YAML config file in a copy of the config folder
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAMLResponsePolicy(t, configDir, responsePolicyName, projectID, gcpNetworkName, dnsNameRule1, dnsNameRule2, localIP)
 
 	// Define Terraform variables
 	tfVars := map[string]any{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		NoColor:      true,
@@ -121,7 +125,7 @@ func TestCloudDNSResponsePolicy(t *testing.T) {
 	verifyResponsePolicyRule2(t, responsePolicyName, "rule2", dnsNameRule2)
 }
 
-func createConfigYAMLResponsePolicy(t *testing.T, policyName, projectID, networkName, rule1DNSName, rule2DNSName, rule1IP string) {
+func createConfigYAMLResponsePolicy(t *testing.T, dir, policyName, projectID, networkName, rule1DNSName, rule2DNSName, rule1IP string) {
 	config := ResponsePoliciesConfig{
 		ResponsePolicies: []ResponsePolicy{
 			{
@@ -160,11 +164,11 @@ func createConfigYAMLResponsePolicy(t *testing.T, policyName, projectID, network
 		t.Fatalf("Error while marshaling response policy config: %v", err)
 	}
 
-	if err := os.MkdirAll(configFolderPath, 0755); err != nil {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		t.Fatalf("Failed to create config directory: %v", err)
 	}
 
-	filePath := filepath.Join(configFolderPath, yamlFileName)
+	filePath := filepath.Join(dir, yamlFileName)
 	if err := os.WriteFile(filePath, yamlData, 0644); err != nil {
 		t.Fatalf("Unable to write response policy config to file: %v", err)
 	}
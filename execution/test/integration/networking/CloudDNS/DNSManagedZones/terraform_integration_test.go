@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/integration/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/execution/test/integration/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -142,17 +143,20 @@ func TestCloudDNSUserJourney(t *testing.T) {
 	common_utils.CreateVPCSubnets(t, projectID, peerNetworkName, peerSubnetworkName, region)
 	defer common_utils.DeleteVPCSubnets(t, projectID, peerNetworkName, peerSubnetworkName, region)
 
-	// Setup: Create YAML config file
-	createConfigYAMLDNS(t)
+	// Setup: Create YAML config file in a copy of the config folder
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAMLDNS(t, configDir)
 
 	// Define Terraform variables
 	tfVars := map[string]any{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		NoColor:      true,
@@ -165,7 +169,7 @@ func TestCloudDNSUserJourney(t *testing.T) {
 	t.Log("Verifying DNS Managed Zone and Record Set configurations...")
 
 	// Load expected config from YAML
-	configPath := filepath.Join(configFolderPath, yamlFileName)
+	configPath := filepath.Join(configDir, yamlFileName)
 	yamlBytes, err := os.ReadFile(configPath)
 	if err != nil {
 		t.Fatalf("Failed to read config YAML: %v", err)
@@ -185,8 +189,8 @@ func TestCloudDNSUserJourney(t *testing.T) {
 	}
 }
 
-// createConfigYAMLDNS generates a YAML configuration file for the DNS zones.
-func createConfigYAMLDNS(t *testing.T) {
+// createConfigYAMLDNS generates a YAML configuration file for the DNS zones in the folder dir.
+func createConfigYAMLDNS(t *testing.T, dir string) {
 	config := DNSConfig{
 		Zones: []Zone{
 			{
@@ -256,11 +260,11 @@ func createConfigYAMLDNS(t *testing.T) {
 		t.Fatalf("Error while marshaling DNS config: %v", err)
 	}
 
-	if err := os.MkdirAll(configFolderPath, 0755); err != nil {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		t.Fatalf("Failed to create config directory: %v", err)
 	}
 
-	filePath := filepath.Join(configFolderPath, yamlFileName)
+	filePath := filepath.Join(dir, yamlFileName)
 	if err := os.WriteFile(filePath, yamlData, 0644); err != nil {
 		t.Fatalf("Unable to write DNS config to file: %v", err)
 	}
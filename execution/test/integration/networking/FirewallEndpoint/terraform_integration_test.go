@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
@@ -74,15 +75,19 @@ func TestFirewallEndpointIntegration(t *testing.T) {
 
 	endpointName := "fw-ep-integ-test-" + instanceSuffix
 	assocName := "assoc-integ-test-" + instanceSuffix
-	createConfigYAML(t, orgID, billingProjectID, projectID, vpcProtectedName, zone, endpointName, assocName)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAML(t, configDir, orgID, billingProjectID, projectID, vpcProtectedName, zone, endpointName, assocName)
 
-	tfVars := map[string]interface{}{"config_folder_path": configFolderPath}
+	tfVars := map[string]interface{}{"config_folder_path": configDir}
+	envVars := ws.EnvVars()
+	envVars["GOOGLE_PROJECT"] = projectID
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		NoColor:      true,
-		EnvVars:      map[string]string{"GOOGLE_PROJECT": projectID},
+		EnvVars:      envVars,
 	})
 
 	defer terraform.Destroy(t, terraformOptions)
@@ -102,8 +107,9 @@ func TestFirewallEndpointIntegration(t *testing.T) {
 	assert.NoError(t, err, "Control plane configuration validation failed.")
 }
 
-// createConfigYAML remains local as it's test-specific.
-func createConfigYAML(t *testing.T, orgID, billingProjectID, assocProjectID, vpcName, location, endpointName, assocName string) {
+// createConfigYAML remains local as it's test-specific. It writes the
+// configuration into the folder dir.
+func createConfigYAML(t *testing.T, dir, orgID, billingProjectID, assocProjectID, vpcName, location, endpointName, assocName string) {
 	type firewallEndpoint struct {
 		Create           bool   `yaml:"create"`
 		Name             string `yaml:"name"`
@@ -129,9 +135,9 @@ func createConfigYAML(t *testing.T, orgID, billingProjectID, assocProjectID, vpc
 	}
 	yamlData, err := yaml.Marshal(&config)
 	assert.NoError(t, err)
-	err = os.MkdirAll(configFolderPath, 0755)
+	err = os.MkdirAll(dir, 0755)
 	assert.NoError(t, err)
-	filePath := filepath.Join(configFolderPath, "instance.yaml")
+	filePath := filepath.Join(dir, "instance.yaml")
 	err = os.WriteFile(filePath, yamlData, 0644)
 	assert.NoError(t, err)
 	t.Logf("Created test YAML config file: %s", filePath)
@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -116,8 +117,11 @@ func TestNCC(t *testing.T) {
 		t.Skipf("Skipping test because TF_VAR_project_id is not set %s.", projectID)
 	}
 
+	ws := workspace.New(t, terraformNCCDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPathNCC)
+
 	// Setup: create YAML config and VPC/subnet/PSA
-	createConfigYAMLNCC(t, true, "", false, testHubName)
+	createConfigYAMLNCC(t, configDir, true, "", false, testHubName)
 	createVPCAndSubnetWithPSA(t, projectID, networkName, subnetworkName, region, psaRangeName, psaRange)
 	createVPCAndSubnetWithPSA(t, projectID, secondNetworkName, secondSubnetworkName, region, secondPSARangeName, secondPSARange)
 	firstIPGateway1, secondIPGateway1 := createHAVPNGateway(t, projectID, networkName, firstGatewayName, "65417")
@@ -127,7 +131,7 @@ func TestNCC(t *testing.T) {
 	createHAVPNTunnel(t, projectID, firstGatewayName, secondGatewayName, firstTunnel)
 	createHAVPNTunnel(t, projectID, secondGatewayName, firstGatewayName, secondTunnel)
 	tfVars := map[string]interface{}{
-		"config_folder_path":   configFolderPathNCC,
+		"config_folder_path":   configDir,
 		"create_new_hub":       true,
 		"existing_hub_uri":     nil,
 		"export_psc":           defaultExportPSC,
@@ -142,8 +146,9 @@ func TestNCC(t *testing.T) {
 	}
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:         tfVars,
-		TerraformDir: terraformNCCDirectoryPath,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
+		TerraformDir: ws.Dir,
 		Reconfigure:  true,
 		Lock:         true,
 		NoColor:      true,
@@ -161,8 +166,8 @@ func TestNCC(t *testing.T) {
 	verifyNCCResources(t, terraformOptions, testHubName)
 }
 
-// createConfigYAMLNCC creates the configuration YAML file for NCC.
-func createConfigYAMLNCC(t *testing.T, createNewHub bool, existingHubURI string, existingSpoke bool, nccHubName string) {
+// createConfigYAMLNCC creates the configuration YAML file for NCC in the folder dir.
+func createConfigYAMLNCC(t *testing.T, dir string, createNewHub bool, existingHubURI string, existingSpoke bool, nccHubName string) {
 	t.Helper()
 
 	spokes := []SpokeConfig{
@@ -221,11 +226,11 @@ func createConfigYAMLNCC(t *testing.T, createNewHub bool, existingHubURI string,
 		t.Fatalf("Error while marshaling: %v", err)
 	}
 
-	if err := os.MkdirAll(configFolderPathNCC, 0755); err != nil {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		t.Fatalf("Failed to create config directory: %v", err)
 	}
 
-	filePath := filepath.Join(configFolderPathNCC, yamlFileName)
+	filePath := filepath.Join(dir, yamlFileName)
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
 	err = os.WriteFile(filePath, []byte(yamlData), 0644)
@@ -15,66 +15,23 @@
 package integrationtest
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
 	"os"
-	"os/exec"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/command"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/producer"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// Producer defines the configuration for a specific producer type (e.g., CloudSQL, AlloyDB).
-type Producer struct {
-	Name                  string
-	GetCreateArgs         func(name, projectID, allowedProjects, region, networkName string) []string
-	GetDeleteArgs         func(name, projectID, region string) []string
-	GetDescribeAttachArgs func(name, projectID, region string) []string
-	GetTerraformBlock     func(name string) map[string]interface{}
-	GetReadyState         func(t *testing.T, name, projectID, region string) (string, error)
-	ExpectedReadyState    string
-	TerraformProducerKey  string
-}
-
-// producersToTest is the list of all producers to be tested.
-// To add a new producer, add a new entry here.
-var producersToTest = map[string]Producer{
-	"cloudsql": {
-		Name:                 "cloudsql",
-		TerraformProducerKey: "producer_cloudsql",
-		ExpectedReadyState:   "RUNNABLE",
-		GetCreateArgs: func(name, projectID, allowedProjects, region, networkName string) []string {
-			return []string{"sql", "instances", "create", name,
-				"--project=" + projectID, "--database-version=MYSQL_8_0", "--region=" + region,
-				"--enable-private-service-connect", "--allowed-psc-projects=" + allowedProjects,
-				"--no-assign-ip", "--availability-type=REGIONAL", "--tier=db-n1-standard-1",
-				"--enable-bin-log", "--async",
-			}
-		},
-		GetDeleteArgs: func(name, projectID, region string) []string {
-			return []string{"sql", "instances", "delete", name, "--project=" + projectID, "--quiet"}
-		},
-		GetDescribeAttachArgs: func(name, projectID, region string) []string {
-			return []string{"sql", "instances", "describe", name, "--project=" + projectID, "--format=value(pscServiceAttachmentLink)"}
-		},
-		GetTerraformBlock: func(name string) map[string]interface{} {
-			// The terraform module expects both 'instance_name' and 'cluster_id'.
-			return map[string]interface{}{
-				"instance_name": name,
-				"cluster_id":    name,
-			}
-		},
-		GetReadyState: func(t *testing.T, name, projectID, region string) (string, error) {
-			return runGcloudCommandWithOutput(t, "sql", "instances", "describe", name, "--project="+projectID, "--format=value(state)")
-		},
-	},
-}
-
 // Constants for the Terraform directory path.
 const (
 	terraformDirectoryPath = "../../../05-producer-connectivity/"
@@ -87,45 +44,16 @@ var (
 	region                     = "us-central1"
 )
 
-// runGcloudCommand executes a gcloud command and streams its output for logging.
-func runGcloudCommand(_ *testing.T, args ...string) error {
-	command := "gcloud"
-	commandArgs := args
-	if len(args) > 0 && args[0] == "bash" {
-		command = args[0]
-		commandArgs = args[1:]
-	}
-
-	log.Printf("Running command: %s %s", command, strings.Join(commandArgs, " "))
+// run runs the gcloud commands of the test, retrying failed ones.
+var run = producer.Retry(command.Exec, 5, 5*time.Second)
 
-	var output []byte
-	var err error
-	for i := 0; i < 5; i++ {
-		cmd := exec.Command(command, commandArgs...)
-		output, err = cmd.CombinedOutput()
-		if err == nil {
-			log.Printf("Output:\n%s", string(output))
-			return nil
-		}
-		log.Printf("Command failed, retrying in 5 seconds... Output:\n%s", string(output))
-		time.Sleep(5 * time.Second)
-	}
-	log.Printf("Final output after retries:\n%s", string(output))
+// runGcloudCommand executes a gcloud command, retrying it when it fails.
+func runGcloudCommand(_ *testing.T, args ...string) error {
+	log.Printf("Running command: gcloud %s", strings.Join(args, " "))
+	_, err := run(context.Background(), "", "gcloud", args...)
 	return err
 }
 
-// runGcloudCommandWithOutput executes a gcloud command and returns its output as a string.
-func runGcloudCommandWithOutput(_ *testing.T, args ...string) (string, error) {
-	log.Printf("Running gcloud command for output: gcloud %s", strings.Join(args, " "))
-	cmd := exec.Command("gcloud", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		log.Printf("gcloud command failed. Output:\n%s", string(output))
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
-}
-
 // setupNetwork creates a custom VPC and Subnet in the endpoint project.
 func setupNetwork(t *testing.T, projectID string, uniqueID int) (string, string, func()) {
 	networkName := fmt.Sprintf("test-vpc-%d", uniqueID)
@@ -156,21 +84,6 @@ func setupNetwork(t *testing.T, projectID string, uniqueID int) (string, string,
 	return networkName, subnetworkName, cleanupFunc
 }
 
-// waitForProducer polls the status of a producer instance until it reaches the expected ready state.
-func waitForProducer(t *testing.T, producer Producer, projectID, instanceName, region string) {
-	log.Printf("Waiting for %s instance %s to be %s...", producer.Name, instanceName, producer.ExpectedReadyState)
-	for i := 0; i < 30; i++ { // Wait for up to 30 minutes.
-		status, err := producer.GetReadyState(t, instanceName, projectID, region)
-		if err == nil && status == producer.ExpectedReadyState {
-			log.Printf("%s instance %s is %s.", producer.Name, instanceName, producer.ExpectedReadyState)
-			return
-		}
-		log.Printf("Instance %s not ready yet (current state: %s), waiting 60 seconds...", instanceName, status)
-		time.Sleep(60 * time.Second)
-	}
-	t.Fatalf("Timed out waiting for %s instance %s to become %s.", producer.Name, instanceName, producer.ExpectedReadyState)
-}
-
 // getEndpointProjectID retrieves the mandatory endpoint project ID from an environment variable.
 func getEndpointProjectID(t *testing.T) string {
 	projectID := os.Getenv("TF_VAR_endpoint_project_id")
@@ -192,60 +105,72 @@ func getProducerProjectID(_ *testing.T, endpointProjectID string) string {
 // TestPlanFailsWithoutVars tests that the Terraform plan fails when required input variables are missing.
 func TestPlanFailsWithoutVars(t *testing.T) {
 	t.Parallel()
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath, Reconfigure: true, Lock: true, NoColor: true,
+		TerraformDir: ws.Dir, EnvVars: ws.EnvVars(), Reconfigure: true, Lock: true, NoColor: true,
 	})
 	_, err := terraform.InitAndPlanE(t, terraformOptions)
 	assert.Error(t, err, "Expected Terraform plan to fail due to missing variables")
 }
 
 // TestProducerConnectivity is the main test function that orchestrates all test cases.
+// It runs once per producer registered in the producer package, or per producer
+// named in the comma separated TEST_PRODUCERS environment variable. The producers
+// run in parallel, each applying its own copy of the stage with its own state.
 func TestProducerConnectivity(t *testing.T) {
 	t.Parallel()
 	endpointProjectID := getEndpointProjectID(t)
 	producerProjectID := getProducerProjectID(t, endpointProjectID)
+	producers, err := producer.Select(os.Getenv("TEST_PRODUCERS"))
+	require.NoError(t, err, "Invalid TEST_PRODUCERS")
 
-	for producerName, producer := range producersToTest {
-		// Capture range variables for parallel testing
-		producer := producer
-		producerName := producerName
-		t.Run(producerName, func(t *testing.T) {
+	for _, p := range producers {
+		t.Run(p.Name(), func(t *testing.T) {
 			t.Parallel()
 
 			// ONE-TIME SETUP: Create producer instance and network once per producer type.
-			rand.Seed(time.Now().UnixNano())
 			uniqueID := rand.Intn(10000)
-			dynamicInstanceName := fmt.Sprintf("test-%s-psc-%d", producer.Name, uniqueID)
+			instance := producer.Instance{
+				Name:            fmt.Sprintf("test-%s-psc-%d", p.Name(), uniqueID),
+				ProjectID:       producerProjectID,
+				Region:          region,
+				AllowedProjects: []string{endpointProjectID},
+			}
+
+			// The stage copy of the producer, whose local state the test
+			// variations below share one after the other.
+			ws := workspace.New(t, terraformDirectoryPath)
 
 			networkName, subnetworkName, cleanupNetwork := setupNetwork(t, endpointProjectID, uniqueID)
 			defer cleanupNetwork()
 
-			createArgs := producer.GetCreateArgs(dynamicInstanceName, producerProjectID, endpointProjectID, region, networkName)
-			// 'err' is declared for the first time here.
-			err := runGcloudCommand(t, createArgs...)
+			err := producer.Create(run, p, instance)
 			require.NoError(t, err, "Failed to start producer instance creation")
 			defer func() {
-				log.Printf("Destroying %s instance: %s", producer.Name, dynamicInstanceName)
-				deleteArgs := producer.GetDeleteArgs(dynamicInstanceName, producerProjectID, region)
-				deleteErr := runGcloudCommand(t, deleteArgs...)
-				assert.NoError(t, deleteErr, "Failed to destroy producer instance")
+				log.Printf("Destroying %s instance: %s", p.Name(), instance.Name)
+				assert.NoError(t, producer.Delete(run, p, instance), "Failed to destroy producer instance")
 			}()
 
-			waitForProducer(t, producer, producerProjectID, dynamicInstanceName, region)
+			err = producer.WaitReady(run, p, instance, 30*time.Minute, time.Minute)
+			require.NoError(t, err, "Producer instance did not become ready")
 
-			var serviceAttachment string
-			// The existing 'err' variable from above will be reused.
-			for i := 0; i < 4; i++ {
-				describeArgs := producer.GetDescribeAttachArgs(dynamicInstanceName, producerProjectID, region)
-				serviceAttachment, err = runGcloudCommandWithOutput(t, describeArgs...)
-				if err == nil && serviceAttachment != "" {
-					break // Success
-				}
-				log.Printf("Service attachment link not yet available for %s, retrying in 15 seconds...", dynamicInstanceName)
-				time.Sleep(15 * time.Second)
-			}
+			serviceAttachment, err := producer.WaitAttachment(run, p, instance, time.Minute, 15*time.Second)
 			require.NoError(t, err, "Failed to get service attachment link after retries")
-			require.NotEmpty(t, serviceAttachment, "Service attachment link was empty after retries")
+			producerKey, producerValue := p.Endpoint(instance, serviceAttachment)
+
+			// pscEndpoints returns the psc_endpoints variable of the stage, with one
+			// entry selecting the producer instance with key and value.
+			pscEndpoints := func(ipAddress, key string, value any) []map[string]interface{} {
+				return []map[string]interface{}{{
+					"endpoint_project_id":          endpointProjectID,
+					"producer_instance_project_id": producerProjectID,
+					"subnetwork_name":              subnetworkName,
+					"network_name":                 networkName,
+					"ip_address_literal":           ipAddress,
+					"region":                       region,
+					key:                            value,
+				}}
+			}
 
 			// === RUN TEST VARIATIONS AGAINST THE CREATED PRODUCER ===
 			// These sub-tests run SEQUENTIALLY to avoid race conditions.
@@ -253,55 +178,31 @@ func TestProducerConnectivity(t *testing.T) {
 			// Test Case 1: With a provided IP address
 			t.Run("WithProvidedIPAddress", func(t *testing.T) {
 				tfVars := map[string]interface{}{
-					"psc_endpoints": []map[string]interface{}{{
-						"endpoint_project_id":          endpointProjectID,
-						"producer_instance_project_id": producerProjectID,
-						"subnetwork_name":              subnetworkName,
-						"network_name":                 networkName,
-						"ip_address_literal":           ipAddressLiteral,
-						"region":                       region,
-						producer.TerraformProducerKey:  producer.GetTerraformBlock(dynamicInstanceName),
-					}},
+					"psc_endpoints": pscEndpoints(ipAddressLiteral, producerKey, producerValue),
 				}
-				tfOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{TerraformDir: terraformDirectoryPath, Vars: tfVars})
+				tfOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{TerraformDir: ws.Dir, Vars: tfVars, EnvVars: ws.EnvVars()})
 				defer terraform.Destroy(t, tfOptions)
 				terraform.InitAndApply(t, tfOptions)
-				assertOutputs(t, tfOptions, producer.TerraformProducerKey)
+				assertOutputs(t, tfOptions, producer.ForwardingRuleName(producerKey, producerValue, 0))
 			})
 
 			// Test Case 2: With an auto-allocated IP address
 			t.Run("WithAutoAllocatedIPAddress", func(t *testing.T) {
 				tfVars := map[string]interface{}{
-					"psc_endpoints": []map[string]interface{}{{
-						"endpoint_project_id":          endpointProjectID,
-						"producer_instance_project_id": producerProjectID,
-						"subnetwork_name":              subnetworkName,
-						"network_name":                 networkName,
-						"ip_address_literal":           "", // Key change for this test
-						"region":                       region,
-						producer.TerraformProducerKey:  producer.GetTerraformBlock(dynamicInstanceName),
-					}},
+					"psc_endpoints": pscEndpoints("", producerKey, producerValue), // Key change for this test
 				}
-				tfOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{TerraformDir: terraformDirectoryPath, Vars: tfVars})
+				tfOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{TerraformDir: ws.Dir, Vars: tfVars, EnvVars: ws.EnvVars()})
 				defer terraform.Destroy(t, tfOptions)
 				terraform.InitAndApply(t, tfOptions)
-				assertOutputsForAutoAllocatedIPAddress(t, tfOptions, producer.TerraformProducerKey)
+				assertOutputsForAutoAllocatedIPAddress(t, tfOptions, producer.ForwardingRuleName(producerKey, producerValue, 0))
 			})
 
 			// Test Case 3: With a direct service attachment target
 			t.Run("WithDirectTarget", func(t *testing.T) {
 				tfVars := map[string]interface{}{
-					"psc_endpoints": []map[string]interface{}{{
-						"endpoint_project_id":          endpointProjectID,
-						"producer_instance_project_id": producerProjectID,
-						"subnetwork_name":              subnetworkName,
-						"network_name":                 networkName,
-						"ip_address_literal":           ipAddressLiteralWithTarget,
-						"region":                       region,
-						"target":                       serviceAttachment, // Use the pre-fetched target.
-					}},
+					"psc_endpoints": pscEndpoints(ipAddressLiteralWithTarget, producer.Target, serviceAttachment), // Use the pre-fetched target.
 				}
-				tfOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{TerraformDir: terraformDirectoryPath, Vars: tfVars})
+				tfOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{TerraformDir: ws.Dir, Vars: tfVars, EnvVars: ws.EnvVars()})
 				defer terraform.Destroy(t, tfOptions)
 				terraform.InitAndApply(t, tfOptions)
 				assertOutputsWithTarget(t, tfOptions, serviceAttachment)
@@ -312,17 +213,9 @@ func TestProducerConnectivity(t *testing.T) {
 
 // ============== ASSERTION HELPERS ==============
 
-func assertOutputs(t *testing.T, tfOptions *terraform.Options, producerKey string) {
+func assertOutputs(t *testing.T, tfOptions *terraform.Options, expectedFwdRuleName string) {
 	actualFwdRuleMap := terraform.OutputMap(t, tfOptions, "forwarding_rule_self_link")
 	actualIPMap := terraform.OutputMap(t, tfOptions, "ip_address_literal")
-	vars := tfOptions.Vars["psc_endpoints"].([]map[string]interface{})[0]
-	producerBlock := vars[producerKey].(map[string]interface{})
-	var instanceName string
-	// Get the instance/cluster name from the producer block
-	for _, v := range producerBlock {
-		instanceName = v.(string)
-	}
-	expectedFwdRuleName := fmt.Sprintf("psc-forwarding-rule-%s", instanceName)
 	actualFwdRuleSelfLink := actualFwdRuleMap["0"]
 	parts := strings.Split(actualFwdRuleSelfLink, "/")
 	actualFwdRuleName := parts[len(parts)-1]
@@ -331,16 +224,9 @@ func assertOutputs(t *testing.T, tfOptions *terraform.Options, producerKey strin
 	assert.NotNil(t, actualIPAddress, "IP address is nil")
 }
 
-func assertOutputsForAutoAllocatedIPAddress(t *testing.T, tfOptions *terraform.Options, producerKey string) {
+func assertOutputsForAutoAllocatedIPAddress(t *testing.T, tfOptions *terraform.Options, expectedFwdRuleName string) {
 	actualFwdRuleMap := terraform.OutputMap(t, tfOptions, "forwarding_rule_self_link")
 	actualIPMap := terraform.OutputMap(t, tfOptions, "ip_address_literal")
-	vars := tfOptions.Vars["psc_endpoints"].([]map[string]interface{})[0]
-	producerBlock := vars[producerKey].(map[string]interface{})
-	var instanceName string
-	for _, v := range producerBlock {
-		instanceName = v.(string)
-	}
-	expectedFwdRuleName := fmt.Sprintf("psc-forwarding-rule-%s", instanceName)
 	actualFwdRuleSelfLink := actualFwdRuleMap["0"]
 	parts := strings.Split(actualFwdRuleSelfLink, "/")
 	actualFwdRuleName := parts[len(parts)-1]
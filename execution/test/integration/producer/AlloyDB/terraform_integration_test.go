@@ -16,6 +16,8 @@ package integrationtest
 import (
 	"fmt"
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/matrix"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v2"
@@ -23,29 +25,27 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
 
 var (
 	projectID              = os.Getenv("TF_VAR_project_id")
-	region                 = "us-central1"
 	terraformDirectoryPath = "../../../../04-producer/AlloyDB"
-	configFolderPath       = "../../test/integration/producer/AlloyDB/config"
-	rangeName              = fmt.Sprintf("psatestrangealloydb-%s", clusterDisplayName)
-	clusterDisplayName     = fmt.Sprint(rand.Int())
-	networkName            = fmt.Sprintf("vpc-%s-test", clusterDisplayName)
-	alloyDBClusterID       = fmt.Sprintf("cid-%s-test", clusterDisplayName)
-	instanceID             = fmt.Sprintf("id-%s-test", clusterDisplayName)
+	rangeName              = fmt.Sprintf("psatestrangealloydb-%s", runID)
+	runID                  = fmt.Sprint(rand.Int())
+	networkName            = fmt.Sprintf("vpc-%s-test", runID)
 	networkID              = fmt.Sprintf("projects/%s/global/networks/%s", projectID, networkName)
 )
 
 type PrimaryInstanceStruct struct {
-	InstanceID    string      `yaml:"instance_id"`
-	DisplayName   string      `yaml:"display_name"`
-	InstanceType  string      `yaml:"instance_type"`
-	MachineCPUs   int         `yaml:"machine_cpu_count"`
-	DatabaseFlags interface{} `yaml:"database_flags"`
+	InstanceID       string      `yaml:"instance_id"`
+	DisplayName      string      `yaml:"display_name"`
+	InstanceType     string      `yaml:"instance_type"`
+	MachineCPUs      int         `yaml:"machine_cpu_count"`
+	AvailabilityType string      `yaml:"availability_type,omitempty"`
+	DatabaseFlags    interface{} `yaml:"database_flags"`
 }
 
 type AlloyDBStruct struct {
@@ -53,6 +53,7 @@ type AlloyDBStruct struct {
 	ClusterDisplayName         string                `yaml:"cluster_display_name"`
 	ProjectID                  string                `yaml:"project_id"`
 	Region                     string                `yaml:"region"`
+	DatabaseVersion            string                `yaml:"database_version,omitempty"`
 	NetworkID                  string                `yaml:"network_id"`
 	PrimaryInstance            PrimaryInstanceStruct `yaml:"primary_instance"`
 	AllocatedIPRange           string                `yaml:"allocated_ip_range"`
@@ -64,56 +65,88 @@ type AlloyDBStruct struct {
 }
 
 /*
-This test creates all the pre-requsite resources including the vpc network, subnetwork along with a PSA range.
-It then validates if
+This test runs once per cell of matrix.yaml, whose axes are the database
+version, PSA or PSC connectivity, the region and HA. The cells share the
+project numbers and a vpc network with a PSA range, created before the first
+PSA cell and deleted after the last cell. Set TEST_MATRIX_FILTER, e.g. to
+"connectivity=psc", to run some of the cells. Each cell validates if
 1. AlloyDB instance is created.
-2. AlloyDB instance is created in the correct network and correct PSA range.
-3. AlloyDB instance is in ACTIVE state.
+2. AlloyDB instance is created in the correct network and correct PSA range, or with PSC.
+3. AlloyDB instance is in ACTIVE state, with the database version of the cell.
 */
 func TestCreateAlloyDB(t *testing.T) {
-	// Initialize AlloyDB config YAML files
-	createConfigYAMLs(t)
-
-	// Get the project number
-	projectNumber, err := common_utils.GetProjectNumber(t, projectID)
-	if err != nil {
-		t.Fatal(err)
-	}
-	attachmentProjectID := os.Getenv("TF_VAR_ATTACHMENT_PROJECT_ID")
-	// Get the attachment project number
-	attachmentProjectNumber, err := common_utils.GetAttachmentProjectNumber(t, projectID, attachmentProjectID)
+	m, err := matrix.Load("matrix.yaml")
 	if err != nil {
 		t.Fatal(err)
 	}
+	m.Run(t, func(t *testing.T, c matrix.Cell, s *matrix.Shared) {
+		t.Parallel()
+		consumerProjects, err := matrix.Fixture(s, "projects", func() ([]string, func(*testing.T), error) {
+			// Get the project number
+			projectNumber, err := common_utils.GetProjectNumber(t, projectID)
+			if err != nil {
+				return nil, nil, err
+			}
+			attachmentProjectID := os.Getenv("TF_VAR_ATTACHMENT_PROJECT_ID")
+			// Get the attachment project number
+			attachmentProjectNumber, err := common_utils.GetAttachmentProjectNumber(t, projectID, attachmentProjectID)
+			if err != nil {
+				return nil, nil, err
+			}
+			return []string{projectNumber, attachmentProjectNumber}, nil, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Get("connectivity") == "psa" {
+			_, err := matrix.Fixture(s, "network", func() (string, func(*testing.T), error) {
+				// Create VPC outside of the terraform module.
+				common_utils.CreateVPCSubnets(t, projectID, networkName, "", "")
+				// Create PSA in the VPC.
+				common_utils.CreatePSA(t, projectID, networkName, rangeName)
+				return networkID, func(t *testing.T) {
+					// Remove PSA from the VPC.
+					common_utils.DeletePSA(t, projectID, networkName, rangeName)
+					// Delete VPC created outside of the terraform module.
+					common_utils.DeleteVPCSubnets(t, projectID, networkName, "", "")
+				}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		testCreateAlloyDB(t, c, consumerProjects)
+	})
+}
+
+/*
+testCreateAlloyDB creates and validates the AlloyDB cluster of a cell of the
+matrix. Each cell plans a private copy of the stage, with its own state and
+configuration folder, so the cells run in parallel; each destroys its cluster.
+*/
+func testCreateAlloyDB(t *testing.T, c matrix.Cell, consumerProjects []string) {
+	clusterDisplayName := fmt.Sprintf("%s-%s", runID, c.Suffix())
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, "config")
+	// Initialize AlloyDB config YAML file
+	createConfigYAML(t, c, clusterDisplayName, consumerProjects, configDir)
 
 	var (
 		tfVars = map[string]any{
-			"config_folder_path": configFolderPath,
+			"config_folder_path": configDir,
 		}
 	)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
 		// Set the path to the Terraform code that will be tested.
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
 		SetVarsAfterVarFiles: true,
 	})
-	// Create VPC outside of the terraform module.
-	common_utils.CreateVPCSubnets(t, projectID, networkName, "", "")
-	if err != nil {
-		t.Fatal(err)
-	}
-	// Create PSA in the VPC.
-	common_utils.CreatePSA(t, projectID, networkName, rangeName)
-
-	// Delete VPC created outside of the terraform module.
-	defer common_utils.DeleteVPCSubnets(t, projectID, networkName, "", "")
-
-	// Remove PSA from the VPC.
-	defer common_utils.DeletePSA(t, projectID, networkName, rangeName)
 
 	// Clean up resources with "terraform destroy" at the end of the test.
 	defer terraform.Destroy(t, terraformOptions)
@@ -131,121 +164,97 @@ func TestCreateAlloyDB(t *testing.T) {
 	}
 
 	result := gjson.Parse(alloyDBOutputValue)
+	clusterKey := clusterDisplayName
+	psa := c.Get("connectivity") == "psa"
+	t.Logf(" ========= Verifying Cluster: %s ========= ", clusterKey)
 
-	// Define cluster keys for easier access
-	psaClusterKey := fmt.Sprint(clusterDisplayName)
-	pscClusterKey := fmt.Sprintf("%s-psc", clusterDisplayName)
-	clusterKeys := []string{psaClusterKey, pscClusterKey}
+	// Verify Cluster ID
+	t.Log(" ========= Verify AlloyDB Cluster ID ========= ")
+	clusterIDPath := fmt.Sprintf("%s.cluster_id", clusterKey)
+	gotClusterID := gjson.Get(result.String(), clusterIDPath).String()
+	gotClusterID = path.Base(gotClusterID)
 
-	for _, clusterKey := range clusterKeys {
-		t.Logf(" ========= Verifying Cluster: %s ========= ", clusterKey)
+	wantClusterID := fmt.Sprintf("cid-%s-test", clusterDisplayName)
+	if gotClusterID != wantClusterID {
+		t.Errorf("AlloyDB Cluster with invalid Cluster ID = %v, want = %v", gotClusterID, wantClusterID)
+	}
 
-		// Verify Cluster ID
-		t.Log(" ========= Verify AlloyDB Cluster ID ========= ")
-		clusterIDPath := fmt.Sprintf("%s.cluster_id", clusterKey)
-		gotClusterID := gjson.Get(result.String(), clusterIDPath).String()
-		gotClusterID = path.Base(gotClusterID)
+	// Verify AlloyDB Cluster Status
+	t.Log(" ========= Verify AlloyDB Cluster Status ========= ")
+	wantStatus := "READY"
+	clusterStatusPath := fmt.Sprintf("%s.cluster_status", clusterKey)
+	gotStatus := gjson.Get(result.String(), clusterStatusPath).String()
+	if gotStatus != wantStatus {
+		t.Errorf("AlloyDB Cluster with invalid Cluster status = %v, want = %v", gotStatus, wantStatus)
+	}
 
-		wantClusterID := fmt.Sprintf("cid-%s-test", clusterDisplayName)
-		if clusterKey == pscClusterKey {
-			wantClusterID = fmt.Sprintf("cid-%s-test-psc", clusterDisplayName)
-		}
-		if gotClusterID != wantClusterID {
-			t.Errorf("AlloyDB Cluster with invalid Cluster ID = %v, want = %v", gotClusterID, wantClusterID)
-		}
+	// Verify Allocated IP Range (only for PSA)
+	t.Log(" ========= Verify Allocated IP Range ========= ")
+	allocatedIPRangePath := fmt.Sprintf("%s.network_config.allocated_ip_range", clusterKey)
+	gotAllocatedIPRange := gjson.Get(result.String(), allocatedIPRangePath).String()
 
-		// Verify AlloyDB Cluster Status
-		t.Log(" ========= Verify AlloyDB Cluster Status ========= ")
-		wantStatus := "READY"
-		clusterStatusPath := fmt.Sprintf("%s.cluster_status", clusterKey)
-		gotStatus := gjson.Get(result.String(), clusterStatusPath).String()
-		if gotStatus != wantStatus {
-			t.Errorf("AlloyDB Cluster with invalid Cluster status = %v, want = %v", gotStatus, wantStatus)
+	if psa {
+		if gotAllocatedIPRange != rangeName {
+			t.Errorf("Allocated IP range mismatch for PSA cluster. Got: %v, Want: %v", gotAllocatedIPRange, rangeName)
 		}
-
-		// Verify Allocated IP Range (only for PSA)
-		t.Log(" ========= Verify Allocated IP Range ========= ")
-		allocatedIPRangePath := fmt.Sprintf("%s.network_config.allocated_ip_range", clusterKey)
-		gotAllocatedIPRange := gjson.Get(result.String(), allocatedIPRangePath).String()
-
-		if clusterKey == psaClusterKey {
-			wantAllocatedIPRange := fmt.Sprintf("psatestrangealloydb-%s", clusterDisplayName)
-			if gotAllocatedIPRange != wantAllocatedIPRange {
-				t.Errorf("Allocated IP range mismatch for PSA cluster. Got: %v, Want: %v", gotAllocatedIPRange, wantAllocatedIPRange)
-			}
-		} else {
-			if gotAllocatedIPRange != "" {
-				t.Errorf("Allocated IP range should be empty for non-PSA cluster. Got: %v", gotAllocatedIPRange)
-			}
+	} else {
+		if gotAllocatedIPRange != "" {
+			t.Errorf("Allocated IP range should be empty for non-PSA cluster. Got: %v", gotAllocatedIPRange)
 		}
+	}
 
-		// Verify Connectivity Options
-		t.Log(" ========= Verify Connectivity Options ========= ")
-		connectivityOptionsPath := fmt.Sprintf("%s.connectivity_options", clusterKey)
-		gotConnectivityOptions := gjson.Get(result.String(), connectivityOptionsPath).String()
-		wantConnectivityOptions := "PSA"
-		if clusterKey == pscClusterKey {
-			wantConnectivityOptions = "PSC"
-		}
-		if gotConnectivityOptions != wantConnectivityOptions {
-			t.Errorf("Connectivity Options mismatch. Got: %v, Want: %v", gotConnectivityOptions, wantConnectivityOptions)
-		}
+	// Verify Connectivity Options
+	t.Log(" ========= Verify Connectivity Options ========= ")
+	connectivityOptionsPath := fmt.Sprintf("%s.connectivity_options", clusterKey)
+	gotConnectivityOptions := gjson.Get(result.String(), connectivityOptionsPath).String()
+	wantConnectivityOptions := strings.ToUpper(c.Get("connectivity"))
+	if gotConnectivityOptions != wantConnectivityOptions {
+		t.Errorf("Connectivity Options mismatch. Got: %v, Want: %v", gotConnectivityOptions, wantConnectivityOptions)
+	}
 
-		// Verify PSC Allowed Consumer Projects
-		t.Log(" ========= Verify PSC Allowed Consumer Projects ========= ")
-		consumerProjectsPath := fmt.Sprintf("%s.network_config.psc_config.configured_allowed_consumer_projects", clusterKey)
-		gotConsumerProjects := gjson.Get(result.String(), consumerProjectsPath).Array()
-		if clusterKey == pscClusterKey {
-			wantConsumerProjects := []string{projectNumber, attachmentProjectNumber}
-			gotConsumerProjectsStr := []string{}
-			for _, v := range gotConsumerProjects {
-				gotConsumerProjectsStr = append(gotConsumerProjectsStr, v.String())
-			}
-			if !reflect.DeepEqual(gotConsumerProjectsStr, wantConsumerProjects) {
-				t.Errorf("PSC consumer projects mismatch. Got: %v, Want: %v", gotConsumerProjectsStr, wantConsumerProjects)
-			}
-		} else {
-			if len(gotConsumerProjects) > 0 {
-				t.Errorf("PSC consumer projects expected to be empty. Got: %v", gotConsumerProjects)
-			}
+	// Verify PSC Allowed Consumer Projects
+	t.Log(" ========= Verify PSC Allowed Consumer Projects ========= ")
+	consumerProjectsPath := fmt.Sprintf("%s.network_config.psc_config.configured_allowed_consumer_projects", clusterKey)
+	gotConsumerProjects := gjson.Get(result.String(), consumerProjectsPath).Array()
+	if !psa {
+		gotConsumerProjectsStr := []string{}
+		for _, v := range gotConsumerProjects {
+			gotConsumerProjectsStr = append(gotConsumerProjectsStr, v.String())
 		}
-
-		// Verify Database Version
-		t.Log(" ========= Verify Database Version ========= ")
-		databaseVersionPath := fmt.Sprintf("%s.database_version", clusterKey)
-		gotDatabaseVersion := gjson.Get(result.String(), databaseVersionPath).String()
-		wantDatabaseVersion := "POSTGRES_15"
-		if gotDatabaseVersion != wantDatabaseVersion {
-			t.Errorf("Database version mismatch. Got: %v, Want: %v", gotDatabaseVersion, wantDatabaseVersion)
+		if !reflect.DeepEqual(gotConsumerProjectsStr, consumerProjects) {
+			t.Errorf("PSC consumer projects mismatch. Got: %v, Want: %v", gotConsumerProjectsStr, consumerProjects)
 		}
+	} else {
+		if len(gotConsumerProjects) > 0 {
+			t.Errorf("PSC consumer projects expected to be empty. Got: %v", gotConsumerProjects)
+		}
+	}
 
+	// Verify Database Version
+	t.Log(" ========= Verify Database Version ========= ")
+	databaseVersionPath := fmt.Sprintf("%s.database_version", clusterKey)
+	gotDatabaseVersion := gjson.Get(result.String(), databaseVersionPath).String()
+	wantDatabaseVersion := c.Get("database_version")
+	if gotDatabaseVersion != wantDatabaseVersion {
+		t.Errorf("Database version mismatch. Got: %v, Want: %v", gotDatabaseVersion, wantDatabaseVersion)
 	}
 }
 
 /*
 createConfigYAML is a helper function which creates the configigration YAML file
-for an alloydb instance range before the.
+for the alloydb cluster of a cell of the matrix in the folder dir. The connectivity axis picks
+PSA in the shared network or PSC, and the config of the cell, such as the
+availability type of the ha axis, is overlaid on the cluster.
 */
-func createConfigYAMLs(t *testing.T) {
-	// Get the project number
-	projectNumber, err := common_utils.GetProjectNumber(t, projectID)
-	if err != nil {
-		t.Fatal(err)
-	}
-	attachmentProjectID := os.Getenv("TF_VAR_ATTACHMENT_PROJECT_ID")
-	// Get the attachment project number (replace with your actual logic)
-	attachmentProjectNumber, err := common_utils.GetAttachmentProjectNumber(t, projectID, attachmentProjectID)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	instance1 := AlloyDBStruct{ // PSA config
-		ClusterID:          alloyDBClusterID,
+func createConfigYAML(t *testing.T, c matrix.Cell, clusterDisplayName string, consumerProjects []string, dir string) {
+	instanceID := fmt.Sprintf("id-%s-test", clusterDisplayName)
+	instance1 := AlloyDBStruct{
+		ClusterID:          fmt.Sprintf("cid-%s-test", clusterDisplayName),
 		ClusterDisplayName: clusterDisplayName,
 		ProjectID:          projectID,
-		Region:             region,
+		Region:             c.Get("region"),
+		DatabaseVersion:    c.Get("database_version"),
 		NetworkID:          networkID,
-		AllocatedIPRange:   rangeName,
 		PrimaryInstance: PrimaryInstanceStruct{
 			InstanceID:    instanceID,
 			DisplayName:   instanceID,
@@ -256,48 +265,24 @@ func createConfigYAMLs(t *testing.T) {
 		ReadPoolInstance:           nil,
 		AutomatedBackupPolicy:      nil,
 		DeletionProtection:         false,
-		ConnectivityOptions:        "psa",
-		PscAllowedConsumerProjects: []string{projectNumber, attachmentProjectNumber},
+		ConnectivityOptions:        c.Get("connectivity"),
+		PscAllowedConsumerProjects: consumerProjects,
 	}
-
-	instance2 := AlloyDBStruct{ // PSC config
-		ClusterID:          alloyDBClusterID + "-psc",
-		ClusterDisplayName: clusterDisplayName + "-psc",
-		ProjectID:          projectID,
-		Region:             region,
-		NetworkID:          networkID,
-		PrimaryInstance: PrimaryInstanceStruct{
-			InstanceID:    instanceID + "-psc",
-			DisplayName:   instanceID + "-psc",
-			InstanceType:  "PRIMARY",
-			MachineCPUs:   2,
-			DatabaseFlags: nil,
-		},
-		ReadPoolInstance:           nil,
-		AutomatedBackupPolicy:      nil,
-		DeletionProtection:         false,
-		ConnectivityOptions:        "psc",
-		PscAllowedConsumerProjects: []string{projectNumber, attachmentProjectNumber},
-		AllocatedIPRange:           "", // No Allocated IP Range for PSC
+	// No Allocated IP Range for PSC
+	if c.Get("connectivity") == "psa" {
+		instance1.AllocatedIPRange = rangeName
+	}
+	if err := c.Decode(&instance1); err != nil {
+		t.Fatalf("Error applying the config of cell %s: %v", c.Name(), err)
 	}
 
 	yamlData1, err := yaml.Marshal(&instance1)
 	if err != nil {
 		t.Errorf("Error marshalling instance1: %v", err)
 	}
-	filePath1 := fmt.Sprintf("%s/%s", "config", "instance1.yaml")
+	filePath1 := fmt.Sprintf("%s/%s", dir, "instance1.yaml")
 	err = os.WriteFile(filePath1, []byte(yamlData1), 0666)
 	if err != nil {
 		t.Errorf("Unable to write instance1 data: %v", err)
 	}
-
-	yamlData2, err := yaml.Marshal(&instance2)
-	if err != nil {
-		t.Errorf("Error marshalling instance2: %v", err)
-	}
-	filePath2 := fmt.Sprintf("%s/%s", "config", "instance2.yaml")
-	err = os.WriteFile(filePath2, []byte(yamlData2), 0666)
-	if err != nil {
-		t.Errorf("Unable to write instance2 data: %v", err)
-	}
 }
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -33,7 +34,6 @@ import (
 const (
 	location               = "us-central1"
 	terraformDirectoryPath = "../../../../04-producer/BigQuery"
-	terraformConfigDir     = "../../test/integration/producer/BigQuery/config"
 	localConfigDir         = "config"
 	tableID                = "test_table"
 	bqLocation             = "US"
@@ -74,17 +74,19 @@ func TestCreateBigQueryDataset(t *testing.T) {
 
 	datasetID := fmt.Sprintf("bq_base_test_%d", randID)
 
-	common_utils.CleanupConfigDir(t, localConfigDir)
-	createBigQueryConfigYAML(t, projectID, datasetID, localConfigDir)
-	defer deleteBigQueryConfigYAML(t, localConfigDir, datasetID)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, localConfigDir)
+	common_utils.CleanupConfigDir(t, configDir)
+	createBigQueryConfigYAML(t, projectID, datasetID, configDir)
 
 	var tfVars = map[string]any{
-		"config_folder_path": terraformConfigDir,
+		"config_folder_path": configDir,
 	}
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 	})
 
 	defer terraform.Destroy(t, terraformOptions)
@@ -200,13 +202,6 @@ func createBigQueryConfigYAML(t *testing.T, projectID string, datasetID string,
 	}
 }
 
-func deleteBigQueryConfigYAML(t *testing.T, configPath string, datasetID string) {
-	fullConfigFilePath := filepath.Join(configPath, fmt.Sprintf("dataset_%s.yaml", datasetID))
-	if err := os.Remove(fullConfigFilePath); err != nil {
-		t.Logf("Warning: Could not remove temporary config file %s: %v", fullConfigFilePath, err)
-	}
-}
-
 func deleteBigQueryDataset(t *testing.T, projectID, datasetID string) {
 	cmd := shell.Command{
 		Command: "bq",
@@ -16,6 +16,8 @@ package integrationtest
 import (
 	"fmt"
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/matrix"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v2"
@@ -27,13 +29,10 @@ import (
 
 var (
 	projectID              = os.Getenv("TF_VAR_project_id")
-	region                 = "us-central1"
 	terraformDirectoryPath = "../../../../04-producer/CloudSQL"
-	configFolderPath       = "../../test/integration/producer/CloudSQL/config"
 	rangeName              = "psatestrangecloudsql"
-	databaseVersion        = "POSTGRES_15"
-	name                   = fmt.Sprintf("cloudsql-%d", rand.Int())
-	networkName            = fmt.Sprintf("vpc-%s-test", name)
+	runID                  = rand.Int()
+	networkName            = fmt.Sprintf("vpc-cloudsql-%d-test", runID)
 	networkID              = fmt.Sprintf("projects/%s/global/networks/%s", projectID, networkName)
 )
 
@@ -71,44 +70,81 @@ type CloudSQLStruct struct {
 	ProjectID                   string              `yaml:"project_id"`
 	Region                      string              `yaml:"region"`
 	DatabaseVersion             string              `yaml:"database_version"`
+	AvailabilityType            string              `yaml:"availability_type,omitempty"`
 	NetworkConfig               NetworkConfigStruct `yaml:"network_config"`
 	TerraformDeletionProtection bool                `yaml:"terraform_deletion_protection"`
 	GCPDeletionProtection       bool                `yaml:"gcp_deletion_protection"`
 }
 
 /*
-This test creates all the pre-requsite resources including the vpc network, subnetwork along with a PSA range.
-It then validates if
+This test runs once per cell of matrix.yaml, whose axes are the database
+version, PSA or PSC connectivity, the region and HA. The PSA cells share a
+vpc network with a PSA range, created before the first of them and deleted
+after the last cell. Set TEST_MATRIX_FILTER, e.g. to "connectivity=psa,ha=false", to run
+some of the cells. The cells run in parallel, each in its own copy of the
+stage. Each cell validates if
 1. CloudSQL instance is created.
-2. CloudSQL instance is created in the correct network, project, region and of correct version.
-3. CloudSQL instance only have a private ip and does not have a public IP.
+2. CloudSQL instance is created in the correct project, region and of correct version.
+3. CloudSQL instance does not have a public IP, and has a private ip with PSA.
 */
 func TestCreateCloudSQL(t *testing.T) {
+	m, err := matrix.Load("matrix.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Run(t, func(t *testing.T, c matrix.Cell, s *matrix.Shared) {
+		t.Parallel()
+		if c.Get("connectivity") != "psa" {
+			testCreateCloudSQL(t, c)
+			return
+		}
+		_, err := matrix.Fixture(s, "network", func() (string, func(*testing.T), error) {
+			// Create VPC outside of the terraform module.
+			common_utils.CreateVPCSubnets(t, projectID, networkName, "", "")
+			// Create PSA in the VPC.
+			common_utils.CreatePSA(t, projectID, networkName, rangeName)
+			return networkID, func(t *testing.T) {
+				// Remove PSA from the VPC.
+				common_utils.DeletePSA(t, projectID, networkName, rangeName)
+				// Delete VPC created outside of the terraform module.
+				common_utils.DeleteVPCSubnets(t, projectID, networkName, "", "")
+			}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		testCreateCloudSQL(t, c)
+	})
+}
+
+/*
+testCreateCloudSQL creates and validates the Cloud SQL instance of a cell of
+the matrix. Each cell plans a private copy of the stage, with its own state
+and configuration folder, and destroys its instance.
+*/
+func testCreateCloudSQL(t *testing.T, c matrix.Cell) {
+	name := fmt.Sprintf("cloudsql-%d-%s", runID, c.Suffix())
+	region := c.Get("region")
+	databaseVersion := c.Get("database_version")
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, "config")
 	// Initialize a Cloud SQL config YAML file to be tested.
-	createConfigYAML(t)
+	createConfigYAML(t, c, name, configDir)
 	var (
 		tfVars = map[string]any{
-			"config_folder_path": configFolderPath,
+			"config_folder_path": configDir,
 		}
 	)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
 		// Set the path to the Terraform code that will be tested.
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
 		SetVarsAfterVarFiles: true,
 	})
-	// Create VPC outside of the terraform module.
-	common_utils.CreateVPCSubnets(t, projectID, networkName, "", "")
-
-	// Create PSA in the VPC.
-	common_utils.CreatePSA(t, projectID, networkName, rangeName)
-	// Delete VPC created outside of the terraform module.
-	defer common_utils.DeleteVPCSubnets(t, projectID, networkName, "", "")
-	// Remove PSA from the VPC.
-	defer common_utils.DeletePSA(t, projectID, networkName, rangeName)
 	// Clean up resources with "terraform destroy" at the end of the test.
 	defer terraform.Destroy(t, terraformOptions)
 	// Run "terraform init" and "terraform apply". Fail the test if there are any errors.
@@ -151,6 +187,9 @@ func TestCreateCloudSQL(t *testing.T) {
 		t.Errorf("Cloud SQL Instance with public ip created(should be a private ip only) = %v", got)
 	}
 
+	if c.Get("connectivity") != "psa" {
+		return
+	}
 	t.Log(" ========= Verify Cloud SQL Instance does  have a private ip ========= ")
 	cloudSQLPrivateIPPath := fmt.Sprintf("%s.private_ip_address", name)
 	got = gjson.Get(result.String(), cloudSQLPrivateIPPath).String()
@@ -161,33 +200,39 @@ func TestCreateCloudSQL(t *testing.T) {
 
 /*
 createConfigYAML is a helper function which creates the configigration YAML file
-for a cloudsql instance.
+for the cloudsql instance of a cell of the matrix in the folder dir. The connectivity axis picks
+PSA in the shared network or PSC, and the config of the cell, such as the
+availability type of the ha axis, is overlaid on the instance.
 */
-func createConfigYAML(t *testing.T) {
+func createConfigYAML(t *testing.T, c matrix.Cell, name, dir string) {
 	t.Log("========= YAML File =========")
 	instance1 := CloudSQLStruct{
 		Name:                        name,
 		ProjectID:                   projectID,
-		Region:                      region,
-		DatabaseVersion:             databaseVersion,
+		Region:                      c.Get("region"),
+		DatabaseVersion:             c.Get("database_version"),
 		TerraformDeletionProtection: false,
 		GCPDeletionProtection:       false,
-		NetworkConfig: NetworkConfigStruct{
-			Connectivity: ConnectivityStruct{
-				PSAConfig: PSAConfigStruct{
-					PrivateNetwork: networkID,
-					AllocatedIPRanges: AllocatedIPRangesStruct{
-						Primary: rangeName,
-					},
-				},
+	}
+	switch c.Get("connectivity") {
+	case "psa":
+		instance1.NetworkConfig.Connectivity.PSAConfig = PSAConfigStruct{
+			PrivateNetwork: networkID,
+			AllocatedIPRanges: AllocatedIPRangesStruct{
+				Primary: rangeName,
 			},
-		},
+		}
+	case "psc":
+		instance1.NetworkConfig.Connectivity.PSCAllowedConsumerProjects = []string{projectID}
+	}
+	if err := c.Decode(&instance1); err != nil {
+		t.Fatalf("Error while applying the config of cell %s: %v", c.Name(), err)
 	}
 	yamlData, err := yaml.Marshal(&instance1)
 	if err != nil {
 		t.Errorf("Error while marshallaing %v", err)
 	}
-	filePath := fmt.Sprintf("%s/%s", "config", "instance1.yaml")
+	filePath := fmt.Sprintf("%s/%s", dir, "instance1.yaml")
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 	err = os.WriteFile(filePath, []byte(yamlData), 0666)
 	if err != nil {
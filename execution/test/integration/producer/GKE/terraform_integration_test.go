@@ -26,6 +26,7 @@ import (
 
 	// for sorting slices
 	// for comparison operations
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/google/go-cmp/cmp"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
@@ -77,17 +78,20 @@ type GKEConfig struct {
 // TestCreateGKECluster tests the creation of a GKE cluster.
 func TestCreateGKECluster(t *testing.T) {
 	// Initialize a GKE config YAML file to be tested.
-	createGKEConfigYAML(t)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createGKEConfigYAML(t, configDir)
 
 	var (
 		tfVars = map[string]any{
-			"config_folder_path": configFolderPath,
+			"config_folder_path": configDir,
 		}
 	)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -180,9 +184,11 @@ func TestTerraformModuleResourceAddressListMatch(t *testing.T) {
 	expectedModulesAddress := []string{fmt.Sprintf("module.gke[\"%s\"]", gkeConfig.Name)}
 
 	// Terraform options for planning.
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -233,11 +239,12 @@ func TestInitAndPlanRunWithInvalidTfVarsExpectFailureScenario(t *testing.T) {
 	*/
 	// Construct the terraform options with default retryable errors to handle the most common
 	// retryable errors in terraform testing.
-
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
 		// Set the path to the Terraform code that will be tested.
-		TerraformDir: terraformDirectoryPath,
-		Vars:         invalidTFVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(invalidTFVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -255,9 +262,11 @@ func TestInitAndPlanRunWithInvalidTfVarsExpectFailureScenario(t *testing.T) {
 // succeed with the provided variables. It expects changes (exit code 2) as it's not applying.
 
 func TestInitAndPlanRunWithTfVars(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -277,9 +286,11 @@ func TestInitAndPlanRunWithTfVars(t *testing.T) {
 
 // TestResourcesCount verifies the number of resources to be added by the Terraform plan.
 func TestResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -304,9 +315,9 @@ func TestResourcesCount(t *testing.T) {
 }
 
 /*
-createGKEConfigYAML creates the YAML configuration file for GKE.
+createGKEConfigYAML creates the YAML configuration file for GKE in configDir.
 */
-func createGKEConfigYAML(t *testing.T) {
+func createGKEConfigYAML(t *testing.T, configDir string) {
 	t.Log("========= YAML File =========")
 	gkeConfig := GKEConfig{
 		Name:                  instanceName,
@@ -325,7 +336,7 @@ func createGKEConfigYAML(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error while marshalling YAML: %v", err)
 	}
-	filePath := filepath.Join(configFolderPath, "gke-config.yaml")
+	filePath := filepath.Join(configDir, "gke-config.yaml")
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
 	err = os.WriteFile(filePath, []byte(yamlData), 0666)
@@ -21,6 +21,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/matrix"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -32,16 +34,11 @@ var (
 	projectRoot, _ = filepath.Abs("../../../../")
 	// Path to the Terraform module directory.
 	terraformDirectoryPath = filepath.Join(projectRoot, "04-producer/MRC")
-	// Path to the folder containing YAML configuration files.
-	configFolderPath = filepath.Join(projectRoot, "test/integration/producer/MRC/config")
 )
 
 var (
 	projectID                 = os.Getenv("TF_VAR_project_id")
-	region                    = "us-central1"
-	instanceName              = fmt.Sprintf("mrc-%d", rand.Int())
-	networkName               = fmt.Sprintf("vpc-%s-test", instanceName)
-	networkID                 = fmt.Sprintf("projects/%s/global/networks/%s", projectID, networkName)
+	runID                     = rand.Int()
 	deletionProtectionEnabled = false
 )
 
@@ -50,39 +47,71 @@ type MRCStruct struct {
 	ProjectID                 string `yaml:"project_id"`
 	NetworkID                 string `yaml:"network_id"`
 	Region                    string `yaml:"region"`
+	ShardCount                int    `yaml:"shard_count"`
+	ReplicaCount              int    `yaml:"replica_count"`
 	DeletionProtectionEnabled bool   `yaml:"deletion_protection_enabled"`
 }
 
-// GetFirstNonEmptyEnvVarOrUseDefault retrieves the first non-empty environment variable
-// from the provided list, or falls back to a default value if none are set.
+/*
+TestCreateMRC runs once per cell of matrix.yaml, whose axes are the region and
+HA, which sets the replica count. The cells of a region share a vpc network
+with a subnet and a service connection policy, created before the first of
+them and deleted after the last cell. Set TEST_MATRIX_FILTER, e.g. to
+"ha=false", to run some of the cells. The cells run in parallel, each in its
+own copy of the stage.
+*/
 func TestCreateMRC(t *testing.T) {
+	m, err := matrix.Load("matrix.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Run(t, func(t *testing.T, c matrix.Cell, s *matrix.Shared) {
+		t.Parallel()
+		region := c.Get("region")
+		networkName, err := matrix.Fixture(s, "network-"+region, func() (string, func(*testing.T), error) {
+			networkName := fmt.Sprintf("vpc-mrc-%d-%s-test", runID, c.Suffix())
+			// Create VPC, subnet, and service connection policy
+			createVPC(t, projectID, networkName, region)
+			time.Sleep(60 * time.Second)
+			return networkName, func(t *testing.T) {
+				// Delete VPC, subnet, and service connection policy
+				deleteVPC(t, projectID, networkName, region)
+			}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		testCreateMRC(t, c, networkName)
+	})
+}
+
+/*
+testCreateMRC creates and validates the MRC cluster of a cell of the matrix.
+Each cell plans a private copy of the stage, with its own state and
+configuration folder, and destroys its cluster.
+*/
+func testCreateMRC(t *testing.T, c matrix.Cell, networkName string) {
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, "config")
 	// Initialize a MRC config YAML file to be tested.
-	createConfigYAML(t)
+	instance := createConfigYAML(t, c, networkName, configDir)
 
 	var (
 		tfVars = map[string]any{
-			"config_folder_path": configFolderPath,
-			"shard_count":        3,
-			"replica_count":      1, // Example: Assuming replica_count is a variable
+			"config_folder_path": configDir,
 		}
 	)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
 		SetVarsAfterVarFiles: true,
 	})
 
-	// Create VPC, subnet, and service connection policy
-	createVPC(t, projectID, networkName)
-	time.Sleep(60 * time.Second)
-
-	// Delete VPC, subnet, and service connection policy
-	defer deleteVPC(t, projectID, networkName)
-
 	// Clean up resources with "terraform destroy" at the end of the test.
 	defer terraform.Destroy(t, terraformOptions)
 
@@ -129,7 +158,7 @@ func TestCreateMRC(t *testing.T) {
 			expectedNetworkID := value.Get("network").String()
 			cmd := shell.Command{
 				Command: "gcloud",
-				Args:    []string{"redis", "clusters", "describe", instanceName, "--project=" + projectID, "--region=" + region, "--format=json", "--verbosity=none", "--quiet"},
+				Args:    []string{"redis", "clusters", "describe", instanceName, "--project=" + projectID, "--region=" + instance.Region, "--format=json", "--verbosity=none", "--quiet"},
 			}
 			output, err := shell.RunCommandAndGetOutputE(t, cmd)
 			if err != nil {
@@ -148,14 +177,14 @@ func TestCreateMRC(t *testing.T) {
 
 			// 4. Verify Shard Count
 			gotShardCount := value.Get("shard_count").Int()
-			if gotShardCount != 3 {
-				t.Errorf("MRC Cluster '%s' has invalid shard count: got %d, want 3", instanceName, gotShardCount)
+			if gotShardCount != int64(instance.ShardCount) {
+				t.Errorf("MRC Cluster '%s' has invalid shard count: got %d, want %d", instanceName, gotShardCount, instance.ShardCount)
 			}
 
 			// 5. Verify Replica Count
 			gotReplicaCount := value.Get("replica_count").Int()
-			if gotReplicaCount != 1 {
-				t.Errorf("MRC Cluster '%s' has invalid replica count: got %d, want 1", instanceName, gotReplicaCount)
+			if gotReplicaCount != int64(instance.ReplicaCount) {
+				t.Errorf("MRC Cluster '%s' has invalid replica count: got %d, want %d", instanceName, gotReplicaCount, instance.ReplicaCount)
 			}
 			return true // Continue iterating to the next instance
 		})
@@ -171,7 +200,7 @@ func TestCreateMRC(t *testing.T) {
 /*
 createVPC creates the VPC, subnet, and service connection policy before the test execution.
 */
-func createVPC(t *testing.T, projectID string, networkName string) {
+func createVPC(t *testing.T, projectID string, networkName string, region string) {
 	text := "compute"
 
 	// Create VPC
@@ -225,7 +254,7 @@ func createVPC(t *testing.T, projectID string, networkName string) {
 /*
 deleteVPC deletes the VPC, subnet, and service connection policy after the test.
 */
-func deleteVPC(t *testing.T, projectID string, networkName string) {
+func deleteVPC(t *testing.T, projectID string, networkName string, region string) {
 	text := "compute"
 	time.Sleep(60 * time.Second)
 
@@ -275,27 +304,32 @@ func deleteVPC(t *testing.T, projectID string, networkName string) {
 
 /*
 createConfigYAML is a helper function which creates the configigration YAML file
-for an MRC instance.
+for the MRC instance of a cell of the matrix in the folder dir, with the config of the cell, such
+as the replica count of the ha axis, overlaid on the instance.
 */
-func createConfigYAML(t *testing.T) {
+func createConfigYAML(t *testing.T, c matrix.Cell, networkName, dir string) MRCStruct {
 	t.Log("========= YAML File =========")
 	instance1 := MRCStruct{
-		InstanceName:              instanceName,
+		InstanceName:              fmt.Sprintf("mrc-%d-%s", runID, c.Suffix()),
 		ProjectID:                 projectID,
-		NetworkID:                 networkID,
-		Region:                    region,
+		NetworkID:                 fmt.Sprintf("projects/%s/global/networks/%s", projectID, networkName),
+		Region:                    c.Get("region"),
 		DeletionProtectionEnabled: deletionProtectionEnabled,
 	}
+	if err := c.Decode(&instance1); err != nil {
+		t.Fatalf("Error while applying the config of cell %s: %v", c.Name(), err)
+	}
 
 	yamlData, err := yaml.Marshal(&instance1)
 	if err != nil {
 		t.Errorf("Error while marshallaing %v", err)
 	}
-	filePath := fmt.Sprintf("%s/%s", "config", "instance1.yaml")
+	filePath := fmt.Sprintf("%s/%s", dir, "instance1.yaml")
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
 	err = os.WriteFile(filePath, []byte(yamlData), 0666)
 	if err != nil {
 		t.Errorf("Unable to write data into the file %v", err)
 	}
+	return instance1
 }
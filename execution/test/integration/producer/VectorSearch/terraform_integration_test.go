@@ -15,6 +15,7 @@ package integrationtest
 
 import (
 	"fmt"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -61,13 +62,14 @@ performs verification on successfull creation of the vector search resources.
 */
 func TestCreateVectorSearch(t *testing.T) {
 	// Initialize a Vector Search config YAML file to be tested.
-	createConfigYAML(t)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, "config")
+	createConfigYAML(t, configDir)
 
 	// provider.tf already exists in the test pipeline and the following code will not be required.
 	if os.Getenv("ENTER_TF_PRODUCER_VECTOR_SEARCH_PREFIX") == "" {
 		sourceFile := "provider.tf"
-		destinationFile := terraformDirectoryPath + "/test-provider.tf"
-		defer os.Remove(destinationFile)
+		destinationFile := ws.Dir + "/test-provider.tf"
 
 
 	source, err := os.Open(sourceFile)
@@ -89,13 +91,14 @@ func TestCreateVectorSearch(t *testing.T) {
 	}
 	var (
 		tfVars = map[string]any{
-			"config_folder_path": configFolderPath,
+			"config_folder_path": configDir,
 		}
 	)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
 		// Set the path to the Terraform code that will be tested.
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -240,9 +243,9 @@ func createPSA(t *testing.T, projectID string, networkName string, rangeName str
 
 /*
 createConfigYAML is a helper function which creates the config YAML file which is used
-for creation of test instance.
+for creation of test instance, in configDir.
  */
-func createConfigYAML(t *testing.T) {
+func createConfigYAML(t *testing.T, configDir string) {
 	// Fetch Project Number
 	text := "projects"
 	cmd := shell.Command{
@@ -272,7 +275,7 @@ func createConfigYAML(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error while marshallaing %v", err)
 	}
-	filePath := fmt.Sprintf("%s/%s", "config", "instance1.yaml")
+	filePath := fmt.Sprintf("%s/%s", configDir, "instance1.yaml")
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 	err = os.WriteFile(filePath, []byte(yamlData), 0666)
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -60,17 +61,20 @@ func TestCreateEndpointWithVPC(t *testing.T) {
 	createVPC(t, projectID, VPCName)
 	defer deleteVPC(t, projectID, VPCName)
 
-	createEndpointConfigYAML(t, VPCName, "endpoint_vpc.yaml")
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createEndpointConfigYAML(t, configDir, VPCName, "endpoint_vpc.yaml")
 
 	var (
 		tfVars = map[string]interface{}{
-			"config_folder_path": configFolderPath,
+			"config_folder_path": configDir,
 		}
 	)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -82,7 +86,7 @@ func TestCreateEndpointWithVPC(t *testing.T) {
 	terraform.InitAndApply(t, terraformOptions)
 
 	// Read the YAML file
-	yamlConfig, err := readEndpointConfigYAML("endpoint_vpc.yaml")
+	yamlConfig, err := readEndpointConfigYAML(configDir, "endpoint_vpc.yaml")
 	if err != nil {
 		t.Logf("Error reading YAML config: %v", err)
 	}
@@ -90,8 +94,8 @@ func TestCreateEndpointWithVPC(t *testing.T) {
 	validateEndpoints(t, terraformOptions, yamlConfig.Network)
 }
 
-// Function to create a YAML config for the Online Endpoint
-func createEndpointConfigYAML(t *testing.T, vpcName string, fileName string) {
+// Function to create a YAML config for the Online Endpoint in configDir
+func createEndpointConfigYAML(t *testing.T, configDir string, vpcName string, fileName string) {
 	t.Log("========= YAML File =========")
 
 	// Generate a unique endpoint name with a timestamp
@@ -112,7 +116,7 @@ func createEndpointConfigYAML(t *testing.T, vpcName string, fileName string) {
 		t.Errorf("Error while marshalling %v", err)
 	}
 
-	filePath := fmt.Sprintf("%s/%s", configFolderPath, fileName)
+	filePath := fmt.Sprintf("%s/%s", configDir, fileName)
 	t.Logf("Created YAML config at %s with content:\n%s", filePath, string(yamlData))
 
 	err = os.WriteFile(filePath, []byte(yamlData), 0666)
@@ -121,9 +125,9 @@ func createEndpointConfigYAML(t *testing.T, vpcName string, fileName string) {
 	}
 }
 
-// readEndpointConfigYAML reads the YAML file and returns the EndpointConfig struct
-func readEndpointConfigYAML(fileName string) (*EndpointConfig, error) {
-	filePath := fmt.Sprintf("%s/%s", configFolderPath, fileName)
+// readEndpointConfigYAML reads the YAML file of configDir and returns the EndpointConfig struct
+func readEndpointConfigYAML(configDir string, fileName string) (*EndpointConfig, error) {
+	filePath := fmt.Sprintf("%s/%s", configDir, fileName)
 	yamlData, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read YAML file: %v", err)
@@ -347,30 +351,3 @@ func deleteVPC(t *testing.T, projectID string, networkName string) {
 		t.Errorf("===Error %s Encountered while executing %s", err, text)
 	}
 }
-
-func TestMain(m *testing.M) {
-	if err := cleanupYAMLFiles(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error cleaning up YAML files: %v\n", err)
-		// Allow tests to run, but signal a setup failure
-		m.Run()
-		os.Exit(1) // Exit after tests complete
-	}
-
-	os.Exit(m.Run())
-}
-
-// cleanupYAMLFiles cleans up unused old YAML files.
-func cleanupYAMLFiles() error {
-	files, err := filepath.Glob(filepath.Join(configFolderPath, "*.yaml"))
-	if err != nil {
-		return fmt.Errorf("failed to list YAML files: %v", err)
-	}
-
-	for _, file := range files {
-		if err := os.Remove(file); err != nil {
-			return fmt.Errorf("failed to remove file %s: %v", file, err)
-		}
-	}
-
-	return nil
-}
@@ -16,6 +16,7 @@ package integrationtest
 
 import (
 	"fmt"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/shell"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/tidwall/gjson"
@@ -57,19 +58,22 @@ It then validates if Regional and Global network firewall policies are created a
 */
 func TestCreateFirewallPolicy(t *testing.T) {
 	// Initialize Network Firewall Policy config YAML files
-	createConfigYAMLs(t, region, projectID, regionalFirewallPolicyVPCName, regionalFirewallPolicy)
-	createConfigYAMLs(t, global, projectID, globalFirewallPolicyVPCName, globalFirewallPolicy)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, "config")
+	createConfigYAMLs(t, configDir, region, projectID, regionalFirewallPolicyVPCName, regionalFirewallPolicy)
+	createConfigYAMLs(t, configDir, global, projectID, globalFirewallPolicyVPCName, globalFirewallPolicy)
 
 	var (
 		tfVars = map[string]any{
-			"config_folder_path": configFolderPath,
+			"config_folder_path": configDir,
 		}
 	)
 
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
 		// Set the path to the Terraform code that will be tested.
-		Vars:                 tfVars,
-		TerraformDir:         terraformDirectoryPath,
+		Vars:                 ws.Vars(tfVars),
+		EnvVars:              ws.EnvVars(),
+		TerraformDir:         ws.Dir,
 		Reconfigure:          true,
 		Lock:                 true,
 		NoColor:              true,
@@ -160,8 +164,9 @@ func createVPC(t *testing.T, projectID string, networkName string) error {
 /*
 createConfigYAML is a helper function which creates the configuration YAML file
 for an network firewall policy instance range before the.
+The file is written into configDir.
 */
-func createConfigYAMLs(t *testing.T, region string, projectID string, vPCName string, firewallPolicyType string) {
+func createConfigYAMLs(t *testing.T, configDir string, region string, projectID string, vPCName string, firewallPolicyType string) {
 
 	instance := FirewallPolicyStruct{
 		Name:     fmt.Sprintf("%s", firewallPolicyType),
@@ -176,7 +181,7 @@ func createConfigYAMLs(t *testing.T, region string, projectID string, vPCName st
 	if err != nil {
 		t.Errorf("Error marshalling instance for %s: %v", firewallPolicyType, err)
 	}
-	filePath := fmt.Sprintf("%s/%s-%s", "config", firewallPolicyType, "instance.yaml")
+	filePath := fmt.Sprintf("%s/%s-%s", configDir, firewallPolicyType, "instance.yaml")
 	err = os.WriteFile(filePath, []byte(yamlData), 0666)
 	if err != nil {
 		t.Errorf("Unable to write instance data for %s: %v", filePath, err)
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/test/integration/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/random"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
@@ -86,17 +87,20 @@ func TestSecurityProfileIntegration(t *testing.T) {
 	defer common_utils.DeleteGCEInstance(t, projectID, vmClientName, zone)
 
 	profileGroupName := "spg-integ-test-" + instanceSuffix
-	createConfigYAML(t, orgID, "sp-integ-test-"+instanceSuffix, profileGroupName)
+	ws := workspace.New(t, terraformDirectoryPath)
+	configDir := workspace.ConfigDir(t, configFolderPath)
+	createConfigYAML(t, configDir, orgID, "sp-integ-test-"+instanceSuffix, profileGroupName)
 
 	common_utils.CreateOrgFirewallPolicy(t, orgID, firewallPolicyName)
 	defer common_utils.DeleteOrgFirewallPolicy(t, orgID, firewallPolicyName)
 
 	tfVars := map[string]interface{}{
-		"config_folder_path": configFolderPath,
+		"config_folder_path": configDir,
 	}
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		Vars:         ws.Vars(tfVars),
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		NoColor:      true,
 	})
@@ -128,7 +132,7 @@ func TestSecurityProfileIntegration(t *testing.T) {
 	t.Fatalf("Timeout: Did not find success or failure message in serial port logs for VM '%s'.", vmClientName)
 }
 
-func createConfigYAML(t *testing.T, orgID, profileName, groupName string) {
+func createConfigYAML(t *testing.T, configDir, orgID, profileName, groupName string) {
 	type securityProfile struct {
 		Create                  bool                   `yaml:"create"`
 		Name                    string                 `yaml:"name"`
@@ -168,10 +172,7 @@ func createConfigYAML(t *testing.T, orgID, profileName, groupName string) {
 	yamlData, err := yaml.Marshal(&config)
 	assert.NoError(t, err)
 
-	err = os.MkdirAll(configFolderPath, 0755)
-	assert.NoError(t, err)
-
-	filePath := filepath.Join(configFolderPath, "instance.yaml")
+	filePath := filepath.Join(configDir, "instance.yaml")
 	err = os.WriteFile(filePath, yamlData, 0644)
 	assert.NoError(t, err)
 	t.Logf("Created test YAML config file: %s", filePath)
@@ -22,6 +22,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform" // Terraform testing library
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -48,9 +49,11 @@ code differs, it logs an error.
 */
 
 func TestInitAndPlanRunWithTfVars(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -75,9 +78,11 @@ count matches the expected value of 7. An error is logged if the count differs.
 */
 
 func TestResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -133,10 +138,12 @@ func TestTerraformModuleLBResourceAddressListMatch(t *testing.T) {
 		}
 	}
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	// Initialize Terraform and generate a plan.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
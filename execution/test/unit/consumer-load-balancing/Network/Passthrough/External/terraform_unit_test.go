@@ -23,6 +23,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -50,9 +51,11 @@ It expects an exit code of 2, indicating that changes are planned.
 If the exit code differs, it logs an error.
 */
 func TestInitAndPlanRunWithTfVarsNLB(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathNLB)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathNLB,
-		Vars:         tfVarsNLB,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsNLB),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan-nlb", // Use a distinct plan file name
@@ -83,9 +86,11 @@ If you have one YAML file defining one NLB, and it creates these 3 main resource
 the count would be 3.
 */
 func TestResourcesCountNLB(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathNLB)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathNLB,
-		Vars:         tfVarsNLB,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsNLB),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan-nlb", // Use a distinct plan file name
@@ -187,10 +192,12 @@ func TestTerraformModuleNLBResourceAddressListMatch(t *testing.T) {
 			"If this is unexpected, check your configFolderPathNLB and test setup.", configFolderPathNLB)
 	}
 
+	ws := workspace.New(t, terraformDirectoryPathNLB)
 	// Initialize Terraform and generate a plan.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathNLB,
-		Vars:         tfVarsNLB,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsNLB),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan-nlb-addressmatch", // Use a distinct plan file name
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 )
@@ -34,8 +35,10 @@ const (
 func TestInitAndValidate(t *testing.T) {
 	t.Parallel()
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
 		NoColor:      true,
 	})
 
@@ -67,12 +70,14 @@ region: us-central1
 	err = os.WriteFile(invalidFilePath, invalidYAML, 0644)
 	assert.NoError(t, err)
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	// Define Terraform options, pointing config_folder_path to our temporary directory.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": tempDir,
-		},
+		}),
 		Reconfigure: true,
 		Lock:        true,
 		NoColor:     true,
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform" // Terraform testing library
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -46,9 +47,11 @@ var (
 )
 
 func TestInitAndPlanRunWithTfVars(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -68,9 +71,11 @@ func TestInitAndPlanRunWithTfVars(t *testing.T) {
 
 // TestResourcesCount verifies the number of resources to be added by the Terraform plan.
 func TestResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -87,9 +92,11 @@ func TestResourcesCount(t *testing.T) {
 }
 
 func TestTerraformModuleVMResourceAddressListMatch(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform" // Terraform testing library
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -44,9 +45,11 @@ var (
 )
 
 func TestInitAndPlanRunWithTfVars(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -66,9 +69,11 @@ func TestInitAndPlanRunWithTfVars(t *testing.T) {
 
 // TestResourcesCount verifies the number of resources to be added by the Terraform plan.
 func TestResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -116,10 +121,12 @@ func TestTerraformModuleMIGResourceAddressListMatch(t *testing.T) {
 		}
 	}
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	// Initialize Terraform and generate a plan.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -34,12 +35,14 @@ var (
 
 // Create the resources using the terrafrom consumer code
 func TestInitAndPlan(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	// Construct the terraform options.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath, // Use the YAML configs
-		},
+		}),
 		Reconfigure:  true, // Important for switching between test cases
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -59,12 +62,14 @@ func TestInitAndPlan(t *testing.T) {
 
 // Count the number of resources created expected vs actual
 func TestResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	// Construct the terraform options.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath, // Use the YAML configs
-		},
+		}),
 		Reconfigure:  true, // Important for switching between test cases
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -109,11 +114,13 @@ func TestResourcesCount(t *testing.T) {
 
 // Match Expected resources to Created resources to ensure correct resource creation
 func TestTerraformModuleResourceAddressListMatch(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath,
-		},
+		}),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -153,12 +160,14 @@ func TestTerraformModuleResourceAddressListMatch(t *testing.T) {
 func TestInitAndPlanFailure(t *testing.T) {
 	t.Parallel()
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath,           // Use ALL configs, including invalid
 			"project_id":         "dummy-project-id-failure", //Required for plan
-		},
+		}),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -36,12 +37,14 @@ var (
 )
 
 func TestInitAndPlan(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	// Construct the terraform options.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath, // Use the YAML configs
-		},
+		}),
 		Reconfigure:  true, // Important for switching between test cases
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -59,12 +62,14 @@ func TestInitAndPlan(t *testing.T) {
 
 }
 func TestResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	// Construct the terraform options.
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath, // Use the YAML configs
-		},
+		}),
 		Reconfigure:  true, // Important for switching between test cases
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -108,11 +113,13 @@ func TestResourcesCount(t *testing.T) {
 }
 
 func TestTerraformModuleResourceAddressListMatch(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath, // Use the YAML configs
-		},
+		}),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -176,12 +183,14 @@ func TestTerraformModuleResourceAddressListMatch(t *testing.T) {
 func TestInitAndPlanFailure(t *testing.T) {
 	t.Parallel()
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath,           // Use ALL configs, including invalid
 			"project_id":         "dummy-project-id-failure", //Required for plan to fail
-		},
+		}),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
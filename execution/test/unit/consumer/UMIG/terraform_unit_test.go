@@ -22,6 +22,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -40,9 +41,11 @@ var (
 )
 
 func TestInitAndPlanRunWithTfVars(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -59,9 +62,11 @@ func TestInitAndPlanRunWithTfVars(t *testing.T) {
 }
 
 func TestResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -108,9 +113,11 @@ func TestTerraformModuleUMIGResourceAddressListMatch(t *testing.T) {
 		}
 	}
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -157,12 +164,14 @@ func TestTerraformModuleUMIGResourceAddressListMatch(t *testing.T) {
 func TestInitAndPlanFailure(t *testing.T) {
 	t.Parallel()
 
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
-		Vars: map[string]interface{}{
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars: ws.Vars(map[string]interface{}{
 			"config_folder_path": configFolderPath,           // Use ALL configs, including invalid
 			"project_id":         "dummy-project-id-failure", //Required for plan
-		},
+		}),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,9 +42,11 @@ var (
 )
 
 func TestPacketMirroringPlanSuccess(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathPM)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPM,
-		Vars:         tfVarsSuccess,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsSuccess),
 		Reconfigure:  true,
 		PlanFilePath: "./plan",
 		NoColor:      true,
@@ -56,9 +59,11 @@ func TestPacketMirroringResourcesCount(t *testing.T) {
 	expectedAddCount := 7
 	expectedChangeCount := 0
 	expectedDestroyCount := 0
+	ws := workspace.New(t, terraformDirectoryPathPM)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPM,
-		Vars:         tfVarsSuccess,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsSuccess),
 		Reconfigure:  true,
 		PlanFilePath: "./plan",
 		NoColor:      true,
@@ -73,9 +78,11 @@ func TestPacketMirroringResourcesCount(t *testing.T) {
 }
 
 func TestPacketMirroringModuleAddressListMatch(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathPM)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPM,
-		Vars:         tfVarsSuccess,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsSuccess),
 		Reconfigure:  true,
 		PlanFilePath: "./plan",
 		NoColor:      true,
@@ -122,9 +129,11 @@ func TestPacketMirroringPlanFailure(t *testing.T) {
 		"config_folder_path": tempConfigDir,
 		"project_id":         "test-project",
 	}
+	ws := workspace.New(t, terraformDirectoryPathPM)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPM,
-		Vars:         tfVarsFailure,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsFailure),
 		Reconfigure:  true,
 		PlanFilePath: "./plan",
 		NoColor:      true,
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/exp/slices"
@@ -40,9 +41,11 @@ var (
 )
 
 func TestPMRulePlanSuccess(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathPMR)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPMR,
-		Vars:         tfVarsPMR,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsPMR),
 		Reconfigure:  true,
 		PlanFilePath: "./plan_pmr_success",
 		NoColor:      true,
@@ -77,9 +80,11 @@ match:
 	tfVarsFailure := map[string]any{
 		"config_folder_path": tempConfigDir,
 	}
+	ws := workspace.New(t, terraformDirectoryPathPMR)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPMR,
-		Vars:         tfVarsFailure,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsFailure),
 		Reconfigure:  true,
 		PlanFilePath: "./plan_pmr_failure",
 		NoColor:      true,
@@ -105,9 +110,11 @@ func TestPMRuleResourcesCount(t *testing.T) {
 		}
 	}
 	assert.NotZero(t, expectedResourceCount, "No YAML files found in the test config directory")
+	ws := workspace.New(t, terraformDirectoryPathPMR)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPMR,
-		Vars:         tfVarsPMR,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsPMR),
 		Reconfigure:  true,
 		PlanFilePath: "./plan_pmr_count",
 		NoColor:      true,
@@ -126,9 +133,11 @@ func TestPMRuleResourcesCount(t *testing.T) {
 
 func TestPMRuleModuleAddressListMatch(t *testing.T) {
 	t.Parallel()
+	ws := workspace.New(t, terraformDirectoryPathPMR)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathPMR,
-		Vars:         tfVarsPMR,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsPMR),
 		Reconfigure:  true,
 		PlanFilePath: "./plan_pmr_match",
 		NoColor:      true,
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,9 +45,11 @@ var (
 
 // TestFirewallEndpointPlanExitCode verifies that the plan exits with a code of 2, indicating changes are planned.
 func TestFirewallEndpointPlanExitCode(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathFE)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathFE,
-		Vars:         tfVarsFE,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsFE),
 		Reconfigure:  true,
 		PlanFilePath: "./plan_fe",
 		NoColor:      true,
@@ -58,9 +61,11 @@ func TestFirewallEndpointPlanExitCode(t *testing.T) {
 
 // TestFirewallEndpointResourcesCount verifies the number of resources to be added by the plan.
 func TestFirewallEndpointResourcesCount(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathFE)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathFE,
-		Vars:         tfVarsFE,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsFE),
 		Reconfigure:  true,
 		PlanFilePath: "./plan_fe",
 		NoColor:      true,
@@ -78,9 +83,11 @@ func TestFirewallEndpointResourcesCount(t *testing.T) {
 // TestFirewallEndpointModuleAddressListMatch verifies that a module instance is planned for each YAML config file.
 func TestFirewallEndpointModuleAddressListMatch(t *testing.T) {
 	t.Parallel()
+	ws := workspace.New(t, terraformDirectoryPathFE)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathFE,
-		Vars:         tfVarsFE,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVarsFE),
 		Reconfigure:  true,
 		PlanFilePath: "./plan_fe",
 		NoColor:      true,
@@ -17,6 +17,7 @@ package unittest
 import (
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 )
 
@@ -93,8 +94,10 @@ func initTfVars() {
 
 func TestInitAndValidate(t *testing.T) {
 	initTfVars()
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
 		NoColor:      true,
 	})
 
@@ -106,8 +109,10 @@ func TestInitAndValidate(t *testing.T) {
 }
 
 func TestPlanFailsWithoutInputVariables(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: planFilePath,
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform" // Terraform testing library
 )
 
@@ -31,8 +32,10 @@ var (
 
 // TestTerraformConfigValidity checks if the Terraform configuration files are valid.
 func TestTerraformConfigValidity(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
 		NoColor:      true,
 	})
 
@@ -52,8 +55,10 @@ func TestTerraformConfigValidity(t *testing.T) {
 
 // TestTerraformInit checks if the Terraform initialization runs correctly.
 func TestTerraformInit(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPath,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
 		NoColor:      true,
 	})
 
@@ -16,10 +16,12 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -83,6 +85,34 @@ func setupTerraformMock(t *testing.T, outputFile string) (string, func()) {
 	return tempDir, cleanup
 }
 
+// setupScriptedTerraformMock creates a temporary directory with a fake 'terraform' executable inside it,
+// which records the stage directory and arguments of every call and replays the files of the
+// '.terraform-mock' directory of the stage: 'state' for 'state list', 'exit_code' for 'plan' and
+// 'show.json' for 'show'.
+func setupScriptedTerraformMock(t *testing.T, outputFile, executionDir string) (string, func()) {
+	tempDir, err := os.MkdirTemp("", "test-tf-mock")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	mockScriptContent := fmt.Sprintf(`#!/bin/sh
+# Scripted Mock Terraform
+echo "${PWD#%s/}: $*" >> %s
+case "$1" in
+    state) cat .terraform-mock/state 2>/dev/null ;;
+    plan) exit "$(cat .terraform-mock/exit_code 2>/dev/null || echo 0)" ;;
+    show) cat .terraform-mock/show.json ;;
+esac
+`, executionDir, outputFile)
+	mockScriptPath := filepath.Join(tempDir, "terraform")
+	if err := os.WriteFile(mockScriptPath, []byte(mockScriptContent), 0755); err != nil {
+		t.Fatalf("Failed to write mock terraform script: %v", err)
+	}
+	cleanup := func() {
+		os.RemoveAll(tempDir)
+	}
+	return tempDir, cleanup
+}
+
 // TestStaticAnalysis ensures the ORIGINAL run.sh script maintains a high standard of code quality.
 func TestStaticAnalysis(t *testing.T) {
 	_, err := exec.LookPath("shellcheck")
@@ -316,3 +346,323 @@ func loadTestConfig(t *testing.T) TestConfig {
 	}
 	return config
 }
+
+// TestSecretReferences runs a copy of run.sh against a stage whose tfvars hold
+// a secret reference and checks that the resolved variables reach Terraform
+// through a pipe, not a file.
+func TestSecretReferences(t *testing.T) {
+	root := t.TempDir()
+	executionDir := filepath.Join(root, "execution")
+	for _, d := range []string{filepath.Join(executionDir, "02-networking"), filepath.Join(root, "configuration")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", d, err)
+		}
+	}
+	script, err := os.ReadFile(runScriptPath)
+	if err != nil {
+		t.Fatalf("Failed to read run.sh file: %v", err)
+	}
+	files := map[string]string{
+		"execution/run.sh":                string(script),
+		"configuration/networking.tfvars": "tunnel_1_shared_secret = \"env:VPN_SECRET_1\"\n",
+		// The fake resolver prints the resolved variables, as resolve-secrets does.
+		"resolver": "#!/bin/sh\necho \"$@\" > " + filepath.Join(root, "resolver_args.txt") + "\nprintf 'tunnel_1_shared_secret = \"%s\"\\n' \"$VPN_SECRET_1\"\n",
+		// The fake terraform records its arguments and the content of the var files read from pipes.
+		"bin/terraform": "#!/bin/bash\necho \"$@\" >> " + filepath.Join(root, "terraform_output.txt") + "\nfor a in \"$@\"; do case \"$a\" in -var-file=/dev/fd/*) cat \"${a#-var-file=}\" >> " + filepath.Join(root, "terraform_output.txt") + ";; esac; done\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cmd := exec.Command("bash", "./run.sh", "-s", "networking", "-t", "apply")
+	cmd.Dir = executionDir
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("PATH=%s:%s", filepath.Join(root, "bin"), os.Getenv("PATH")),
+		"SECRETS_RESOLVER="+filepath.Join(root, "resolver"),
+		"VPN_SECRET_1=resolved-secret",
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("run.sh failed: %v\nOutput:\n%s", err, string(output))
+	}
+	content, err := os.ReadFile(filepath.Join(root, "terraform_output.txt"))
+	if err != nil {
+		t.Fatalf("Could not read mock output file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 || !regexp.MustCompile(`^apply -var-file=\.\./\.\./configuration/networking\.tfvars -var-file=/dev/fd/\d+$`).MatchString(lines[0]) {
+		t.Fatalf("Incorrect terraform command generated.\nGot:\n%s", content)
+	}
+	if lines[1] != `tunnel_1_shared_secret = "resolved-secret"` {
+		t.Errorf("Resolved variables = %q, want the secret read from the environment", lines[1])
+	}
+	args, err := os.ReadFile(filepath.Join(root, "resolver_args.txt"))
+	if err != nil {
+		t.Fatalf("Could not read resolver arguments: %v", err)
+	}
+	if !strings.Contains(string(args), "-tfvars "+filepath.Join(root, "configuration", "networking.tfvars")) {
+		t.Errorf("Resolver arguments = %q, want the absolute tfvars path", args)
+	}
+	matches, _ := filepath.Glob("/dev/shm/cncs-secrets.*")
+	for _, m := range matches {
+		if strings.Contains(string(args), m) {
+			t.Errorf("In-memory secrets folder %s was not removed", m)
+		}
+	}
+}
+
+// TestPolicyCheck runs a copy of run.sh with POLICY_CHECK=true and checks that
+// the saved plan of the stage is checked against the policy rules, and only
+// applied when the checker passes.
+func TestPolicyCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		stdin     string
+		checkExit int
+		wantErr   bool
+		wantApply bool
+	}{
+		{name: "Passing Plan Is Applied", command: "apply-auto-approve", wantApply: true},
+		{name: "Passing Plan Is Applied After Confirmation", command: "apply", stdin: "y\n", wantApply: true},
+		{name: "Passing Plan Is Not Applied Without Confirmation", command: "apply", stdin: "n\n", wantErr: true},
+		{name: "Violating Plan Is Not Applied", command: "apply-auto-approve", checkExit: 1, wantErr: true},
+	}
+	script, err := os.ReadFile(runScriptPath)
+	if err != nil {
+		t.Fatalf("Failed to read run.sh file: %v", err)
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			executionDir := filepath.Join(root, "execution")
+			if err := os.MkdirAll(filepath.Join(executionDir, "02-networking"), 0755); err != nil {
+				t.Fatalf("Failed to create the stage directory: %v", err)
+			}
+			terraformOutput := filepath.Join(root, "terraform_output.txt")
+			checkerOutput := filepath.Join(root, "checker_output.txt")
+			files := map[string]string{
+				"execution/run.sh": string(script),
+				// The fake checker records its arguments and the plan read from its standard input.
+				"checker": fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %s\ncat >> %s\nexit %d\n", checkerOutput, checkerOutput, tc.checkExit),
+				// The fake terraform records its arguments and prints a plan for terraform show.
+				"bin/terraform": fmt.Sprintf("#!/bin/sh\necho \"$@\" >> %s\nif [ \"$1\" = show ]; then echo '{\"format_version\":\"1.2\"}'; fi\n", terraformOutput),
+			}
+			for name, content := range files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0755); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+
+			cmd := exec.Command("bash", "./run.sh", "-s", "networking", "-t", tc.command)
+			cmd.Dir = executionDir
+			cmd.Stdin = strings.NewReader(tc.stdin)
+			cmd.Env = append(os.Environ(),
+				fmt.Sprintf("PATH=%s:%s", filepath.Join(root, "bin"), os.Getenv("PATH")),
+				"POLICY_CHECK=true",
+				"POLICY_CHECKER="+filepath.Join(root, "checker"),
+				"POLICY_RULES=/rules",
+				"POLICY_PARAMS=environment=prod cost_label=team",
+			)
+			output, err := cmd.CombinedOutput()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("run.sh error = %v, want error %v\nOutput:\n%s", err, tc.wantErr, string(output))
+			}
+
+			content, err := os.ReadFile(terraformOutput)
+			if err != nil {
+				t.Fatalf("Could not read mock output file: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			planCommand := regexp.MustCompile(`^plan -input=false -out=(\S+/tfplan) -var-file=\.\./\.\./configuration/networking\.tfvars$`).FindStringSubmatch(lines[0])
+			if planCommand == nil || len(lines) < 2 || lines[1] != "show -json "+planCommand[1] {
+				t.Fatalf("Incorrect terraform commands generated.\nGot:\n%s", content)
+			}
+			wantLines := 2
+			if tc.wantApply {
+				wantLines = 3
+				if len(lines) < 3 || lines[2] != "apply -input=false "+planCommand[1] {
+					t.Errorf("Incorrect apply command generated.\nGot:\n%s", content)
+				}
+			}
+			if len(lines) != wantLines {
+				t.Errorf("Terraform ran %d command(s), want %d.\nGot:\n%s", len(lines), wantLines, content)
+			}
+			if _, err := os.Stat(filepath.Dir(planCommand[1])); !os.IsNotExist(err) {
+				t.Errorf("Plan folder %s was not removed", filepath.Dir(planCommand[1]))
+			}
+
+			checked, err := os.ReadFile(checkerOutput)
+			if err != nil {
+				t.Fatalf("Could not read checker output: %v", err)
+			}
+			want := "-rules /rules -param stage=02-networking -param environment=prod -param cost_label=team -\n{\"format_version\":\"1.2\"}\n"
+			if string(checked) != want {
+				t.Errorf("Checker input = %q, want %q", checked, want)
+			}
+		})
+	}
+}
+
+// TestDrift runs a copy of run.sh with the drift command against stages scripted with
+// setupScriptedTerraformMock, and checks that only the stages with state are refreshed, that
+// their drift is written to the report and that run.sh exits with 2 on drift and 1 on errors.
+func TestDrift(t *testing.T) {
+	config := loadTestConfig(t)
+	script, err := os.ReadFile(runScriptPath)
+	if err != nil {
+		t.Fatalf("Failed to read run.sh file: %v", err)
+	}
+	tests := []struct {
+		name  string
+		stage string
+		// mocks are the '.terraform-mock' files of the stages, by stage directory.
+		mocks        map[string]map[string]string
+		wantExitCode int
+		wantPlans    []string
+		wantReport   string
+		wantOutput   string
+	}{
+		{
+			name:  "All Stages With State",
+			stage: "all",
+			mocks: map[string]map[string]string{
+				"02-networking":        {"state": "google_compute_network.vpc\n", "exit_code": "2", "show.json": `{"stage":"networking"}`},
+				"04-producer/CloudSQL": {"state": "google_sql_database_instance.primary\n", "exit_code": "0", "show.json": `{"stage":"cloudsql"}`},
+			},
+			wantPlans: []string{
+				"02-networking: plan -refresh-only -detailed-exitcode -input=false -out=PLAN -var-file=../../configuration/networking.tfvars",
+				"02-networking: show -json PLAN",
+				"04-producer/CloudSQL: plan -refresh-only -detailed-exitcode -input=false -out=PLAN -var-file=../../../configuration/producer/CloudSQL/cloudsql.tfvars",
+				"04-producer/CloudSQL: show -json PLAN",
+			},
+			wantExitCode: 2,
+			wantReport:   "-stage 02-networking -\n{\"stage\":\"networking\"}\n-stage 04-producer/CloudSQL -\n{\"stage\":\"cloudsql\"}\n",
+			wantOutput:   "Skipping 06-consumer/GCE: no state or not initialized.",
+		},
+		{
+			name:  "No Drift",
+			stage: "networking",
+			mocks: map[string]map[string]string{
+				"02-networking": {"state": "google_compute_network.vpc\n", "exit_code": "0", "show.json": `{"stage":"networking"}`},
+			},
+			wantPlans: []string{
+				"02-networking: plan -refresh-only -detailed-exitcode -input=false -out=PLAN -var-file=../../configuration/networking.tfvars",
+				"02-networking: show -json PLAN",
+			},
+			wantReport: "-stage 02-networking -\n{\"stage\":\"networking\"}\n",
+			wantOutput: "Drift report written to",
+		},
+		{
+			name:  "Failed Refresh",
+			stage: "networking",
+			mocks: map[string]map[string]string{
+				"02-networking": {"state": "google_compute_network.vpc\n", "exit_code": "1"},
+			},
+			wantExitCode: 1,
+			wantPlans: []string{
+				"02-networking: plan -refresh-only -detailed-exitcode -input=false -out=PLAN -var-file=../../configuration/networking.tfvars",
+			},
+		},
+	}
+	planFile := regexp.MustCompile(`-out=\S+/tfplan|-json \S+/tfplan`)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			executionDir := filepath.Join(root, "execution")
+			for _, stage := range config.Stages {
+				if err := os.MkdirAll(filepath.Join(executionDir, stage.DirPath), 0755); err != nil {
+					t.Fatalf("Failed to create the stage directory: %v", err)
+				}
+			}
+			files := map[string]string{
+				"execution/run.sh": string(script),
+				// The fake reporter prints its arguments and the plan read from its standard input.
+				"reporter": "#!/bin/sh\necho \"$@\"\ncat\necho\n",
+				// A stale report of a previous run is replaced.
+				"report.txt": "stale\n",
+			}
+			for dir, mock := range tc.mocks {
+				for name, content := range mock {
+					files[filepath.Join("execution", dir, ".terraform-mock", name)] = content
+				}
+			}
+			for name, content := range files {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0755); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+			mockOutputFile := filepath.Join(root, "mock_output.txt")
+			mockDir, mockCleanup := setupScriptedTerraformMock(t, mockOutputFile, executionDir)
+			defer mockCleanup()
+
+			cmd := exec.Command("bash", "./run.sh", "-s", tc.stage, "-t", "drift")
+			cmd.Dir = executionDir
+			cmd.Env = append(os.Environ(),
+				fmt.Sprintf("PATH=%s:%s", mockDir, os.Getenv("PATH")),
+				"DRIFT_REPORTER="+filepath.Join(root, "reporter"),
+				"DRIFT_REPORT="+filepath.Join(root, "report.txt"),
+			)
+			output, err := cmd.CombinedOutput()
+			exitCode := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				exitCode = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatalf("Failed to run run.sh: %v", err)
+			}
+			if exitCode != tc.wantExitCode {
+				t.Fatalf("run.sh exit code = %d, want %d\nOutput:\n%s", exitCode, tc.wantExitCode, string(output))
+			}
+			if !strings.Contains(string(output), tc.wantOutput) {
+				t.Errorf("Expected output to contain %q.\nGot:\n%s", tc.wantOutput, output)
+			}
+
+			content, err := os.ReadFile(mockOutputFile)
+			if err != nil {
+				t.Fatalf("Could not read mock output file: %v", err)
+			}
+			var plans []string
+			for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+				if strings.HasSuffix(line, ": state list") {
+					continue
+				}
+				plans = append(plans, planFile.ReplaceAllStringFunc(line, func(m string) string {
+					if strings.HasPrefix(m, "-out=") {
+						return "-out=PLAN"
+					}
+					return "-json PLAN"
+				}))
+			}
+			if !reflect.DeepEqual(plans, tc.wantPlans) {
+				t.Errorf("Incorrect terraform commands generated.\nExpected:\n%s\nGot:\n%s", strings.Join(tc.wantPlans, "\n"), content)
+			}
+			for _, m := range planFile.FindAllString(string(content), -1) {
+				dir := filepath.Dir(m[strings.IndexAny(m, "= ")+1:])
+				if _, err := os.Stat(dir); !os.IsNotExist(err) {
+					t.Errorf("Plan folder %s was not removed", dir)
+				}
+			}
+
+			report, err := os.ReadFile(filepath.Join(root, "report.txt"))
+			if err != nil {
+				t.Fatalf("Could not read the drift report: %v", err)
+			}
+			if string(report) != tc.wantReport {
+				t.Errorf("Drift report = %q, want %q", report, tc.wantReport)
+			}
+		})
+	}
+}
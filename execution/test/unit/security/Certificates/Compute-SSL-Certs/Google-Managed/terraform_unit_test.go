@@ -17,6 +17,7 @@ package unittest
 import (
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/google/go-cmp/cmp"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"golang.org/x/exp/slices"
@@ -45,9 +46,11 @@ var (
 // Expects a successful plan with changes (exit code 2).
 func TestSSLCertInitAndPlanRunWithTfVars(t *testing.T) {
 
+	ws := workspace.New(t, terraformSSLCertificateDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformSSLCertificateDirectoryPath,
-		Vars:         sslTfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(sslTfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan", // Use a distinct plan file path
@@ -66,8 +69,10 @@ func TestSSLCertInitAndPlanRunWithTfVars(t *testing.T) {
 // without required TF variables. Expects a failure (exit code 1).
 func TestSSLCertInitAndPlanRunWithoutTfVarsExpectFailure(t *testing.T) {
 
+	ws := workspace.New(t, terraformSSLCertificateDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformSSLCertificateDirectoryPath,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
 		// Vars:         // Intentionally omit tfVars
 		Reconfigure:  true,
 		Lock:         true,
@@ -87,9 +92,11 @@ func TestSSLCertInitAndPlanRunWithoutTfVarsExpectFailure(t *testing.T) {
 // Assumes the module creates one primary SSL certificate resource.
 func TestSSLCertResourcesCount(t *testing.T) {
 
+	ws := workspace.New(t, terraformSSLCertificateDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformSSLCertificateDirectoryPath,
-		Vars:         sslTfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(sslTfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan", // Use a distinct plan file path
@@ -116,9 +123,11 @@ func TestSSLCertTerraformModuleResourceAddressListMatch(t *testing.T) {
 
 	expectedModulesAddress := []string{"module.ssl_certificate"}
 
+	ws := workspace.New(t, terraformSSLCertificateDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformSSLCertificateDirectoryPath,
-		Vars:         sslTfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(sslTfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan",
@@ -149,9 +158,11 @@ func TestSSLCertTerraformModuleResourceAddressListMatch(t *testing.T) {
 // TestSSLCertificateAttributes tests specific attributes of the planned SSL certificate.
 func TestSSLCertificateAttributes(t *testing.T) {
 
+	ws := workspace.New(t, terraformSSLCertificateDirectoryPath)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformSSLCertificateDirectoryPath,
-		Vars:         sslTfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(sslTfVars),
 		Reconfigure:  true,
 		Lock:         true,
 		PlanFilePath: "./plan", // This plan file will be created by InitAndPlanAndShow
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/common_utils/workspace"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/assert"
 )
@@ -41,9 +42,11 @@ var (
 
 // TestSecurityProfilePlanExitCode verifies that the plan exits with a code of 2, indicating changes are planned.
 func TestSecurityProfilePlanExitCode(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathSP)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathSP,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		PlanFilePath: "./plan",
 		NoColor:      true,
@@ -58,9 +61,11 @@ func TestSecurityProfileResourcesCount(t *testing.T) {
 	expectedAddCount := 3
 	expectedChangeCount := 0
 	expectedDestroyCount := 0
+	ws := workspace.New(t, terraformDirectoryPathSP)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathSP,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		PlanFilePath: "./plan",
 		NoColor:      true,
@@ -76,9 +81,11 @@ func TestSecurityProfileResourcesCount(t *testing.T) {
 
 // TestSecurityProfileModuleAddressListMatch verifies that a module instance is planned for each YAML config file.
 func TestSecurityProfileModuleAddressListMatch(t *testing.T) {
+	ws := workspace.New(t, terraformDirectoryPathSP)
 	terraformOptions := terraform.WithDefaultRetryableErrors(t, &terraform.Options{
-		TerraformDir: terraformDirectoryPathSP,
-		Vars:         tfVars,
+		TerraformDir: ws.Dir,
+		EnvVars:      ws.EnvVars(),
+		Vars:         ws.Vars(tfVars),
 		Reconfigure:  true,
 		PlanFilePath: "./plan",
 		NoColor:      true,
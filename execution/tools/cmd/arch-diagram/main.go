@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command arch-diagram draws the architecture a configuration tree builds,
+// as a Mermaid flowchart, a Graphviz DOT graph or JSON.
+//
+// Usage:
+//
+//	arch-diagram [-format mermaid|dot|json] [-plan FILE]... [-state FILE]... [-out FILE] [CONFIG_DIR]
+//
+// -plan and -state take the output of terraform show -json for a saved plan
+// or the state of a stage, and may be repeated for several stages: the
+// nodes a plan changes are colored by change, and deployed nodes get their
+// IP address. CONFIG_DIR defaults to ../../configuration.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/diagram"
+)
+
+func main() {
+	format := flag.String("format", diagram.Mermaid, "output format, mermaid, dot or json")
+	out := flag.String("out", "", "file to write the diagram to, instead of stdout")
+	var files []string
+	add := func(f string) error {
+		files = append(files, f)
+		return nil
+	}
+	flag.Func("plan", "terraform show -json output of a saved plan (repeatable)", add)
+	flag.Func("state", "terraform show -json output of a state (repeatable)", add)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [CONFIG_DIR]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	root := "../../configuration"
+	if flag.NArg() == 1 {
+		root = flag.Arg(0)
+	}
+
+	tree, err := config.LoadTree(root)
+	if err != nil {
+		fail(err)
+	}
+	g := diagram.Build(tree)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			fail(err)
+		}
+		n, err := diagram.Enrich(g, data)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", f, err))
+		}
+		if n == 0 {
+			fmt.Fprintf(os.Stderr, "arch-diagram: %s: no resource matches the configuration\n", f)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := diagram.Write(&buf, g, *format); err != nil {
+		fail(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := os.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "arch-diagram:", err)
+	os.Exit(2)
+}
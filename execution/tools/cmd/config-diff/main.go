@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command config-diff prints a semantic diff between two configuration trees.
+//
+// Usage:
+//
+//	config-diff [-format text|json] [-stage name]... OLD_CONFIG_DIR NEW_CONFIG_DIR
+//
+// Like diff(1) it exits with 0 when the trees are equal, 1 when they differ
+// and 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/diff"
+)
+
+type stageList []string
+
+func (s *stageList) String() string { return strings.Join(*s, ",") }
+
+func (s *stageList) Set(v string) error {
+	stage, ok := config.LookupStage(v)
+	if !ok {
+		return fmt.Errorf("unknown stage %q", v)
+	}
+	*s = append(*s, stage.Name)
+	return nil
+}
+
+func main() {
+	format := flag.String("format", "text", "output format, text or json")
+	var stages stageList
+	flag.Var(&stages, "stage", "only compare this stage (repeatable), e.g. producer/cloudsql")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] OLD_CONFIG_DIR NEW_CONFIG_DIR\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := run(flag.Arg(0), flag.Arg(1), stages)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config-diff:", err)
+		os.Exit(2)
+	}
+	if *format == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config-diff:", err)
+		os.Exit(2)
+	}
+	if !report.Empty() {
+		os.Exit(1)
+	}
+}
+
+func run(oldDir, newDir string, stages []string) (*diff.Report, error) {
+	oldTree, err := config.LoadTree(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newTree, err := config.LoadTree(newDir)
+	if err != nil {
+		return nil, err
+	}
+	return diff.Trees(oldTree, newTree, stages...)
+}
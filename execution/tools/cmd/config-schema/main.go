@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command config-schema writes the JSON Schema documents of the stage YAML
+// files, or checks that the committed ones are up to date.
+//
+// Usage:
+//
+//	config-schema [-execution DIR] [-out DIR] [-check]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/schema"
+)
+
+func main() {
+	execution := flag.String("execution", "..", "execution/ directory holding the stages")
+	out := flag.String("out", "../../configuration/schemas", "directory of the schema files")
+	check := flag.Bool("check", false, "report out of date schema files instead of writing them")
+	flag.Parse()
+
+	if *check {
+		stale, err := schema.Check(*execution, *out)
+		if err != nil {
+			fail(err)
+		}
+		for _, f := range stale {
+			fmt.Fprintf(os.Stderr, "%s is out of date\n", f)
+		}
+		if len(stale) > 0 {
+			fmt.Fprintln(os.Stderr, "run `go run ./cmd/config-schema` from execution/tools to regenerate the schemas")
+			os.Exit(1)
+		}
+		return
+	}
+	if err := schema.WriteAll(*execution, *out); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "config-schema:", err)
+	os.Exit(2)
+}
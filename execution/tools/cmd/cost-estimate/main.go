@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cost-estimate estimates the monthly cost of the resources of the
+// Terraform plans of one or more stages from a local price table, per stage
+// and in total. With -base, it estimates the change of the monthly cost from
+// the base plans to the plans.
+//
+// Usage:
+//
+//	terraform show -json PLANFILE > plan.json
+//	cost-estimate [-prices FILE] [-base [STAGE=]PLAN,...] [-format text|json] [STAGE=]plan.json...
+//
+// The stage of a plan defaults to the name of its file without extension. It
+// exits with 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/cost"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+func main() {
+	pricesFile := flag.String("prices", "cost/prices.yaml", "price table file")
+	base := flag.String("base", "", "comma separated [STAGE=]PLAN base plans, to estimate the change of the cost from them")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Parse()
+	if flag.NArg() == 0 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	prices, err := cost.LoadPrices(*pricesFile)
+	if err != nil {
+		fail(err)
+	}
+	est, err := estimate(prices, flag.Args())
+	if err != nil {
+		fail(err)
+	}
+	if *base == "" {
+		if err := cost.Write(os.Stdout, est, *format); err != nil {
+			fail(err)
+		}
+		return
+	}
+	before, err := estimate(prices, strings.Split(*base, ","))
+	if err != nil {
+		fail(err)
+	}
+	if err := cost.Write(os.Stdout, cost.Compare(before, est), *format); err != nil {
+		fail(err)
+	}
+}
+
+// estimate estimates the [STAGE=]PLAN plans.
+func estimate(prices *cost.Prices, args []string) (*cost.Estimate, error) {
+	est := cost.New(prices)
+	for _, arg := range args {
+		stage, file, ok := strings.Cut(arg, "=")
+		if !ok {
+			file = arg
+			stage = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		plan, err := tfplan.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		est.Add(stage, plan, prices)
+	}
+	return est, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "cost-estimate:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command drift-report lists the resources of a stage changed outside of
+// Terraform, and their changed attributes, from the refresh-only plan of the
+// stage.
+//
+// Usage:
+//
+//	terraform plan -refresh-only -out=PLANFILE -var-file=...
+//	terraform show -json PLANFILE > plan.json
+//	drift-report [-stage NAME] [-format text|json] plan.json
+//
+// The plan is read from the standard input when it is "-". It exits with 2
+// on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/drift"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+func main() {
+	stage := flag.String("stage", "", "stage name, the name of the plan file without extension by default")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Parse()
+	if flag.NArg() != 1 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file := flag.Arg(0)
+	name := *stage
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	plan, err := tfplan.ReadFile(file)
+	if err != nil {
+		fail(err)
+	}
+	if err := drift.Find(name, plan).Write(os.Stdout, *format); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "drift-report:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command firewall-lint analyzes the VPC firewall rules of the 03-security
+// stages and the firewall policies of a configuration tree.
+//
+// Usage:
+//
+//	firewall-lint [-format text|json] [-fail-on SEVERITY] [CONFIG_DIR]
+//
+// It exits with 1 when a finding is at least as severe as -fail-on, and with
+// 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/firewall"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format, text or json")
+	failOn := flag.String("fail-on", "error", "exit with 1 on findings of this severity or above: info, warning or error")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [CONFIG_DIR]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	threshold, err := report.ParseSeverity(*failOn)
+	if err != nil || flag.NArg() > 1 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	root := "../../configuration"
+	if flag.NArg() == 1 {
+		root = flag.Arg(0)
+	}
+
+	tree, err := config.LoadTree(root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "firewall-lint:", err)
+		os.Exit(2)
+	}
+	model, findings := firewall.Load(tree)
+	r := &report.Report{}
+	r.Add(findings...)
+	r.Add(firewall.Analyze(model)...)
+	r.Sort()
+	if err := r.Write(os.Stdout, *format); err != nil {
+		fmt.Fprintln(os.Stderr, "firewall-lint:", err)
+		os.Exit(2)
+	}
+	if r.Fails(threshold) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command import-config converts `gcloud ... describe --format=json` output
+// into stage configuration files.
+//
+// Usage:
+//
+//	import-config [-out CONFIG_DIR] [-force] [-strict] [-firewall-stage STAGE] [FILE...]
+//
+// Describe documents are read from the given files, or from stdin when no
+// file (or "-") is given. Without -out the generated files are printed.
+// Fields that could not be mapped are reported on stderr.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/importer"
+)
+
+func main() {
+	out := flag.String("out", "", "configuration root to write the generated files to, e.g. ../../configuration")
+	force := flag.Bool("force", false, "overwrite existing files under -out")
+	strict := flag.Bool("strict", false, "exit with status 1 when some fields could not be mapped")
+	firewallStage := flag.String("firewall-stage", "security/gce", "03-security stage firewall rules are imported into")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [FILE...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	docs, err := readDocuments(flag.Args())
+	if err != nil {
+		fail(err)
+	}
+	results, err := importer.Import(docs, importer.Options{FirewallStage: *firewallStage})
+	if err != nil {
+		fail(err)
+	}
+
+	written := map[string]bool{}
+	incomplete := false
+	for _, r := range results {
+		content, err := r.Encode()
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", r.File, err))
+		}
+		if *out == "" {
+			fmt.Printf("# %s\n%s\n", r.File, content)
+		} else if err := write(*out, r.File, content, *force, written); err != nil {
+			fail(err)
+		}
+		if len(r.Unmapped) > 0 {
+			incomplete = true
+			fmt.Fprintf(os.Stderr, "%s: %s %q: unmapped fields: %s\n", r.File, r.Type, r.Name, strings.Join(r.Unmapped, ", "))
+		}
+		if len(r.Missing) > 0 {
+			fmt.Fprintf(os.Stderr, "%s: %s %q: set by hand: %s\n", r.File, r.Type, r.Name, strings.Join(r.Missing, ", "))
+		}
+	}
+	if *strict && incomplete {
+		os.Exit(1)
+	}
+}
+
+func readDocuments(files []string) ([]map[string]any, error) {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	var docs []map[string]any
+	for _, f := range files {
+		var r io.Reader = os.Stdin
+		if f != "-" {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			r = bytes.NewReader(b)
+		}
+		d, err := importer.Decode(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		docs = append(docs, d...)
+	}
+	return docs, nil
+}
+
+func write(root, file string, content []byte, force bool, written map[string]bool) error {
+	if written[file] {
+		return fmt.Errorf("%s: generated more than once, import the resources separately", file)
+	}
+	written[file] = true
+	target := filepath.Join(root, filepath.FromSlash(file))
+	if _, err := os.Stat(target); err == nil && !force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", target)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "wrote", target)
+	return os.WriteFile(target, content, 0644)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "import-config:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ip-plan checks the IP ranges declared across the stages of one or
+// more configuration trees for overlaps, ranges outside the allowed blocks
+// and exhausted pools, and proposes free ranges.
+//
+// Usage:
+//
+//	ip-plan [-format text|json] [-allowed CIDR]... [-pool CIDR]... [-threshold RATIO] [-map] [CONFIG_DIR...]
+//	ip-plan -next PREFIX_LENGTH -network NAME [-pool CIDR]... [CONFIG_DIR...]
+//
+// It exits with 1 when a finding is at least as severe as -fail-on, and with
+// 2 on error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ipplan"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+type prefixList []netip.Prefix
+
+func (l *prefixList) String() string {
+	s := make([]string, len(*l))
+	for i, p := range *l {
+		s[i] = p.String()
+	}
+	return strings.Join(s, ",")
+}
+
+func (l *prefixList) Set(v string) error {
+	for _, s := range strings.Split(v, ",") {
+		p, err := netip.ParsePrefix(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		*l = append(*l, p.Masked())
+	}
+	return nil
+}
+
+// maxFree is the number of free blocks printed per network in text mode.
+const maxFree = 8
+
+func main() {
+	var opts ipplan.Options
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Var((*prefixList)(&opts.Allowed), "allowed", "block ranges must be taken from (repeatable), RFC1918 by default")
+	flag.Var((*prefixList)(&opts.Pools), "pool", "block ranges are allocated from (repeatable), the allowed blocks by default")
+	flag.Float64Var(&opts.Threshold, "threshold", ipplan.DefaultThreshold, "used share of a pool reported as exhaustion")
+	showMap := flag.Bool("map", false, "print the address map of every network")
+	failOn := flag.String("fail-on", "error", "exit with 1 on findings of this severity or above: info, warning or error")
+	next := flag.Int("next", 0, "print the next free range of this prefix length in -network")
+	network := flag.String("network", "", "network searched by -next")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [CONFIG_DIR...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	threshold, err := report.ParseSeverity(*failOn)
+	if err != nil || (*format != "text" && *format != "json") || (*next != 0 && *network == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"../../configuration"}
+	}
+
+	plan, err := collect(roots)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ip-plan:", err)
+		os.Exit(2)
+	}
+	if *next != 0 {
+		p, err := plan.NextFree(ipplan.NetworkName(*network), *next, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ip-plan:", err)
+			os.Exit(2)
+		}
+		fmt.Println(p)
+		return
+	}
+
+	r := &report.Report{}
+	r.Add(plan.Check(opts)...)
+	var maps []ipplan.NetworkMap
+	if *showMap {
+		maps = plan.Map(opts)
+	}
+	if *format == "json" {
+		err = writeJSON(os.Stdout, maps, r)
+	} else {
+		err = writeMaps(os.Stdout, maps)
+		if err == nil {
+			err = r.WriteText(os.Stdout)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ip-plan:", err)
+		os.Exit(2)
+	}
+	if r.Fails(threshold) {
+		os.Exit(1)
+	}
+}
+
+func collect(roots []string) (*ipplan.Plan, error) {
+	var trees []*config.Tree
+	for _, root := range roots {
+		tree, err := config.LoadTree(root)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, tree)
+	}
+	return ipplan.Collect(trees...), nil
+}
+
+func writeJSON(w io.Writer, maps []ipplan.NetworkMap, r *report.Report) error {
+	out := struct {
+		Networks []ipplan.NetworkMap `json:"networks,omitempty"`
+		Findings []report.Finding    `json:"findings"`
+	}{maps, r.Findings}
+	if out.Findings == nil {
+		out.Findings = []report.Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeMaps(w io.Writer, maps []ipplan.NetworkMap) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, m := range maps {
+		fmt.Fprintf(tw, "network %q\n", m.Network)
+		for _, a := range m.Allocations {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", a.Prefix, a.Kind, a.Name, a.Region, a.File)
+		}
+		for _, u := range m.Usage {
+			fmt.Fprintf(tw, "  pool %s: %.1f%% used (%d of %d addresses)\n", u.Pool, 100*u.Ratio(), u.Used, u.Size)
+		}
+		free := make([]string, 0, maxFree)
+		for i, p := range m.Free {
+			if i == maxFree {
+				free = append(free, fmt.Sprintf("... (%d more)", len(m.Free)-maxFree))
+				break
+			}
+			free = append(free, p.String())
+		}
+		fmt.Fprintf(tw, "  free: %s\n\n", strings.Join(free, ", "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command ncc-lint validates the hubs and spokes of the 02-networking/NCC
+// stage of one or more configuration trees, against the networks, HA VPN
+// tunnels and VLAN attachments their 02-networking stages create.
+//
+// Usage:
+//
+//	ncc-lint [-format text|json] [-fail-on SEVERITY] [CONFIG_DIR...]
+//
+// Pass one CONFIG_DIR per VPC when the spokes of a hub link the networks of
+// several configuration trees. It exits with 1 when a finding is at least as
+// severe as -fail-on, and with 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ncc"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format, text or json")
+	failOn := flag.String("fail-on", "error", "exit with 1 on findings of this severity or above: info, warning or error")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [CONFIG_DIR...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	threshold, err := report.ParseSeverity(*failOn)
+	if err != nil || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"../../configuration"}
+	}
+
+	var trees []*config.Tree
+	for _, root := range roots {
+		tree, err := config.LoadTree(root)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "ncc-lint:", err)
+			os.Exit(2)
+		}
+		trees = append(trees, tree)
+	}
+	r := &report.Report{}
+	r.Add(ncc.Validate(trees...)...)
+	r.Sort()
+	if err := r.Write(os.Stdout, *format); err != nil {
+		fmt.Fprintln(os.Stderr, "ncc-lint:", err)
+		os.Exit(2)
+	}
+	if r.Fails(threshold) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command negative-tests derives invalid variants of the unit test fixtures
+// of the stages with a typed YAML schema, plans each stage with every
+// variant and reports the variants the stage accepts.
+//
+// Usage:
+//
+//	negative-tests [-execution DIR] [-unit DIR] [-stage NAME,...] [-var NAME=VALUE]... [-list] [-format text|json] [-fail-on SEVERITY]
+//
+// It exits with 1 when a finding is at least as severe as -fail-on, and with
+// 2 on error.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/negative"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+func main() {
+	execution := flag.String("execution", "..", "execution/ directory holding the stages")
+	unit := flag.String("unit", "../test/unit", "directory of the unit tests holding the stage fixtures")
+	stages := flag.String("stage", "", "comma separated stages to test, by name or path, every stage with fixtures by default")
+	vars := map[string]string{}
+	flag.Func("var", "variable NAME=VALUE passed to every plan, e.g. project_id=my-project, may be repeated", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("expected NAME=VALUE, got %q", s)
+		}
+		vars[name] = value
+		return nil
+	})
+	list := flag.Bool("list", false, "print the variants instead of planning them")
+	format := flag.String("format", "text", "output format, text or json")
+	failOn := flag.String("fail-on", "error", "exit with 1 on findings of this severity or above: info, warning or error")
+	flag.Parse()
+	threshold, err := report.ParseSeverity(*failOn)
+	if err != nil || flag.NArg() > 0 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	selected, err := selectStages(*stages)
+	if err != nil {
+		fail(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	r := &report.Report{}
+	var variants []negative.Variant
+	for _, stage := range selected {
+		dir, ok := negative.FixtureDir(*unit, stage)
+		if !ok {
+			if *stages != "" {
+				fail(fmt.Errorf("stage %s has no unit test fixtures under %s", stage.Name, *unit))
+			}
+			continue
+		}
+		vs, err := negative.Generate(stage, dir)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", stage.Name, err))
+		}
+		if *list {
+			variants = append(variants, vs...)
+			continue
+		}
+		stageDir := filepath.Join(*execution, filepath.FromSlash(stage.Path))
+		fmt.Fprintf(os.Stderr, "%s: planning %d variants\n", stage.Name, len(vs))
+		results, err := negative.Run(ctx, negative.Options{StageDir: stageDir, FixtureDir: dir, Vars: vars}, vs)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", stage.Name, err))
+		}
+		vars, err := negative.Variables(stageDir)
+		if err != nil {
+			fail(fmt.Errorf("%s: %w", stage.Name, err))
+		}
+		rel, err := filepath.Rel(*unit, dir)
+		if err != nil {
+			rel = dir
+		}
+		r.Add(negative.Findings(stage, filepath.ToSlash(rel), results, vars)...)
+	}
+
+	if *list {
+		if err := writeVariants(variants, *format); err != nil {
+			fail(err)
+		}
+		return
+	}
+	r.Sort()
+	if err := r.Write(os.Stdout, *format); err != nil {
+		fail(err)
+	}
+	if r.Fails(threshold) {
+		os.Exit(1)
+	}
+}
+
+// selectStages returns the stages named in a comma separated list, or every
+// stage with a typed YAML schema.
+func selectStages(list string) ([]config.Stage, error) {
+	stages, err := config.SelectStages(list)
+	if err != nil {
+		return nil, err
+	}
+	var out []config.Stage
+	for _, s := range stages {
+		switch {
+		case s.Typed():
+			out = append(out, s)
+		case list != "":
+			return nil, fmt.Errorf("stage %s has no typed YAML schema", s.Name)
+		}
+	}
+	return out, nil
+}
+
+func writeVariants(variants []negative.Variant, format string) error {
+	if format == "json" {
+		if variants == nil {
+			variants = []negative.Variant{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(variants)
+	}
+	for _, v := range variants {
+		fmt.Printf("%-16s %s: %s: %s\n", v.Kind, v.File, v.Path, v.Describe())
+	}
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "negative-tests:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command policy-check checks the resource changes of a Terraform plan
+// against the policy rules, and reports the violations with the address of
+// the resource.
+//
+// Usage:
+//
+//	terraform show -json PLANFILE > plan.json
+//	policy-check [-rules PATH,...] [-rule ID,...] [-param NAME=VALUE]... [-format text|json] [-fail-on SEVERITY] plan.json
+//
+// The plan is read from the standard input when it is "-". It exits with 1
+// when a violation is at least as severe as -fail-on, and with 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/policy"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+func main() {
+	rules := flag.String("rules", "policy/rules", "comma separated rule files or directories of rule files")
+	only := flag.String("rule", "", "comma separated ids of the rules to check, every rule by default")
+	params := map[string]string{}
+	flag.Func("param", "policy parameter NAME=VALUE, e.g. environment=prod, may be repeated", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("expected NAME=VALUE, got %q", s)
+		}
+		params[name] = value
+		return nil
+	})
+	format := flag.String("format", "text", "output format, text or json")
+	failOn := flag.String("fail-on", "error", "exit with 1 on violations of this severity or above: info, warning or error")
+	flag.Parse()
+	threshold, err := report.ParseSeverity(*failOn)
+	if err != nil || flag.NArg() != 1 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	p, err := policy.Load(strings.Split(*rules, ",")...)
+	if err != nil {
+		fail(err)
+	}
+	if *only != "" {
+		if err := p.Select(strings.Split(*only, ",")); err != nil {
+			fail(err)
+		}
+	}
+	plan, err := tfplan.ReadFile(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+	findings, err := p.Evaluate(plan, params)
+	if err != nil {
+		fail(err)
+	}
+	r := &report.Report{}
+	r.Add(findings...)
+	r.Sort()
+	if err := r.Write(os.Stdout, *format); err != nil {
+		fail(err)
+	}
+	if r.Fails(threshold) {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "policy-check:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command provider-mirror builds a local mirror of the providers and remote
+// modules terraform init downloads for the stages, and checks that it covers
+// every stage, so that the unit tests run without network.
+//
+// Usage:
+//
+//	provider-mirror -dir DIR [-build] [-execution DIR] [-stage NAME,...] [-platform OS_ARCH,...] [-format text|json] [-fail-on SEVERITY]
+//
+// -build runs terraform init and terraform providers mirror in every stage,
+// which needs network, before the check. It exits with 1 when a finding is
+// at least as severe as -fail-on, and with 2 on error.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/mirror"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+func main() {
+	dir := flag.String("dir", os.Getenv("TEST_TERRAFORM_MIRROR"), "mirror directory, $TEST_TERRAFORM_MIRROR by default")
+	build := flag.Bool("build", false, "initialize every stage and copy its providers and modules into the mirror before the check")
+	execution := flag.String("execution", "..", "execution/ directory holding the stages")
+	stages := flag.String("stage", "", "comma separated stages, by name or path, every stage by default")
+	platforms := flag.String("platform", mirror.DefaultPlatform(), "comma separated platforms to mirror the providers for")
+	format := flag.String("format", "text", "output format, text or json")
+	failOn := flag.String("fail-on", "error", "exit with 1 on findings of this severity or above: info, warning or error")
+	flag.Parse()
+	threshold, err := report.ParseSeverity(*failOn)
+	if err != nil || *dir == "" || flag.NArg() > 0 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	selected, err := config.SelectStages(*stages)
+	if err != nil {
+		fail(err)
+	}
+	var plats []string
+	for _, p := range strings.Split(*platforms, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			plats = append(plats, p)
+		}
+	}
+	if *build {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		opts := mirror.Options{Dir: *dir, Execution: *execution, Platforms: plats, Log: os.Stderr}
+		if err := mirror.Build(ctx, opts, selected); err != nil {
+			fail(err)
+		}
+	}
+
+	findings, err := mirror.Verify(*dir, *execution, selected, plats)
+	if err != nil {
+		fail(err)
+	}
+	r := &report.Report{}
+	r.Add(findings...)
+	r.Sort()
+	if err := r.Write(os.Stdout, *format); err != nil {
+		fail(err)
+	}
+	if r.Fails(threshold) {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "provider-mirror:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command quota-check counts the resources the Terraform plans of the
+// selected stages create per Compute Engine quota, and compares them against
+// the usage and limit of the quotas, from a snapshot file or fetched live
+// with gcloud.
+//
+// Usage:
+//
+//	terraform show -json PLANFILE > plan.json
+//	quota-check (-snapshot FILE | -live) [-project PROJECT] [-format text|json] [STAGE=]plan.json...
+//
+// The stage of a plan defaults to the name of its file without extension. It
+// exits with 1 when the planned additions exceed a quota, and with 2 on
+// error.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/quota"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+func main() {
+	snapshot := flag.String("snapshot", "", "quota snapshot file")
+	live := flag.Bool("live", false, "fetch the quotas with gcloud instead of reading a snapshot")
+	project := flag.String("project", "", "project of the resources whose plan has no project")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Parse()
+	if flag.NArg() == 0 || (*snapshot == "") == !*live || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var fetcher quota.Fetcher = &quota.Gcloud{}
+	if *snapshot != "" {
+		s, err := quota.LoadSnapshot(*snapshot)
+		if err != nil {
+			fail(err)
+		}
+		fetcher = s
+	}
+	planned := &quota.Planned{Project: *project}
+	for _, arg := range flag.Args() {
+		stage, file, ok := strings.Cut(arg, "=")
+		if !ok {
+			file = arg
+			stage = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		}
+		plan, err := tfplan.ReadFile(file)
+		if err != nil {
+			fail(err)
+		}
+		if err := planned.Add(stage, plan); err != nil {
+			fail(err)
+		}
+	}
+	rows, err := quota.Check(context.Background(), fetcher, planned)
+	if err != nil {
+		fail(err)
+	}
+	if err := quota.Write(os.Stdout, rows, *format); err != nil {
+		fail(err)
+	}
+	if quota.Exceeded(rows) {
+		os.Exit(1)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "quota-check:", err)
+	os.Exit(2)
+}
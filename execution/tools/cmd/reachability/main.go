@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command reachability simulates, from the configuration of one or more
+// trees, whether a source reaches a destination and prints the decision
+// path.
+//
+// Usage:
+//
+//	reachability -from SOURCE -to DESTINATION -port PORT [-protocol PROTOCOL] [-format text|json] [CONFIG_DIR...]
+//	reachability -checks FILE [-format text|json] [CONFIG_DIR...]
+//
+// With -from and -to it exits with 1 when the destination is unreachable.
+// With -checks it exits with 1 when a check does not have its expected
+// outcome. It exits with 2 on error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/reach"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+func main() {
+	var q reach.Query
+	flag.StringVar(&q.Source, "from", "", "source: instance, mig, address or [KIND/]NAME")
+	flag.StringVar(&q.Destination, "to", "", "destination: instance, PSC endpoint, Cloud SQL, AlloyDB, address or [KIND/]NAME")
+	flag.StringVar(&q.Protocol, "protocol", "tcp", "protocol")
+	flag.IntVar(&q.Port, "port", 0, "destination port")
+	checks := flag.String("checks", "", "YAML file of checks with their expected outcome")
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [CONFIG_DIR...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	single := q.Source != "" || q.Destination != ""
+	if single == (*checks != "") || (single && (q.Source == "" || q.Destination == "")) || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	roots := flag.Args()
+	if len(roots) == 0 {
+		roots = []string{"../../configuration"}
+	}
+
+	var trees []*config.Tree
+	for _, root := range roots {
+		tree, err := config.LoadTree(root)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "reachability:", err)
+			os.Exit(2)
+		}
+		trees = append(trees, tree)
+	}
+	model, findings := reach.Build(trees...)
+	// Configuration problems are printed on stderr, they may explain an
+	// unexpected verdict.
+	if len(findings) > 0 {
+		r := &report.Report{}
+		r.Add(findings...)
+		r.Sort()
+		r.WriteText(os.Stderr)
+	}
+
+	expectations := []reach.Expectation{{Query: q, Expect: "reachable"}}
+	if *checks != "" {
+		var err error
+		if expectations, err = reach.ReadExpectations(*checks); err != nil {
+			fmt.Fprintln(os.Stderr, "reachability:", err)
+			os.Exit(2)
+		}
+	}
+	type result struct {
+		reach.Verdict
+		Expect string `json:"expect,omitempty"`
+		Met    bool   `json:"met"`
+	}
+	var results []result
+	failed := 0
+	for _, e := range expectations {
+		res := result{Verdict: model.Evaluate(e.Query)}
+		if *checks != "" {
+			res.Expect, res.Met = e.Expect, e.Met(res.Verdict)
+		} else {
+			res.Met = res.Reachable
+		}
+		if !res.Met {
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintln(os.Stderr, "reachability:", err)
+			os.Exit(2)
+		}
+	} else {
+		for i, res := range results {
+			if i > 0 {
+				fmt.Println()
+			}
+			if res.Expect != "" && !res.Met {
+				fmt.Printf("UNMET: expected %s\n", res.Expect)
+			}
+			fmt.Print(res.Verdict)
+		}
+		if *checks != "" {
+			fmt.Printf("\n%d check(s): %d met, %d unmet.\n", len(results), len(results)-failed, failed)
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
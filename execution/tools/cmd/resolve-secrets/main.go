@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command resolve-secrets resolves the secret references of a stage and
+// prints the resolved variables as tfvars, for run.sh to pass to Terraform
+// through a pipe.
+//
+// Usage:
+//
+//	resolve-secrets -tfvars FILE [-stage-dir DIR] [-yaml-dir DIR] [-provider SCHEME=COMMAND]...
+//
+// Nothing is printed when the stage holds no reference. When the stage YAML
+// files hold references, the resolved files are written to -yaml-dir and
+// config_folder_path is overridden to point at it.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/secrets"
+)
+
+type providerList map[string]*secrets.Command
+
+func (p providerList) String() string { return "" }
+
+func (p providerList) Set(v string) error {
+	scheme, command, ok := strings.Cut(v, "=")
+	args := strings.Fields(command)
+	if !ok || scheme == "" || len(args) == 0 {
+		return fmt.Errorf("expected SCHEME=COMMAND, got %q", v)
+	}
+	p[scheme] = &secrets.Command{Name: args[0], Args: args[1:]}
+	return nil
+}
+
+func main() {
+	tfvars := flag.String("tfvars", "", "stage tfvars file")
+	stageDir := flag.String("stage-dir", ".", "stage directory, config_folder_path is relative to it")
+	yamlDir := flag.String("yaml-dir", "", "private directory, ideally in memory, receiving the resolved YAML files")
+	providers := providerList{}
+	flag.Var(providers, "provider", "command resolving the references of a scheme (repeatable), e.g. vault=\"vault kv get -field=value\"")
+	flag.Parse()
+	if *tfvars == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	r := secrets.NewResolver()
+	for scheme, p := range providers {
+		r.Register(scheme, p)
+	}
+	o, err := secrets.ResolveStage(context.Background(), r, *stageDir, *tfvars)
+	if err != nil {
+		fail(err)
+	}
+	if o.Empty() {
+		return
+	}
+	out, err := o.WriteTfvars(*yamlDir)
+	if err != nil {
+		fail(err)
+	}
+	os.Stdout.Write(out)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "resolve-secrets:", err)
+	os.Exit(2)
+}
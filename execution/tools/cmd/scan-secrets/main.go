@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command scan-secrets reports the secrets written in plain text in
+// configuration files.
+//
+// Usage:
+//
+//	scan-secrets [-format text|json] [PATH...]
+//
+// PATH defaults to ../../configuration. The .example and .sample files that
+// do not parse are reported as warnings and skipped. It exits with 1 when
+// secrets are found and 2 on error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/secrets"
+)
+
+func main() {
+	format := flag.String("format", "text", "output format, text or json")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [PATH...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *format != "text" && *format != "json" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{"../../configuration"}
+	}
+
+	scanner := secrets.NewScanner(secrets.NewResolver())
+	scanner.Warn = func(err error) {
+		fmt.Fprintln(os.Stderr, "scan-secrets: warning: skipped", err)
+	}
+	var findings []secrets.Finding
+	failed := false
+	for _, p := range paths {
+		f, err := scanner.Scan(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "scan-secrets:", err)
+			failed = true
+		}
+		findings = append(findings, f...)
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if findings == nil {
+			findings = []secrets.Finding{}
+		}
+		if err := enc.Encode(findings); err != nil {
+			fmt.Fprintln(os.Stderr, "scan-secrets:", err)
+			os.Exit(2)
+		}
+	} else {
+		for _, f := range findings {
+			fmt.Println(f)
+		}
+	}
+	switch {
+	case failed:
+		os.Exit(2)
+	case len(findings) > 0:
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command validation-coverage reports which variable validation rules of
+// the stages and modules the unit tests exercise, from the Terraform
+// diagnostics of unit test logs.
+//
+// Usage:
+//
+//	validation-coverage [-root DIR] [-format text|json] [-uncovered] [-min PCT] [-min-dir PCT] LOG...
+//
+// A LOG of "-" is read from the standard input. It exits with 1 when the
+// coverage is below -min in total or below -min-dir in a directory, and
+// with 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/validation"
+)
+
+func main() {
+	root := flag.String("root", "../..", "root of the repository holding execution/ and modules/")
+	format := flag.String("format", "text", "output format, text or json")
+	uncovered := flag.Bool("uncovered", false, "list the rules no diagnostic was reported by")
+	minCoverage := flag.Float64("min", 0, "exit with 1 when the total coverage, in percent, is below this")
+	minDir := flag.Float64("min-dir", 0, "exit with 1 when the coverage of a directory, in percent, is below this")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] LOG...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || (*format != "text" && *format != "json") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	rules, err := validation.Load(*root, "execution", "modules")
+	if err != nil {
+		fail(err)
+	}
+	for _, name := range flag.Args() {
+		diags, err := readLog(name)
+		if err != nil {
+			fail(err)
+		}
+		validation.Match(rules, diags)
+	}
+
+	r := validation.NewReport(rules)
+	if *format == "json" {
+		err = r.WriteJSON(os.Stdout)
+	} else {
+		err = r.WriteText(os.Stdout, *uncovered)
+	}
+	if err != nil {
+		fail(err)
+	}
+	if failures := r.Check(*minCoverage, *minDir); len(failures) > 0 {
+		for _, f := range failures {
+			fmt.Fprintln(os.Stderr, "validation-coverage:", f)
+		}
+		os.Exit(1)
+	}
+}
+
+func readLog(name string) ([]validation.Diagnostic, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	diags, err := validation.ParseDiagnostics(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return diags, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "validation-coverage:", err)
+	os.Exit(2)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package command runs the external commands the tools depend on, such as
+// terraform, gcloud and vault. Packages take a Runner, Exec by default,
+// which their tests replace with a fake to run offline.
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Runner runs the command name with args in dir, the working directory when
+// dir is empty, and returns its standard output. A command which ran and
+// failed returns an *Error.
+type Runner func(ctx context.Context, dir, name string, args ...string) ([]byte, error)
+
+// Error is the error of a failed command, with its standard error.
+type Error struct {
+	Name   string
+	Args   []string
+	Err    error
+	Stderr string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s: %v", strings.Join(append([]string{e.Name}, e.Args...), " "), e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Exec runs commands with os/exec.
+func Exec(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return out, &Error{Name: name, Args: args, Err: err, Stderr: stderr.String()}
+	}
+	return out, nil
+}
+
+// Output returns the standard output of a command followed, when it failed,
+// by its standard error, where Terraform prints its diagnostics.
+func Output(out []byte, err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return string(out) + e.Stderr
+	}
+	return string(out)
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+	dir := t.TempDir()
+	out, err := Exec(context.Background(), dir, "sh", "-c", "pwd")
+	if err != nil {
+		t.Fatalf("Failed to run the command: %v", err)
+	}
+	if got := string(out); got != dir+"\n" {
+		t.Errorf("Exec() ran in %q, want %q", got, dir)
+	}
+
+	out, err = Exec(context.Background(), "", "sh", "-c", "echo out; echo oops >&2; exit 3")
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("Exec() error = %v, want an *Error", err)
+	}
+	if want := "sh -c echo out; echo oops >&2; exit 3: exit status 3: oops"; err.Error() != want {
+		t.Errorf("Exec() error = %q, want %q", err, want)
+	}
+	if got := Output(out, err); got != "out\noops\n" {
+		t.Errorf("Output() = %q, want the standard output then the standard error", got)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// VMInstanceConfig is the schema of a 06-consumer/GCE instance YAML file.
+type VMInstanceConfig struct {
+	Name                      string            `yaml:"name" doc:"Name of the VM instance."`
+	ProjectID                 string            `yaml:"project_id" doc:"Project the instance is created in."`
+	Region                    string            `yaml:"region" doc:"Region of the instance, e.g. us-central1."`
+	Zone                      string            `yaml:"zone" doc:"Zone of the instance, e.g. us-central1-a."`
+	Image                     string            `yaml:"image,omitempty"`
+	Network                   string            `yaml:"network" doc:"Self link of the VPC network, e.g. projects/<project-id>/global/networks/<network-name>."`
+	Subnetwork                string            `yaml:"subnetwork" doc:"Self link of the subnetwork, e.g. projects/<project-id>/regions/<region>/subnetworks/<subnetwork-name>."`
+	InstanceType              string            `yaml:"instance_type,omitempty"`
+	Description               string            `yaml:"description,omitempty"`
+	Hostname                  string            `yaml:"hostname,omitempty"`
+	CanIPForward              *bool             `yaml:"can_ip_forward,omitempty"`
+	EnableDisplay             *bool             `yaml:"enable_display,omitempty"`
+	MinCPUPlatform            string            `yaml:"min_cpu_platform,omitempty"`
+	Tags                      []string          `yaml:"tags,omitempty"`
+	TagBindings               map[string]string `yaml:"tag_bindings,omitempty"`
+	Labels                    map[string]string `yaml:"labels,omitempty"`
+	Metadata                  map[string]string `yaml:"metadata,omitempty"`
+	NetworkAttachedInterfaces []string          `yaml:"network_attached_interfaces,omitempty"`
+	ServiceAccount            map[string]any    `yaml:"service_account,omitempty"`
+	BootDisk                  map[string]any    `yaml:"boot_disk,omitempty"`
+	AttachedDisks             []map[string]any  `yaml:"attached_disks,omitempty"`
+	ScratchDisks              map[string]any    `yaml:"scratch_disks,omitempty"`
+	SnapshotSchedules         map[string]any    `yaml:"snapshot_schedules,omitempty"`
+	Options                   map[string]any    `yaml:"options,omitempty"`
+	ShieldedConfig            map[string]any    `yaml:"shielded_config,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *VMInstanceConfig) Resources() []Resource {
+	return []Resource{{Kind: "instance", Name: c.Name, Value: c}}
+}
+
+// AutoscalerConfig is the autoscaler block of a MIGConfig.
+type AutoscalerConfig struct {
+	MaxReplicas    int            `yaml:"max_replicas,omitempty"`
+	MinReplicas    int            `yaml:"min_replicas,omitempty"`
+	CooldownPeriod int            `yaml:"cooldown_period,omitempty"`
+	ScalingSignals map[string]any `yaml:"scaling_signals,omitempty"`
+}
+
+// MIGConfig is the schema of a 06-consumer/MIG managed instance group YAML file.
+type MIGConfig struct {
+	Name                string            `yaml:"name" doc:"Name of the managed instance group."`
+	ProjectID           string            `yaml:"project_id" doc:"Project the instance group is created in."`
+	Location            string            `yaml:"location" doc:"Region or zone of the instance group."`
+	Zone                string            `yaml:"zone,omitempty"`
+	VPCName             string            `yaml:"vpc_name" doc:"Name of the VPC network of the instances."`
+	SubnetworkName      string            `yaml:"subnetwork_name" doc:"Name of the subnetwork of the instances."`
+	TargetSize          *int              `yaml:"target_size,omitempty"`
+	Description         string            `yaml:"description,omitempty"`
+	AutoscalerConfig    *AutoscalerConfig `yaml:"autoscaler_config,omitempty"`
+	AutoHealingPolicies map[string]any    `yaml:"auto_healing_policies,omitempty"`
+	HealthCheckConfig   map[string]any    `yaml:"health_check_config,omitempty"`
+	DistributionPolicy  map[string]any    `yaml:"distribution_policy,omitempty"`
+	NamedPorts          map[string]int    `yaml:"named_ports,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *MIGConfig) Resources() []Resource {
+	return []Resource{{Kind: "instance_group", Name: c.Name, Value: c}}
+}
+
+// NamedPortConfig is a named port of an unmanaged instance group.
+type NamedPortConfig struct {
+	Name string `yaml:"name"`
+	Port int    `yaml:"port"`
+}
+
+// UMIGConfig is the schema of a 06-consumer/UMIG unmanaged instance group YAML file.
+type UMIGConfig struct {
+	Name        string            `yaml:"name" doc:"Name of the unmanaged instance group."`
+	ProjectID   string            `yaml:"project_id" doc:"Project the instance group is created in."`
+	Zone        string            `yaml:"zone" doc:"Zone of the instance group."`
+	Description string            `yaml:"description,omitempty" doc:"Description of the instance group."`
+	Network     string            `yaml:"network" doc:"VPC network of the instances."`
+	Instances   []string          `yaml:"instances,omitempty" doc:"Names or self links of the VM instances of the group."`
+	NamedPorts  []NamedPortConfig `yaml:"named_ports,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *UMIGConfig) Resources() []Resource {
+	return []Resource{{Kind: "instance_group", Name: c.Name, Value: c}}
+}
+
+// WorkbenchNetworkInterface is a network interface of a Workbench instance.
+type WorkbenchNetworkInterface struct {
+	Network        string `yaml:"network"`
+	Subnet         string `yaml:"subnet"`
+	NICType        string `yaml:"nic_type,omitempty"`
+	InternalIPOnly *bool  `yaml:"internal_ip_only,omitempty"`
+}
+
+// WorkbenchServiceAccount is a service account of a Workbench instance.
+type WorkbenchServiceAccount struct {
+	Email  string   `yaml:"email"`
+	Scopes []string `yaml:"scopes"`
+}
+
+// WorkbenchGCESetup is the gce_setup block of a WorkbenchConfig.
+type WorkbenchGCESetup struct {
+	MachineType               string                      `yaml:"machine_type,omitempty"`
+	ServiceAccounts           []WorkbenchServiceAccount   `yaml:"service_accounts,omitempty"`
+	InstanceOwners            []string                    `yaml:"instance_owners,omitempty"`
+	DisablePublicIP           *bool                       `yaml:"disable_public_ip,omitempty"`
+	DisableProxyAccess        *bool                       `yaml:"disable_proxy_access,omitempty"`
+	Tags                      []string                    `yaml:"tags,omitempty"`
+	Metadata                  map[string]string           `yaml:"metadata,omitempty"`
+	VMImage                   map[string]string           `yaml:"vm_image,omitempty"`
+	BootDiskType              string                      `yaml:"boot_disk_type,omitempty"`
+	BootDiskSizeGB            any                         `yaml:"boot_disk_size_gb,omitempty"`
+	DataDisks                 []map[string]any            `yaml:"data_disks,omitempty"`
+	NetworkInterfaces         []WorkbenchNetworkInterface `yaml:"network_interfaces,omitempty"`
+	AcceleratorConfigs        []map[string]any            `yaml:"accelerator_configs,omitempty"`
+	EnableSecureBoot          *bool                       `yaml:"enable_secure_boot,omitempty"`
+	EnableVTPM                *bool                       `yaml:"enable_vtpm,omitempty"`
+	EnableIntegrityMonitoring *bool                       `yaml:"enable_integrity_monitoring,omitempty"`
+	Labels                    map[string]string           `yaml:"labels,omitempty"`
+
+	// The keys below appear in the instance-expanded example but the stage
+	// does not read them.
+	ServiceAccount             string         `yaml:"service_account,omitempty" doc:"Not read by the stage, see service_accounts."`
+	NetworkTags                []string       `yaml:"network_tags,omitempty" doc:"Not read by the stage, see tags."`
+	BootDiskKMSKey             string         `yaml:"boot_disk_kms_key,omitempty" doc:"Not read by the stage."`
+	InstallGPUDriver           *bool          `yaml:"install_gpu_driver,omitempty" doc:"Not read by the stage."`
+	CustomGPUDriverPath        string         `yaml:"custom_gpu_driver_path,omitempty" doc:"Not read by the stage."`
+	IdleShutdown               *bool          `yaml:"idle_shutdown,omitempty" doc:"Not read by the stage."`
+	IdleShutdownTimeout        *int           `yaml:"idle_shutdown_timeout,omitempty" doc:"Not read by the stage."`
+	ShieldedInstanceConfig     map[string]any `yaml:"shielded_instance_config,omitempty" doc:"Not read by the stage, see enable_secure_boot."`
+	ConfidentialInstanceConfig map[string]any `yaml:"confidential_instance_config,omitempty" doc:"Not read by the stage."`
+}
+
+// WorkbenchConfig is the schema of a 06-consumer/Workbench instance YAML file.
+type WorkbenchConfig struct {
+	Name      string            `yaml:"name" doc:"Name of the Workbench instance."`
+	ProjectID string            `yaml:"project_id" doc:"Project the instance is created in."`
+	Location  string            `yaml:"location"`
+	GCESetup  WorkbenchGCESetup `yaml:"gce_setup,omitempty" doc:"Compute Engine setup of the instance."`
+}
+
+// Resources implements ResourceLister.
+func (c *WorkbenchConfig) Resources() []Resource {
+	return []Resource{{Kind: "instance", Name: c.Name, Value: c}}
+}
+
+// CloudRunStruct is the schema shared by the 06-consumer Cloud Run job and
+// service YAML files.
+type CloudRunStruct struct {
+	Name                 string                    `yaml:"name" doc:"Name of the Cloud Run job or service."`
+	ProjectID            string                    `yaml:"project_id" doc:"Project the job or service is created in."`
+	Region               string                    `yaml:"region" doc:"Region of the job or service, e.g. us-central1."`
+	Containers           map[string]map[string]any `yaml:"containers"`
+	CreateJob            *bool                     `yaml:"create_job,omitempty"`
+	CustomAudiences      []string                  `yaml:"custom_audiences,omitempty"`
+	EncryptionKey        string                    `yaml:"encryption_key,omitempty"`
+	EventarcTriggers     map[string]any            `yaml:"eventarc_triggers,omitempty"`
+	IAM                  map[string][]string       `yaml:"iam,omitempty"`
+	Prefix               string                    `yaml:"prefix,omitempty"`
+	TagBindings          map[string]string         `yaml:"tag_bindings,omitempty"`
+	Ingress              string                    `yaml:"ingress,omitempty" enum:"INGRESS_TRAFFIC_ALL,INGRESS_TRAFFIC_INTERNAL_ONLY,INGRESS_TRAFFIC_INTERNAL_LOAD_BALANCER"`
+	LaunchStage          string                    `yaml:"launch_stage,omitempty"`
+	Labels               map[string]string         `yaml:"labels,omitempty"`
+	ServiceAccount       string                    `yaml:"service_account,omitempty"`
+	ServiceAccountCreate *bool                     `yaml:"service_account_create,omitempty"`
+	Revision             map[string]any            `yaml:"revision,omitempty"`
+	VPCConnectorCreate   map[string]any            `yaml:"vpc_connector_create,omitempty"`
+	Volumes              map[string]any            `yaml:"volumes,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *CloudRunStruct) Resources() []Resource {
+	return []Resource{{Kind: "cloud_run", Name: c.Name, Value: c}}
+}
+
+// VPCAccessConnectorConfig is the schema of a 06-consumer/Serverless/VPCAccessConnector YAML file.
+type VPCAccessConnectorConfig struct {
+	Name          string `yaml:"name" doc:"Name of the Serverless VPC Access connector."`
+	ProjectID     string `yaml:"project_id" doc:"Project the connector is created in."`
+	Region        string `yaml:"region" doc:"Region of the connector, e.g. us-central1."`
+	Network       string `yaml:"network,omitempty"`
+	IPCIDRRange   string `yaml:"ip_cidr_range,omitempty"`
+	SubnetName    string `yaml:"subnet_name,omitempty"`
+	HostProjectID string `yaml:"host_project_id,omitempty"`
+	MachineType   string `yaml:"machine_type,omitempty"`
+	MinInstances  *int   `yaml:"min_instances,omitempty"`
+	MaxInstances  *int   `yaml:"max_instances,omitempty"`
+	MinThroughput *int   `yaml:"min_throughput,omitempty"`
+	MaxThroughput *int   `yaml:"max_throughput,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *VPCAccessConnectorConfig) Resources() []Resource {
+	return []Resource{{Kind: "connector", Name: c.Name, Value: c}}
+}
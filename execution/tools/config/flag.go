@@ -0,0 +1,46 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Flag is a boolean input. Some stage variables (create_nat, create_havpn,
+// create_interconnect) are declared as strings holding "true" or "false", so
+// Flag accepts both forms.
+type Flag bool
+
+// Enabled reports whether the flag is set and true.
+func (f *Flag) Enabled() bool {
+	return f != nil && bool(*f)
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (f *Flag) UnmarshalYAML(node *yaml.Node) error {
+	if node.Value == "" {
+		*f = false
+		return nil
+	}
+	b, err := strconv.ParseBool(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %q is not a boolean", node.Line, node.Value)
+	}
+	*f = Flag(b)
+	return nil
+}
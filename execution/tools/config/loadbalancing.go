@@ -0,0 +1,192 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// BackendConfig is a backend instance group of a passthrough network load
+// balancer. The group location is either group_zone or group_region; when
+// neither is set the load balancer region is used.
+type BackendConfig struct {
+	GroupName   string `yaml:"group_name" doc:"Name of the backend instance group."`
+	GroupZone   string `yaml:"group_zone,omitempty" doc:"Zone of a zonal instance group. Do not set together with group_region."`
+	GroupRegion string `yaml:"group_region,omitempty" doc:"Region of a regional instance group, defaults to the load balancer region."`
+	Description string `yaml:"description,omitempty"`
+	Failover    *bool  `yaml:"failover,omitempty"`
+}
+
+// ConnectionTrackingConfig is the connection_tracking block of a backend service.
+type ConnectionTrackingConfig struct {
+	IdleTimeoutSec         *int   `yaml:"idle_timeout_sec,omitempty"`
+	PersistConnOnUnhealthy string `yaml:"persist_conn_on_unhealthy,omitempty" enum:"DEFAULT_FOR_PROTOCOL,NEVER_PERSIST,ALWAYS_PERSIST"`
+	TrackPerSession        *bool  `yaml:"track_per_session,omitempty"`
+}
+
+// FailoverConfig is the failover_config block of a backend service.
+type FailoverConfig struct {
+	DisableConnDrain       *bool    `yaml:"disable_conn_drain,omitempty"`
+	DropTrafficIfUnhealthy *bool    `yaml:"drop_traffic_if_unhealthy,omitempty"`
+	Ratio                  *float64 `yaml:"ratio,omitempty" doc:"Failover ratio, between 0 and 1."`
+}
+
+// BackendServiceConfig is the backend_service block of a passthrough network
+// load balancer.
+type BackendServiceConfig struct {
+	Protocol                     string                    `yaml:"protocol,omitempty" enum:"TCP,UDP,UNSPECIFIED"`
+	PortName                     string                    `yaml:"port_name,omitempty"`
+	TimeoutSec                   *int                      `yaml:"timeout_sec,omitempty"`
+	ConnectionDrainingTimeoutSec *int                      `yaml:"connection_draining_timeout_sec,omitempty"`
+	LogSampleRate                *float64                  `yaml:"log_sample_rate,omitempty"`
+	LocalityLBPolicy             string                    `yaml:"locality_lb_policy,omitempty" enum:"MAGLEV,WEIGHTED_MAGLEV"`
+	SessionAffinity              string                    `yaml:"session_affinity,omitempty" enum:"NONE,CLIENT_IP,CLIENT_IP_PROTO,CLIENT_IP_PORT_PROTO"`
+	ConnectionTracking           *ConnectionTrackingConfig `yaml:"connection_tracking,omitempty"`
+	FailoverConfig               *FailoverConfig           `yaml:"failover_config,omitempty"`
+}
+
+// HealthCheckProtocolConfig is the protocol block (tcp, http, https, http2,
+// grpc or ssl) of a health check.
+type HealthCheckProtocolConfig struct {
+	Port              *int   `yaml:"port,omitempty"`
+	PortName          string `yaml:"port_name,omitempty"`
+	PortSpecification string `yaml:"port_specification,omitempty" enum:"USE_FIXED_PORT,USE_NAMED_PORT,USE_SERVING_PORT"`
+	Host              string `yaml:"host,omitempty"`
+	RequestPath       string `yaml:"request_path,omitempty"`
+	Request           string `yaml:"request,omitempty"`
+	Response          string `yaml:"response,omitempty"`
+	ProxyHeader       string `yaml:"proxy_header,omitempty" enum:"NONE,PROXY_V1"`
+	GRPCServiceName   string `yaml:"grpc_service_name,omitempty"`
+}
+
+// HealthCheckConfig is the health_check block of a passthrough network load
+// balancer. The External stage creates the health check from one protocol
+// block, or uses the existing health check name; the Internal stage reads
+// type, port, request_path and enable_log instead of the protocol blocks.
+type HealthCheckConfig struct {
+	Name               string                     `yaml:"name,omitempty" doc:"Existing health check to use instead of creating one (External stage)."`
+	Type               string                     `yaml:"type,omitempty" enum:"tcp,http,https" doc:"Protocol of the health check (Internal stage)."`
+	Port               *int                       `yaml:"port,omitempty" doc:"Port of the health check (Internal stage)."`
+	RequestPath        string                     `yaml:"request_path,omitempty" doc:"Request path of an http or https health check (Internal stage)."`
+	EnableLog          *bool                      `yaml:"enable_log,omitempty" doc:"Enables health check logging (Internal stage)."`
+	CheckIntervalSec   *int                       `yaml:"check_interval_sec,omitempty"`
+	TimeoutSec         *int                       `yaml:"timeout_sec,omitempty"`
+	HealthyThreshold   *int                       `yaml:"healthy_threshold,omitempty"`
+	UnhealthyThreshold *int                       `yaml:"unhealthy_threshold,omitempty"`
+	EnableLogging      *bool                      `yaml:"enable_logging,omitempty"`
+	Description        string                     `yaml:"description,omitempty"`
+	TCP                *HealthCheckProtocolConfig `yaml:"tcp,omitempty"`
+	HTTP               *HealthCheckProtocolConfig `yaml:"http,omitempty"`
+	HTTPS              *HealthCheckProtocolConfig `yaml:"https,omitempty"`
+	HTTP2              *HealthCheckProtocolConfig `yaml:"http2,omitempty"`
+	GRPC               *HealthCheckProtocolConfig `yaml:"grpc,omitempty"`
+	SSL                *HealthCheckProtocolConfig `yaml:"ssl,omitempty"`
+}
+
+// ForwardingRuleConfig is a forwarding rule of a passthrough network load
+// balancer.
+type ForwardingRuleConfig struct {
+	Name         string   `yaml:"name,omitempty" doc:"Name of the forwarding rule, defaults to the load balancer name (External stage)."`
+	Protocol     string   `yaml:"protocol,omitempty" enum:"TCP,UDP,L3_DEFAULT"`
+	Ports        []string `yaml:"ports,omitempty" doc:"Ports forwarded to the backends." type:"string,integer"`
+	Address      string   `yaml:"address,omitempty"`
+	Description  string   `yaml:"description,omitempty"`
+	IPv6         *bool    `yaml:"ipv6,omitempty"`
+	GlobalAccess *bool    `yaml:"global_access,omitempty"`
+	Subnetwork   string   `yaml:"subnetwork,omitempty"`
+}
+
+// NetworkLoadBalancerConfig is the schema of a
+// 07-consumer-load-balancing/Network/Passthrough/External YAML file.
+type NetworkLoadBalancerConfig struct {
+	Name            string                          `yaml:"name" doc:"Name of the load balancer."`
+	ProjectID       string                          `yaml:"project_id" doc:"Project the load balancer is created in."`
+	Region          string                          `yaml:"region" doc:"Region of the load balancer, e.g. us-central1."`
+	Description     string                          `yaml:"description,omitempty"`
+	Labels          map[string]string               `yaml:"labels,omitempty"`
+	BackendService  *BackendServiceConfig           `yaml:"backend_service,omitempty" doc:"Backend service settings."`
+	Backends        []BackendConfig                 `yaml:"backends" doc:"Backend instance groups."`
+	HealthCheck     *HealthCheckConfig              `yaml:"health_check,omitempty" doc:"Health check created for the backend service. Set exactly one protocol block."`
+	ForwardingRules map[string]ForwardingRuleConfig `yaml:"forwarding_rules,omitempty" doc:"Forwarding rules, keyed by name."`
+	// ForwardingRuleProtocol is the protocol of the forwarding rules which
+	// do not set one.
+	ForwardingRuleProtocol string `yaml:"forwarding_rule_protocol,omitempty" enum:"TCP,UDP,L3_DEFAULT"`
+}
+
+// Resources implements ResourceLister.
+func (c *NetworkLoadBalancerConfig) Resources() []Resource {
+	return []Resource{{Kind: "load_balancer", Name: c.Name, Value: c}}
+}
+
+// InternalNetworkLoadBalancerConfig is the schema of a
+// 07-consumer-load-balancing/Network/Passthrough/Internal YAML file. The
+// stage reads the session affinity and draining timeout at the top level,
+// not from backend_service.
+type InternalNetworkLoadBalancerConfig struct {
+	Name                         string                `yaml:"name" doc:"Name of the load balancer."`
+	Project                      string                `yaml:"project" doc:"Project the load balancer is created in."`
+	Region                       string                `yaml:"region" doc:"Region of the load balancer, e.g. us-central1."`
+	Description                  string                `yaml:"description,omitempty"`
+	Labels                       map[string]string     `yaml:"labels,omitempty"`
+	Network                      string                `yaml:"network" doc:"Self link of the VPC network."`
+	Subnetwork                   string                `yaml:"subnetwork" doc:"Self link of the subnetwork of the forwarding rule."`
+	SourceTags                   []string              `yaml:"source_tags,omitempty" doc:"Network tags of the clients allowed by the backend firewall rule."`
+	TargetTags                   []string              `yaml:"target_tags,omitempty" doc:"Network tags of the backends the firewall rules apply to."`
+	IsMirroringCollector         *bool                 `yaml:"is_mirroring_collector,omitempty"`
+	CreateBackendFirewall        *bool                 `yaml:"create_backend_firewall,omitempty"`
+	CreateHealthCheckFirewall    *bool                 `yaml:"create_health_check_firewall,omitempty"`
+	FirewallEnableLogging        *bool                 `yaml:"firewall_enable_logging,omitempty"`
+	SessionAffinity              string                `yaml:"session_affinity,omitempty" enum:"NONE,CLIENT_IP,CLIENT_IP_PROTO,CLIENT_IP_PORT_PROTO"`
+	ConnectionDrainingTimeoutSec *int                  `yaml:"connection_draining_timeout_sec,omitempty"`
+	BackendService               *BackendServiceConfig `yaml:"backend_service,omitempty" doc:"Backend service settings."`
+	Backends                     []BackendConfig       `yaml:"backends" doc:"Backend instance groups."`
+	HealthCheck                  *HealthCheckConfig    `yaml:"health_check,omitempty" doc:"Health check created for the backend service."`
+	ForwardingRule               *ForwardingRuleConfig `yaml:"forwarding_rule,omitempty" doc:"Forwarding rule of the load balancer."`
+}
+
+// Resources implements ResourceLister.
+func (c *InternalNetworkLoadBalancerConfig) Resources() []Resource {
+	return []Resource{{Kind: "load_balancer", Name: c.Name, Value: c}}
+}
+
+// GroupConfig is an instance group of an Application Load Balancer backend.
+type GroupConfig struct {
+	Group  string `yaml:"group" doc:"Name of the instance group."`
+	Region string `yaml:"region,omitempty"`
+	Zone   string `yaml:"zone,omitempty"`
+}
+
+// ApplicationBackendConfig is a backend of an Application Load Balancer.
+type ApplicationBackendConfig struct {
+	Protocol    string         `yaml:"protocol,omitempty" enum:"HTTP,HTTPS,HTTP2"`
+	Port        *int           `yaml:"port,omitempty"`
+	PortName    string         `yaml:"port_name,omitempty"`
+	TimeoutSec  *int           `yaml:"timeout_sec,omitempty"`
+	EnableCDN   *bool          `yaml:"enable_cdn,omitempty"`
+	HealthCheck map[string]any `yaml:"health_check,omitempty"`
+	LogConfig   map[string]any `yaml:"log_config,omitempty"`
+	IAPConfig   map[string]any `yaml:"iap_config,omitempty"`
+	Groups      []GroupConfig  `yaml:"groups" doc:"Backend instance groups."`
+}
+
+// LoadBalancerConfig is the schema of a
+// 07-consumer-load-balancing/Application/External YAML file.
+type LoadBalancerConfig struct {
+	Name     string                              `yaml:"name" doc:"Name of the load balancer."`
+	Project  string                              `yaml:"project" doc:"Project the load balancer is created in."`
+	Network  string                              `yaml:"network" doc:"Name of the VPC network."`
+	Backends map[string]ApplicationBackendConfig `yaml:"backends" doc:"Backend services, keyed by name."`
+}
+
+// Resources implements ResourceLister.
+func (c *LoadBalancerConfig) Resources() []Resource {
+	return []Resource{{Kind: "load_balancer", Name: c.Name, Value: c}}
+}
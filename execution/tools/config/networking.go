@@ -0,0 +1,335 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import "fmt"
+
+// SubnetConfig is a subnet of the 02-networking subnets variable.
+type SubnetConfig struct {
+	Name                string            `yaml:"name"`
+	IPCIDRRange         string            `yaml:"ip_cidr_range"`
+	Region              string            `yaml:"region"`
+	Description         string            `yaml:"description,omitempty"`
+	EnablePrivateAccess *bool             `yaml:"enable_private_access,omitempty"`
+	SecondaryIPRanges   map[string]string `yaml:"secondary_ip_ranges,omitempty"`
+	FlowLogsConfig      map[string]any    `yaml:"flow_logs_config,omitempty"`
+}
+
+// PeerGatewayConfig is an entry of the 02-networking peer_gateways variable.
+type PeerGatewayConfig struct {
+	External map[string]any `yaml:"external,omitempty"`
+	GCP      string         `yaml:"gcp,omitempty"`
+}
+
+// NetworkingVars is the schema of networking.tfvars, the input of the
+// 02-networking stage.
+type NetworkingVars struct {
+	ProjectID           string                       `yaml:"project_id"`
+	Region              string                       `yaml:"region"`
+	NetworkName         string                       `yaml:"network_name"`
+	CreateNetwork       *Flag                        `yaml:"create_network,omitempty"`
+	CreateSubnetwork    *Flag                        `yaml:"create_subnetwork,omitempty"`
+	CreatePSA           *Flag                        `yaml:"create_psa,omitempty"`
+	PSARangeName        string                       `yaml:"psa_range_name,omitempty"`
+	PSARange            string                       `yaml:"psa_range,omitempty"`
+	Subnets             []SubnetConfig               `yaml:"subnets,omitempty"`
+	SharedVPCHost       *Flag                        `yaml:"shared_vpc_host,omitempty"`
+	CreateSCPPolicy     *Flag                        `yaml:"create_scp_policy,omitempty"`
+	SubnetsForSCPPolicy []string                     `yaml:"subnets_for_scp_policy,omitempty"`
+	CreateNAT           *Flag                        `yaml:"create_nat,omitempty"`
+	NATName             string                       `yaml:"nat_name,omitempty"`
+	CreateHAVPN         *Flag                        `yaml:"create_havpn,omitempty"`
+	HAVPNGateway1Name   string                       `yaml:"ha_vpn_gateway1_name,omitempty"`
+	PeerGateways        map[string]PeerGatewayConfig `yaml:"peer_gateways,omitempty"`
+	Router1ASN          *int64                       `yaml:"router1_asn,omitempty"`
+
+	Tunnel1BGPPeerIPAddress      string `yaml:"tunnel_1_bgp_peer_ip_address,omitempty"`
+	Tunnel1BGPPeerASN            *int64 `yaml:"tunnel_1_bgp_peer_asn,omitempty"`
+	Tunnel1RouterBGPSessionRange string `yaml:"tunnel_1_router_bgp_session_range,omitempty"`
+	Tunnel1SharedSecret          string `yaml:"tunnel_1_shared_secret,omitempty"`
+	Tunnel2BGPPeerIPAddress      string `yaml:"tunnel_2_bgp_peer_ip_address,omitempty"`
+	Tunnel2BGPPeerASN            *int64 `yaml:"tunnel_2_bgp_peer_asn,omitempty"`
+	Tunnel2RouterBGPSessionRange string `yaml:"tunnel_2_router_bgp_session_range,omitempty"`
+	Tunnel2SharedSecret          string `yaml:"tunnel_2_shared_secret,omitempty"`
+
+	CreateInterconnect     *Flag  `yaml:"create_interconnect,omitempty"`
+	InterconnectProjectID  string `yaml:"interconnect_project_id,omitempty"`
+	FirstInterconnectName  string `yaml:"first_interconnect_name,omitempty"`
+	SecondInterconnectName string `yaml:"second_interconnect_name,omitempty"`
+	ICRouterName           string `yaml:"ic_router_name,omitempty"`
+	ICRouterBGPASN         string `yaml:"ic_router_bgp_asn,omitempty"`
+	FirstVAName            string `yaml:"first_va_name,omitempty"`
+	FirstVAASN             string `yaml:"first_va_asn,omitempty"`
+	FirstVABandwidth       string `yaml:"first_va_bandwidth,omitempty"`
+	FirstVABGPRange        string `yaml:"first_va_bgp_range,omitempty"`
+	FirstVLANTag           *int   `yaml:"first_vlan_tag,omitempty"`
+	SecondVAName           string `yaml:"second_va_name,omitempty"`
+	SecondVAASN            string `yaml:"second_va_asn,omitempty"`
+	SecondVABandwidth      string `yaml:"second_va_bandwidth,omitempty"`
+	SecondVABGPRange       string `yaml:"second_va_bgp_range,omitempty"`
+	SecondVLANTag          *int   `yaml:"second_vlan_tag,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (v *NetworkingVars) Resources() []Resource {
+	resources := []Resource{{Kind: "network", Name: v.NetworkName, Value: v.networkSettings()}}
+	for i := range v.Subnets {
+		s := &v.Subnets[i]
+		resources = append(resources, Resource{Kind: "subnet", Name: s.Region + "/" + s.Name, Value: s})
+	}
+	if v.CreateHAVPN.Enabled() {
+		resources = append(resources,
+			Resource{Kind: "vpn_tunnel", Name: "remote-0", Value: map[string]any{
+				"bgp_peer_ip_address":      v.Tunnel1BGPPeerIPAddress,
+				"bgp_peer_asn":             v.Tunnel1BGPPeerASN,
+				"router_bgp_session_range": v.Tunnel1RouterBGPSessionRange,
+			}},
+			Resource{Kind: "vpn_tunnel", Name: "remote-1", Value: map[string]any{
+				"bgp_peer_ip_address":      v.Tunnel2BGPPeerIPAddress,
+				"bgp_peer_asn":             v.Tunnel2BGPPeerASN,
+				"router_bgp_session_range": v.Tunnel2RouterBGPSessionRange,
+			}})
+	}
+	if v.CreateInterconnect.Enabled() {
+		resources = append(resources,
+			Resource{Kind: "vlan_attachment", Name: v.FirstVAName, Value: map[string]any{
+				"interconnect": v.FirstInterconnectName,
+				"asn":          v.FirstVAASN,
+				"bandwidth":    v.FirstVABandwidth,
+				"bgp_range":    v.FirstVABGPRange,
+				"vlan_tag":     v.FirstVLANTag,
+			}},
+			Resource{Kind: "vlan_attachment", Name: v.SecondVAName, Value: map[string]any{
+				"interconnect": v.SecondInterconnectName,
+				"asn":          v.SecondVAASN,
+				"bandwidth":    v.SecondVABandwidth,
+				"bgp_range":    v.SecondVABGPRange,
+				"vlan_tag":     v.SecondVLANTag,
+			}})
+	}
+	return resources
+}
+
+// networkSettings returns the network wide settings, leaving out the values
+// already reported as their own resources.
+func (v *NetworkingVars) networkSettings() map[string]any {
+	return map[string]any{
+		"project_id":             v.ProjectID,
+		"region":                 v.Region,
+		"create_network":         v.CreateNetwork,
+		"create_subnetwork":      v.CreateSubnetwork,
+		"create_psa":             v.CreatePSA,
+		"psa_range_name":         v.PSARangeName,
+		"psa_range":              v.PSARange,
+		"shared_vpc_host":        v.SharedVPCHost,
+		"create_scp_policy":      v.CreateSCPPolicy,
+		"subnets_for_scp_policy": v.SubnetsForSCPPolicy,
+		"create_nat":             v.CreateNAT,
+		"create_havpn":           v.CreateHAVPN,
+		"create_interconnect":    v.CreateInterconnect,
+	}
+}
+
+// HubConfig is an NCC hub of an NCCConfig.
+type HubConfig struct {
+	Name               string            `yaml:"name" doc:"Name of the NCC hub."`
+	ProjectID          string            `yaml:"project_id" doc:"Project of the hub."`
+	Description        string            `yaml:"description,omitempty"`
+	Labels             map[string]string `yaml:"labels,omitempty"`
+	ExportPSC          *bool             `yaml:"export_psc,omitempty"`
+	PolicyMode         string            `yaml:"policy_mode,omitempty" enum:"PRESET"`
+	PresetTopology     string            `yaml:"preset_topology,omitempty" enum:"MESH,STAR"`
+	AutoAcceptProjects []string          `yaml:"auto_accept_projects,omitempty"`
+	CreateNewHub       *bool             `yaml:"create_new_hub,omitempty"`
+	ExistingHubURI     string            `yaml:"existing_hub_uri,omitempty"`
+	GroupName          string            `yaml:"group_name,omitempty"`
+	GroupDescription   string            `yaml:"group_decription,omitempty"`
+	SpokeLabels        map[string]string `yaml:"spoke_labels,omitempty"`
+}
+
+// RouterApplianceInstance is a router appliance VM of a router_appliance_spoke.
+type RouterApplianceInstance struct {
+	VirtualMachine string `yaml:"virtual_machine" doc:"Self link of the router appliance VM."`
+	IPAddress      string `yaml:"ip_address" doc:"Internal IP address of the VM."`
+}
+
+// SpokeConfig is an NCC spoke of an NCCConfig.
+type SpokeConfig struct {
+	Type                   string                    `yaml:"type" enum:"linked_vpc_network,linked_producer_vpc_network,linked_vpn_tunnels,linked_interconnect_attachments,router_appliance_spoke"`
+	Name                   string                    `yaml:"name" doc:"Name of the spoke."`
+	ProjectID              string                    `yaml:"project_id" doc:"Project of the spoke."`
+	Location               string                    `yaml:"location,omitempty"`
+	URI                    string                    `yaml:"uri,omitempty"`
+	URIs                   []string                  `yaml:"uris,omitempty"`
+	Router                 string                    `yaml:"router,omitempty"`
+	Description            string                    `yaml:"description,omitempty"`
+	Labels                 map[string]string         `yaml:"labels,omitempty"`
+	Group                  string                    `yaml:"group,omitempty"`
+	Peering                string                    `yaml:"peering,omitempty"`
+	ExcludeExportRanges    []string                  `yaml:"exclude_export_ranges,omitempty"`
+	IncludeExportRanges    []string                  `yaml:"include_export_ranges,omitempty"`
+	SiteToSiteDataTransfer *bool                     `yaml:"site_to_site_data_transfer,omitempty"`
+	Instances              []RouterApplianceInstance `yaml:"instances,omitempty" doc:"Router appliance VMs of a router_appliance_spoke."`
+}
+
+// NCCConfig is the schema of a 02-networking/NCC YAML file.
+type NCCConfig struct {
+	Hubs   []HubConfig   `yaml:"hubs" doc:"NCC hubs."`
+	Spokes []SpokeConfig `yaml:"spokes,omitempty" doc:"NCC spokes attached to the hubs."`
+}
+
+// Resources implements ResourceLister.
+func (c *NCCConfig) Resources() []Resource {
+	var resources []Resource
+	for i := range c.Hubs {
+		resources = append(resources, Resource{Kind: "hub", Name: c.Hubs[i].Name, Value: &c.Hubs[i]})
+	}
+	for i := range c.Spokes {
+		resources = append(resources, Resource{Kind: "spoke", Name: c.Spokes[i].Name, Value: &c.Spokes[i]})
+	}
+	return resources
+}
+
+// NetworkRef references a VPC network by URL.
+type NetworkRef struct {
+	NetworkURL string `yaml:"network_url"`
+}
+
+// PrivateVisibilityConfig lists the networks a private zone is visible to.
+type PrivateVisibilityConfig struct {
+	Networks []NetworkRef `yaml:"networks"`
+}
+
+// TargetNameServer is a forwarding target of a forwarding zone.
+type TargetNameServer struct {
+	IPv4Address    string `yaml:"ipv4_address"`
+	ForwardingPath string `yaml:"forwarding_path,omitempty" enum:"default,private"`
+}
+
+// ForwardingConfig holds the name servers a forwarding zone forwards to.
+type ForwardingConfig struct {
+	TargetNameServers []TargetNameServer `yaml:"target_name_servers"`
+}
+
+// PeeringConfig holds the network a peering zone peers with.
+type PeeringConfig struct {
+	TargetNetwork NetworkRef `yaml:"target_network"`
+}
+
+// ZoneConfig is the zone_config block of a managed zone.
+type ZoneConfig struct {
+	Domain                  string                   `yaml:"domain" doc:"DNS name of the zone, with a trailing dot, e.g. example.com."`
+	Visibility              string                   `yaml:"visibility,omitempty" enum:"public,private"`
+	ReverseLookup           bool                     `yaml:"reverse_lookup,omitempty"`
+	PrivateVisibilityConfig *PrivateVisibilityConfig `yaml:"private_visibility_config,omitempty"`
+	ForwardingConfig        *ForwardingConfig        `yaml:"forwarding_config,omitempty"`
+	PeeringConfig           *PeeringConfig           `yaml:"peering_config,omitempty"`
+}
+
+// RecordSet is a record set of a managed zone.
+type RecordSet struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type" enum:"A,AAAA,CAA,CNAME,DNSKEY,DS,HTTPS,IPSECKEY,MX,NAPTR,NS,PTR,SOA,SPF,SRV,SSHFP,SVCB,TLSA,TXT"`
+	TTL     int      `yaml:"ttl"`
+	Records []string `yaml:"records"`
+}
+
+// Zone is a managed zone of a DNSConfig.
+type Zone struct {
+	Name         string              `yaml:"zone" doc:"Name of the managed zone."`
+	ProjectID    string              `yaml:"project_id"`
+	Description  string              `yaml:"description,omitempty"`
+	ForceDestroy bool                `yaml:"force_destroy,omitempty"`
+	IAM          map[string][]string `yaml:"iam,omitempty" doc:"IAM bindings of the zone, from role to members."`
+	ZoneConfig   ZoneConfig          `yaml:"zone_config"`
+	Recordsets   []RecordSet         `yaml:"recordsets,omitempty"`
+}
+
+// DNSConfig is the schema of a 02-networking/CloudDNS/DNSManagedZones YAML file.
+type DNSConfig struct {
+	Zones []Zone `yaml:"zones" doc:"Cloud DNS managed zones."`
+}
+
+// Resources implements ResourceLister.
+func (c *DNSConfig) Resources() []Resource {
+	var resources []Resource
+	for i := range c.Zones {
+		z := &c.Zones[i]
+		resources = append(resources, Resource{Kind: "zone", Name: z.Name, Value: z.withoutRecordsets()})
+		for j := range z.Recordsets {
+			rs := &z.Recordsets[j]
+			resources = append(resources, Resource{Kind: "recordset", Name: fmt.Sprintf("%s/%s %s", z.Name, rs.Type, rs.Name), Value: rs})
+		}
+	}
+	return resources
+}
+
+func (z *Zone) withoutRecordsets() *Zone {
+	c := *z
+	c.Recordsets = nil
+	return &c
+}
+
+// Record is a local_data record of a response policy rule.
+type Record struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type" enum:"A,AAAA,CAA,CNAME,MX,PTR,SRV,TXT"`
+	TTL     int      `yaml:"ttl"`
+	RRDatas []string `yaml:"rrdatas"`
+}
+
+// Rule is a response policy rule.
+type Rule struct {
+	DNSName   string            `yaml:"dns_name" doc:"DNS name the rule applies to, with a trailing dot."`
+	Behavior  string            `yaml:"behavior,omitempty"`
+	LocalData map[string]Record `yaml:"local_data,omitempty"`
+}
+
+// ResponsePolicy is a response policy of a ResponsePoliciesConfig. Rules are
+// written as a list of single entry maps from rule name to rule.
+type ResponsePolicy struct {
+	Name               string            `yaml:"name"`
+	ProjectID          string            `yaml:"project_id"`
+	Description        string            `yaml:"description,omitempty"`
+	PolicyCreate       *bool             `yaml:"policy_create,omitempty"`
+	Networks           map[string]string `yaml:"networks,omitempty"`
+	Clusters           map[string]string `yaml:"clusters,omitempty"`
+	FactoriesConfig    map[string]any    `yaml:"factories_config,omitempty"`
+	ForwardingPolicies map[string]any    `yaml:"forwarding_policies,omitempty"`
+	Rules              []map[string]Rule `yaml:"rules,omitempty"`
+}
+
+// ResponsePoliciesConfig is the schema of a
+// 02-networking/CloudDNS/CloudDNSResponsePolicy YAML file.
+type ResponsePoliciesConfig struct {
+	ResponsePolicies []ResponsePolicy `yaml:"response_policies" doc:"Cloud DNS response policies."`
+}
+
+// Resources implements ResourceLister.
+func (c *ResponsePoliciesConfig) Resources() []Resource {
+	var resources []Resource
+	for i := range c.ResponsePolicies {
+		p := &c.ResponsePolicies[i]
+		policy := *p
+		policy.Rules = nil
+		resources = append(resources, Resource{Kind: "response_policy", Name: p.Name, Value: &policy})
+		for _, entry := range p.Rules {
+			for name, rule := range entry {
+				resources = append(resources, Resource{Kind: "rule", Name: p.Name + "/" + name, Value: rule})
+			}
+		}
+	}
+	return resources
+}
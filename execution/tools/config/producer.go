@@ -0,0 +1,375 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// AllocatedIPRangesStruct represents the allocated IP Ranges in the PSA Configuration(PSAConfigStruct).
+type AllocatedIPRangesStruct struct {
+	Primary string `yaml:"primary,omitempty" doc:"Name of the PSA allocated range used by the primary instance."`
+	Replica string `yaml:"replica,omitempty" doc:"Name of the PSA allocated range used by the replicas."`
+}
+
+// PSAConfigStruct represents the PSA configurations in the Connectivity Struct.
+type PSAConfigStruct struct {
+	PrivateNetwork    string                   `yaml:"private_network" doc:"Self link of the VPC network the instance is connected to through Private Service Access, e.g. projects/<project-id>/global/networks/<network-name>."`
+	AllocatedIPRanges *AllocatedIPRangesStruct `yaml:"allocated_ip_ranges,omitempty"`
+}
+
+// ConnectivityStruct represents the Connectivity in the network configuration.
+type ConnectivityStruct struct {
+	PublicIPV4                 bool             `yaml:"public_ipv4,omitempty" doc:"Whether the instance is assigned a public IPv4 address."`
+	PSAConfig                  *PSAConfigStruct `yaml:"psa_config,omitempty" doc:"Private Service Access connectivity."`
+	PSCAllowedConsumerProjects []string         `yaml:"psc_allowed_consumer_projects,omitempty" doc:"Projects allowed to connect to the instance through Private Service Connect."`
+}
+
+// NetworkConfigStruct represent the Network Config in the CloudSQLStruct.
+type NetworkConfigStruct struct {
+	AuthorizedNetworks map[string]string  `yaml:"authorized_networks,omitempty" doc:"Map of names to CIDR ranges allowed to connect to the public IP address."`
+	Connectivity       ConnectivityStruct `yaml:"connectivity" doc:"Connectivity of the instance, through PSA or PSC."`
+}
+
+// CloudSQLStruct is the schema of a 04-producer/CloudSQL instance YAML file.
+type CloudSQLStruct struct {
+	Name                        string              `yaml:"name" doc:"Name of the Cloud SQL instance."`
+	ProjectID                   string              `yaml:"project_id" doc:"Project the instance is created in."`
+	Region                      string              `yaml:"region" doc:"Region of the instance, e.g. us-central1."`
+	DatabaseVersion             string              `yaml:"database_version"`
+	NetworkConfig               NetworkConfigStruct `yaml:"network_config" doc:"Network configuration of the instance."`
+	Tier                        string              `yaml:"tier,omitempty"`
+	Edition                     string              `yaml:"edition,omitempty" enum:"ENTERPRISE,ENTERPRISE_PLUS"`
+	AvailabilityType            string              `yaml:"availability_type,omitempty" enum:"ZONAL,REGIONAL"`
+	ActivationPolicy            string              `yaml:"activation_policy,omitempty" enum:"ALWAYS,NEVER,ON_DEMAND"`
+	DiskSize                    *int                `yaml:"disk_size,omitempty"`
+	DiskType                    string              `yaml:"disk_type,omitempty" enum:"PD_SSD,PD_HDD"`
+	DiskAutoresizeLimit         *int                `yaml:"disk_autoresize_limit,omitempty"`
+	Collation                   string              `yaml:"collation,omitempty"`
+	ConnectorEnforcement        string              `yaml:"connector_enforcement,omitempty" enum:"NOT_REQUIRED,REQUIRED"`
+	DataCache                   *bool               `yaml:"data_cache,omitempty"`
+	Timezone                    string              `yaml:"timezone,omitempty" var:"time_zone"`
+	Encryption                  string              `yaml:"encryption,omitempty" var:"encryption_key_name"`
+	Prefix                      string              `yaml:"prefix,omitempty"`
+	RootPassword                string              `yaml:"root_password,omitempty"`
+	Databases                   []string            `yaml:"databases,omitempty"`
+	Flags                       map[string]any      `yaml:"flags,omitempty"`
+	Labels                      map[string]string   `yaml:"labels,omitempty"`
+	Users                       map[string]any      `yaml:"users,omitempty"`
+	Replicas                    map[string]any      `yaml:"replicas,omitempty"`
+	BackupConfiguration         map[string]any      `yaml:"backup_configuration,omitempty"`
+	InsightsConfig              map[string]any      `yaml:"insights_config,omitempty"`
+	MaintenanceConfig           map[string]any      `yaml:"maintenance_config,omitempty"`
+	SSL                         map[string]any      `yaml:"ssl,omitempty"`
+	TerraformDeletionProtection *bool               `yaml:"terraform_deletion_protection,omitempty"`
+	GCPDeletionProtection       *bool               `yaml:"gcp_deletion_protection,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *CloudSQLStruct) Resources() []Resource {
+	return []Resource{{Kind: "instance", Name: c.Name, Value: c}}
+}
+
+// PrimaryInstanceStruct is the primary instance of an AlloyDB cluster.
+type PrimaryInstanceStruct struct {
+	InstanceID          string         `yaml:"instance_id" doc:"ID of the primary instance."`
+	DisplayName         string         `yaml:"display_name,omitempty"`
+	InstanceType        string         `yaml:"instance_type,omitempty" enum:"PRIMARY"`
+	MachineCPUCount     int            `yaml:"machine_cpu_count,omitempty" enum:"2,4,8,16,32,64,96,128"`
+	DatabaseFlags       map[string]any `yaml:"database_flags,omitempty"`
+	AvailabilityType    string         `yaml:"availability_type,omitempty" enum:"ZONAL,REGIONAL"`
+	GCEZone             string         `yaml:"gce_zone,omitempty"`
+	SSLMode             string         `yaml:"ssl_mode,omitempty"`
+	RequireConnectors   *bool          `yaml:"require_connectors,omitempty"`
+	QueryInsightsConfig map[string]any `yaml:"query_insights_config,omitempty"`
+}
+
+// ReadPoolInstanceStruct is a read pool instance of an AlloyDB cluster.
+type ReadPoolInstanceStruct struct {
+	InstanceID          string         `yaml:"instance_id" doc:"ID of the read pool instance."`
+	DisplayName         string         `yaml:"display_name,omitempty"`
+	NodeCount           int            `yaml:"node_count,omitempty" doc:"Number of nodes of the read pool."`
+	MachineCPUCount     int            `yaml:"machine_cpu_count,omitempty" enum:"2,4,8,16,32,64,96,128"`
+	DatabaseFlags       map[string]any `yaml:"database_flags,omitempty"`
+	AvailabilityType    string         `yaml:"availability_type,omitempty" enum:"ZONAL,REGIONAL"`
+	GCEZone             string         `yaml:"gce_zone,omitempty"`
+	SSLMode             string         `yaml:"ssl_mode,omitempty"`
+	RequireConnectors   *bool          `yaml:"require_connectors,omitempty"`
+	QueryInsightsConfig map[string]any `yaml:"query_insights_config,omitempty"`
+}
+
+// AlloyDBStruct is the schema of a 04-producer/AlloyDB cluster YAML file.
+type AlloyDBStruct struct {
+	ClusterID                  string                   `yaml:"cluster_id" doc:"ID of the AlloyDB cluster."`
+	ClusterDisplayName         string                   `yaml:"cluster_display_name" doc:"Display name of the AlloyDB cluster."`
+	ProjectID                  string                   `yaml:"project_id" doc:"Project the cluster is created in."`
+	Region                     string                   `yaml:"region" doc:"Region of the cluster, e.g. us-central1."`
+	NetworkID                  string                   `yaml:"network_id,omitempty"`
+	DatabaseVersion            string                   `yaml:"database_version,omitempty"`
+	PrimaryInstance            PrimaryInstanceStruct    `yaml:"primary_instance" doc:"Primary instance of the cluster."`
+	ReadPoolInstance           []ReadPoolInstanceStruct `yaml:"read_pool_instance,omitempty"`
+	AllocatedIPRange           string                   `yaml:"allocated_ip_range,omitempty"`
+	ConnectivityOptions        string                   `yaml:"connectivity_options,omitempty" enum:"PSA,PSC,psa,psc"`
+	PSCAllowedConsumerProjects []string                 `yaml:"psc_allowed_consumer_projects,omitempty"`
+	ClusterLabels              map[string]string        `yaml:"cluster_labels,omitempty"`
+	ClusterInitialUser         map[string]any           `yaml:"cluster_initial_user,omitempty"`
+	AutomatedBackupPolicy      map[string]any           `yaml:"automated_backup_policy,omitempty"`
+	ClusterEncryptionKeyName   string                   `yaml:"cluster_encryption_key_name,omitempty"`
+	DeletionProtection         *bool                    `yaml:"deletion_protection,omitempty" doc:"Whether Terraform is prevented from deleting the cluster."`
+}
+
+// Resources implements ResourceLister.
+func (c *AlloyDBStruct) Resources() []Resource {
+	return []Resource{{Kind: "cluster", Name: c.ClusterID, Value: c}}
+}
+
+// MRCStruct is the schema of a 04-producer/MRC Memorystore Redis Cluster YAML file.
+type MRCStruct struct {
+	InstanceName              string `yaml:"redis_cluster_name" doc:"Name of the Memorystore for Redis Cluster."`
+	ProjectID                 string `yaml:"project_id" doc:"Project the cluster is created in."`
+	NetworkID                 string `yaml:"network_id" doc:"Self link of the VPC network of the PSC endpoints, e.g. projects/<project-id>/global/networks/<network-name>."`
+	Region                    string `yaml:"region,omitempty"`
+	ShardCount                *int   `yaml:"shard_count,omitempty"`
+	ReplicaCount              *int   `yaml:"replica_count,omitempty"`
+	DeletionProtectionEnabled *bool  `yaml:"deletion_protection_enabled,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *MRCStruct) Resources() []Resource {
+	return []Resource{{Kind: "cluster", Name: c.InstanceName, Value: c}}
+}
+
+// GKEConfig is the schema of a 04-producer/GKE cluster YAML file.
+type GKEConfig struct {
+	Name                                    string                       `yaml:"name" doc:"Name of the GKE cluster."`
+	ProjectID                               string                       `yaml:"project_id" doc:"Project the cluster is created in."`
+	Region                                  string                       `yaml:"region,omitempty"`
+	Zones                                   []string                     `yaml:"zones,omitempty"`
+	Regional                                *bool                        `yaml:"regional,omitempty"`
+	KubernetesVersion                       string                       `yaml:"kubernetes_version,omitempty"`
+	Network                                 string                       `yaml:"network" doc:"VPC network of the cluster."`
+	Subnetwork                              string                       `yaml:"subnetwork" doc:"Subnetwork of the cluster nodes."`
+	NetworkProjectID                        string                       `yaml:"network_project_id,omitempty"`
+	IPRangePods                             string                       `yaml:"ip_range_pods" doc:"Name of the subnet secondary range used for pods."`
+	IPRangeServices                         string                       `yaml:"ip_range_services" doc:"Name of the subnet secondary range used for services."`
+	AdditionalIPRangePods                   []string                     `yaml:"additional_ip_range_pods,omitempty"`
+	MasterIPV4CIDRBlock                     string                       `yaml:"master_ipv4_cidr_block,omitempty"`
+	MasterAuthorizedNetworks                []map[string]any             `yaml:"master_authorized_networks,omitempty"`
+	EnablePrivateNodes                      *bool                        `yaml:"enable_private_nodes,omitempty"`
+	EnablePrivateEndpoint                   *bool                        `yaml:"enable_private_endpoint,omitempty"`
+	NodePools                               []map[string]any             `yaml:"node_pools,omitempty"`
+	ReleaseChannel                          string                       `yaml:"release_channel,omitempty" enum:"RAPID,REGULAR,STABLE,EXTENDED,UNSPECIFIED"`
+	RemoveDefaultNodePool                   *bool                        `yaml:"remove_default_node_pool,omitempty"`
+	DeletionProtection                      *bool                        `yaml:"deletion_protection,omitempty"`
+	ClusterResourceLabels                   map[string]string            `yaml:"cluster_resource_labels,omitempty"`
+	Description                             string                       `yaml:"description,omitempty"`
+	EnableVerticalPodAutoscaling            *bool                        `yaml:"enable_vertical_pod_autoscaling,omitempty"`
+	HorizontalPodAutoscaling                *bool                        `yaml:"horizontal_pod_autoscaling,omitempty"`
+	HTTPLoadBalancing                       *bool                        `yaml:"http_load_balancing,omitempty"`
+	ServiceExternalIPs                      *bool                        `yaml:"service_external_ips,omitempty"`
+	DatapathProvider                        string                       `yaml:"datapath_provider,omitempty"`
+	MaintenanceStartTime                    string                       `yaml:"maintenance_start_time,omitempty"`
+	MaintenanceExclusions                   []map[string]any             `yaml:"maintenance_exclusions,omitempty"`
+	MaintenanceEndTime                      string                       `yaml:"maintenance_end_time,omitempty"`
+	MaintenanceRecurrence                   string                       `yaml:"maintenance_recurrence,omitempty"`
+	StackType                               string                       `yaml:"stack_type,omitempty"`
+	WindowsNodePools                        []map[string]string          `yaml:"windows_node_pools,omitempty"`
+	NodePoolsLabels                         map[string]map[string]string `yaml:"node_pools_labels,omitempty"`
+	NodePoolsResourceLabels                 map[string]map[string]string `yaml:"node_pools_resource_labels,omitempty"`
+	NodePoolsMetadata                       map[string]map[string]string `yaml:"node_pools_metadata,omitempty"`
+	NodePoolsLinuxNodeConfigsSysctls        map[string]map[string]string `yaml:"node_pools_linux_node_configs_sysctls,omitempty"`
+	EnableCostAllocation                    *bool                        `yaml:"enable_cost_allocation,omitempty"`
+	ResourceUsageExportDatasetID            string                       `yaml:"resource_usage_export_dataset_id,omitempty"`
+	EnableNetworkEgressExport               *bool                        `yaml:"enable_network_egress_export,omitempty"`
+	EnableResourceConsumptionExport         *bool                        `yaml:"enable_resource_consumption_export,omitempty"`
+	ClusterAutoscaling                      map[string]any               `yaml:"cluster_autoscaling,omitempty"`
+	NodePoolsTaints                         map[string][]map[string]any  `yaml:"node_pools_taints,omitempty"`
+	NodePoolsTags                           map[string][]string          `yaml:"node_pools_tags,omitempty"`
+	NodePoolsOAuthScopes                    map[string][]string          `yaml:"node_pools_oauth_scopes,omitempty"`
+	NetworkTags                             []string                     `yaml:"network_tags,omitempty"`
+	StubDomains                             map[string][]string          `yaml:"stub_domains,omitempty"`
+	UpstreamNameservers                     []string                     `yaml:"upstream_nameservers,omitempty"`
+	NonMasqueradeCIDRs                      []string                     `yaml:"non_masquerade_cidrs,omitempty"`
+	IPMasqResyncInterval                    string                       `yaml:"ip_masq_resync_interval,omitempty"`
+	IPMasqLinkLocal                         *bool                        `yaml:"ip_masq_link_local,omitempty"`
+	ConfigureIPMasq                         *bool                        `yaml:"configure_ip_masq,omitempty"`
+	LoggingService                          string                       `yaml:"logging_service,omitempty"`
+	MonitoringService                       string                       `yaml:"monitoring_service,omitempty"`
+	CreateServiceAccount                    *bool                        `yaml:"create_service_account,omitempty"`
+	GrantRegistryAccess                     *bool                        `yaml:"grant_registry_access,omitempty"`
+	RegistryProjectIDs                      []string                     `yaml:"registry_project_ids,omitempty"`
+	ServiceAccount                          string                       `yaml:"service_account,omitempty"`
+	ServiceAccountName                      string                       `yaml:"service_account_name,omitempty"`
+	BootDiskKMSKey                          string                       `yaml:"boot_disk_kms_key,omitempty"`
+	IssueClientCertificate                  *bool                        `yaml:"issue_client_certificate,omitempty"`
+	ClusterIPV4CIDR                         string                       `yaml:"cluster_ipv4_cidr,omitempty"`
+	DNSCache                                *bool                        `yaml:"dns_cache,omitempty"`
+	AuthenticatorSecurityGroup              string                       `yaml:"authenticator_security_group,omitempty"`
+	IdentityNamespace                       string                       `yaml:"identity_namespace,omitempty"`
+	EnableMeshCertificates                  *bool                        `yaml:"enable_mesh_certificates,omitempty"`
+	GatewayAPIChannel                       string                       `yaml:"gateway_api_channel,omitempty"`
+	AddClusterFirewallRules                 *bool                        `yaml:"add_cluster_firewall_rules,omitempty"`
+	AddMasterWebhookFirewallRules           *bool                        `yaml:"add_master_webhook_firewall_rules,omitempty"`
+	FirewallPriority                        *int                         `yaml:"firewall_priority,omitempty"`
+	FirewallInboundPorts                    []string                     `yaml:"firewall_inbound_ports,omitempty"`
+	AddShadowFirewallRules                  *bool                        `yaml:"add_shadow_firewall_rules,omitempty"`
+	ShadowFirewallRulesPriority             *int                         `yaml:"shadow_firewall_rules_priority,omitempty"`
+	ShadowFirewallRulesLogConfig            map[string]any               `yaml:"shadow_firewall_rules_log_config,omitempty"`
+	EnableConfidentialNodes                 *bool                        `yaml:"enable_confidential_nodes,omitempty"`
+	EnableCiliumClusterwideNetworkPolicy    *bool                        `yaml:"enable_cilium_clusterwide_network_policy,omitempty"`
+	SecurityPostureMode                     string                       `yaml:"security_posture_mode,omitempty"`
+	SecurityPostureVulnerabilityMode        string                       `yaml:"security_posture_vulnerability_mode,omitempty"`
+	DisableDefaultSNAT                      *bool                        `yaml:"disable_default_snat,omitempty"`
+	NotificationConfigTopic                 string                       `yaml:"notification_config_topic,omitempty"`
+	NotificationFilterEventType             []string                     `yaml:"notification_filter_event_type,omitempty"`
+	EnableTPU                               *bool                        `yaml:"enable_tpu,omitempty"`
+	NetworkPolicy                           *bool                        `yaml:"network_policy,omitempty"`
+	NetworkPolicyProvider                   string                       `yaml:"network_policy_provider,omitempty"`
+	InitialNodeCount                        *int                         `yaml:"initial_node_count,omitempty"`
+	FilestoreCSIDriver                      *bool                        `yaml:"filestore_csi_driver,omitempty"`
+	DisableLegacyMetadataEndpoints          *bool                        `yaml:"disable_legacy_metadata_endpoints,omitempty"`
+	DefaultMaxPodsPerNode                   *int                         `yaml:"default_max_pods_per_node,omitempty"`
+	DatabaseEncryption                      []map[string]any             `yaml:"database_encryption,omitempty"`
+	EnableShieldedNodes                     *bool                        `yaml:"enable_shielded_nodes,omitempty"`
+	EnableBinaryAuthorization               *bool                        `yaml:"enable_binary_authorization,omitempty"`
+	NodeMetadata                            string                       `yaml:"node_metadata,omitempty"`
+	ClusterDNSProvider                      string                       `yaml:"cluster_dns_provider,omitempty"`
+	ClusterDNSScope                         string                       `yaml:"cluster_dns_scope,omitempty"`
+	ClusterDNSDomain                        string                       `yaml:"cluster_dns_domain,omitempty"`
+	GCEPDCSIDriver                          *bool                        `yaml:"gce_pd_csi_driver,omitempty"`
+	GKEBackupAgentConfig                    *bool                        `yaml:"gke_backup_agent_config,omitempty"`
+	GCSFuseCSIDriver                        *bool                        `yaml:"gcs_fuse_csi_driver,omitempty"`
+	StatefulHA                              *bool                        `yaml:"stateful_ha,omitempty"`
+	Timeouts                                map[string]string            `yaml:"timeouts,omitempty"`
+	MonitoringEnableManagedPrometheus       *bool                        `yaml:"monitoring_enable_managed_prometheus,omitempty"`
+	MonitoringEnableObservabilityMetrics    *bool                        `yaml:"monitoring_enable_observability_metrics,omitempty"`
+	MonitoringObservabilityMetricsRelayMode string                       `yaml:"monitoring_observability_metrics_relay_mode,omitempty"`
+	MonitoringEnabledComponents             []string                     `yaml:"monitoring_enabled_components,omitempty"`
+	LoggingEnabledComponents                []string                     `yaml:"logging_enabled_components,omitempty"`
+	EnableKubernetesAlpha                   *bool                        `yaml:"enable_kubernetes_alpha,omitempty"`
+	ConfigConnector                         *bool                        `yaml:"config_connector,omitempty"`
+	EnableIntranodeVisibility               *bool                        `yaml:"enable_intranode_visibility,omitempty"`
+	EnableL4ILBSubsetting                   *bool                        `yaml:"enable_l4_ilb_subsetting,omitempty"`
+	FleetProject                            string                       `yaml:"fleet_project,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *GKEConfig) Resources() []Resource {
+	return []Resource{{Kind: "cluster", Name: c.Name, Value: c}}
+}
+
+// VectorSearchStruct is the schema of a 04-producer/VectorSearch YAML file.
+type VectorSearchStruct struct {
+	ProjectID                   string            `yaml:"project_id" doc:"Project the index is created in."`
+	Region                      string            `yaml:"region" doc:"Region of the index, e.g. us-central1."`
+	IndexDisplayName            string            `yaml:"index_display_name" doc:"Display name of the index."`
+	IndexDescription            string            `yaml:"index_description,omitempty"`
+	IndexLabels                 map[string]string `yaml:"index_labels,omitempty"`
+	Dimension                   int               `yaml:"dimension,omitempty" doc:"Number of dimensions of the input vectors."`
+	ApproximateNeighborsCount   int               `yaml:"approximate_neighbors_count,omitempty"`
+	ShardSize                   string            `yaml:"shard_size,omitempty" enum:"SHARD_SIZE_SMALL,SHARD_SIZE_MEDIUM,SHARD_SIZE_LARGE"`
+	DistanceMeasureType         string            `yaml:"distance_measure_type,omitempty" enum:"SQUARED_L2_DISTANCE,L1_DISTANCE,COSINE_DISTANCE,DOT_PRODUCT_DISTANCE"`
+	IndexUpdateMethod           string            `yaml:"index_update_method,omitempty" enum:"BATCH_UPDATE,STREAM_UPDATE"`
+	TreeAHConfig                map[string]any    `yaml:"tree_ah_config,omitempty"`
+	BruteForceConfig            map[string]any    `yaml:"brute_force_config,omitempty"`
+	IndexEndpointDisplayName    string            `yaml:"index_endpoint_display_name" doc:"Display name of the index endpoint."`
+	IndexEndpointDescription    string            `yaml:"index_endpoint_description,omitempty"`
+	IndexEndpointNetwork        string            `yaml:"index_endpoint_network,omitempty"`
+	IndexEndpointLabels         map[string]string `yaml:"index_endpoint_labels,omitempty"`
+	PublicEndpointEnabled       *bool             `yaml:"public_endpoint_enabled,omitempty"`
+	PrivateServiceConnectConfig map[string]any    `yaml:"private_service_connect_config,omitempty"`
+	DeployedIndexID             string            `yaml:"deployed_index_id,omitempty" doc:"ID of the deployed index."`
+	DeployedDisplayName         string            `yaml:"deployed_display_name,omitempty"`
+	EnableAccessLogging         *bool             `yaml:"enable_access_logging,omitempty"`
+	DeploymentGroup             string            `yaml:"deployment_group,omitempty"`
+	DeployedIndexAuthConfig     map[string]any    `yaml:"deployed_index_auth_config,omitempty"`
+	ReservedIPRanges            []string          `yaml:"reserved_ip_ranges,omitempty"`
+	DedicatedResources          map[string]any    `yaml:"dedicated_resources,omitempty"`
+	AutomaticResources          map[string]any    `yaml:"automatic_resources,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *VectorSearchStruct) Resources() []Resource {
+	return []Resource{{Kind: "index", Name: c.IndexDisplayName, Value: c}}
+}
+
+// EndpointConfig is the schema of a 04-producer/Vertex-AI-Online-Endpoints YAML file.
+type EndpointConfig struct {
+	Name                        string            `yaml:"name"`
+	Project                     string            `yaml:"project" doc:"Project the endpoint is created in."`
+	DisplayName                 string            `yaml:"display_name"`
+	Description                 string            `yaml:"description,omitempty"`
+	Location                    string            `yaml:"location"`
+	Region                      string            `yaml:"region,omitempty"`
+	Network                     string            `yaml:"network,omitempty" doc:"VPC network the endpoint is peered with."`
+	Labels                      map[string]string `yaml:"labels,omitempty"`
+	PrivateServiceConnectConfig map[string]any    `yaml:"private_service_connect_config,omitempty" doc:"Private Service Connect configuration of the endpoint."`
+}
+
+// Resources implements ResourceLister.
+func (c *EndpointConfig) Resources() []Resource {
+	return []Resource{{Kind: "endpoint", Name: c.Name, Value: c}}
+}
+
+// ProducerCloudSQLRef references the Cloud SQL instance a PSC endpoint targets.
+type ProducerCloudSQLRef struct {
+	InstanceName string `yaml:"instance_name,omitempty"`
+}
+
+// ProducerAlloyDBRef references the AlloyDB instance a PSC endpoint targets.
+type ProducerAlloyDBRef struct {
+	InstanceName string `yaml:"instance_name,omitempty"`
+	ClusterID    string `yaml:"cluster_id,omitempty"`
+}
+
+// PSCEndpointConfig is an entry of the 05-producer-connectivity psc_endpoints
+// variable. Exactly one of ProducerCloudSQL, ProducerAlloyDB and Target is set.
+type PSCEndpointConfig struct {
+	EndpointProjectID         string               `yaml:"endpoint_project_id"`
+	ProducerInstanceProjectID string               `yaml:"producer_instance_project_id"`
+	SubnetworkName            string               `yaml:"subnetwork_name"`
+	NetworkName               string               `yaml:"network_name"`
+	Region                    string               `yaml:"region,omitempty"`
+	IPAddressLiteral          string               `yaml:"ip_address_literal,omitempty"`
+	AllowPSCGlobalAccess      *bool                `yaml:"allow_psc_global_access,omitempty"`
+	Labels                    map[string]string    `yaml:"labels,omitempty"`
+	ProducerCloudSQL          *ProducerCloudSQLRef `yaml:"producer_cloudsql,omitempty"`
+	ProducerAlloyDB           *ProducerAlloyDBRef  `yaml:"producer_alloydb,omitempty"`
+	Target                    string               `yaml:"target,omitempty"`
+}
+
+// Producer returns the name of the producer the endpoint connects to.
+func (e *PSCEndpointConfig) Producer() string {
+	switch {
+	case e.ProducerCloudSQL != nil && e.ProducerCloudSQL.InstanceName != "":
+		return e.ProducerCloudSQL.InstanceName
+	case e.ProducerAlloyDB != nil && e.ProducerAlloyDB.InstanceName != "":
+		return e.ProducerAlloyDB.ClusterID + "/" + e.ProducerAlloyDB.InstanceName
+	}
+	return e.Target
+}
+
+// ProducerConnectivityVars is the schema of producer-connectivity.tfvars, the
+// input of the 05-producer-connectivity stage.
+type ProducerConnectivityVars struct {
+	PSCEndpoints []PSCEndpointConfig `yaml:"psc_endpoints"`
+}
+
+// Resources implements ResourceLister. Endpoints are named after their
+// network and the producer they connect to.
+func (v *ProducerConnectivityVars) Resources() []Resource {
+	var resources []Resource
+	for i := range v.PSCEndpoints {
+		e := &v.PSCEndpoints[i]
+		resources = append(resources, Resource{Kind: "psc_endpoint", Name: e.NetworkName + "/" + e.Producer(), Value: e})
+	}
+	return resources
+}
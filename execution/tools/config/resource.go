@@ -0,0 +1,43 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// Resource is a single named object declared by a stage configuration, such
+// as a Cloud SQL instance, a DNS zone or an ingress firewall rule.
+type Resource struct {
+	// Kind groups resources of the same shape, e.g. "instance" or "ingress_rule".
+	Kind string
+	// Name identifies the resource within its stage and kind.
+	Name string
+	// Value is the typed schema value the resource was decoded into.
+	Value any
+	// File is the configuration file declaring the resource, relative to the
+	// configuration root. It is set by StageConfig.Resources.
+	File string
+}
+
+// ResourceLister is implemented by typed schemas that declare named resources.
+type ResourceLister interface {
+	Resources() []Resource
+}
+
+// ResourcesOf returns the named resources declared by a decoded value. Values
+// without a typed schema are reported as a single resource of kind "config".
+func ResourcesOf(v any, fallbackName string) []Resource {
+	if l, ok := v.(ResourceLister); ok {
+		return l.Resources()
+	}
+	return []Resource{{Kind: "config", Name: fallbackName, Value: v}}
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FirewallRuleProtocol is a protocol and port list allowed or denied by a
+// firewall rule.
+type FirewallRuleProtocol struct {
+	Protocol string   `yaml:"protocol"`
+	Ports    []string `yaml:"ports,omitempty"`
+}
+
+// FirewallRuleConfig is an ingress or egress rule of the 03-security stages.
+type FirewallRuleConfig struct {
+	Deny               bool                   `yaml:"deny,omitempty"`
+	Description        string                 `yaml:"description,omitempty"`
+	DestinationRanges  []string               `yaml:"destination_ranges,omitempty"`
+	Disabled           bool                   `yaml:"disabled,omitempty"`
+	EnableLogging      map[string]any         `yaml:"enable_logging,omitempty"`
+	Priority           *int                   `yaml:"priority,omitempty"`
+	SourceRanges       []string               `yaml:"source_ranges,omitempty"`
+	Sources            []string               `yaml:"sources,omitempty"`
+	Targets            []string               `yaml:"targets,omitempty"`
+	UseServiceAccounts bool                   `yaml:"use_service_accounts,omitempty"`
+	Rules              []FirewallRuleProtocol `yaml:"rules,omitempty"`
+}
+
+// legacyFirewallRuleConfig is the list entry shape still found in some
+// 03-security tfvars (name, allow/deny blocks and target_tags).
+type legacyFirewallRuleConfig struct {
+	Name         string                 `yaml:"name"`
+	Description  string                 `yaml:"description,omitempty"`
+	Priority     *int                   `yaml:"priority,omitempty"`
+	SourceRanges []string               `yaml:"source_ranges,omitempty"`
+	TargetTags   []string               `yaml:"target_tags,omitempty"`
+	Allow        []FirewallRuleProtocol `yaml:"allow,omitempty"`
+	Deny         []FirewallRuleProtocol `yaml:"deny,omitempty"`
+}
+
+// FirewallRules maps rule names to rules. It also accepts the legacy list
+// form, where every entry carries its own name.
+type FirewallRules map[string]FirewallRuleConfig
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (r *FirewallRules) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		var m map[string]FirewallRuleConfig
+		if err := node.Decode(&m); err != nil {
+			return err
+		}
+		*r = m
+		return nil
+	}
+	var list []legacyFirewallRuleConfig
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*r = FirewallRules{}
+	for i, l := range list {
+		name := l.Name
+		if name == "" {
+			name = fmt.Sprint(i)
+		}
+		rule := FirewallRuleConfig{
+			Description:  l.Description,
+			Priority:     l.Priority,
+			SourceRanges: l.SourceRanges,
+			Targets:      l.TargetTags,
+			Rules:        l.Allow,
+		}
+		if len(l.Deny) > 0 {
+			rule.Deny = true
+			rule.Rules = l.Deny
+		}
+		(*r)[name] = rule
+	}
+	return nil
+}
+
+// FirewallRulesVars is the schema of the 03-security firewall tfvars
+// (alloydb, mrc, cloudsql, gce, mig and workbench).
+type FirewallRulesVars struct {
+	ProjectID    string        `yaml:"project_id"`
+	Network      string        `yaml:"network"`
+	IngressRules FirewallRules `yaml:"ingress_rules,omitempty"`
+	EgressRules  FirewallRules `yaml:"egress_rules,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (v *FirewallRulesVars) Resources() []Resource {
+	var resources []Resource
+	for _, name := range sortedKeys(v.IngressRules) {
+		rule := v.IngressRules[name]
+		resources = append(resources, Resource{Kind: "ingress_rule", Name: name, Value: &rule})
+	}
+	for _, name := range sortedKeys(v.EgressRules) {
+		rule := v.EgressRules[name]
+		resources = append(resources, Resource{Kind: "egress_rule", Name: name, Value: &rule})
+	}
+	return resources
+}
+
+// Layer4Config is a protocol and port list matched by a firewall policy rule.
+type Layer4Config struct {
+	Protocol string   `yaml:"protocol"`
+	Ports    []string `yaml:"ports,omitempty" type:"string,integer"`
+}
+
+// FirewallPolicyMatch is the match block of a firewall policy rule.
+type FirewallPolicyMatch struct {
+	SourceRanges        []string       `yaml:"source_ranges,omitempty"`
+	DestinationRanges   []string       `yaml:"destination_ranges,omitempty"`
+	SourceTags          []string       `yaml:"source_tags,omitempty"`
+	AddressGroups       []string       `yaml:"address_groups,omitempty"`
+	FQDNs               []string       `yaml:"fqdns,omitempty"`
+	RegionCodes         []string       `yaml:"region_codes,omitempty"`
+	ThreatIntelligences []string       `yaml:"threat_intelligences,omitempty"`
+	Layer4Configs       []Layer4Config `yaml:"layer4_configs,omitempty"`
+}
+
+// FirewallPolicyRule is a rule of a firewall policy. In YAML a rule is written
+// as a list entry whose first key is the rule name with a null value.
+type FirewallPolicyRule struct {
+	Name                 string              `yaml:"-"`
+	Action               string              `yaml:"action,omitempty" enum:"allow,deny,goto_next,apply_security_profile_group"`
+	Priority             *int                `yaml:"priority,omitempty"`
+	Description          string              `yaml:"description,omitempty"`
+	Disabled             bool                `yaml:"disabled,omitempty"`
+	EnableLogging        bool                `yaml:"enable_logging,omitempty"`
+	TargetTags           []string            `yaml:"target_tags,omitempty"`
+	TargetAccounts       []string            `yaml:"target_service_accounts,omitempty"`
+	TargetResources      []string            `yaml:"target_resources,omitempty" doc:"VPC networks the rule applies to."`
+	SecurityProfileGroup string              `yaml:"security_profile_group,omitempty" doc:"Security profile group of an apply_security_profile_group rule."`
+	TLSInspect           *bool               `yaml:"tls_inspect,omitempty"`
+	Match                FirewallPolicyMatch `yaml:"match"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (r *FirewallPolicyRule) UnmarshalYAML(node *yaml.Node) error {
+	type plain FirewallPolicyRule
+	var p plain
+	if err := node.Decode(&p); err != nil {
+		return err
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if v := node.Content[i+1]; v.Tag == "!!null" {
+				p.Name = node.Content[i].Value
+				break
+			}
+		}
+	}
+	*r = FirewallPolicyRule(p)
+	return nil
+}
+
+// MarshalYAML implements yaml.Marshaler so that the rule name survives a
+// round trip.
+func (r FirewallPolicyRule) MarshalYAML() (any, error) {
+	type plain FirewallPolicyRule
+	var node yaml.Node
+	if err := node.Encode(plain(r)); err != nil {
+		return nil, err
+	}
+	if r.Name != "" {
+		node.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: r.Name},
+			{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"},
+		}, node.Content...)
+	}
+	return &node, nil
+}
+
+// FirewallPolicyStruct is the schema of a 03-security/Firewall/FirewallPolicy
+// YAML file.
+type FirewallPolicyStruct struct {
+	Name         string               `yaml:"name" doc:"Name of the firewall policy."`
+	ParentID     string               `yaml:"parent_id" doc:"Parent of the policy: the project ID for global and regional policies."`
+	Description  string               `yaml:"description,omitempty"`
+	Region       string               `yaml:"region,omitempty" doc:"Region of a regional policy, or global."`
+	Attachments  map[string]string    `yaml:"attachments,omitempty"`
+	IngressRules []FirewallPolicyRule `yaml:"ingress_rules,omitempty"`
+	EgressRules  []FirewallPolicyRule `yaml:"egress_rules,omitempty"`
+}
+
+// Resources implements ResourceLister.
+func (c *FirewallPolicyStruct) Resources() []Resource {
+	policy := *c
+	policy.IngressRules, policy.EgressRules = nil, nil
+	resources := []Resource{{Kind: "firewall_policy", Name: c.Name, Value: &policy}}
+	for i := range c.IngressRules {
+		r := &c.IngressRules[i]
+		resources = append(resources, Resource{Kind: "ingress_rule", Name: c.Name + "/" + policyRuleName(r, i), Value: r})
+	}
+	for i := range c.EgressRules {
+		r := &c.EgressRules[i]
+		resources = append(resources, Resource{Kind: "egress_rule", Name: c.Name + "/" + policyRuleName(r, i), Value: r})
+	}
+	return resources
+}
+
+func policyRuleName(r *FirewallPolicyRule, index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprint(index)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config holds the typed schemas of the stage configuration files
+// (tfvars and YAML) and loads a configuration tree through them.
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stage describes where a stage keeps its configuration. Paths are relative
+// to the configuration root (the configuration/ directory of this repository).
+type Stage struct {
+	// Name is the friendly stage name accepted by run.sh -s.
+	Name string
+	// Path is the stage directory relative to execution/.
+	Path string
+	// Tfvars is the stage tfvars file.
+	Tfvars string
+	// ConfigDir is the folder holding the stage YAML files, empty when the
+	// stage is configured through tfvars only.
+	ConfigDir string
+
+	newVars     func() any
+	newDocument func() any
+}
+
+// NewVars returns a pointer to the typed tfvars of the stage, or a generic map
+// when the stage has no typed tfvars schema.
+func (s Stage) NewVars() any {
+	if s.newVars == nil {
+		return &map[string]any{}
+	}
+	return s.newVars()
+}
+
+// NewDocument returns a pointer to the typed schema of a stage YAML file, or
+// a generic map when the stage has no typed YAML schema.
+func (s Stage) NewDocument() any {
+	if s.newDocument == nil {
+		return &map[string]any{}
+	}
+	return s.newDocument()
+}
+
+// Typed reports whether YAML documents of the stage decode into a typed schema.
+func (s Stage) Typed() bool {
+	return s.newDocument != nil
+}
+
+func newOf[T any]() func() any {
+	return func() any { return new(T) }
+}
+
+// Stages lists every stage in run.sh execution order.
+var Stages = []Stage{
+	{Name: "organization", Path: "01-organization", Tfvars: "organization.tfvars"},
+	{Name: "networking", Path: "02-networking", Tfvars: "networking.tfvars", newVars: newOf[NetworkingVars]()},
+	{Name: "networking/ncc", Path: "02-networking/NCC", Tfvars: "networking/ncc/ncc.tfvars", ConfigDir: "networking/ncc/config", newDocument: newOf[NCCConfig]()},
+	{Name: "networking/firewallendpoint", Path: "02-networking/FirewallEndpoint", Tfvars: "networking/FirewallEndpoint/firewallendpoint.tfvars", ConfigDir: "networking/FirewallEndpoint/config"},
+	{Name: "networking/CloudDNS/DNSManagedZones", Path: "02-networking/CloudDNS/DNSManagedZones", Tfvars: "networking/CloudDNS/dns.tfvars", ConfigDir: "networking/CloudDNS/DNSManagedZones/config", newDocument: newOf[DNSConfig]()},
+	{Name: "networking/CloudDNS/CloudDNSResponsePolicy", Path: "02-networking/CloudDNS/CloudDNSResponsePolicy", Tfvars: "networking/CloudDNS/responsepolicy.tfvars", ConfigDir: "networking/CloudDNS/CloudDNSResponsePolicy/config", newDocument: newOf[ResponsePoliciesConfig]()},
+	{Name: "security/firewall/firewallpolicy", Path: "03-security/Firewall/FirewallPolicy", Tfvars: "security/Firewall/FirewallPolicy/firewallpolicy.tfvars", ConfigDir: "security/Firewall/FirewallPolicy/config", newDocument: newOf[FirewallPolicyStruct]()},
+	{Name: "security/securityprofile", Path: "03-security/SecurityProfile", Tfvars: "security/SecurityProfile/securityprofile.tfvars", ConfigDir: "security/SecurityProfile/config"},
+	{Name: "security/certificates/compute-ssl-certs/google-managed", Path: "03-security/Certificates/Compute-SSL-Certs/Google-Managed", Tfvars: "security/Certificates/Compute-SSL-Certs/Google-Managed/google_managed_ssl.tfvars"},
+	{Name: "security/alloydb", Path: "03-security/AlloyDB", Tfvars: "security/alloydb.tfvars", newVars: newOf[FirewallRulesVars]()},
+	{Name: "security/mrc", Path: "03-security/MRC", Tfvars: "security/mrc.tfvars", newVars: newOf[FirewallRulesVars]()},
+	{Name: "security/cloudsql", Path: "03-security/CloudSQL", Tfvars: "security/cloudsql.tfvars", newVars: newOf[FirewallRulesVars]()},
+	{Name: "security/gce", Path: "03-security/GCE", Tfvars: "security/gce.tfvars", newVars: newOf[FirewallRulesVars]()},
+	{Name: "security/mig", Path: "03-security/MIG", Tfvars: "security/mig.tfvars", newVars: newOf[FirewallRulesVars]()},
+	{Name: "security/workbench", Path: "03-security/Workbench", Tfvars: "security/workbench.tfvars", newVars: newOf[FirewallRulesVars]()},
+	{Name: "producer/alloydb", Path: "04-producer/AlloyDB", Tfvars: "producer/AlloyDB/alloydb.tfvars", ConfigDir: "producer/AlloyDB/config", newDocument: newOf[AlloyDBStruct]()},
+	{Name: "producer/mrc", Path: "04-producer/MRC", Tfvars: "producer/MRC/mrc.tfvars", ConfigDir: "producer/MRC/config", newDocument: newOf[MRCStruct]()},
+	{Name: "producer/cloudsql", Path: "04-producer/CloudSQL", Tfvars: "producer/CloudSQL/cloudsql.tfvars", ConfigDir: "producer/CloudSQL/config", newDocument: newOf[CloudSQLStruct]()},
+	{Name: "producer/gke", Path: "04-producer/GKE", Tfvars: "producer/GKE/gke.tfvars", ConfigDir: "producer/GKE/config", newDocument: newOf[GKEConfig]()},
+	{Name: "producer/vectorsearch", Path: "04-producer/VectorSearch", Tfvars: "producer/VectorSearch/vectorsearch.tfvars", ConfigDir: "producer/VectorSearch/config", newDocument: newOf[VectorSearchStruct]()},
+	{Name: "producer/onlineendpoint", Path: "04-producer/Vertex-AI-Online-Endpoints", Tfvars: "producer/Vertex-AI-Online-Endpoints/vertex-ai-online-endpoints.tfvars", ConfigDir: "producer/Vertex-AI-Online-Endpoints/config", newDocument: newOf[EndpointConfig]()},
+	{Name: "producer/bigquery", Path: "04-producer/BigQuery", Tfvars: "producer/BigQuery/bigquery.tfvars", ConfigDir: "producer/BigQuery/config"},
+	{Name: "producer-connectivity", Path: "05-producer-connectivity", Tfvars: "producer-connectivity.tfvars", newVars: newOf[ProducerConnectivityVars]()},
+	{Name: "consumer/gce", Path: "06-consumer/GCE", Tfvars: "consumer/GCE/gce.tfvars", ConfigDir: "consumer/GCE/config", newDocument: newOf[VMInstanceConfig]()},
+	{Name: "consumer/serverless/cloudrun/job", Path: "06-consumer/Serverless/CloudRun/Job", Tfvars: "consumer/Serverless/CloudRun/Job/cloudrunjob.tfvars", ConfigDir: "consumer/Serverless/CloudRun/Job/config", newDocument: newOf[CloudRunStruct]()},
+	{Name: "consumer/serverless/cloudrun/service", Path: "06-consumer/Serverless/CloudRun/Service", Tfvars: "consumer/Serverless/CloudRun/Service/cloudrunservice.tfvars", ConfigDir: "consumer/Serverless/CloudRun/Service/config", newDocument: newOf[CloudRunStruct]()},
+	{Name: "consumer/serverless/appengine/standard", Path: "06-consumer/Serverless/AppEngine/Standard", Tfvars: "consumer/Serverless/AppEngine/Standard/standardappengine.tfvars", ConfigDir: "consumer/Serverless/AppEngine/Standard/config"},
+	{Name: "consumer/serverless/appengine/flexible", Path: "06-consumer/Serverless/AppEngine/Flexible", Tfvars: "consumer/Serverless/AppEngine/Flexible/flexibleappengine.tfvars", ConfigDir: "consumer/Serverless/AppEngine/Flexible/config"},
+	{Name: "consumer/serverless/vpcaccessconnector", Path: "06-consumer/Serverless/VPCAccessConnector", Tfvars: "consumer/Serverless/VPCAccessConnector/vpcaccessconnector.tfvars", ConfigDir: "consumer/Serverless/VPCAccessConnector/config", newDocument: newOf[VPCAccessConnectorConfig]()},
+	{Name: "consumer/mig", Path: "06-consumer/MIG", Tfvars: "consumer/MIG/mig.tfvars", ConfigDir: "consumer/MIG/config", newDocument: newOf[MIGConfig]()},
+	{Name: "consumer/workbench", Path: "06-consumer/Workbench", Tfvars: "consumer/Workbench/workbench.tfvars", ConfigDir: "consumer/Workbench/config", newDocument: newOf[WorkbenchConfig]()},
+	{Name: "consumer/umig", Path: "06-consumer/UMIG", Tfvars: "consumer/UMIG/umig.tfvars", ConfigDir: "consumer/UMIG/config", newDocument: newOf[UMIGConfig]()},
+	{Name: "load-balancing/application/external", Path: "07-consumer-load-balancing/Application/External", Tfvars: "consumer-load-balancing/Application/External/external-application-lb.tfvars", ConfigDir: "consumer-load-balancing/Application/External/config", newDocument: newOf[LoadBalancerConfig]()},
+	{Name: "load-balancing/network/passthrough/internal", Path: "07-consumer-load-balancing/Network/Passthrough/Internal", Tfvars: "consumer-load-balancing/Network/Passthrough/Internal/internal-network-passthrough.tfvars", ConfigDir: "consumer-load-balancing/Network/Passthrough/Internal/config", newDocument: newOf[InternalNetworkLoadBalancerConfig]()},
+	{Name: "load-balancing/network/passthrough/external", Path: "07-consumer-load-balancing/Network/Passthrough/External", Tfvars: "consumer-load-balancing/Network/Passthrough/External/external-network-passthrough.tfvars", ConfigDir: "consumer-load-balancing/Network/Passthrough/External/config", newDocument: newOf[NetworkLoadBalancerConfig]()},
+	{Name: "network-security-integration/outofband", Path: "08-network-security-integration/Out-Of-Band", Tfvars: "network-security-integration/OutOfBand/nsioutofband.tfvars", ConfigDir: "network-security-integration/OutOfBand/config"},
+	{Name: "network-security-integration/securityprofile", Path: "08-network-security-integration/SecurityProfile", Tfvars: "network-security-integration/SecurityProfile/securityprofile.tfvars", ConfigDir: "network-security-integration/SecurityProfile/config"},
+	{Name: "network-security-integration/packetmirroringrule", Path: "08-network-security-integration/PacketMirroringRule", Tfvars: "network-security-integration/PacketMirroringRule/packetmirroringrule.tfvars", ConfigDir: "network-security-integration/PacketMirroringRule/config"},
+}
+
+// LookupStage returns the stage with the given friendly name or execution
+// path. The lookup is case insensitive, like run.sh.
+func LookupStage(name string) (Stage, bool) {
+	for _, s := range Stages {
+		if strings.EqualFold(s.Name, name) || strings.EqualFold(s.Path, name) {
+			return s, true
+		}
+	}
+	return Stage{}, false
+}
+
+// SelectStages returns the stages named, by friendly name or execution path,
+// in a comma separated list, such as the -stage flag of the commands, or
+// every stage when list is empty.
+func SelectStages(list string) ([]Stage, error) {
+	if list == "" {
+		return Stages, nil
+	}
+	var out []Stage
+	for _, name := range strings.Split(list, ",") {
+		s, ok := LookupStage(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown stage %q", name)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
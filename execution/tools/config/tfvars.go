@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseTfvars evaluates the attributes of a tfvars file into plain Go values
+// (maps, slices, strings, int64, float64, bool and nil). Tfvars files may only
+// hold literal values, so no evaluation context is provided.
+func ParseTfvars(filename string, src []byte) (map[string]any, error) {
+	file, diags := hclparse.NewParser().ParseHCL(src, filename)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	vars := make(map[string]any, len(attrs))
+	for name, attr := range attrs {
+		v, d := attr.Expr.Value(nil)
+		diags = append(diags, d...)
+		if d.HasErrors() {
+			continue
+		}
+		vars[name] = goValue(v)
+	}
+	if diags.HasErrors() {
+		return vars, diags
+	}
+	return vars, nil
+}
+
+// DecodeTfvars parses a tfvars file into out, a pointer to a typed schema.
+func DecodeTfvars(filename string, src []byte, out any) error {
+	vars, err := ParseTfvars(filename, src)
+	if err != nil {
+		return err
+	}
+	return Convert(vars, out)
+}
+
+// Convert copies a plain Go value into out, a pointer to a typed schema,
+// honouring the yaml tags of the schema.
+func Convert(in, out any) error {
+	b, err := yaml.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(b, out)
+}
+
+// EncodeTfvars renders a typed schema as a tfvars file. Variables are written
+// in schema field order and empty values are left out.
+func EncodeTfvars(v any) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return nil, err
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("cannot encode %T as tfvars", v)
+	}
+	f := hclwrite.NewEmptyFile()
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var value any
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return nil, err
+		}
+		f.Body().SetAttributeValue(node.Content[i].Value, ctyValue(value))
+	}
+	return hclwrite.Format(f.Bytes()), nil
+}
+
+func ctyValue(v any) cty.Value {
+	switch v := v.(type) {
+	case string:
+		return cty.StringVal(v)
+	case bool:
+		return cty.BoolVal(v)
+	case int:
+		return cty.NumberIntVal(int64(v))
+	case int64:
+		return cty.NumberIntVal(v)
+	case uint64:
+		return cty.NumberUIntVal(v)
+	case float64:
+		return cty.NumberFloatVal(v)
+	case map[string]any:
+		if len(v) == 0 {
+			return cty.EmptyObjectVal
+		}
+		m := make(map[string]cty.Value, len(v))
+		for k, e := range v {
+			m[k] = ctyValue(e)
+		}
+		return cty.ObjectVal(m)
+	case []any:
+		if len(v) == 0 {
+			return cty.EmptyTupleVal
+		}
+		l := make([]cty.Value, len(v))
+		for i, e := range v {
+			l[i] = ctyValue(e)
+		}
+		return cty.TupleVal(l)
+	}
+	return cty.NullVal(cty.DynamicPseudoType)
+}
+
+func goValue(v cty.Value) any {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+	v, _ = v.Unmark()
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Bool:
+		return v.True()
+	case t == cty.Number:
+		f := v.AsBigFloat()
+		if f.IsInt() {
+			if i, acc := f.Int64(); acc == big.Exact {
+				return i
+			}
+		}
+		n, _ := f.Float64()
+		return n
+	case t.IsObjectType() || t.IsMapType():
+		m := make(map[string]any, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, e := it.Element()
+			m[k.AsString()] = goValue(e)
+		}
+		return m
+	case t.IsListType() || t.IsTupleType() || t.IsSetType():
+		l := make([]any, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			_, e := it.Element()
+			l = append(l, goValue(e))
+		}
+		return l
+	}
+	// Capsule types cannot be written as tfvars literals.
+	return nil
+}
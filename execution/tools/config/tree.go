@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a decoded stage YAML file.
+type Document struct {
+	// File is the path of the document relative to the configuration root.
+	File string
+	// Value is a pointer to the typed schema of the stage, or to a generic map.
+	Value any
+}
+
+// StageConfig is the configuration of a single stage found in a tree.
+type StageConfig struct {
+	Stage Stage
+	// VarsFile is the tfvars file relative to the configuration root, empty
+	// when the tree has none for this stage.
+	VarsFile string
+	// Vars is a pointer to the typed tfvars of the stage, or to a generic map.
+	Vars      any
+	Documents []Document
+}
+
+// Resources returns the named resources declared by the stage tfvars and
+// YAML files, in file order.
+func (s *StageConfig) Resources() []Resource {
+	var resources []Resource
+	if s.Vars != nil {
+		for _, r := range ResourcesOf(s.Vars, path.Base(s.VarsFile)) {
+			r.File = s.VarsFile
+			resources = append(resources, r)
+		}
+	}
+	for _, d := range s.Documents {
+		for _, r := range ResourcesOf(d.Value, strings.TrimSuffix(path.Base(d.File), ".yaml")) {
+			r.File = d.File
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// Tree is a configuration tree, the configuration/ directory of this
+// repository or a copy of it.
+type Tree struct {
+	Root   string
+	Stages []*StageConfig
+}
+
+// Stage returns the configuration of the named stage, or nil when the tree
+// does not configure it.
+func (t *Tree) Stage(name string) *StageConfig {
+	for _, s := range t.Stages {
+		if strings.EqualFold(s.Stage.Name, name) {
+			return s
+		}
+	}
+	return nil
+}
+
+// LoadTree reads the tfvars and YAML files of every known stage under root.
+// Like the stages themselves it only reads YAML files whose name does not
+// start with an underscore; .example and .sample files are ignored. Files
+// that fail to decode are reported in the returned error, which joins one
+// error per file, while the rest of the tree is still returned.
+func LoadTree(root string) (*Tree, error) {
+	if _, err := os.Stat(root); err != nil {
+		return nil, err
+	}
+	tree := &Tree{Root: root}
+	var errs []error
+	for _, stage := range Stages {
+		sc := &StageConfig{Stage: stage}
+		if src, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(stage.Tfvars))); err == nil {
+			vars := stage.NewVars()
+			if err := DecodeTfvars(stage.Tfvars, src, vars); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", stage.Tfvars, err))
+			} else {
+				sc.VarsFile, sc.Vars = stage.Tfvars, vars
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		if stage.ConfigDir != "" {
+			files, err := ConfigFiles(filepath.Join(root, filepath.FromSlash(stage.ConfigDir)))
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for _, f := range files {
+				rel := path.Join(stage.ConfigDir, f)
+				doc, err := decodeDocument(stage, filepath.Join(root, filepath.FromSlash(rel)))
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", rel, err))
+					continue
+				}
+				sc.Documents = append(sc.Documents, Document{File: rel, Value: doc})
+			}
+		}
+		if sc.Vars != nil || len(sc.Documents) > 0 {
+			tree.Stages = append(tree.Stages, sc)
+		}
+	}
+	return tree, errors.Join(errs...)
+}
+
+// ConfigFiles lists the YAML files of a stage config folder the way the
+// stages do with fileset(config_folder_path, "[^_]*.yaml"), in name order.
+func ConfigFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if ok, _ := path.Match("[^_]*.yaml", e.Name()); ok && !e.IsDir() {
+			files = append(files, e.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func decodeDocument(stage Stage, file string) (any, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	doc := stage.NewDocument()
+	// Unknown keys, such as a misspelled machine_tpye, are errors rather
+	// than silently dropped.
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	dec.KnownFields(true)
+	if err := dec.Decode(doc); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return doc, nil
+}
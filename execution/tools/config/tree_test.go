@@ -0,0 +1,274 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadTree(t *testing.T) {
+	tree, err := LoadTree("testdata/tree")
+	if err != nil {
+		t.Fatalf("LoadTree() failed: %v", err)
+	}
+
+	networking := tree.Stage("networking")
+	if networking == nil {
+		t.Fatalf("Expected the networking stage to be loaded")
+	}
+	vars, ok := networking.Vars.(*NetworkingVars)
+	if !ok {
+		t.Fatalf("Expected *NetworkingVars, got %T", networking.Vars)
+	}
+	if len(vars.Subnets) != 1 || vars.Subnets[0].IPCIDRRange != "10.0.0.0/24" {
+		t.Errorf("Unexpected subnets: %+v", vars.Subnets)
+	}
+	if !vars.CreateNAT.Enabled() || vars.CreateHAVPN.Enabled() || vars.CreateInterconnect.Enabled() {
+		t.Errorf("Expected only create_nat to be enabled, got nat=%v havpn=%v interconnect=%v",
+			vars.CreateNAT.Enabled(), vars.CreateHAVPN.Enabled(), vars.CreateInterconnect.Enabled())
+	}
+	if vars.ICRouterBGPASN != "65004" {
+		t.Errorf("Expected ic_router_bgp_asn 65004, got %q", vars.ICRouterBGPASN)
+	}
+
+	ncc := tree.Stage("networking/ncc")
+	if ncc == nil || len(ncc.Documents) != 1 {
+		t.Fatalf("Expected exactly one NCC document, underscore prefixed files must be skipped")
+	}
+	if got := ncc.Documents[0].File; got != "networking/ncc/config/ncc.yaml" {
+		t.Errorf("Unexpected document path %q", got)
+	}
+	resources := ncc.Resources()
+	if len(resources) != 2 || resources[0].Kind != "hub" || resources[1].Name != "spoke-1" {
+		t.Errorf("Unexpected NCC resources: %+v", resources)
+	}
+
+	gce := tree.Stage("security/gce").Vars.(*FirewallRulesVars)
+	rule, ok := gce.IngressRules["allow-ssh"]
+	if !ok {
+		t.Fatalf("Expected the legacy list form to be keyed by rule name, got %+v", gce.IngressRules)
+	}
+	if len(rule.Targets) != 1 || rule.Rules[0].Ports[0] != "22" {
+		t.Errorf("Unexpected converted rule: %+v", rule)
+	}
+}
+
+func TestLoadTreeReportsBrokenFiles(t *testing.T) {
+	tree, err := LoadTree("testdata/broken")
+	if err == nil {
+		t.Fatalf("Expected an error for an invalid shard_count")
+	}
+	if !strings.Contains(err.Error(), filepath.ToSlash("producer/MRC/config/mrc.yaml")) {
+		t.Errorf("Expected the error to name the broken file, got: %v", err)
+	}
+	if tree == nil {
+		t.Errorf("Expected the rest of the tree to be returned alongside the error")
+	}
+}
+
+func TestLoadTreeRejectsUnknownKeys(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "consumer", "GCE", "config")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create the config folder: %v", err)
+	}
+	doc := "name: vm-1\nproject_id: host-project\nzone: us-central1-a\nmachine_tpye: e2-small\n"
+	if err := os.WriteFile(filepath.Join(dir, "instance.yaml"), []byte(doc), 0644); err != nil {
+		t.Fatalf("Failed to write the instance: %v", err)
+	}
+	_, err := LoadTree(root)
+	if err == nil || !strings.Contains(err.Error(), "field machine_tpye not found") {
+		t.Errorf("Expected the misspelled key to be rejected, got: %v", err)
+	}
+}
+
+func TestLoadTreeMissingRoot(t *testing.T) {
+	if _, err := LoadTree(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not exist error, got %v", err)
+	}
+}
+
+func TestStagesMatchRepository(t *testing.T) {
+	for _, s := range Stages {
+		if _, err := os.Stat(filepath.Join("../..", s.Path)); err != nil {
+			t.Errorf("Stage %s: execution path %s does not exist", s.Name, s.Path)
+		}
+		if _, err := os.Stat(filepath.Join("../../../configuration", s.Tfvars)); err != nil {
+			t.Errorf("Stage %s: tfvars %s does not exist", s.Name, s.Tfvars)
+		}
+	}
+}
+
+func TestSelectStages(t *testing.T) {
+	all, err := SelectStages("")
+	if err != nil || len(all) != len(Stages) {
+		t.Errorf("SelectStages(\"\") = %d stages, %v, want every stage", len(all), err)
+	}
+	got, err := SelectStages("producer/cloudsql, 02-networking")
+	if err != nil {
+		t.Fatalf("Failed to select stages: %v", err)
+	}
+	if len(got) != 2 || got[0].Path != "04-producer/CloudSQL" || got[1].Path != "02-networking" {
+		t.Errorf("SelectStages() = %+v, want CloudSQL and networking", got)
+	}
+	if _, err := SelectStages("networking,nope"); err == nil || err.Error() != `unknown stage "nope"` {
+		t.Errorf("SelectStages() with an unknown stage returned %v", err)
+	}
+}
+
+func TestLoadTreeAcceptsStageKeys(t *testing.T) {
+	tree, err := LoadTree("testdata/stagekeys")
+	if err != nil {
+		t.Fatalf("LoadTree() failed: %v", err)
+	}
+	for _, s := range Stages {
+		if !s.Typed() {
+			continue
+		}
+		sc := tree.Stage(s.Name)
+		if sc == nil || len(sc.Documents) != 1 {
+			t.Errorf("Stage %s: expected one document in testdata/stagekeys/%s", s.Name, s.ConfigDir)
+			continue
+		}
+		doc := sc.Documents[0]
+		typ := reflect.TypeOf(doc.Value)
+
+		raw, err := os.ReadFile(filepath.Join("testdata/stagekeys", doc.File))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", doc.File, err)
+		}
+		var in any
+		if err := yaml.Unmarshal(raw, &in); err != nil {
+			t.Fatalf("Failed to decode %s: %v", doc.File, err)
+		}
+		inKeys := map[string]bool{}
+		documentKeys(typ, in, "", inKeys)
+		for _, k := range schemaKeys(typ, "") {
+			if _, ok := inKeys[k]; !ok {
+				t.Errorf("Stage %s: %s does not set %s", s.Name, doc.File, k)
+			}
+		}
+
+		out, err := yaml.Marshal(doc.Value)
+		if err != nil {
+			t.Fatalf("Failed to encode %s: %v", doc.File, err)
+		}
+		var back any
+		if err := yaml.Unmarshal(out, &back); err != nil {
+			t.Fatalf("Failed to decode the encoded %s: %v", doc.File, err)
+		}
+		outKeys := map[string]bool{}
+		documentKeys(typ, back, "", outKeys)
+		for k, set := range inKeys {
+			if _, ok := outKeys[k]; set && !ok {
+				t.Errorf("Stage %s: %s loses %s", s.Name, doc.File, k)
+			}
+		}
+	}
+}
+
+// schemaKeys returns the key paths declared by the yaml tags of t, with []
+// for list items and * for the keys of maps of structs.
+func schemaKeys(t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		return schemaKeys(t.Elem(), prefix+"[]")
+	case reflect.Map:
+		if elem := derefType(t.Elem()); elem.Kind() == reflect.Struct || elem.Kind() == reflect.Slice {
+			return schemaKeys(t.Elem(), prefix+".*")
+		}
+	case reflect.Struct:
+		var keys []string
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			if name == "-" || name == "" {
+				continue
+			}
+			keys = append(keys, prefix+"."+name)
+			keys = append(keys, schemaKeys(t.Field(i).Type, prefix+"."+name)...)
+		}
+		return keys
+	}
+	return nil
+}
+
+// documentKeys adds the key paths of the decoded YAML v to keys, walking it
+// along t as schemaKeys does. Keys t does not declare are added too. A key
+// maps to false when its value is null or empty, which the stages read as
+// unset.
+func documentKeys(t reflect.Type, v any, prefix string, keys map[string]bool) {
+	t = derefType(t)
+	switch v := v.(type) {
+	case []any:
+		if t.Kind() == reflect.Slice {
+			for _, item := range v {
+				documentKeys(t.Elem(), item, prefix+"[]", keys)
+			}
+		}
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Map:
+			if elem := derefType(t.Elem()); elem.Kind() == reflect.Struct || elem.Kind() == reflect.Slice {
+				for _, item := range v {
+					documentKeys(t.Elem(), item, prefix+".*", keys)
+				}
+			}
+		case reflect.Struct:
+			for name, item := range v {
+				keys[prefix+"."+name] = !isEmpty(item)
+				if f, ok := yamlField(t, name); ok {
+					documentKeys(f.Type, item, prefix+"."+name, keys)
+				}
+			}
+		}
+	}
+}
+
+func yamlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if n, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); n == name {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
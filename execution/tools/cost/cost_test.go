@@ -0,0 +1,294 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+func loadPrices(t *testing.T, file string) *Prices {
+	t.Helper()
+	p, err := LoadPrices(file)
+	if err != nil {
+		t.Fatalf("Failed to load the prices: %v", err)
+	}
+	return p
+}
+
+func readPlan(t *testing.T) *tfplan.Plan {
+	t.Helper()
+	p, err := tfplan.ReadFile(filepath.Join("testdata", "plan.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the plan: %v", err)
+	}
+	return p
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestLoadPrices(t *testing.T) {
+	p := loadPrices(t, filepath.Join("testdata", "prices.yaml"))
+	if p.Version != "test" || p.Currency != "USD" || p.HoursPerMonth != 100 {
+		t.Errorf("Failed to load the prices: got version %q, currency %q, %g hours per month", p.Version, p.Currency, p.HoursPerMonth)
+	}
+	tests := []struct {
+		key, region string
+		want        float64
+		ok          bool
+	}{
+		{"cloudsql.vcpu_hour", "europe-west1", 2, true},
+		{"cloudsql.vcpu_hour", "us-central1", 1, true},
+		{"cloudsql.vcpu_hour", "", 1, true},
+		{"compute.c3.vcpu_hour", "us-central1", 0, false},
+	}
+	for _, tc := range tests {
+		got, ok := p.Price(tc.key, tc.region)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Price(%q, %q) = %g, %v, want %g, %v", tc.key, tc.region, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestLoadPricesErrors(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"no version", "currency: USD\nhours_per_month: 730\n", "version is required"},
+		{"no currency", "version: v1\nhours_per_month: 730\n", "currency is required"},
+		{"no hours", "version: v1\ncurrency: USD\n", "hours_per_month must be positive"},
+		{"negative", "version: v1\ncurrency: USD\nhours_per_month: 730\nprices:\n  vpn.tunnel_hour: {default: -1}\n", "vpn.tunnel_hour: negative price in default"},
+		{"unknown field", "version: v1\ncurrency: USD\nhours_per_month: 730\nregion: us-central1\n", "field region not found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "prices.yaml")
+			if err := os.WriteFile(file, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("Failed to write the prices: %v", err)
+			}
+			_, err := LoadPrices(file)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("LoadPrices() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	est := New(loadPrices(t, filepath.Join("testdata", "prices.yaml")))
+	est.Add("networking", readPlan(t), loadPrices(t, filepath.Join("testdata", "prices.yaml")))
+	tests := []struct {
+		resource string
+		region   string
+		monthly  float64
+		notes    []string
+	}{
+		{`module.cloudsql["sql-ha"].google_sql_database_instance.primary`, "europe-west1", 900, nil},
+		{`module.cloudsql["sql-micro"].google_sql_database_instance.primary`, "us-central1", 2, []string{"disk size unknown until apply, 10 GB assumed"}},
+		{"module.alloydb.google_alloydb_instance.primary", "us-central1", 1440, nil},
+		{"module.alloydb.google_alloydb_instance.read_pool", "us-central1", 1080, nil},
+		{"module.memorystore.google_redis_cluster.cluster", "us-central1", 300, nil},
+		{"module.memorystore.google_redis_cluster.pending", "us-central1", 0, []string{"shard_count unknown until apply, not priced"}},
+		{`module.vm["vm-1"].google_compute_instance.default`, "us-central1", 142, nil},
+		{`module.vm["vm-2"].google_compute_instance.default`, "us-central1", 3, []string{"boot disk size unknown until apply, 10 GB assumed"}},
+		{"module.mig.google_compute_region_instance_group_manager.default", "us-central1", 1140, []string{"autoscaled, priced for the 2 minimum replicas"}},
+		{"module.nat.google_compute_router_nat.nat", "us-central1", 10, []string{"data processing is billed on usage, not estimated"}},
+		{`module.vpn.google_compute_vpn_tunnel.tunnels["remote-0"]`, "us-central1", 10, nil},
+		{`module.vpn.google_compute_vpn_tunnel.tunnels["remote-1"]`, "us-central1", 10, nil},
+		{"module.partner.google_compute_interconnect_attachment.vlan", "us-central1", 100, nil},
+		{"module.dedicated.google_compute_interconnect_attachment.vlan", "us-central1", 200, nil},
+		{"module.psc.google_compute_forwarding_rule.endpoint", "us-central1", 20, nil},
+		{"module.lb.google_compute_forwarding_rule.internal", "us-central1", 50, nil},
+		{"module.lb.google_compute_forwarding_rule.internal_v6", "us-central1", 0, []string{"included in the charge of the first 5 forwarding rules of host/us-central1"}},
+		{"module.lb.google_compute_global_forwarding_rule.external", "", 50, nil},
+	}
+	if len(est.Stages) != 1 || est.Stages[0].Name != "networking" {
+		t.Fatalf("Failed to estimate the plan: got stages %+v", est.Stages)
+	}
+	lines := est.Stages[0].Lines
+	if len(lines) != len(tests) {
+		t.Fatalf("Failed to estimate the plan: got %d lines, want %d", len(lines), len(tests))
+	}
+	for i, tc := range tests {
+		l := lines[i]
+		if l.Resource != tc.resource || l.Region != tc.region || !near(l.Monthly, tc.monthly) || !reflect.DeepEqual(l.Notes, tc.notes) {
+			t.Errorf("line %d = %s in %q: %g, notes %q, want %s in %q: %g, notes %q", i, l.Resource, l.Region, l.Monthly, l.Notes, tc.resource, tc.region, tc.monthly, tc.notes)
+		}
+	}
+	if !near(est.Stages[0].Monthly, 5457) || !near(est.Monthly, 5457) {
+		t.Errorf("Failed to total the estimate: got stage %g, total %g, want 5457", est.Stages[0].Monthly, est.Monthly)
+	}
+}
+
+func TestEstimateItems(t *testing.T) {
+	est := New(loadPrices(t, filepath.Join("testdata", "prices.yaml")))
+	est.Add("networking", readPlan(t), loadPrices(t, filepath.Join("testdata", "prices.yaml")))
+	got := est.Stages[0].Lines[0].Items
+	want := []Item{
+		{Name: "db-custom-2-4096: 2 vCPU (HA)", Key: "cloudsql.vcpu_hour", Quantity: 400, Unit: "vCPU-hour", Monthly: 800},
+		{Name: "db-custom-2-4096: 4 GB memory (HA)", Key: "cloudsql.memory_gb_hour", Quantity: 800, Unit: "GB-hour", Monthly: 80},
+		{Name: "100 GB ssd disk (HA)", Key: "cloudsql.ssd_gb_month", Quantity: 200, Unit: "GB-month", Monthly: 20},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Failed to price the Cloud SQL instance: got items %+v", got)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Key != want[i].Key || !near(got[i].Quantity, want[i].Quantity) || got[i].Unit != want[i].Unit || !near(got[i].Monthly, want[i].Monthly) {
+			t.Errorf("item %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestForwardingRules(t *testing.T) {
+	plan := &tfplan.Plan{FormatVersion: "1.2"}
+	for i := range 7 {
+		plan.ResourceChanges = append(plan.ResourceChanges, tfplan.ResourceChange{
+			Address: fmt.Sprintf("google_compute_forwarding_rule.rule[%d]", i),
+			Mode:    "managed",
+			Type:    "google_compute_forwarding_rule",
+			Change:  tfplan.Change{Actions: []string{"create"}, After: map[string]any{"project": "host", "region": "us-east4"}},
+		})
+	}
+	prices := loadPrices(t, filepath.Join("testdata", "prices.yaml"))
+	est := New(prices)
+	est.Add("lb", plan, prices)
+	var got []float64
+	for _, l := range est.Stages[0].Lines {
+		got = append(got, math.Round(l.Monthly*100)/100)
+	}
+	want := []float64{50, 0, 0, 0, 0, 10, 10}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to price the forwarding rules: got %v, want %v", got, want)
+	}
+}
+
+func TestMissingPrice(t *testing.T) {
+	prices := loadPrices(t, filepath.Join("testdata", "prices.yaml"))
+	delete(prices.Prices, "vpn.tunnel_hour")
+	est := New(prices)
+	est.Add("networking", readPlan(t), prices)
+	for _, l := range est.Stages[0].Lines {
+		if l.Type != "google_compute_vpn_tunnel" {
+			continue
+		}
+		want := []string{"no price for vpn.tunnel_hour, VPN tunnel not priced"}
+		if l.Monthly != 0 || !reflect.DeepEqual(l.Notes, want) {
+			t.Errorf("%s = %g, notes %q, want 0, notes %q", l.Resource, l.Monthly, l.Notes, want)
+		}
+	}
+}
+
+// TestDefaultPrices checks that the default price table prices every item
+// of the test plan.
+func TestDefaultPrices(t *testing.T) {
+	prices := loadPrices(t, "prices.yaml")
+	est := New(prices)
+	est.Add("networking", readPlan(t), prices)
+	for _, l := range est.Stages[0].Lines {
+		for _, n := range l.Notes {
+			if strings.HasPrefix(n, "no price") {
+				t.Errorf("%s: %s", l.Resource, n)
+			}
+		}
+	}
+	if est.Monthly <= 0 {
+		t.Errorf("Failed to estimate the plan with the default prices: got %g", est.Monthly)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := &Estimate{Version: "v1", Currency: "USD", Monthly: 18, Stages: []Stage{
+		{Name: "a", Monthly: 15, Lines: []Line{{Resource: "x", Monthly: 10}, {Resource: "y", Monthly: 5}}},
+		{Name: "b", Monthly: 3, Lines: []Line{{Resource: "z", Monthly: 3}}},
+	}}
+	target := &Estimate{Version: "v1", Currency: "USD", Monthly: 22, Stages: []Stage{
+		{Name: "a", Monthly: 18, Lines: []Line{{Resource: "x", Monthly: 12}, {Resource: "y", Monthly: 5}, {Resource: "w", Monthly: 1}}},
+		{Name: "c", Monthly: 4, Lines: []Line{{Resource: "q", Monthly: 4}}},
+	}}
+	d := Compare(base, target)
+	if d.Before != 18 || d.After != 22 || d.Delta != 4 {
+		t.Errorf("Failed to compare the totals: got %g, %g, %g", d.Before, d.After, d.Delta)
+	}
+	wantStages := []StageDiff{
+		{Name: "a", Before: 15, After: 18, Delta: 3},
+		{Name: "b", Before: 3, After: 0, Delta: -3},
+		{Name: "c", Before: 0, After: 4, Delta: 4},
+	}
+	if !reflect.DeepEqual(d.Stages, wantStages) {
+		t.Errorf("Failed to compare the stages: got %+v, want %+v", d.Stages, wantStages)
+	}
+	wantChanges := []Change{
+		{Stage: "a", Resource: "x", Before: 10, After: 12, Delta: 2},
+		{Stage: "a", Resource: "w", After: 1, Delta: 1},
+		{Stage: "b", Resource: "z", Before: 3, Delta: -3},
+		{Stage: "c", Resource: "q", After: 4, Delta: 4},
+	}
+	if !reflect.DeepEqual(d.Changes, wantChanges) {
+		t.Errorf("Failed to compare the resources: got %+v, want %+v", d.Changes, wantChanges)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	est := &Estimate{Version: "v1", Currency: "USD", Monthly: 12.5, Stages: []Stage{
+		{Name: "02-networking", Monthly: 12.5, Lines: []Line{
+			{Resource: "google_compute_router_nat.nat", Region: "us-central1", Monthly: 12.5, Items: []Item{{Name: "NAT gateway"}}, Notes: []string{"usage not estimated"}},
+		}},
+	}}
+	var text bytes.Buffer
+	if err := Write(&text, est, "text"); err != nil {
+		t.Fatalf("Failed to write the estimate: %v", err)
+	}
+	want := `STAGE          RESOURCE                       REGION       MONTHLY  ITEMS
+02-networking  google_compute_router_nat.nat  us-central1  12.50    NAT gateway
+02-networking  TOTAL                                       12.50
+TOTAL                                                      12.50
+
+Monthly USD, prices v1.
+
+Notes:
+02-networking: google_compute_router_nat.nat: usage not estimated
+`
+	if text.String() != want {
+		t.Errorf("Failed to write the estimate:\ngot:\n%s\nwant:\n%s", text.String(), want)
+	}
+
+	diff := Compare(&Estimate{Version: "v1", Currency: "USD"}, est)
+	var js bytes.Buffer
+	if err := Write(&js, diff, "json"); err != nil {
+		t.Fatalf("Failed to write the diff: %v", err)
+	}
+	var got Diff
+	if err := json.Unmarshal(js.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode the diff: %v", err)
+	}
+	if got.Delta != 12.5 || len(got.Changes) != 1 {
+		t.Errorf("Failed to write the diff: got %+v", got)
+	}
+
+	if err := Write(&js, est, "xml"); err == nil {
+		t.Errorf("Write() with an unknown format succeeded")
+	}
+}
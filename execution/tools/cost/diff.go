@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+// Change is the change of the monthly cost of a resource between two
+// estimates.
+type Change struct {
+	Stage    string  `json:"stage"`
+	Resource string  `json:"resource"`
+	Type     string  `json:"type"`
+	Before   float64 `json:"before"`
+	After    float64 `json:"after"`
+	Delta    float64 `json:"delta"`
+}
+
+// StageDiff is the change of the monthly cost of a stage.
+type StageDiff struct {
+	Name   string  `json:"name"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+	Delta  float64 `json:"delta"`
+}
+
+// Diff is the change of the monthly cost between two estimates, such as the
+// ones of the plans of a stage before and after a configuration change.
+type Diff struct {
+	Version  string      `json:"version"`
+	Currency string      `json:"currency"`
+	Before   float64     `json:"before"`
+	After    float64     `json:"after"`
+	Delta    float64     `json:"delta"`
+	Stages   []StageDiff `json:"stages"`
+	// Changes are the resources whose cost changes, added and removed
+	// resources included.
+	Changes []Change `json:"changes"`
+}
+
+// Compare returns the change of the monthly cost from base to target, by
+// stage name and resource address.
+func Compare(base, target *Estimate) *Diff {
+	d := &Diff{Version: target.Version, Currency: target.Currency, Before: base.Monthly, After: target.Monthly, Stages: []StageDiff{}, Changes: []Change{}}
+	d.Delta = d.After - d.Before
+	before := map[string]Stage{}
+	var names []string
+	for _, s := range base.Stages {
+		before[s.Name] = s
+		names = append(names, s.Name)
+	}
+	after := map[string]Stage{}
+	for _, s := range target.Stages {
+		after[s.Name] = s
+		if _, ok := before[s.Name]; !ok {
+			names = append(names, s.Name)
+		}
+	}
+	for _, name := range names {
+		b, a := before[name], after[name]
+		d.Stages = append(d.Stages, StageDiff{Name: name, Before: b.Monthly, After: a.Monthly, Delta: a.Monthly - b.Monthly})
+		d.Changes = append(d.Changes, changes(name, b.Lines, a.Lines)...)
+	}
+	return d
+}
+
+// changes returns the changes of the resources of a stage, in the order of
+// the target lines followed by the removed resources.
+func changes(stage string, base, target []Line) []Change {
+	before := map[string]Line{}
+	for _, l := range base {
+		before[l.Resource] = l
+	}
+	var out []Change
+	seen := map[string]bool{}
+	for _, l := range target {
+		seen[l.Resource] = true
+		b := before[l.Resource]
+		if l.Monthly != b.Monthly {
+			out = append(out, Change{Stage: stage, Resource: l.Resource, Type: l.Type, Before: b.Monthly, After: l.Monthly, Delta: l.Monthly - b.Monthly})
+		}
+	}
+	for _, l := range base {
+		if !seen[l.Resource] && l.Monthly != 0 {
+			out = append(out, Change{Stage: stage, Resource: l.Resource, Type: l.Type, Before: l.Monthly, Delta: -l.Monthly})
+		}
+	}
+	return out
+}
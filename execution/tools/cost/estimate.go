@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+// Item is a priced component of a resource, such as its vCPUs or its disk.
+type Item struct {
+	Name string `json:"name"`
+	// Key is the key of the price in the price table.
+	Key string `json:"key"`
+	// Quantity is the billed quantity per month in Unit, e.g. 1460
+	// vCPU-hours.
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Monthly  float64 `json:"monthly"`
+}
+
+// Line is the estimate of a resource.
+type Line struct {
+	Resource string  `json:"resource"`
+	Type     string  `json:"type"`
+	Region   string  `json:"region,omitempty"`
+	Monthly  float64 `json:"monthly"`
+	Items    []Item  `json:"items,omitempty"`
+	// Notes are the assumptions of the estimate, such as a default value
+	// used for a value unknown until apply, and the missing prices.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Stage is the estimate of the plan of a stage.
+type Stage struct {
+	Name    string  `json:"name"`
+	Monthly float64 `json:"monthly"`
+	Lines   []Line  `json:"lines"`
+}
+
+// Estimate is the monthly estimate of the plans of one or more stages.
+type Estimate struct {
+	Version  string  `json:"version"`
+	Currency string  `json:"currency"`
+	Monthly  float64 `json:"monthly"`
+	Stages   []Stage `json:"stages"`
+}
+
+// pricer adds the items of a planned resource to its line.
+type pricer func(e *estimator, l *Line, c tfplan.ResourceChange)
+
+// Types returns the priced resource types, sorted.
+func Types() []string {
+	var out []string
+	for t := range pricers {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// New returns an empty estimate priced with prices.
+func New(prices *Prices) *Estimate {
+	return &Estimate{Version: prices.Version, Currency: prices.Currency}
+}
+
+// Add prices the resources of a stage plan as planned, those the plan
+// creates, updates, replaces or keeps, and adds the stage to the estimate.
+func (est *Estimate) Add(name string, plan *tfplan.Plan, prices *Prices) {
+	e := &estimator{prices: prices, plan: plan, rules: map[string]int{}}
+	s := Stage{Name: name, Lines: []Line{}}
+	for _, c := range plan.ResourceChanges {
+		price, ok := pricers[c.Type]
+		if !ok || !c.Managed() || !c.Has("create", "update", "no-op") {
+			continue
+		}
+		l := Line{Resource: c.Address, Type: c.Type, Region: tfplan.Region(c.Change.After)}
+		price(e, &l, c)
+		for _, it := range l.Items {
+			l.Monthly += it.Monthly
+		}
+		s.Monthly += l.Monthly
+		s.Lines = append(s.Lines, l)
+	}
+	est.Monthly += s.Monthly
+	est.Stages = append(est.Stages, s)
+}
+
+// estimator prices the resources of a plan.
+type estimator struct {
+	prices *Prices
+	plan   *tfplan.Plan
+	// rules counts the forwarding rules priced per project and region.
+	rules map[string]int
+}
+
+// hourly adds an item billed per hour for units, e.g. vCPUs, to a line.
+func (e *estimator) hourly(l *Line, name, key string, units float64, unit string) {
+	e.add(l, name, key, units*e.prices.HoursPerMonth, unit)
+}
+
+// monthly adds an item billed per month for quantity, e.g. GB of storage,
+// to a line.
+func (e *estimator) monthly(l *Line, name, key string, quantity float64, unit string) {
+	e.add(l, name, key, quantity, unit)
+}
+
+func (e *estimator) add(l *Line, name, key string, quantity float64, unit string) {
+	price, ok := e.prices.Price(key, l.Region)
+	if !ok {
+		note(l, "no price for %s, %s not priced", key, name)
+		return
+	}
+	l.Items = append(l.Items, Item{Name: name, Key: key, Quantity: quantity, Unit: unit, Monthly: quantity * price})
+}
+
+func note(l *Line, format string, args ...any) {
+	l.Notes = append(l.Notes, fmt.Sprintf(format, args...))
+}
+
+// related returns the planned resources of a type of the module of c, or
+// of the plan when the module has none, such as the instance template of a
+// managed instance group, whose reference is unknown until apply.
+func (e *estimator) related(c tfplan.ResourceChange, types ...string) []tfplan.ResourceChange {
+	var module, plan []tfplan.ResourceChange
+	for _, r := range e.plan.ResourceChanges {
+		if !r.Managed() || r.Change.After == nil || !slices.Contains(types, r.Type) {
+			continue
+		}
+		plan = append(plan, r)
+		if r.ModuleAddress == c.ModuleAddress {
+			module = append(module, r)
+		}
+	}
+	if len(module) > 0 {
+		return module
+	}
+	return plan
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cost estimates the monthly cost of the resources of stage plans
+// offline, from a versioned local price table, and the cost of the change
+// between two plans.
+package cost
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRegion is the key of the price of a table entry applying to the
+// regions without a price of their own, and to global resources.
+const DefaultRegion = "default"
+
+// Prices is a price table in Currency. The unit of a price is the last part
+// of its key: hour for an hourly price, e.g. compute.n2.vcpu_hour, and month
+// for a monthly one, e.g. compute.pd-ssd.gb_month.
+type Prices struct {
+	// Version identifies the snapshot of the list prices, e.g. its date.
+	Version       string  `yaml:"version"`
+	Currency      string  `yaml:"currency"`
+	HoursPerMonth float64 `yaml:"hours_per_month"`
+	// Prices maps a price key, such as compute.n2.vcpu_hour, to its price
+	// per region, DefaultRegion for every other region.
+	Prices map[string]map[string]float64 `yaml:"prices"`
+}
+
+// LoadPrices reads a price table:
+//
+//	version: "2026-10-01"
+//	currency: USD
+//	hours_per_month: 730
+//	prices:
+//	  cloudsql.vcpu_hour: {default: 0.0413, europe-west1: 0.0454}
+func LoadPrices(file string) (*Prices, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var p Prices
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	switch {
+	case p.Version == "":
+		return nil, fmt.Errorf("%s: version is required", file)
+	case p.Currency == "":
+		return nil, fmt.Errorf("%s: currency is required", file)
+	case p.HoursPerMonth <= 0:
+		return nil, fmt.Errorf("%s: hours_per_month must be positive", file)
+	}
+	for key, regions := range p.Prices {
+		for region, price := range regions {
+			if price < 0 {
+				return nil, fmt.Errorf("%s: %s: negative price in %s", file, key, region)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// Price returns the price of key in region, or its default price.
+func (p *Prices) Price(key, region string) (float64, bool) {
+	regions, ok := p.Prices[key]
+	if !ok {
+		return 0, false
+	}
+	if price, ok := regions[region]; ok {
+		return price, true
+	}
+	price, ok := regions[DefaultRegion]
+	return price, ok
+}
@@ -0,0 +1,357 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+// pricers are the pricers of the resource types with a cost. Other resource
+// types are free, or billed on usage only, and are not listed.
+var pricers = map[string]pricer{
+	"google_sql_database_instance":                 cloudSQL,
+	"google_alloydb_instance":                      alloyDB,
+	"google_redis_cluster":                         redisCluster,
+	"google_compute_instance":                      instance,
+	"google_compute_instance_group_manager":        instanceGroup,
+	"google_compute_region_instance_group_manager": instanceGroup,
+	"google_compute_router_nat":                    nat,
+	"google_compute_vpn_tunnel":                    vpnTunnel,
+	"google_compute_interconnect_attachment":       interconnectAttachment,
+	"google_compute_forwarding_rule":               forwardingRule,
+	"google_compute_global_forwarding_rule":        forwardingRule,
+}
+
+// Default values of the provider for optional attributes, and the values
+// assumed for attributes unknown until apply.
+const (
+	defaultDiskGB       = 10
+	defaultAlloyDBCPU   = 2
+	alloyDBMemoryPerCPU = 8
+)
+
+var (
+	sqlCustom = regexp.MustCompile(`^db-custom-(\d+)-(\d+)$`)
+	sqlLegacy = regexp.MustCompile(`^db-n1-(standard|highmem)-(\d+)$`)
+	sqlPerf   = regexp.MustCompile(`^db-perf-optimized-n-(\d+)$`)
+)
+
+// sqlShape returns the vCPUs and GB of memory of a dedicated core Cloud SQL
+// tier, false for a shared core tier.
+func sqlShape(tier string) (vcpu, memory float64, ok bool) {
+	if m := sqlCustom.FindStringSubmatch(tier); m != nil {
+		cpu, _ := strconv.Atoi(m[1])
+		mb, _ := strconv.Atoi(m[2])
+		return float64(cpu), float64(mb) / 1024, true
+	}
+	if m := sqlLegacy.FindStringSubmatch(tier); m != nil {
+		cpu, _ := strconv.Atoi(m[2])
+		ratio := 3.75
+		if m[1] == "highmem" {
+			ratio = 6.5
+		}
+		return float64(cpu), float64(cpu) * ratio, true
+	}
+	if m := sqlPerf.FindStringSubmatch(strings.ToLower(tier)); m != nil {
+		cpu, _ := strconv.Atoi(m[1])
+		return float64(cpu), float64(cpu) * 8, true
+	}
+	return 0, 0, false
+}
+
+// cloudSQL prices the tier and the disk of a Cloud SQL instance, twice for a
+// regional (HA) instance.
+func cloudSQL(e *estimator, l *Line, c tfplan.ResourceChange) {
+	after := c.Change.After
+	nodes, ha := 1.0, ""
+	if v, _ := tfplan.String(after, "settings", 0, "availability_type"); v == "REGIONAL" {
+		nodes, ha = 2, " (HA)"
+	}
+	prefix := "cloudsql."
+	if v, _ := tfplan.String(after, "settings", 0, "edition"); v == "ENTERPRISE_PLUS" {
+		prefix = "cloudsql.enterprise_plus."
+	}
+	tier, ok := tfplan.String(after, "settings", 0, "tier")
+	switch {
+	case !ok:
+		note(l, "tier unknown until apply, not priced")
+	default:
+		if vcpu, memory, dedicated := sqlShape(tier); dedicated {
+			e.hourly(l, fmt.Sprintf("%s: %g vCPU%s", tier, vcpu, ha), prefix+"vcpu_hour", vcpu*nodes, "vCPU-hour")
+			e.hourly(l, fmt.Sprintf("%s: %g GB memory%s", tier, memory, ha), prefix+"memory_gb_hour", memory*nodes, "GB-hour")
+		} else {
+			e.hourly(l, tier+ha, "cloudsql.shared_core_hour."+tier, nodes, "instance-hour")
+		}
+	}
+	size, ok := tfplan.Number(after, "settings", 0, "disk_size")
+	if !ok || size == 0 {
+		size = defaultDiskGB
+		note(l, "disk size unknown until apply, %d GB assumed", defaultDiskGB)
+	}
+	diskType, ok := tfplan.String(after, "settings", 0, "disk_type")
+	if !ok {
+		diskType = "PD_SSD"
+	}
+	key := "cloudsql.ssd_gb_month"
+	if diskType == "PD_HDD" {
+		key = "cloudsql.hdd_gb_month"
+	}
+	e.monthly(l, fmt.Sprintf("%g GB %s disk%s", size, strings.ToLower(strings.TrimPrefix(diskType, "PD_")), ha), key, size*nodes, "GB-month")
+}
+
+// alloyDB prices the vCPUs and memory of the nodes of an AlloyDB instance:
+// two for a regional primary instance, the node count of a read pool. The
+// region is the location of the cluster of the instance.
+func alloyDB(e *estimator, l *Line, c tfplan.ResourceChange) {
+	after := c.Change.After
+	if l.Region == "" {
+		for _, cluster := range e.related(c, "google_alloydb_cluster") {
+			l.Region = tfplan.Region(cluster.Change.After)
+			break
+		}
+	}
+	cpu, ok := tfplan.Number(after, "machine_config", 0, "cpu_count")
+	if !ok {
+		cpu = defaultAlloyDBCPU
+		note(l, "cpu_count unknown until apply, %d assumed", defaultAlloyDBCPU)
+	}
+	nodes := 1.0
+	instanceType, _ := tfplan.String(after, "instance_type")
+	switch instanceType {
+	case "READ_POOL":
+		if n, ok := tfplan.Number(after, "read_pool_config", 0, "node_count"); ok {
+			nodes = n
+		}
+	default:
+		if v, _ := tfplan.String(after, "availability_type"); v != "ZONAL" {
+			nodes = 2
+		}
+	}
+	e.hourly(l, fmt.Sprintf("%g node(s) of %g vCPU", nodes, cpu), "alloydb.vcpu_hour", cpu*nodes, "vCPU-hour")
+	e.hourly(l, fmt.Sprintf("%g node(s) of %g GB memory", nodes, cpu*alloyDBMemoryPerCPU), "alloydb.memory_gb_hour", cpu*alloyDBMemoryPerCPU*nodes, "GB-hour")
+}
+
+// redisCluster prices the nodes of a Memorystore for Redis cluster, a
+// primary and its replicas per shard.
+func redisCluster(e *estimator, l *Line, c tfplan.ResourceChange) {
+	after := c.Change.After
+	shards, ok := tfplan.Number(after, "shard_count")
+	if !ok {
+		note(l, "shard_count unknown until apply, not priced")
+		return
+	}
+	replicas, _ := tfplan.Number(after, "replica_count")
+	nodeType, ok := tfplan.String(after, "node_type")
+	if !ok || nodeType == "" {
+		nodeType = "REDIS_HIGHMEM_MEDIUM"
+	}
+	nodes := shards * (1 + replicas)
+	name := strings.ToLower(strings.TrimPrefix(nodeType, "REDIS_"))
+	e.hourly(l, fmt.Sprintf("%g shard(s) x %g node(s) %s", shards, 1+replicas, nodeType), "redis_cluster."+name+".node_hour", nodes, "node-hour")
+}
+
+// instance prices the machine type and the boot disk of a VM.
+func instance(e *estimator, l *Line, c tfplan.ResourceChange) {
+	after := c.Change.After
+	e.machine(l, after, 1)
+	size, ok := tfplan.Number(after, "boot_disk", 0, "initialize_params", 0, "size")
+	if !ok {
+		size = defaultDiskGB
+		note(l, "boot disk size unknown until apply, %d GB assumed", defaultDiskGB)
+	}
+	diskType, ok := tfplan.String(after, "boot_disk", 0, "initialize_params", 0, "type")
+	if !ok {
+		diskType = "pd-standard"
+	}
+	e.disk(l, size, diskType, 1)
+}
+
+// instanceGroup prices the instances of a managed instance group: its target
+// size, or the minimum replicas of its autoscaler, times the machine type
+// and the disks of its instance template. The template and the autoscaler
+// reference the group by self links unknown until apply, so they are the
+// ones of the module of the group, or of the plan.
+func instanceGroup(e *estimator, l *Line, c tfplan.ResourceChange) {
+	after := c.Change.After
+	size, ok := tfplan.Number(after, "target_size")
+	if !ok {
+		autoscalers := e.related(c, "google_compute_autoscaler", "google_compute_region_autoscaler")
+		if len(autoscalers) == 1 {
+			size, ok = tfplan.Number(autoscalers[0].Change.After, "autoscaling_policy", 0, "min_replicas")
+		}
+		if ok {
+			note(l, "autoscaled, priced for the %g minimum replicas", size)
+		} else {
+			size = 1
+			note(l, "target size unknown until apply, 1 instance assumed")
+		}
+	}
+	templates := e.related(c, "google_compute_instance_template", "google_compute_region_instance_template")
+	if len(templates) != 1 {
+		note(l, "%d instance templates in the plan, instances not priced", len(templates))
+		return
+	}
+	template := templates[0].Change.After
+	e.machine(l, template, size)
+	for _, d := range tfplan.List(template, "disk") {
+		gb, ok := tfplan.Number(d, "disk_size_gb")
+		if !ok {
+			gb = defaultDiskGB
+			note(l, "template disk size unknown until apply, %d GB assumed", defaultDiskGB)
+		}
+		diskType, ok := tfplan.String(d, "disk_type")
+		if !ok {
+			diskType = "pd-standard"
+		}
+		e.disk(l, gb, diskType, size)
+	}
+}
+
+// machine adds the vCPUs and memory of count VMs of the machine type of a
+// VM or an instance template to a line. A compute.TYPE.hour price, e.g. for
+// a shared core type, takes precedence.
+func (e *estimator) machine(l *Line, after any, count float64) {
+	machineType, ok := tfplan.String(after, "machine_type")
+	if !ok {
+		note(l, "machine type unknown until apply, not priced")
+		return
+	}
+	machineType = machineType[strings.LastIndex(machineType, "/")+1:]
+	if _, ok := e.prices.Price("compute."+machineType+".hour", l.Region); ok {
+		e.hourly(l, fmt.Sprintf("%g x %s", count, machineType), "compute."+machineType+".hour", count, "instance-hour")
+		return
+	}
+	family, vcpu, memory, ok := machineShape(machineType)
+	if !ok {
+		note(l, "unknown machine type %s, not priced", machineType)
+		return
+	}
+	e.hourly(l, fmt.Sprintf("%g x %s: %g vCPU", count, machineType, vcpu), "compute."+family+".vcpu_hour", vcpu*count, "vCPU-hour")
+	e.hourly(l, fmt.Sprintf("%g x %s: %g GB memory", count, machineType, memory), "compute."+family+".memory_gb_hour", memory*count, "GB-hour")
+}
+
+// disk adds count persistent disks to a line.
+func (e *estimator) disk(l *Line, gb float64, diskType string, count float64) {
+	diskType = diskType[strings.LastIndex(diskType, "/")+1:]
+	e.monthly(l, fmt.Sprintf("%g x %g GB %s", count, gb, diskType), "compute."+diskType+".gb_month", gb*count, "GB-month")
+}
+
+var (
+	customMachine     = regexp.MustCompile(`^(?:([a-z0-9]+)-)?custom-(\d+)-(\d+)(?:-ext)?$`)
+	predefinedMachine = regexp.MustCompile(`^([a-z0-9]+)-(standard|highmem|highcpu)-(\d+)$`)
+)
+
+// sharedCore are the vCPU fraction and GB of memory of the shared core
+// machine types billed by vCPU and memory.
+var sharedCore = map[string][2]float64{
+	"e2-micro":  {0.25, 1},
+	"e2-small":  {0.5, 2},
+	"e2-medium": {1, 4},
+}
+
+// machineShape returns the family, vCPUs and GB of memory of a machine type.
+func machineShape(machineType string) (family string, vcpu, memory float64, ok bool) {
+	if s, ok := sharedCore[machineType]; ok {
+		return "e2", s[0], s[1], true
+	}
+	if m := customMachine.FindStringSubmatch(machineType); m != nil {
+		family = m[1]
+		if family == "" {
+			family = "n1"
+		}
+		cpu, _ := strconv.Atoi(m[2])
+		mb, _ := strconv.Atoi(m[3])
+		return family, float64(cpu), float64(mb) / 1024, true
+	}
+	m := predefinedMachine.FindStringSubmatch(machineType)
+	if m == nil {
+		return "", 0, 0, false
+	}
+	cpu, _ := strconv.Atoi(m[3])
+	ratios := map[string]float64{"standard": 4, "highmem": 8, "highcpu": 1}
+	if m[1] == "n1" {
+		ratios = map[string]float64{"standard": 3.75, "highmem": 6.5, "highcpu": 0.9}
+	}
+	return m[1], float64(cpu), float64(cpu) * ratios[m[2]], true
+}
+
+// nat prices a Cloud NAT gateway at its maximum hourly charge. Data
+// processing is billed on usage and not estimated.
+func nat(e *estimator, l *Line, c tfplan.ResourceChange) {
+	e.hourly(l, "NAT gateway", "nat.gateway_hour", 1, "gateway-hour")
+	note(l, "data processing is billed on usage, not estimated")
+}
+
+// vpnTunnel prices a VPN tunnel. Egress is billed on usage and not
+// estimated.
+func vpnTunnel(e *estimator, l *Line, c tfplan.ResourceChange) {
+	e.hourly(l, "VPN tunnel", "vpn.tunnel_hour", 1, "tunnel-hour")
+}
+
+// interconnectAttachment prices a VLAN attachment by its type and capacity,
+// interconnect.TYPE.CAPACITY.hour, or interconnect.TYPE.hour.
+func interconnectAttachment(e *estimator, l *Line, c tfplan.ResourceChange) {
+	after := c.Change.After
+	kind, ok := tfplan.String(after, "type")
+	if !ok {
+		kind = "DEDICATED"
+	}
+	kind = strings.ToLower(strings.TrimSuffix(kind, "_PROVIDER"))
+	name := kind + " VLAN attachment"
+	bandwidth, ok := tfplan.String(after, "bandwidth")
+	if !ok && kind == "partner" {
+		bandwidth = "BPS_10G"
+		note(l, "bandwidth unknown until apply, %s assumed", bandwidth)
+	}
+	key := "interconnect." + kind + "." + strings.ToLower(bandwidth) + ".hour"
+	if _, ok := e.prices.Price(key, l.Region); ok {
+		name += " " + bandwidth
+	} else {
+		key = "interconnect." + kind + ".hour"
+	}
+	e.hourly(l, name, key, 1, "attachment-hour")
+}
+
+// forwardingRule prices a forwarding rule: a PSC endpoint, targeting a
+// service attachment, per endpoint, other rules by project and region, the
+// first five for a flat charge, carried by the first rule of the plan, and
+// the next ones each.
+func forwardingRule(e *estimator, l *Line, c tfplan.ResourceChange) {
+	after := c.Change.After
+	if target, _ := tfplan.String(after, "target"); strings.Contains(target, "/serviceAttachments/") {
+		e.hourly(l, "PSC endpoint", "psc.endpoint_hour", 1, "endpoint-hour")
+		return
+	}
+	region := l.Region
+	if c.Type == "google_compute_global_forwarding_rule" {
+		region = "global"
+	}
+	project, _ := tfplan.String(after, "project")
+	scope := project + "/" + region
+	e.rules[scope]++
+	switch n := e.rules[scope]; {
+	case n == 1:
+		e.hourly(l, "first 5 forwarding rules", "forwarding_rule.first5_hour", 1, "hour")
+	case n <= 5:
+		note(l, "included in the charge of the first 5 forwarding rules of %s", scope)
+	default:
+		e.hourly(l, "additional forwarding rule", "forwarding_rule.additional_hour", 1, "rule-hour")
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteText prints a table of the resources of every stage with their
+// monthly cost and priced items, the notes of the estimate, the total of
+// every stage and the total.
+func (est *Estimate) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tRESOURCE\tREGION\tMONTHLY\tITEMS")
+	var notes []string
+	for _, s := range est.Stages {
+		for _, l := range s.Lines {
+			var items []string
+			for _, it := range l.Items {
+				items = append(items, it.Name)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%.2f\t%s\n", s.Name, l.Resource, l.Region, l.Monthly, strings.Join(items, ", "))
+			for _, n := range l.Notes {
+				notes = append(notes, fmt.Sprintf("%s: %s: %s", s.Name, l.Resource, n))
+			}
+		}
+		fmt.Fprintf(tw, "%s\tTOTAL\t\t%.2f\n", s.Name, s.Monthly)
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t\t%.2f\n", est.Monthly)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return writeFooter(w, est.Currency, est.Version, notes)
+}
+
+// WriteText prints a table of the resources whose cost changes, the change
+// of every stage and the total change.
+func (d *Diff) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tRESOURCE\tBEFORE\tAFTER\tDELTA")
+	for _, s := range d.Stages {
+		for _, c := range d.Changes {
+			if c.Stage == s.Name {
+				fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%+.2f\n", c.Stage, c.Resource, c.Before, c.After, c.Delta)
+			}
+		}
+		fmt.Fprintf(tw, "%s\tTOTAL\t%.2f\t%.2f\t%+.2f\n", s.Name, s.Before, s.After, s.Delta)
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t%.2f\t%.2f\t%+.2f\n", d.Before, d.After, d.Delta)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return writeFooter(w, d.Currency, d.Version, nil)
+}
+
+func writeFooter(w io.Writer, currency, version string, notes []string) error {
+	if _, err := fmt.Fprintf(w, "\nMonthly %s, prices %s.\n", currency, version); err != nil {
+		return err
+	}
+	if len(notes) > 0 {
+		if _, err := fmt.Fprintln(w, "\nNotes:"); err != nil {
+			return err
+		}
+	}
+	for _, n := range notes {
+		if _, err := fmt.Fprintln(w, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write prints an estimate or a diff in the given format, text or json.
+func Write(w io.Writer, v interface{ WriteText(io.Writer) error }, format string) error {
+	switch format {
+	case "text":
+		return v.WriteText(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return fmt.Errorf("unknown format %q, expected text or json", format)
+}
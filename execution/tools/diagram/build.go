@@ -0,0 +1,624 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagram
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ipplan"
+)
+
+// Defaults of the networking stage variables the graph depends on.
+const (
+	defaultNATName        = "internet-gateway"
+	defaultVPNGatewayName = "vpn1"
+)
+
+var defaultAttachmentNames = [2]string{"dedicated-ic-vlan-attachment-3", "dedicated-ic-vlan-attachment-4"}
+
+type builder struct {
+	g     *Graph
+	stage string
+	// subnets maps network/name and name to the declared subnets.
+	subnets map[string]*Node
+}
+
+// Build returns the architecture graph of a tree. Networks, subnets,
+// instances, instance groups and forwarding rules referenced by the tree
+// but not declared in it are added as external nodes.
+func Build(tree *config.Tree) *Graph {
+	b := &builder{g: &Graph{}, subnets: map[string]*Node{}}
+	// Stages run in order, so the resources a stage references are
+	// declared by the time it is built.
+	for _, s := range tree.Stages {
+		b.stage = s.Stage.Name
+		if v, ok := s.Vars.(*config.NetworkingVars); ok {
+			b.networking(v, s.VarsFile)
+		}
+		if v, ok := s.Vars.(*config.ProducerConnectivityVars); ok {
+			b.pscEndpoints(v, s.VarsFile)
+		}
+		for _, d := range s.Documents {
+			b.document(s.Stage.Name, d)
+		}
+	}
+	b.g.sort()
+	return b.g
+}
+
+func (b *builder) document(stage string, d config.Document) {
+	switch v := d.Value.(type) {
+	case *config.NCCConfig:
+		b.ncc(v, d.File)
+	case *config.FirewallPolicyStruct:
+		b.firewallPolicy(v, d.File)
+	case *config.CloudSQLStruct:
+		b.cloudSQL(v, d.File)
+	case *config.AlloyDBStruct:
+		b.alloyDB(v, d.File)
+	case *config.MRCStruct:
+		b.mrc(v, d.File)
+	case *config.GKEConfig:
+		b.gke(v, d.File)
+	case *config.VectorSearchStruct:
+		b.vectorSearch(v, d.File)
+	case *config.EndpointConfig:
+		b.onlineEndpoint(v, d.File)
+	case *config.VMInstanceConfig:
+		b.instance(v, d.File)
+	case *config.MIGConfig:
+		b.mig(v, d.File)
+	case *config.UMIGConfig:
+		b.umig(v, d.File)
+	case *config.WorkbenchConfig:
+		b.workbench(v, d.File)
+	case *config.CloudRunStruct:
+		b.cloudRun(v, lastSegment(stage), d.File)
+	case *config.VPCAccessConnectorConfig:
+		b.connector(v, d.File)
+	case *config.NetworkLoadBalancerConfig:
+		b.externalNLB(v, d.File)
+	case *config.InternalNetworkLoadBalancerConfig:
+		b.internalNLB(v, d.File)
+	case *config.LoadBalancerConfig:
+		b.alb(v, d.File)
+	case *map[string]any:
+		switch stage {
+		case "network-security-integration/outofband":
+			b.outOfBand(*v, d.File)
+		case "network-security-integration/securityprofile":
+			b.securityProfile(*v, d.File)
+		case "network-security-integration/packetmirroringrule":
+			b.mirroringRule(*v, d.File)
+		}
+	}
+}
+
+// lastSegment returns the last element of a slash separated path.
+func lastSegment(s string) string {
+	return s[strings.LastIndex(s, "/")+1:]
+}
+
+// regionOf returns the region of a zone, e.g. us-central1 for
+// us-central1-a, or the location itself when it is a region.
+func regionOf(location string) string {
+	if i := strings.LastIndex(location, "-"); i >= 0 && len(location)-i == 2 {
+		return location[:i]
+	}
+	return location
+}
+
+// node adds a node declared by the current stage. Empty attributes are left
+// out.
+func (b *builder) node(kind, id, name, file string, attrs map[string]string, resources ...string) *Node {
+	maps.DeleteFunc(attrs, func(_, v string) bool { return v == "" })
+	return b.g.add(&Node{ID: kind + ":" + id, Kind: kind, Name: name, Stage: b.stage, File: file, Attrs: attrs, Resources: resources})
+}
+
+// ref returns the node of the given kind and ID, adding it as an external
+// node when the tree does not declare it.
+func (b *builder) ref(kind, id, name string) *Node {
+	if n := b.g.Node(kind + ":" + id); n != nil {
+		return n
+	}
+	return b.g.add(&Node{ID: kind + ":" + id, Kind: kind, Name: name, External: true})
+}
+
+// network returns the node of a network given by name or self link, or nil
+// when ref is empty.
+func (b *builder) network(ref string) *Node {
+	name := ipplan.NetworkName(ref)
+	if name == "" {
+		return nil
+	}
+	return b.ref(Network, name, name)
+}
+
+// subnet returns the node of a subnet given by name or self link, or nil
+// when ref is empty. A subnet is looked up in network first, then by name
+// alone when network is nil.
+func (b *builder) subnet(network *Node, ref, region string) *Node {
+	if ref == "" {
+		return nil
+	}
+	name := lastSegment(ref)
+	if i := strings.Index(ref, "/regions/"); i >= 0 {
+		region = strings.SplitN(ref[i+len("/regions/"):], "/", 2)[0]
+	}
+	key := name
+	if network != nil {
+		key = network.Name + "/" + name
+	}
+	if n, ok := b.subnets[key]; ok {
+		return n
+	}
+	var parts []string
+	if network != nil {
+		parts = append(parts, network.Name)
+	}
+	if region != "" {
+		parts = append(parts, region)
+	}
+	n := b.ref(Subnet, strings.Join(append(parts, name), "/"), name)
+	if region != "" {
+		n.Attrs["region"] = region
+	}
+	if network != nil {
+		b.g.link(network, n, Contains)
+	}
+	return n
+}
+
+// attach links n to its subnet, or to its network when it has no subnet.
+func (b *builder) attach(n *Node, networkRef, subnetRef, region string) {
+	network := b.network(networkRef)
+	if s := b.subnet(network, subnetRef, region); s != nil {
+		b.g.link(n, s, Attached)
+	} else if network != nil {
+		b.g.link(n, network, Attached)
+	}
+}
+
+// group returns the node of the instance group used as a load balancer
+// backend, given by name or self link.
+func (b *builder) group(ref string) *Node {
+	name := lastSegment(ref)
+	for _, kind := range []string{MIG, UMIG} {
+		if n := b.g.Node(kind + ":" + name); n != nil {
+			return n
+		}
+	}
+	return b.ref(InstanceGroup, name, name)
+}
+
+func (b *builder) networking(v *config.NetworkingVars, file string) {
+	// The tfvars of the repository leave the network unnamed.
+	if v.NetworkName == "" {
+		return
+	}
+	attrs := map[string]string{"project": v.ProjectID, "region": v.Region}
+	if v.CreateNetwork != nil && !v.CreateNetwork.Enabled() {
+		attrs["existing"] = "true"
+	}
+	var resources []string
+	if v.CreatePSA == nil || v.CreatePSA.Enabled() {
+		name, cidr := v.PSARangeName, v.PSARange
+		if name == "" {
+			name = ipplan.DefaultPSARangeName
+		}
+		if cidr == "" {
+			cidr = ipplan.DefaultPSARange
+		}
+		attrs["psa_range"] = cidr
+		resources = append(resources, "google_compute_global_address/"+name)
+	}
+	network := b.node(Network, v.NetworkName, v.NetworkName, file, attrs, append(resources, "google_compute_network/"+v.NetworkName)...)
+	for _, s := range v.Subnets {
+		if s.Name == "" {
+			continue
+		}
+		n := b.node(Subnet, v.NetworkName+"/"+s.Region+"/"+s.Name, s.Name, file,
+			map[string]string{"cidr": s.IPCIDRRange, "region": s.Region},
+			"google_compute_subnetwork/"+s.Region+"/"+s.Name)
+		b.subnets[v.NetworkName+"/"+s.Name] = n
+		b.subnets[s.Name] = n
+		b.g.link(network, n, Contains)
+	}
+	if v.CreateNAT == nil || v.CreateNAT.Enabled() {
+		name := v.NATName
+		if name == "" {
+			name = defaultNATName
+		}
+		n := b.node(NAT, v.NetworkName+"/"+name, name, file, map[string]string{"region": v.Region}, "google_compute_router_nat/"+name)
+		b.g.link(n, network, Attached)
+	}
+	if v.CreateHAVPN.Enabled() {
+		name := v.HAVPNGateway1Name
+		if name == "" {
+			name = defaultVPNGatewayName
+		}
+		n := b.node(VPNGateway, v.NetworkName+"/"+name, name, file, map[string]string{"region": v.Region}, "google_compute_ha_vpn_gateway/"+name)
+		b.g.link(n, network, Attached)
+	}
+	if v.CreateInterconnect.Enabled() {
+		for i, a := range [2][2]string{{v.FirstVAName, v.FirstInterconnectName}, {v.SecondVAName, v.SecondInterconnectName}} {
+			name := a[0]
+			if name == "" {
+				name = defaultAttachmentNames[i]
+			}
+			n := b.node(VLANAttachment, name, name, file, map[string]string{"region": v.Region, "interconnect": lastSegment(a[1])}, "google_compute_interconnect_attachment/"+name)
+			b.g.link(n, network, Attached)
+		}
+	}
+}
+
+func (b *builder) ncc(v *config.NCCConfig, file string) {
+	var hub *Node
+	for i, h := range v.Hubs {
+		var n *Node
+		if h.ExistingHubURI != "" {
+			n = b.ref(Hub, lastSegment(h.ExistingHubURI), lastSegment(h.ExistingHubURI))
+		} else {
+			n = b.node(Hub, h.Name, h.Name, file, map[string]string{
+				"project":         h.ProjectID,
+				"policy_mode":     h.PolicyMode,
+				"preset_topology": h.PresetTopology,
+			}, "google_network_connectivity_hub/"+h.Name)
+		}
+		// Like the stage, spokes attach to the first hub of their file.
+		if i == 0 {
+			hub = n
+		}
+	}
+	for _, s := range v.Spokes {
+		n := b.node(Spoke, s.Name, s.Name, file, map[string]string{
+			"type":     s.Type,
+			"project":  s.ProjectID,
+			"location": s.Location,
+			"group":    s.Group,
+		}, "google_network_connectivity_spoke/"+s.Name)
+		if hub != nil {
+			b.g.link(n, hub, SpokeOf)
+		}
+		switch s.Type {
+		case "linked_vpc_network", "linked_producer_vpc_network":
+			if network := b.network(s.URI); network != nil {
+				b.g.link(n, network, Attached)
+			}
+		case "linked_interconnect_attachments":
+			for _, uri := range s.URIs {
+				b.g.link(n, b.ref(VLANAttachment, lastSegment(uri), lastSegment(uri)), Attached)
+			}
+		case "linked_vpn_tunnels":
+			for _, uri := range s.URIs {
+				b.g.link(n, b.ref(VPNTunnel, lastSegment(uri), lastSegment(uri)), Attached)
+			}
+		}
+	}
+}
+
+func (b *builder) firewallPolicy(v *config.FirewallPolicyStruct, file string) {
+	n := b.node(FirewallPolicy, v.Name, v.Name, file, map[string]string{"region": v.Region}, "google_compute_network_firewall_policy/"+v.Name)
+	for _, key := range slices.Sorted(maps.Keys(v.Attachments)) {
+		if network := b.network(v.Attachments[key]); network != nil {
+			b.g.link(n, network, Applies)
+		}
+	}
+}
+
+func (b *builder) cloudSQL(v *config.CloudSQLStruct, file string) {
+	conn := v.NetworkConfig.Connectivity
+	attrs := map[string]string{"project": v.ProjectID, "region": v.Region, "version": v.DatabaseVersion}
+	if len(conn.PSCAllowedConsumerProjects) > 0 {
+		attrs["psc"] = "true"
+	}
+	n := b.node(CloudSQL, v.Name, v.Name, file, attrs, "google_sql_database_instance/"+v.Name)
+	if conn.PSAConfig != nil {
+		if network := b.network(conn.PSAConfig.PrivateNetwork); network != nil {
+			b.g.link(n, network, PSA)
+		}
+	}
+}
+
+func (b *builder) alloyDB(v *config.AlloyDBStruct, file string) {
+	attrs := map[string]string{"project": v.ProjectID, "region": v.Region}
+	psc := strings.EqualFold(v.ConnectivityOptions, "psc")
+	if psc {
+		attrs["psc"] = "true"
+	}
+	n := b.node(AlloyDB, v.ClusterID, v.ClusterID, file, attrs,
+		"google_alloydb_cluster/"+v.ClusterID, "google_alloydb_instance/"+v.PrimaryInstance.InstanceID)
+	if network := b.network(v.NetworkID); network != nil && !psc {
+		b.g.link(n, network, PSA)
+	}
+}
+
+func (b *builder) mrc(v *config.MRCStruct, file string) {
+	n := b.node(MRC, v.InstanceName, v.InstanceName, file, map[string]string{"project": v.ProjectID, "region": v.Region}, "google_redis_cluster/"+v.InstanceName)
+	// The cluster creates its PSC endpoints in the network through a
+	// service connection policy.
+	if network := b.network(v.NetworkID); network != nil {
+		b.g.link(network, n, PSC)
+	}
+}
+
+func (b *builder) gke(v *config.GKEConfig, file string) {
+	n := b.node(GKE, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "region": v.Region}, "google_container_cluster/"+v.Name)
+	b.attach(n, v.Network, v.Subnetwork, v.Region)
+}
+
+func (b *builder) vectorSearch(v *config.VectorSearchStruct, file string) {
+	attrs := map[string]string{"project": v.ProjectID, "region": v.Region, "endpoint": v.IndexEndpointDisplayName}
+	if v.PrivateServiceConnectConfig != nil {
+		attrs["psc"] = "true"
+	}
+	n := b.node(VectorSearch, v.IndexDisplayName, v.IndexDisplayName, file, attrs,
+		"google_vertex_ai_index/"+v.IndexDisplayName, "google_vertex_ai_index_endpoint/"+v.IndexEndpointDisplayName)
+	if network := b.network(v.IndexEndpointNetwork); network != nil {
+		b.g.link(n, network, PSA)
+	}
+}
+
+func (b *builder) onlineEndpoint(v *config.EndpointConfig, file string) {
+	attrs := map[string]string{"project": v.Project, "location": v.Location}
+	if v.PrivateServiceConnectConfig != nil {
+		attrs["psc"] = "true"
+	}
+	n := b.node(OnlineEndpoint, v.Name, v.Name, file, attrs, "google_vertex_ai_endpoint/"+v.Name)
+	if network := b.network(v.Network); network != nil {
+		b.g.link(n, network, PSA)
+	}
+}
+
+func (b *builder) pscEndpoints(v *config.ProducerConnectivityVars, file string) {
+	for i, e := range v.PSCEndpoints {
+		// The forwarding rule and address names of the psc_forwarding_rule
+		// module.
+		suffix := fmt.Sprintf("custom-%d", i)
+		var producer *Node
+		switch {
+		case e.ProducerCloudSQL != nil && e.ProducerCloudSQL.InstanceName != "":
+			suffix = e.ProducerCloudSQL.InstanceName
+			producer = b.ref(CloudSQL, suffix, suffix)
+		case e.ProducerAlloyDB != nil && e.ProducerAlloyDB.InstanceName != "":
+			suffix = e.ProducerAlloyDB.InstanceName
+			producer = b.ref(AlloyDB, e.ProducerAlloyDB.ClusterID, e.ProducerAlloyDB.ClusterID)
+		case e.Target != "":
+			producer = b.ref(ServiceAttachment, lastSegment(e.Target), lastSegment(e.Target))
+		}
+		name := "psc-forwarding-rule-" + suffix
+		n := b.node(PSCEndpoint, e.NetworkName+"/"+lastSegment(e.Producer()), name, file,
+			map[string]string{"project": e.EndpointProjectID, "region": e.Region, "ip": e.IPAddressLiteral},
+			"google_compute_forwarding_rule/"+name, "google_compute_address/psc-compute-address-"+suffix)
+		b.attach(n, e.NetworkName, e.SubnetworkName, e.Region)
+		if producer != nil {
+			b.g.link(n, producer, PSC)
+		}
+	}
+}
+
+func (b *builder) instance(v *config.VMInstanceConfig, file string) {
+	n := b.node(Instance, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "zone": v.Zone}, "google_compute_instance/"+v.Name)
+	b.attach(n, v.Network, v.Subnetwork, regionOf(v.Zone))
+}
+
+func (b *builder) mig(v *config.MIGConfig, file string) {
+	n := b.node(MIG, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "location": v.Location},
+		"google_compute_region_instance_group_manager/"+v.Name, "google_compute_instance_group_manager/"+v.Name)
+	b.attach(n, v.VPCName, v.SubnetworkName, regionOf(v.Location))
+}
+
+func (b *builder) umig(v *config.UMIGConfig, file string) {
+	n := b.node(UMIG, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "zone": v.Zone}, "google_compute_instance_group/"+v.Name)
+	b.attach(n, v.Network, "", "")
+	for _, ref := range v.Instances {
+		b.g.link(n, b.ref(Instance, lastSegment(ref), lastSegment(ref)), Member)
+	}
+}
+
+func (b *builder) workbench(v *config.WorkbenchConfig, file string) {
+	n := b.node(Workbench, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "location": v.Location}, "google_workbench_instance/"+v.Name)
+	for _, nic := range v.GCESetup.NetworkInterfaces {
+		b.attach(n, nic.Network, nic.Subnet, regionOf(v.Location))
+	}
+}
+
+func (b *builder) cloudRun(v *config.CloudRunStruct, kind, file string) {
+	n := b.node(CloudRun, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "region": v.Region, "type": kind},
+		"google_cloud_run_v2_"+kind+"/"+v.Name)
+	if c := v.VPCConnectorCreate; c != nil {
+		name := stringOf(c, "name")
+		if name == "" {
+			name = v.Name
+		}
+		connector := b.node(Connector, name, name, file, map[string]string{"region": v.Region, "cidr": stringOf(c, "ip_cidr_range")}, "google_vpc_access_connector/"+name)
+		b.attach(connector, stringOf(c, "network"), stringOf(mapOf(c, "subnet"), "name"), v.Region)
+		b.g.link(n, connector, Attached)
+	}
+	access := mapOf(v.Revision, "vpc_access")
+	if ref := stringOf(access, "connector"); ref != "" {
+		b.g.link(n, b.ref(Connector, lastSegment(ref), lastSegment(ref)), Attached)
+	}
+	if s := b.subnet(nil, stringOf(access, "subnet"), v.Region); s != nil {
+		b.g.link(n, s, Attached)
+	}
+}
+
+func (b *builder) connector(v *config.VPCAccessConnectorConfig, file string) {
+	n := b.node(Connector, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "region": v.Region, "cidr": v.IPCIDRRange}, "google_vpc_access_connector/"+v.Name)
+	b.attach(n, v.Network, v.SubnetName, v.Region)
+}
+
+func (b *builder) externalNLB(v *config.NetworkLoadBalancerConfig, file string) {
+	n := b.node(LoadBalancer, v.Name, v.Name, file, map[string]string{"project": v.ProjectID, "region": v.Region, "type": "external-nlb"},
+		"google_compute_region_backend_service/"+v.Name)
+	for _, be := range v.Backends {
+		b.g.link(n, b.group(be.GroupName), Backend)
+	}
+}
+
+func (b *builder) internalNLB(v *config.InternalNetworkLoadBalancerConfig, file string) {
+	attrs := map[string]string{"project": v.Project, "region": v.Region, "type": "internal-nlb"}
+	if v.IsMirroringCollector != nil && *v.IsMirroringCollector {
+		attrs["mirroring_collector"] = "true"
+	}
+	if v.ForwardingRule != nil {
+		attrs["ip"] = v.ForwardingRule.Address
+	}
+	// The forwarding rule is named after the load balancer.
+	n := b.node(LoadBalancer, v.Name, v.Name, file, attrs,
+		"google_compute_region_backend_service/"+v.Name, "google_compute_forwarding_rule/"+v.Name)
+	b.attach(n, v.Network, v.Subnetwork, v.Region)
+	for _, be := range v.Backends {
+		b.g.link(n, b.group(be.GroupName), Backend)
+	}
+}
+
+func (b *builder) alb(v *config.LoadBalancerConfig, file string) {
+	n := b.node(LoadBalancer, v.Name, v.Name, file, map[string]string{"project": v.Project, "type": "external-alb"},
+		"google_compute_url_map/"+v.Name, "google_compute_global_forwarding_rule/"+v.Name)
+	for _, key := range slices.Sorted(maps.Keys(v.Backends)) {
+		for _, g := range v.Backends[key].Groups {
+			b.g.link(n, b.group(g.Group), Backend)
+		}
+	}
+}
+
+// outOfBand adds the mirroring deployment group, deployments, endpoint
+// group and endpoint associations of an Out-Of-Band file.
+func (b *builder) outOfBand(doc map[string]any, file string) {
+	var group *Node
+	if dg := mapOf(doc, "deployment_group"); created(dg) {
+		name := stringOf(dg, "name")
+		group = b.node(MirroringGroup, name, name, file, map[string]string{"project": stringOf(dg, "deployment_group_project_id")},
+			"google_network_security_mirroring_deployment_group/"+name)
+		if network := b.network(stringOf(dg, "producer_network_link")); network != nil {
+			b.g.link(group, network, Attached)
+		}
+	} else if id := stringOf(doc, "existing_deployment_group_id"); id != "" {
+		group = b.ref(MirroringGroup, lastSegment(id), lastSegment(id))
+	}
+	for _, d := range listOf(doc, "deployments") {
+		name := stringOf(d, "name")
+		n := b.node(MirroringDeployment, name, name, file, map[string]string{"project": stringOf(d, "deployment_project_id"), "location": stringOf(d, "location")},
+			"google_network_security_mirroring_deployment/"+name)
+		if group != nil {
+			b.g.link(n, group, Member)
+		}
+		if link := stringOf(d, "forwarding_rule_link"); link != "" {
+			// Internal passthrough load balancers name their forwarding
+			// rule after themselves.
+			collector := b.g.Node(LoadBalancer + ":" + lastSegment(link))
+			if collector == nil {
+				collector = b.ref(ForwardingRule, lastSegment(link), lastSegment(link))
+			}
+			b.g.link(n, collector, Collector)
+		}
+	}
+	var endpoint *Node
+	if eg := mapOf(doc, "endpoint_group"); created(eg) {
+		name := stringOf(eg, "name")
+		endpoint = b.node(MirroringEndpoint, name, name, file, map[string]string{"project": stringOf(eg, "endpoint_group_project_id")},
+			"google_network_security_mirroring_endpoint_group/"+name)
+		if group != nil {
+			b.g.link(endpoint, group, Deployment)
+		}
+	} else if id := stringOf(doc, "existing_endpoint_group_id"); id != "" {
+		endpoint = b.ref(MirroringEndpoint, lastSegment(id), lastSegment(id))
+	}
+	if endpoint == nil {
+		return
+	}
+	networks := []string{stringOf(doc, "consumer_network_link")}
+	for _, a := range listOf(doc, "endpoint_associations") {
+		networks = append(networks, stringOf(a, "consumer_network_link"))
+	}
+	for _, ref := range networks {
+		if network := b.network(ref); network != nil {
+			b.g.link(network, endpoint, Mirrors)
+		}
+	}
+}
+
+// securityProfile adds the security profile group of a SecurityProfile
+// file, linked to the endpoint group of its custom mirroring profile.
+func (b *builder) securityProfile(doc map[string]any, file string) {
+	spg := mapOf(doc, "security_profile_group")
+	if !created(spg) {
+		return
+	}
+	name := stringOf(spg, "name")
+	n := b.node(SecurityProfileGroup, name, name, file, nil, "google_network_security_security_profile_group/"+name)
+	profile := mapOf(mapOf(doc, "security_profile"), "custom_mirroring_profile")
+	if ref := stringOf(profile, "mirroring_endpoint_group"); ref != "" {
+		b.g.link(n, b.ref(MirroringEndpoint, lastSegment(ref), lastSegment(ref)), Mirrors)
+	}
+}
+
+// mirroringRule adds the packet mirroring rule of a PacketMirroringRule file.
+func (b *builder) mirroringRule(doc map[string]any, file string) {
+	policy := stringOf(doc, "firewall_policy_name")
+	priority := stringOf(doc, "priority")
+	if policy == "" {
+		return
+	}
+	n := b.node(MirroringRule, policy+"/"+priority, policy+"/"+priority, file,
+		map[string]string{"direction": stringOf(doc, "direction"), "action": stringOf(doc, "action")})
+	b.g.link(n, b.ref(FirewallPolicy, lastSegment(policy), lastSegment(policy)), Applies)
+	if ref := stringOf(doc, "security_profile_group"); ref != "" {
+		b.g.link(n, b.ref(SecurityProfileGroup, lastSegment(ref), lastSegment(ref)), Mirrors)
+	}
+}
+
+func mapOf(m map[string]any, key string) map[string]any {
+	v, _ := m[key].(map[string]any)
+	return v
+}
+
+func listOf(m map[string]any, key string) []map[string]any {
+	items, _ := m[key].([]any)
+	var out []map[string]any
+	for _, item := range items {
+		if v, ok := item.(map[string]any); ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func stringOf(m map[string]any, key string) string {
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// created reports whether a block of an NSI file is set and not disabled
+// with create: false.
+func created(block map[string]any) bool {
+	if block == nil {
+		return false
+	}
+	create, ok := block["create"].(bool)
+	return !ok || create
+}
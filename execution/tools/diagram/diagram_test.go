@@ -0,0 +1,228 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagram
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+func buildTestdata(t *testing.T) *Graph {
+	t.Helper()
+	tree, err := config.LoadTree("testdata")
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %v", err)
+	}
+	return Build(tree)
+}
+
+func enrichFile(t *testing.T, g *Graph, name string) int {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", name, err)
+	}
+	n, err := Enrich(g, data)
+	if err != nil {
+		t.Fatalf("Enrich(%s) failed: %v", name, err)
+	}
+	return n
+}
+
+func TestBuild(t *testing.T) {
+	g := buildTestdata(t)
+	testCases := []struct {
+		name         string
+		from, to     string
+		kind         string
+		wantExternal []string
+	}{
+		{name: "Subnet", from: "network:vpc-a", to: "subnet:vpc-a/us-east1/tools", kind: Contains},
+		{name: "Spoke", from: "spoke:spoke-vpc-a", to: "hub:hub-a", kind: SpokeOf},
+		{name: "ProducerSpoke", from: "spoke:spoke-producer", to: "network:servicenetworking", kind: Attached, wantExternal: []string{"network:servicenetworking"}},
+		{name: "PSA", from: "cloudsql:sql-psa", to: "network:vpc-a", kind: PSA},
+		{name: "PSCEndpointSubnet", from: "psc_endpoint:vpc-a/sql-psc", to: "subnet:vpc-a/us-central1/app", kind: Attached},
+		{name: "PSCEndpointCloudSQL", from: "psc_endpoint:vpc-a/sql-psc", to: "cloudsql:sql-psc", kind: PSC},
+		{name: "PSCEndpointAlloyDB", from: "psc_endpoint:vpc-a/primary-1", to: "alloydb:cluster-1", kind: PSC},
+		{
+			name:         "PSCEndpointTarget",
+			from:         "psc_endpoint:vpc-a/partner-sa",
+			to:           "service_attachment:partner-sa",
+			kind:         PSC,
+			wantExternal: []string{"service_attachment:partner-sa"},
+		},
+		{name: "UMIGMember", from: "umig:umig-1", to: "instance:vm-2", kind: Member},
+		{name: "CloudRunConnector", from: "cloud_run:service-1", to: "connector:connector-1", kind: Attached},
+		{name: "Backend", from: "load_balancer:nlb-1", to: "umig:umig-1", kind: Backend},
+		{name: "UnknownBackend", from: "load_balancer:nlb-1", to: "instance_group:legacy-group", kind: Backend, wantExternal: []string{"instance_group:legacy-group"}},
+		{name: "Collector", from: "mirroring_deployment:deployment-1", to: "load_balancer:collector", kind: Collector},
+		{name: "Mirrors", from: "network:consumer-vpc", to: "mirroring_endpoint_group:eg-1", kind: Mirrors, wantExternal: []string{"network:consumer-vpc"}},
+		{name: "Rule", from: "mirroring_rule:consumer-policy/100", to: "firewall_policy:consumer-policy", kind: Applies, wantExternal: []string{"firewall_policy:consumer-policy"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := Edge{From: tc.from, To: tc.to, Kind: tc.kind}
+			found := false
+			for _, e := range g.Edges {
+				found = found || e == want
+			}
+			if !found {
+				t.Errorf("Build() has no edge %+v", want)
+			}
+			for _, id := range []string{tc.from, tc.to} {
+				n := g.Node(id)
+				if n == nil {
+					t.Fatalf("Build() has no node %q", id)
+				}
+				wantExternal := false
+				for _, e := range tc.wantExternal {
+					wantExternal = wantExternal || e == id
+				}
+				if n.External != wantExternal {
+					t.Errorf("Node %q External = %v, want %v", id, n.External, wantExternal)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildStable(t *testing.T) {
+	var got [2]bytes.Buffer
+	for i := range got {
+		if err := WriteJSON(&got[i], buildTestdata(t)); err != nil {
+			t.Fatalf("WriteJSON() failed: %v", err)
+		}
+	}
+	if !bytes.Equal(got[0].Bytes(), got[1].Bytes()) {
+		t.Errorf("Build() is not stable.\nfirst:\n%s\nsecond:\n%s", got[0].String(), got[1].String())
+	}
+}
+
+func TestEnrichPlan(t *testing.T) {
+	g := buildTestdata(t)
+	if n := enrichFile(t, g, "plan.json"); n != 6 {
+		t.Errorf("Enrich() annotated %d nodes, want 6", n)
+	}
+	got := map[string]string{}
+	for _, n := range g.Nodes {
+		if change := n.Attrs["change"]; change != "" {
+			got[n.ID] = change
+		}
+	}
+	want := map[string]string{
+		"network:vpc-a":               NoOp,
+		"subnet:vpc-a/us-east1/tools": Update,
+		"cloudsql:sql-psc":            Create,
+		"psc_endpoint:vpc-a/sql-psc":  Create,
+		"instance:vm-1":               Replace,
+		"mig:mig-1":                   Delete,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Enrich() changes mismatch.\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEnrichState(t *testing.T) {
+	g := buildTestdata(t)
+	if n := enrichFile(t, g, "state.json"); n != 3 {
+		t.Errorf("Enrich() annotated %d nodes, want 3", n)
+	}
+	testCases := []struct {
+		id     string
+		wantIP string
+	}{
+		{id: "instance:vm-1", wantIP: "10.0.0.2"},
+		{id: "instance:vm-2", wantIP: "10.0.0.3"},
+		{id: "psc_endpoint:vpc-a/primary-1", wantIP: "10.0.0.51"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			n := g.Node(tc.id)
+			if n.Attrs["deployed"] != "true" {
+				t.Errorf("Node %q is not deployed", tc.id)
+			}
+			if n.Attrs["ip"] != tc.wantIP {
+				t.Errorf("Node %q ip = %q, want %q", tc.id, n.Attrs["ip"], tc.wantIP)
+			}
+		})
+	}
+	// Data sources are not deployed by the stages.
+	if g.Node("network:vpc-a").Attrs["deployed"] != "" {
+		t.Errorf("Node %q is deployed by a data source", "network:vpc-a")
+	}
+}
+
+func TestEnrichInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "NotJSON", data: "not json"},
+		{name: "NeitherPlanNorState", data: `{"format_version": "1.0"}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Enrich(&Graph{}, []byte(tc.data)); err == nil {
+				t.Errorf("Enrich(%q) succeeded, want an error", tc.data)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	testCases := []struct {
+		format string
+		golden string
+	}{
+		{format: Mermaid, golden: "diagram.golden.mmd"},
+		{format: DOT, golden: "diagram.golden.dot"},
+		{format: JSON, golden: "diagram.golden.json"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			g := buildTestdata(t)
+			enrichFile(t, g, "plan.json")
+			var buf bytes.Buffer
+			if err := Write(&buf, g, tc.format); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+			got := buf.Bytes()
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Write() mismatch with %s.\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+	if err := Write(&bytes.Buffer{}, &Graph{}, "svg"); err == nil {
+		t.Errorf("Write() with format svg succeeded, want an error")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagram
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Changes set by Enrich from a plan, in increasing order of significance.
+const (
+	NoOp    = "no-op"
+	Create  = "create"
+	Update  = "update"
+	Delete  = "delete"
+	Replace = "replace"
+)
+
+var changeOrder = []string{NoOp, Create, Update, Delete, Replace}
+
+// plan holds the parts of the terraform show -json output of a plan or a
+// state that Enrich uses.
+type plan struct {
+	ResourceChanges []struct {
+		Type   string `json:"type"`
+		Change struct {
+			Actions []string       `json:"actions"`
+			Before  map[string]any `json:"before"`
+			After   map[string]any `json:"after"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+	Values *struct {
+		RootModule module `json:"root_module"`
+	} `json:"values"`
+}
+
+type module struct {
+	Resources []struct {
+		Type   string         `json:"type"`
+		Mode   string         `json:"mode"`
+		Values map[string]any `json:"values"`
+	} `json:"resources"`
+	ChildModules []module `json:"child_modules"`
+}
+
+// idAttributes are the attributes holding the name of a resource, which
+// depend on its type.
+var idAttributes = []string{
+	"name",
+	"cluster_id",
+	"instance_id",
+	"display_name",
+	"mirroring_deployment_id",
+	"mirroring_deployment_group_id",
+	"mirroring_endpoint_group_id",
+}
+
+// keys returns the TYPE/NAME keys of a resource, as used by Node.Resources.
+func keys(typ string, values map[string]any) []string {
+	var out []string
+	for _, attr := range idAttributes {
+		name, _ := values[attr].(string)
+		if name == "" {
+			continue
+		}
+		if region, _ := values["region"].(string); typ == "google_compute_subnetwork" && region != "" {
+			name = region + "/" + name
+		}
+		out = append(out, typ+"/"+name)
+	}
+	return out
+}
+
+// Enrich annotates the nodes of g with the output of terraform show -json,
+// for a plan or a state. A plan sets the "change" attribute of the nodes
+// whose resources change; a state sets "deployed" and, when the resource has
+// one, the "ip" attribute. Enrich returns the number of nodes annotated.
+func Enrich(g *Graph, data []byte) (int, error) {
+	var p plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return 0, err
+	}
+	byKey := map[string][]*Node{}
+	for _, n := range g.Nodes {
+		for _, k := range n.Resources {
+			byKey[k] = append(byKey[k], n)
+		}
+	}
+	matched := map[*Node]bool{}
+	match := func(typ string, values map[string]any, fn func(n *Node)) {
+		for _, k := range keys(typ, values) {
+			for _, n := range byKey[k] {
+				fn(n)
+				matched[n] = true
+			}
+		}
+	}
+	switch {
+	case p.ResourceChanges != nil:
+		for _, rc := range p.ResourceChanges {
+			change := planChange(rc.Change.Actions)
+			values := rc.Change.After
+			if values == nil {
+				values = rc.Change.Before
+			}
+			match(rc.Type, values, func(n *Node) {
+				if slices.Index(changeOrder, change) > slices.Index(changeOrder, n.Attrs["change"]) {
+					n.Attrs["change"] = change
+				}
+			})
+		}
+	case p.Values != nil:
+		var walk func(m module)
+		walk = func(m module) {
+			for _, r := range m.Resources {
+				if r.Mode == "data" {
+					continue
+				}
+				match(r.Type, r.Values, func(n *Node) {
+					n.Attrs["deployed"] = "true"
+					if ip := address(r.Values); ip != "" {
+						n.Attrs["ip"] = ip
+					}
+				})
+			}
+			for _, c := range m.ChildModules {
+				walk(c)
+			}
+		}
+		walk(p.Values.RootModule)
+	default:
+		return 0, errors.New("neither a plan nor a state: no resource_changes or values")
+	}
+	return len(matched), nil
+}
+
+// planChange returns the change of a resource from its actions.
+func planChange(actions []string) string {
+	switch strings.Join(actions, ",") {
+	case "create":
+		return Create
+	case "update":
+		return Update
+	case "delete":
+		return Delete
+	case "delete,create", "create,delete":
+		return Replace
+	}
+	return NoOp
+}
+
+// address returns the internal IP address of a resource of a state.
+func address(values map[string]any) string {
+	for _, attr := range []string{"ip_address", "address", "private_ip_address"} {
+		if ip, ok := values[attr].(string); ok && ip != "" {
+			return ip
+		}
+	}
+	if nics, ok := values["network_interface"].([]any); ok && len(nics) > 0 {
+		if nic, ok := nics[0].(map[string]any); ok {
+			if ip, ok := nic["network_ip"].(string); ok {
+				return ip
+			}
+		}
+	}
+	return ""
+}
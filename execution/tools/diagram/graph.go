@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diagram builds the architecture graph of a configuration tree:
+// networks and subnets, NCC hubs and spokes, producers and their PSC
+// endpoints, consumers, load balancers and packet mirroring. The graph is
+// rendered as Mermaid, Graphviz DOT or JSON.
+//
+// Node IDs are derived from the kind and the names of the resources only,
+// and nodes and edges are sorted, so the renderings of two revisions of a
+// tree can be diffed.
+package diagram
+
+import (
+	"sort"
+)
+
+// Node kinds.
+const (
+	Network              = "network"
+	Subnet               = "subnet"
+	NAT                  = "nat"
+	VPNGateway           = "vpn_gateway"
+	VLANAttachment       = "vlan_attachment"
+	Hub                  = "hub"
+	Spoke                = "spoke"
+	CloudSQL             = "cloudsql"
+	AlloyDB              = "alloydb"
+	MRC                  = "mrc"
+	GKE                  = "gke"
+	VectorSearch         = "vectorsearch"
+	OnlineEndpoint       = "online_endpoint"
+	PSCEndpoint          = "psc_endpoint"
+	ServiceAttachment    = "service_attachment"
+	Instance             = "instance"
+	MIG                  = "mig"
+	UMIG                 = "umig"
+	Workbench            = "workbench"
+	CloudRun             = "cloud_run"
+	Connector            = "connector"
+	LoadBalancer         = "load_balancer"
+	ForwardingRule       = "forwarding_rule"
+	MirroringDeployment  = "mirroring_deployment"
+	MirroringGroup       = "mirroring_deployment_group"
+	MirroringEndpoint    = "mirroring_endpoint_group"
+	MirroringRule        = "mirroring_rule"
+	FirewallPolicy       = "firewall_policy"
+	SecurityProfileGroup = "security_profile_group"
+	InstanceGroup        = "instance_group"
+	VPNTunnel            = "vpn_tunnel"
+)
+
+// Edge kinds.
+const (
+	Contains   = "contains"
+	Attached   = "attached"
+	SpokeOf    = "spoke"
+	PSA        = "psa"
+	PSC        = "psc"
+	Backend    = "backend"
+	Member     = "member"
+	Mirrors    = "mirrors"
+	Collector  = "collector"
+	Deployment = "deployment"
+	Applies    = "applies"
+)
+
+// Node is a resource of the architecture.
+type Node struct {
+	// ID is stable across revisions: it is derived from Kind and the names
+	// of the resource and of its parents.
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Stage string `json:"stage,omitempty"`
+	File  string `json:"file,omitempty"`
+	// External is set for resources referenced but not declared by the
+	// tree, e.g. a network created outside of it.
+	External bool              `json:"external,omitempty"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	// Resources are the Terraform resources of the node, as TYPE/NAME, used
+	// to match plan and state resources.
+	Resources []string `json:"-"`
+}
+
+// Edge links two nodes.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is the architecture graph of a tree.
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []Edge  `json:"edges"`
+	index map[string]*Node
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	return g.index[id]
+}
+
+// add adds a node, or returns the existing node of the same ID. An existing
+// external node is replaced by a declared one.
+func (g *Graph) add(n *Node) *Node {
+	if g.index == nil {
+		g.index = map[string]*Node{}
+	}
+	if n.Attrs == nil {
+		n.Attrs = map[string]string{}
+	}
+	if old, ok := g.index[n.ID]; ok {
+		if old.External && !n.External {
+			*old = *n
+		}
+		return old
+	}
+	g.index[n.ID] = n
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+// link adds an edge, unless it already exists.
+func (g *Graph) link(from, to *Node, kind string) {
+	e := Edge{From: from.ID, To: to.ID, Kind: kind}
+	for _, o := range g.Edges {
+		if o == e {
+			return
+		}
+	}
+	g.Edges = append(g.Edges, e)
+}
+
+// sort orders the nodes by ID and the edges by their ends.
+func (g *Graph) sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+}
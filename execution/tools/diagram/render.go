@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagram
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats accepted by Write.
+const (
+	Mermaid = "mermaid"
+	DOT     = "dot"
+	JSON    = "json"
+)
+
+// changeColors are the fill colors of the nodes a plan changes.
+var changeColors = map[string]string{
+	Create:  "#d9f2d9",
+	Update:  "#fff2cc",
+	Delete:  "#f8d7da",
+	Replace: "#fde2c8",
+}
+
+// Write renders g in the given format.
+func Write(w io.Writer, g *Graph, format string) error {
+	switch format {
+	case Mermaid:
+		return WriteMermaid(w, g)
+	case DOT:
+		return WriteDOT(w, g)
+	case JSON:
+		return WriteJSON(w, g)
+	}
+	return fmt.Errorf("unknown format %q, want %s, %s or %s", format, Mermaid, DOT, JSON)
+}
+
+// WriteJSON writes the graph as indented JSON.
+func WriteJSON(w io.Writer, g *Graph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// label returns the lines of the label of a node: its kind, its name and
+// its address range or IP address when known.
+func label(n *Node) []string {
+	lines := []string{n.Kind, n.Name}
+	for _, attr := range []string{"cidr", "ip"} {
+		if v := n.Attrs[attr]; v != "" {
+			lines = append(lines, v)
+		}
+	}
+	return lines
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. External nodes are
+// dashed and the nodes a plan changes are filled by change.
+func WriteMermaid(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	ids := mermaidIDs(g)
+	fmt.Fprintln(bw, "flowchart LR")
+	for _, n := range g.Nodes {
+		lines := label(n)
+		for i, l := range lines {
+			lines[i] = strings.ReplaceAll(l, `"`, "#quot;")
+		}
+		fmt.Fprintf(bw, "  %s[\"%s\"]\n", ids[n.ID], strings.Join(lines, "<br/>"))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  %s -->|%s| %s\n", ids[e.From], e.Kind, ids[e.To])
+	}
+	fmt.Fprintln(bw, "  classDef external stroke-dasharray: 5 5")
+	for _, change := range changeOrder[1:] {
+		fmt.Fprintf(bw, "  classDef %s fill:%s\n", change, changeColors[change])
+	}
+	for _, n := range g.Nodes {
+		if n.External {
+			fmt.Fprintf(bw, "  class %s external\n", ids[n.ID])
+		}
+		if change := n.Attrs["change"]; changeColors[change] != "" {
+			fmt.Fprintf(bw, "  class %s %s\n", ids[n.ID], change)
+		}
+	}
+	return bw.Flush()
+}
+
+// mermaidIDs maps the node IDs to Mermaid identifiers, which only hold
+// letters, digits and underscores. Identifiers that would collide get a
+// numeric suffix, in node order.
+func mermaidIDs(g *Graph) map[string]string {
+	ids := map[string]string{}
+	used := map[string]bool{}
+	for _, n := range g.Nodes {
+		id := strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, n.ID)
+		for i, base := 2, id; used[id]; i++ {
+			id = fmt.Sprintf("%s_%d", base, i)
+		}
+		used[id] = true
+		ids[n.ID] = id
+	}
+	return ids
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. External nodes are
+// dashed and the nodes a plan changes are filled by change.
+func WriteDOT(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph architecture {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, "  node [shape=box, fontname=\"Helvetica\"];")
+	fmt.Fprintln(bw, "  edge [fontname=\"Helvetica\", fontsize=10];")
+	for _, n := range g.Nodes {
+		attrs := []string{"label=" + dotQuote(strings.Join(label(n), "\n"))}
+		var style []string
+		if n.External {
+			style = append(style, "dashed")
+		}
+		if color := changeColors[n.Attrs["change"]]; color != "" {
+			style = append(style, "filled")
+			attrs = append(attrs, "fillcolor="+dotQuote(color))
+		}
+		if len(style) > 0 {
+			attrs = append(attrs, "style="+dotQuote(strings.Join(style, ",")))
+		}
+		fmt.Fprintf(bw, "  %s [%s];\n", dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Kind))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
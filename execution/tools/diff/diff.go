@@ -0,0 +1,317 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff computes a semantic diff between two configuration trees.
+// Instead of comparing lines it compares the named resources each stage
+// declares (instances, subnets, firewall rules, ...) field by field.
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+// Action is the kind of a change.
+type Action string
+
+const (
+	Added    Action = "added"
+	Removed  Action = "removed"
+	Modified Action = "modified"
+)
+
+// FieldChange is a change to a single field of a resource. Path uses dots for
+// map keys and brackets for list elements, e.g. "rules[tcp].ports".
+type FieldChange struct {
+	Path   string `json:"path"`
+	Action Action `json:"action"`
+	Old    any    `json:"old,omitempty"`
+	New    any    `json:"new,omitempty"`
+}
+
+// Change is an added, removed or modified resource.
+type Change struct {
+	Stage  string        `json:"stage"`
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Action Action        `json:"action"`
+	File   string        `json:"file"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// Report is the diff between two configuration trees.
+type Report struct {
+	Old     string   `json:"old"`
+	New     string   `json:"new"`
+	Changes []Change `json:"changes"`
+}
+
+// Empty reports whether the trees are semantically equal.
+func (r *Report) Empty() bool {
+	return len(r.Changes) == 0
+}
+
+// Trees compares two loaded configuration trees. Stages are reported in
+// run.sh order and resources in declaration order. When stages is not empty
+// only the named stages are compared.
+func Trees(oldTree, newTree *config.Tree, stages ...string) (*Report, error) {
+	report := &Report{Old: oldTree.Root, New: newTree.Root, Changes: []Change{}}
+	for _, stage := range config.Stages {
+		if len(stages) > 0 && !contains(stages, stage.Name) {
+			continue
+		}
+		changes, err := Stage(stage.Name, oldTree.Stage(stage.Name), newTree.Stage(stage.Name))
+		if err != nil {
+			return nil, err
+		}
+		report.Changes = append(report.Changes, changes...)
+	}
+	return report, nil
+}
+
+// Stage compares the configuration of one stage in two trees. Either side may
+// be nil when the stage is not configured in that tree.
+func Stage(name string, oldStage, newStage *config.StageConfig) ([]Change, error) {
+	oldResources, err := index(oldStage)
+	if err != nil {
+		return nil, err
+	}
+	newResources, err := index(newStage)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, o := range oldResources.list {
+		n, ok := newResources.byKey[o.key]
+		if !ok {
+			changes = append(changes, Change{Stage: name, Kind: o.Kind, Name: o.Name, Action: Removed, File: o.File})
+			continue
+		}
+		if fields := compare("", o.value, n.value, nil); len(fields) > 0 {
+			changes = append(changes, Change{Stage: name, Kind: n.Kind, Name: n.Name, Action: Modified, File: n.File, Fields: fields})
+		}
+	}
+	for _, n := range newResources.list {
+		if _, ok := oldResources.byKey[n.key]; !ok {
+			changes = append(changes, Change{Stage: name, Kind: n.Kind, Name: n.Name, Action: Added, File: n.File})
+		}
+	}
+	return changes, nil
+}
+
+type resource struct {
+	config.Resource
+	key   string
+	value any
+}
+
+type resourceIndex struct {
+	list  []*resource
+	byKey map[string]*resource
+}
+
+func index(s *config.StageConfig) (*resourceIndex, error) {
+	idx := &resourceIndex{byKey: map[string]*resource{}}
+	if s == nil {
+		return idx, nil
+	}
+	for _, r := range s.Resources() {
+		var value any
+		if err := config.Convert(r.Value, &value); err != nil {
+			return nil, fmt.Errorf("%s: %s %q: %w", r.File, r.Kind, r.Name, err)
+		}
+		res := &resource{Resource: r, key: r.Kind + "\x00" + r.Name, value: value}
+		// Resources sharing a name (often an unset one) are told apart by file.
+		if _, dup := idx.byKey[res.key]; dup {
+			res.key += "\x00" + r.File
+		}
+		idx.list = append(idx.list, res)
+		idx.byKey[res.key] = res
+	}
+	return idx, nil
+}
+
+// compare appends the differences between two generic values to changes.
+func compare(path string, oldValue, newValue any, changes []FieldChange) []FieldChange {
+	switch o := oldValue.(type) {
+	case map[string]any:
+		if n, ok := newValue.(map[string]any); ok {
+			return compareMaps(path, o, n, changes)
+		}
+	case []any:
+		if n, ok := newValue.([]any); ok {
+			return compareLists(path, o, n, changes)
+		}
+	}
+	if reflect.DeepEqual(oldValue, newValue) {
+		return changes
+	}
+	switch {
+	case oldValue == nil:
+		return append(changes, FieldChange{Path: path, Action: Added, New: newValue})
+	case newValue == nil:
+		return append(changes, FieldChange{Path: path, Action: Removed, Old: oldValue})
+	}
+	return append(changes, FieldChange{Path: path, Action: Modified, Old: oldValue, New: newValue})
+}
+
+func compareMaps(path string, o, n map[string]any, changes []FieldChange) []FieldChange {
+	keys := map[string]bool{}
+	for k := range o {
+		keys[k] = true
+	}
+	for k := range n {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		changes = compare(join(path, k), o[k], n[k], changes)
+	}
+	return changes
+}
+
+// compareLists compares scalar lists as sets and lists of maps by their
+// identifying key when every element has a distinct one, falling back to a
+// positional comparison.
+func compareLists(path string, o, n []any, changes []FieldChange) []FieldChange {
+	if scalars(o) && scalars(n) {
+		for _, v := range o {
+			if !containsValue(n, v) {
+				changes = append(changes, FieldChange{Path: path, Action: Removed, Old: v})
+			}
+		}
+		for _, v := range n {
+			if !containsValue(o, v) {
+				changes = append(changes, FieldChange{Path: path, Action: Added, New: v})
+			}
+		}
+		return changes
+	}
+	if key := listKey(o, n); key != "" {
+		oldByKey := keyed(o, key)
+		newByKey := keyed(n, key)
+		for _, e := range o {
+			k := keyOf(e, key)
+			changes = compare(fmt.Sprintf("%s[%s]", path, k), e, newByKey[k], changes)
+		}
+		for _, e := range n {
+			if k := keyOf(e, key); oldByKey[k] == nil {
+				changes = compare(fmt.Sprintf("%s[%s]", path, k), nil, e, changes)
+			}
+		}
+		return changes
+	}
+	for i := 0; i < len(o) || i < len(n); i++ {
+		var oe, ne any
+		if i < len(o) {
+			oe = o[i]
+		}
+		if i < len(n) {
+			ne = n[i]
+		}
+		changes = compare(fmt.Sprintf("%s[%d]", path, i), oe, ne, changes)
+	}
+	return changes
+}
+
+// identityKeys are the fields used, in order of preference, to match list
+// elements across trees.
+var identityKeys = []string{"name", "group_name", "group", "zone", "protocol", "network_url", "ipv4_address", "instance_id"}
+
+func listKey(lists ...[]any) string {
+	for _, key := range identityKeys {
+		usable := true
+		for _, l := range lists {
+			seen := map[string]bool{}
+			for _, e := range l {
+				k := keyOf(e, key)
+				if k == "" || seen[k] {
+					usable = false
+					break
+				}
+				seen[k] = true
+			}
+		}
+		if usable {
+			return key
+		}
+	}
+	return ""
+}
+
+func keyOf(e any, key string) string {
+	m, ok := e.(map[string]any)
+	if !ok {
+		return ""
+	}
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func keyed(l []any, key string) map[string]any {
+	m := make(map[string]any, len(l))
+	for _, e := range l {
+		m[keyOf(e, key)] = e
+	}
+	return m
+}
+
+func scalars(l []any) bool {
+	for _, e := range l {
+		switch e.(type) {
+		case map[string]any, []any:
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(l []any, v any) bool {
+	for _, e := range l {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(l []string, s string) bool {
+	for _, e := range l {
+		if strings.EqualFold(e, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func loadReport(t *testing.T) *Report {
+	t.Helper()
+	oldTree, err := config.LoadTree("testdata/old")
+	if err != nil {
+		t.Fatalf("Failed to load old tree: %v", err)
+	}
+	newTree, err := config.LoadTree("testdata/new")
+	if err != nil {
+		t.Fatalf("Failed to load new tree: %v", err)
+	}
+	report, err := Trees(oldTree, newTree)
+	if err != nil {
+		t.Fatalf("Failed to diff trees: %v", err)
+	}
+	return report
+}
+
+func TestTrees(t *testing.T) {
+	report := loadReport(t)
+	want := []Change{
+		{
+			Stage: "security/gce", Kind: "ingress_rule", Name: "allow-icmp", Action: Removed,
+			File: "security/gce.tfvars",
+		},
+		{
+			Stage: "security/gce", Kind: "ingress_rule", Name: "allow-ssh-https", Action: Modified,
+			File:   "security/gce.tfvars",
+			Fields: []FieldChange{{Path: "rules[tcp].ports", Action: Removed, Old: "22"}},
+		},
+		{
+			Stage: "producer/cloudsql", Kind: "instance", Name: "sql-1", Action: Modified,
+			File:   "producer/CloudSQL/config/sql-1.yaml",
+			Fields: []FieldChange{{Path: "tier", Action: Modified, Old: "db-f1-micro", New: "db-custom-2-7680"}},
+		},
+		{
+			Stage: "producer/cloudsql", Kind: "instance", Name: "sql-2", Action: Added,
+			File: "producer/CloudSQL/config/sql-2.yaml",
+		},
+	}
+	if !reflect.DeepEqual(report.Changes, want) {
+		t.Errorf("Trees() changes mismatch.\ngot:  %+v\nwant: %+v", report.Changes, want)
+	}
+}
+
+func TestTreesStageFilter(t *testing.T) {
+	oldTree, _ := config.LoadTree("testdata/old")
+	newTree, _ := config.LoadTree("testdata/new")
+	report, err := Trees(oldTree, newTree, "producer/cloudsql")
+	if err != nil {
+		t.Fatalf("Failed to diff trees: %v", err)
+	}
+	for _, c := range report.Changes {
+		if c.Stage != "producer/cloudsql" {
+			t.Errorf("Unexpected change for stage %q with -stage producer/cloudsql", c.Stage)
+		}
+	}
+}
+
+func TestTreesEqual(t *testing.T) {
+	tree, err := config.LoadTree("testdata/new")
+	if err != nil {
+		t.Fatalf("Failed to load tree: %v", err)
+	}
+	report, err := Trees(tree, tree)
+	if err != nil {
+		t.Fatalf("Failed to diff trees: %v", err)
+	}
+	if !report.Empty() {
+		t.Errorf("Expected no changes comparing a tree with itself, got %+v", report.Changes)
+	}
+}
+
+func TestCompareLists(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old, new []any
+		want     []FieldChange
+	}{
+		{
+			name: "ScalarListsAreSets",
+			old:  []any{"a", "b"},
+			new:  []any{"b", "a", "c"},
+			want: []FieldChange{{Path: "l", Action: Added, New: "c"}},
+		},
+		{
+			name: "MapsMatchedByName",
+			old:  []any{map[string]any{"name": "x", "v": 1}, map[string]any{"name": "y", "v": 1}},
+			new:  []any{map[string]any{"name": "y", "v": 2}, map[string]any{"name": "x", "v": 1}},
+			want: []FieldChange{{Path: "l[y].v", Action: Modified, Old: 1, New: 2}},
+		},
+		{
+			name: "MapsWithoutKeyByIndex",
+			old:  []any{map[string]any{"v": 1}},
+			new:  []any{map[string]any{"v": 1}, map[string]any{"v": 2}},
+			want: []FieldChange{{Path: "l[1]", Action: Added, New: map[string]any{"v": 2}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := compareLists("l", tc.old, tc.new, nil)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("compareLists() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := loadReport(t).WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"security/gce\n",
+		`  - ingress_rule "allow-icmp" (security/gce.tfvars)`,
+		`      rules[tcp].ports removed: "22"`,
+		`      tier changed: "db-f1-micro" -> "db-custom-2-7680"`,
+		`  + instance "sql-2" (producer/CloudSQL/config/sql-2.yaml)`,
+		"4 resource(s) changed: 1 added, 1 removed, 2 modified.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Text report is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := loadReport(t).WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON report does not decode: %v", err)
+	}
+	if len(decoded.Changes) != 4 || decoded.Changes[3].Action != Added {
+		t.Errorf("Unexpected JSON report: %s", buf.String())
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+var symbols = map[Action]string{Added: "+", Removed: "-", Modified: "~"}
+
+// WriteText writes a human readable report grouped by stage, e.g.
+//
+//	producer/cloudsql
+//	  ~ instance "sql-1" (producer/CloudSQL/config/sql-1.yaml)
+//	      tier changed: "db-f1-micro" -> "db-n1-standard-2"
+func (r *Report) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if r.Empty() {
+		fmt.Fprintf(bw, "No differences between %s and %s.\n", r.Old, r.New)
+		return bw.Flush()
+	}
+	counts := map[Action]int{}
+	stage := ""
+	for _, c := range r.Changes {
+		if c.Stage != stage {
+			if stage != "" {
+				fmt.Fprintln(bw)
+			}
+			stage = c.Stage
+			fmt.Fprintln(bw, stage)
+		}
+		counts[c.Action]++
+		fmt.Fprintf(bw, "  %s %s %q (%s)\n", symbols[c.Action], c.Kind, c.Name, c.File)
+		for _, f := range c.Fields {
+			switch f.Action {
+			case Added:
+				fmt.Fprintf(bw, "      %s added: %s\n", f.Path, format(f.New))
+			case Removed:
+				fmt.Fprintf(bw, "      %s removed: %s\n", f.Path, format(f.Old))
+			default:
+				fmt.Fprintf(bw, "      %s changed: %s -> %s\n", f.Path, format(f.Old), format(f.New))
+			}
+		}
+	}
+	fmt.Fprintf(bw, "\n%d resource(s) changed: %d added, %d removed, %d modified.\n",
+		len(r.Changes), counts[Added], counts[Removed], counts[Modified])
+	return bw.Flush()
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func format(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func loadTree(t *testing.T) *config.Tree {
+	t.Helper()
+	tree, err := config.LoadTree("testdata")
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %v", err)
+	}
+	return tree
+}
+
+func TestLintZones(t *testing.T) {
+	var got []string
+	for _, f := range LintZones(loadTree(t)) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		`WARNING networking/CloudDNS/DNSManagedZones/config/duplicate.yaml: zone "forward-zone": target 10.0.1.1 is listed twice [forwarding]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: zone "corp-internal-zone": domain "example.com" must end with a dot: "example.com." [trailing-dot]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "A db.example.com": name "db.example.com" has no trailing dot and is relative to the zone: it resolves to "db.example.com.example.com.", use "db.example.com." [trailing-dot]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "A www.other.com.": www.other.com. is outside the zone domain example.com. [outside-zone]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "CNAME app.example.com.": host name "web.example.com" must be fully qualified, with a trailing dot [rdata]`,
+		`WARNING networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "TXT app.example.com.": TTL 10 is below 30 seconds, resolvers will query the zone on most lookups [ttl]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "TXT app.example.com.": "\"unbalanced" has unbalanced quotes [rdata]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "CNAME example.com.": a CNAME cannot be at the zone apex [cname]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "CNAME example.com.": a CNAME record set holds a single record, not 2 [cname]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "MX mail.example.com.": "mail.example.com." is not in the PREFERENCE EXCHANGE format, e.g. "10 mail.example.com." [rdata]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "SRV _ldap._tcp.example.com.": port "70000" is not a number between 0 and 65535 [rdata]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "CAA example.com.": value pki.goog must be quoted [rdata]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "CAA example.com.": tag "policy" is not one of issue, issuewild, iodef, issuemail, issuevmc [rdata]`,
+		`WARNING networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "AAAA v6.example.com.": TTL 9999999 is above 604800 seconds (a week), changes will take long to propagate [ttl]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "AAAA v6.example.com.": "10.0.0.1" is not an IPv6 address [rdata]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "AAAA v6.example.com.": zone "corp-internal-zone" already has a record set of type AAAA for v6.example.com. [duplicate]`,
+		`WARNING networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "A dup.example.com.": record "192.0.2.1" is listed twice [duplicate]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "CNAME app.example.com.": a CNAME cannot coexist with other record sets of the same name: TXT [cname]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: recordset "CNAME example.com.": a CNAME cannot coexist with other record sets of the same name: CAA [cname]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: zone "forward-zone": zone "forward-zone" of project "host-project" is already defined in networking/CloudDNS/DNSManagedZones/config/duplicate.yaml [duplicate]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: zone "forward-zone": a forwarding zone must have private visibility [forwarding]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: zone "forward-zone": target "2001:db8::53" is not an IPv4 address [forwarding]`,
+		`INFO    networking/CloudDNS/DNSManagedZones/config/invalid.yaml: zone "forward-zone": target 8.8.8.8 is not an RFC 1918 address and is reached over the internet; set forwarding_path: private to reach it through the VPC network [forwarding]`,
+		`ERROR   networking/CloudDNS/DNSManagedZones/config/invalid.yaml: zone "forward-zone": target 127.0.0.1 is not a unicast address a name server can use [forwarding]`,
+		`WARNING networking/CloudDNS/DNSManagedZones/config/invalid.yaml: zone "forward-zone": record sets of a forwarding or peering zone are not served, queries are sent to the target [forwarding]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintZones() findings mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateRData(t *testing.T) {
+	testCases := []struct {
+		recordType string
+		rdata      string
+		wantErr    string
+	}{
+		{"A", "192.0.2.1", ""},
+		{"A", "2001:db8::1", `"2001:db8::1" is not an IPv4 address`},
+		{"AAAA", "::ffff:192.0.2.1", `"::ffff:192.0.2.1" is not an IPv6 address`},
+		{"CNAME", "target.example.com.", ""},
+		{"CNAME", "bad_host!.example.com.", `invalid host name: "bad_host!.example.com." has an invalid character '!'`},
+		{"MX", "10 mail.example.com.", ""},
+		{"MX", "x mail.example.com.", `preference "x" is not a number between 0 and 65535`},
+		{"SRV", "0 0 443 .", ""},
+		{"SRV", "1 2 3", `"1 2 3" is not in the PRIORITY WEIGHT PORT TARGET format, e.g. "10 5 5060 sip.example.com."`},
+		{"TXT", `"a \" quote"`, ""},
+		{"TXT", "v=spf1 -all", ""},
+		{"TXT", `"a" b`, `"\"a\" b" has text outside quotes`},
+		{"TXT", `"` + strings.Repeat("x", 256) + `"`, "string of 256 characters, the limit is 255"},
+		{"CAA", `128 issuewild ";"`, ""},
+		{"CAA", `256 issue "pki.goog"`, `flags "256" is not a number between 0 and 255`},
+		{"NAPTR", "anything", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.recordType+" "+tc.rdata, func(t *testing.T) {
+			err := ValidateRData(tc.recordType, tc.rdata)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.wantErr {
+				t.Errorf("ValidateRData() error = %q, want %q", got, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLintPolicies(t *testing.T) {
+	var got []string
+	for _, f := range LintPolicies(loadTree(t)) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		`WARNING networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts": network "vpc-z" is not in the networking configuration, which creates "vpc-a" [network]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "duplicate-dns-name": dns_name "www.example.com" must end with a dot: "www.example.com." [trailing-dot]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "duplicate-dns-name": dns_name www.example.com. is already used by rule "exception" [duplicate]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "exception": rule name "exception" is used twice, the stage fails with a duplicate object key [duplicate]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "unsupported": behavior "block" is not supported, use bypassResponsePolicy or local_data [behavior]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "empty": rule has neither local_data nor behavior [behavior]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "invalid-name": invalid dns_name: "bad name.com." has an invalid character ' ' [invalid-name]`,
+		`WARNING networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "bad-data": behavior "bypassResponsePolicy" is ignored, local_data takes precedence [behavior]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "bad-data" local_data "A": name "other.internal." does not match the dns_name data.internal. of the rule [rdata]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "bad-data" local_data "A": "10.0.0.300" is not an IPv4 address [rdata]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "bad-data" local_data "CNAME": host name "b.internal" must be fully qualified, with a trailing dot [rdata]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "bad-data" local_data "CNAME": a CNAME record set holds a single record, not 2 [cname]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "bad-data" local_data "MX": record set has no rrdatas [rdata]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "bad-data": a CNAME cannot coexist with other local_data record sets [cname]`,
+		`WARNING networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "same-as-wildcard": rule is covered by wildcard rule "blocked" (*.example.com.) with the same answers and changes nothing [shadowed]`,
+		`INFO    networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "conflicts" rule "exception": rule overrides wildcard rule "blocked" (*.example.com.) for www.example.com., the most specific rule applies [shadowed]`,
+		`WARNING networking/CloudDNS/CloudDNSResponsePolicy/config/invalid.yaml: response_policy "detached": policy is not attached to any network or cluster and applies to no query [network]`,
+		`ERROR   networking/CloudDNS/CloudDNSResponsePolicy/config/valid.yaml: response_policy "googleapis": network "vpc-a" is already attached to response policy "conflicts", a network has a single response policy [network]`,
+		`INFO    networking/CloudDNS/CloudDNSResponsePolicy/config/valid.yaml: response_policy "googleapis" rule "private-googleapis": rule overrides wildcard rule "googleapis" (*.googleapis.com.) for private.googleapis.com., the most specific rule applies [shadowed]`,
+		`INFO    networking/CloudDNS/CloudDNSResponsePolicy/config/valid.yaml: response_policy "googleapis" rule "oauth2": rule overrides wildcard rule "googleapis" (*.googleapis.com.) for oauth2.googleapis.com., the most specific rule applies [shadowed]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintPolicies() findings mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
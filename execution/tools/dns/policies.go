@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ipplan"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// PoliciesStage is the stage holding the response policies.
+const PoliciesStage = "networking/CloudDNS/CloudDNSResponsePolicy"
+
+// BypassResponsePolicy is the behavior of a passthru rule: the query is
+// resolved as if the response policy had no rule for the name. It is the
+// only behavior accepted by the API.
+const BypassResponsePolicy = "bypassResponsePolicy"
+
+// Checks reported by LintPolicies, besides CheckDuplicate, CheckRData,
+// CheckCNAME, CheckTrailingDot and CheckInvalidName.
+const (
+	CheckShadowed = "shadowed"
+	CheckBehavior = "behavior"
+	CheckNetwork  = "network"
+)
+
+// policyRule is a rule of a response policy, with its canonical DNS name.
+type policyRule struct {
+	name    string
+	dnsName string
+	rule    config.Rule
+}
+
+func (r *policyRule) wildcard() bool {
+	return strings.HasPrefix(r.dnsName, "*.")
+}
+
+// covers reports whether the wildcard rule w matches the names of r.
+func (w *policyRule) covers(r *policyRule) bool {
+	return w.wildcard() && w != r && strings.HasSuffix(r.dnsName, w.dnsName[1:])
+}
+
+// LintPolicies checks the response policies of the CloudDNSResponsePolicy
+// stage:
+//
+//   - rule names and DNS names are not duplicated in a policy;
+//   - DNS names are valid, fully qualified names;
+//   - a rule has local_data, or the bypassResponsePolicy behavior;
+//   - local_data records are named after the rule and have valid rdata;
+//   - rules covered by a wildcard rule of the policy: Cloud DNS applies the
+//     most specific rule, so they override the wildcard rule for their
+//     names, or change nothing when they give the same answers;
+//   - policies are attached to the network of the networking stage, and a
+//     network to a single policy.
+//
+// Policies with policy_create set to false are not deployed by the stage
+// and are skipped.
+func LintPolicies(tree *config.Tree) []report.Finding {
+	s := tree.Stage(PoliciesStage)
+	if s == nil {
+		return nil
+	}
+	l := &linter{}
+	network := networkingNetwork(tree)
+	// attached maps the networks to the policy attached to them.
+	attached := map[string]string{}
+	for _, d := range s.Documents {
+		c, ok := d.Value.(*config.ResponsePoliciesConfig)
+		if !ok {
+			continue
+		}
+		for _, p := range c.ResponsePolicies {
+			if p.PolicyCreate != nil && !*p.PolicyCreate {
+				continue
+			}
+			resource := fmt.Sprintf("response_policy %q", p.Name)
+			if len(p.Networks) == 0 && len(p.Clusters) == 0 {
+				l.add(report.Warning, CheckNetwork, d.File, resource, "policy is not attached to any network or cluster and applies to no query")
+			}
+			for _, key := range slices.Sorted(maps.Keys(p.Networks)) {
+				name := ipplan.NetworkName(p.Networks[key])
+				if other, ok := attached[name]; ok {
+					l.add(report.Error, CheckNetwork, d.File, resource, "network %q is already attached to response policy %q, a network has a single response policy", name, other)
+				} else {
+					attached[name] = p.Name
+				}
+				if network != "" && name != network {
+					l.add(report.Warning, CheckNetwork, d.File, resource, "network %q is not in the networking configuration, which creates %q", name, network)
+				}
+			}
+			l.policy(d.File, p)
+		}
+	}
+	return l.findings
+}
+
+// networkingNetwork returns the network of the networking stage, or "" when
+// the tree does not configure one.
+func networkingNetwork(tree *config.Tree) string {
+	s := tree.Stage("networking")
+	if s == nil {
+		return ""
+	}
+	if v, ok := s.Vars.(*config.NetworkingVars); ok {
+		return v.NetworkName
+	}
+	return ""
+}
+
+func (l *linter) policy(file string, p config.ResponsePolicy) {
+	var rules []*policyRule
+	// names and dnsNames map the rule names and DNS names to their rule.
+	names := map[string]*policyRule{}
+	dnsNames := map[string]*policyRule{}
+	for _, item := range p.Rules {
+		for _, name := range slices.Sorted(maps.Keys(item)) {
+			r := &policyRule{name: name, dnsName: Canonical(item[name].DNSName), rule: item[name]}
+			resource := fmt.Sprintf("response_policy %q rule %q", p.Name, name)
+			if _, ok := names[name]; ok {
+				l.add(report.Error, CheckDuplicate, file, resource, "rule name %q is used twice, the stage fails with a duplicate object key", name)
+				continue
+			}
+			names[name] = r
+			if !l.rule(file, resource, r) {
+				continue
+			}
+			if other, ok := dnsNames[r.dnsName]; ok {
+				l.add(report.Error, CheckDuplicate, file, resource, "dns_name %s is already used by rule %q", r.dnsName, other.name)
+				continue
+			}
+			dnsNames[r.dnsName] = r
+			rules = append(rules, r)
+		}
+	}
+	for _, r := range rules {
+		// Only the most specific wildcard rule covering r applies to its
+		// names.
+		var w *policyRule
+		for _, c := range rules {
+			if c.covers(r) && (w == nil || len(c.dnsName) > len(w.dnsName)) {
+				w = c
+			}
+		}
+		if w == nil {
+			continue
+		}
+		resource := fmt.Sprintf("response_policy %q rule %q", p.Name, r.name)
+		if sameAnswers(r.rule, w.rule) {
+			l.add(report.Warning, CheckShadowed, file, resource, "rule is covered by wildcard rule %q (%s) with the same answers and changes nothing", w.name, w.dnsName)
+			continue
+		}
+		l.add(report.Info, CheckShadowed, file, resource, "rule overrides wildcard rule %q (%s) for %s, the most specific rule applies", w.name, w.dnsName, r.dnsName)
+	}
+}
+
+// rule checks a rule and reports whether its DNS name is valid.
+func (l *linter) rule(file, resource string, r *policyRule) bool {
+	raw := r.rule.DNSName
+	if err := ValidateName(raw); err != nil {
+		l.add(report.Error, CheckInvalidName, file, resource, "invalid dns_name: %v", err)
+		return false
+	}
+	if !Fqdn(raw) {
+		l.add(report.Error, CheckTrailingDot, file, resource, "dns_name %q must end with a dot: %q", raw, raw+".")
+	}
+	switch {
+	case len(r.rule.LocalData) > 0:
+		if r.rule.Behavior != "" {
+			l.add(report.Warning, CheckBehavior, file, resource, "behavior %q is ignored, local_data takes precedence", r.rule.Behavior)
+		}
+	case r.rule.Behavior == "":
+		l.add(report.Error, CheckBehavior, file, resource, "rule has neither local_data nor behavior")
+	case r.rule.Behavior != BypassResponsePolicy:
+		l.add(report.Error, CheckBehavior, file, resource, "behavior %q is not supported, use %s or local_data", r.rule.Behavior, BypassResponsePolicy)
+	}
+	types := map[string]bool{}
+	for _, key := range slices.Sorted(maps.Keys(r.rule.LocalData)) {
+		rec := r.rule.LocalData[key]
+		typ := strings.ToUpper(rec.Type)
+		recResource := fmt.Sprintf("%s local_data %q", resource, key)
+		if rec.Name != "" && Canonical(rec.Name) != r.dnsName {
+			l.add(report.Error, CheckRData, file, recResource, "name %q does not match the dns_name %s of the rule", rec.Name, r.dnsName)
+		}
+		if types[typ] {
+			l.add(report.Error, CheckDuplicate, file, recResource, "local_data already has a record set of type %s", typ)
+		}
+		types[typ] = true
+		if len(rec.RRDatas) == 0 {
+			l.add(report.Error, CheckRData, file, recResource, "record set has no rrdatas")
+		}
+		for _, rdata := range rec.RRDatas {
+			if err := ValidateRData(typ, rdata); err != nil {
+				l.add(report.Error, CheckRData, file, recResource, "%v", err)
+			}
+		}
+		if typ == "CNAME" && len(rec.RRDatas) > 1 {
+			l.add(report.Error, CheckCNAME, file, recResource, "a CNAME record set holds a single record, not %d", len(rec.RRDatas))
+		}
+	}
+	if types["CNAME"] && len(types) > 1 {
+		l.add(report.Error, CheckCNAME, file, resource, "a CNAME cannot coexist with other local_data record sets")
+	}
+	return true
+}
+
+// sameAnswers reports whether two rules answer the same way.
+func sameAnswers(a, b config.Rule) bool {
+	if len(a.LocalData) == 0 || len(b.LocalData) == 0 {
+		return len(a.LocalData) == len(b.LocalData) && a.Behavior == b.Behavior
+	}
+	byType := func(r config.Rule) map[string]string {
+		m := map[string]string{}
+		for _, rec := range r.LocalData {
+			data := slices.Sorted(slices.Values(rec.RRDatas))
+			m[strings.ToUpper(rec.Type)] = fmt.Sprintf("%d %s", rec.TTL, strings.Join(data, "\n"))
+		}
+		return m
+	}
+	return maps.Equal(byType(a), byType(b))
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Fqdn reports whether name is fully qualified, i.e. ends with a dot.
+func Fqdn(name string) bool {
+	return strings.HasSuffix(name, ".")
+}
+
+// Canonical returns name in lower case with a trailing dot.
+func Canonical(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !Fqdn(name) {
+		name += "."
+	}
+	return name
+}
+
+// InDomain reports whether the fully qualified name is domain or one of its
+// subdomains.
+func InDomain(name, domain string) bool {
+	name, domain = Canonical(name), Canonical(domain)
+	return domain == "." || name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// ValidateName returns an error when name is not a valid DNS name. Labels may
+// hold letters, digits, hyphens and underscores, and the first label may be
+// the wildcard *.
+func ValidateName(name string) error {
+	if name == "." {
+		return nil
+	}
+	trimmed := strings.TrimSuffix(name, ".")
+	if trimmed == "" {
+		return errors.New("empty name")
+	}
+	if len(trimmed) > 253 {
+		return fmt.Errorf("%q is longer than 253 characters", name)
+	}
+	for i, label := range strings.Split(trimmed, ".") {
+		switch {
+		case label == "":
+			return fmt.Errorf("%q has an empty label", name)
+		case len(label) > 63:
+			return fmt.Errorf("%q has a label longer than 63 characters", name)
+		case label == "*" && i == 0:
+			continue
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return fmt.Errorf("%q has an invalid character %q", name, r)
+			}
+		}
+	}
+	return nil
+}
+
+// checkTarget checks a host name used in rdata, which must be fully
+// qualified.
+func checkTarget(what, name string) error {
+	if err := ValidateName(name); err != nil {
+		return fmt.Errorf("invalid %s: %w", what, err)
+	}
+	if !Fqdn(name) {
+		return fmt.Errorf("%s %q must be fully qualified, with a trailing dot", what, name)
+	}
+	return nil
+}
+
+func uint16Field(what, s string) error {
+	if _, err := strconv.ParseUint(s, 10, 16); err != nil {
+		return fmt.Errorf("%s %q is not a number between 0 and 65535", what, s)
+	}
+	return nil
+}
+
+// CAATags are the property tags of CAA records defined by RFC 8659.
+var CAATags = []string{"issue", "issuewild", "iodef", "issuemail", "issuevmc"}
+
+// ValidateRData returns an error when rdata is not valid for the record type.
+// A, AAAA, CNAME, MX, NS, PTR, SRV, TXT and CAA records are checked, other
+// types are accepted as is.
+func ValidateRData(recordType, rdata string) error {
+	fields := strings.Fields(rdata)
+	switch strings.ToUpper(recordType) {
+	case "A":
+		a, err := netip.ParseAddr(rdata)
+		if err != nil || !a.Is4() {
+			return fmt.Errorf("%q is not an IPv4 address", rdata)
+		}
+	case "AAAA":
+		a, err := netip.ParseAddr(rdata)
+		if err != nil || !a.Is6() || a.Is4In6() {
+			return fmt.Errorf("%q is not an IPv6 address", rdata)
+		}
+	case "CNAME", "NS", "PTR":
+		if len(fields) != 1 {
+			return fmt.Errorf("%q is not a single host name", rdata)
+		}
+		return checkTarget("host name", fields[0])
+	case "MX":
+		if len(fields) != 2 {
+			return fmt.Errorf("%q is not in the PREFERENCE EXCHANGE format, e.g. \"10 mail.example.com.\"", rdata)
+		}
+		if err := uint16Field("preference", fields[0]); err != nil {
+			return err
+		}
+		return checkTarget("exchange", fields[1])
+	case "SRV":
+		if len(fields) != 4 {
+			return fmt.Errorf("%q is not in the PRIORITY WEIGHT PORT TARGET format, e.g. \"10 5 5060 sip.example.com.\"", rdata)
+		}
+		for i, what := range []string{"priority", "weight", "port"} {
+			if err := uint16Field(what, fields[i]); err != nil {
+				return err
+			}
+		}
+		if fields[3] == "." {
+			return nil
+		}
+		return checkTarget("target", fields[3])
+	case "TXT", "SPF":
+		return checkTXT(rdata)
+	case "CAA":
+		if len(fields) < 3 {
+			return fmt.Errorf("%q is not in the FLAGS TAG \"VALUE\" format, e.g. 0 issue \"pki.goog\"", rdata)
+		}
+		if _, err := strconv.ParseUint(fields[0], 10, 8); err != nil {
+			return fmt.Errorf("flags %q is not a number between 0 and 255", fields[0])
+		}
+		if !slices.Contains(CAATags, strings.ToLower(fields[1])) {
+			return fmt.Errorf("tag %q is not one of %s", fields[1], strings.Join(CAATags, ", "))
+		}
+		value := strings.TrimSpace(rdata[strings.Index(rdata, fields[1])+len(fields[1]):])
+		if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+			return fmt.Errorf("value %s must be quoted", value)
+		}
+	}
+	return nil
+}
+
+// checkTXT checks the character strings of a TXT record: quotes must be
+// balanced and every string must hold at most 255 characters.
+func checkTXT(rdata string) error {
+	if strings.TrimSpace(rdata) == "" {
+		return errors.New("empty TXT record")
+	}
+	if !strings.HasPrefix(strings.TrimSpace(rdata), `"`) {
+		if len(rdata) > 255 {
+			return fmt.Errorf("unquoted string of %d characters, split it into quoted strings of at most 255 characters", len(rdata))
+		}
+		return nil
+	}
+	var (
+		quoted bool
+		length int
+	)
+	for i := 0; i < len(rdata); i++ {
+		c := rdata[i]
+		switch {
+		case c == '\\' && quoted:
+			i++
+			length++
+		case c == '"':
+			if quoted && length > 255 {
+				return fmt.Errorf("string of %d characters, the limit is 255", length)
+			}
+			quoted, length = !quoted, 0
+		case quoted:
+			length++
+		case c != ' ' && c != '\t':
+			return fmt.Errorf("%q has text outside quotes", rdata)
+		}
+	}
+	if quoted {
+		return fmt.Errorf("%q has unbalanced quotes", rdata)
+	}
+	return nil
+}
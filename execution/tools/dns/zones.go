@@ -0,0 +1,247 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dns lints the Cloud DNS configuration of the
+// 02-networking/CloudDNS stages before it is applied.
+package dns
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// ZonesStage is the stage holding the managed zones.
+const ZonesStage = "networking/CloudDNS/DNSManagedZones"
+
+// TTL bounds. TTLs outside [MinTTL, MaxTTL] are accepted by Cloud DNS but
+// reported as warnings.
+const (
+	MinTTL = 30
+	MaxTTL = 7 * 24 * 3600
+	// MaxAPITTL is the largest TTL accepted by the API.
+	MaxAPITTL = 1<<31 - 1
+)
+
+// Checks reported by LintZones.
+const (
+	CheckTrailingDot = "trailing-dot"
+	CheckOutsideZone = "outside-zone"
+	CheckCNAME       = "cname"
+	CheckRData       = "rdata"
+	CheckTTL         = "ttl"
+	CheckDuplicate   = "duplicate"
+	CheckForwarding  = "forwarding"
+	CheckInvalidName = "invalid-name"
+)
+
+type linter struct {
+	findings []report.Finding
+}
+
+func (l *linter) add(sev report.Severity, check, file, resource, format string, args ...any) {
+	l.findings = append(l.findings, report.Finding{
+		Severity: sev, Check: check, File: file, Resource: resource, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// LintZones checks the managed zones of the DNSManagedZones stage:
+//
+//   - domains and record names are valid, fully qualified names; a record
+//     name looking like a FQDN without its trailing dot is reported, since
+//     the stage makes it relative to the zone domain;
+//   - records are inside the zone domain;
+//   - a CNAME is the only record set of its name, and not at the zone apex;
+//   - the rdata of A, AAAA, CNAME, MX, NS, PTR, SRV, TXT and CAA records;
+//   - TTLs are within bounds;
+//   - zone names and record sets are not duplicated;
+//   - forwarding targets are IPv4 addresses reachable with their path.
+func LintZones(tree *config.Tree) []report.Finding {
+	s := tree.Stage(ZonesStage)
+	if s == nil {
+		return nil
+	}
+	l := &linter{}
+	// zones maps the zones seen to the file defining them.
+	zones := map[string]string{}
+	for _, d := range s.Documents {
+		c, ok := d.Value.(*config.DNSConfig)
+		if !ok {
+			continue
+		}
+		for i := range c.Zones {
+			z := &c.Zones[i]
+			resource := fmt.Sprintf("zone %q", z.Name)
+			key := z.ProjectID + "/" + z.Name
+			if other, ok := zones[key]; ok {
+				l.add(report.Error, CheckDuplicate, d.File, resource, "zone %q of project %q is already defined in %s", z.Name, z.ProjectID, other)
+			}
+			zones[key] = d.File
+			l.zone(d.File, z)
+		}
+	}
+	return l.findings
+}
+
+func (l *linter) zone(file string, z *config.Zone) {
+	resource := fmt.Sprintf("zone %q", z.Name)
+	cfg := z.ZoneConfig
+	domain := cfg.Domain
+	if err := ValidateName(domain); err != nil {
+		l.add(report.Error, CheckInvalidName, file, resource, "invalid domain: %v", err)
+		return
+	}
+	if !Fqdn(domain) {
+		l.add(report.Error, CheckTrailingDot, file, resource, "domain %q must end with a dot: %q", domain, domain+".")
+		domain += "."
+	}
+	if cfg.ForwardingConfig != nil {
+		l.forwarding(file, resource, z)
+	}
+	if len(z.Recordsets) > 0 && (cfg.ForwardingConfig != nil || cfg.PeeringConfig != nil) {
+		l.add(report.Warning, CheckForwarding, file, resource, "record sets of a forwarding or peering zone are not served, queries are sent to the target")
+	}
+
+	// types maps the record names to the types of their record sets.
+	types := map[string][]string{}
+	for _, rs := range z.Recordsets {
+		name, ok := l.recordName(file, domain, rs)
+		if !ok {
+			continue
+		}
+		typ := strings.ToUpper(rs.Type)
+		rsResource := fmt.Sprintf("recordset %q", typ+" "+name)
+		if slices.Contains(types[name], typ) {
+			l.add(report.Error, CheckDuplicate, file, rsResource, "zone %q already has a record set of type %s for %s", z.Name, typ, name)
+			continue
+		}
+		types[name] = append(types[name], typ)
+		l.recordSet(file, rsResource, domain, name, typ, rs)
+	}
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		t := types[name]
+		if slices.Contains(t, "CNAME") && len(t) > 1 {
+			others := slices.DeleteFunc(slices.Clone(t), func(s string) bool { return s == "CNAME" })
+			l.add(report.Error, CheckCNAME, file, fmt.Sprintf("recordset %q", "CNAME "+name),
+				"a CNAME cannot coexist with other record sets of the same name: %s", strings.Join(others, ", "))
+		}
+	}
+}
+
+// recordName returns the absolute name of a record set. As in the stage, a
+// name without trailing dot is relative to the zone domain, and an empty
+// name is the zone apex.
+func (l *linter) recordName(file, domain string, rs config.RecordSet) (string, bool) {
+	resource := fmt.Sprintf("recordset %q", strings.ToUpper(rs.Type)+" "+rs.Name)
+	name := rs.Name
+	switch {
+	case name == "" || name == "@":
+		return strings.ToLower(domain), true
+	case Fqdn(name):
+	case strings.HasSuffix(strings.ToLower(name), strings.TrimSuffix(strings.ToLower(domain), ".")):
+		l.add(report.Error, CheckTrailingDot, file, resource,
+			"name %q has no trailing dot and is relative to the zone: it resolves to %q, use %q", name, name+"."+domain, name+".")
+		return "", false
+	default:
+		name += "." + domain
+	}
+	if err := ValidateName(name); err != nil {
+		l.add(report.Error, CheckInvalidName, file, resource, "invalid name: %v", err)
+		return "", false
+	}
+	if !InDomain(name, domain) {
+		l.add(report.Error, CheckOutsideZone, file, resource, "%s is outside the zone domain %s", name, domain)
+		return "", false
+	}
+	return strings.ToLower(name), true
+}
+
+func (l *linter) recordSet(file, resource, domain, name, typ string, rs config.RecordSet) {
+	switch {
+	case rs.TTL < 0 || rs.TTL > MaxAPITTL:
+		l.add(report.Error, CheckTTL, file, resource, "TTL %d is not between 0 and %d", rs.TTL, MaxAPITTL)
+	case rs.TTL < MinTTL:
+		l.add(report.Warning, CheckTTL, file, resource, "TTL %d is below %d seconds, resolvers will query the zone on most lookups", rs.TTL, MinTTL)
+	case rs.TTL > MaxTTL:
+		l.add(report.Warning, CheckTTL, file, resource, "TTL %d is above %d seconds (a week), changes will take long to propagate", rs.TTL, MaxTTL)
+	}
+	if len(rs.Records) == 0 {
+		l.add(report.Error, CheckRData, file, resource, "record set has no records")
+	}
+	if typ == "CNAME" {
+		if name == strings.ToLower(domain) {
+			l.add(report.Error, CheckCNAME, file, resource, "a CNAME cannot be at the zone apex")
+		}
+		if len(rs.Records) > 1 {
+			l.add(report.Error, CheckCNAME, file, resource, "a CNAME record set holds a single record, not %d", len(rs.Records))
+		}
+	}
+	seen := map[string]bool{}
+	for _, r := range rs.Records {
+		if err := ValidateRData(typ, r); err != nil {
+			l.add(report.Error, CheckRData, file, resource, "%v", err)
+		}
+		if seen[r] {
+			l.add(report.Warning, CheckDuplicate, file, resource, "record %q is listed twice", r)
+		}
+		seen[r] = true
+	}
+}
+
+// rfc1918 are the ranges Cloud DNS forwards to through the VPC network with
+// the default forwarding path.
+var rfc1918 = []netip.Prefix{
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+}
+
+func (l *linter) forwarding(file, resource string, z *config.Zone) {
+	cfg := z.ZoneConfig
+	if cfg.Visibility != "private" {
+		l.add(report.Error, CheckForwarding, file, resource, "a forwarding zone must have private visibility")
+	}
+	targets := cfg.ForwardingConfig.TargetNameServers
+	if len(targets) == 0 {
+		l.add(report.Error, CheckForwarding, file, resource, "forwarding_config has no target name server")
+	}
+	seen := map[netip.Addr]bool{}
+	for _, t := range targets {
+		addr, err := netip.ParseAddr(t.IPv4Address)
+		if err != nil || !addr.Is4() {
+			l.add(report.Error, CheckForwarding, file, resource, "target %q is not an IPv4 address", t.IPv4Address)
+			continue
+		}
+		private := slices.ContainsFunc(rfc1918, func(p netip.Prefix) bool { return p.Contains(addr) })
+		switch {
+		case seen[addr]:
+			l.add(report.Warning, CheckForwarding, file, resource, "target %s is listed twice", addr)
+		case addr.IsUnspecified() || addr.IsLoopback() || addr.IsMulticast() || addr.IsLinkLocalUnicast() || addr == netip.AddrFrom4([4]byte{255, 255, 255, 255}):
+			l.add(report.Error, CheckForwarding, file, resource, "target %s is not a unicast address a name server can use", addr)
+		case !private && t.ForwardingPath != "private":
+			l.add(report.Info, CheckForwarding, file, resource,
+				"target %s is not an RFC 1918 address and is reached over the internet; set forwarding_path: private to reach it through the VPC network", addr)
+		}
+		seen[addr] = true
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnstest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func loadResolver(t *testing.T) *Resolver {
+	t.Helper()
+	tree, err := config.LoadTree("testdata")
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %v", err)
+	}
+	r, err := Build(tree)
+	if err != nil {
+		t.Fatalf("Failed to build the resolver: %v", err)
+	}
+	return r
+}
+
+func TestLookup(t *testing.T) {
+	r := loadResolver(t)
+	testCases := []struct {
+		name    string
+		network string
+		query   string
+		qtype   string
+		status  Status
+		source  string
+		records []string
+	}{
+		{
+			name:    "private zone",
+			network: "vpc-a",
+			query:   "db.corp.internal.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+			records: []string{"db.corp.internal. 300 IN A 10.10.1.5"},
+		},
+		{
+			name:    "same domain in another network",
+			network: "projects/host-project/global/networks/vpc-b",
+			query:   "db.corp.internal",
+			qtype:   "a",
+			status:  NoError,
+			source:  `zone "corp-b"`,
+			records: []string{"db.corp.internal. 300 IN A 10.20.1.5"},
+		},
+		{
+			name:   "private zone from the internet",
+			query:  "db.corp.internal.",
+			qtype:  "A",
+			status: NotConfigured,
+		},
+		{
+			name:    "cname",
+			network: "vpc-a",
+			query:   "www.corp.internal.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+			records: []string{
+				"www.corp.internal. 300 IN CNAME web.corp.internal.",
+				"web.corp.internal. 60 IN A 10.10.1.6",
+				"web.corp.internal. 60 IN A 10.10.1.7",
+			},
+		},
+		{
+			name:    "cname query",
+			network: "vpc-a",
+			query:   "www.corp.internal.",
+			qtype:   "CNAME",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+			records: []string{"www.corp.internal. 300 IN CNAME web.corp.internal."},
+		},
+		{
+			name:    "apex",
+			network: "vpc-a",
+			query:   "corp.internal.",
+			qtype:   "MX",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+			records: []string{"corp.internal. 3600 IN MX 10 mail.corp.internal."},
+		},
+		{
+			name:    "no data",
+			network: "vpc-a",
+			query:   "db.corp.internal.",
+			qtype:   "AAAA",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+		},
+		{
+			name:    "empty non-terminal",
+			network: "vpc-a",
+			query:   "_tcp.corp.internal.",
+			qtype:   "SRV",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+		},
+		{
+			name:    "nxdomain",
+			network: "vpc-a",
+			query:   "nope.corp.internal.",
+			qtype:   "A",
+			status:  NXDomain,
+			source:  `zone "corp-a"`,
+		},
+		{
+			name:    "wildcard",
+			network: "vpc-a",
+			query:   "a.b.svc.corp.internal.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+			records: []string{"a.b.svc.corp.internal. 300 IN A 10.10.3.1"},
+		},
+		{
+			name:    "cname loop",
+			network: "vpc-a",
+			query:   "loop1.corp.internal.",
+			qtype:   "A",
+			status:  ServFail,
+			source:  `zone "corp-a"`,
+			records: []string{
+				"loop1.corp.internal. 300 IN CNAME loop2.corp.internal.",
+				"loop2.corp.internal. 300 IN CNAME loop1.corp.internal.",
+			},
+		},
+		{
+			name:    "public zone",
+			network: "vpc-b",
+			query:   "api.example.com.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `zone "example-public"`,
+			records: []string{"api.example.com. 300 IN A 203.0.113.10"},
+		},
+		{
+			name:    "public zone from the internet",
+			query:   "api.example.com.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `zone "example-public"`,
+			records: []string{"api.example.com. 300 IN A 203.0.113.10"},
+		},
+		{
+			name:    "local data",
+			network: "vpc-a",
+			query:   "api.example.com.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `response policy "policy-a" rule "override-api"`,
+			records: []string{"api.example.com. 300 IN A 10.10.9.9"},
+		},
+		{
+			name:    "local data without the query type",
+			network: "vpc-a",
+			query:   "api.example.com.",
+			qtype:   "TXT",
+			status:  NoError,
+			source:  `response policy "policy-a" rule "override-api"`,
+		},
+		{
+			name:    "cname to local data",
+			network: "vpc-a",
+			query:   "ext.corp.internal.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+			records: []string{
+				"ext.corp.internal. 300 IN CNAME api.example.com.",
+				"api.example.com. 300 IN A 10.10.9.9",
+			},
+		},
+		{
+			name:    "wildcard rule",
+			network: "vpc-a",
+			query:   "y.apps.corp.internal.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `response policy "policy-a" rule "apps"`,
+			records: []string{"y.apps.corp.internal. 300 IN A 10.99.0.1"},
+		},
+		{
+			name:    "passthru rule",
+			network: "vpc-a",
+			query:   "x.apps.corp.internal.",
+			qtype:   "TXT",
+			status:  NoError,
+			source:  `zone "corp-a"`,
+			records: []string{`x.apps.corp.internal. 300 IN TXT "passthru"`},
+		},
+		{
+			name:    "wildcard rule with cname local data",
+			network: "vpc-a",
+			query:   "storage.googleapis.com.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `response policy "policy-a" rule "googleapis"`,
+			records: []string{
+				"storage.googleapis.com. 300 IN CNAME private.googleapis.com.",
+				"private.googleapis.com. 300 IN A 199.36.153.8",
+				"private.googleapis.com. 300 IN A 199.36.153.9",
+			},
+		},
+		{
+			name:    "name without zone",
+			network: "vpc-b",
+			query:   "storage.googleapis.com.",
+			qtype:   "A",
+			status:  NotConfigured,
+		},
+		{
+			name:    "forwarding zone",
+			network: "vpc-a",
+			query:   "host.onprem.corp.",
+			qtype:   "A",
+			status:  Forwarded,
+			source:  `zone "onprem"`,
+		},
+		{
+			name:    "peering zone",
+			network: "vpc-a",
+			query:   "db.producer.internal.",
+			qtype:   "A",
+			status:  NoError,
+			source:  `zone "producer" through zone "producer-peering"`,
+			records: []string{"db.producer.internal. 300 IN A 10.30.0.2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := r.Lookup(tc.network, tc.query, tc.qtype)
+			var records []string
+			for _, rec := range a.Records {
+				records = append(records, rec.String())
+			}
+			if a.Status != tc.status || a.Source != tc.source || !reflect.DeepEqual(records, tc.records) {
+				t.Errorf("Lookup(%q, %q, %q) mismatch.\ngot:\n%swant: %s from %s\n  %s",
+					tc.network, tc.query, tc.qtype, a, tc.status, tc.source, strings.Join(tc.records, "\n  "))
+			}
+		})
+	}
+	if a := r.Lookup("vpc-a", "host.onprem.corp.", "A"); !reflect.DeepEqual(a.Targets, []string{"192.168.0.53"}) {
+		t.Errorf("Forwarding targets mismatch: got %v, want [192.168.0.53]", a.Targets)
+	}
+}
+
+func TestBuildUnsupportedBehavior(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "networking/CloudDNS/CloudDNSResponsePolicy/config")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create %s: %v", dir, err)
+	}
+	policy := `response_policies:
+  - name: "policy"
+    project_id: "project"
+    networks:
+      vpc: "projects/project/global/networks/vpc"
+    rules:
+      - blocked:
+          dns_name: "*.blocked.com."
+          behavior: "block"
+`
+	if err := os.WriteFile(filepath.Join(dir, "policy.yaml"), []byte(policy), 0o644); err != nil {
+		t.Fatalf("Failed to write the policy: %v", err)
+	}
+	tree, err := config.LoadTree(root)
+	if err != nil {
+		t.Fatalf("Failed to load the tree: %v", err)
+	}
+	_, err = Build(tree)
+	want := `networking/CloudDNS/CloudDNSResponsePolicy/config/policy.yaml: response policy "policy" rule "blocked": behavior "block" is not supported, use bypassResponsePolicy or local_data`
+	if err == nil || err.Error() != want {
+		t.Errorf("Build error mismatch: got %v, want %s", err, want)
+	}
+}
+
+func TestServer(t *testing.T) {
+	s, err := NewServer(loadResolver(t), "vpc-a")
+	if err != nil {
+		t.Fatalf("Failed to start the server: %v", err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	nr := s.NetResolver()
+
+	addrs, err := nr.LookupHost(ctx, "www.corp.internal.")
+	slices.Sort(addrs)
+	if err != nil || !reflect.DeepEqual(addrs, []string{"10.10.1.6", "10.10.1.7"}) {
+		t.Errorf("LookupHost(www.corp.internal.) = %v, %v, want [10.10.1.6 10.10.1.7]", addrs, err)
+	}
+	cname, err := nr.LookupCNAME(ctx, "storage.googleapis.com.")
+	if err != nil || cname != "private.googleapis.com." {
+		t.Errorf("LookupCNAME(storage.googleapis.com.) = %q, %v, want private.googleapis.com.", cname, err)
+	}
+	mx, err := nr.LookupMX(ctx, "corp.internal.")
+	if err != nil || len(mx) != 1 || mx[0].Host != "mail.corp.internal." || mx[0].Pref != 10 {
+		t.Errorf("LookupMX(corp.internal.) = %v, %v, want [10 mail.corp.internal.]", mx, err)
+	}
+	_, srv, err := nr.LookupSRV(ctx, "sip", "tcp", "corp.internal.")
+	if err != nil || len(srv) != 1 || srv[0].Target != "sip.corp.internal." || srv[0].Port != 5060 {
+		t.Errorf("LookupSRV(_sip._tcp.corp.internal.) = %v, %v, want [10 5 5060 sip.corp.internal.]", srv, err)
+	}
+	txt, err := nr.LookupTXT(ctx, "corp.internal.")
+	if err != nil || !reflect.DeepEqual(txt, []string{"v=spf1 -all"}) {
+		t.Errorf("LookupTXT(corp.internal.) = %q, %v, want [v=spf1 -all]", txt, err)
+	}
+	_, err = nr.LookupHost(ctx, "nope.corp.internal.")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("LookupHost(nope.corp.internal.) error = %v, want not found", err)
+	}
+}
+
+func TestTXTStrings(t *testing.T) {
+	testCases := []struct {
+		rdata string
+		want  []string
+	}{
+		{rdata: `v=spf1 -all`, want: []string{"v=spf1 -all"}},
+		{rdata: `"v=spf1 -all"`, want: []string{"v=spf1 -all"}},
+		{rdata: `"part one" "part two"`, want: []string{"part one", "part two"}},
+		{rdata: `"say \"hi\""`, want: []string{`say "hi"`}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.rdata, func(t *testing.T) {
+			if got := TXTStrings(tc.rdata); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("TXTStrings(%s) = %q, want %q", tc.rdata, got, tc.want)
+			}
+		})
+	}
+}
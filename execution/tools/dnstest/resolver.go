@@ -0,0 +1,406 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dnstest answers DNS queries from the configuration of the
+// 02-networking/CloudDNS stages, so tests can assert the answers a VPC
+// network gets before anything is deployed.
+//
+// A Resolver follows the Cloud DNS name resolution order of a network:
+// response policies bound to the network, then the private zones visible to
+// it, then the public zones. A Server serves the answers of a Resolver over
+// UDP on the loopback interface, for code using a net.Resolver.
+//
+// The SOA and NS record sets Cloud DNS creates at the apex of every zone,
+// response policies bound to GKE clusters and the answers of forwarding
+// targets are not simulated.
+package dnstest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/dns"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ipplan"
+)
+
+// maxChain bounds the CNAME chains followed by Lookup.
+const maxChain = 8
+
+// Status is the outcome of a lookup.
+type Status string
+
+// Lookup outcomes.
+const (
+	NoError  Status = "NOERROR"
+	NXDomain Status = "NXDOMAIN"
+	// ServFail is returned for CNAME chains looping or longer than 8
+	// names.
+	ServFail Status = "SERVFAIL"
+	// Forwarded is returned for names of a forwarding zone, the answer
+	// comes from the target name servers.
+	Forwarded Status = "FORWARDED"
+	// NotConfigured is returned when no zone or rule of the configuration
+	// answers for the name.
+	NotConfigured Status = "NOTCONFIGURED"
+)
+
+// Record is a resource record of an answer.
+type Record struct {
+	Name string
+	Type string
+	TTL  int
+	Data string
+}
+
+// String returns the record in zone file format.
+func (r Record) String() string {
+	return fmt.Sprintf("%s %d IN %s %s", r.Name, r.TTL, r.Type, r.Data)
+}
+
+// Answer is the answer to a query.
+type Answer struct {
+	Name    string
+	Type    string
+	Status  Status
+	Records []Record
+	// Source is the zone or response policy rule answering for Name, e.g.
+	// zone "corp" or response policy "policy" rule "rule".
+	Source string
+	// Targets are the name servers of the forwarding zone, when Status is
+	// Forwarded.
+	Targets []string
+}
+
+// String returns the status and the records of the answer, one per line.
+func (a Answer) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", a.Status, a.Type, a.Name)
+	if a.Source != "" {
+		fmt.Fprintf(&b, " from %s", a.Source)
+	}
+	if len(a.Targets) > 0 {
+		fmt.Fprintf(&b, " to %s", strings.Join(a.Targets, ", "))
+	}
+	b.WriteString("\n")
+	for _, r := range a.Records {
+		fmt.Fprintf(&b, "  %s\n", r)
+	}
+	return b.String()
+}
+
+// rrset is the data of a record set, or of the local data of a rule for one
+// type.
+type rrset struct {
+	ttl  int
+	data []string
+}
+
+type zone struct {
+	name     string
+	domain   string
+	public   bool
+	networks []string
+	targets  []string
+	peer     string
+	// records maps the owner names to the record sets by type.
+	records map[string]map[string]rrset
+}
+
+type rule struct {
+	policy  string
+	name    string
+	dnsName string
+	bypass  bool
+	local   map[string]rrset
+}
+
+type policy struct {
+	name     string
+	networks []string
+	rules    []*rule
+}
+
+// Resolver answers queries from the zones and response policies of a
+// configuration tree.
+type Resolver struct {
+	zones    []*zone
+	policies []*policy
+}
+
+// Build returns the resolver of the tree. Record names are made absolute as
+// in the DNSManagedZones stage, and response policies with policy_create set
+// to false are left out, as in the CloudDNSResponsePolicy stage. It returns
+// an error for rules the Cloud DNS API rejects.
+func Build(tree *config.Tree) (*Resolver, error) {
+	r := &Resolver{}
+	if s := tree.Stage(dns.ZonesStage); s != nil {
+		for _, d := range s.Documents {
+			if c, ok := d.Value.(*config.DNSConfig); ok {
+				for i := range c.Zones {
+					r.zones = append(r.zones, newZone(&c.Zones[i]))
+				}
+			}
+		}
+	}
+	if s := tree.Stage(dns.PoliciesStage); s != nil {
+		for _, d := range s.Documents {
+			c, ok := d.Value.(*config.ResponsePoliciesConfig)
+			if !ok {
+				continue
+			}
+			for _, p := range c.ResponsePolicies {
+				if p.PolicyCreate != nil && !*p.PolicyCreate {
+					continue
+				}
+				rp, err := newPolicy(p)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", d.File, err)
+				}
+				r.policies = append(r.policies, rp)
+			}
+		}
+	}
+	return r, nil
+}
+
+func newZone(z *config.Zone) *zone {
+	cfg := z.ZoneConfig
+	domain := dns.Canonical(cfg.Domain)
+	nz := &zone{
+		name:    z.Name,
+		domain:  domain,
+		public:  cfg.Visibility != "private",
+		records: map[string]map[string]rrset{},
+	}
+	if v := cfg.PrivateVisibilityConfig; v != nil {
+		for _, n := range v.Networks {
+			nz.networks = append(nz.networks, ipplan.NetworkName(n.NetworkURL))
+		}
+	}
+	if f := cfg.ForwardingConfig; f != nil {
+		for _, t := range f.TargetNameServers {
+			nz.targets = append(nz.targets, t.IPv4Address)
+		}
+	}
+	if p := cfg.PeeringConfig; p != nil {
+		nz.peer = ipplan.NetworkName(p.TargetNetwork.NetworkURL)
+	}
+	for _, rs := range z.Recordsets {
+		name := rs.Name
+		switch {
+		case name == "" || name == "@":
+			name = domain
+		case !dns.Fqdn(name):
+			name += "." + domain
+		}
+		name = dns.Canonical(name)
+		if nz.records[name] == nil {
+			nz.records[name] = map[string]rrset{}
+		}
+		nz.records[name][strings.ToUpper(rs.Type)] = rrset{ttl: rs.TTL, data: rs.Records}
+	}
+	return nz
+}
+
+func newPolicy(p config.ResponsePolicy) (*policy, error) {
+	np := &policy{name: p.Name}
+	for _, n := range p.Networks {
+		np.networks = append(np.networks, ipplan.NetworkName(n))
+	}
+	seen := map[string]bool{}
+	for _, item := range p.Rules {
+		for name, ru := range item {
+			if seen[name] {
+				return nil, fmt.Errorf("response policy %q: rule %q is defined twice", p.Name, name)
+			}
+			seen[name] = true
+			nr := &rule{policy: p.Name, name: name, dnsName: dns.Canonical(ru.DNSName)}
+			switch {
+			case len(ru.LocalData) > 0:
+				nr.local = map[string]rrset{}
+				for _, rec := range ru.LocalData {
+					nr.local[strings.ToUpper(rec.Type)] = rrset{ttl: rec.TTL, data: rec.RRDatas}
+				}
+			case ru.Behavior == dns.BypassResponsePolicy:
+				nr.bypass = true
+			default:
+				return nil, fmt.Errorf("response policy %q rule %q: behavior %q is not supported, use %s or local_data", p.Name, name, ru.Behavior, dns.BypassResponsePolicy)
+			}
+			np.rules = append(np.rules, nr)
+		}
+	}
+	return np, nil
+}
+
+// Lookup returns the answer to a query of type qtype for name, sent from
+// the VPC network given by name or self link. An empty network stands for a
+// client on the internet, which only sees the public zones.
+func (r *Resolver) Lookup(network, name, qtype string) Answer {
+	return r.lookup(ipplan.NetworkName(network), dns.Canonical(name), strings.ToUpper(qtype), true, nil)
+}
+
+// lookup resolves name, following CNAMEs. policies is false for queries
+// sent through DNS peering, which only use the zones of the target network.
+// chain holds the names already resolved for the query.
+func (r *Resolver) lookup(network, name, qtype string, policies bool, chain []string) Answer {
+	a := Answer{Name: name, Type: qtype}
+	if slices.Contains(chain, name) || len(chain) >= maxChain {
+		a.Status = ServFail
+		return a
+	}
+	chain = append(chain, name)
+	if policies && network != "" {
+		if ru := r.rule(network, name); ru != nil && !ru.bypass {
+			a.Source = fmt.Sprintf("response policy %q rule %q", ru.policy, ru.name)
+			return r.answer(a, network, ru.local, policies, chain)
+		}
+	}
+	z := r.zone(network, name)
+	if z == nil {
+		a.Status = NotConfigured
+		return a
+	}
+	a.Source = fmt.Sprintf("zone %q", z.name)
+	switch {
+	case len(z.targets) > 0:
+		a.Status, a.Targets = Forwarded, z.targets
+		return a
+	case z.peer != "":
+		peered := r.lookup(z.peer, name, qtype, false, chain[:len(chain)-1])
+		peered.Source = fmt.Sprintf("%s through zone %q", peered.Source, z.name)
+		return peered
+	}
+	sets, ok := z.records[name]
+	if !ok {
+		switch {
+		case z.exists(name):
+			// An empty non-terminal, with names below it.
+			a.Status = NoError
+			return a
+		case z.wildcard(name) != nil:
+			sets = z.wildcard(name)
+		default:
+			a.Status = NXDomain
+			return a
+		}
+	}
+	return r.answer(a, network, sets, policies, chain)
+}
+
+// answer fills a from the record sets of its name: the record set of the
+// query type, or the CNAME followed to its target.
+func (r *Resolver) answer(a Answer, network string, sets map[string]rrset, policies bool, chain []string) Answer {
+	a.Status = NoError
+	if rs, ok := sets[a.Type]; ok {
+		for _, d := range rs.data {
+			a.Records = append(a.Records, Record{Name: a.Name, Type: a.Type, TTL: rs.ttl, Data: d})
+		}
+		return a
+	}
+	rs, ok := sets["CNAME"]
+	if !ok || len(rs.data) == 0 {
+		return a
+	}
+	a.Records = append(a.Records, Record{Name: a.Name, Type: "CNAME", TTL: rs.ttl, Data: rs.data[0]})
+	next := r.lookup(network, dns.Canonical(rs.data[0]), a.Type, policies, chain)
+	a.Status = next.Status
+	a.Records = append(a.Records, next.Records...)
+	a.Targets = next.Targets
+	return a
+}
+
+// rule returns the rule of the response policies bound to network matching
+// name: the rule of the name itself, else the wildcard rule of its closest
+// parent.
+func (r *Resolver) rule(network, name string) *rule {
+	var (
+		best  *rule
+		score = -1
+	)
+	for _, p := range r.policies {
+		if !slices.Contains(p.networks, network) {
+			continue
+		}
+		for _, ru := range p.rules {
+			s := -1
+			switch {
+			case ru.dnsName == name:
+				// Exact rules beat any wildcard rule.
+				s = len(ru.dnsName) + 1<<16
+			case strings.HasPrefix(ru.dnsName, "*."):
+				if strings.HasSuffix(name, ru.dnsName[1:]) {
+					s = len(ru.dnsName)
+				}
+			}
+			if s > score {
+				best, score = ru, s
+			}
+		}
+	}
+	return best
+}
+
+// zone returns the zone answering for name in network: the private zone
+// visible to the network with the longest domain, else the public zone with
+// the longest domain.
+func (r *Resolver) zone(network, name string) *zone {
+	var private, public *zone
+	for _, z := range r.zones {
+		if !dns.InDomain(name, z.domain) {
+			continue
+		}
+		switch {
+		case z.public:
+			if public == nil || len(z.domain) > len(public.domain) {
+				public = z
+			}
+		case network != "" && slices.Contains(z.networks, network):
+			if private == nil || len(z.domain) > len(private.domain) {
+				private = z
+			}
+		}
+	}
+	if private != nil {
+		return private
+	}
+	return public
+}
+
+// exists reports whether name is the zone apex, the owner of a record set
+// or an empty non-terminal above one.
+func (z *zone) exists(name string) bool {
+	if name == z.domain {
+		return true
+	}
+	for owner := range z.records {
+		if owner == name || strings.HasSuffix(owner, "."+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcard returns the record sets of the wildcard matching name, at its
+// closest existing parent (RFC 4592).
+func (z *zone) wildcard(name string) map[string]rrset {
+	for parent := name; parent != z.domain; {
+		_, parent, _ = strings.Cut(parent, ".")
+		if z.exists(parent) {
+			return z.records["*."+parent]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnstest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Server serves the answers a Resolver gives to one VPC network, over UDP
+// on the loopback interface.
+type Server struct {
+	resolver *Resolver
+	network  string
+	conn     net.PacketConn
+	done     chan struct{}
+}
+
+// NewServer starts a server answering the queries as sent from network. The
+// caller must Close it.
+func NewServer(r *Resolver, network string) (*Server, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{resolver: r, network: network, conn: conn, done: make(chan struct{})}
+	go s.serve()
+	return s, nil
+}
+
+// Addr returns the address of the server, e.g. 127.0.0.1:53535.
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	err := s.conn.Close()
+	<-s.done
+	return err
+}
+
+// NetResolver returns a resolver sending all its queries to the server.
+func (s *Server) NetResolver() *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", s.Addr())
+		},
+	}
+}
+
+func (s *Server) serve() {
+	defer close(s.done)
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+		if resp, err := s.respond(buf[:n]); err == nil {
+			s.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// rcodes maps the lookup outcomes to response codes. The server cannot
+// answer for forwarded names or names outside the configuration, so it
+// refuses them.
+var rcodes = map[Status]dnsmessage.RCode{
+	NoError:       dnsmessage.RCodeSuccess,
+	NXDomain:      dnsmessage.RCodeNameError,
+	ServFail:      dnsmessage.RCodeServerFailure,
+	Forwarded:     dnsmessage.RCodeRefused,
+	NotConfigured: dnsmessage.RCodeRefused,
+}
+
+func (s *Server) respond(query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+	a := s.resolver.Lookup(s.network, q.Name.String(), strings.TrimPrefix(q.Type.String(), "Type"))
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{
+		ID:                 h.ID,
+		Response:           true,
+		OpCode:             h.OpCode,
+		Authoritative:      true,
+		RecursionDesired:   h.RecursionDesired,
+		RecursionAvailable: true,
+		RCode:              rcodes[a.Status],
+	})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+	for _, rec := range a.Records {
+		// Records the message package cannot encode, or with invalid
+		// data, are left out of the response.
+		addResource(&b, rec)
+	}
+	return b.Finish()
+}
+
+func addResource(b *dnsmessage.Builder, rec Record) error {
+	name, err := dnsmessage.NewName(rec.Name)
+	if err != nil {
+		return err
+	}
+	h := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: uint32(rec.TTL)}
+	fields := strings.Fields(rec.Data)
+	switch rec.Type {
+	case "A", "AAAA":
+		addr, err := netip.ParseAddr(rec.Data)
+		if err != nil {
+			return err
+		}
+		if rec.Type == "A" && addr.Is4() {
+			return b.AResource(h, dnsmessage.AResource{A: addr.As4()})
+		}
+		if rec.Type == "AAAA" && addr.Is6() {
+			return b.AAAAResource(h, dnsmessage.AAAAResource{AAAA: addr.As16()})
+		}
+		return errors.New("address family mismatch")
+	case "CNAME", "NS", "PTR":
+		target, err := dnsmessage.NewName(rec.Data)
+		if err != nil {
+			return err
+		}
+		switch rec.Type {
+		case "CNAME":
+			return b.CNAMEResource(h, dnsmessage.CNAMEResource{CNAME: target})
+		case "NS":
+			return b.NSResource(h, dnsmessage.NSResource{NS: target})
+		}
+		return b.PTRResource(h, dnsmessage.PTRResource{PTR: target})
+	case "MX":
+		if len(fields) != 2 {
+			return errors.New("invalid MX record")
+		}
+		pref, err := strconv.ParseUint(fields[0], 10, 16)
+		if err != nil {
+			return err
+		}
+		mx, err := dnsmessage.NewName(fields[1])
+		if err != nil {
+			return err
+		}
+		return b.MXResource(h, dnsmessage.MXResource{Pref: uint16(pref), MX: mx})
+	case "SRV":
+		if len(fields) != 4 {
+			return errors.New("invalid SRV record")
+		}
+		var v [3]uint16
+		for i := range v {
+			n, err := strconv.ParseUint(fields[i], 10, 16)
+			if err != nil {
+				return err
+			}
+			v[i] = uint16(n)
+		}
+		target, err := dnsmessage.NewName(fields[3])
+		if err != nil {
+			return err
+		}
+		return b.SRVResource(h, dnsmessage.SRVResource{Priority: v[0], Weight: v[1], Port: v[2], Target: target})
+	case "TXT", "SPF":
+		return b.TXTResource(h, dnsmessage.TXTResource{TXT: TXTStrings(rec.Data)})
+	}
+	return errors.New("unsupported record type " + rec.Type)
+}
+
+// TXTStrings returns the character strings of the rdata of a TXT record:
+// the quoted strings without their quotes, or the whole rdata when it is not
+// quoted.
+func TXTStrings(rdata string) []string {
+	if !strings.HasPrefix(strings.TrimSpace(rdata), `"`) {
+		return []string{rdata}
+	}
+	var (
+		strs   []string
+		cur    strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(rdata); i++ {
+		c := rdata[i]
+		switch {
+		case c == '\\' && quoted && i+1 < len(rdata):
+			i++
+			cur.WriteByte(rdata[i])
+		case c == '"':
+			if quoted {
+				strs = append(strs, cur.String())
+				cur.Reset()
+			}
+			quoted = !quoted
+		case quoted:
+			cur.WriteByte(c)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drift lists the resources of a stage changed outside of Terraform,
+// such as by a console hotfix, and their changed attributes, from the
+// refresh-only plan of the stage.
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+// Resource is a resource changed outside of Terraform.
+type Resource struct {
+	Address string `json:"address"`
+	// Actions are update for a changed resource and delete for a deleted
+	// one.
+	Actions []string `json:"actions"`
+	// Attributes are the paths of the changed attributes, e.g.
+	// settings[0].tier, without their values, which may be sensitive.
+	Attributes []string `json:"attributes,omitempty"`
+}
+
+// Stage is the drift of a stage.
+type Stage struct {
+	Name      string     `json:"name"`
+	Resources []Resource `json:"resources"`
+}
+
+// Find returns the managed resources a refresh-only plan found changed
+// outside of Terraform.
+func Find(name string, plan *tfplan.Plan) *Stage {
+	s := &Stage{Name: name, Resources: []Resource{}}
+	for _, c := range plan.ResourceDrift {
+		if !c.Managed() || c.Has("no-op") {
+			continue
+		}
+		r := Resource{Address: c.Address, Actions: c.Change.Actions}
+		if c.Change.After != nil {
+			r.Attributes = Attributes(c.Change.Before, c.Change.After)
+		}
+		s.Resources = append(s.Resources, r)
+	}
+	return s
+}
+
+// Attributes returns the paths of the attributes whose values differ between
+// before and after, sorted. Lists of the same length are compared element
+// by element, other lists as a whole.
+func Attributes(before, after any) []string {
+	var out []string
+	diff("", before, after, &out)
+	sort.Strings(out)
+	return out
+}
+
+func diff(path string, before, after any, out *[]string) {
+	switch b := before.(type) {
+	case map[string]any:
+		if a, ok := after.(map[string]any); ok {
+			var keys []string
+			for k := range b {
+				keys = append(keys, k)
+			}
+			for k := range a {
+				if _, ok := b[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			for _, k := range keys {
+				p := k
+				if path != "" {
+					p = path + "." + k
+				}
+				diff(p, b[k], a[k], out)
+			}
+			return
+		}
+	case []any:
+		if a, ok := after.([]any); ok && len(a) == len(b) {
+			for i := range b {
+				diff(path+"["+strconv.Itoa(i)+"]", b[i], a[i], out)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(before, after) {
+		*out = append(*out, path)
+	}
+}
+
+// WriteText prints the drifted resources of the stage with their changed
+// attributes.
+func (s *Stage) WriteText(w io.Writer) error {
+	if len(s.Resources) == 0 {
+		_, err := fmt.Fprintf(w, "%s: no drift\n", s.Name)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s: %d resource(s) changed outside of Terraform\n", s.Name, len(s.Resources)); err != nil {
+		return err
+	}
+	for _, r := range s.Resources {
+		if _, err := fmt.Fprintf(w, "  %s %v\n", r.Address, r.Actions); err != nil {
+			return err
+		}
+		for _, a := range r.Attributes {
+			if _, err := fmt.Fprintf(w, "    %s\n", a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Write prints the drift of the stage in the given format, text or json.
+func (s *Stage) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return s.WriteText(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	return fmt.Errorf("unknown format %q, expected text or json", format)
+}
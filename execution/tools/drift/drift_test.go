@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drift
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+func TestFind(t *testing.T) {
+	plan, err := tfplan.ReadFile(filepath.Join("testdata", "refresh.json"))
+	if err != nil {
+		t.Fatalf("Failed to read the plan: %v", err)
+	}
+	got := Find("02-networking", plan)
+	want := &Stage{Name: "02-networking", Resources: []Resource{
+		{
+			Address:    `module.firewall.google_compute_firewall.rules["allow-ssh"]`,
+			Actions:    []string{"update"},
+			Attributes: []string{"allow[0].ports[0]", "description", "labels.owner", "source_ranges"},
+		},
+		{
+			Address:    `module.cloudsql["sql-1"].google_sql_database_instance.primary`,
+			Actions:    []string{"update"},
+			Attributes: []string{"settings[0].tier"},
+		},
+		{
+			Address: "module.vm.google_compute_instance.default",
+			Actions: []string{"delete"},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Find() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAttributes(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after any
+		want          []string
+	}{
+		{"equal", map[string]any{"a": 1.0}, map[string]any{"a": 1.0}, nil},
+		{"added key", map[string]any{}, map[string]any{"a": "x"}, []string{"a"}},
+		{"removed key", map[string]any{"a": "x"}, map[string]any{}, []string{"a"}},
+		{"null to value", map[string]any{"a": nil}, map[string]any{"a": map[string]any{"b": true}}, []string{"a"}},
+		{"nested", map[string]any{"a": []any{map[string]any{"b": 1.0, "c": 2.0}}}, map[string]any{"a": []any{map[string]any{"b": 1.0, "c": 3.0}}}, []string{"a[0].c"}},
+		{"list length", map[string]any{"a": []any{"x"}}, map[string]any{"a": []any{"x", "y"}}, []string{"a"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Attributes(tc.before, tc.after); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Attributes() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		stage *Stage
+		want  string
+	}{
+		{
+			name:  "no drift",
+			stage: &Stage{Name: "04-producer/CloudSQL"},
+			want:  "04-producer/CloudSQL: no drift\n",
+		},
+		{
+			name: "drift",
+			stage: &Stage{Name: "02-networking", Resources: []Resource{
+				{Address: "google_compute_firewall.ssh", Actions: []string{"update"}, Attributes: []string{"description", "source_ranges"}},
+				{Address: "google_compute_instance.vm", Actions: []string{"delete"}},
+			}},
+			want: `02-networking: 2 resource(s) changed outside of Terraform
+  google_compute_firewall.ssh [update]
+    description
+    source_ranges
+  google_compute_instance.vm [delete]
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.stage.Write(&buf, "text"); err != nil {
+				t.Fatalf("Failed to write the drift: %v", err)
+			}
+			if buf.String() != tc.want {
+				t.Errorf("Failed to write the drift:\ngot:\n%s\nwant:\n%s", buf.String(), tc.want)
+			}
+		})
+	}
+	if err := (&Stage{}).Write(&bytes.Buffer{}, "xml"); err == nil {
+		t.Errorf("Write() with an unknown format succeeded")
+	}
+}
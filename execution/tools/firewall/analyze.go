@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// SensitivePort is a port that must not be reachable from the internet.
+type SensitivePort struct {
+	Protocol string
+	Port     int
+	Service  string
+}
+
+// SensitivePorts are the ports reported when an ingress rule allows them from
+// 0.0.0.0/0.
+var SensitivePorts = []SensitivePort{
+	{"tcp", 22, "SSH"},
+	{"tcp", 3389, "RDP"},
+	{"tcp", 3306, "MySQL"},
+	{"tcp", 5432, "PostgreSQL"},
+	{"tcp", 1433, "SQL Server"},
+	{"tcp", 1521, "Oracle"},
+	{"tcp", 6379, "Redis"},
+	{"tcp", 27017, "MongoDB"},
+	{"tcp", 9042, "Cassandra"},
+	{"tcp", 11211, "Memcached"},
+}
+
+// Analyze reports, for the VPC firewall rules of every network and for the
+// rules of every policy:
+//
+//   - shadowed rules, fully matched by a rule evaluated first;
+//   - deny rules that never match, or only restate the implied deny ingress
+//     rule of the network;
+//   - exact duplicates, and near duplicates that could be merged;
+//   - rules of a policy sharing a priority, whatever their direction;
+//   - ingress rules exposing sensitive ports to 0.0.0.0/0.
+//
+// VPC rules and policy rules are evaluated in different steps, so rules are
+// only compared with the rules of their own network or policy.
+func Analyze(m *Model) []report.Finding {
+	var findings []report.Finding
+	for _, n := range m.Networks {
+		for _, dir := range []string{Ingress, Egress} {
+			rules := enabled(n.Rules, dir)
+			findings = append(findings, shadowed(rules)...)
+			findings = append(findings, duplicates(rules)...)
+			if dir == Ingress {
+				findings = append(findings, denyNoEffect(rules)...)
+			}
+		}
+		findings = append(findings, exposures(n.Rules)...)
+	}
+	for _, p := range m.Policies {
+		// Priorities are unique among all the rules of a policy, ingress and
+		// egress alike.
+		findings = append(findings, collisions(p.Rules)...)
+		for _, dir := range []string{Ingress, Egress} {
+			rules := enabled(p.Rules, dir)
+			findings = append(findings, shadowed(rules)...)
+			findings = append(findings, duplicates(rules)...)
+		}
+		findings = append(findings, exposures(p.Rules)...)
+	}
+	return findings
+}
+
+// enabled returns the enabled rules of a direction in evaluation order:
+// by priority, deny rules first among VPC rules of the same priority.
+func enabled(rules []*Rule, direction string) []*Rule {
+	var out []*Rule
+	for _, r := range rules {
+		if !r.Disabled && r.Direction == direction {
+			out = append(out, r)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority < out[j].Priority
+		}
+		return out[i].Action == Deny && out[j].Action != Deny
+	})
+	return out
+}
+
+// before reports whether a is evaluated before b and wins over it.
+func before(a, b *Rule) bool {
+	return a.Priority < b.Priority || (a.Priority == b.Priority && a.Action == Deny && b.Action != Deny)
+}
+
+func finding(sev report.Severity, check string, r *Rule, format string, args ...any) report.Finding {
+	return report.Finding{Severity: sev, Check: check, File: r.File, Resource: r.Resource(), Message: fmt.Sprintf(format, args...)}
+}
+
+// describe names another rule in a message, with its file when it differs.
+func describe(r, from *Rule) string {
+	s := fmt.Sprintf("%s rule %q (priority %d", r.Action, r.Name, r.Priority)
+	if r.File != from.File {
+		s += ", " + r.File
+	}
+	return s + ")"
+}
+
+// shadows reports whether a takes all the traffic of b: a matches it and is
+// evaluated first, or has the same priority and action and matches more.
+func shadows(a, b *Rule) bool {
+	if a == b || !a.Match.covers(b.Match) {
+		return false
+	}
+	return before(a, b) || (a.Priority == b.Priority && a.Action == b.Action && !b.Match.covers(a.Match))
+}
+
+func shadowed(rules []*Rule) []report.Finding {
+	var findings []report.Finding
+	for _, b := range rules {
+		for _, a := range rules {
+			if !shadows(a, b) {
+				continue
+			}
+			switch {
+			case a.Action == b.Action:
+				findings = append(findings, finding(report.Warning, "shadowed", b,
+					"never matches: %s already matches all its traffic", describe(a, b)))
+			case b.Action == Deny:
+				findings = append(findings, finding(report.Error, "deny-never-matches", b,
+					"never matches: %s matches all its traffic first", describe(a, b)))
+			default:
+				findings = append(findings, finding(report.Error, "shadowed", b,
+					"never matches: %s matches all its traffic first", describe(a, b)))
+			}
+			break
+		}
+	}
+	return findings
+}
+
+// denyNoEffect reports VPC deny ingress rules that no allow rule evaluated
+// after them overlaps: the implied deny ingress rule already blocks their
+// traffic.
+func denyNoEffect(rules []*Rule) []report.Finding {
+	var findings []report.Finding
+	for i, r := range rules {
+		if r.Action != Deny {
+			continue
+		}
+		effective := slices.ContainsFunc(rules[i+1:], func(a *Rule) bool {
+			return a.Action == Allow && before(r, a) && r.Match.overlaps(a.Match)
+		})
+		if !effective && !slices.ContainsFunc(rules, func(a *Rule) bool { return shadows(a, r) }) {
+			findings = append(findings, finding(report.Info, "deny-no-effect", r,
+				"no allow rule with a lower priority overlaps it, the implied deny ingress rule already blocks its traffic"))
+		}
+	}
+	return findings
+}
+
+func duplicates(rules []*Rule) []report.Finding {
+	var findings []report.Finding
+	for i, b := range rules {
+		for _, a := range rules[:i] {
+			if a.Action != b.Action || !sameSet(a.Match.Targets, b.Match.Targets) || !sameSet(a.Match.Sources, b.Match.Sources) {
+				continue
+			}
+			if shadows(a, b) || shadows(b, a) || a.Priority != b.Priority {
+				continue
+			}
+			if a.Match.covers(b.Match) && b.Match.covers(a.Match) {
+				findings = append(findings, finding(report.Warning, "duplicate", b,
+					"duplicates %s", describe(a, b)))
+				break
+			}
+			// Rules differing only by their ranges or by their ports can be
+			// merged.
+			if slices.Equal(a.Match.Ranges, b.Match.Ranges) || protocolsEqual(a.Match.Protocols, b.Match.Protocols) {
+				findings = append(findings, finding(report.Info, "near-duplicate", b,
+					"differs from %s only by its ranges or ports, consider merging them", describe(a, b)))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, s := range a {
+		if !slices.Contains(b, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func protocolsEqual(a, b []Protocol) bool {
+	covered := func(x, y []Protocol) bool {
+		for _, q := range y {
+			if !slices.ContainsFunc(x, func(p Protocol) bool { return p.covers(q) }) {
+				return false
+			}
+		}
+		return true
+	}
+	return covered(a, b) && covered(b, a)
+}
+
+// collisions reports the enabled rules of a policy using the priority of an
+// earlier enabled rule, in the order the rules are declared.
+func collisions(rules []*Rule) []report.Finding {
+	var findings []report.Finding
+	for i, b := range rules {
+		if b.Disabled {
+			continue
+		}
+		for _, a := range rules[:i] {
+			if !a.Disabled && a.Priority == b.Priority {
+				findings = append(findings, finding(report.Error, "priority-collision", b,
+					"priority %d is already used by rule %q of the policy", b.Priority, a.Name))
+				break
+			}
+		}
+	}
+	return findings
+}
+
+func exposures(rules []*Rule) []report.Finding {
+	var findings []report.Finding
+	for _, r := range rules {
+		i := slices.IndexFunc(r.Match.Ranges, func(p netip.Prefix) bool { return p.Bits() == 0 })
+		if r.Disabled || r.Direction != Ingress || r.Action != Allow || i < 0 {
+			continue
+		}
+		from := r.Match.Ranges[i]
+		if slices.ContainsFunc(r.Match.Protocols, func(p Protocol) bool { return p.Name == "all" }) {
+			findings = append(findings, finding(report.Error, "exposure", r, "allows all protocols from %s", from))
+			continue
+		}
+		var exposed []string
+		for _, sp := range SensitivePorts {
+			if slices.ContainsFunc(r.Match.Protocols, func(p Protocol) bool { return p.matchesPort(sp.Protocol, sp.Port) }) {
+				exposed = append(exposed, fmt.Sprintf("%s:%d (%s)", sp.Protocol, sp.Port, sp.Service))
+			}
+		}
+		if len(exposed) > 0 {
+			findings = append(findings, finding(report.Error, "exposure", r, "allows %s from %s", strings.Join(exposed, ", "), from))
+		}
+	}
+	return findings
+}
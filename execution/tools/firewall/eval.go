@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+)
+
+// Packet is the traffic evaluated against the rules of a network.
+type Packet struct {
+	Direction string
+	// Remote is the source of ingress traffic or the destination of egress
+	// traffic. It is a single address, or the range an instance gets its
+	// address from when the address is not known.
+	Remote netip.Prefix
+	// RemoteTags are the network tags and service accounts (prefixed with
+	// serviceAccount:) of the remote instance, matched by source tags.
+	RemoteTags []string
+	// Tags are the network tags and service accounts of the instance the
+	// rules apply to, matched by targets.
+	Tags     []string
+	Protocol string
+	Port     int
+}
+
+// Result is how a rule matches a packet.
+type Result int
+
+const (
+	NoMatch Result = iota
+	// PartialMatch is returned when the rule only matches some of the
+	// addresses of Packet.Remote.
+	PartialMatch
+	FullMatch
+)
+
+// Matches returns how the rule matches the packet.
+func (r *Rule) Matches(p Packet) Result {
+	if r.Disabled || r.Direction != p.Direction {
+		return NoMatch
+	}
+	if len(r.Match.Targets) > 0 && !slices.ContainsFunc(r.Match.Targets, func(t string) bool { return slices.Contains(p.Tags, t) }) {
+		return NoMatch
+	}
+	if !slices.ContainsFunc(r.Match.Protocols, func(q Protocol) bool { return q.matchesPort(normalizeProtocol(p.Protocol), p.Port) }) {
+		return NoMatch
+	}
+	if slices.ContainsFunc(r.Match.Sources, func(s string) bool { return slices.Contains(p.RemoteTags, s) }) {
+		return FullMatch
+	}
+	if slices.ContainsFunc(r.Match.Ranges, func(q netip.Prefix) bool { return contains(q, p.Remote) }) {
+		return FullMatch
+	}
+	if slices.ContainsFunc(r.Match.Ranges, p.Remote.Overlaps) {
+		return PartialMatch
+	}
+	return NoMatch
+}
+
+// Step is a rule, or an implied rule, considered by Evaluate.
+type Step struct {
+	// Rule is nil for the implied rules.
+	Rule   *Rule
+	Result Result
+	Detail string
+}
+
+// Decision is the outcome of the evaluation of a packet.
+type Decision struct {
+	Allowed bool
+	// Certain is false when a rule matching only part of Packet.Remote would
+	// have decided otherwise.
+	Certain bool
+	// Path lists the matching rules in evaluation order, ending with the
+	// rule that decided.
+	Path []Step
+}
+
+// Evaluate returns whether the rules of a network allow the packet. Like
+// Cloud NGFW with the default enforcement order, it evaluates the
+// hierarchical policies, then the VPC firewall rules, then the network
+// policies attached to the network, and finally the implied rules: deny
+// ingress and allow egress. goto_next rules and policies without a matching
+// rule hand the packet over to the next step.
+func (m *Model) Evaluate(network string, p Packet) Decision {
+	d := Decision{Certain: true}
+	var partial []*Rule
+	// decide returns true once a rule fully matches with a final action.
+	decide := func(rules []*Rule) bool {
+		for _, r := range enabled(rules, p.Direction) {
+			switch res := r.Matches(p); res {
+			case PartialMatch:
+				partial = append(partial, r)
+				d.Path = append(d.Path, Step{Rule: r, Result: res, Detail: fmt.Sprintf("%s matches part of %s", describeRule(r), p.Remote)})
+			case FullMatch:
+				d.Path = append(d.Path, Step{Rule: r, Result: res, Detail: describeRule(r) + " matches"})
+				if r.Action == GotoNext {
+					return false
+				}
+				d.Allowed = r.Action != Deny
+				return true
+			}
+		}
+		return false
+	}
+	levels := [][]*Rule{}
+	for _, pol := range m.Policies {
+		if pol.Hierarchical {
+			levels = append(levels, pol.Rules)
+		}
+	}
+	var policies []*Policy
+	if n := m.Network(network); n != nil {
+		levels = append(levels, n.Rules)
+		policies = n.Policies
+	}
+	for _, pol := range policies {
+		levels = append(levels, pol.Rules)
+	}
+	decided := false
+	for _, rules := range levels {
+		if decided = decide(rules); decided {
+			break
+		}
+	}
+	if !decided {
+		d.Allowed = p.Direction == Egress
+		detail := "implied deny ingress rule"
+		if d.Allowed {
+			detail = "implied allow egress rule"
+		}
+		d.Path = append(d.Path, Step{Result: FullMatch, Detail: detail})
+	}
+	for _, r := range partial {
+		if (r.Action == Deny && d.Allowed) || (r.Action == Allow && !d.Allowed) {
+			d.Certain = false
+		}
+	}
+	return d
+}
+
+func describeRule(r *Rule) string {
+	return fmt.Sprintf("%s %s (priority %d, %s)", r.Action, r.Resource(), r.Priority, r.File)
+}
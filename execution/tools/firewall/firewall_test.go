@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func TestAnalyze(t *testing.T) {
+	tree, err := config.LoadTree("testdata/config")
+	if err != nil {
+		t.Fatalf("Failed to load the configuration: %v", err)
+	}
+	m, findings := Load(tree)
+	if len(m.Networks) != 1 || m.Networks[0].Name != "vpc-a" || len(m.Networks[0].Policies) != 1 {
+		t.Fatalf("Load() networks = %+v, want vpc-a with one policy", m.Networks)
+	}
+	findings = append(findings, Analyze(m)...)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	want := []string{
+		`ERROR   security/mig.tfvars: ingress_rule "allow-bad-port": "10.0.0.0/33" is not a CIDR range [invalid]`,
+		`ERROR   security/mig.tfvars: ingress_rule "allow-bad-port": invalid port range "70000" [invalid]`,
+		`WARNING security/gce.tfvars: ingress_rule "allow-web": never matches: allow rule "allow-internal" (priority 900) already matches all its traffic [shadowed]`,
+		`ERROR   security/gce.tfvars: ingress_rule "deny-web": never matches: allow rule "allow-internal" (priority 900) matches all its traffic first [deny-never-matches]`,
+		`WARNING security/mig.tfvars: ingress_rule "allow-db-copy": duplicates allow rule "allow-db" (priority 1000, security/gce.tfvars) [duplicate]`,
+		`INFO    security/mig.tfvars: ingress_rule "allow-mysql": differs from allow rule "allow-db" (priority 1000, security/gce.tfvars) only by its ranges or ports, consider merging them [near-duplicate]`,
+		`INFO    security/gce.tfvars: ingress_rule "deny-telnet": no allow rule with a lower priority overlaps it, the implied deny ingress rule already blocks its traffic [deny-no-effect]`,
+		`ERROR   security/gce.tfvars: ingress_rule "allow-ssh-anywhere": allows tcp:22 (SSH) from 0.0.0.0/0 [exposure]`,
+		`ERROR   security/Firewall/FirewallPolicy/config/policy.yaml: ingress_rule "allow-ssh-all" of policy "policy-a": priority 100 is already used by rule "allow-ssh-iap" of the policy [priority-collision]`,
+		`WARNING security/Firewall/FirewallPolicy/config/policy.yaml: ingress_rule "allow-ssh-iap" of policy "policy-a": never matches: allow rule "allow-ssh-all" (priority 100) already matches all its traffic [shadowed]`,
+		`ERROR   security/Firewall/FirewallPolicy/config/policy.yaml: ingress_rule "deny-iap" of policy "policy-a": never matches: allow rule "allow-ssh-iap" (priority 100) matches all its traffic first [deny-never-matches]`,
+		`WARNING security/Firewall/FirewallPolicy/config/policy.yaml: egress_rule "smtp-again" of policy "policy-a": never matches: deny rule "smtp" (priority 1000) already matches all its traffic [shadowed]`,
+		`ERROR   security/Firewall/FirewallPolicy/config/policy.yaml: ingress_rule "allow-ssh-all" of policy "policy-a": allows tcp:22 (SSH) from 0.0.0.0/0 [exposure]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Analyze() findings mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPriorityCollisionAcrossDirections(t *testing.T) {
+	p := &Policy{Name: "policy-a", File: "policy.yaml"}
+	p.Rules = []*Rule{
+		{Name: "allow-iap", Direction: Ingress, Action: Allow, Priority: 100, Policy: p, File: p.File},
+		{Name: "deny-smtp", Direction: Egress, Action: Deny, Priority: 100, Policy: p, File: p.File},
+		{Name: "allow-dns", Direction: Egress, Action: Allow, Priority: 200, Policy: p, File: p.File},
+		{Name: "allow-hc", Direction: Ingress, Action: Allow, Priority: 200, Policy: p, File: p.File, Disabled: true},
+	}
+	var got []string
+	for _, f := range Analyze(&Model{Policies: []*Policy{p}}) {
+		if f.Check == "priority-collision" {
+			got = append(got, f.String())
+		}
+	}
+	want := []string{
+		`ERROR   policy.yaml: egress_rule "deny-smtp" of policy "policy-a": priority 100 is already used by rule "allow-iap" of the policy [priority-collision]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Analyze() collisions mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestProtocolCovers(t *testing.T) {
+	tcp := func(ports ...PortRange) Protocol { return Protocol{Name: "tcp", Ports: ports} }
+	testCases := []struct {
+		name         string
+		p, q         Protocol
+		wantCovers   bool
+		wantOverlaps bool
+	}{
+		{name: "AllProtocols", p: Protocol{Name: "all"}, q: tcp(PortRange{22, 22}), wantCovers: true, wantOverlaps: true},
+		{name: "AllPorts", p: tcp(), q: tcp(PortRange{80, 443}), wantCovers: true, wantOverlaps: true},
+		{name: "PortsDoNotCoverAllPorts", p: tcp(PortRange{1, 65535}), q: tcp(), wantCovers: false, wantOverlaps: true},
+		{name: "Range", p: tcp(PortRange{8000, 8080}), q: tcp(PortRange{8080, 8080}), wantCovers: true, wantOverlaps: true},
+		{name: "PartialOverlap", p: tcp(PortRange{22, 23}), q: tcp(PortRange{23, 25}), wantCovers: false, wantOverlaps: true},
+		{name: "OtherProtocol", p: Protocol{Name: "udp"}, q: tcp(PortRange{53, 53}), wantCovers: false, wantOverlaps: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.p.covers(tc.q); got != tc.wantCovers {
+				t.Errorf("%s covers %s = %v, want %v", tc.p, tc.q, got, tc.wantCovers)
+			}
+			if got := tc.p.overlaps(tc.q); got != tc.wantOverlaps {
+				t.Errorf("%s overlaps %s = %v, want %v", tc.p, tc.q, got, tc.wantOverlaps)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package firewall
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PortRange is an inclusive range of ports.
+type PortRange struct {
+	From, To int
+}
+
+func (r PortRange) String() string {
+	if r.From == r.To {
+		return strconv.Itoa(r.From)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// ParsePortRange parses a port (22) or a port range (8000-8080).
+func ParsePortRange(s string) (PortRange, error) {
+	from, to, found := strings.Cut(strings.TrimSpace(s), "-")
+	lo, err := strconv.Atoi(from)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port %q", s)
+	}
+	hi := lo
+	if found {
+		if hi, err = strconv.Atoi(to); err != nil {
+			return PortRange{}, fmt.Errorf("invalid port range %q", s)
+		}
+	}
+	if lo < 0 || hi > 65535 || lo > hi {
+		return PortRange{}, fmt.Errorf("invalid port range %q", s)
+	}
+	return PortRange{lo, hi}, nil
+}
+
+// Protocol is a protocol with the ports a rule matches. A protocol "all" or
+// an empty port list matches every port.
+type Protocol struct {
+	Name  string
+	Ports []PortRange
+}
+
+func (p Protocol) String() string {
+	if len(p.Ports) == 0 {
+		return p.Name
+	}
+	ports := make([]string, len(p.Ports))
+	for i, r := range p.Ports {
+		ports[i] = r.String()
+	}
+	return p.Name + ":" + strings.Join(ports, ",")
+}
+
+// protocolNames maps the IANA numbers accepted by the API to names.
+var protocolNames = map[string]string{"1": "icmp", "6": "tcp", "17": "udp", "47": "gre", "50": "esp", "51": "ah", "132": "sctp"}
+
+func normalizeProtocol(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if n, ok := protocolNames[name]; ok {
+		return n
+	}
+	if name == "" {
+		return "all"
+	}
+	return name
+}
+
+// covers reports whether p matches every port matched by q.
+func (p Protocol) covers(q Protocol) bool {
+	if p.Name == "all" {
+		return true
+	}
+	if p.Name != q.Name {
+		return false
+	}
+	if len(p.Ports) == 0 {
+		return true
+	}
+	if len(q.Ports) == 0 {
+		return false
+	}
+	for _, r := range q.Ports {
+		if !slices.ContainsFunc(p.Ports, func(s PortRange) bool { return s.From <= r.From && r.To <= s.To }) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlaps reports whether p and q match a common port.
+func (p Protocol) overlaps(q Protocol) bool {
+	if p.Name == "all" || q.Name == "all" {
+		return true
+	}
+	if p.Name != q.Name {
+		return false
+	}
+	if len(p.Ports) == 0 || len(q.Ports) == 0 {
+		return true
+	}
+	for _, r := range p.Ports {
+		for _, s := range q.Ports {
+			if r.From <= s.To && s.From <= r.To {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesPort reports whether p matches the given protocol and port.
+func (p Protocol) matchesPort(protocol string, port int) bool {
+	return p.covers(Protocol{Name: protocol, Ports: []PortRange{{port, port}}})
+}
+
+// Match is the traffic a rule applies to.
+type Match struct {
+	// Ranges are the source ranges of an ingress rule or the destination
+	// ranges of an egress rule.
+	Ranges []netip.Prefix
+	// Sources are the source tags or service accounts of an ingress rule,
+	// matched in addition to Ranges.
+	Sources []string
+	// Targets are the target tags or service accounts, every instance of the
+	// network when empty.
+	Targets   []string
+	Protocols []Protocol
+}
+
+// covers reports whether m matches all the traffic matched by n.
+func (m Match) covers(n Match) bool {
+	for _, r := range n.Ranges {
+		if !slices.ContainsFunc(m.Ranges, func(s netip.Prefix) bool { return contains(s, r) }) {
+			return false
+		}
+	}
+	for _, s := range n.Sources {
+		if !slices.Contains(m.Sources, s) {
+			return false
+		}
+	}
+	if len(m.Targets) > 0 {
+		if len(n.Targets) == 0 {
+			return false
+		}
+		for _, t := range n.Targets {
+			if !slices.Contains(m.Targets, t) {
+				return false
+			}
+		}
+	}
+	for _, q := range n.Protocols {
+		if !slices.ContainsFunc(m.Protocols, func(p Protocol) bool { return p.covers(q) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlaps reports whether some traffic may be matched by both m and n.
+func (m Match) overlaps(n Match) bool {
+	sources := slices.ContainsFunc(m.Ranges, func(r netip.Prefix) bool {
+		return slices.ContainsFunc(n.Ranges, r.Overlaps)
+	}) || slices.ContainsFunc(m.Sources, func(s string) bool { return slices.Contains(n.Sources, s) })
+	targets := len(m.Targets) == 0 || len(n.Targets) == 0 ||
+		slices.ContainsFunc(m.Targets, func(t string) bool { return slices.Contains(n.Targets, t) })
+	protocols := slices.ContainsFunc(m.Protocols, func(p Protocol) bool {
+		return slices.ContainsFunc(n.Protocols, p.overlaps)
+	})
+	return sources && targets && protocols
+}
+
+func contains(block, p netip.Prefix) bool {
+	return block.Bits() <= p.Bits() && block.Contains(p.Addr())
+}
@@ -0,0 +1,333 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package firewall loads the VPC firewall rules of the 03-security stages and
+// the rules of the firewall policies into one model per network, and
+// analyzes them for shadowed, duplicate and dangerous rules.
+package firewall
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// Directions of a rule.
+const (
+	Ingress = "INGRESS"
+	Egress  = "EGRESS"
+)
+
+// Actions of a rule. VPC firewall rules only allow or deny.
+const (
+	Allow    = "allow"
+	Deny     = "deny"
+	GotoNext = "goto_next"
+)
+
+// DefaultPriority is the priority of VPC firewall rules that set none.
+const DefaultPriority = 1000
+
+var anyIPv4 = netip.MustParsePrefix("0.0.0.0/0")
+
+// Rule is a VPC firewall rule or a firewall policy rule.
+type Rule struct {
+	Name      string
+	Direction string
+	Action    string
+	Priority  int
+	Disabled  bool
+	Match     Match
+	// Policy is the firewall policy of the rule, nil for VPC firewall rules.
+	Policy *Policy
+	Stage  string
+	File   string
+}
+
+// Resource returns the rule as a report resource.
+func (r *Rule) Resource() string {
+	kind := strings.ToLower(r.Direction) + "_rule"
+	if r.Policy != nil {
+		return fmt.Sprintf("%s %q of policy %q", kind, r.Name, r.Policy.Name)
+	}
+	return fmt.Sprintf("%s %q", kind, r.Name)
+}
+
+// Policy is a network or hierarchical firewall policy.
+type Policy struct {
+	Name     string
+	ParentID string
+	Region   string
+	// Networks are the networks the policy is attached to. Hierarchical
+	// policies, attached to folders or organizations, apply to every network.
+	Networks     []string
+	Hierarchical bool
+	Rules        []*Rule
+	File         string
+}
+
+// Network is the set of rules evaluated for the traffic of a network.
+type Network struct {
+	Name string
+	// Rules are the VPC firewall rules of the network.
+	Rules []*Rule
+	// Policies are the network firewall policies attached to the network.
+	Policies []*Policy
+}
+
+// Model is the firewall configuration of a tree.
+type Model struct {
+	Networks []*Network
+	Policies []*Policy
+}
+
+// Network returns the named network, nil when it has no rule or policy.
+func (m *Model) Network(name string) *Network {
+	for _, n := range m.Networks {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+func (m *Model) network(name string) *Network {
+	if n := m.Network(name); n != nil {
+		return n
+	}
+	n := &Network{Name: name}
+	m.Networks = append(m.Networks, n)
+	return n
+}
+
+// Load builds the model of the 03-security stages of a tree. Values that
+// cannot be interpreted, such as invalid CIDRs or ports, are returned as
+// findings and left out of the model.
+func Load(tree *config.Tree) (*Model, []report.Finding) {
+	l := &loader{model: &Model{}}
+	for _, s := range tree.Stages {
+		switch v := s.Vars.(type) {
+		case *config.FirewallRulesVars:
+			network := l.model.network(networkName(v.Network))
+			for _, dir := range []struct {
+				name  string
+				rules config.FirewallRules
+			}{{Ingress, v.IngressRules}, {Egress, v.EgressRules}} {
+				for _, name := range sortedKeys(dir.rules) {
+					if r := l.vpcRule(name, dir.name, dir.rules[name], s.Stage.Name, s.VarsFile); r != nil {
+						network.Rules = append(network.Rules, r)
+					}
+				}
+			}
+		}
+		for _, d := range s.Documents {
+			if v, ok := d.Value.(*config.FirewallPolicyStruct); ok {
+				l.policy(v, s.Stage.Name, d.File)
+			}
+		}
+	}
+	sort.SliceStable(l.model.Networks, func(i, j int) bool { return l.model.Networks[i].Name < l.model.Networks[j].Name })
+	return l.model, l.findings
+}
+
+type loader struct {
+	model    *Model
+	findings []report.Finding
+}
+
+func (l *loader) errorf(file, resource, format string, args ...any) {
+	l.findings = append(l.findings, report.Finding{
+		Severity: report.Error, Check: "invalid", File: file, Resource: resource, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// networkName returns the name of a network given by name or self link.
+func networkName(ref string) string {
+	if i := strings.LastIndex(ref, "/networks/"); i >= 0 {
+		return strings.Trim(ref[i+len("/networks/"):], "/")
+	}
+	return ref
+}
+
+func sortedKeys(m config.FirewallRules) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func placeholder(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || (strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">"))
+}
+
+// ranges parses CIDRs, skipping empty template values.
+func (l *loader) ranges(cidrs []string, r *Rule) []netip.Prefix {
+	var ranges []netip.Prefix
+	for _, c := range cidrs {
+		if placeholder(c) {
+			continue
+		}
+		p, err := netip.ParsePrefix(strings.TrimSpace(c))
+		if err != nil {
+			// A single address is accepted as a /32.
+			a, aerr := netip.ParseAddr(strings.TrimSpace(c))
+			if aerr != nil {
+				l.errorf(r.File, r.Resource(), "%q is not a CIDR range", c)
+				continue
+			}
+			p = netip.PrefixFrom(a, a.BitLen())
+		}
+		ranges = append(ranges, p.Masked())
+	}
+	return ranges
+}
+
+func (l *loader) protocol(name string, ports []string, r *Rule) Protocol {
+	p := Protocol{Name: normalizeProtocol(name)}
+	for _, s := range ports {
+		pr, err := ParsePortRange(s)
+		if err != nil {
+			l.errorf(r.File, r.Resource(), "%v", err)
+			continue
+		}
+		p.Ports = append(p.Ports, pr)
+	}
+	return p
+}
+
+// vpcRule converts a 03-security rule with the defaults of the
+// net-vpc-firewall module: priority 1000, all protocols, and 0.0.0.0/0 as
+// source of ingress rules without sources and destination of egress rules.
+func (l *loader) vpcRule(name, direction string, c config.FirewallRuleConfig, stage, file string) *Rule {
+	r := &Rule{Name: name, Direction: direction, Action: Allow, Priority: DefaultPriority, Disabled: c.Disabled, Stage: stage, File: file}
+	if c.Deny {
+		r.Action = Deny
+	}
+	if c.Priority != nil {
+		r.Priority = *c.Priority
+	}
+	cidrs := c.SourceRanges
+	if direction == Egress {
+		cidrs = c.DestinationRanges
+	}
+	ranges := l.ranges(cidrs, r)
+	if len(cidrs) == 0 && (direction == Egress || len(c.Sources) == 0) {
+		ranges = []netip.Prefix{anyIPv4}
+	}
+	r.Match.Ranges = ranges
+	prefix := ""
+	if c.UseServiceAccounts {
+		prefix = "serviceAccount:"
+	}
+	for _, s := range c.Sources {
+		r.Match.Sources = append(r.Match.Sources, prefix+s)
+	}
+	for _, t := range c.Targets {
+		r.Match.Targets = append(r.Match.Targets, prefix+t)
+	}
+	for _, p := range c.Rules {
+		r.Match.Protocols = append(r.Match.Protocols, l.protocol(p.Protocol, p.Ports, r))
+	}
+	if len(c.Rules) == 0 {
+		r.Match.Protocols = []Protocol{{Name: "all"}}
+	}
+	if len(ranges) == 0 && len(r.Match.Sources) == 0 {
+		// Every range was a template placeholder.
+		return nil
+	}
+	return r
+}
+
+func (l *loader) policy(c *config.FirewallPolicyStruct, stage, file string) {
+	p := &Policy{Name: c.Name, ParentID: c.ParentID, Region: c.Region, File: file}
+	p.Hierarchical = strings.HasPrefix(c.ParentID, "folders/") || strings.HasPrefix(c.ParentID, "organizations/") ||
+		strings.HasPrefix(c.ParentID, "folder/") || strings.HasPrefix(c.ParentID, "organization/")
+	if !p.Hierarchical {
+		for _, k := range sortedAttachments(c.Attachments) {
+			if ref := c.Attachments[k]; !placeholder(ref) {
+				p.Networks = append(p.Networks, networkName(ref))
+			}
+		}
+	}
+	for _, dir := range []struct {
+		name   string
+		rules  []config.FirewallPolicyRule
+		action string
+	}{{Ingress, c.IngressRules, Allow}, {Egress, c.EgressRules, Deny}} {
+		for i, pr := range dir.rules {
+			name := pr.Name
+			if name == "" {
+				name = fmt.Sprint(i)
+			}
+			r := &Rule{Name: name, Direction: dir.name, Action: pr.Action, Disabled: pr.Disabled, Policy: p, Stage: stage, File: file}
+			if r.Action == "" {
+				// The stage ingress_action and egress_action defaults.
+				r.Action = dir.action
+			}
+			if pr.Priority == nil {
+				l.errorf(file, r.Resource(), "priority is required")
+				continue
+			}
+			r.Priority = *pr.Priority
+			cidrs := pr.Match.SourceRanges
+			if dir.name == Egress {
+				cidrs = pr.Match.DestinationRanges
+			}
+			r.Match.Ranges = l.ranges(cidrs, r)
+			if dir.name == Ingress {
+				r.Match.Sources = pr.Match.SourceTags
+			}
+			if len(r.Match.Ranges) == 0 && len(r.Match.Sources) == 0 {
+				r.Match.Ranges = []netip.Prefix{anyIPv4}
+			}
+			r.Match.Targets = append(append([]string(nil), pr.TargetTags...), prefixed("serviceAccount:", pr.TargetAccounts)...)
+			for _, l4 := range pr.Match.Layer4Configs {
+				r.Match.Protocols = append(r.Match.Protocols, l.protocol(l4.Protocol, l4.Ports, r))
+			}
+			if len(r.Match.Protocols) == 0 {
+				r.Match.Protocols = []Protocol{{Name: "all"}}
+			}
+			p.Rules = append(p.Rules, r)
+		}
+	}
+	l.model.Policies = append(l.model.Policies, p)
+	for _, n := range p.Networks {
+		network := l.model.network(n)
+		network.Policies = append(network.Policies, p)
+	}
+}
+
+func sortedAttachments(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func prefixed(prefix string, values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = prefix + v
+	}
+	return out
+}
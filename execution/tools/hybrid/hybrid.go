@@ -0,0 +1,324 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hybrid validates the HA VPN and Dedicated Interconnect inputs of
+// the 02-networking stage: BGP ASNs, link-local BGP ranges, peer addresses,
+// VLAN tags and attachment bandwidths.
+package hybrid
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// LinkLocal is the block BGP sessions and VLAN attachment BGP ranges are
+// taken from.
+var LinkLocal = netip.MustParsePrefix("169.254.0.0/16")
+
+// Bandwidths are the accepted VLAN attachment bandwidths.
+var Bandwidths = []string{
+	"BPS_50M", "BPS_100M", "BPS_200M", "BPS_300M", "BPS_400M", "BPS_500M",
+	"BPS_1G", "BPS_2G", "BPS_5G", "BPS_10G", "BPS_20G", "BPS_50G", "BPS_100G", "BPS_400G",
+}
+
+// Default values of the 02-networking variables.
+const (
+	DefaultRouterASN = 64513
+	DefaultBandwidth = "BPS_1G"
+)
+
+var defaultAttachmentNames = [2]string{"dedicated-ic-vlan-attachment-3", "dedicated-ic-vlan-attachment-4"}
+
+var defaultTunnelRanges = [2]string{"169.254.1.2/30", "169.254.2.2/30"}
+
+// Checks reported by Validate.
+const (
+	CheckASN       = "asn"
+	CheckBGPRange  = "bgp-range"
+	CheckPeerIP    = "peer-ip"
+	CheckVLANTag   = "vlan-tag"
+	CheckBandwidth = "bandwidth"
+)
+
+// PrivateASN reports whether asn is in the RFC6996 private ranges,
+// 64512-65534 and 4200000000-4294967294.
+func PrivateASN(asn int64) bool {
+	return (asn >= 64512 && asn <= 65534) || (asn >= 4200000000 && asn <= 4294967294)
+}
+
+// validASN reports whether asn can be used by a BGP peer: it is neither 0,
+// AS_TRANS (23456), nor the last ASN of the 16 and 32 bit spaces.
+func validASN(asn int64) bool {
+	return asn > 0 && asn < 4294967295 && asn != 23456 && asn != 65535
+}
+
+type validator struct {
+	file     string
+	findings []report.Finding
+}
+
+func (v *validator) add(sev report.Severity, check, resource, format string, args ...any) {
+	v.findings = append(v.findings, report.Finding{
+		Severity: sev, Check: check, File: v.file, Resource: resource, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate checks the networking stage of a tree. HA VPN inputs are only
+// checked when create_havpn is set, and interconnect inputs when
+// create_interconnect is set.
+func Validate(tree *config.Tree) []report.Finding {
+	s := tree.Stage("networking")
+	if s == nil {
+		return nil
+	}
+	vars, ok := s.Vars.(*config.NetworkingVars)
+	if !ok {
+		return nil
+	}
+	v := &validator{file: s.VarsFile}
+	var sessions []linkLocalRange
+	if vars.CreateHAVPN.Enabled() {
+		sessions = v.haVPN(vars)
+	}
+	if vars.CreateInterconnect.Enabled() {
+		sessions = append(sessions, v.interconnect(vars)...)
+	}
+	v.overlaps(sessions)
+	return v.findings
+}
+
+// routerASN checks the ASN of a Cloud Router, which must be private.
+func (v *validator) routerASN(resource, variable string, asn int64) {
+	switch {
+	case !validASN(asn):
+		v.add(report.Error, CheckASN, resource, "%s %d is not a valid ASN", variable, asn)
+	case !PrivateASN(asn):
+		v.add(report.Error, CheckASN, resource, "%s %d is a public ASN, a Cloud Router needs a private ASN (64512-65534 or 4200000000-4294967294)", variable, asn)
+	}
+}
+
+// peerASN checks the ASN of a BGP peer: it may be public, but must differ
+// from the ASN of the Cloud Router.
+func (v *validator) peerASN(resource, variable string, asn, router int64) {
+	switch {
+	case !validASN(asn):
+		v.add(report.Error, CheckASN, resource, "%s %d is not a valid ASN", variable, asn)
+	case asn == router:
+		v.add(report.Error, CheckASN, resource, "%s %d is the ASN of the Cloud Router, the BGP session must be external", variable, asn)
+	case !PrivateASN(asn):
+		v.add(report.Info, CheckASN, resource, "%s %d is a public ASN, make sure it is registered to the peer network", variable, asn)
+	}
+}
+
+// linkLocalRange is a BGP range, for the overlap check.
+type linkLocalRange struct {
+	resource string
+	prefix   netip.Prefix
+}
+
+func (v *validator) haVPN(vars *config.NetworkingVars) []linkLocalRange {
+	router := int64(DefaultRouterASN)
+	if vars.Router1ASN != nil {
+		router = *vars.Router1ASN
+	}
+	v.routerASN("router \"router1\"", "router1_asn", router)
+	tunnels := []struct {
+		n                 int
+		peerIP, sessionIP string
+		peerASN           *int64
+	}{
+		{1, vars.Tunnel1BGPPeerIPAddress, vars.Tunnel1RouterBGPSessionRange, vars.Tunnel1BGPPeerASN},
+		{2, vars.Tunnel2BGPPeerIPAddress, vars.Tunnel2RouterBGPSessionRange, vars.Tunnel2BGPPeerASN},
+	}
+	var ranges []linkLocalRange
+	for i, t := range tunnels {
+		resource := fmt.Sprintf("vpn_tunnel %q", fmt.Sprintf("remote-%d", i))
+		prefix := fmt.Sprintf("tunnel_%d_", t.n)
+		if t.peerASN == nil {
+			v.add(report.Error, CheckASN, resource, "%sbgp_peer_asn is required", prefix)
+		} else {
+			v.peerASN(resource, prefix+"bgp_peer_asn", *t.peerASN, router)
+		}
+		session := t.sessionIP
+		if session == "" {
+			session = defaultTunnelRanges[i]
+		}
+		local, ok := v.session(resource, prefix+"router_bgp_session_range", session)
+		if ok {
+			ranges = append(ranges, linkLocalRange{resource, local.Masked()})
+		}
+		v.peerIP(resource, prefix, t.peerIP, local, ok)
+	}
+	// The stage passes tunnel_2_bgp_peer_asn to both tunnels.
+	if a, b := vars.Tunnel1BGPPeerASN, vars.Tunnel2BGPPeerASN; a != nil && b != nil && *a != *b {
+		v.add(report.Warning, CheckASN, `vpn_tunnel "remote-0"`,
+			"tunnel_1_bgp_peer_asn %d differs from tunnel_2_bgp_peer_asn %d, which the stage uses for both tunnels", *a, *b)
+	}
+	return ranges
+}
+
+// session parses the Cloud Router side of a tunnel BGP session, an address
+// and its /30, e.g. 169.254.1.2/30.
+func (v *validator) session(resource, variable, value string) (netip.Prefix, bool) {
+	p, err := netip.ParsePrefix(value)
+	switch {
+	case err != nil:
+		v.add(report.Error, CheckBGPRange, resource, "%s %q is not an address with a prefix length, e.g. 169.254.1.2/30", variable, value)
+	case !p.Addr().Is4() || !LinkLocal.Contains(p.Addr()):
+		v.add(report.Error, CheckBGPRange, resource, "%s %s is not in %s", variable, p, LinkLocal)
+	case p.Bits() != 30:
+		v.add(report.Error, CheckBGPRange, resource, "%s %s must be a /30", variable, p)
+	case !usable(p.Addr(), p.Masked()):
+		v.add(report.Error, CheckBGPRange, resource, "%s %s is the network or broadcast address of %s, use one of its two host addresses", variable, p.Addr(), p.Masked())
+	default:
+		return p, true
+	}
+	return p, false
+}
+
+// usable reports whether addr is a host address of prefix.
+func usable(addr netip.Addr, prefix netip.Prefix) bool {
+	last := prefix.Addr()
+	for range 1<<(32-prefix.Bits()) - 1 {
+		last = last.Next()
+	}
+	return prefix.Contains(addr) && addr != prefix.Addr() && addr != last
+}
+
+func (v *validator) peerIP(resource, prefix, value string, session netip.Prefix, sessionOK bool) {
+	variable := prefix + "bgp_peer_ip_address"
+	if value == "" {
+		v.add(report.Error, CheckPeerIP, resource, "%s is required", variable)
+		return
+	}
+	addr, err := netip.ParseAddr(value)
+	switch {
+	case err != nil || !addr.Is4():
+		v.add(report.Error, CheckPeerIP, resource, "%s %q is not an IPv4 address", variable, value)
+	case !sessionOK:
+	case !session.Masked().Contains(addr):
+		v.add(report.Error, CheckPeerIP, resource, "%s %s is not in the BGP session range %s", variable, addr, session.Masked())
+	case addr == session.Addr():
+		v.add(report.Error, CheckPeerIP, resource, "%s %s is the Cloud Router address of the BGP session", variable, addr)
+	case !usable(addr, session.Masked()):
+		v.add(report.Error, CheckPeerIP, resource, "%s %s is the network or broadcast address of %s", variable, addr, session.Masked())
+	}
+}
+
+func (v *validator) interconnect(vars *config.NetworkingVars) []linkLocalRange {
+	router, err := strconv.ParseInt(strings.TrimSpace(vars.ICRouterBGPASN), 10, 64)
+	switch {
+	case vars.ICRouterBGPASN == "":
+		v.add(report.Error, CheckASN, `router "interconnect"`, "ic_router_bgp_asn is required")
+	case err != nil:
+		v.add(report.Error, CheckASN, `router "interconnect"`, "ic_router_bgp_asn %q is not a number", vars.ICRouterBGPASN)
+	default:
+		v.routerASN(`router "interconnect"`, "ic_router_bgp_asn", router)
+	}
+	attachments := []struct {
+		prefix, name, interconnect, asn, bandwidth, bgpRange string
+		vlanTag                                              *int
+	}{
+		{"first_", vars.FirstVAName, vars.FirstInterconnectName, vars.FirstVAASN, vars.FirstVABandwidth, vars.FirstVABGPRange, vars.FirstVLANTag},
+		{"second_", vars.SecondVAName, vars.SecondInterconnectName, vars.SecondVAASN, vars.SecondVABandwidth, vars.SecondVABGPRange, vars.SecondVLANTag},
+	}
+	var ranges []linkLocalRange
+	// tags maps the VLAN tags to the attachment and interconnect using them.
+	tags := map[int][2]string{}
+	for i, a := range attachments {
+		name := a.name
+		if name == "" {
+			name = defaultAttachmentNames[i]
+		}
+		resource := fmt.Sprintf("vlan_attachment %q", name)
+
+		asn, err := strconv.ParseInt(strings.TrimSpace(a.asn), 10, 64)
+		switch {
+		case a.asn == "":
+			v.add(report.Error, CheckASN, resource, "%sva_asn is required", a.prefix)
+		case err != nil:
+			v.add(report.Error, CheckASN, resource, "%sva_asn %q is not a number", a.prefix, a.asn)
+		default:
+			v.peerASN(resource, a.prefix+"va_asn", asn, router)
+		}
+
+		bandwidth := a.bandwidth
+		if bandwidth == "" {
+			bandwidth = DefaultBandwidth
+		}
+		if !slices.Contains(Bandwidths, bandwidth) {
+			v.add(report.Error, CheckBandwidth, resource, "%sva_bandwidth %q is not one of %s", a.prefix, bandwidth, strings.Join(Bandwidths, ", "))
+		}
+
+		if a.bgpRange != "" {
+			if p, ok := v.bgpRange(resource, a.prefix+"va_bgp_range", a.bgpRange); ok {
+				ranges = append(ranges, linkLocalRange{resource, p})
+			}
+		}
+
+		if a.vlanTag == nil {
+			continue
+		}
+		tag := *a.vlanTag
+		if tag < 2 || tag > 4094 {
+			v.add(report.Error, CheckVLANTag, resource, "%svlan_tag %d is not in the range 2-4094", a.prefix, tag)
+			continue
+		}
+		if other, ok := tags[tag]; ok {
+			if a.interconnect == other[1] {
+				v.add(report.Error, CheckVLANTag, resource, "VLAN tag %d is already used by %s on interconnect %q", tag, other[0], a.interconnect)
+			} else {
+				v.add(report.Warning, CheckVLANTag, resource,
+					"VLAN tag %d is also used by %s on interconnect %q; it is allowed on different interconnects, but is usually a copy and paste mistake", tag, other[0], other[1])
+			}
+		}
+		tags[tag] = [2]string{resource, a.interconnect}
+	}
+	return ranges
+}
+
+// bgpRange parses the candidate range of a VLAN attachment, a link-local
+// /29 or shorter prefix.
+func (v *validator) bgpRange(resource, variable, value string) (netip.Prefix, bool) {
+	p, err := netip.ParsePrefix(value)
+	switch {
+	case err != nil:
+		v.add(report.Error, CheckBGPRange, resource, "%s %q is not a CIDR range", variable, value)
+	case !p.Addr().Is4() || !LinkLocal.Contains(p.Addr()) || p.Bits() < LinkLocal.Bits():
+		v.add(report.Error, CheckBGPRange, resource, "%s %s is not in %s", variable, p, LinkLocal)
+	case p.Bits() > 29:
+		v.add(report.Error, CheckBGPRange, resource, "%s %s must be a /29 or shorter prefix", variable, p)
+	case p != p.Masked():
+		v.add(report.Error, CheckBGPRange, resource, "%s %s has host bits set, the range is %s", variable, p, p.Masked())
+	default:
+		return p, true
+	}
+	return p, false
+}
+
+func (v *validator) overlaps(ranges []linkLocalRange) {
+	for i, b := range ranges {
+		for _, a := range ranges[:i] {
+			if a.prefix.Overlaps(b.prefix) {
+				v.add(report.Error, CheckBGPRange, b.resource, "BGP range %s overlaps %s of %s", b.prefix, a.prefix, a.resource)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hybrid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name string
+		root string
+		want []string
+	}{
+		{
+			name: "Valid",
+			root: "testdata/valid",
+		},
+		{
+			name: "Invalid",
+			root: "testdata/invalid",
+			want: []string{
+				`ERROR   networking.tfvars: router "router1": router1_asn 15169 is a public ASN, a Cloud Router needs a private ASN (64512-65534 or 4200000000-4294967294) [asn]`,
+				`ERROR   networking.tfvars: vpn_tunnel "remote-0": tunnel_1_bgp_peer_ip_address 169.254.9.1 is not in the BGP session range 169.254.1.0/30 [peer-ip]`,
+				`ERROR   networking.tfvars: vpn_tunnel "remote-1": tunnel_2_bgp_peer_asn 15169 is the ASN of the Cloud Router, the BGP session must be external [asn]`,
+				`ERROR   networking.tfvars: vpn_tunnel "remote-1": tunnel_2_bgp_peer_ip_address 169.254.61.2 is the Cloud Router address of the BGP session [peer-ip]`,
+				`WARNING networking.tfvars: vpn_tunnel "remote-0": tunnel_1_bgp_peer_asn 64514 differs from tunnel_2_bgp_peer_asn 15169, which the stage uses for both tunnels [asn]`,
+				`ERROR   networking.tfvars: vlan_attachment "va-1": first_va_asn 65004 is the ASN of the Cloud Router, the BGP session must be external [asn]`,
+				`ERROR   networking.tfvars: vlan_attachment "va-1": first_va_bandwidth "BPS_3G" is not one of BPS_50M, BPS_100M, BPS_200M, BPS_300M, BPS_400M, BPS_500M, BPS_1G, BPS_2G, BPS_5G, BPS_10G, BPS_20G, BPS_50G, BPS_100G, BPS_400G [bandwidth]`,
+				`ERROR   networking.tfvars: vlan_attachment "va-2": second_va_asn 23456 is not a valid ASN [asn]`,
+				`ERROR   networking.tfvars: vlan_attachment "va-2": second_va_bgp_range 10.0.0.0/30 is not in 169.254.0.0/16 [bgp-range]`,
+				`ERROR   networking.tfvars: vlan_attachment "va-2": VLAN tag 601 is already used by vlan_attachment "va-1" on interconnect "interconnect-1" [vlan-tag]`,
+				`ERROR   networking.tfvars: vlan_attachment "va-1": BGP range 169.254.61.0/29 overlaps 169.254.61.0/30 of vpn_tunnel "remote-1" [bgp-range]`,
+			},
+		},
+		{
+			// The values of the networking unit test.
+			name: "Same VLAN tag on two interconnects",
+			root: "testdata/shared-tag",
+			want: []string{
+				`WARNING networking.tfvars: vlan_attachment "dedicated-ic-vlan-attachment-4": VLAN tag 601 is also used by vlan_attachment "dedicated-ic-vlan-attachment-3" on interconnect "interconnect-1"; it is allowed on different interconnects, but is usually a copy and paste mistake [vlan-tag]`,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := config.LoadTree(tc.root)
+			if err != nil {
+				t.Fatalf("Failed to load %s: %v", tc.root, err)
+			}
+			var got []string
+			for _, f := range Validate(tree) {
+				got = append(got, f.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Validate() findings mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tc.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestPrivateASN(t *testing.T) {
+	testCases := []struct {
+		asn  int64
+		want bool
+	}{
+		{64511, false},
+		{64512, true},
+		{65534, true},
+		{65535, false},
+		{15169, false},
+		{4199999999, false},
+		{4200000000, true},
+		{4294967294, true},
+	}
+	for _, tc := range testCases {
+		if got := PrivateASN(tc.asn); got != tc.want {
+			t.Errorf("PrivateASN(%d) = %t, want %t", tc.asn, got, tc.want)
+		}
+	}
+}
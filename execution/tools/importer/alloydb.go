@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+var alloyDBOutputOnly = []string{
+	"clusterType", "encryptionInfo", "continuousBackupInfo", "backupSource",
+	"migrationSource", "subscriptionType", "trialMetadata", "satisfiesPzs",
+	"pscConfig.pscEnabled", "network",
+	// Instance fields.
+	"ipAddress", "publicIpAddress", "outboundPublicIpAddresses", "nodes",
+	"writableNode", "pscInstanceConfig.serviceAttachmentLink",
+	"pscInstanceConfig.pscDnsName", "availabilityType", "gceZone",
+}
+
+// importAlloyDBCluster converts a `gcloud alloydb clusters describe` document.
+// The primary and read pool instances are added by addAlloyDBInstance.
+func importAlloyDBCluster(doc map[string]any) *Result {
+	s := newSource(doc, alloyDBOutputOnly...)
+	name := s.str("name")
+	c := &config.AlloyDBStruct{
+		ClusterID:                lastSegment(name),
+		ClusterDisplayName:       s.str("displayName"),
+		ProjectID:                segment(name, "projects"),
+		Region:                   segment(name, "locations"),
+		DatabaseVersion:          s.str("databaseVersion"),
+		ClusterLabels:            s.stringMap("labels"),
+		ClusterEncryptionKeyName: s.str("encryptionConfig.kmsKeyName"),
+	}
+	if c.ClusterDisplayName == "" {
+		c.ClusterDisplayName = c.ClusterID
+	}
+	network := s.str("networkConfig.network")
+	if network == "" {
+		// Older clusters only report the deprecated top level field.
+		network = s.str("network")
+	}
+	if psc := s.boolean("pscConfig.pscEnabled"); psc != nil && *psc {
+		c.ConnectivityOptions = "psc"
+	} else {
+		c.ConnectivityOptions = "psa"
+		c.NetworkID = relativeName(network)
+		c.AllocatedIPRange = s.str("networkConfig.allocatedIpRange")
+	}
+	r := newResult(AlloyDB, c.ClusterID, "producer/alloydb", c)
+	r.Unmapped = s.unmapped()
+	r.Missing = []string{"primary_instance"}
+	return r
+}
+
+// addAlloyDBInstance merges a `gcloud alloydb instances describe` document
+// into the result of its cluster.
+func addAlloyDBInstance(results []*Result, doc map[string]any) error {
+	s := newSource(doc, alloyDBOutputOnly...)
+	name := s.str("name")
+	clusterID := segment(name, "clusters")
+	var r *Result
+	for _, candidate := range results {
+		if c, ok := candidate.Value.(*config.AlloyDBStruct); ok && c.ClusterID == clusterID &&
+			c.ProjectID == segment(name, "projects") && c.Region == segment(name, "locations") {
+			r = candidate
+		}
+	}
+	if r == nil {
+		return fmt.Errorf("AlloyDB instance %s: the describe output of cluster %s is required as well", name, clusterID)
+	}
+	c := r.Value.(*config.AlloyDBStruct)
+	flags := map[string]any{}
+	for k, v := range s.stringMap("databaseFlags") {
+		flags[k] = v
+	}
+	if len(flags) == 0 {
+		flags = nil
+	}
+	cpus := 0
+	if n := s.integer("machineConfig.cpuCount"); n != nil {
+		cpus = *n
+	}
+	switch s.str("instanceType") {
+	case "PRIMARY", "SECONDARY":
+		c.PrimaryInstance = config.PrimaryInstanceStruct{
+			InstanceID:      lastSegment(name),
+			DisplayName:     s.str("displayName"),
+			InstanceType:    s.str("instanceType"),
+			MachineCPUCount: cpus,
+			DatabaseFlags:   flags,
+		}
+		r.Missing = removeString(r.Missing, "primary_instance")
+	case "READ_POOL":
+		nodes := 0
+		if n := s.integer("readPoolConfig.nodeCount"); n != nil {
+			nodes = *n
+		}
+		c.ReadPoolInstance = append(c.ReadPoolInstance, config.ReadPoolInstanceStruct{
+			InstanceID:      lastSegment(name),
+			DisplayName:     s.str("displayName"),
+			NodeCount:       nodes,
+			MachineCPUCount: cpus,
+			DatabaseFlags:   flags,
+		})
+	default:
+		return fmt.Errorf("AlloyDB instance %s: unsupported instance type %q", name, s.str("instanceType"))
+	}
+	for _, p := range s.strings("pscInstanceConfig.allowedConsumerProjects") {
+		if !containsString(c.PSCAllowedConsumerProjects, p) {
+			c.PSCAllowedConsumerProjects = append(c.PSCAllowedConsumerProjects, p)
+		}
+	}
+	for _, f := range s.unmapped() {
+		r.Unmapped = append(r.Unmapped, "instances."+lastSegment(name)+"."+f)
+	}
+	return nil
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(l []string, s string) []string {
+	var out []string
+	for _, e := range l {
+		if e != s {
+			out = append(out, e)
+		}
+	}
+	return out
+}
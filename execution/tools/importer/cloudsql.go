@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+// cloudSQLOutputOnly lists the Cloud SQL instance fields computed by the API.
+var cloudSQLOutputOnly = []string{
+	"connectionName", "ipAddresses", "serverCaCert", "serviceAccountEmailAddress",
+	"backendType", "gceZone", "secondaryGceZone", "instanceType",
+	"databaseInstalledVersion", "maintenanceVersion", "availableMaintenanceVersions",
+	"upgradableDatabaseVersions", "sqlNetworkArchitecture", "dnsName",
+	"pscServiceAttachmentLink", "geminiConfig", "includeReplicasForMajorVersionUpgrade",
+	"createTime", "settings.settingsVersion", "settings.pricingPlan",
+	"settings.replicationType", "settings.backupConfiguration.backupRetentionSettings.retentionUnit",
+	"settings.backupConfiguration.transactionalLogStorageState",
+	"settings.ipConfiguration.pscConfig.pscEnabled",
+	"settings.ipConfiguration.serverCaMode",
+}
+
+// importCloudSQL converts a `gcloud sql instances describe` document.
+func importCloudSQL(doc map[string]any) *Result {
+	s := newSource(doc, cloudSQLOutputOnly...)
+	c := &config.CloudSQLStruct{
+		Name:                  s.str("name"),
+		ProjectID:             s.str("project"),
+		Region:                s.str("region"),
+		DatabaseVersion:       s.str("databaseVersion"),
+		Tier:                  s.str("settings.tier"),
+		Edition:               s.str("settings.edition"),
+		AvailabilityType:      s.str("settings.availabilityType"),
+		ActivationPolicy:      s.str("settings.activationPolicy"),
+		DiskSize:              s.integer("settings.dataDiskSizeGb"),
+		DiskType:              s.str("settings.dataDiskType"),
+		Collation:             s.str("settings.collation"),
+		ConnectorEnforcement:  s.str("settings.connectorEnforcement"),
+		DataCache:             s.boolean("settings.dataCacheConfig.dataCacheEnabled"),
+		Timezone:              s.str("settings.timeZone"),
+		Labels:                s.stringMap("settings.userLabels"),
+		GCPDeletionProtection: s.boolean("settings.deletionProtectionEnabled"),
+	}
+	if limit := s.integer("settings.storageAutoResizeLimit"); limit != nil && *limit > 0 {
+		c.DiskAutoresizeLimit = limit
+	}
+	if key := s.str("diskEncryptionConfiguration.kmsKeyName"); key != "" {
+		c.Encryption = key
+	}
+
+	ip := "settings.ipConfiguration"
+	if public := s.boolean(ip + ".ipv4Enabled"); public != nil {
+		c.NetworkConfig.Connectivity.PublicIPV4 = *public
+	}
+	if network := s.str(ip + ".privateNetwork"); network != "" {
+		c.NetworkConfig.Connectivity.PSAConfig = &config.PSAConfigStruct{PrivateNetwork: relativeName(network)}
+		if r := s.str(ip + ".allocatedIpRange"); r != "" {
+			c.NetworkConfig.Connectivity.PSAConfig.AllocatedIPRanges = &config.AllocatedIPRangesStruct{Primary: r}
+		}
+	}
+	c.NetworkConfig.Connectivity.PSCAllowedConsumerProjects = s.strings(ip + ".pscConfig.allowedConsumerProjects")
+	for i := 0; i < s.length(ip+".authorizedNetworks"); i++ {
+		p := fmt.Sprintf("%s.authorizedNetworks.%d", ip, i)
+		if c.NetworkConfig.AuthorizedNetworks == nil {
+			c.NetworkConfig.AuthorizedNetworks = map[string]string{}
+		}
+		name := s.str(p + ".name")
+		if name == "" {
+			name = fmt.Sprintf("network-%d", i)
+		}
+		c.NetworkConfig.AuthorizedNetworks[name] = s.str(p + ".value")
+	}
+	ssl := map[string]any{}
+	if mode := s.str(ip + ".sslMode"); mode != "" {
+		ssl["ssl_mode"] = mode
+	}
+	if req := s.boolean(ip + ".requireSsl"); req != nil && *req {
+		ssl["require_ssl"] = true
+	}
+	if len(ssl) > 0 {
+		c.SSL = ssl
+	}
+
+	for i := 0; i < s.length("settings.databaseFlags"); i++ {
+		p := fmt.Sprintf("settings.databaseFlags.%d", i)
+		if c.Flags == nil {
+			c.Flags = map[string]any{}
+		}
+		c.Flags[s.str(p+".name")] = s.str(p + ".value")
+	}
+
+	c.BackupConfiguration = cloudSQLBackup(s)
+	c.InsightsConfig = cloudSQLInsights(s)
+	c.MaintenanceConfig = cloudSQLMaintenance(s)
+
+	r := newResult(CloudSQL, c.Name, "producer/cloudsql", c)
+	r.Unmapped = s.unmapped()
+	return r
+}
+
+func cloudSQLBackup(s *source) map[string]any {
+	p := "settings.backupConfiguration"
+	if !s.has(p) {
+		return nil
+	}
+	b := map[string]any{}
+	set := func(key string, v any) {
+		switch v := v.(type) {
+		case *bool:
+			if v != nil {
+				b[key] = *v
+			}
+		case *int:
+			if v != nil {
+				b[key] = *v
+			}
+		case string:
+			if v != "" {
+				b[key] = v
+			}
+		}
+	}
+	set("enabled", s.boolean(p+".enabled"))
+	set("binary_log_enabled", s.boolean(p+".binaryLogEnabled"))
+	set("start_time", s.str(p+".startTime"))
+	set("location", s.str(p+".location"))
+	set("log_retention_days", s.integer(p+".transactionLogRetentionDays"))
+	set("point_in_time_recovery_enabled", s.boolean(p+".pointInTimeRecoveryEnabled"))
+	set("retention_count", s.integer(p+".backupRetentionSettings.retainedBackups"))
+	return b
+}
+
+func cloudSQLInsights(s *source) map[string]any {
+	p := "settings.insightsConfig"
+	enabled := s.boolean(p + ".queryInsightsEnabled")
+	if enabled == nil || !*enabled {
+		// Disabled insights still report their defaults.
+		s.value(p)
+		return nil
+	}
+	insights := map[string]any{}
+	if v := s.integer(p + ".queryStringLength"); v != nil {
+		insights["query_string_length"] = *v
+	}
+	if v := s.boolean(p + ".recordApplicationTags"); v != nil {
+		insights["record_application_tags"] = *v
+	}
+	if v := s.boolean(p + ".recordClientAddress"); v != nil {
+		insights["record_client_address"] = *v
+	}
+	if v := s.integer(p + ".queryPlansPerMinute"); v != nil {
+		insights["query_plans_per_minute"] = *v
+	}
+	return insights
+}
+
+func cloudSQLMaintenance(s *source) map[string]any {
+	p := "settings.maintenanceWindow"
+	day := s.integer(p + ".day")
+	if day == nil || *day == 0 {
+		// Day 0 means no preferred window.
+		s.value(p)
+		return nil
+	}
+	window := map[string]any{"day": *day, "hour": 0}
+	if hour := s.integer(p + ".hour"); hour != nil {
+		window["hour"] = *hour
+	}
+	if track := s.str(p + ".updateTrack"); track != "" {
+		window["update_track"] = track
+	}
+	return map[string]any{"maintenance_window": window}
+}
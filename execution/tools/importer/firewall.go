@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+// importFirewalls converts `gcloud compute firewall-rules describe` documents
+// into one tfvars file of the given 03-security stage per network.
+func importFirewalls(docs []map[string]any, stage config.Stage) []*Result {
+	var results []*Result
+	byNetwork := map[string]*Result{}
+	for _, doc := range docs {
+		s := newSource(doc)
+		network := relativeName(s.str("network"))
+		r, ok := byNetwork[network]
+		if !ok {
+			vars := &config.FirewallRulesVars{
+				ProjectID: segment(s.str("selfLink"), "projects"),
+				Network:   lastSegment(network),
+			}
+			if p := segment(network, "projects"); p != vars.ProjectID {
+				// Shared VPC networks are referenced by self link.
+				vars.Network = network
+			}
+			r = &Result{Type: Firewall, Name: network, Stage: stage, File: stage.Tfvars, Value: vars}
+			byNetwork[network] = r
+			results = append(results, r)
+		}
+		vars := r.Value.(*config.FirewallRulesVars)
+		name := s.str("name")
+		rule := firewallRule(s)
+		if s.str("direction") == "EGRESS" {
+			if vars.EgressRules == nil {
+				vars.EgressRules = config.FirewallRules{}
+			}
+			vars.EgressRules[name] = rule
+		} else {
+			if vars.IngressRules == nil {
+				vars.IngressRules = config.FirewallRules{}
+			}
+			vars.IngressRules[name] = rule
+		}
+		for _, f := range s.unmapped() {
+			r.Unmapped = append(r.Unmapped, name+"."+f)
+		}
+	}
+	return results
+}
+
+func firewallRule(s *source) config.FirewallRuleConfig {
+	rule := config.FirewallRuleConfig{
+		Description:       s.str("description"),
+		Priority:          s.integer("priority"),
+		SourceRanges:      s.strings("sourceRanges"),
+		DestinationRanges: s.strings("destinationRanges"),
+	}
+	if v := s.boolean("disabled"); v != nil {
+		rule.Disabled = *v
+	}
+	if enabled := s.boolean("logConfig.enable"); enabled != nil && *enabled {
+		rule.EnableLogging = map[string]any{
+			"include_metadata": s.str("logConfig.metadata") == "INCLUDE_ALL_METADATA",
+		}
+	} else {
+		s.value("logConfig")
+	}
+	if accounts := s.strings("targetServiceAccounts"); len(accounts) > 0 {
+		rule.UseServiceAccounts = true
+		rule.Targets = accounts
+		rule.Sources = s.strings("sourceServiceAccounts")
+	} else {
+		rule.Targets = s.strings("targetTags")
+		rule.Sources = s.strings("sourceTags")
+	}
+	protocols := "allowed"
+	if s.length("denied") > 0 {
+		rule.Deny = true
+		protocols = "denied"
+	}
+	for i := 0; i < s.length(protocols); i++ {
+		p := fmt.Sprintf("%s.%d", protocols, i)
+		rule.Rules = append(rule.Rules, config.FirewallRuleProtocol{
+			Protocol: s.str(p + ".IPProtocol"),
+			Ports:    s.strings(p + ".ports"),
+		})
+	}
+	return rule
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+var gceOutputOnly = []string{
+	"cpuPlatform", "lastStartTimestamp", "lastStopTimestamp", "lastSuspendedTimestamp",
+	"startRestricted", "resourceStatus", "shieldedInstanceIntegrityPolicy",
+	"networkInterfaces.*.networkIP", "networkInterfaces.*.name",
+	"networkInterfaces.*.stackType", "networkInterfaces.*.accessConfigs",
+	"disks.*.index", "disks.*.interface", "disks.*.licenses", "disks.*.type",
+	"disks.*.guestOsFeatures", "disks.*.architecture", "disks.*.savedState",
+}
+
+// importGCE converts a `gcloud compute instances describe` document.
+func importGCE(doc map[string]any) *Result {
+	s := newSource(doc, gceOutputOnly...)
+	zone := lastSegment(s.str("zone"))
+	c := &config.VMInstanceConfig{
+		Name:         s.str("name"),
+		ProjectID:    segment(s.str("selfLink"), "projects"),
+		Zone:         zone,
+		Region:       zone[:max(strings.LastIndex(zone, "-"), 0)],
+		Network:      relativeName(s.str("networkInterfaces.0.network")),
+		Subnetwork:   relativeName(s.str("networkInterfaces.0.subnetwork")),
+		InstanceType: lastSegment(s.str("machineType")),
+		Description:  s.str("description"),
+		Hostname:     s.str("hostname"),
+		CanIPForward: s.boolean("canIpForward"),
+		Tags:         s.strings("tags.items"),
+		Labels:       s.stringMap("labels"),
+	}
+	if c.ProjectID == "" {
+		c.ProjectID = segment(s.str("zone"), "projects")
+	}
+	for i := 0; i < s.length("metadata.items"); i++ {
+		if c.Metadata == nil {
+			c.Metadata = map[string]string{}
+		}
+		p := fmt.Sprintf("metadata.items.%d", i)
+		c.Metadata[s.str(p+".key")] = s.str(p + ".value")
+	}
+	if s.length("serviceAccounts") > 0 {
+		c.ServiceAccount = map[string]any{
+			"email":  s.str("serviceAccounts.0.email"),
+			"scopes": s.strings("serviceAccounts.0.scopes"),
+		}
+	}
+	if s.has("shieldedInstanceConfig") {
+		c.ShieldedConfig = map[string]any{}
+		for key, field := range map[string]string{
+			"enable_secure_boot":          "enableSecureBoot",
+			"enable_vtpm":                 "enableVtpm",
+			"enable_integrity_monitoring": "enableIntegrityMonitoring",
+		} {
+			v := s.boolean("shieldedInstanceConfig." + field)
+			c.ShieldedConfig[key] = v != nil && *v
+		}
+	}
+	for i := 0; i < s.length("disks"); i++ {
+		p := fmt.Sprintf("disks.%d", i)
+		if boot := s.boolean(p + ".boot"); boot != nil && *boot {
+			disk := map[string]any{}
+			if v := s.boolean(p + ".autoDelete"); v != nil {
+				disk["auto_delete"] = *v
+			}
+			if size := s.integer(p + ".diskSizeGb"); size != nil {
+				disk["initialize_params"] = map[string]any{"size": *size}
+			}
+			// The source disk is recreated from the image, not attached.
+			s.value(p + ".source")
+			s.value(p + ".deviceName")
+			s.value(p + ".mode")
+			c.BootDisk = disk
+			continue
+		}
+		options := map[string]any{}
+		if v := s.boolean(p + ".autoDelete"); v != nil {
+			options["auto_delete"] = *v
+		}
+		if mode := s.str(p + ".mode"); mode != "" {
+			options["mode"] = mode
+		}
+		attached := map[string]any{
+			"name":        s.str(p + ".deviceName"),
+			"device_name": s.str(p + ".deviceName"),
+			"source_type": "attach",
+			"source":      relativeName(s.str(p + ".source")),
+			"options":     options,
+		}
+		if size := s.integer(p + ".diskSizeGb"); size != nil {
+			attached["size"] = *size
+		}
+		c.AttachedDisks = append(c.AttachedDisks, attached)
+	}
+
+	r := newResult(GCE, c.Name, "consumer/gce", c)
+	r.Unmapped = s.unmapped()
+	// The boot image is a property of the boot disk, not of the instance.
+	r.Missing = []string{"image"}
+	return r
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package importer converts `gcloud ... describe --format=json` documents of
+// existing resources into stage configuration files, so that an existing
+// estate can be brought under this repository without retyping it.
+//
+// Supported resources are Cloud SQL instances, AlloyDB clusters and
+// instances, Memorystore Redis clusters, Compute Engine instances and VPC
+// firewall rules.
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Resource types understood by the importer.
+const (
+	CloudSQL = "cloudsql"
+	AlloyDB  = "alloydb"
+	MRC      = "mrc"
+	GCE      = "gce"
+	Firewall = "firewall"
+)
+
+// Result is a stage configuration file generated from one or more describe
+// documents.
+type Result struct {
+	// Type is one of the resource type constants.
+	Type string
+	// Name is the name of the imported resource.
+	Name string
+	// Stage is the stage the file belongs to.
+	Stage config.Stage
+	// File is the generated file, relative to the configuration root.
+	File string
+	// Value is a pointer to the typed stage schema.
+	Value any
+	// Unmapped lists the describe fields that have no equivalent in the stage
+	// schema, as dotted paths.
+	Unmapped []string
+	// Missing lists the required stage fields that cannot be derived from the
+	// describe output and must be filled in by hand.
+	Missing []string
+}
+
+// Options tune the conversion.
+type Options struct {
+	// FirewallStage is the 03-security stage firewall rules are imported
+	// into. It defaults to security/gce.
+	FirewallStage string
+}
+
+// Decode reads the describe documents of r. It accepts one or more JSON
+// objects, and JSON arrays of objects as printed by `gcloud ... list
+// --format=json`.
+func Decode(r io.Reader) ([]map[string]any, error) {
+	dec := json.NewDecoder(r)
+	var docs []map[string]any
+	for {
+		var v any
+		if err := dec.Decode(&v); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		switch d := v.(type) {
+		case map[string]any:
+			docs = append(docs, d)
+		case []any:
+			for _, e := range d {
+				m, ok := e.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("expected a JSON object, got %T", e)
+				}
+				docs = append(docs, m)
+			}
+		default:
+			return nil, fmt.Errorf("expected a JSON object, got %T", v)
+		}
+	}
+}
+
+// Detect returns the resource type of a describe document.
+func Detect(doc map[string]any) (string, error) {
+	switch doc["kind"] {
+	case "sql#instance":
+		return CloudSQL, nil
+	case "compute#instance":
+		return GCE, nil
+	case "compute#firewall":
+		return Firewall, nil
+	}
+	name, _ := doc["name"].(string)
+	switch {
+	case segment(name, "clusters") != "" && segment(name, "instances") != "":
+		return AlloyDB, nil
+	case segment(name, "clusters") != "" && (doc["shardCount"] != nil || doc["pscConfigs"] != nil):
+		return MRC, nil
+	case segment(name, "clusters") != "" && (doc["clusterType"] != nil || doc["databaseVersion"] != nil || doc["networkConfig"] != nil):
+		return AlloyDB, nil
+	}
+	return "", fmt.Errorf("unsupported describe document (kind %v, name %q)", doc["kind"], name)
+}
+
+// Import converts describe documents into stage configuration files. AlloyDB
+// instances are merged into the result of their cluster, and firewall rules
+// of the same network into a single tfvars file.
+func Import(docs []map[string]any, opts Options) ([]*Result, error) {
+	if opts.FirewallStage == "" {
+		opts.FirewallStage = "security/gce"
+	}
+	firewallStage, ok := config.LookupStage(opts.FirewallStage)
+	if !ok || !strings.HasPrefix(firewallStage.Path, "03-security/") || firewallStage.ConfigDir != "" {
+		return nil, fmt.Errorf("%q is not a 03-security firewall rules stage", opts.FirewallStage)
+	}
+
+	var results []*Result
+	var alloydbInstances []map[string]any
+	var firewalls []map[string]any
+	var errs []error
+	for _, doc := range docs {
+		t, err := Detect(doc)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		switch t {
+		case CloudSQL:
+			results = append(results, importCloudSQL(doc))
+		case MRC:
+			results = append(results, importMRC(doc))
+		case GCE:
+			results = append(results, importGCE(doc))
+		case Firewall:
+			firewalls = append(firewalls, doc)
+		case AlloyDB:
+			if name, _ := doc["name"].(string); segment(name, "instances") != "" {
+				alloydbInstances = append(alloydbInstances, doc)
+			} else {
+				results = append(results, importAlloyDBCluster(doc))
+			}
+		}
+	}
+	for _, doc := range alloydbInstances {
+		if err := addAlloyDBInstance(results, doc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	results = append(results, importFirewalls(firewalls, firewallStage)...)
+	for _, r := range results {
+		sort.Strings(r.Unmapped)
+	}
+	return results, errors.Join(errs...)
+}
+
+func newResult(t, name, stageName string, value any) *Result {
+	stage, _ := config.LookupStage(stageName)
+	return &Result{
+		Type:  t,
+		Name:  name,
+		Stage: stage,
+		File:  path.Join(stage.ConfigDir, name+".yaml"),
+		Value: value,
+	}
+}
+
+// Encode renders the result in the format of its stage file: YAML for stage
+// config folders and HCL for tfvars.
+func (r *Result) Encode() ([]byte, error) {
+	if strings.HasSuffix(r.File, ".tfvars") {
+		return config.EncodeTfvars(r.Value)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(r.Value); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// importFixtures decodes the recorded describe outputs and imports them.
+func importFixtures(t *testing.T, fixtures ...string) []*Result {
+	t.Helper()
+	var docs []map[string]any
+	for _, f := range fixtures {
+		b, err := os.ReadFile(filepath.Join("testdata", f))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		d, err := Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v", f, err)
+		}
+		docs = append(docs, d...)
+	}
+	results, err := Import(docs, Options{})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	return results
+}
+
+func TestImport(t *testing.T) {
+	testCases := []struct {
+		name         string
+		fixtures     []string
+		golden       string
+		wantFile     string
+		wantUnmapped []string
+		wantMissing  []string
+	}{
+		{
+			name:     "CloudSQL",
+			fixtures: []string{"cloudsql.json"},
+			golden:   "cloudsql.golden.yaml",
+			wantFile: "producer/CloudSQL/config/sql-1.yaml",
+			wantUnmapped: []string{
+				"settings.locationPreference.zone",
+				"settings.storageAutoResize",
+			},
+		},
+		{
+			name:     "AlloyDB",
+			fixtures: []string{"alloydb-cluster.json", "alloydb-instance.json"},
+			golden:   "alloydb.golden.yaml",
+			wantFile: "producer/AlloyDB/config/cluster-1.yaml",
+			wantUnmapped: []string{
+				"automatedBackupPolicy.backupWindow",
+				"automatedBackupPolicy.enabled",
+				"automatedBackupPolicy.location",
+				"automatedBackupPolicy.weeklySchedule.daysOfWeek",
+				"automatedBackupPolicy.weeklySchedule.startTimes.0.hours",
+				"instances.primary-1.queryInsightsConfig.queryPlansPerMinute",
+				"instances.primary-1.queryInsightsConfig.queryStringLength",
+				"instances.primary-1.queryInsightsConfig.recordApplicationTags",
+				"instances.primary-1.queryInsightsConfig.recordClientAddress",
+			},
+		},
+		{
+			name:     "MRC",
+			fixtures: []string{"mrc.json"},
+			golden:   "mrc.golden.yaml",
+			wantFile: "producer/MRC/config/mrc-1.yaml",
+			wantUnmapped: []string{
+				"authorizationMode",
+				"nodeType",
+				"transitEncryptionMode",
+				"zoneDistributionConfig.mode",
+			},
+		},
+		{
+			name:     "GCE",
+			fixtures: []string{"gce.json"},
+			golden:   "gce.golden.yaml",
+			wantFile: "consumer/GCE/config/vm-1.yaml",
+			wantUnmapped: []string{
+				"deletionProtection",
+				"scheduling.automaticRestart",
+				"scheduling.onHostMaintenance",
+				"scheduling.preemptible",
+				"scheduling.provisioningModel",
+			},
+			wantMissing: []string{"image"},
+		},
+		{
+			name:     "Firewall",
+			fixtures: []string{"firewall.json"},
+			golden:   "firewall.golden.tfvars",
+			wantFile: "security/gce.tfvars",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results := importFixtures(t, tc.fixtures...)
+			if len(results) != 1 {
+				t.Fatalf("Expected a single result, got %d", len(results))
+			}
+			r := results[0]
+			if r.File != tc.wantFile {
+				t.Errorf("File = %q, want %q", r.File, tc.wantFile)
+			}
+			if !reflect.DeepEqual(r.Unmapped, tc.wantUnmapped) {
+				t.Errorf("Unmapped = %q, want %q", r.Unmapped, tc.wantUnmapped)
+			}
+			if !reflect.DeepEqual(r.Missing, tc.wantMissing) {
+				t.Errorf("Missing = %q, want %q", r.Missing, tc.wantMissing)
+			}
+			got, err := r.Encode()
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Encode() mismatch with %s.\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// TestImportRoundTrip checks that the generated files decode back into the
+// stage schemas, the way the configuration tree loader reads them.
+func TestImportRoundTrip(t *testing.T) {
+	results := importFixtures(t, "cloudsql.json", "alloydb-cluster.json", "alloydb-instance.json", "mrc.json", "gce.json", "firewall.json")
+	for _, r := range results {
+		content, err := r.Encode()
+		if err != nil {
+			t.Fatalf("%s: Encode() failed: %v", r.File, err)
+		}
+		root := t.TempDir()
+		target := filepath.Join(root, filepath.FromSlash(r.File))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		tree, err := config.LoadTree(root)
+		if err != nil {
+			t.Fatalf("%s: generated file does not load: %v", r.File, err)
+		}
+		stage := tree.Stage(r.Stage.Name)
+		if stage == nil {
+			t.Fatalf("%s: stage %s not found in the generated tree", r.File, r.Stage.Name)
+		}
+		got := stage.Vars
+		if len(stage.Documents) > 0 {
+			got = stage.Documents[0].Value
+		}
+		// Compare the YAML forms, generic fields decode into []any and map[string]any.
+		gotYAML, _ := yaml.Marshal(got)
+		wantYAML, _ := yaml.Marshal(r.Value)
+		if !bytes.Equal(gotYAML, wantYAML) {
+			t.Errorf("%s: round trip mismatch.\ngot:\n%s\nwant:\n%s", r.File, gotYAML, wantYAML)
+		}
+	}
+}
+
+func TestImportCloudSQLPSAConfig(t *testing.T) {
+	r := importFixtures(t, "cloudsql.json")[0]
+	psa := r.Value.(*config.CloudSQLStruct).NetworkConfig.Connectivity.PSAConfig
+	if psa == nil || psa.PrivateNetwork != "projects/test-project/global/networks/vpc-1" {
+		t.Errorf("Expected psa_config to be populated from ipConfiguration.privateNetwork, got %+v", psa)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		opts    Options
+		wantErr string
+	}{
+		{
+			name:    "UnsupportedDocument",
+			input:   `{"kind": "compute#network", "name": "vpc-1"}`,
+			wantErr: "unsupported describe document",
+		},
+		{
+			name:    "AlloyDBInstanceWithoutCluster",
+			input:   `{"name": "projects/p/locations/r/clusters/c/instances/i", "instanceType": "PRIMARY"}`,
+			wantErr: "the describe output of cluster c is required",
+		},
+		{
+			name:    "InvalidFirewallStage",
+			input:   `[]`,
+			opts:    Options{FirewallStage: "producer/cloudsql"},
+			wantErr: "is not a 03-security firewall rules stage",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			docs, err := Decode(strings.NewReader(tc.input))
+			if err != nil {
+				t.Fatalf("Decode() failed: %v", err)
+			}
+			_, err = Import(docs, tc.opts)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Import() error = %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import "github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+
+var mrcOutputOnly = []string{
+	"discoveryEndpoints", "pscConnections", "pscServiceAttachments", "sizeGb",
+	"preciseSizeGb", "stateInfo", "managedBackupSource", "gcsSource",
+	"backupCollection", "crossClusterReplicationConfig.membership",
+}
+
+// importMRC converts a `gcloud redis clusters describe` document.
+func importMRC(doc map[string]any) *Result {
+	s := newSource(doc, mrcOutputOnly...)
+	name := s.str("name")
+	c := &config.MRCStruct{
+		InstanceName:              lastSegment(name),
+		ProjectID:                 segment(name, "projects"),
+		Region:                    segment(name, "locations"),
+		NetworkID:                 relativeName(s.str("pscConfigs.0.network")),
+		ShardCount:                s.integer("shardCount"),
+		ReplicaCount:              s.integer("replicaCount"),
+		DeletionProtectionEnabled: s.boolean("deletionProtectionEnabled"),
+	}
+	r := newResult(MRC, c.InstanceName, "producer/mrc", c)
+	r.Unmapped = s.unmapped()
+	if c.NetworkID == "" {
+		r.Missing = append(r.Missing, "network_id")
+	}
+	return r
+}
@@ -0,0 +1,263 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// source wraps a describe document and records which fields were read, so
+// that the fields left over can be reported as unmapped. Paths are dotted,
+// with list indexes as segments, e.g. "networkInterfaces.0.network".
+type source struct {
+	doc     map[string]any
+	used    map[string]bool
+	ignored []string
+}
+
+func newSource(doc map[string]any, ignored ...string) *source {
+	return &source{doc: doc, used: map[string]bool{}, ignored: append(outputOnly, ignored...)}
+}
+
+// outputOnly lists fields reported by every API that carry no configuration.
+var outputOnly = []string{
+	"kind", "id", "uid", "etag", "selfLink", "state", "status", "reconciling",
+	"createTime", "updateTime", "creationTimestamp", "fingerprint",
+	"labelFingerprint", "satisfiesPzs", "satisfiesPzi",
+}
+
+// value returns the value at path and marks it as mapped.
+func (s *source) value(path string) any {
+	var cur any = s.doc
+	for _, seg := range strings.Split(path, ".") {
+		switch c := cur.(type) {
+		case map[string]any:
+			cur = c[seg]
+		case []any:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil
+			}
+			cur = c[i]
+		default:
+			return nil
+		}
+	}
+	if cur != nil {
+		s.used[path] = true
+	}
+	return cur
+}
+
+func (s *source) has(path string) bool {
+	var cur any = s.doc
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return false
+		}
+		if cur, ok = m[seg]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *source) str(path string) string {
+	switch v := s.value(path).(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// integer reads a number. The APIs encode int64 fields as JSON strings.
+func (s *source) integer(path string) *int {
+	switch v := s.value(path).(type) {
+	case float64:
+		i := int(v)
+		return &i
+	case string:
+		if i, err := strconv.Atoi(v); err == nil {
+			return &i
+		}
+	}
+	return nil
+}
+
+func (s *source) boolean(path string) *bool {
+	if v, ok := s.value(path).(bool); ok {
+		return &v
+	}
+	return nil
+}
+
+func (s *source) strings(path string) []string {
+	l, _ := s.value(path).([]any)
+	var out []string
+	for _, e := range l {
+		out = append(out, fmt.Sprint(e))
+	}
+	return out
+}
+
+func (s *source) stringMap(path string) map[string]string {
+	m, _ := s.value(path).(map[string]any)
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+// length returns the number of elements of the list at path without marking
+// it as mapped.
+func (s *source) length(path string) int {
+	var cur any = s.doc
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return 0
+		}
+		cur = m[seg]
+	}
+	l, _ := cur.([]any)
+	return len(l)
+}
+
+// unmapped returns the leaf fields of the document that were neither read nor
+// ignored.
+func (s *source) unmapped() []string {
+	var out []string
+	var walk func(path string, v any)
+	walk = func(path string, v any) {
+		if s.covered(path) {
+			return
+		}
+		switch c := v.(type) {
+		case map[string]any:
+			if len(c) == 0 {
+				return
+			}
+			for k, e := range c {
+				walk(joinPath(path, k), e)
+			}
+			return
+		case []any:
+			if len(c) > 0 && !scalarList(c) {
+				for i, e := range c {
+					walk(joinPath(path, strconv.Itoa(i)), e)
+				}
+				return
+			}
+			if len(c) == 0 {
+				return
+			}
+		case nil:
+			return
+		}
+		out = append(out, path)
+	}
+	walk("", s.doc)
+	sort.Strings(out)
+	return out
+}
+
+// covered reports whether path or one of its parents was read or ignored.
+// Ignore patterns match any list index with "*" and match at any depth when
+// they consist of a single segment.
+func (s *source) covered(path string) bool {
+	if path == "" {
+		return false
+	}
+	segs := strings.Split(path, ".")
+	for i := len(segs); i > 0; i-- {
+		if s.used[strings.Join(segs[:i], ".")] {
+			return true
+		}
+	}
+	for _, pattern := range s.ignored {
+		p := strings.Split(pattern, ".")
+		if len(p) == 1 && p[0] == segs[len(segs)-1] {
+			return true
+		}
+		if len(p) <= len(segs) && matchSegments(p, segs[:len(p)]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segs []string) bool {
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != segs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func scalarList(l []any) bool {
+	for _, e := range l {
+		switch e.(type) {
+		case map[string]any, []any:
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path, seg string) string {
+	if path == "" {
+		return seg
+	}
+	return path + "." + seg
+}
+
+// lastSegment returns the last segment of a resource name or URL.
+func lastSegment(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// relativeName strips the API endpoint from a resource URL, e.g.
+// https://www.googleapis.com/compute/v1/projects/p/global/networks/n becomes
+// projects/p/global/networks/n.
+func relativeName(url string) string {
+	if i := strings.Index(url, "projects/"); i >= 0 {
+		return url[i:]
+	}
+	return url
+}
+
+// segment returns the value following key in a resource name, e.g. the
+// project of projects/p/locations/l/clusters/c for key "projects".
+func segment(name, key string) string {
+	parts := strings.Split(relativeName(name), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == key {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipplan
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"net/netip"
+	"sort"
+)
+
+// span is an inclusive range of IPv4 addresses. uint64 bounds keep the end of
+// 255.255.255.255 representable as end+1.
+type span struct {
+	start, end uint64
+}
+
+func (s span) size() uint64 { return s.end - s.start + 1 }
+
+func v4(a netip.Addr) uint64 {
+	b := a.As4()
+	return uint64(binary.BigEndian.Uint32(b[:]))
+}
+
+func addrOf(n uint64) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(n))
+	return netip.AddrFrom4(b)
+}
+
+// spanOf returns the addresses of an IPv4 prefix. ok is false for IPv6.
+func spanOf(p netip.Prefix) (s span, ok bool) {
+	p = p.Masked()
+	if !p.Addr().Is4() {
+		return span{}, false
+	}
+	start := v4(p.Addr())
+	return span{start, start + (uint64(1) << (32 - p.Bits())) - 1}, true
+}
+
+// union merges spans into sorted, disjoint spans.
+func union(spans []span) []span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var out []span
+	for _, s := range spans {
+		if n := len(out); n > 0 && s.start <= out[n-1].end+1 {
+			out[n-1].end = max(out[n-1].end, s.end)
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// clip returns the parts of the sorted, disjoint spans that are inside s.
+func clip(spans []span, s span) []span {
+	var out []span
+	for _, u := range spans {
+		if u.end < s.start || u.start > s.end {
+			continue
+		}
+		out = append(out, span{max(u.start, s.start), min(u.end, s.end)})
+	}
+	return out
+}
+
+// gaps returns the parts of s not covered by the sorted, disjoint used spans.
+func gaps(s span, used []span) []span {
+	var out []span
+	next := s.start
+	for _, u := range clip(used, s) {
+		if u.start > next {
+			out = append(out, span{next, u.start - 1})
+		}
+		next = u.end + 1
+	}
+	if next <= s.end {
+		out = append(out, span{next, s.end})
+	}
+	return out
+}
+
+// prefixes splits a span into the fewest CIDR blocks covering it exactly.
+func prefixes(s span) []netip.Prefix {
+	var out []netip.Prefix
+	for start := s.start; start <= s.end; {
+		// The largest block aligned on start that fits in the span.
+		size := uint64(1) << 32
+		if start != 0 {
+			size = uint64(1) << bits.TrailingZeros64(start)
+		}
+		for size > s.end-start+1 {
+			size >>= 1
+		}
+		out = append(out, netip.PrefixFrom(addrOf(start), 32-bits.TrailingZeros64(size)))
+		start += size
+	}
+	return out
+}
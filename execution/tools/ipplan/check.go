@@ -0,0 +1,351 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipplan
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+var (
+	// RFC1918 are the private IPv4 blocks, the default allowed blocks.
+	RFC1918 = []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("172.16.0.0/12"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+	}
+	linkLocalBlock = netip.MustParsePrefix("169.254.0.0/16")
+)
+
+// DefaultThreshold is the share of a pool above which it is reported as close
+// to exhaustion.
+const DefaultThreshold = 0.8
+
+// Options configure Check.
+type Options struct {
+	// Allowed are the blocks ranges must be taken from, RFC1918 when empty.
+	// Cloud Router link-local ranges must be in 169.254.0.0/16 instead.
+	Allowed []netip.Prefix
+	// Pools are the blocks ranges are allocated from, used for the exhaustion
+	// check and by NextFree. The allowed blocks when empty.
+	Pools []netip.Prefix
+	// Threshold is the used share of a pool reported as exhaustion,
+	// DefaultThreshold when zero.
+	Threshold float64
+}
+
+func (o Options) allowed() []netip.Prefix {
+	if len(o.Allowed) == 0 {
+		return RFC1918
+	}
+	return o.Allowed
+}
+
+func (o Options) pools() []netip.Prefix {
+	if len(o.Pools) == 0 {
+		return o.allowed()
+	}
+	return o.Pools
+}
+
+// Networks returns the names of the networks with allocations, sorted.
+func (p *Plan) Networks() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, a := range p.Allocations {
+		if !seen[a.Network] {
+			seen[a.Network] = true
+			names = append(names, a.Network)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// allocations returns the allocations of a network.
+func (p *Plan) allocations(network string) []Allocation {
+	var out []Allocation
+	for _, a := range p.Allocations {
+		if a.Network == network {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Check returns the collection findings followed by the overlapping ranges
+// within a network and across the networks of an NCC hub, the ranges outside
+// the allowed blocks and the pools close to exhaustion.
+func (p *Plan) Check(opts Options) []report.Finding {
+	findings := append([]report.Finding(nil), p.Findings...)
+	for _, network := range p.Networks() {
+		allocs := p.allocations(network)
+		for i, a := range allocs {
+			for _, b := range allocs[i+1:] {
+				if a.Prefix.Overlaps(b.Prefix) {
+					findings = append(findings, overlap(a, b, "overlap", fmt.Sprintf("network %q", network)))
+				}
+			}
+			findings = append(findings, outside(a, opts.allowed())...)
+		}
+		findings = append(findings, p.exhaustion(network, opts)...)
+	}
+	findings = append(findings, p.CheckHubs()...)
+	return findings
+}
+
+func overlap(a, b Allocation, check, where string) report.Finding {
+	return report.Finding{
+		Severity: report.Error,
+		Check:    check,
+		File:     a.File,
+		Resource: a.Resource(),
+		Message: fmt.Sprintf("%s overlaps %s %q (%s, %s) in %s",
+			a.Prefix, b.Kind, b.Name, b.Prefix, b.File, where),
+	}
+}
+
+func outside(a Allocation, allowed []netip.Prefix) []report.Finding {
+	if a.Kind.linkLocal() {
+		if !contains(linkLocalBlock, a.Prefix) {
+			return []report.Finding{{
+				Severity: report.Error, Check: "link-local", File: a.File, Resource: a.Resource(),
+				Message: fmt.Sprintf("%s is not in %s", a.Prefix, linkLocalBlock),
+			}}
+		}
+		return nil
+	}
+	for _, b := range allowed {
+		if contains(b, a.Prefix) {
+			return nil
+		}
+	}
+	return []report.Finding{{
+		Severity: report.Warning, Check: "outside-allowed", File: a.File, Resource: a.Resource(),
+		Message: fmt.Sprintf("%s is not in the allowed blocks %v", a.Prefix, allowed),
+	}}
+}
+
+func contains(block, p netip.Prefix) bool {
+	return block.Bits() <= p.Bits() && block.Contains(p.Addr())
+}
+
+// used returns the addresses reserved in a network, link-local ranges aside.
+func (p *Plan) used(network string) []span {
+	var spans []span
+	for _, a := range p.allocations(network) {
+		if s, ok := spanOf(a.Prefix); ok && !a.Kind.linkLocal() {
+			spans = append(spans, s)
+		}
+	}
+	return union(spans)
+}
+
+// Usage is the share of a pool used in a network.
+type Usage struct {
+	Network string       `json:"network"`
+	Pool    netip.Prefix `json:"pool"`
+	Used    uint64       `json:"used"`
+	Size    uint64       `json:"size"`
+}
+
+// Ratio returns the used share of the pool.
+func (u Usage) Ratio() float64 {
+	return float64(u.Used) / float64(u.Size)
+}
+
+// Usage returns the use of every IPv4 pool in a network.
+func (p *Plan) Usage(network string, opts Options) []Usage {
+	used := p.used(network)
+	var out []Usage
+	for _, pool := range opts.pools() {
+		s, ok := spanOf(pool)
+		if !ok {
+			continue
+		}
+		u := Usage{Network: network, Pool: pool.Masked(), Size: s.size()}
+		for _, c := range clip(used, s) {
+			u.Used += c.size()
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+func (p *Plan) exhaustion(network string, opts Options) []report.Finding {
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	var findings []report.Finding
+	for _, u := range p.Usage(network, opts) {
+		if u.Used > 0 && u.Ratio() >= threshold {
+			findings = append(findings, report.Finding{
+				Severity: report.Warning, Check: "exhaustion", Resource: fmt.Sprintf("network %q", network),
+				Message: fmt.Sprintf("%.0f%% of %s is allocated (%d of %d addresses)", 100*u.Ratio(), u.Pool, u.Used, u.Size),
+			})
+		}
+	}
+	return findings
+}
+
+// exported returns the allocations a spoke exports to its hub.
+func (p *Plan) exported(s Spoke) []Allocation {
+	var out []Allocation
+	for _, a := range p.allocations(s.Network) {
+		switch {
+		case a.Kind.linkLocal() || a.Kind == GKEMaster:
+			continue
+		case s.Producer != (a.Kind == PSARange):
+			continue
+		case len(s.Include) > 0 && !inAny(s.Include, a.Prefix):
+			continue
+		case inAny(s.Exclude, a.Prefix):
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func inAny(blocks []netip.Prefix, p netip.Prefix) bool {
+	for _, b := range blocks {
+		if contains(b, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHubs reports ranges exported to the same NCC hub by different
+// networks that overlap.
+func (p *Plan) CheckHubs() []report.Finding {
+	hubs := make([]string, 0, len(p.Hubs))
+	for h := range p.Hubs {
+		hubs = append(hubs, h)
+	}
+	sort.Strings(hubs)
+	var findings []report.Finding
+	for _, hub := range hubs {
+		spokes := p.Hubs[hub]
+		for i, s := range spokes {
+			for _, t := range spokes[i+1:] {
+				if s.Network == t.Network {
+					continue
+				}
+				for _, a := range p.exported(s) {
+					for _, b := range p.exported(t) {
+						if a.Prefix.Overlaps(b.Prefix) {
+							findings = append(findings, overlap(a, b, "hub-overlap",
+								fmt.Sprintf("NCC hub %q, exported by spokes %q and %q", hub, s.Name, t.Name)))
+						}
+					}
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// peers returns the networks sharing an NCC hub with network.
+func (p *Plan) peers(network string) []string {
+	seen := map[string]bool{network: true}
+	out := []string{network}
+	for _, spokes := range p.Hubs {
+		member := false
+		for _, s := range spokes {
+			member = member || s.Network == network
+		}
+		if !member {
+			continue
+		}
+		for _, s := range spokes {
+			if !seen[s.Network] {
+				seen[s.Network] = true
+				out = append(out, s.Network)
+			}
+		}
+	}
+	return out
+}
+
+// NextFree returns the first range of the given prefix length that is free
+// in network and in the networks sharing an NCC hub with it, searching the
+// pools in order. A network without any range in the plan is an error.
+func (p *Plan) NextFree(network string, bits int, opts Options) (netip.Prefix, error) {
+	if bits < 0 || bits > 32 {
+		return netip.Prefix{}, fmt.Errorf("invalid IPv4 prefix length %d", bits)
+	}
+	if len(p.allocations(network)) == 0 {
+		return netip.Prefix{}, fmt.Errorf("network %q is not in the plan", network)
+	}
+	var spans []span
+	for _, n := range p.peers(network) {
+		spans = append(spans, p.used(n)...)
+	}
+	used := union(spans)
+	size := uint64(1) << (32 - bits)
+	for _, pool := range opts.pools() {
+		ps, ok := spanOf(pool)
+		if !ok || ps.size() < size {
+			continue
+		}
+		for _, g := range gaps(ps, used) {
+			// Round the start of the gap up to the block alignment.
+			start := (g.start + size - 1) &^ (size - 1)
+			if start+size-1 <= g.end {
+				return netip.PrefixFrom(addrOf(start), bits), nil
+			}
+		}
+	}
+	return netip.Prefix{}, fmt.Errorf("no free /%d range left in network %q within %v", bits, network, opts.pools())
+}
+
+// NetworkMap is the address map of a network.
+type NetworkMap struct {
+	Network     string         `json:"network"`
+	Allocations []Allocation   `json:"allocations"`
+	Usage       []Usage        `json:"usage"`
+	Free        []netip.Prefix `json:"free"`
+}
+
+// Map returns the address map of every network, allocations sorted by
+// address, with the free blocks left in the pools.
+func (p *Plan) Map(opts Options) []NetworkMap {
+	var maps []NetworkMap
+	for _, network := range p.Networks() {
+		m := NetworkMap{Network: network, Allocations: p.allocations(network), Usage: p.Usage(network, opts), Free: []netip.Prefix{}}
+		sort.SliceStable(m.Allocations, func(i, j int) bool {
+			a, b := m.Allocations[i].Prefix, m.Allocations[j].Prefix
+			if c := a.Addr().Compare(b.Addr()); c != 0 {
+				return c < 0
+			}
+			return a.Bits() < b.Bits()
+		})
+		used := p.used(network)
+		for _, pool := range opts.pools() {
+			if s, ok := spanOf(pool); ok {
+				for _, g := range gaps(s, used) {
+					m.Free = append(m.Free, prefixes(g)...)
+				}
+			}
+		}
+		maps = append(maps, m)
+	}
+	return maps
+}
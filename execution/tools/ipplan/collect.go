@@ -0,0 +1,354 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipplan collects the IP ranges declared across the stages of one or
+// more configuration trees into a per-network address map, and checks it for
+// overlapping, misplaced and exhausted ranges.
+//
+// Networks are identified by name, the last segment of a network self link.
+package ipplan
+
+import (
+	"fmt"
+	"net/netip"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// Kind is the kind of an allocation.
+type Kind string
+
+const (
+	Subnet         Kind = "subnet"
+	SecondaryRange Kind = "secondary_range"
+	PSARange       Kind = "psa_range"
+	Connector      Kind = "connector"
+	GKEMaster      Kind = "gke_master"
+	BGPSession     Kind = "bgp_session"
+	VLANAttachment Kind = "vlan_attachment"
+)
+
+// linkLocal reports whether the kind is a Cloud Router link-local range,
+// expected in 169.254.0.0/16 and never exported to peers.
+func (k Kind) linkLocal() bool {
+	return k == BGPSession || k == VLANAttachment
+}
+
+// Stage defaults of 02-networking, used when networking.tfvars leaves the
+// variables unset.
+const (
+	DefaultPSARangeName = "psarange"
+	DefaultPSARange     = "10.0.64.0/20"
+)
+
+var defaultTunnelRanges = [2]string{"169.254.1.2/30", "169.254.2.2/30"}
+
+// Allocation is an IP range reserved in a network by a stage.
+type Allocation struct {
+	Network string       `json:"network"`
+	Prefix  netip.Prefix `json:"prefix"`
+	Kind    Kind         `json:"kind"`
+	// Name identifies the allocation within its kind, e.g. the subnet name or
+	// subnet/range for a secondary range.
+	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
+	Stage  string `json:"stage"`
+	File   string `json:"file"`
+}
+
+// Resource returns the allocation as a report resource.
+func (a Allocation) Resource() string {
+	return fmt.Sprintf("%s %q", a.Kind, a.Name)
+}
+
+// Spoke is an NCC spoke exporting the ranges of a network to its hub.
+type Spoke struct {
+	Name    string `json:"name"`
+	Network string `json:"network"`
+	// Producer is set for linked_producer_vpc_network spokes, which export
+	// the PSA ranges of the network instead of its subnets.
+	Producer bool           `json:"producer,omitempty"`
+	Include  []netip.Prefix `json:"include_export_ranges,omitempty"`
+	Exclude  []netip.Prefix `json:"exclude_export_ranges,omitempty"`
+	File     string         `json:"file"`
+}
+
+// Plan is the address plan of one or more configuration trees.
+type Plan struct {
+	Allocations []Allocation `json:"allocations"`
+	// Hubs maps NCC hub names to their VPC spokes.
+	Hubs map[string][]Spoke `json:"hubs,omitempty"`
+	// Findings are the problems found while collecting the ranges, such as
+	// invalid CIDRs or references to unknown ranges.
+	Findings []report.Finding `json:"-"`
+}
+
+// Collect builds the address plan of the given trees. When several trees are
+// given, typically one per VPC, file names are prefixed with the tree root.
+func Collect(trees ...*config.Tree) *Plan {
+	p := &Plan{Hubs: map[string][]Spoke{}}
+	for _, t := range trees {
+		c := &collector{plan: p, tree: t}
+		if len(trees) > 1 {
+			c.prefix = filepath.ToSlash(t.Root)
+		}
+		c.collect()
+	}
+	return p
+}
+
+type collector struct {
+	plan   *Plan
+	tree   *config.Tree
+	prefix string
+	// psaRanges maps network names to the names of the PSA ranges the
+	// networking stage allocates in them.
+	psaRanges map[string]string
+}
+
+func (c *collector) file(name string) string {
+	if c.prefix == "" {
+		return name
+	}
+	return path.Join(c.prefix, name)
+}
+
+func (c *collector) finding(sev report.Severity, check, file, resource, format string, args ...any) {
+	c.plan.Findings = append(c.plan.Findings, report.Finding{
+		Severity: sev, Check: check, File: c.file(file), Resource: resource, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// add records a range. Empty values, as in the unfilled tfvars templates, are
+// skipped.
+func (c *collector) add(a Allocation, cidr string, file string, masked bool) {
+	if cidr == "" || isPlaceholder(cidr) {
+		return
+	}
+	a.File = c.file(file)
+	p, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		c.finding(report.Error, "invalid-cidr", file, a.Resource(), "%q is not a CIDR range", cidr)
+		return
+	}
+	if masked {
+		// BGP session ranges hold the address of the router interface.
+		p = p.Masked()
+	} else if p != p.Masked() {
+		c.finding(report.Error, "host-bits", file, a.Resource(), "%s has host bits set, the range is %s", p, p.Masked())
+		p = p.Masked()
+	}
+	a.Prefix = p
+	c.plan.Allocations = append(c.plan.Allocations, a)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isPlaceholder(s string) bool {
+	return strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">")
+}
+
+// NetworkName returns the name of a network given by name or self link, e.g.
+// projects/p/global/networks/vpc.
+func NetworkName(ref string) string {
+	if i := strings.LastIndex(ref, "/networks/"); i >= 0 {
+		return strings.Trim(ref[i+len("/networks/"):], "/")
+	}
+	return ref
+}
+
+func (c *collector) collect() {
+	c.psaRanges = map[string]string{}
+	// Producers and consumers reference the networking ranges by name, so
+	// the networking stage goes first.
+	if s := c.tree.Stage("networking"); s != nil {
+		if v, ok := s.Vars.(*config.NetworkingVars); ok {
+			c.networking(v, s.VarsFile)
+		}
+	}
+	for _, s := range c.tree.Stages {
+		if s.Stage.Name == "networking" {
+			continue
+		}
+		for _, d := range s.Documents {
+			switch v := d.Value.(type) {
+			case *config.NCCConfig:
+				c.ncc(v, d.File)
+			case *config.CloudSQLStruct:
+				c.cloudSQL(v, d.File)
+			case *config.AlloyDBStruct:
+				c.alloyDB(v, d.File)
+			case *config.GKEConfig:
+				c.gke(v, s.Stage.Name, d.File)
+			case *config.VPCAccessConnectorConfig:
+				c.connector(v, s, d.File)
+			}
+		}
+	}
+}
+
+func (c *collector) networking(v *config.NetworkingVars, file string) {
+	const stage = "networking"
+	network := v.NetworkName
+	for _, s := range v.Subnets {
+		name := s.Name
+		c.add(Allocation{Network: network, Kind: Subnet, Name: name, Region: s.Region, Stage: stage}, s.IPCIDRRange, file, false)
+		for _, r := range sortedKeys(s.SecondaryIPRanges) {
+			c.add(Allocation{Network: network, Kind: SecondaryRange, Name: name + "/" + r, Region: s.Region, Stage: stage}, s.SecondaryIPRanges[r], file, false)
+		}
+	}
+	if v.CreatePSA == nil || v.CreatePSA.Enabled() {
+		name := v.PSARangeName
+		if name == "" {
+			name = DefaultPSARangeName
+		}
+		cidr := v.PSARange
+		if cidr == "" {
+			cidr = DefaultPSARange
+		}
+		c.psaRanges[network] = name
+		c.add(Allocation{Network: network, Kind: PSARange, Name: name, Stage: stage}, cidr, file, false)
+	}
+	if v.CreateHAVPN.Enabled() {
+		for i, cidr := range []string{v.Tunnel1RouterBGPSessionRange, v.Tunnel2RouterBGPSessionRange} {
+			if cidr == "" {
+				cidr = defaultTunnelRanges[i]
+			}
+			c.add(Allocation{Network: network, Kind: BGPSession, Name: fmt.Sprintf("remote-%d", i), Region: v.Region, Stage: stage}, cidr, file, true)
+		}
+	}
+	if v.CreateInterconnect.Enabled() {
+		c.add(Allocation{Network: network, Kind: VLANAttachment, Name: v.FirstVAName, Region: v.Region, Stage: stage}, v.FirstVABGPRange, file, true)
+		c.add(Allocation{Network: network, Kind: VLANAttachment, Name: v.SecondVAName, Region: v.Region, Stage: stage}, v.SecondVABGPRange, file, true)
+	}
+}
+
+func (c *collector) ncc(v *config.NCCConfig, file string) {
+	if len(v.Hubs) == 0 {
+		return
+	}
+	// Like the stage, spokes attach to the first hub of their file.
+	hub := v.Hubs[0].Name
+	for _, s := range v.Spokes {
+		if s.Type != "linked_vpc_network" && s.Type != "linked_producer_vpc_network" {
+			continue
+		}
+		if s.URI == "" || isPlaceholder(NetworkName(s.URI)) {
+			continue
+		}
+		spoke := Spoke{Name: s.Name, Network: NetworkName(s.URI), Producer: s.Type == "linked_producer_vpc_network", File: c.file(file)}
+		spoke.Include = c.prefixes(s.IncludeExportRanges, file, s.Name)
+		spoke.Exclude = c.prefixes(s.ExcludeExportRanges, file, s.Name)
+		c.plan.Hubs[hub] = append(c.plan.Hubs[hub], spoke)
+	}
+}
+
+func (c *collector) prefixes(cidrs []string, file, spoke string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, cidr := range cidrs {
+		if isPlaceholder(cidr) {
+			continue
+		}
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			c.finding(report.Error, "invalid-cidr", file, fmt.Sprintf("spoke %q", spoke), "%q is not a CIDR range", cidr)
+			continue
+		}
+		out = append(out, p.Masked())
+	}
+	return out
+}
+
+// psaRange checks that a producer uses the PSA range the networking stage
+// allocates in its network. Networks not configured in the tree are skipped.
+func (c *collector) psaRange(networkRef, rangeName, file, resource string) {
+	network := NetworkName(networkRef)
+	want, ok := c.psaRanges[network]
+	if rangeName == "" || isPlaceholder(rangeName) || !ok {
+		return
+	}
+	if rangeName != want {
+		c.finding(report.Error, "unknown-range", file, resource,
+			"allocated range %q is not the PSA range of network %q (%q)", rangeName, network, want)
+	}
+}
+
+func (c *collector) cloudSQL(v *config.CloudSQLStruct, file string) {
+	psa := v.NetworkConfig.Connectivity.PSAConfig
+	if psa == nil || psa.AllocatedIPRanges == nil {
+		return
+	}
+	resource := fmt.Sprintf("instance %q", v.Name)
+	c.psaRange(psa.PrivateNetwork, psa.AllocatedIPRanges.Primary, file, resource)
+	c.psaRange(psa.PrivateNetwork, psa.AllocatedIPRanges.Replica, file, resource)
+}
+
+func (c *collector) alloyDB(v *config.AlloyDBStruct, file string) {
+	c.psaRange(v.NetworkID, v.AllocatedIPRange, file, fmt.Sprintf("cluster %q", v.ClusterID))
+}
+
+func (c *collector) gke(v *config.GKEConfig, stage, file string) {
+	network, subnet := NetworkName(v.Network), path.Base(v.Subnetwork)
+	c.add(Allocation{Network: network, Kind: GKEMaster, Name: v.Name, Region: v.Region, Stage: stage}, v.MasterIPV4CIDRBlock, file, false)
+
+	// Pod and service ranges are secondary ranges of the node subnet.
+	var ranges map[string]bool
+	for _, a := range c.plan.Allocations {
+		if a.Network == network && a.Kind == SecondaryRange && strings.HasPrefix(a.Name, subnet+"/") {
+			if ranges == nil {
+				ranges = map[string]bool{}
+			}
+			ranges[strings.TrimPrefix(a.Name, subnet+"/")] = true
+		}
+	}
+	if ranges == nil {
+		// The subnet is not declared in this tree.
+		return
+	}
+	for _, r := range append([]string{v.IPRangePods, v.IPRangeServices}, v.AdditionalIPRangePods...) {
+		if r != "" && !isPlaceholder(r) && !ranges[r] {
+			c.finding(report.Error, "unknown-range", file, fmt.Sprintf("cluster %q", v.Name),
+				"subnet %q of network %q has no secondary range %q", subnet, network, r)
+		}
+	}
+}
+
+func (c *collector) connector(v *config.VPCAccessConnectorConfig, s *config.StageConfig, file string) {
+	network, cidr := v.Network, v.IPCIDRRange
+	if defaults, ok := s.Vars.(*map[string]any); ok {
+		if network == "" {
+			network, _ = (*defaults)["network"].(string)
+		}
+		if cidr == "" {
+			cidr, _ = (*defaults)["ip_cidr_range"].(string)
+		}
+	}
+	if v.SubnetName != "" && v.IPCIDRRange == "" {
+		// The connector uses an existing subnet.
+		return
+	}
+	c.add(Allocation{Network: NetworkName(network), Kind: Connector, Name: v.Name, Region: v.Region, Stage: s.Stage.Name}, cidr, file, false)
+}
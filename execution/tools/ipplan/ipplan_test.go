@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipplan
+
+import (
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func loadPlan(t *testing.T) *Plan {
+	t.Helper()
+	var trees []*config.Tree
+	for _, root := range []string{"testdata/vpc-a", "testdata/vpc-b"} {
+		tree, err := config.LoadTree(root)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", root, err)
+		}
+		trees = append(trees, tree)
+	}
+	return Collect(trees...)
+}
+
+func TestCheck(t *testing.T) {
+	var got []string
+	for _, f := range loadPlan(t).Check(Options{}) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		`ERROR   testdata/vpc-a/networking.tfvars: subnet "subnet-c": 10.1.0.1/24 has host bits set, the range is 10.1.0.0/24 [host-bits]`,
+		`ERROR   testdata/vpc-a/producer/CloudSQL/config/sql-1.yaml: instance "sql-1": allocated range "other-range" is not the PSA range of network "vpc-a" ("psarange") [unknown-range]`,
+		`ERROR   testdata/vpc-a/producer/GKE/config/cluster.yaml: cluster "cluster-1": subnet "subnet-a" of network "vpc-a" has no secondary range "svc" [unknown-range]`,
+		`ERROR   testdata/vpc-a/networking.tfvars: subnet "subnet-a": 10.0.0.0/24 overlaps subnet "subnet-b" (10.0.0.128/25, testdata/vpc-a/networking.tfvars) in network "vpc-a" [overlap]`,
+		`WARNING testdata/vpc-a/networking.tfvars: subnet "subnet-d": 100.64.0.0/24 is not in the allowed blocks [10.0.0.0/8 172.16.0.0/12 192.168.0.0/16] [outside-allowed]`,
+		`ERROR   testdata/vpc-a/networking.tfvars: psa_range "psarange": 10.0.64.0/20 overlaps gke_master "cluster-1" (10.0.64.16/28, testdata/vpc-a/producer/GKE/config/cluster.yaml) in network "vpc-a" [overlap]`,
+		`ERROR   testdata/vpc-a/networking.tfvars: bgp_session "remote-0": 169.254.1.0/30 overlaps bgp_session "remote-1" (169.254.1.0/30, testdata/vpc-a/networking.tfvars) in network "vpc-a" [overlap]`,
+		// The pods range overlaps subnet-y but is excluded from the export.
+		`ERROR   testdata/vpc-a/consumer/Serverless/VPCAccessConnector/config/connector.yaml: connector "connector-1": 10.8.0.0/28 overlaps subnet "subnet-x" (10.8.0.0/24, testdata/vpc-b/networking.tfvars) in NCC hub "hub-1", exported by spokes "spoke-a" and "spoke-b" [hub-overlap]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() findings mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestExhaustion(t *testing.T) {
+	opts := Options{Pools: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/23"), netip.MustParsePrefix("10.0.64.0/18")}}
+	var got []string
+	for _, f := range loadPlan(t).Check(opts) {
+		if f.Check == "exhaustion" {
+			got = append(got, f.String())
+		}
+	}
+	want := []string{`WARNING network "vpc-a": 100% of 10.0.0.0/23 is allocated (512 of 512 addresses) [exhaustion]`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Exhaustion findings = %q, want %q", got, want)
+	}
+}
+
+func TestNextFree(t *testing.T) {
+	p := loadPlan(t)
+	testCases := []struct {
+		name    string
+		network string
+		bits    int
+		pool    string
+		want    string
+		wantErr string
+	}{
+		{name: "AfterSubnets", network: "vpc-a", bits: 24, pool: "10.0.0.0/16", want: "10.0.2.0/24"},
+		{name: "Aligned", network: "vpc-a", bits: 20, pool: "10.0.0.0/16", want: "10.0.16.0/20"},
+		{name: "AvoidsHubPeers", network: "vpc-a", bits: 24, pool: "10.8.0.0/16", want: "10.8.1.0/24"},
+		{name: "UnknownNetwork", network: "vpc-c", bits: 28, pool: "10.0.0.0/16", wantErr: `network "vpc-c" is not in the plan`},
+		{name: "Exhausted", network: "vpc-a", bits: 24, pool: "10.0.0.0/23", wantErr: "no free /24 range"},
+		{name: "InvalidLength", network: "vpc-a", bits: 33, pool: "10.0.0.0/8", wantErr: "invalid IPv4 prefix length"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := p.NextFree(tc.network, tc.bits, Options{Pools: []netip.Prefix{netip.MustParsePrefix(tc.pool)}})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("NextFree() error = %v, want it to contain %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NextFree() failed: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("NextFree() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMap(t *testing.T) {
+	maps := loadPlan(t).Map(Options{Pools: []netip.Prefix{netip.MustParsePrefix("10.5.0.0/16")}})
+	if len(maps) != 2 || maps[1].Network != "vpc-b" {
+		t.Fatalf("Map() networks = %v, want vpc-a and vpc-b", maps)
+	}
+	var allocs []string
+	for _, a := range maps[1].Allocations {
+		allocs = append(allocs, a.Prefix.String())
+	}
+	if want := []string{"10.5.0.0/24", "10.8.0.0/24"}; !reflect.DeepEqual(allocs, want) {
+		t.Errorf("vpc-b allocations = %v, want %v", allocs, want)
+	}
+	var free []string
+	for _, p := range maps[1].Free {
+		free = append(free, p.String())
+	}
+	want := []string{"10.5.1.0/24", "10.5.2.0/23", "10.5.4.0/22", "10.5.8.0/21", "10.5.16.0/20", "10.5.32.0/19", "10.5.64.0/18", "10.5.128.0/17"}
+	if !reflect.DeepEqual(free, want) {
+		t.Errorf("vpc-b free blocks = %v, want %v", free, want)
+	}
+	if u := maps[1].Usage[0]; u.Used != 256 || u.Size != 65536 {
+		t.Errorf("vpc-b usage = %d of %d, want 256 of 65536", u.Used, u.Size)
+	}
+}
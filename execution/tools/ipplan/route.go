@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipplan
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// Lookup returns the allocations of a network containing the address, the
+// most specific first.
+func (p *Plan) Lookup(network string, addr netip.Addr) []Allocation {
+	var out []Allocation
+	for _, a := range p.allocations(network) {
+		if a.Prefix.Contains(addr) {
+			out = append(out, a)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Prefix.Bits() > out[j].Prefix.Bits() })
+	return out
+}
+
+// Route returns the NCC hub through which network from reaches dst in
+// network to: from is a VPC spoke of the hub, and a spoke of to exports a
+// range containing dst.
+func (p *Plan) Route(from, to string, dst netip.Prefix) (string, bool) {
+	hubs := make([]string, 0, len(p.Hubs))
+	for h := range p.Hubs {
+		hubs = append(hubs, h)
+	}
+	sort.Strings(hubs)
+	for _, hub := range hubs {
+		var member, exported bool
+		for _, s := range p.Hubs[hub] {
+			if s.Network == from && !s.Producer {
+				member = true
+			}
+			if s.Network != to {
+				continue
+			}
+			for _, a := range p.exported(s) {
+				if contains(a.Prefix, dst) {
+					exported = true
+				}
+			}
+		}
+		if member && exported {
+			return hub, true
+		}
+	}
+	return "", false
+}
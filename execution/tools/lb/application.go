@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lb
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// applicationBackend is the only backend service the Application Load
+// Balancer stage creates.
+const applicationBackend = "default"
+
+// Defaults of the Application Load Balancer stage and of modules/lb_http.
+const (
+	defaultBackendProtocol     = "HTTP"
+	defaultHealthCheckProtocol = "TCP"
+)
+
+// intOf returns an integer of a generic YAML map, or nil when it is not set
+// or not an integer.
+func intOf(m map[string]any, key string) *int {
+	if n, ok := m[key].(int); ok {
+		return &n
+	}
+	return nil
+}
+
+// floatOf returns a number of a generic YAML map, or nil when it is not set
+// or not a number.
+func floatOf(m map[string]any, key string) *float64 {
+	switch n := m[key].(type) {
+	case int:
+		f := float64(n)
+		return &f
+	case float64:
+		return &n
+	}
+	return nil
+}
+
+func (v *validator) applicationLB(c *config.LoadBalancerConfig) {
+	var keys []string
+	for k := range c.Backends {
+		if k != applicationBackend {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v.add(report.Warning, CheckUnused, "backends.%s is ignored, the stage only creates the %s backend service", k, applicationBackend)
+	}
+	b, ok := c.Backends[applicationBackend]
+	if !ok {
+		v.add(report.Error, CheckBackend, "backends.%s is required, the stage only creates the %s backend service", applicationBackend, applicationBackend)
+		return
+	}
+	field := "backends." + applicationBackend
+	v.applicationGroups(field, b.Groups)
+
+	protocol := strings.ToUpper(b.Protocol)
+	if protocol == "" {
+		protocol = defaultBackendProtocol
+	}
+	if protocol != "HTTP" && protocol != "HTTPS" && protocol != "HTTP2" {
+		v.add(report.Error, CheckBackendService, "%s.protocol %q is not supported, use HTTP, HTTPS or HTTP2", field, b.Protocol)
+	}
+	v.port(CheckBackendService, field+".port", b.Port)
+	if b.TimeoutSec != nil && *b.TimeoutSec <= 0 {
+		v.add(report.Error, CheckBackendService, "%s.timeout_sec %d is not positive", field, *b.TimeoutSec)
+	}
+	if b.LogConfig != nil {
+		v.ratio(CheckBackendService, field+".log_config.sample_rate", floatOf(b.LogConfig, "sample_rate"))
+	}
+	if b.HealthCheck != nil {
+		v.applicationHealthCheck(field+".health_check", b.HealthCheck)
+	}
+}
+
+// applicationGroups checks the instance groups of the backend service. The
+// stage only uses the first group, as a regional instance group.
+func (v *validator) applicationGroups(field string, groups []config.GroupConfig) {
+	if len(groups) == 0 {
+		v.add(report.Error, CheckBackend, "%s.groups is empty, the stage needs one instance group", field)
+		return
+	}
+	for i := range groups[1:] {
+		v.add(report.Warning, CheckUnused, "%s.groups[%d] is ignored, the stage only uses the first instance group", field, i+1)
+	}
+	g := groups[0]
+	field += ".groups[0]"
+	if g.Group == "" {
+		v.add(report.Error, CheckBackend, "%s.group is required", field)
+	}
+	if g.Zone != "" {
+		v.add(report.Error, CheckBackend, "%s.zone is not supported, the stage only references regional instance groups", field)
+	}
+	if g.Region == "" {
+		v.add(report.Error, CheckBackend, "%s.region is required, the stage builds the instance group self link from it", field)
+		return
+	}
+	v.location(field+".region", g.Region, false, "")
+}
+
+// applicationHealthCheck checks the health check of the backend service.
+// modules/lb_http creates it with health_check.protocol, which defaults to
+// TCP whatever the backend protocol.
+func (v *validator) applicationHealthCheck(field string, hc map[string]any) {
+	protocol, _ := hc["protocol"].(string)
+	protocol = strings.ToUpper(protocol)
+	if protocol == "" {
+		protocol = defaultHealthCheckProtocol
+	}
+	http := protocol == "HTTP" || protocol == "HTTPS" || protocol == "HTTP2"
+	if !http && protocol != "TCP" {
+		v.add(report.Error, CheckHealthCheck, "%s.protocol %q is not supported, use HTTP, HTTPS, HTTP2 or TCP", field, hc["protocol"])
+		return
+	}
+	for _, f := range []struct {
+		name    string
+		allowed bool
+	}{
+		{"request_path", http}, {"host", http}, {"request", !http},
+	} {
+		if _, ok := hc[f.name]; !ok || f.allowed {
+			continue
+		}
+		if _, ok := hc["protocol"]; ok {
+			v.add(report.Warning, CheckUnused, "%s.%s is ignored by %s health checks", field, f.name, protocol)
+		} else {
+			v.add(report.Warning, CheckUnused, "%s.%s is ignored, health_check.protocol defaults to TCP: set it to HTTP, HTTPS or HTTP2", field, f.name)
+		}
+	}
+	if path, ok := hc["request_path"].(string); ok && http && !strings.HasPrefix(path, "/") {
+		v.add(report.Error, CheckHealthCheck, "%s.request_path %q does not start with /", field, path)
+	}
+	v.port(CheckHealthCheck, field+".port", intOf(hc, "port"))
+	v.timers(field, healthCheckTimers{
+		interval:  orDefault(intOf(hc, "check_interval_sec"), defaultCheckIntervalSec),
+		timeout:   orDefault(intOf(hc, "timeout_sec"), defaultTimeoutSec),
+		healthy:   orDefault(intOf(hc, "healthy_threshold"), defaultThreshold),
+		unhealthy: orDefault(intOf(hc, "unhealthy_threshold"), defaultThreshold),
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lb validates the mutually dependent fields of the load balancer
+// stages of 07-consumer-load-balancing: the External and Internal passthrough
+// Network Load Balancers and the External Application Load Balancer.
+//
+// Values holding a <placeholder> are not checked.
+package lb
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// Checks reported by Validate.
+const (
+	CheckBackend        = "backend"
+	CheckBackendService = "backend-service"
+	CheckHealthCheck    = "health-check"
+	CheckForwardingRule = "forwarding-rule"
+	CheckUnused         = "unused"
+)
+
+// Session affinities of a passthrough backend service.
+const (
+	AffinityNone              = "NONE"
+	AffinityClientIP          = "CLIENT_IP"
+	AffinityClientIPProto     = "CLIENT_IP_PROTO"
+	AffinityClientIPPortProto = "CLIENT_IP_PORT_PROTO"
+)
+
+// MaxPorts is the number of ports a forwarding rule may list.
+const MaxPorts = 5
+
+var (
+	regionName = regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+$`)
+	zoneName   = regexp.MustCompile(`^([a-z]+-[a-z]+[0-9]+)-[a-z]$`)
+)
+
+type validator struct {
+	file     string
+	resource string
+	findings []report.Finding
+}
+
+func (v *validator) add(sev report.Severity, check, format string, args ...any) {
+	v.findings = append(v.findings, report.Finding{
+		Severity: sev, Check: check, File: v.file, Resource: v.resource, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate checks the load balancer files of a tree.
+func Validate(tree *config.Tree) []report.Finding {
+	v := &validator{}
+	for _, s := range tree.Stages {
+		for _, d := range s.Documents {
+			v.file = d.File
+			switch c := d.Value.(type) {
+			case *config.NetworkLoadBalancerConfig:
+				v.resource = fmt.Sprintf("load balancer %q", c.Name)
+				v.externalNLB(c)
+			case *config.InternalNetworkLoadBalancerConfig:
+				v.resource = fmt.Sprintf("load balancer %q", c.Name)
+				v.internalNLB(c)
+			case *config.LoadBalancerConfig:
+				v.resource = fmt.Sprintf("load balancer %q", c.Name)
+				v.applicationLB(c)
+			}
+		}
+	}
+	return v.findings
+}
+
+func placeholder(s string) bool {
+	return strings.Contains(s, "<")
+}
+
+// location checks the region or zone of an instance group against the
+// region of its load balancer, which is empty when not known.
+func (v *validator) location(field, value string, zonal bool, region string) {
+	if placeholder(value) {
+		return
+	}
+	got := value
+	if zonal {
+		m := zoneName.FindStringSubmatch(value)
+		if m == nil {
+			v.add(report.Error, CheckBackend, "%s %q is not a zone, e.g. us-central1-a", field, value)
+			return
+		}
+		got = m[1]
+	} else if !regionName.MatchString(value) {
+		v.add(report.Error, CheckBackend, "%s %q is not a region, e.g. us-central1", field, value)
+		return
+	}
+	if region != "" && !placeholder(region) && got != region {
+		v.add(report.Error, CheckBackend, "%s %s is not in the load balancer region %s, the backends of a regional backend service must be in its region", field, value, region)
+	}
+}
+
+// ratio checks a value that must be between 0 and 1.
+func (v *validator) ratio(check, field string, value *float64) {
+	if value != nil && (*value < 0 || *value > 1) {
+		v.add(report.Error, check, "%s %g is not between 0 and 1", field, *value)
+	}
+}
+
+// port checks a port number.
+func (v *validator) port(check, field string, port *int) {
+	if port != nil && (*port < 1 || *port > 65535) {
+		v.add(report.Error, check, "%s %d is not between 1 and 65535", field, *port)
+	}
+}
+
+// healthCheckTimers are the timers and thresholds of a health check, with
+// the stage defaults applied.
+type healthCheckTimers struct {
+	interval, timeout, healthy, unhealthy int
+}
+
+func (v *validator) timers(field string, t healthCheckTimers) {
+	for _, b := range []struct {
+		name     string
+		value    int
+		min, max int
+	}{
+		{"check_interval_sec", t.interval, 1, 300},
+		{"timeout_sec", t.timeout, 1, 300},
+		{"healthy_threshold", t.healthy, 1, 10},
+		{"unhealthy_threshold", t.unhealthy, 1, 10},
+	} {
+		if b.value < b.min || b.value > b.max {
+			v.add(report.Error, CheckHealthCheck, "%s.%s %d is not between %d and %d", field, b.name, b.value, b.min, b.max)
+		}
+	}
+	if t.interval >= 1 && t.timeout > t.interval {
+		v.add(report.Error, CheckHealthCheck, "%s.timeout_sec %d is greater than check_interval_sec %d", field, t.timeout, t.interval)
+	}
+}
+
+// orDefault returns *p, or def when p is nil.
+func orDefault[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// forwardingPorts checks the ports of a forwarding rule: L3_DEFAULT rules
+// forward all the ports, TCP and UDP rules up to MaxPorts single ports.
+func (v *validator) forwardingPorts(field, protocol string, ports []string) {
+	if protocol == "L3_DEFAULT" {
+		if len(ports) > 0 {
+			v.add(report.Error, CheckForwardingRule, "%s: an L3_DEFAULT forwarding rule forwards all ports, remove ports", field)
+		}
+		return
+	}
+	if len(ports) > MaxPorts {
+		v.add(report.Error, CheckForwardingRule, "%s: %d ports are listed, a forwarding rule takes up to %d", field, len(ports), MaxPorts)
+	}
+	var seen []int
+	for _, p := range ports {
+		if placeholder(p) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || n < 1 || n > 65535 {
+			v.add(report.Error, CheckForwardingRule, "%s: port %q is not a port number between 1 and 65535, ranges are not supported", field, p)
+			continue
+		}
+		if slices.Contains(seen, n) {
+			v.add(report.Warning, CheckForwardingRule, "%s: port %d is listed twice", field, n)
+			continue
+		}
+		seen = append(seen, n)
+	}
+}
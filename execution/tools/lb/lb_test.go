@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func validate(t *testing.T, root string) []string {
+	t.Helper()
+	tree, err := config.LoadTree(root)
+	if err != nil {
+		t.Fatalf("Failed to load %s: %v", root, err)
+	}
+	var got []string
+	for _, f := range Validate(tree) {
+		got = append(got, f.String())
+	}
+	return got
+}
+
+func TestValidateValid(t *testing.T) {
+	if got := validate(t, "testdata/valid"); len(got) > 0 {
+		t.Errorf("Validate() reported findings on valid files:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	got := validate(t, "testdata/invalid")
+	want := []string{
+		`ERROR   consumer-load-balancing/Application/External/config/alb-hc.yaml: load balancer "alb-hc": backends.default.health_check.protocol "SSL" is not supported, use HTTP, HTTPS, HTTP2 or TCP [health-check]`,
+		`WARNING consumer-load-balancing/Application/External/config/alb-ssl.yaml: load balancer "alb-ssl": backends.web is ignored, the stage only creates the default backend service [unused]`,
+		`ERROR   consumer-load-balancing/Application/External/config/alb-ssl.yaml: load balancer "alb-ssl": backends.default is required, the stage only creates the default backend service [backend]`,
+		`WARNING consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.api is ignored, the stage only creates the default backend service [unused]`,
+		`WARNING consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.groups[1] is ignored, the stage only uses the first instance group [unused]`,
+		`ERROR   consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.groups[0].zone is not supported, the stage only references regional instance groups [backend]`,
+		`ERROR   consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.groups[0].region is required, the stage builds the instance group self link from it [backend]`,
+		`ERROR   consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.protocol "GRPC" is not supported, use HTTP, HTTPS or HTTP2 [backend-service]`,
+		`ERROR   consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.port 0 is not between 1 and 65535 [backend-service]`,
+		`ERROR   consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.timeout_sec 0 is not positive [backend-service]`,
+		`ERROR   consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.log_config.sample_rate 5 is not between 0 and 1 [backend-service]`,
+		`WARNING consumer-load-balancing/Application/External/config/alb.yaml: load balancer "alb-invalid": backends.default.health_check.request_path is ignored, health_check.protocol defaults to TCP: set it to HTTP, HTTPS or HTTP2 [unused]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/Internal/config/ilb-tcp.yaml: load balancer "ilb-tcp": backends: the load balancer has no backend [backend]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/Internal/config/ilb-tcp.yaml: load balancer "ilb-tcp": health_check.type "ssl" is not supported, use tcp, http or https [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/Internal/config/ilb-tcp.yaml: load balancer "ilb-tcp": health_check.port 0 is not between 1 and 65535 [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": backends[0].group_region europe-west1 is not in the load balancer region us-central1, the backends of a regional backend service must be in its region [backend]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": backend_service.session_affinity is ignored by the Internal stage, set session_affinity at the top level [unused]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": backend_service.connection_draining_timeout_sec is ignored by the Internal stage, set connection_draining_timeout_sec at the top level [unused]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": backend_service.failover_config is not supported by the Internal stage and is ignored [unused]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": health_check: the Internal stage ignores the http protocol block and reads health_check.type, port and request_path [unused]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": health_check.enable_logging is ignored by the Internal stage, set enable_log [unused]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": health_check.check_interval_sec 0 is not between 1 and 300 [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": forwarding_rule: an L3_DEFAULT forwarding rule forwards all ports, remove ports [forwarding-rule]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": forwarding_rule.subnetwork is ignored by the Internal stage [unused]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/Internal/config/ilb.yaml: load balancer "ilb-invalid": forwarding_rule.address "app.example.com" is not an IPv4 address [forwarding-rule]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/External/config/nlb-existing-hc.yaml: load balancer "nlb-existing-hc": health_check.name uses an existing health check, the tcp protocol block is ignored [unused]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb-existing-hc.yaml: load balancer "nlb-existing-hc": forwarding_rules["tcp"]: backend_service.protocol UNSPECIFIED needs L3_DEFAULT forwarding rules, not TCP [forwarding-rule]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backends[0] sets both group_zone and group_region, the stage uses the zonal group in us-central1-a and ignores group_region [backend]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backends[1].group_region us-east1 is not in the load balancer region us-central1, the backends of a regional backend service must be in its region [backend]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backends[2].group_zone "uscentral1" is not a zone, e.g. us-central1-a [backend]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backends[3]: instance group us-central1-a/mig-a is already the backend of backends[0] [backend]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backends[4].group_name is required [backend]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.request_path is only read by the Internal stage, set it in a protocol block [unused]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.healthy_threshold 11 is not between 1 and 10 [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.timeout_sec 10 is greater than check_interval_sec 5 [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check sets the tcp, http, grpc protocol blocks, set exactly one [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.tcp.port 70000 is not between 1 and 65535 [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.tcp.request_path is not a field of tcp health checks [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.http: port and port_name cannot be set with port_specification USE_SERVING_PORT [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.http.request_path "healthz" does not start with / [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.grpc: port_specification USE_NAMED_PORT requires port_name [health-check]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": health_check.grpc.request is not a field of grpc health checks [health-check]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backend_service.connection_tracking.track_per_session has no effect with session affinity NONE, connections are tracked by their 5-tuple; use CLIENT_IP or CLIENT_IP_PROTO [backend-service]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backend_service.connection_tracking.idle_timeout_sec 0 is not positive [backend-service]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backend_service.log_sample_rate 2 is not between 0 and 1 [backend-service]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backend_service.failover_config.ratio 1.5 is not between 0 and 1 [backend-service]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backend_service.failover_config has no effect, no backend sets failover [backend-service]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": backend_service.locality_lb_policy WEIGHTED_MAGLEV needs an http, https or http2 health check, not tcp [backend-service]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": forwarding_rules["l3"]: an L3_DEFAULT forwarding rule needs backend_service.protocol UNSPECIFIED, not UDP [forwarding-rule]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": forwarding_rules["l3"]: an L3_DEFAULT forwarding rule forwards all ports, remove ports [forwarding-rule]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": forwarding_rules["tcp"]: protocol TCP differs from backend_service.protocol UDP [forwarding-rule]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": forwarding_rules["tcp"]: 6 ports are listed, a forwarding rule takes up to 5 [forwarding-rule]`,
+		`WARNING consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": forwarding_rules["tcp"]: port 80 is listed twice [forwarding-rule]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": forwarding_rules["tcp"]: port "1000-2000" is not a port number between 1 and 65535, ranges are not supported [forwarding-rule]`,
+		`ERROR   consumer-load-balancing/Network/Passthrough/External/config/nlb.yaml: load balancer "nlb-invalid": forwarding_rules["v6"]: address 203.0.113.10 is not an IPv6 address [forwarding-rule]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
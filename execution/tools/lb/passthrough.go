@@ -0,0 +1,378 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lb
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// Defaults of the passthrough Network Load Balancer stages.
+const (
+	defaultProtocol         = "TCP"
+	defaultInternalHCType   = "http"
+	defaultInternalHCPort   = 80
+	defaultCheckIntervalSec = 5
+	defaultTimeoutSec       = 5
+	defaultThreshold        = 2
+)
+
+var defaultInternalPorts = []string{"80"}
+
+// protocolBlock is a protocol block of a health check.
+type protocolBlock struct {
+	name string
+	*config.HealthCheckProtocolConfig
+}
+
+// protocolBlocks returns the protocol blocks set in a health check.
+func protocolBlocks(hc *config.HealthCheckConfig) []protocolBlock {
+	var blocks []protocolBlock
+	for _, b := range []protocolBlock{
+		{"tcp", hc.TCP}, {"http", hc.HTTP}, {"https", hc.HTTPS},
+		{"http2", hc.HTTP2}, {"grpc", hc.GRPC}, {"ssl", hc.SSL},
+	} {
+		if b.HealthCheckProtocolConfig != nil {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+func blockNames(blocks []protocolBlock) string {
+	names := make([]string, len(blocks))
+	for i, b := range blocks {
+		names[i] = b.name
+	}
+	return strings.Join(names, ", ")
+}
+
+func timersOf(hc *config.HealthCheckConfig) healthCheckTimers {
+	if hc == nil {
+		hc = &config.HealthCheckConfig{}
+	}
+	return healthCheckTimers{
+		interval:  orDefault(hc.CheckIntervalSec, defaultCheckIntervalSec),
+		timeout:   orDefault(hc.TimeoutSec, defaultTimeoutSec),
+		healthy:   orDefault(hc.HealthyThreshold, defaultThreshold),
+		unhealthy: orDefault(hc.UnhealthyThreshold, defaultThreshold),
+	}
+}
+
+// backends checks the instance groups of a passthrough load balancer. Like
+// the stages, a backend uses group_zone when set, then group_region, then
+// the load balancer region.
+func (v *validator) backends(region string, backends []config.BackendConfig) {
+	if len(backends) == 0 {
+		v.add(report.Warning, CheckBackend, "backends: the load balancer has no backend")
+	}
+	seen := map[string]int{}
+	for i, b := range backends {
+		field := fmt.Sprintf("backends[%d]", i)
+		if b.GroupName == "" {
+			v.add(report.Error, CheckBackend, "%s.group_name is required", field)
+		}
+		location := region
+		switch {
+		case b.GroupZone != "":
+			if b.GroupRegion != "" {
+				v.add(report.Error, CheckBackend, "%s sets both group_zone and group_region, the stage uses the zonal group in %s and ignores group_region", field, b.GroupZone)
+			}
+			v.location(field+".group_zone", b.GroupZone, true, region)
+			location = b.GroupZone
+		case b.GroupRegion != "":
+			v.location(field+".group_region", b.GroupRegion, false, region)
+			location = b.GroupRegion
+		}
+		key := location + "/" + b.GroupName
+		if j, ok := seen[key]; ok && b.GroupName != "" && !placeholder(b.GroupName) {
+			v.add(report.Error, CheckBackend, "%s: instance group %s is already the backend of backends[%d]", field, key, j)
+			continue
+		}
+		seen[key] = i
+	}
+}
+
+// protocolBlock checks the fields of a health check protocol block.
+func (v *validator) protocolBlock(field string, b protocolBlock) {
+	field = field + "." + b.name
+	v.port(CheckHealthCheck, field+".port", b.Port)
+	switch b.PortSpecification {
+	case "USE_NAMED_PORT":
+		if b.PortName == "" {
+			v.add(report.Error, CheckHealthCheck, "%s: port_specification USE_NAMED_PORT requires port_name", field)
+		}
+	case "USE_SERVING_PORT":
+		if b.Port != nil || b.PortName != "" {
+			v.add(report.Error, CheckHealthCheck, "%s: port and port_name cannot be set with port_specification USE_SERVING_PORT", field)
+		}
+	}
+	http := b.name == "http" || b.name == "https" || b.name == "http2"
+	for _, f := range []struct {
+		name    string
+		set     bool
+		allowed bool
+	}{
+		{"host", b.Host != "", http},
+		{"request_path", b.RequestPath != "", http},
+		{"request", b.Request != "", b.name == "tcp" || b.name == "ssl"},
+		{"response", b.Response != "", http || b.name == "tcp" || b.name == "ssl"},
+		{"proxy_header", b.ProxyHeader != "", b.name != "grpc"},
+		{"grpc_service_name", b.GRPCServiceName != "", b.name == "grpc"},
+	} {
+		if f.set && !f.allowed {
+			v.add(report.Error, CheckHealthCheck, "%s.%s is not a field of %s health checks", field, f.name, b.name)
+		}
+	}
+	if b.RequestPath != "" && !strings.HasPrefix(b.RequestPath, "/") {
+		v.add(report.Error, CheckHealthCheck, "%s.request_path %q does not start with /", field, b.RequestPath)
+	}
+}
+
+// externalHealthCheck checks the health check of an External passthrough
+// load balancer and returns the protocol it is created with, or "" when an
+// existing health check is used.
+func (v *validator) externalHealthCheck(hc *config.HealthCheckConfig) string {
+	const field = "health_check"
+	if hc == nil {
+		return "tcp"
+	}
+	blocks := protocolBlocks(hc)
+	if hc.Name != "" {
+		if len(blocks) > 0 {
+			v.add(report.Warning, CheckUnused, "%s.name uses an existing health check, the %s protocol block is ignored", field, blockNames(blocks))
+		}
+		return ""
+	}
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"type", hc.Type != ""}, {"port", hc.Port != nil}, {"request_path", hc.RequestPath != ""}, {"enable_log", hc.EnableLog != nil},
+	} {
+		if f.set {
+			v.add(report.Warning, CheckUnused, "%s.%s is only read by the Internal stage, set it in a protocol block", field, f.name)
+		}
+	}
+	v.timers(field, timersOf(hc))
+	switch len(blocks) {
+	case 0:
+		v.add(report.Info, CheckHealthCheck, "%s has no protocol block, the stage creates a TCP health check on the serving port", field)
+		return "tcp"
+	case 1:
+	default:
+		v.add(report.Error, CheckHealthCheck, "%s sets the %s protocol blocks, set exactly one", field, blockNames(blocks))
+	}
+	for _, b := range blocks {
+		v.protocolBlock(field, b)
+	}
+	return blocks[0].name
+}
+
+// connectionTracking checks the connection tracking policy against the
+// session affinity, which sets the tuple PER_SESSION tracking uses.
+func (v *validator) connectionTracking(field, affinity string, ct *config.ConnectionTrackingConfig) {
+	if ct == nil {
+		return
+	}
+	if ct.TrackPerSession != nil && *ct.TrackPerSession && (affinity == AffinityNone || affinity == AffinityClientIPPortProto) {
+		v.add(report.Warning, CheckBackendService, "%s.track_per_session has no effect with session affinity %s, connections are tracked by their 5-tuple; use %s or %s", field, affinity, AffinityClientIP, AffinityClientIPProto)
+	}
+	if ct.IdleTimeoutSec != nil && *ct.IdleTimeoutSec <= 0 {
+		v.add(report.Error, CheckBackendService, "%s.idle_timeout_sec %d is not positive", field, *ct.IdleTimeoutSec)
+	}
+}
+
+func (v *validator) externalNLB(c *config.NetworkLoadBalancerConfig) {
+	if c.Region != "" && !placeholder(c.Region) && !regionName.MatchString(c.Region) {
+		v.add(report.Error, CheckBackend, "region %q is not a region, e.g. us-central1", c.Region)
+	}
+	v.backends(c.Region, c.Backends)
+	hcProtocol := v.externalHealthCheck(c.HealthCheck)
+
+	bs := c.BackendService
+	if bs == nil {
+		bs = &config.BackendServiceConfig{}
+	}
+	const field = "backend_service"
+	affinity := bs.SessionAffinity
+	if affinity == "" {
+		affinity = AffinityNone
+	}
+	v.connectionTracking(field+".connection_tracking", affinity, bs.ConnectionTracking)
+	v.ratio(CheckBackendService, field+".log_sample_rate", bs.LogSampleRate)
+	if bs.FailoverConfig != nil {
+		v.ratio(CheckBackendService, field+".failover_config.ratio", bs.FailoverConfig.Ratio)
+		failover := false
+		for _, b := range c.Backends {
+			failover = failover || (b.Failover != nil && *b.Failover)
+		}
+		if !failover {
+			v.add(report.Warning, CheckBackendService, "%s.failover_config has no effect, no backend sets failover", field)
+		}
+	}
+	if bs.LocalityLBPolicy == "WEIGHTED_MAGLEV" && hcProtocol != "" && !strings.HasPrefix(hcProtocol, "http") {
+		v.add(report.Error, CheckBackendService, "%s.locality_lb_policy WEIGHTED_MAGLEV needs an http, https or http2 health check, not %s", field, hcProtocol)
+	}
+
+	backendProtocol := bs.Protocol
+	if backendProtocol == "" {
+		backendProtocol = defaultProtocol
+	}
+	rules := c.ForwardingRules
+	if len(rules) == 0 {
+		// The stage creates a single rule with the defaults.
+		rules = map[string]config.ForwardingRuleConfig{"": {}}
+	}
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := rules[name]
+		ruleField := fmt.Sprintf("forwarding_rules[%q]", name)
+		protocol := r.Protocol
+		if protocol == "" {
+			protocol = defaultProtocol
+		}
+		switch {
+		case protocol == "L3_DEFAULT" && backendProtocol != "UNSPECIFIED":
+			v.add(report.Error, CheckForwardingRule, "%s: an L3_DEFAULT forwarding rule needs backend_service.protocol UNSPECIFIED, not %s", ruleField, backendProtocol)
+		case protocol != "L3_DEFAULT" && backendProtocol == "UNSPECIFIED":
+			v.add(report.Error, CheckForwardingRule, "%s: backend_service.protocol UNSPECIFIED needs L3_DEFAULT forwarding rules, not %s", ruleField, protocol)
+		case protocol != "L3_DEFAULT" && protocol != backendProtocol:
+			v.add(report.Error, CheckForwardingRule, "%s: protocol %s differs from backend_service.protocol %s", ruleField, protocol, backendProtocol)
+		}
+		v.forwardingPorts(ruleField, protocol, r.Ports)
+		v.address(ruleField, r.Address, r.IPv6 != nil && *r.IPv6)
+	}
+}
+
+// address checks that the IP address of a forwarding rule matches its IP
+// version. Addresses given by name are not checked.
+func (v *validator) address(field, address string, ipv6 bool) {
+	a, err := netip.ParseAddr(address)
+	if err != nil {
+		return
+	}
+	if a.Is4() == ipv6 {
+		version := "IPv4"
+		if ipv6 {
+			version = "IPv6"
+		}
+		v.add(report.Error, CheckForwardingRule, "%s: address %s is not an %s address", field, address, version)
+	}
+}
+
+func (v *validator) internalNLB(c *config.InternalNetworkLoadBalancerConfig) {
+	if c.Region != "" && !placeholder(c.Region) && !regionName.MatchString(c.Region) {
+		v.add(report.Error, CheckBackend, "region %q is not a region, e.g. us-central1", c.Region)
+	}
+	v.backends(c.Region, c.Backends)
+
+	if bs := c.BackendService; bs != nil {
+		// The stage only reads the session affinity and the draining timeout,
+		// at the top level.
+		for _, f := range []struct {
+			name  string
+			set   bool
+			moved bool
+		}{
+			{"session_affinity", bs.SessionAffinity != "", true},
+			{"connection_draining_timeout_sec", bs.ConnectionDrainingTimeoutSec != nil, true},
+			{"protocol", bs.Protocol != "", false},
+			{"port_name", bs.PortName != "", false},
+			{"timeout_sec", bs.TimeoutSec != nil, false},
+			{"log_sample_rate", bs.LogSampleRate != nil, false},
+			{"locality_lb_policy", bs.LocalityLBPolicy != "", false},
+			{"connection_tracking", bs.ConnectionTracking != nil, false},
+			{"failover_config", bs.FailoverConfig != nil, false},
+		} {
+			switch {
+			case f.set && f.moved:
+				v.add(report.Warning, CheckUnused, "backend_service.%s is ignored by the Internal stage, set %s at the top level", f.name, f.name)
+			case f.set:
+				v.add(report.Warning, CheckUnused, "backend_service.%s is not supported by the Internal stage and is ignored", f.name)
+			}
+		}
+	}
+
+	hc := c.HealthCheck
+	if hc == nil {
+		hc = &config.HealthCheckConfig{}
+	}
+	const field = "health_check"
+	if blocks := protocolBlocks(hc); len(blocks) > 0 {
+		v.add(report.Warning, CheckUnused, "%s: the Internal stage ignores the %s protocol block and reads health_check.type, port and request_path", field, blockNames(blocks))
+	}
+	if hc.Name != "" {
+		v.add(report.Warning, CheckUnused, "%s.name is ignored, the Internal stage always creates the health check", field)
+	}
+	if hc.EnableLogging != nil {
+		v.add(report.Warning, CheckUnused, "%s.enable_logging is ignored by the Internal stage, set enable_log", field)
+	}
+	hcType := hc.Type
+	if hcType == "" {
+		hcType = defaultInternalHCType
+	}
+	switch hcType {
+	case "tcp":
+		if hc.RequestPath != "" {
+			v.add(report.Warning, CheckUnused, "%s.request_path is ignored by tcp health checks", field)
+		}
+	case "http", "https":
+		if hc.RequestPath != "" && !strings.HasPrefix(hc.RequestPath, "/") {
+			v.add(report.Error, CheckHealthCheck, "%s.request_path %q does not start with /", field, hc.RequestPath)
+		}
+	default:
+		v.add(report.Error, CheckHealthCheck, "%s.type %q is not supported, use tcp, http or https", field, hc.Type)
+	}
+	v.port(CheckHealthCheck, field+".port", hc.Port)
+	v.timers(field, timersOf(hc))
+
+	r := c.ForwardingRule
+	if r == nil {
+		r = &config.ForwardingRuleConfig{}
+	}
+	protocol, ports := r.Protocol, r.Ports
+	if protocol == "" {
+		protocol = defaultProtocol
+	}
+	if ports == nil {
+		ports = defaultInternalPorts
+	}
+	v.forwardingPorts("forwarding_rule", protocol, ports)
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"subnetwork", r.Subnetwork != ""}, {"ipv6", r.IPv6 != nil}, {"description", r.Description != ""},
+	} {
+		if f.set {
+			v.add(report.Warning, CheckUnused, "forwarding_rule.%s is ignored by the Internal stage", f.name)
+		}
+	}
+	if r.Address != "" && !placeholder(r.Address) {
+		if a, err := netip.ParseAddr(r.Address); err != nil || !a.Is4() {
+			v.add(report.Error, CheckForwardingRule, "forwarding_rule.address %q is not an IPv4 address", r.Address)
+		}
+	}
+}
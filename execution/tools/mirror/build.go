@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/command"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+// Options configure Build.
+type Options struct {
+	// Dir is the mirror directory, created when missing, and Execution the
+	// execution/ directory holding the stages.
+	Dir       string
+	Execution string
+	// Platforms are the platforms to mirror the providers for, such as
+	// linux_amd64.
+	Platforms []string
+	// Run runs terraform, command.Exec when nil.
+	Run command.Runner
+	// Log receives a line per step, when set.
+	Log io.Writer
+}
+
+// Build initializes every stage, without its backend, which writes the lock
+// file of the stage when it has none and installs its modules, then mirrors
+// the providers of the lock file and copies the lock file and the modules
+// into the mirror. It needs network, once; the mirror is then used offline.
+func Build(ctx context.Context, opts Options, stages []config.Stage) error {
+	run := opts.Run
+	if run == nil {
+		run = command.Exec
+	}
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return err
+	}
+	providers := filepath.Join(dir, ProvidersDir)
+	if err := os.MkdirAll(providers, 0755); err != nil {
+		return err
+	}
+	mirrorArgs := []string{"providers", "mirror"}
+	for _, platform := range opts.Platforms {
+		mirrorArgs = append(mirrorArgs, "-platform="+platform)
+	}
+	mirrorArgs = append(mirrorArgs, providers)
+
+	for _, stage := range stages {
+		stageDir := filepath.Join(opts.Execution, filepath.FromSlash(stage.Path))
+		if opts.Log != nil {
+			fmt.Fprintf(opts.Log, "%s: mirroring providers and modules\n", stage.Path)
+		}
+		for _, args := range [][]string{{"init", "-input=false", "-no-color", "-backend=false"}, mirrorArgs} {
+			if _, err := run(ctx, stageDir, "terraform", args...); err != nil {
+				return fmt.Errorf("%s: %w", stageDir, err)
+			}
+		}
+		mirrored := filepath.Join(dir, StagesDir, filepath.FromSlash(stage.Path))
+		if err := os.RemoveAll(mirrored); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(mirrored, 0755); err != nil {
+			return err
+		}
+		if err := copyFile(filepath.Join(stageDir, LockFile), filepath.Join(mirrored, LockFile)); err != nil {
+			return err
+		}
+		modules := filepath.Join(stageDir, ".terraform", "modules")
+		if _, err := os.Stat(modules); err == nil {
+			if err := copyTree(modules, filepath.Join(mirrored, ModulesDir)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, CLIConfigFile), []byte(CLIConfig(dir)), 0644)
+}
+
+// copyTree copies the regular files and directories of src into dst. Remote
+// modules are plain files; .git directories of git sources are left out.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case d.Type().IsRegular():
+			return copyFile(p, target)
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mirror builds and checks a local copy of everything terraform init
+// downloads for the stages, so that the unit tests run without network: a
+// filesystem mirror of the providers pinned by the lock file of each stage,
+// and the remote modules each stage calls. A mirror directory holds
+//
+//	providers/                        terraform providers mirror output
+//	stages/<stage>/.terraform.lock.hcl the lock file of the stage
+//	stages/<stage>/modules/           the .terraform/modules of the stage
+//	terraformrc                       a CLI configuration using providers/
+//
+// where <stage> is the stage directory relative to execution/, such as
+// 04-producer/CloudSQL. The workspace package of the tests reads the same
+// layout.
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// Layout of a mirror directory.
+const (
+	ProvidersDir  = "providers"
+	StagesDir     = "stages"
+	LockFile      = ".terraform.lock.hcl"
+	ModulesDir    = "modules"
+	CLIConfigFile = "terraformrc"
+	// manifestFile is the manifest of the modules terraform init installs.
+	manifestFile = "modules.json"
+)
+
+// Provider is a provider version pinned by a lock file.
+type Provider struct {
+	// Address is the source address of the provider, such as
+	// registry.terraform.io/hashicorp/google.
+	Address string `json:"address"`
+	Version string `json:"version"`
+}
+
+// Archive returns the path of the archive of the provider for a platform,
+// such as linux_amd64, relative to the providers directory of a mirror.
+func (p Provider) Archive(platform string) string {
+	return path.Join(p.Address, fmt.Sprintf("terraform-provider-%s_%s_%s.zip", path.Base(p.Address), p.Version, platform))
+}
+
+// ModuleCall is a call of a remote module by a stage, or by a local module
+// the stage calls.
+type ModuleCall struct {
+	// Key is the key of the call in the module manifest, such as vpc, or
+	// vpc.subnets for a call of the local module vpc.
+	Key    string `json:"key"`
+	Source string `json:"source"`
+}
+
+var (
+	lockSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "provider", LabelNames: []string{"address"}}},
+	}
+	lockProviderSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "version", Required: true}},
+	}
+	moduleFileSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "module", LabelNames: []string{"name"}}},
+	}
+	moduleSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "source"}},
+	}
+)
+
+// DefaultPlatform is the platform of the host, such as linux_amd64.
+func DefaultPlatform() string {
+	return runtime.GOOS + "_" + runtime.GOARCH
+}
+
+// ReadLock returns the providers pinned by a lock file, sorted by address.
+func ReadLock(file string) ([]Provider, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	f, diags := hclparse.NewParser().ParseHCL(src, file)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	content, _, diags := f.Body.PartialContent(lockSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var providers []Provider
+	for _, block := range content.Blocks {
+		attrs, _, diags := block.Body.PartialContent(lockProviderSchema)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		v, diags := attrs.Attributes["version"].Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		providers = append(providers, Provider{Address: block.Labels[0], Version: v.AsString()})
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Address < providers[j].Address })
+	return providers, nil
+}
+
+// ModuleCalls returns the remote module calls of the stage at stageDir and
+// of the local modules it calls, directly or not, sorted by key.
+func ModuleCalls(stageDir string) ([]ModuleCall, error) {
+	var calls []ModuleCall
+	var read func(dir, prefix string, seen map[string]bool) error
+	read = func(dir, prefix string, seen map[string]bool) error {
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			return fmt.Errorf("module %s calls itself", dir)
+		}
+		seen[dir] = true
+		defer delete(seen, dir)
+		files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+		if err != nil {
+			return err
+		}
+		parser := hclparse.NewParser()
+		for _, file := range files {
+			f, diags := parser.ParseHCLFile(file)
+			if diags.HasErrors() {
+				return diags
+			}
+			content, _, diags := f.Body.PartialContent(moduleFileSchema)
+			if diags.HasErrors() {
+				return diags
+			}
+			for _, block := range content.Blocks {
+				body, _, _ := block.Body.PartialContent(moduleSchema)
+				attr, ok := body.Attributes["source"]
+				if !ok {
+					continue
+				}
+				v, diags := attr.Expr.Value(nil)
+				if diags.HasErrors() || v.IsNull() {
+					continue
+				}
+				key, source := prefix+block.Labels[0], v.AsString()
+				if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+					if err := read(filepath.Join(dir, filepath.FromSlash(source)), key+".", seen); err != nil {
+						return err
+					}
+					continue
+				}
+				calls = append(calls, ModuleCall{Key: key, Source: source})
+			}
+		}
+		return nil
+	}
+	if err := read(stageDir, "", map[string]bool{}); err != nil {
+		return nil, err
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Key < calls[j].Key })
+	return calls, nil
+}
+
+// CLIConfig returns a Terraform CLI configuration installing every provider
+// from the providers directory of the mirror at dir, an absolute path, and
+// never from the network.
+func CLIConfig(dir string) string {
+	return fmt.Sprintf(`provider_installation {
+  filesystem_mirror {
+    path    = %q
+    include = ["*/*/*"]
+  }
+}
+`, filepath.ToSlash(filepath.Join(dir, ProvidersDir)))
+}
+
+// manifest is the module manifest terraform init writes.
+type manifest struct {
+	Modules []struct {
+		Key    string `json:"Key"`
+		Source string `json:"Source"`
+		Dir    string `json:"Dir"`
+	} `json:"Modules"`
+}
+
+// Verify reports what the mirror at dir lacks for terraform init of the
+// stages at execution on the platforms: the lock file of a stage, the
+// archive of a provider it pins, or a remote module it calls. Stages missing
+// from the execution directory are reported as warnings.
+func Verify(dir, execution string, stages []config.Stage, platforms []string) ([]report.Finding, error) {
+	var findings []report.Finding
+	add := func(severity report.Severity, check string, stage config.Stage, resource, format string, args ...any) {
+		findings = append(findings, report.Finding{
+			Severity: severity,
+			Check:    check,
+			File:     stage.Path,
+			Resource: resource,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+	for _, stage := range stages {
+		stageDir := filepath.Join(execution, filepath.FromSlash(stage.Path))
+		if _, err := os.Stat(stageDir); err != nil {
+			add(report.Warning, "missing-stage", stage, "", "stage directory %s not found", stageDir)
+			continue
+		}
+		mirrored := filepath.Join(dir, StagesDir, filepath.FromSlash(stage.Path))
+
+		lock := filepath.Join(mirrored, LockFile)
+		providers, err := ReadLock(lock)
+		switch {
+		case os.IsNotExist(err):
+			add(report.Error, "missing-lock", stage, "", "the mirror has no lock file for the stage")
+		case err != nil:
+			return nil, err
+		}
+		for _, p := range providers {
+			for _, platform := range platforms {
+				if _, err := os.Stat(filepath.Join(dir, ProvidersDir, filepath.FromSlash(p.Archive(platform)))); err != nil {
+					add(report.Error, "missing-provider", stage, p.Address, "the mirror has no %s %s archive for %s", p.Address, p.Version, platform)
+				}
+			}
+		}
+
+		calls, err := ModuleCalls(stageDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stage.Path, err)
+		}
+		if len(calls) == 0 {
+			continue
+		}
+		installed := map[string]string{}
+		data, err := os.ReadFile(filepath.Join(mirrored, ModulesDir, manifestFile))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			var m manifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				return nil, fmt.Errorf("%s: %w", stage.Path, err)
+			}
+			for _, mod := range m.Modules {
+				installed[mod.Key] = mod.Dir
+			}
+		}
+		for _, call := range calls {
+			modDir, ok := installed[call.Key]
+			if ok {
+				// Dir is relative to the stage, under .terraform/modules.
+				rel := strings.TrimPrefix(path.Clean(filepath.ToSlash(modDir)), ".terraform/modules/")
+				_, err := os.Stat(filepath.Join(mirrored, ModulesDir, filepath.FromSlash(rel)))
+				ok = err == nil
+			}
+			if !ok {
+				add(report.Error, "missing-module", stage, "module "+call.Key, "the mirror has no copy of %s", call.Source)
+			}
+		}
+	}
+	return findings, nil
+}
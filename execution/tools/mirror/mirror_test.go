@@ -0,0 +1,261 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/command"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+const lock = `# This file is maintained automatically by "terraform init".
+
+provider "registry.terraform.io/hashicorp/google-beta" {
+  version     = "6.20.0"
+  constraints = ">= 6.20.0, < 7.0.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/google" {
+  version     = "6.21.0"
+  constraints = ">= 6.20.0, < 7.0.0"
+}
+`
+
+const manifestJSON = `{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"nat","Source":"git::https://example.com/fabric.git//modules/net-cloudnat?ref=v36.0.1","Dir":".terraform/modules/nat/modules/net-cloudnat"},{"Key":"vpc","Source":"../modules/vpc","Dir":"../modules/vpc"},{"Key":"vpc.subnets","Source":"registry.terraform.io/example/subnets/google","Version":"1.0.0","Dir":".terraform/modules/vpc.subnets"}]}`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// execution returns an execution directory with the stage 02-networking,
+// calling a remote module and a local module which calls a remote module.
+func execution(t *testing.T) string {
+	t.Helper()
+	return writeFiles(t, map[string]string{
+		"02-networking/nat.tf": `module "nat" {
+  source = "git::https://example.com/fabric.git//modules/net-cloudnat?ref=v36.0.1"
+}
+`,
+		"02-networking/vpc.tf": `module "vpc" {
+  source = "../modules/vpc"
+}
+
+terraform {
+  required_providers {
+    google = {
+      source = "hashicorp/google"
+    }
+  }
+}
+`,
+		"modules/vpc/main.tf": `module "subnets" {
+  source  = "registry.terraform.io/example/subnets/google"
+  version = "1.0.0"
+}
+`,
+	})
+}
+
+var networking = config.Stage{Name: "networking", Path: "02-networking"}
+
+func TestReadLock(t *testing.T) {
+	dir := writeFiles(t, map[string]string{LockFile: lock})
+	got, err := ReadLock(filepath.Join(dir, LockFile))
+	if err != nil {
+		t.Fatalf("Failed to read lock file: %v", err)
+	}
+	want := []Provider{
+		{Address: "registry.terraform.io/hashicorp/google", Version: "6.21.0"},
+		{Address: "registry.terraform.io/hashicorp/google-beta", Version: "6.20.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to read lock file: got %+v, want %+v", got, want)
+	}
+	if got, want := got[0].Archive("linux_amd64"), "registry.terraform.io/hashicorp/google/terraform-provider-google_6.21.0_linux_amd64.zip"; got != want {
+		t.Errorf("Failed to name the archive: got %s, want %s", got, want)
+	}
+}
+
+func TestModuleCalls(t *testing.T) {
+	got, err := ModuleCalls(filepath.Join(execution(t), "02-networking"))
+	if err != nil {
+		t.Fatalf("Failed to read module calls: %v", err)
+	}
+	want := []ModuleCall{
+		{Key: "nat", Source: "git::https://example.com/fabric.git//modules/net-cloudnat?ref=v36.0.1"},
+		{Key: "vpc.subnets", Source: "registry.terraform.io/example/subnets/google"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to read module calls: got %+v, want %+v", got, want)
+	}
+}
+
+func TestModuleCallsRepository(t *testing.T) {
+	for _, stage := range config.Stages {
+		if _, err := ModuleCalls(filepath.Join("..", "..", filepath.FromSlash(stage.Path))); err != nil {
+			t.Errorf("Failed to read module calls of %s: %v", stage.Path, err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	exec := execution(t)
+	complete := map[string]string{
+		"stages/02-networking/.terraform.lock.hcl":                                                                    lock,
+		"stages/02-networking/modules/modules.json":                                                                   manifestJSON,
+		"stages/02-networking/modules/nat/modules/net-cloudnat/main.tf":                                               "",
+		"stages/02-networking/modules/vpc.subnets/main.tf":                                                            "",
+		"providers/registry.terraform.io/hashicorp/google/terraform-provider-google_6.21.0_linux_amd64.zip":           "",
+		"providers/registry.terraform.io/hashicorp/google-beta/terraform-provider-google-beta_6.20.0_linux_amd64.zip": "",
+	}
+	tests := []struct {
+		name   string
+		remove string
+		want   []string
+	}{
+		{name: "complete"},
+		{
+			name:   "missing lock file",
+			remove: "stages/02-networking/.terraform.lock.hcl",
+			want:   []string{"missing-lock "},
+		},
+		{
+			name:   "missing provider",
+			remove: "providers/registry.terraform.io/hashicorp/google-beta/terraform-provider-google-beta_6.20.0_linux_amd64.zip",
+			want:   []string{"missing-provider registry.terraform.io/hashicorp/google-beta"},
+		},
+		{
+			name:   "missing module directory",
+			remove: "stages/02-networking/modules/vpc.subnets/main.tf",
+			want:   []string{"missing-module module vpc.subnets"},
+		},
+		{
+			name:   "missing manifest",
+			remove: "stages/02-networking/modules/modules.json",
+			want:   []string{"missing-module module nat", "missing-module module vpc.subnets"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{}
+			for name, content := range complete {
+				if name != tt.remove {
+					files[name] = content
+				}
+			}
+			dir := writeFiles(t, files)
+			findings, err := Verify(dir, exec, []config.Stage{networking}, []string{"linux_amd64"})
+			if err != nil {
+				t.Fatalf("Failed to verify the mirror: %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.Check+" "+f.Resource)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Failed to verify the mirror: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	findings, err := Verify(t.TempDir(), exec, []config.Stage{{Path: "99-missing"}}, nil)
+	if err != nil || len(findings) != 1 || findings[0].Check != "missing-stage" {
+		t.Errorf("Failed to report a missing stage: got %+v, %v", findings, err)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	exec := execution(t)
+	dir := filepath.Join(t.TempDir(), "mirror")
+	var calls []string
+	run := func(_ context.Context, d, _ string, args ...string) ([]byte, error) {
+		calls = append(calls, filepath.Base(d)+": "+strings.Join(args, " "))
+		switch args[0] {
+		case "init":
+			for name, content := range map[string]string{
+				LockFile:                              lock,
+				".terraform/modules/modules.json":     manifestJSON,
+				".terraform/modules/nat/.git/HEAD":    "ref",
+				".terraform/modules/vpc.subnets/x.tf": "",
+			} {
+				file := filepath.Join(d, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					return nil, err
+				}
+				if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+					return nil, err
+				}
+			}
+		case "providers":
+			if _, err := os.Stat(args[len(args)-1]); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+	opts := Options{Dir: dir, Execution: exec, Platforms: []string{"linux_amd64", "darwin_arm64"}, Run: run}
+	if err := Build(context.Background(), opts, []config.Stage{networking}); err != nil {
+		t.Fatalf("Failed to build the mirror: %v", err)
+	}
+	abs, _ := filepath.Abs(dir)
+	want := []string{
+		"02-networking: init -input=false -no-color -backend=false",
+		"02-networking: providers mirror -platform=linux_amd64 -platform=darwin_arm64 " + filepath.Join(abs, ProvidersDir),
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Failed to run terraform:\ngot  %q\nwant %q", calls, want)
+	}
+	for _, file := range []string{"stages/02-networking/" + LockFile, "stages/02-networking/modules/modules.json", "stages/02-networking/modules/vpc.subnets/x.tf", CLIConfigFile} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("Failed to write %s: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stages/02-networking/modules/nat/.git")); !os.IsNotExist(err) {
+		t.Errorf("Failed to leave out .git: %v", err)
+	}
+	rc, _ := os.ReadFile(filepath.Join(dir, CLIConfigFile))
+	if !strings.Contains(string(rc), filepath.ToSlash(filepath.Join(abs, ProvidersDir))) {
+		t.Errorf("Failed to point the CLI configuration at the mirror:\n%s", rc)
+	}
+
+	failing := func(_ context.Context, _, name string, args ...string) ([]byte, error) {
+		return nil, &command.Error{Name: name, Args: args, Err: errors.New("exit status 1"), Stderr: "Error: Failed to query available provider packages"}
+	}
+	opts.Run = failing
+	if err := Build(context.Background(), opts, []config.Stage{networking}); err == nil || !strings.Contains(err.Error(), "Failed to query") {
+		t.Errorf("Failed to report the terraform error: got %v", err)
+	}
+}
@@ -0,0 +1,411 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ncc validates the hubs and spokes of the 02-networking/NCC stage
+// against the stage and API rules, and against the networks, HA VPN tunnels
+// and VLAN attachments the networking stage creates.
+package ncc
+
+import (
+	"fmt"
+	"net/netip"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ipplan"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// Spoke types created by the stage.
+const (
+	VPCSpoke          = "linked_vpc_network"
+	ProducerVPCSpoke  = "linked_producer_vpc_network"
+	VPNSpoke          = "linked_vpn_tunnels"
+	InterconnectSpoke = "linked_interconnect_attachments"
+	RouterSpoke       = "router_appliance_spoke"
+)
+
+// Preset topologies and their groups.
+const (
+	Mesh = "MESH"
+	Star = "STAR"
+
+	DefaultGroup = "default"
+	CenterGroup  = "center"
+	EdgeGroup    = "edge"
+)
+
+// Checks reported by Validate, besides the hub-overlap check of ipplan.
+const (
+	CheckHub         = "hub"
+	CheckHubURI      = "hub-uri"
+	CheckSpoke       = "spoke"
+	CheckNetwork     = "network"
+	CheckHybrid      = "hybrid"
+	CheckExportRange = "export-range"
+	CheckTopology    = "topology"
+)
+
+const stageName = "networking/ncc"
+
+var (
+	hubURI          = regexp.MustCompile(`^(https://networkconnectivity\.googleapis\.com/v1/)?projects/[^/]+/locations/global/hubs/[a-z]([-a-z0-9]*[a-z0-9])?$`)
+	networkURI      = regexp.MustCompile(`^(https://www\.googleapis\.com/compute/v1/)?projects/[^/]+/global/networks/[^/]+$`)
+	tunnelURI       = regexp.MustCompile(`^(https://www\.googleapis\.com/compute/v1/)?projects/[^/]+/regions/([^/]+)/vpnTunnels/[^/]+$`)
+	attachmentURI   = regexp.MustCompile(`^(https://www\.googleapis\.com/compute/v1/)?projects/[^/]+/regions/([^/]+)/interconnectAttachments/[^/]+$`)
+	defaultVAs      = [2]string{"dedicated-ic-vlan-attachment-3", "dedicated-ic-vlan-attachment-4"}
+	defaultVPNName  = "vpn1"
+	hybridResources = map[string]struct {
+		uri  *regexp.Regexp
+		kind string
+	}{
+		VPNSpoke:          {tunnelURI, "VPN tunnel"},
+		InterconnectSpoke: {attachmentURI, "VLAN attachment"},
+	}
+)
+
+// networking holds what the networking stages of the trees create.
+type networking struct {
+	// networks are the names of the networks.
+	networks map[string]bool
+	// tunnels and attachments map the HA VPN tunnel and VLAN attachment
+	// names to their region.
+	tunnels     map[string]string
+	attachments map[string]string
+}
+
+func collectNetworking(trees []*config.Tree) *networking {
+	n := &networking{networks: map[string]bool{}, tunnels: map[string]string{}, attachments: map[string]string{}}
+	for _, t := range trees {
+		s := t.Stage("networking")
+		if s == nil {
+			continue
+		}
+		v, ok := s.Vars.(*config.NetworkingVars)
+		if !ok || v.NetworkName == "" {
+			continue
+		}
+		n.networks[v.NetworkName] = true
+		if v.CreateHAVPN.Enabled() {
+			gateway := v.HAVPNGateway1Name
+			if gateway == "" {
+				gateway = defaultVPNName
+			}
+			// The net-vpn-ha module names the tunnels after the gateway.
+			for _, key := range []string{"remote-0", "remote-1"} {
+				n.tunnels[gateway+"-"+key] = v.Region
+			}
+		}
+		if v.CreateInterconnect.Enabled() {
+			for i, name := range []string{v.FirstVAName, v.SecondVAName} {
+				if name == "" {
+					name = defaultVAs[i]
+				}
+				n.attachments[name] = v.Region
+			}
+		}
+	}
+	return n
+}
+
+type validator struct {
+	net      *networking
+	prefix   string
+	findings []report.Finding
+	// hubs maps the hub names to the file declaring them.
+	hubs map[string]string
+	// vpcs maps the networks linked by VPC spokes to their spoke.
+	vpcs map[string]string
+}
+
+func (v *validator) add(sev report.Severity, check, file, resource, format string, args ...any) {
+	if v.prefix != "" {
+		file = path.Join(v.prefix, file)
+	}
+	v.findings = append(v.findings, report.Finding{
+		Severity: sev, Check: check, File: file, Resource: resource, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate checks the NCC stage of the given trees. The networks, tunnels
+// and attachments spokes link are looked up in the networking stages of all
+// the trees, typically one per VPC, and so are the ranges VPC spokes export
+// to a hub. When several trees are given, file names are prefixed with the
+// tree root.
+//
+// Values holding a <placeholder> are not checked.
+func Validate(trees ...*config.Tree) []report.Finding {
+	v := &validator{net: collectNetworking(trees), hubs: map[string]string{}, vpcs: map[string]string{}}
+	for _, t := range trees {
+		v.prefix = ""
+		if len(trees) > 1 {
+			v.prefix = filepath.ToSlash(t.Root)
+		}
+		s := t.Stage(stageName)
+		if s == nil {
+			continue
+		}
+		for _, d := range s.Documents {
+			if c, ok := d.Value.(*config.NCCConfig); ok {
+				v.file(d.File, c)
+			}
+		}
+	}
+	return append(v.findings, ipplan.Collect(trees...).CheckHubs()...)
+}
+
+func placeholder(s string) bool {
+	return strings.Contains(s, "<")
+}
+
+// file checks the hubs and spokes of a stage file. Like the stage, spokes
+// attach to the first hub of their file.
+func (v *validator) file(file string, c *config.NCCConfig) {
+	for i := range c.Hubs {
+		v.hub(file, &c.Hubs[i])
+	}
+	if len(c.Spokes) == 0 {
+		return
+	}
+	if len(c.Hubs) == 0 {
+		for _, s := range c.Spokes {
+			v.add(report.Warning, CheckSpoke, file, fmt.Sprintf("spoke %q", s.Name), "the file has no hub, the stage attaches spokes to the first hub of their file and skips the spoke")
+		}
+		return
+	}
+	hub := &c.Hubs[0]
+	if len(c.Hubs) > 1 {
+		v.add(report.Info, CheckHub, file, fmt.Sprintf("hub %q", c.Hubs[1].Name), "spokes of the file attach to the first hub %q only", hub.Name)
+	}
+	names := map[string]bool{}
+	for i := range c.Spokes {
+		s := &c.Spokes[i]
+		resource := fmt.Sprintf("spoke %q", s.Name)
+		if names[s.Name] {
+			v.add(report.Error, CheckSpoke, file, resource, "spoke name is used twice, the stage fails with a duplicate object key")
+			continue
+		}
+		names[s.Name] = true
+		v.spoke(file, resource, s)
+		v.group(file, resource, hub, s)
+	}
+	if topology(hub) == Star && hub.ExistingHubURI == "" {
+		v.add(report.Warning, CheckTopology, file, fmt.Sprintf("hub %q", hub.Name), "the spokes join the %s group of the STAR hub as the stage does not set their group, and %s spokes only reach %s spokes", EdgeGroup, EdgeGroup, CenterGroup)
+	}
+}
+
+func topology(h *config.HubConfig) string {
+	if h.PresetTopology == "" {
+		return Mesh
+	}
+	return strings.ToUpper(h.PresetTopology)
+}
+
+func (v *validator) hub(file string, h *config.HubConfig) {
+	resource := fmt.Sprintf("hub %q", h.Name)
+	if other, ok := v.hubs[h.Name]; ok && !placeholder(h.Name) {
+		v.add(report.Error, CheckHub, file, resource, "hub is already declared in %s, hub names must be unique", other)
+		return
+	}
+	v.hubs[h.Name] = path.Join(v.prefix, file)
+	create := h.CreateNewHub != nil && *h.CreateNewHub
+	switch {
+	case h.ExistingHubURI == "" && !create:
+		v.add(report.Error, CheckHub, file, resource, "neither create_new_hub nor existing_hub_uri is set, the stage has no hub to attach the spokes to (create_new_hub defaults to false)")
+	case h.ExistingHubURI != "" && create:
+		v.add(report.Warning, CheckHub, file, resource, "create_new_hub creates hub %q, but the spokes attach to existing_hub_uri", h.Name)
+	}
+	if h.ExistingHubURI != "" && !placeholder(h.ExistingHubURI) {
+		if !hubURI.MatchString(h.ExistingHubURI) {
+			v.add(report.Error, CheckHubURI, file, resource, "existing_hub_uri %q is not of the form projects/<project>/locations/global/hubs/<hub>", h.ExistingHubURI)
+		} else if name := path.Base(h.ExistingHubURI); name != h.Name {
+			v.add(report.Info, CheckHubURI, file, resource, "existing_hub_uri names hub %q, spokes attach to it rather than to %q", name, h.Name)
+		}
+	}
+	if h.PolicyMode != "" && !strings.EqualFold(h.PolicyMode, "PRESET") {
+		v.add(report.Error, CheckTopology, file, resource, "policy_mode %q is not supported, use PRESET", h.PolicyMode)
+	}
+	top := topology(h)
+	group := h.GroupName
+	if group == "" {
+		group = DefaultGroup
+	}
+	switch top {
+	case Mesh:
+		if group != DefaultGroup {
+			v.add(report.Error, CheckTopology, file, resource, "group_name %q is not a group of a MESH hub, which only has the %s group", group, DefaultGroup)
+		}
+	case Star:
+		if group != CenterGroup && group != EdgeGroup {
+			v.add(report.Error, CheckTopology, file, resource, "group_name %q is not a group of a STAR hub, use %s or %s", group, CenterGroup, EdgeGroup)
+		}
+	default:
+		v.add(report.Error, CheckTopology, file, resource, "preset_topology %q is not supported, use %s or %s", h.PresetTopology, Mesh, Star)
+	}
+}
+
+// group checks the group of a spoke against the topology of its hub. The
+// stage does not pass the group to the spokes, which join the default group
+// of a MESH hub and the edge group of a STAR hub.
+func (v *validator) group(file, resource string, hub *config.HubConfig, s *config.SpokeConfig) {
+	if hub.ExistingHubURI != "" || s.Group == "" {
+		// The topology of an existing hub is not known.
+		return
+	}
+	switch topology(hub) {
+	case Mesh:
+		if s.Group != DefaultGroup {
+			v.add(report.Error, CheckTopology, file, resource, "group %q is not a group of MESH hub %q, which only has the %s group", s.Group, hub.Name, DefaultGroup)
+		}
+	case Star:
+		switch s.Group {
+		case EdgeGroup:
+		case CenterGroup:
+			v.add(report.Warning, CheckTopology, file, resource, "the stage does not set the group of spokes, the spoke joins the %s group of STAR hub %q", EdgeGroup, hub.Name)
+		default:
+			v.add(report.Error, CheckTopology, file, resource, "group %q is not a group of STAR hub %q, use %s or %s", s.Group, hub.Name, CenterGroup, EdgeGroup)
+		}
+	}
+}
+
+func (v *validator) spoke(file, resource string, s *config.SpokeConfig) {
+	switch s.Type {
+	case VPCSpoke, ProducerVPCSpoke:
+		v.vpcSpoke(file, resource, s)
+		v.exportRanges(file, resource, s)
+		return
+	case VPNSpoke, InterconnectSpoke:
+		v.hybridSpoke(file, resource, s)
+	case RouterSpoke:
+	default:
+		v.add(report.Error, CheckSpoke, file, resource, "type %q is not a spoke type of the stage, which skips the spoke", s.Type)
+		return
+	}
+	if len(s.IncludeExportRanges) > 0 || len(s.ExcludeExportRanges) > 0 {
+		v.add(report.Warning, CheckExportRange, file, resource, "export ranges only apply to VPC and producer VPC spokes and are ignored")
+	}
+}
+
+func (v *validator) vpcSpoke(file, resource string, s *config.SpokeConfig) {
+	if s.Type == ProducerVPCSpoke {
+		if s.Peering == "" {
+			v.add(report.Error, CheckSpoke, file, resource, "peering is required, e.g. servicenetworking-googleapis-com")
+		}
+		if s.Location != "global" {
+			v.add(report.Error, CheckSpoke, file, resource, "location must be global for a producer VPC spoke, not %q", s.Location)
+		}
+	}
+	switch {
+	case s.URI == "":
+		v.add(report.Error, CheckSpoke, file, resource, "uri is required")
+		return
+	case placeholder(s.URI):
+		return
+	case !networkURI.MatchString(s.URI):
+		v.add(report.Error, CheckSpoke, file, resource, "uri %q is not of the form projects/<project>/global/networks/<network>", s.URI)
+		return
+	}
+	network := ipplan.NetworkName(s.URI)
+	if !v.net.networks[network] {
+		v.add(report.Warning, CheckNetwork, file, resource, "network %q is not created by the networking configuration", network)
+	}
+	if s.Type != VPCSpoke {
+		return
+	}
+	if other, ok := v.vpcs[network]; ok {
+		v.add(report.Error, CheckNetwork, file, resource, "network %q is already linked by spoke %q, a network is the VPC spoke of a single hub", network, other)
+		return
+	}
+	v.vpcs[network] = s.Name
+}
+
+func (v *validator) hybridSpoke(file, resource string, s *config.SpokeConfig) {
+	r := hybridResources[s.Type]
+	if s.Location == "" {
+		v.add(report.Error, CheckHybrid, file, resource, "location is required, the region of the %ss", r.kind)
+	}
+	if len(s.URIs) == 0 {
+		v.add(report.Error, CheckHybrid, file, resource, "uris is required, the %ss of the spoke", r.kind)
+	}
+	known := v.net.tunnels
+	if s.Type == InterconnectSpoke {
+		known = v.net.attachments
+	}
+	for _, uri := range s.URIs {
+		if placeholder(uri) {
+			continue
+		}
+		m := r.uri.FindStringSubmatch(uri)
+		if m == nil {
+			v.add(report.Error, CheckHybrid, file, resource, "%q is not the self link of a %s", uri, r.kind)
+			continue
+		}
+		region, name := m[2], path.Base(uri)
+		if s.Location != "" && !placeholder(s.Location) && region != s.Location {
+			v.add(report.Error, CheckHybrid, file, resource, "%s %q is in %s, not in the spoke location %s", r.kind, name, region, s.Location)
+		}
+		if want, ok := known[name]; !ok {
+			v.add(report.Warning, CheckHybrid, file, resource, "%s %q is not created by the networking configuration", r.kind, name)
+		} else if want != region {
+			v.add(report.Error, CheckHybrid, file, resource, "%s %q is created in %s, not in %s", r.kind, name, want, region)
+		}
+	}
+}
+
+// exportRanges checks the include_export_ranges and exclude_export_ranges
+// filters of a VPC or producer VPC spoke.
+func (v *validator) exportRanges(file, resource string, s *config.SpokeConfig) {
+	parse := func(variable string, cidrs []string) []netip.Prefix {
+		var out []netip.Prefix
+		seen := map[netip.Prefix]bool{}
+		for _, cidr := range cidrs {
+			if placeholder(cidr) {
+				continue
+			}
+			p, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+			if err != nil {
+				v.add(report.Error, CheckExportRange, file, resource, "%s: %q is not a CIDR range", variable, cidr)
+				continue
+			}
+			if p != p.Masked() {
+				v.add(report.Error, CheckExportRange, file, resource, "%s: %s has host bits set, the range is %s", variable, p, p.Masked())
+				p = p.Masked()
+			}
+			if seen[p] {
+				v.add(report.Warning, CheckExportRange, file, resource, "%s: %s is listed twice", variable, p)
+				continue
+			}
+			seen[p] = true
+			out = append(out, p)
+		}
+		return out
+	}
+	include := parse("include_export_ranges", s.IncludeExportRanges)
+	exclude := parse("exclude_export_ranges", s.ExcludeExportRanges)
+	for _, e := range exclude {
+		within := false
+		for _, i := range include {
+			if i == e {
+				v.add(report.Error, CheckExportRange, file, resource, "%s is both included and excluded", e)
+			}
+			within = within || (i.Bits() <= e.Bits() && i.Contains(e.Addr()))
+		}
+		if len(include) > 0 && !within {
+			v.add(report.Warning, CheckExportRange, file, resource, "exclude_export_ranges: %s is outside the include_export_ranges and excludes nothing", e)
+		}
+	}
+}
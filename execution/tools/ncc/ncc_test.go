@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ncc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func loadTrees(t *testing.T, roots ...string) []*config.Tree {
+	t.Helper()
+	var trees []*config.Tree
+	for _, root := range roots {
+		tree, err := config.LoadTree(root)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", root, err)
+		}
+		trees = append(trees, tree)
+	}
+	return trees
+}
+
+func TestValidate(t *testing.T) {
+	var got []string
+	for _, f := range Validate(loadTrees(t, "testdata/vpc-a", "testdata/vpc-b")...) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: hub "hub-star": group_name "default" is not a group of a STAR hub, use center or edge [topology]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: hub "hub-bad-uri": existing_hub_uri "projects/host-project/hubs/hub-bad-uri" is not of the form projects/<project>/locations/global/hubs/<hub> [hub-uri]`,
+		`INFO    testdata/vpc-a/networking/ncc/config/invalid.yaml: hub "hub-bad-uri": spokes of the file attach to the first hub "hub-star" only [hub]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-router": type "router_spoke" is not a spoke type of the stage, which skips the spoke [spoke]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-router": group "spokes" is not a group of STAR hub "hub-star", use center or edge [topology]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-again": network "vpc-d" is not created by the networking configuration [network]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-d": network "vpc-d" is not created by the networking configuration [network]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-d": network "vpc-d" is already linked by spoke "spoke-again", a network is the VPC spoke of a single hub [network]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-again": spoke name is used twice, the stage fails with a duplicate object key [spoke]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-producer-bad": peering is required, e.g. servicenetworking-googleapis-com [spoke]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-producer-bad": location must be global for a producer VPC spoke, not "us-central1" [spoke]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-producer-bad": uri "projects/host-project/networks/servicenetworking" is not of the form projects/<project>/global/networks/<network> [spoke]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-producer-bad": the stage does not set the group of spokes, the spoke joins the edge group of STAR hub "hub-star" [topology]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-vpn-bad": VPN tunnel "gw-1-remote-1" is in us-central1, not in the spoke location us-east1 [hybrid]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-vpn-bad": VPN tunnel "unknown" is not created by the networking configuration [hybrid]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-vpn-bad": "gw-1-remote-0" is not the self link of a VPN tunnel [hybrid]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-vpn-bad": export ranges only apply to VPC and producer VPC spokes and are ignored [export-range]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-ranges": network "vpc-c" is not created by the networking configuration [network]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-ranges": include_export_ranges: 10.0.0.1/8 has host bits set, the range is 10.0.0.0/8 [export-range]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-ranges": include_export_ranges: 10.1.0.0/16 is listed twice [export-range]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-ranges": include_export_ranges: "bogus" is not a CIDR range [export-range]`,
+		`ERROR   testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-ranges": 10.1.0.0/16 is both included and excluded [export-range]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: spoke "spoke-ranges": exclude_export_ranges: 192.168.0.0/16 is outside the include_export_ranges and excludes nothing [export-range]`,
+		`WARNING testdata/vpc-a/networking/ncc/config/invalid.yaml: hub "hub-star": the spokes join the edge group of the STAR hub as the stage does not set their group, and edge spokes only reach center spokes [topology]`,
+		`ERROR   testdata/vpc-b/networking/ncc/config/hubs.yaml: hub "hub-a": hub is already declared in testdata/vpc-a/networking/ncc/config/valid.yaml, hub names must be unique [hub]`,
+		`WARNING testdata/vpc-b/networking/ncc/config/hubs.yaml: hub "hub-existing": create_new_hub creates hub "hub-existing", but the spokes attach to existing_hub_uri [hub]`,
+		`INFO    testdata/vpc-b/networking/ncc/config/hubs.yaml: hub "hub-existing": existing_hub_uri names hub "shared-hub", spokes attach to it rather than to "hub-existing" [hub-uri]`,
+		`ERROR   testdata/vpc-b/networking/ncc/config/hubs.yaml: hub "hub-none": neither create_new_hub nor existing_hub_uri is set, the stage has no hub to attach the spokes to (create_new_hub defaults to false) [hub]`,
+		`ERROR   testdata/vpc-b/networking/ncc/config/hubs.yaml: hub "hub-none": group_name "center" is not a group of a MESH hub, which only has the default group [topology]`,
+		`WARNING testdata/vpc-b/networking/ncc/config/spokes.yaml: spoke "spoke-orphan": the file has no hub, the stage attaches spokes to the first hub of their file and skips the spoke [spoke]`,
+		`ERROR   testdata/vpc-a/networking.tfvars: subnet "subnet-a": 10.0.0.0/24 overlaps subnet "subnet-b" (10.0.0.0/25, testdata/vpc-b/networking.tfvars) in NCC hub "hub-a", exported by spokes "spoke-vpc-a" and "spoke-vpc-b" [hub-overlap]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() mismatch.\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateSingleTree(t *testing.T) {
+	// Without the vpc-b tree, the network of spoke-vpc-b is unknown and file
+	// names are relative to the tree root.
+	want := `WARNING networking/ncc/config/valid.yaml: spoke "spoke-vpc-b": network "vpc-b" is not created by the networking configuration [network]`
+	found := false
+	for _, f := range Validate(loadTrees(t, "testdata/vpc-a")...) {
+		found = found || f.String() == want
+	}
+	if !found {
+		t.Errorf("Validate() has no finding %q", want)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package negative
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// localSource matches the source of a module block calling a local module.
+var localSource = regexp.MustCompile(`(?m)^\s*source\s*=\s*"(\.\.?/[^"]*)"`)
+
+// copyStage copies the stage at stageDir and the local modules it calls, and
+// the ones they call, under root, keeping their layout relative to their
+// closest common parent so that module sources resolve unchanged, as the
+// workspace package of the integration tests does. It returns the path of
+// the stage copy.
+func copyStage(stageDir, root string) (string, error) {
+	stageDir, err := filepath.Abs(stageDir)
+	if err != nil {
+		return "", err
+	}
+	dirs, err := localModules(stageDir)
+	if err != nil {
+		return "", err
+	}
+	base := commonParent(dirs)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(base, dir)
+		if err != nil {
+			return "", err
+		}
+		if err := copyModule(dir, filepath.Join(root, rel)); err != nil {
+			return "", err
+		}
+	}
+	rel, err := filepath.Rel(base, stageDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, rel), nil
+}
+
+// localModules returns the directory of the stage and of every local module
+// it calls, directly or not, sorted.
+func localModules(stageDir string) ([]string, error) {
+	seen := map[string]bool{}
+	var visit func(dir string) error
+	visit = func(dir string) error {
+		if seen[dir] {
+			return nil
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		seen[dir] = true
+		files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			for _, m := range localSource.FindAllStringSubmatch(string(src), -1) {
+				if err := visit(filepath.Join(dir, filepath.FromSlash(m[1]))); err != nil {
+					return fmt.Errorf("module of %s: %w", file, err)
+				}
+			}
+		}
+		return nil
+	}
+	if err := visit(filepath.Clean(stageDir)); err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0, len(seen))
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// commonParent returns the closest directory holding every one of dirs.
+func commonParent(dirs []string) string {
+	base := dirs[0]
+	for _, dir := range dirs[1:] {
+		for base != filepath.Dir(base) && dir != base && !strings.HasPrefix(dir, base+string(filepath.Separator)) {
+			base = filepath.Dir(base)
+		}
+	}
+	return base
+}
+
+// copyModule copies the files of the module at src into dst, leaving out
+// the state, the .terraform directory and the subdirectories holding Terraform files, which are other stages or
+// modules copied on their own when called.
+func copyModule(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if p != src {
+				if d.Name() == ".terraform" {
+					return filepath.SkipDir
+				}
+				if tf, _ := filepath.Glob(filepath.Join(p, "*.tf")); len(tf) > 0 {
+					return filepath.SkipDir
+				}
+			}
+			return os.MkdirAll(target, 0755)
+		}
+		if strings.HasPrefix(d.Name(), "terraform.tfstate") || !d.Type().IsRegular() {
+			return nil
+		}
+		return copyFile(p, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,423 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package negative derives invalid variants of the valid YAML fixtures of a
+// stage from its typed schema in the config package, plans the stage with
+// each variant and reports the variants the stage accepts.
+//
+// A variant changes one field of one fixture file:
+//
+//	missing-required  a field whose yaml tag has no omitempty is removed
+//	wrong-type        a field gets a value of another type, e.g. a list
+//	                  for a string or a string for a boolean
+//	out-of-enum       a field with an enum tag gets a value outside it
+//	malformed-name    a name or ID field (name, *_name, *_id) gets a value
+//	                  no Google Cloud resource name accepts
+//	invalid-cidr      a field holding a CIDR range gets an invalid one
+//
+// The plan of a variant is expected to fail with a diagnostic mentioning
+// the field.
+package negative
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is the kind of change of a variant.
+type Kind string
+
+// Kinds of variants.
+const (
+	MissingRequired Kind = "missing-required"
+	WrongType       Kind = "wrong-type"
+	OutOfEnum       Kind = "out-of-enum"
+	MalformedName   Kind = "malformed-name"
+	InvalidCIDR     Kind = "invalid-cidr"
+)
+
+// Invalid values set by the variants.
+const (
+	invalidEnum = "INVALID_ENUM_VALUE"
+	invalidName = "Invalid_Name!"
+	invalidCIDR = "10.0.0.0/33"
+)
+
+// Variant is a fixture file with one invalid field.
+type Variant struct {
+	Kind Kind `json:"kind"`
+	// File is the name of the fixture file in its folder.
+	File string `json:"file"`
+	// Path is the path of the field in the file, e.g.
+	// network_config.connectivity.psa_config.private_network, with list
+	// indexes in brackets.
+	Path string `json:"path"`
+	// Field is the key of the field, and Names the names a diagnostic may
+	// give it: the key and the stage variable it defaults to.
+	Field string   `json:"field"`
+	Names []string `json:"-"`
+	// Value is the invalid value, nil for a removed field.
+	Value any `json:"value,omitempty"`
+	// Enum lists the values an out-of-enum field accepts.
+	Enum []string `json:"-"`
+	// Doc is the changed YAML document.
+	Doc []byte `json:"-"`
+}
+
+// Describe returns what the variant changes, for messages.
+func (v Variant) Describe() string {
+	switch v.Kind {
+	case MissingRequired:
+		return fmt.Sprintf("a document without the required field %s", v.Field)
+	case WrongType:
+		return fmt.Sprintf("%s of the wrong type (%v)", v.Field, v.Value)
+	case OutOfEnum:
+		return fmt.Sprintf("%s = %v, not one of %s", v.Field, v.Value, strings.Join(v.Enum, ", "))
+	case MalformedName:
+		return fmt.Sprintf("the malformed name %s = %q", v.Field, v.Value)
+	case InvalidCIDR:
+		return fmt.Sprintf("the invalid CIDR range %s = %q", v.Field, v.Value)
+	}
+	return string(v.Kind)
+}
+
+// FixtureDir returns the folder of the valid YAML fixtures of a stage in
+// the unit tests, unitDir being execution/test/unit. It mirrors the stage
+// config folder of the configuration tree, matched case insensitively.
+func FixtureDir(unitDir string, stage config.Stage) (string, bool) {
+	if stage.ConfigDir == "" {
+		return "", false
+	}
+	dir := unitDir
+	for _, part := range strings.Split(stage.ConfigDir, "/") {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return "", false
+		}
+		found := false
+		for _, e := range entries {
+			if e.IsDir() && strings.EqualFold(e.Name(), part) {
+				dir, found = filepath.Join(dir, e.Name()), true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return dir, true
+}
+
+// Generate returns the variants of the fixtures of a stage with a typed YAML
+// schema. A change to a field is made once, in the first file having the
+// field, files being read in name order.
+func Generate(stage config.Stage, fixtureDir string) ([]Variant, error) {
+	if !stage.Typed() {
+		return nil, fmt.Errorf("stage %s has no typed YAML schema", stage.Name)
+	}
+	files, err := config.ConfigFiles(fixtureDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no YAML fixture in %s", fixtureDir)
+	}
+	t := reflect.TypeOf(stage.NewDocument()).Elem()
+	var variants []Variant
+	seen := map[string]bool{}
+	for _, file := range files {
+		src, err := os.ReadFile(filepath.Join(fixtureDir, file))
+		if err != nil {
+			return nil, err
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(src, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		var changes []change
+		walk(t, doc.Content[0], nil, &changes)
+		for _, c := range changes {
+			key := string(c.kind) + " " + formatPath(c.path)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			v, err := c.apply(&doc, file)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, v)
+		}
+	}
+	return variants, nil
+}
+
+// step is an element of the path of a field: a mapping key, or a list index
+// when key is empty.
+type step struct {
+	key   string
+	index int
+}
+
+func formatPath(path []step) string {
+	var b strings.Builder
+	for _, s := range path {
+		if s.key == "" {
+			fmt.Fprintf(&b, "[%d]", s.index)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(s.key)
+	}
+	return b.String()
+}
+
+// change is a variant before it is applied to a copy of the document.
+type change struct {
+	kind  Kind
+	path  []step
+	names []string
+	value *yaml.Node
+	enum  []string
+}
+
+var flagType = reflect.TypeOf(config.Flag(false))
+
+// walk records the changes of the fields of t found in the mapping node n.
+func walk(t reflect.Type, n *yaml.Node, path []step, changes *[]change) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		value := lookup(n, name)
+		if value == nil {
+			continue
+		}
+		fieldPath := append(append([]step{}, path...), step{key: name})
+		names := []string{name}
+		if v := f.Tag.Get("var"); v != "" {
+			names = append(names, v)
+		}
+		add := func(kind Kind, value *yaml.Node, enum []string) {
+			*changes = append(*changes, change{kind: kind, path: fieldPath, names: names, value: value, enum: enum})
+		}
+
+		if !strings.Contains(opts, "omitempty") {
+			add(MissingRequired, nil, nil)
+		}
+		if f.Tag.Get("type") == "" {
+			if wrong := wrongType(f.Type); wrong != nil {
+				add(WrongType, wrong, nil)
+			}
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			add(OutOfEnum, outOfEnum(f.Type, strings.Split(enum, ",")), strings.Split(enum, ","))
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.String && value.Kind == yaml.ScalarNode {
+			if isName(name) {
+				add(MalformedName, scalar("!!str", invalidName), nil)
+			}
+			if isCIDR(value.Value) {
+				add(InvalidCIDR, scalar("!!str", invalidCIDR), nil)
+			}
+		}
+		if ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.String && value.Kind == yaml.SequenceNode {
+			for j, item := range value.Content {
+				if isCIDR(item.Value) {
+					*changes = append(*changes, change{kind: InvalidCIDR, path: append(append([]step{}, fieldPath...), step{index: j}),
+						names: names, value: scalar("!!str", invalidCIDR)})
+					break
+				}
+			}
+		}
+		if ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.String && value.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				if isCIDR(value.Content[j+1].Value) {
+					*changes = append(*changes, change{kind: InvalidCIDR, path: append(append([]step{}, fieldPath...), step{key: value.Content[j].Value}),
+						names: names, value: scalar("!!str", invalidCIDR)})
+					break
+				}
+			}
+		}
+		walkValue(ft, value, fieldPath, changes)
+	}
+}
+
+// walkValue descends into a struct, the first item of a list of structs or
+// the first entry of a map of structs.
+func walkValue(t reflect.Type, n *yaml.Node, path []step, changes *[]change) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Struct:
+		walk(t, n, path, changes)
+	case t.Kind() == reflect.Slice && n.Kind == yaml.SequenceNode && len(n.Content) > 0:
+		walkValue(t.Elem(), n.Content[0], append(append([]step{}, path...), step{index: 0}), changes)
+	case t.Kind() == reflect.Map && n.Kind == yaml.MappingNode && len(n.Content) > 1:
+		walkValue(t.Elem(), n.Content[1], append(append([]step{}, path...), step{key: n.Content[0].Value}), changes)
+	}
+}
+
+// wrongType returns a value of another type than t, nil when t accepts any
+// value.
+func wrongType(t reflect.Type) *yaml.Node {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == flagType {
+		return scalar("!!str", "not-a-bool")
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{scalar("!!str", "not-a-string")}}
+	case reflect.Bool:
+		return scalar("!!str", "not-a-bool")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return scalar("!!str", "not-a-number")
+	case reflect.Struct, reflect.Map:
+		return scalar("!!str", "not-an-object")
+	case reflect.Slice, reflect.Array:
+		return scalar("!!str", "not-a-list")
+	}
+	return nil
+}
+
+// outOfEnum returns a value of the type of the field outside enum.
+func outOfEnum(t reflect.Type, enum []string) *yaml.Node {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.String {
+		max := 0
+		for _, v := range enum {
+			if n, err := strconv.Atoi(v); err == nil && n > max {
+				max = n
+			}
+		}
+		return scalar("!!int", strconv.Itoa(max+1))
+	}
+	return scalar("!!str", invalidEnum)
+}
+
+// isName reports whether a key holds the name or ID of a resource. Display
+// names accept any text.
+func isName(key string) bool {
+	if strings.Contains(key, "display") {
+		return false
+	}
+	return key == "name" || strings.HasSuffix(key, "_name") || strings.HasSuffix(key, "_id")
+}
+
+func isCIDR(s string) bool {
+	_, err := netip.ParsePrefix(s)
+	return err == nil
+}
+
+func scalar(tag, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+// lookup returns the value of key in the mapping node n.
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// apply returns the variant of the document with the change.
+func (c change) apply(doc *yaml.Node, file string) (Variant, error) {
+	root := clone(doc)
+	parent := root.Content[0]
+	for _, s := range c.path[:len(c.path)-1] {
+		if s.key == "" {
+			parent = parent.Content[s.index]
+		} else {
+			parent = lookup(parent, s.key)
+		}
+	}
+	last := c.path[len(c.path)-1]
+	v := Variant{Kind: c.kind, File: file, Path: formatPath(c.path), Field: c.names[0], Names: c.names, Enum: c.enum}
+	switch {
+	case c.value == nil:
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == last.key {
+				parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+				break
+			}
+		}
+	case last.key == "":
+		parent.Content[last.index] = c.value
+	default:
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == last.key {
+				parent.Content[i+1] = c.value
+				break
+			}
+		}
+	}
+	if c.value != nil {
+		if err := c.value.Decode(&v.Value); err != nil {
+			return Variant{}, err
+		}
+	}
+	out, err := yaml.Marshal(root)
+	if err != nil {
+		return Variant{}, fmt.Errorf("%s: %w", file, err)
+	}
+	v.Doc = out
+	return v, nil
+}
+
+func clone(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = clone(child)
+	}
+	return &c
+}
@@ -0,0 +1,333 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package negative
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/command"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+	"gopkg.in/yaml.v3"
+)
+
+const instance = `name: sql-1
+project_id: project-1
+region: us-central1
+database_version: MYSQL_8_0
+edition: ENTERPRISE
+timezone: UTC
+network_config:
+  authorized_networks:
+    office: 203.0.113.0/24
+  connectivity:
+    psc_allowed_consumer_projects: [project-2]
+`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func cloudSQL(t *testing.T) config.Stage {
+	t.Helper()
+	stage, ok := config.LookupStage("producer/cloudsql")
+	if !ok {
+		t.Fatalf("Failed to find stage producer/cloudsql")
+	}
+	return stage
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.yaml":         instance,
+		"b.yaml":         strings.Replace(instance, "sql-1", "sql-2", 1),
+		"_ignored.yaml":  "name: [",
+		"c.yaml.example": "name: [",
+	})
+	variants, err := Generate(cloudSQL(t), dir)
+	if err != nil {
+		t.Fatalf("Failed to generate variants: %v", err)
+	}
+	var got []string
+	for _, v := range variants {
+		if v.File != "a.yaml" {
+			t.Errorf("Variant %s %s changes %s, want a.yaml only", v.Kind, v.Path, v.File)
+		}
+		got = append(got, string(v.Kind)+" "+v.Path)
+	}
+	want := []string{
+		"missing-required name", "wrong-type name", "malformed-name name",
+		"missing-required project_id", "wrong-type project_id", "malformed-name project_id",
+		"missing-required region", "wrong-type region",
+		"missing-required database_version", "wrong-type database_version",
+		"missing-required network_config", "wrong-type network_config",
+		"wrong-type network_config.authorized_networks", "invalid-cidr network_config.authorized_networks.office",
+		"missing-required network_config.connectivity", "wrong-type network_config.connectivity",
+		"wrong-type network_config.connectivity.psc_allowed_consumer_projects",
+		"wrong-type edition", "out-of-enum edition",
+		"wrong-type timezone",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate() = %q, want %q", got, want)
+	}
+
+	docs := map[string]map[string]any{}
+	for _, v := range variants {
+		var doc map[string]any
+		if err := yaml.Unmarshal(v.Doc, &doc); err != nil {
+			t.Fatalf("Failed to decode variant %s %s: %v", v.Kind, v.Path, err)
+		}
+		docs[string(v.Kind)+" "+v.Path] = doc
+		if v.Kind == WrongType && v.Field == "timezone" && !reflect.DeepEqual(v.Names, []string{"timezone", "time_zone"}) {
+			t.Errorf("Names of timezone = %q, want the field and its variable", v.Names)
+		}
+	}
+	if _, ok := docs["missing-required name"]["name"]; ok {
+		t.Errorf("Variant missing-required name still has a name")
+	}
+	if got := docs["out-of-enum edition"]["edition"]; got != invalidEnum {
+		t.Errorf("Variant out-of-enum edition has edition %v", got)
+	}
+	cidr := docs["invalid-cidr network_config.authorized_networks.office"]["network_config"].(map[string]any)["authorized_networks"]
+	if !reflect.DeepEqual(cidr, map[string]any{"office": invalidCIDR}) {
+		t.Errorf("Variant invalid-cidr has authorized_networks %v", cidr)
+	}
+	if got := docs["wrong-type name"]["project_id"]; got != "project-1" {
+		t.Errorf("Variant wrong-type name changed project_id to %v", got)
+	}
+}
+
+func TestGenerateInvalid(t *testing.T) {
+	stage, _ := config.LookupStage("producer/bigquery")
+	if _, err := Generate(stage, t.TempDir()); err == nil || err.Error() != "stage producer/bigquery has no typed YAML schema" {
+		t.Errorf("Generate() of an untyped stage returned %v", err)
+	}
+	if _, err := Generate(cloudSQL(t), t.TempDir()); err == nil || !strings.HasPrefix(err.Error(), "no YAML fixture in ") {
+		t.Errorf("Generate() of an empty folder returned %v", err)
+	}
+}
+
+func TestFixtureDir(t *testing.T) {
+	stage, _ := config.LookupStage("networking/CloudDNS/CloudDNSResponsePolicy")
+	dir, ok := FixtureDir("../../test/unit", stage)
+	if want := filepath.FromSlash("../../test/unit/networking/CloudDNS/CloudDNSResponsepolicy/config"); !ok || dir != want {
+		t.Errorf("FixtureDir() = %q, %v, want %q", dir, ok, want)
+	}
+	stage, _ = config.LookupStage("producer-connectivity")
+	if _, ok := FixtureDir("../../test/unit", stage); ok {
+		t.Errorf("FixtureDir() of a stage without config folder returned a folder")
+	}
+}
+
+// fakeTerraform plans a CloudSQL fixture folder: it rejects an unknown
+// edition or a missing name with a diagnostic naming them, fails on other
+// invalid types without naming them, and accepts the rest.
+func fakeTerraform(t *testing.T, calls *[]string) command.Runner {
+	return func(_ context.Context, stageDir, _ string, args ...string) ([]byte, error) {
+		*calls = append(*calls, args[0])
+		if args[0] == "init" {
+			if _, err := os.Stat(filepath.Join(stageDir, "../../../modules/cloudsql/main.tf")); err != nil {
+				t.Errorf("Stage copy misses its module: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(stageDir, ".terraform")); err == nil {
+				t.Errorf("Stage copy holds the .terraform directory of the stage")
+			}
+			return nil, os.WriteFile(filepath.Join(stageDir, ".terraform.lock.hcl"), nil, 0644)
+		}
+		var dir string
+		for _, a := range args {
+			if v, ok := strings.CutPrefix(a, "-var=config_folder_path="); ok {
+				dir = v
+			}
+		}
+		src, err := os.ReadFile(filepath.Join(dir, "a.yaml"))
+		if err != nil {
+			t.Errorf("Failed to read the variant: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "b.yaml")); err != nil {
+			t.Errorf("Plan folder misses a fixture: %v", err)
+		}
+		doc := string(src)
+		switch {
+		case strings.Contains(doc, invalidEnum):
+			return nil, planError("╷\n│ Error: Invalid value for variable\n│ on variables.tf line 3: var.edition\n╵\n")
+		case !strings.Contains(doc, "name: sql-1"):
+			return nil, planError("Error: Unsupported attribute\n  instance.name\n")
+		case strings.Contains(doc, "not-a"):
+			return nil, planError("Error: Invalid function argument\n")
+		}
+		return []byte("Plan: 1 to add, 0 to change, 0 to destroy.\n"), nil
+	}
+}
+
+// planError is the error of a terraform command printing stderr.
+func planError(stderr string) error {
+	return &command.Error{Name: "terraform", Err: errors.New("exit status 1"), Stderr: stderr}
+}
+
+func TestRun(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.yaml": instance, "b.yaml": "name: sql-2\n"})
+	variants, err := Generate(cloudSQL(t), dir)
+	if err != nil {
+		t.Fatalf("Failed to generate variants: %v", err)
+	}
+	repo := writeFiles(t, map[string]string{
+		"execution/04-producer/CloudSQL/main.tf":              "module \"sql\" {\n  source = \"../../../modules/cloudsql\"\n}\n",
+		"execution/04-producer/CloudSQL/.terraform/plan.json": "{}",
+		"modules/cloudsql/main.tf":                            "",
+	})
+	stageDir := filepath.Join(repo, "execution", "04-producer", "CloudSQL")
+	var calls []string
+	results, err := Run(context.Background(), Options{StageDir: stageDir, FixtureDir: dir, Run: fakeTerraform(t, &calls)}, variants)
+	if err != nil {
+		t.Fatalf("Failed to run variants: %v", err)
+	}
+	if len(calls) != len(variants)+1 || calls[0] != "init" {
+		t.Errorf("Run() ran terraform %q, want init then one plan per variant", calls)
+	}
+	if _, err := os.Stat(filepath.Join(stageDir, ".terraform.lock.hcl")); err == nil {
+		t.Errorf("Run() initialized the stage directory rather than a copy")
+	}
+	outcomes := map[string]Result{}
+	for _, r := range results {
+		outcomes[string(r.Kind)+" "+r.Path] = r
+	}
+	tests := []struct {
+		variant    string
+		outcome    Outcome
+		diagnostic string
+	}{
+		{"out-of-enum edition", Rejected, "Error: Invalid value for variable"},
+		{"missing-required name", Rejected, "Error: Unsupported attribute"},
+		{"wrong-type edition", RejectedElsewhere, "Error: Invalid function argument"},
+		{"malformed-name project_id", Accepted, ""},
+		{"invalid-cidr network_config.authorized_networks.office", Accepted, ""},
+	}
+	for _, tc := range tests {
+		r := outcomes[tc.variant]
+		if r.Outcome != tc.outcome || r.Diagnostic != tc.diagnostic {
+			t.Errorf("Outcome of %s = %s %q, want %s %q", tc.variant, r.Outcome, r.Diagnostic, tc.outcome, tc.diagnostic)
+		}
+	}
+
+	failing := func(context.Context, string, string, ...string) ([]byte, error) {
+		return nil, planError("Error: Failed to query available provider packages\n")
+	}
+	if _, err := Run(context.Background(), Options{StageDir: stageDir, FixtureDir: dir, Run: failing}, variants); err == nil ||
+		err.Error() != "terraform init of "+stageDir+": Error: Failed to query available provider packages" {
+		t.Errorf("Run() with a failing init returned %v", err)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		out  string
+		want bool
+	}{
+		{"var.name is required", true},
+		{`attribute "name"`, true},
+		{"name", true},
+		{"var.project_name is required", false},
+		{"instance.names", false},
+		{"var.time_zone is invalid", true},
+	}
+	for _, tc := range tests {
+		if got := mentions(tc.out, []string{"name", "time_zone"}); got != tc.want {
+			t.Errorf("mentions(%q) = %v, want %v", tc.out, got, tc.want)
+		}
+	}
+}
+
+func TestFindings(t *testing.T) {
+	stageDir := writeFiles(t, map[string]string{
+		"stage/variables.tf": `
+variable "edition" {
+  type = string
+  validation {
+    condition     = contains(["ENTERPRISE", "ENTERPRISE_PLUS"], var.edition)
+    error_message = "Invalid edition."
+  }
+}
+variable "time_zone" {
+  type = string
+}
+`,
+		"stage/main.tf": `
+module "sql" {
+  source = "../modules/sql"
+}
+module "remote" {
+  source = "github.com/example/modules//sql"
+}
+`,
+		"modules/sql/variables.tf": `
+variable "edition" {}
+variable "unused" {}
+`,
+	})
+	vars, err := Variables(filepath.Join(stageDir, "stage"))
+	if err != nil {
+		t.Fatalf("Failed to read variables: %v", err)
+	}
+	var names []string
+	for _, v := range vars {
+		names = append(names, v.Name+"/"+filepath.Base(filepath.Dir(v.File))+"/"+string(rune('0'+v.Validations)))
+	}
+	if want := []string{"edition/sql/0", "edition/stage/1", "time_zone/stage/0", "unused/sql/0"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Variables() = %q, want %q", names, want)
+	}
+
+	results := []Result{
+		{Variant: Variant{Kind: OutOfEnum, File: "a.yaml", Path: "edition", Field: "edition", Names: []string{"edition"}, Value: "X", Enum: []string{"A"}}, Outcome: Accepted},
+		{Variant: Variant{Kind: WrongType, File: "a.yaml", Path: "timezone", Field: "timezone", Names: []string{"timezone", "time_zone"}, Value: "[x]"}, Outcome: RejectedElsewhere, Diagnostic: "Error: Invalid function argument"},
+		{Variant: Variant{Kind: MalformedName, File: "a.yaml", Path: "name", Field: "name", Names: []string{"name"}, Value: "X!"}, Outcome: Accepted},
+		{Variant: Variant{Kind: MissingRequired, File: "a.yaml", Path: "region", Field: "region", Names: []string{"region"}}, Outcome: Rejected},
+	}
+	findings := Findings(cloudSQL(t), "producer/CloudSQL/config", results, vars)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Severity.String()+" "+f.Check+" "+f.Resource+": "+strings.ReplaceAll(f.Message, stageDir, "TMP"))
+	}
+	want := []string{
+		`error out-of-enum edition: plan of 04-producer/CloudSQL accepts edition = X, not one of A; no validation block on edition (TMP/modules/sql/variables.tf); the validation of edition (TMP/stage/variables.tf) does not reject it`,
+		`warning wrong-type timezone: plan of 04-producer/CloudSQL rejects timezone of the wrong type ([x]), but no diagnostic mentions timezone: Error: Invalid function argument`,
+		`error malformed-name name: plan of 04-producer/CloudSQL accepts the malformed name name = "X!"; no variable of the stage or of its modules is named after the field`,
+		`info no-validation variable edition: variable has no validation block`,
+		`info no-validation variable time_zone: variable has no validation block`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Findings() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if findings[0].File != "producer/CloudSQL/config/a.yaml" || findings[0].Severity != report.Error {
+		t.Errorf("Finding of an accepted variant = %+v", findings[0])
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package negative
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/command"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// Outcome is the result of the plan of a variant.
+type Outcome string
+
+// Outcomes of a plan.
+const (
+	// Rejected is a failed plan with a diagnostic mentioning the field.
+	Rejected Outcome = "rejected"
+	// RejectedElsewhere is a failed plan whose diagnostics do not mention
+	// the field, which may fail for another reason.
+	RejectedElsewhere Outcome = "rejected-elsewhere"
+	// Accepted is a successful plan.
+	Accepted Outcome = "accepted"
+)
+
+// Result is the outcome of the plan of a variant.
+type Result struct {
+	Variant
+	Outcome Outcome `json:"outcome"`
+	// Diagnostic is the summary line of the first error of a failed plan.
+	Diagnostic string `json:"diagnostic,omitempty"`
+}
+
+// Options configure Run.
+type Options struct {
+	// StageDir is the directory of the stage, FixtureDir the folder of its
+	// valid YAML fixtures.
+	StageDir   string
+	FixtureDir string
+	// Vars are variables passed to every plan besides config_folder_path.
+	Vars map[string]string
+	// Run runs terraform, command.Exec when nil.
+	Run command.Runner
+}
+
+// Run initializes a temporary copy of the stage and plans it once per
+// variant, with a copy of the fixture folder holding the variant. The stage
+// directory is left untouched, so that Run does not share its .terraform
+// directory and lock file with run.sh.
+func Run(ctx context.Context, opts Options, variants []Variant) ([]Result, error) {
+	run := opts.Run
+	if run == nil {
+		run = command.Exec
+	}
+	tmp, err := os.MkdirTemp("", "negative-stage-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	stageDir, err := copyStage(opts.StageDir, tmp)
+	if err != nil {
+		return nil, fmt.Errorf("copy of %s: %w", opts.StageDir, err)
+	}
+	if out, err := run(ctx, stageDir, "terraform", "init", "-input=false", "-no-color"); err != nil {
+		return nil, fmt.Errorf("terraform init of %s: %s", opts.StageDir, diagnostic(command.Output(out, err)))
+	}
+	files, err := config.ConfigFiles(opts.FixtureDir)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, v := range variants {
+		r, err := plan(ctx, run, stageDir, opts, files, v)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func plan(ctx context.Context, run command.Runner, stageDir string, opts Options, files []string, v Variant) (Result, error) {
+	dir, err := os.MkdirTemp("", "negative-")
+	if err != nil {
+		return Result{}, err
+	}
+	defer os.RemoveAll(dir)
+	for _, f := range files {
+		src := v.Doc
+		if f != v.File {
+			if src, err = os.ReadFile(filepath.Join(opts.FixtureDir, f)); err != nil {
+				return Result{}, err
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), src, 0644); err != nil {
+			return Result{}, err
+		}
+	}
+
+	args := []string{"plan", "-input=false", "-no-color", "-lock=false", "-refresh=false", "-var=config_folder_path=" + dir}
+	names := make([]string, 0, len(opts.Vars))
+	for name := range opts.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("-var=%s=%s", name, opts.Vars[name]))
+	}
+	out, err := run(ctx, stageDir, "terraform", args...)
+	if ctx.Err() != nil {
+		return Result{}, ctx.Err()
+	}
+	r := Result{Variant: v, Outcome: Accepted}
+	if err != nil {
+		output := command.Output(out, err)
+		r.Outcome, r.Diagnostic = RejectedElsewhere, diagnostic(output)
+		if mentions(output, v.Names) {
+			r.Outcome = Rejected
+		}
+	}
+	return r, nil
+}
+
+// diagnostic returns the summary line of the first error of a Terraform
+// output, or its last line.
+func diagnostic(out string) string {
+	last := ""
+	s := bufio.NewScanner(strings.NewReader(out))
+	for s.Scan() {
+		line := strings.TrimSpace(strings.TrimLeft(s.Text(), "│╷╵ "))
+		if strings.HasPrefix(line, "Error: ") {
+			return line
+		}
+		if line != "" {
+			last = line
+		}
+	}
+	return last
+}
+
+// mentions reports whether the output names one of names as a word, so that
+// a diagnostic about name does not count for project_name.
+func mentions(out string, names []string) bool {
+	isWord := func(b byte) bool {
+		return b == '_' || b == '-' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+	}
+	for _, name := range names {
+		for i := 0; ; {
+			j := strings.Index(out[i:], name)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(name)
+			if (start == 0 || !isWord(out[start-1])) && (end == len(out) || !isWord(out[end])) {
+				return true
+			}
+			i = end
+		}
+	}
+	return false
+}
+
+// Findings reports the variants whose plan succeeded, as errors naming the
+// variables which could validate the field, and the variants whose plan
+// failed without mentioning the field, as warnings. Every variable a
+// variant exercises which has no validation block is reported as info.
+// dir is the fixture folder as reported, e.g. producer/CloudSQL/config.
+func Findings(stage config.Stage, dir string, results []Result, vars []Variable) []report.Finding {
+	var findings []report.Finding
+	unvalidated := map[string]Variable{}
+	for _, r := range results {
+		matched := lookupVariables(vars, r.Names)
+		for _, v := range matched {
+			if v.Validations == 0 {
+				unvalidated[v.Name+" "+v.File] = v
+			}
+		}
+		f := report.Finding{Check: string(r.Kind), File: dir + "/" + r.File, Resource: r.Path}
+		switch r.Outcome {
+		case Accepted:
+			f.Severity = report.Error
+			f.Message = fmt.Sprintf("plan of %s accepts %s; %s", stage.Path, r.Describe(), coverage(matched))
+		case RejectedElsewhere:
+			f.Severity = report.Warning
+			f.Message = fmt.Sprintf("plan of %s rejects %s, but no diagnostic mentions %s: %s", stage.Path, r.Describe(), r.Field, r.Diagnostic)
+		default:
+			continue
+		}
+		findings = append(findings, f)
+	}
+	for _, v := range vars {
+		if _, ok := unvalidated[v.Name+" "+v.File]; ok {
+			findings = append(findings, report.Finding{
+				Severity: report.Info,
+				Check:    "no-validation",
+				File:     v.File,
+				Resource: "variable " + v.Name,
+				Message:  "variable has no validation block",
+			})
+		}
+	}
+	return findings
+}
+
+// coverage describes the variables of a field for the message of an
+// accepted variant.
+func coverage(vars []Variable) string {
+	if len(vars) == 0 {
+		return "no variable of the stage or of its modules is named after the field"
+	}
+	var none, some []string
+	for _, v := range vars {
+		desc := fmt.Sprintf("%s (%s)", v.Name, v.File)
+		if v.Validations == 0 {
+			none = append(none, desc)
+		} else {
+			some = append(some, desc)
+		}
+	}
+	var parts []string
+	if len(none) > 0 {
+		parts = append(parts, "no validation block on "+strings.Join(none, ", "))
+	}
+	if len(some) > 0 {
+		parts = append(parts, "the validation of "+strings.Join(some, ", ")+" does not reject it")
+	}
+	return strings.Join(parts, "; ")
+}
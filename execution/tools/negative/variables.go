@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package negative
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// Variable is a Terraform variable of a stage or of a local module it calls.
+type Variable struct {
+	Name string
+	// File is the file declaring the variable.
+	File string
+	// Validations is the number of validation blocks of the variable.
+	Validations int
+}
+
+var (
+	fileSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "variable", LabelNames: []string{"name"}},
+			{Type: "module", LabelNames: []string{"name"}},
+		},
+	}
+	variableSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "validation"}},
+	}
+	moduleSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "source"}},
+	}
+)
+
+// Variables returns the variables of the Terraform files of a stage, and of
+// the local modules it calls, e.g. ../../modules/net-vpc, sorted by name
+// and file.
+func Variables(stageDir string) ([]Variable, error) {
+	var vars []Variable
+	seen := map[string]bool{}
+	var read func(dir string, follow bool) error
+	read = func(dir string, follow bool) error {
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			return nil
+		}
+		seen[dir] = true
+		files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+		if err != nil {
+			return err
+		}
+		parser := hclparse.NewParser()
+		for _, file := range files {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			f, diags := parser.ParseHCL(src, file)
+			if diags.HasErrors() {
+				return diags
+			}
+			content, _, diags := f.Body.PartialContent(fileSchema)
+			if diags.HasErrors() {
+				return diags
+			}
+			for _, block := range content.Blocks {
+				switch block.Type {
+				case "variable":
+					body, _, _ := block.Body.PartialContent(variableSchema)
+					vars = append(vars, Variable{Name: block.Labels[0], File: file, Validations: len(body.Blocks)})
+				case "module":
+					if !follow {
+						continue
+					}
+					body, _, _ := block.Body.PartialContent(moduleSchema)
+					attr, ok := body.Attributes["source"]
+					if !ok {
+						continue
+					}
+					v, diags := attr.Expr.Value(nil)
+					if diags.HasErrors() || v.IsNull() {
+						continue
+					}
+					if source := v.AsString(); strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+						// Modules called by these modules are not read.
+						if err := read(filepath.Join(dir, filepath.FromSlash(source)), false); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if err := read(stageDir, true); err != nil {
+		return nil, err
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		if vars[i].Name != vars[j].Name {
+			return vars[i].Name < vars[j].Name
+		}
+		return vars[i].File < vars[j].File
+	})
+	return vars, nil
+}
+
+// lookupVariables returns the variables named after one of names.
+func lookupVariables(vars []Variable, names []string) []Variable {
+	var out []Variable
+	for _, v := range vars {
+		for _, name := range names {
+			if v.Name == name {
+				out = append(out, v)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+// newEnv declares the variables of the rule expressions: resource, the
+// change of a resource (address, module, type, name, provider, actions,
+// before, after and after_unknown), and params, the policy parameters. The
+// optional field syntax, resource.after.?labels.orValue({}), and the string
+// extensions are enabled.
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("params", cel.MapType(cel.StringType, cel.StringType)),
+		cel.OptionalTypes(),
+		ext.Strings(),
+	)
+}
+
+func compile(env *cel.Env, expr string) (cel.Program, error) {
+	ast, iss := env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, fmt.Errorf("expression is of type %s, not bool", t)
+	}
+	return env.Program(ast)
+}
+
+// eval evaluates a compiled expression to a boolean.
+func eval(prg cel.Program, vars map[string]any) (bool, error) {
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %v, not a bool", out)
+	}
+	return b, nil
+}
+
+// Parameters returns the default parameters of the policy overridden by
+// params. It fails on a parameter no rule file declares.
+func (p *Policy) Parameters(params map[string]string) (map[string]string, error) {
+	out := make(map[string]string, len(p.Params))
+	for name, value := range p.Params {
+		out[name] = value
+	}
+	var unknown []string
+	for name, value := range params {
+		if _, ok := p.Params[name]; !ok {
+			unknown = append(unknown, name)
+		}
+		out[name] = value
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown parameter(s) %v, the rule files declare %v", unknown, p.names())
+	}
+	return out, nil
+}
+
+func (p *Policy) names() []string {
+	var out []string
+	for name := range p.Params {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Evaluate checks every resource change of the plan against the rules, and
+// returns a finding per violation, with the resource address. A rule which
+// fails to evaluate for a change, e.g. reading an attribute the change does
+// not have, is reported as a violation too.
+func (p *Policy) Evaluate(plan *tfplan.Plan, params map[string]string) ([]report.Finding, error) {
+	values, err := p.Parameters(params)
+	if err != nil {
+		return nil, err
+	}
+	var findings []report.Finding
+	for _, c := range plan.ResourceChanges {
+		vars := map[string]any{"resource": resource(c), "params": values}
+		for _, r := range p.Rules {
+			if !r.applies(c) {
+				continue
+			}
+			msg, ok := r.check(vars)
+			if ok {
+				continue
+			}
+			findings = append(findings, report.Finding{
+				Severity: r.Severity,
+				Check:    r.ID,
+				Resource: c.Address,
+				Message:  msg,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// resource returns the resource variable of the expressions for a change.
+func resource(c tfplan.ResourceChange) map[string]any {
+	actions := make([]any, len(c.Change.Actions))
+	for i, a := range c.Change.Actions {
+		actions[i] = a
+	}
+	return map[string]any{
+		"address":       c.Address,
+		"module":        c.ModuleAddress,
+		"type":          c.Type,
+		"name":          c.Name,
+		"provider":      c.ProviderName,
+		"actions":       actions,
+		"before":        c.Change.Before,
+		"after":         c.Change.After,
+		"after_unknown": c.Change.AfterUnknown,
+	}
+}
+
+// check evaluates the rule for a change, and returns whether the change
+// satisfies it, or the violation message.
+func (r *Rule) check(vars map[string]any) (string, bool) {
+	if r.when != nil {
+		selected, err := eval(r.when, vars)
+		if err != nil {
+			return fmt.Sprintf("cannot evaluate when: %v", err), false
+		}
+		if !selected {
+			return "", true
+		}
+	}
+	ok, err := eval(r.assert, vars)
+	if err != nil {
+		return fmt.Sprintf("cannot evaluate assert: %v", err), false
+	}
+	return r.message(), ok
+}
@@ -0,0 +1,245 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy checks the resource changes of a Terraform plan against
+// organization guardrails, rules written in YAML whose conditions are CEL
+// expressions, before the plan is applied.
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+// DefaultActions are the plan actions a rule applies to when it lists none:
+// the resources the plan creates, updates or replaces.
+var DefaultActions = []string{"create", "update"}
+
+// Rule is a guardrail a planned resource change must satisfy.
+type Rule struct {
+	ID          string
+	Description string
+	Severity    report.Severity
+	// ResourceTypes are the resource types the rule applies to, as path.Match
+	// patterns, e.g. google_compute_*. Every managed resource when empty.
+	ResourceTypes []string
+	// Actions are the plan actions the rule applies to, DefaultActions when
+	// empty. A change applies when one of its actions is listed.
+	Actions []string
+	// When is an optional CEL expression selecting the changes the rule
+	// applies to, and Assert the CEL expression they must satisfy.
+	When   string
+	Assert string
+	// Message describes a violation, the description when empty.
+	Message string
+	// File is the rule file the rule is read from.
+	File string
+
+	when, assert cel.Program
+}
+
+// Policy is a set of rules and the parameters they read, with their default
+// values.
+type Policy struct {
+	Rules  []*Rule
+	Params map[string]string
+}
+
+// ruleFile is the YAML layout of a rule file.
+type ruleFile struct {
+	Params map[string]string `yaml:"params"`
+	Rules  []struct {
+		ID            string   `yaml:"id"`
+		Description   string   `yaml:"description"`
+		Severity      string   `yaml:"severity"`
+		ResourceTypes []string `yaml:"resource_types"`
+		Actions       []string `yaml:"actions"`
+		When          string   `yaml:"when"`
+		Assert        string   `yaml:"assert"`
+		Message       string   `yaml:"message"`
+	} `yaml:"rules"`
+}
+
+var planActions = []string{"create", "read", "update", "delete", "no-op"}
+
+// Load reads the rule files at paths, or the .yaml files of the directories
+// at paths, and compiles their expressions. A rule file declares the
+// parameters its rules read and their default values, and the rules:
+//
+//	params:
+//	  environment: ""
+//	rules:
+//	  - id: prod-deletion-protection
+//	    description: Production databases are protected against deletion.
+//	    severity: error
+//	    resource_types: [google_sql_database_instance]
+//	    when: params.environment == "prod"
+//	    assert: resource.after.deletion_protection == true
+func Load(paths ...string) (*Policy, error) {
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{Params: map[string]string{}}
+	ids := map[string]string{}
+	for _, file := range ruleFiles(paths) {
+		if err := p.load(env, file, ids); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Rules) == 0 {
+		return nil, fmt.Errorf("no rules in %s", strings.Join(paths, ", "))
+	}
+	return p, nil
+}
+
+// ruleFiles expands the directories of paths to their sorted .yaml files.
+func ruleFiles(paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if info, err := os.Stat(p); err != nil || !info.IsDir() {
+			out = append(out, p)
+			continue
+		}
+		var files []string
+		for _, ext := range []string{"*.yaml", "*.yml"} {
+			matches, _ := filepath.Glob(filepath.Join(p, ext))
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+		out = append(out, files...)
+	}
+	return out
+}
+
+func (p *Policy) load(env *cel.Env, file string, ids map[string]string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	var rf ruleFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rf); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	for name, value := range rf.Params {
+		if prev, ok := p.Params[name]; ok && prev != value {
+			return fmt.Errorf("%s: parameter %s defaults to %q, and to %q in another file", file, name, value, prev)
+		}
+		p.Params[name] = value
+	}
+	for i, r := range rf.Rules {
+		switch {
+		case r.ID == "":
+			return fmt.Errorf("%s: rule %d: id is required", file, i+1)
+		case ids[r.ID] != "":
+			return fmt.Errorf("%s: rule %s: already defined in %s", file, r.ID, ids[r.ID])
+		case r.Assert == "":
+			return fmt.Errorf("%s: rule %s: assert is required", file, r.ID)
+		}
+		ids[r.ID] = file
+		rule := &Rule{
+			ID:            r.ID,
+			Description:   r.Description,
+			Severity:      report.Error,
+			ResourceTypes: r.ResourceTypes,
+			Actions:       r.Actions,
+			When:          r.When,
+			Assert:        r.Assert,
+			Message:       r.Message,
+			File:          file,
+		}
+		if r.Severity != "" {
+			if rule.Severity, err = report.ParseSeverity(r.Severity); err != nil {
+				return fmt.Errorf("%s: rule %s: %w", file, r.ID, err)
+			}
+		}
+		for _, a := range rule.Actions {
+			if !slices.Contains(planActions, a) {
+				return fmt.Errorf("%s: rule %s: unknown action %q, expected one of %s", file, r.ID, a, strings.Join(planActions, ", "))
+			}
+		}
+		for _, t := range rule.ResourceTypes {
+			if _, err := path.Match(t, ""); err != nil {
+				return fmt.Errorf("%s: rule %s: resource type %q: %w", file, r.ID, t, err)
+			}
+		}
+		if r.When != "" {
+			if rule.when, err = compile(env, r.When); err != nil {
+				return fmt.Errorf("%s: rule %s: when: %w", file, r.ID, err)
+			}
+		}
+		if rule.assert, err = compile(env, r.Assert); err != nil {
+			return fmt.Errorf("%s: rule %s: assert: %w", file, r.ID, err)
+		}
+		p.Rules = append(p.Rules, rule)
+	}
+	return nil
+}
+
+// Select keeps the rules of the given ids, in their order of the policy.
+func (p *Policy) Select(ids []string) error {
+	var rules []*Rule
+	for _, id := range ids {
+		if !slices.ContainsFunc(p.Rules, func(r *Rule) bool { return r.ID == id }) {
+			return fmt.Errorf("unknown rule %q", id)
+		}
+	}
+	for _, r := range p.Rules {
+		if slices.Contains(ids, r.ID) {
+			rules = append(rules, r)
+		}
+	}
+	p.Rules = rules
+	return nil
+}
+
+// applies reports whether the rule applies to the resource type and actions
+// of a change.
+func (r *Rule) applies(c tfplan.ResourceChange) bool {
+	if !c.Managed() {
+		return false
+	}
+	if len(r.ResourceTypes) > 0 && !slices.ContainsFunc(r.ResourceTypes, func(t string) bool {
+		ok, _ := path.Match(t, c.Type)
+		return ok
+	}) {
+		return false
+	}
+	actions := r.Actions
+	if len(actions) == 0 {
+		actions = DefaultActions
+	}
+	return c.Has(actions...)
+}
+
+func (r *Rule) message() string {
+	if r.Message != "" {
+		return r.Message
+	}
+	return r.Description
+}
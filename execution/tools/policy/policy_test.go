@@ -0,0 +1,295 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+const (
+	sqlPublic  = `module.cloudsql["sql-public"].google_sql_database_instance.primary`
+	sqlPrivate = `module.cloudsql["sql-private"].google_sql_database_instance.primary`
+	vm         = `module.vm["vm-1"].google_compute_instance.default[0]`
+	vmLabels   = "google_compute_instance.unknown_labels"
+)
+
+func readPlan(t *testing.T) *tfplan.Plan {
+	t.Helper()
+	plan, err := tfplan.ReadFile("testdata/plan.json")
+	if err != nil {
+		t.Fatalf("Failed to read the plan: %v", err)
+	}
+	return plan
+}
+
+// violations returns the findings as "check resource" strings.
+func violations(findings []report.Finding) []string {
+	var out []string
+	for _, f := range findings {
+		out = append(out, f.Check+" "+f.Resource)
+	}
+	return out
+}
+
+func TestLoad(t *testing.T) {
+	p, err := Load("testdata/rules")
+	if err != nil {
+		t.Fatalf("Failed to load the rules: %v", err)
+	}
+	var ids []string
+	for _, r := range p.Rules {
+		ids = append(ids, r.ID)
+	}
+	want := []string{"data-sources", "no-delete", "prod-only", "missing-attribute"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("Failed to load the rules: got %q, want %q", ids, want)
+	}
+	if r := p.Rules[2]; r.Severity != report.Error || r.File != filepath.Join("testdata", "rules", "rules.yaml") {
+		t.Errorf("Failed to default the severity: got %v in %s", r.Severity, r.File)
+	}
+	if !reflect.DeepEqual(p.Params, map[string]string{"environment": "dev"}) {
+		t.Errorf("Failed to load the parameters: got %v", p.Params)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "no rules",
+			files: map[string]string{"a.yaml": "params: {}\n"},
+			want:  "no rules",
+		},
+		{
+			name:  "unknown field",
+			files: map[string]string{"a.yaml": "rules:\n  - id: a\n    assert: 'true'\n    condition: 'true'\n"},
+			want:  "field condition not found",
+		},
+		{
+			name:  "missing id",
+			files: map[string]string{"a.yaml": "rules:\n  - assert: 'true'\n"},
+			want:  "rule 1: id is required",
+		},
+		{
+			name:  "missing assert",
+			files: map[string]string{"a.yaml": "rules:\n  - id: a\n"},
+			want:  "rule a: assert is required",
+		},
+		{
+			name: "duplicate id",
+			files: map[string]string{
+				"a.yaml": "rules:\n  - id: a\n    assert: 'true'\n",
+				"b.yaml": "rules:\n  - id: a\n    assert: 'true'\n",
+			},
+			want: "rule a: already defined in",
+		},
+		{
+			name: "conflicting parameter",
+			files: map[string]string{
+				"a.yaml": "params: {environment: dev}\nrules:\n  - id: a\n    assert: 'true'\n",
+				"b.yaml": "params: {environment: prod}\nrules:\n  - id: b\n    assert: 'true'\n",
+			},
+			want: `parameter environment defaults to "prod", and to "dev" in another file`,
+		},
+		{
+			name:  "unknown severity",
+			files: map[string]string{"a.yaml": "rules:\n  - id: a\n    severity: fatal\n    assert: 'true'\n"},
+			want:  `unknown severity "fatal"`,
+		},
+		{
+			name:  "unknown action",
+			files: map[string]string{"a.yaml": "rules:\n  - id: a\n    actions: [replace]\n    assert: 'true'\n"},
+			want:  `unknown action "replace"`,
+		},
+		{
+			name:  "syntax error",
+			files: map[string]string{"a.yaml": "rules:\n  - id: a\n    assert: resource.after ==\n"},
+			want:  "rule a: assert: ERROR",
+		},
+		{
+			name:  "undeclared variable",
+			files: map[string]string{"a.yaml": "rules:\n  - id: a\n    when: plan.valid\n    assert: 'true'\n"},
+			want:  "rule a: when: ERROR",
+		},
+		{
+			name:  "not a bool",
+			files: map[string]string{"a.yaml": "rules:\n  - id: a\n    assert: params.environment\n"},
+			want:  "expression is of type string, not bool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", name, err)
+				}
+			}
+			_, err := Load(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Failed to reject the rules: got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	plan := readPlan(t)
+	p, err := Load("testdata/rules")
+	if err != nil {
+		t.Fatalf("Failed to load the rules: %v", err)
+	}
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []string
+	}{
+		{
+			name: "dev",
+			want: []string{
+				"no-delete module.alloydb.google_alloydb_cluster.default",
+				"missing-attribute " + vm,
+				"missing-attribute " + vmLabels,
+			},
+		},
+		{
+			name:   "prod",
+			params: map[string]string{"environment": "prod"},
+			want: []string{
+				"prod-only " + sqlPublic,
+				"no-delete module.alloydb.google_alloydb_cluster.default",
+				"prod-only " + vm,
+				"missing-attribute " + vm,
+				"missing-attribute " + vmLabels,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := p.Evaluate(plan, tt.params)
+			if err != nil {
+				t.Fatalf("Failed to evaluate the plan: %v", err)
+			}
+			if got := violations(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Failed to evaluate the plan: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	findings, err := p.Evaluate(plan, nil)
+	if err != nil {
+		t.Fatalf("Failed to evaluate the plan: %v", err)
+	}
+	if f := findings[0]; f.Severity != report.Warning || f.Message != "Nothing is deleted." {
+		t.Errorf("Failed to report the description: got %+v", f)
+	}
+	if f := findings[1]; f.Severity != report.Info || !strings.HasPrefix(f.Message, "cannot evaluate assert: no such key: machine_type") {
+		t.Errorf("Failed to report the evaluation error: got %+v", f)
+	}
+
+	if _, err := p.Evaluate(plan, map[string]string{"env": "prod"}); err == nil || !strings.Contains(err.Error(), "unknown parameter(s) [env]") {
+		t.Errorf("Failed to reject an unknown parameter: got %v", err)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	p, err := Load("testdata/rules")
+	if err != nil {
+		t.Fatalf("Failed to load the rules: %v", err)
+	}
+	if err := p.Select([]string{"prod-only", "no-delete"}); err != nil {
+		t.Fatalf("Failed to select the rules: %v", err)
+	}
+	if len(p.Rules) != 2 || p.Rules[0].ID != "no-delete" || p.Rules[1].ID != "prod-only" {
+		t.Errorf("Failed to select the rules: got %d rules", len(p.Rules))
+	}
+	if err := p.Select([]string{"unknown"}); err == nil {
+		t.Error("Failed to reject an unknown rule")
+	}
+}
+
+// TestRulePack checks the starter rule pack shipped with the tools.
+func TestRulePack(t *testing.T) {
+	plan := readPlan(t)
+	p, err := Load("rules")
+	if err != nil {
+		t.Fatalf("Failed to load the rule pack: %v", err)
+	}
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   []string
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"cloudsql-no-public-ipv4 " + sqlPublic,
+				"cloudsql-cost-center-label " + sqlPrivate,
+				"consumer-gce-no-external-ip " + vm,
+				"cost-center-label " + vmLabels,
+			},
+		},
+		{
+			name:   "prod networking stage",
+			params: map[string]string{"environment": "prod", "stage": "02-networking"},
+			want: []string{
+				"prod-deletion-protection " + sqlPublic,
+				"cloudsql-no-public-ipv4 " + sqlPublic,
+				"cloudsql-cost-center-label " + sqlPrivate,
+				"prod-no-database-delete module.alloydb.google_alloydb_cluster.default",
+				"prod-deletion-protection " + vm,
+				"cost-center-label " + vmLabels,
+			},
+		},
+		{
+			name:   "other label",
+			params: map[string]string{"cost_label": "team", "stage": "06-consumer/GCE"},
+			want: []string{
+				"cloudsql-cost-center-label " + sqlPublic,
+				"cloudsql-no-public-ipv4 " + sqlPublic,
+				"cloudsql-cost-center-label " + sqlPrivate,
+				"cost-center-label " + vm,
+				"consumer-gce-no-external-ip " + vm,
+				"cost-center-label " + vmLabels,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := p.Evaluate(plan, tt.params)
+			if err != nil {
+				t.Fatalf("Failed to evaluate the plan: %v", err)
+			}
+			if got := violations(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Failed to evaluate the rule pack: got %q, want %q", got, tt.want)
+			}
+			for _, f := range findings {
+				if strings.HasPrefix(f.Message, "cannot evaluate") {
+					t.Errorf("Failed to evaluate %s for %s: %s", f.Check, f.Resource, f.Message)
+				}
+			}
+		})
+	}
+}
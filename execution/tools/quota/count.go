@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+// Key identifies a quota metric of a project in a region, or Global.
+type Key struct {
+	Project string
+	Region  string
+	Metric  string
+}
+
+func (k Key) String() string {
+	return k.Project + "/" + k.Region + "/" + k.Metric
+}
+
+// metric returns the quota metric a planned resource counts against, and
+// whether the quota is project wide.
+type metric func(after any) (name string, global bool)
+
+// metrics are the quota metrics of the resource types counted against a
+// quota.
+var metrics = map[string]metric{
+	"google_compute_firewall":   fixed("FIREWALLS", true),
+	"google_compute_network":    fixed("NETWORKS", true),
+	"google_compute_subnetwork": fixed("SUBNETWORKS", true),
+	"google_compute_route":      fixed("ROUTES", true),
+	"google_compute_vpn_tunnel": fixed("VPN_TUNNELS", false),
+	"google_compute_forwarding_rule": func(after any) (string, bool) {
+		// A PSC endpoint targets the service attachment of a producer.
+		if target, _ := tfplan.String(after, "target"); strings.Contains(target, "/serviceAttachments/") {
+			return "PSC_INTERNAL_LB_FORWARDING_RULES", false
+		}
+		return "FORWARDING_RULES", false
+	},
+	"google_compute_global_forwarding_rule": fixed("FORWARDING_RULES", true),
+	"google_compute_address": func(after any) (string, bool) {
+		if t, _ := tfplan.String(after, "address_type"); t == "INTERNAL" {
+			return "INTERNAL_ADDRESSES", false
+		}
+		return "STATIC_ADDRESSES", false
+	},
+	"google_compute_global_address": func(after any) (string, bool) {
+		if t, _ := tfplan.String(after, "address_type"); t == "INTERNAL" {
+			return "GLOBAL_INTERNAL_ADDRESSES", true
+		}
+		return "STATIC_ADDRESSES", true
+	},
+}
+
+func fixed(name string, global bool) metric {
+	return func(any) (string, bool) { return name, global }
+}
+
+// Types returns the resource types counted against a quota, sorted.
+func Types() []string {
+	var out []string
+	for t := range metrics {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Planned counts the resources stage plans create per quota.
+type Planned struct {
+	// Project is the project of the resources whose plan has no project,
+	// such as the ones using the provider default project.
+	Project   string
+	resources map[Key][]string
+}
+
+// Add counts the resources a plan creates. A replaced resource is not
+// counted, it does not add to the usage once applied. Addresses are prefixed
+// with stage, when not empty.
+func (p *Planned) Add(stage string, plan *tfplan.Plan) error {
+	if p.resources == nil {
+		p.resources = map[Key][]string{}
+	}
+	for _, c := range plan.ResourceChanges {
+		m, ok := metrics[c.Type]
+		if !ok || !c.Managed() || !c.Has("create") || c.Has("delete") {
+			continue
+		}
+		address := c.Address
+		if stage != "" {
+			address = stage + ": " + address
+		}
+		after := c.Change.After
+		name, global := m(after)
+		k := Key{Project: p.Project, Region: Global, Metric: name}
+		if project, ok := tfplan.String(after, "project"); ok && project != "" {
+			k.Project = project
+		}
+		if k.Project == "" {
+			return fmt.Errorf("%s: project unknown, set the default project", address)
+		}
+		if !global {
+			if k.Region = tfplan.Region(after); k.Region == "" {
+				return fmt.Errorf("%s: region unknown", address)
+			}
+		}
+		p.resources[k] = append(p.resources[k], address)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quota checks that the resources stage plans create fit in the
+// Compute Engine quotas of their projects before they are applied.
+//
+// The creations are counted per quota metric of a project, in a region or
+// Global, and compared against the usage and limit of the quotas, read from
+// a snapshot file or fetched live by a Fetcher.
+package quota
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/command"
+)
+
+// Global is the region of the project wide quotas.
+const Global = "global"
+
+// Quota is the usage and limit of a quota metric of a project in a region,
+// or Global.
+type Quota struct {
+	Project string  `yaml:"project" json:"project"`
+	Region  string  `yaml:"region" json:"region"`
+	Metric  string  `yaml:"metric" json:"metric"`
+	Usage   float64 `yaml:"usage" json:"usage"`
+	Limit   float64 `yaml:"limit" json:"limit"`
+}
+
+// Fetcher returns the quotas of a project in a region, or the project wide
+// quotas for Global.
+type Fetcher interface {
+	Quotas(ctx context.Context, project, region string) ([]Quota, error)
+}
+
+// Snapshot is a Fetcher returning the quotas of a snapshot file.
+type Snapshot struct {
+	Entries []Quota `yaml:"quotas"`
+}
+
+// LoadSnapshot reads a quota snapshot:
+//
+//	quotas:
+//	  - {project: net-host, region: global, metric: FIREWALLS, usage: 96, limit: 100}
+//	  - {project: net-host, region: us-central1, metric: FORWARDING_RULES, usage: 12, limit: 75}
+func LoadSnapshot(file string) (*Snapshot, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	seen := map[Key]bool{}
+	for i, q := range s.Entries {
+		k := Key{Project: q.Project, Region: q.Region, Metric: q.Metric}
+		switch {
+		case q.Project == "" || q.Region == "" || q.Metric == "":
+			return nil, fmt.Errorf("%s: quota %d: project, region and metric are required", file, i+1)
+		case q.Limit < 0 || q.Usage < 0:
+			return nil, fmt.Errorf("%s: quota %s: negative usage or limit", file, k)
+		case seen[k]:
+			return nil, fmt.Errorf("%s: duplicate quota %s", file, k)
+		}
+		seen[k] = true
+	}
+	return &s, nil
+}
+
+// Quotas implements Fetcher.
+func (s *Snapshot) Quotas(_ context.Context, project, region string) ([]Quota, error) {
+	var out []Quota
+	for _, q := range s.Entries {
+		if q.Project == project && q.Region == region {
+			out = append(out, q)
+		}
+	}
+	return out, nil
+}
+
+// Gcloud fetches the quotas live with the gcloud CLI, using the credentials
+// the stages are applied with.
+type Gcloud struct {
+	Run command.Runner
+}
+
+// Quotas implements Fetcher.
+func (g *Gcloud) Quotas(ctx context.Context, project, region string) ([]Quota, error) {
+	run := g.Run
+	if run == nil {
+		run = command.Exec
+	}
+	args := []string{"compute", "regions", "describe", region, "--project=" + project, "--format=json(quotas)"}
+	if region == Global {
+		args = []string{"compute", "project-info", "describe", "--project=" + project, "--format=json(quotas)"}
+	}
+	out, err := run(ctx, "", "gcloud", args...)
+	if err != nil {
+		return nil, err
+	}
+	var v struct {
+		Quotas []struct {
+			Metric string  `json:"metric"`
+			Usage  float64 `json:"usage"`
+			Limit  float64 `json:"limit"`
+		} `json:"quotas"`
+	}
+	if err := json.Unmarshal(out, &v); err != nil {
+		return nil, fmt.Errorf("quotas of %s in %s: %w", project, region, err)
+	}
+	quotas := make([]Quota, 0, len(v.Quotas))
+	for _, q := range v.Quotas {
+		quotas = append(quotas, Quota{Project: project, Region: region, Metric: q.Metric, Usage: q.Usage, Limit: q.Limit})
+	}
+	return quotas, nil
+}
+
+// Row is the check of a quota: its usage, the planned additions and its
+// limit.
+type Row struct {
+	Project string  `json:"project"`
+	Region  string  `json:"region"`
+	Metric  string  `json:"metric"`
+	Usage   float64 `json:"usage"`
+	Planned int     `json:"planned"`
+	Limit   float64 `json:"limit"`
+	// Known is false when the fetcher has no quota for the metric, the
+	// additions are then not checked.
+	Known bool `json:"known"`
+	// Exceeded is true when the usage and the planned additions are above
+	// the limit.
+	Exceeded bool `json:"exceeded"`
+	// Resources are the addresses of the planned resources.
+	Resources []string `json:"resources"`
+}
+
+// Check compares the planned additions against the quotas fetched with f,
+// fetching the quotas of every project and region once. Rows are sorted by
+// project, region and metric.
+func Check(ctx context.Context, f Fetcher, p *Planned) ([]Row, error) {
+	type scope struct{ project, region string }
+	fetched := map[scope]map[string]Quota{}
+	var rows []Row
+	for k, resources := range p.resources {
+		sc := scope{k.Project, k.Region}
+		quotas, ok := fetched[sc]
+		if !ok {
+			list, err := f.Quotas(ctx, k.Project, k.Region)
+			if err != nil {
+				return nil, err
+			}
+			quotas = map[string]Quota{}
+			for _, q := range list {
+				quotas[q.Metric] = q
+			}
+			fetched[sc] = quotas
+		}
+		r := Row{Project: k.Project, Region: k.Region, Metric: k.Metric, Planned: len(resources), Resources: resources}
+		if q, ok := quotas[k.Metric]; ok {
+			r.Usage, r.Limit, r.Known = q.Usage, q.Limit, true
+			r.Exceeded = q.Usage+float64(r.Planned) > q.Limit
+		}
+		rows = append(rows, r)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Project != b.Project {
+			return a.Project < b.Project
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.Metric < b.Metric
+	})
+	return rows, nil
+}
+
+// Exceeded reports whether the planned additions exceed a quota.
+func Exceeded(rows []Row) bool {
+	for _, r := range rows {
+		if r.Exceeded {
+			return true
+		}
+	}
+	return false
+}
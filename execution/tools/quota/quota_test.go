@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/tfplan"
+)
+
+func planned(t *testing.T) *Planned {
+	t.Helper()
+	p := &Planned{Project: "host"}
+	for _, stage := range []string{"networking", "psc"} {
+		plan, err := tfplan.ReadFile(filepath.Join("testdata", stage+".json"))
+		if err != nil {
+			t.Fatalf("Failed to read the plan: %v", err)
+		}
+		if err := p.Add(stage, plan); err != nil {
+			t.Fatalf("Failed to count the plan: %v", err)
+		}
+	}
+	return p
+}
+
+func TestPlanned(t *testing.T) {
+	p := planned(t)
+	want := map[Key][]string{
+		{"host", Global, "FIREWALLS"}: {
+			`networking: module.firewall.google_compute_firewall.rules["allow-ssh"]`,
+			`networking: module.firewall.google_compute_firewall.rules["allow-hc"]`,
+		},
+		{"host", Global, "SUBNETWORKS"}:                              {`networking: module.vpc.google_compute_subnetwork.subnet["app"]`},
+		{"host", "us-central1", "FORWARDING_RULES"}:                  {"networking: module.lb.google_compute_forwarding_rule.default"},
+		{"host", "us-central1", "INTERNAL_ADDRESSES"}:                {"networking: module.lb.google_compute_address.ilb"},
+		{"consumer", "us-east4", "PSC_INTERNAL_LB_FORWARDING_RULES"}: {"psc: module.psc.google_compute_forwarding_rule.endpoint"},
+		{"host", Global, "FORWARDING_RULES"}:                         {"psc: module.glb.google_compute_global_forwarding_rule.https"},
+	}
+	if !reflect.DeepEqual(p.resources, want) {
+		t.Errorf("Failed to count the plans:\ngot  %v\nwant %v", p.resources, want)
+	}
+}
+
+func TestPlannedErrors(t *testing.T) {
+	tests := []struct {
+		name, address string
+		after         map[string]any
+		want          string
+	}{
+		{"no project", "google_compute_firewall.fw", map[string]any{"name": "allow-ssh"}, "google_compute_firewall.fw: project unknown"},
+		{"no region", "google_compute_vpn_tunnel.tunnel", map[string]any{"project": "host"}, "google_compute_vpn_tunnel.tunnel: region unknown"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			typ, _, _ := strings.Cut(tc.address, ".")
+			plan := &tfplan.Plan{ResourceChanges: []tfplan.ResourceChange{{
+				Address: tc.address,
+				Mode:    "managed",
+				Type:    typ,
+				Change:  tfplan.Change{Actions: []string{"create"}, After: tc.after},
+			}}}
+			err := (&Planned{}).Add("", plan)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Add() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadSnapshotErrors(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{"no metric", "quotas:\n  - {project: p, region: global, limit: 1}\n", "quota 1: project, region and metric are required"},
+		{"negative", "quotas:\n  - {project: p, region: global, metric: FIREWALLS, limit: -1}\n", "quota p/global/FIREWALLS: negative usage or limit"},
+		{"duplicate", "quotas:\n  - {project: p, region: global, metric: FIREWALLS, limit: 1}\n  - {project: p, region: global, metric: FIREWALLS, limit: 2}\n", "duplicate quota p/global/FIREWALLS"},
+		{"unknown field", "quotas:\n  - {project: p, region: global, metric: FIREWALLS, quota: 1}\n", "field quota not found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "snapshot.yaml")
+			if err := os.WriteFile(file, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("Failed to write the snapshot: %v", err)
+			}
+			_, err := LoadSnapshot(file)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("LoadSnapshot() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	s, err := LoadSnapshot(filepath.Join("testdata", "snapshot.yaml"))
+	if err != nil {
+		t.Fatalf("Failed to load the snapshot: %v", err)
+	}
+	rows, err := Check(context.Background(), s, planned(t))
+	if err != nil {
+		t.Fatalf("Failed to check the quotas: %v", err)
+	}
+	type check struct {
+		key      Key
+		usage    float64
+		planned  int
+		limit    float64
+		known    bool
+		exceeded bool
+	}
+	var got []check
+	for _, r := range rows {
+		got = append(got, check{Key{r.Project, r.Region, r.Metric}, r.Usage, r.Planned, r.Limit, r.Known, r.Exceeded})
+	}
+	want := []check{
+		{Key{"consumer", "us-east4", "PSC_INTERNAL_LB_FORWARDING_RULES"}, 0, 1, 50, true, false},
+		{Key{"host", Global, "FIREWALLS"}, 99, 2, 100, true, true},
+		{Key{"host", Global, "FORWARDING_RULES"}, 0, 1, 0, false, false},
+		{Key{"host", Global, "SUBNETWORKS"}, 10, 1, 275, true, false},
+		{Key{"host", "us-central1", "FORWARDING_RULES"}, 74, 1, 75, true, false},
+		{Key{"host", "us-central1", "INTERNAL_ADDRESSES"}, 5, 1, 200, true, false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to check the quotas:\ngot  %+v\nwant %+v", got, want)
+	}
+	if !Exceeded(rows) {
+		t.Errorf("Exceeded() = false, want true")
+	}
+}
+
+func TestGcloud(t *testing.T) {
+	var calls [][]string
+	g := &Gcloud{Run: func(_ context.Context, _, name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+		if args[1] == "regions" && args[3] == "us-west1" {
+			return nil, errors.New("permission denied")
+		}
+		return []byte(`{"quotas": [{"limit": 100.0, "metric": "FIREWALLS", "usage": 42.0}]}`), nil
+	}}
+	got, err := g.Quotas(context.Background(), "host", Global)
+	if err != nil {
+		t.Fatalf("Failed to fetch the quotas: %v", err)
+	}
+	want := []Quota{{Project: "host", Region: Global, Metric: "FIREWALLS", Usage: 42, Limit: 100}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Quotas() = %+v, want %+v", got, want)
+	}
+	if _, err := g.Quotas(context.Background(), "host", "us-central1"); err != nil {
+		t.Fatalf("Failed to fetch the quotas: %v", err)
+	}
+	if _, err := g.Quotas(context.Background(), "host", "us-west1"); err == nil {
+		t.Errorf("Quotas() succeeded, want the error of gcloud")
+	}
+	wantCalls := [][]string{
+		{"gcloud", "compute", "project-info", "describe", "--project=host", "--format=json(quotas)"},
+		{"gcloud", "compute", "regions", "describe", "us-central1", "--project=host", "--format=json(quotas)"},
+		{"gcloud", "compute", "regions", "describe", "us-west1", "--project=host", "--format=json(quotas)"},
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("Failed to run gcloud:\ngot  %q\nwant %q", calls, wantCalls)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rows := []Row{
+		{Project: "host", Region: Global, Metric: "FIREWALLS", Usage: 99, Planned: 2, Limit: 100, Known: true, Exceeded: true, Resources: []string{"networking: fw1", "networking: fw2"}},
+		{Project: "host", Region: Global, Metric: "FORWARDING_RULES", Planned: 1, Resources: []string{"psc: rule"}},
+		{Project: "host", Region: "us-central1", Metric: "SUBNETWORKS", Usage: 10, Planned: 1, Limit: 275, Known: true, Resources: []string{"networking: subnet"}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, rows, "text"); err != nil {
+		t.Fatalf("Failed to write the rows: %v", err)
+	}
+	want := `METRIC            PROJECT  REGION       USAGE  PLANNED  LIMIT  STATUS
+FIREWALLS         host     global       99     2        100    EXCEEDED
+FORWARDING_RULES  host     global       -      1        -      unknown quota
+SUBNETWORKS       host     us-central1  10     1        275    ok
+
+FIREWALLS of host in global exceeded by 1:
+  networking: fw1
+  networking: fw2
+`
+	if buf.String() != want {
+		t.Errorf("Failed to write the rows:\ngot:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	buf.Reset()
+	if err := Write(&buf, nil, "json"); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Write(nil, json) = %q, %v, want []", buf.String(), err)
+	}
+}
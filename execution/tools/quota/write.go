@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Write prints the rows in the given format: text, a table of the quotas
+// followed by the resources exceeding them, or json.
+func Write(w io.Writer, rows []Row, format string) error {
+	switch format {
+	case "text":
+		return writeText(w, rows)
+	case "json":
+		if rows == nil {
+			rows = []Row{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+	return fmt.Errorf("unknown format %q, expected text or json", format)
+}
+
+func writeText(w io.Writer, rows []Row) error {
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No planned resource counts against a quota.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tPROJECT\tREGION\tUSAGE\tPLANNED\tLIMIT\tSTATUS")
+	for _, r := range rows {
+		usage, limit, status := fmt.Sprintf("%g", r.Usage), fmt.Sprintf("%g", r.Limit), "ok"
+		switch {
+		case !r.Known:
+			usage, limit, status = "-", "-", "unknown quota"
+		case r.Exceeded:
+			status = "EXCEEDED"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", r.Metric, r.Project, r.Region, usage, r.Planned, limit, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if !r.Exceeded {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n%s of %s in %s exceeded by %g:\n", r.Metric, r.Project, r.Region, r.Usage+float64(r.Planned)-r.Limit); err != nil {
+			return err
+		}
+		for _, res := range r.Resources {
+			if _, err := fmt.Fprintln(w, "  "+res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
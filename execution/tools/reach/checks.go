@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reach
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Expectation is an entry of a checks file: a query and its expected
+// outcome, reachable or unreachable.
+type Expectation struct {
+	Query  `yaml:",inline"`
+	Expect string `yaml:"expect"`
+}
+
+// ReadExpectations reads a checks file, a YAML list of expectations:
+//
+//   - source: client
+//     destination: cloudsql/sql-1
+//     port: 5432
+//     expect: reachable
+func ReadExpectations(path string) ([]Expectation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []Expectation
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&out); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, e := range out {
+		switch {
+		case e.Source == "" || e.Destination == "":
+			return nil, fmt.Errorf("%s: check %d: source and destination are required", path, i+1)
+		case e.Port < 0 || e.Port > 65535:
+			return nil, fmt.Errorf("%s: check %d: invalid port %d", path, i+1, e.Port)
+		case e.Expect != "reachable" && e.Expect != "unreachable":
+			return nil, fmt.Errorf("%s: check %d: expect must be reachable or unreachable, not %q", path, i+1, e.Expect)
+		}
+	}
+	return out, nil
+}
+
+// Met reports whether the verdict matches the expectation.
+func (e Expectation) Met(v Verdict) bool {
+	return v.Reachable == (e.Expect == "reachable")
+}
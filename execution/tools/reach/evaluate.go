@@ -0,0 +1,280 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reach
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/firewall"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ipplan"
+)
+
+// Resolve returns the endpoint named by ref, which is one of:
+//
+//   - a qualified name, e.g. instance/vm-1, mig/web, psc/sql-1,
+//     cloudsql/sql-1 or alloydb/cluster-1;
+//   - a plain name, when a single endpoint has it;
+//   - an IP address, optionally followed by @network when several networks
+//     use it. The address must be in a subnet or a PSA range.
+func (m *Model) Resolve(ref string) (*Endpoint, error) {
+	if addr, network, _ := strings.Cut(ref, "@"); addr != "" {
+		if a, err := netip.ParseAddr(addr); err == nil {
+			return m.resolveAddr(a, network)
+		}
+	}
+	var found []*Endpoint
+	for _, e := range m.Endpoints {
+		if e.ID() == ref || e.Name == ref {
+			found = append(found, e)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no endpoint named %q", ref)
+	case 1:
+		return found[0], nil
+	}
+	ids := make([]string, len(found))
+	for i, e := range found {
+		ids[i] = e.ID()
+	}
+	return nil, fmt.Errorf("%q is ambiguous, use one of %s", ref, strings.Join(ids, ", "))
+}
+
+func (m *Model) resolveAddr(addr netip.Addr, network string) (*Endpoint, error) {
+	var found []ipplan.Allocation
+	networks := m.Plan.Networks()
+	if network != "" {
+		networks = []string{network}
+	}
+	for _, n := range networks {
+		for _, a := range m.Plan.Lookup(n, addr) {
+			if a.Kind == ipplan.Subnet || a.Kind == ipplan.PSARange {
+				found = append(found, a)
+				break
+			}
+		}
+	}
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("%s is in no subnet or PSA range", addr)
+	case len(found) > 1:
+		var names []string
+		for _, a := range found {
+			names = append(names, addr.String()+"@"+a.Network)
+		}
+		return nil, fmt.Errorf("%s is used by several networks, use one of %s", addr, strings.Join(names, ", "))
+	}
+	a := found[0]
+	e := &Endpoint{Kind: Address, Name: addr.String(), Network: a.Network, Region: a.Region,
+		Prefix: netip.PrefixFrom(addr, addr.BitLen()), File: a.File}
+	if a.Kind == ipplan.PSARange {
+		e.PSA = true
+	} else {
+		e.Firewalled = true
+	}
+	return e, nil
+}
+
+// Query is the traffic to simulate.
+type Query struct {
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination"`
+	// Protocol defaults to tcp.
+	Protocol string `yaml:"protocol,omitempty"`
+	Port     int    `yaml:"port"`
+}
+
+// Step is a check of the decision path.
+type Step struct {
+	Check  string   `json:"check"`
+	OK     bool     `json:"ok"`
+	Detail string   `json:"detail"`
+	Rules  []string `json:"rules,omitempty"`
+}
+
+// Verdict is the outcome of a query.
+type Verdict struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Protocol    string `json:"protocol"`
+	Port        int    `json:"port"`
+	Reachable   bool   `json:"reachable"`
+	// Certain is false when the outcome depends on addresses that are not
+	// known before apply, e.g. a firewall rule matching part of a subnet.
+	Certain bool   `json:"certain"`
+	Steps   []Step `json:"steps"`
+}
+
+func (v *Verdict) step(check string, ok bool, format string, args ...any) *Step {
+	v.Steps = append(v.Steps, Step{Check: check, OK: ok, Detail: fmt.Sprintf(format, args...)})
+	return &v.Steps[len(v.Steps)-1]
+}
+
+// Evaluate simulates the query. The source and destination are resolved
+// with Resolve, then the route between their networks, the PSC global
+// access, the egress rules of the source network, the ingress rules of the
+// destination network and the ports of the destination are checked in turn.
+// Evaluation stops at the first failed check.
+func (m *Model) Evaluate(q Query) Verdict {
+	v := Verdict{Source: q.Source, Destination: q.Destination, Protocol: strings.ToLower(q.Protocol), Port: q.Port, Certain: true}
+	if v.Protocol == "" {
+		v.Protocol = "tcp"
+	}
+	src, err := m.Resolve(q.Source)
+	if err != nil {
+		v.step("source", false, "%v", err)
+		return v
+	}
+	v.step("source", true, "%s: %s in network %q, region %s (%s)", src.ID(), src.Prefix, src.Network, src.Region, src.File)
+	dst, err := m.Resolve(q.Destination)
+	if err != nil {
+		v.step("destination", false, "%v", err)
+		return v
+	}
+	v.step("destination", true, "%s: %s in network %q, region %s (%s)", dst.ID(), dst.Prefix, dst.Network, dst.Region, dst.File)
+	if src.PSA || src.Kind == PSCEndpoint {
+		v.step("source", false, "%s is a producer endpoint, it does not initiate connections", src.ID())
+		return v
+	}
+	checks := []func(*Verdict, *Endpoint, *Endpoint) bool{m.route, m.globalAccess, m.egress, m.ingress, m.ports}
+	for _, check := range checks {
+		if !check(&v, src, dst) {
+			return v
+		}
+	}
+	v.Reachable = true
+	return v
+}
+
+func (m *Model) route(v *Verdict, src, dst *Endpoint) bool {
+	if src.Network == dst.Network {
+		detail := "same network %q"
+		if dst.PSA {
+			detail += ", through the Private Service Access peering"
+		}
+		v.step("route", true, detail, src.Network)
+		return true
+	}
+	hub, ok := m.Plan.Route(src.Network, dst.Network, dst.Prefix)
+	if !ok {
+		what := "subnets"
+		if dst.PSA {
+			what = "PSA ranges (linked_producer_vpc_network spoke)"
+		}
+		v.step("route", false, "no route from network %q to %s: the networks share no NCC hub where %q exports its %s", src.Network, dst.Prefix, dst.Network, what)
+		return false
+	}
+	if dst.Kind == PSCEndpoint && !m.exportPSC[hub] {
+		v.step("route", false, "PSC endpoint %s is in network %q, and NCC hub %q does not propagate PSC endpoints (export_psc)", dst.Prefix, dst.Network, hub)
+		return false
+	}
+	v.step("route", true, "from network %q to network %q through NCC hub %q", src.Network, dst.Network, hub)
+	return true
+}
+
+func (m *Model) globalAccess(v *Verdict, src, dst *Endpoint) bool {
+	if dst.Kind != PSCEndpoint || src.Region == "" || dst.Region == "" {
+		return true
+	}
+	switch {
+	case dst.GlobalAccess:
+		v.step("psc-global-access", true, "PSC endpoint allows global access")
+	case src.Region != dst.Region:
+		v.step("psc-global-access", false, "source is in %s, PSC endpoint in %s without allow_psc_global_access", src.Region, dst.Region)
+		return false
+	}
+	return true
+}
+
+func (m *Model) rules(v *Verdict, check string, network string, p firewall.Packet) bool {
+	d := m.Firewall.Evaluate(network, p)
+	var rules []string
+	for _, s := range d.Path {
+		rules = append(rules, s.Detail)
+	}
+	v.Certain = v.Certain && d.Certain
+	action := "allowed"
+	if !d.Allowed {
+		action = "denied"
+	}
+	detail := fmt.Sprintf("%s by the rules of network %q", action, network)
+	if !d.Certain {
+		detail += ", except for part of the addresses"
+	}
+	v.step(check, d.Allowed, "%s", detail).Rules = rules
+	return d.Allowed
+}
+
+func (m *Model) egress(v *Verdict, src, dst *Endpoint) bool {
+	return m.rules(v, "egress", src.Network, firewall.Packet{
+		Direction: firewall.Egress, Remote: dst.Prefix, Tags: src.Tags, Protocol: v.Protocol, Port: v.Port,
+	})
+}
+
+func (m *Model) ingress(v *Verdict, src, dst *Endpoint) bool {
+	if !dst.Firewalled {
+		v.step("ingress", true, "%s is served by a producer, the ingress rules of the consumer network do not apply", dst.ID())
+		return true
+	}
+	p := firewall.Packet{Direction: firewall.Ingress, Remote: src.Prefix, Tags: dst.Tags, Protocol: v.Protocol, Port: v.Port}
+	// Source tags and service accounts only match within a network.
+	if src.Network == dst.Network {
+		p.RemoteTags = src.Tags
+	}
+	return m.rules(v, "ingress", dst.Network, p)
+}
+
+func (m *Model) ports(v *Verdict, src, dst *Endpoint) bool {
+	if len(dst.Ports) == 0 {
+		return true
+	}
+	if v.Protocol == "tcp" && slices.Contains(dst.Ports, v.Port) {
+		v.step("port", true, "%s serves tcp:%d", dst.Service, v.Port)
+		return true
+	}
+	ports := slices.Clone(dst.Ports)
+	sort.Ints(ports)
+	v.step("port", false, "%s only serves tcp on ports %s", dst.Service, strings.Trim(fmt.Sprint(ports), "[]"))
+	return false
+}
+
+// String returns the decision path, one step per line.
+func (v Verdict) String() string {
+	var b strings.Builder
+	outcome := "REACHABLE"
+	if !v.Reachable {
+		outcome = "UNREACHABLE"
+	}
+	if !v.Certain {
+		outcome += " (uncertain)"
+	}
+	fmt.Fprintf(&b, "%s -> %s %s:%d: %s\n", v.Source, v.Destination, v.Protocol, v.Port, outcome)
+	for _, s := range v.Steps {
+		mark := "ok"
+		if !s.OK {
+			mark = "FAIL"
+		}
+		fmt.Fprintf(&b, "  %-4s %-17s %s\n", mark, s.Check, s.Detail)
+		for _, r := range s.Rules {
+			fmt.Fprintf(&b, "       %-17s %s\n", "", r)
+		}
+	}
+	return b.String()
+}
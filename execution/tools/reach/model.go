@@ -0,0 +1,327 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reach simulates, without calling Google Cloud, whether a consumer
+// reaches a producer or another consumer over a given protocol and port. It
+// combines the address plan of the networking stages, the firewall rules and
+// policies of the security stages, the PSC endpoints of the
+// producer-connectivity stage and the consumer instances, and explains every
+// step of the decision.
+package reach
+
+import (
+	"fmt"
+	"net/netip"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/firewall"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/ipplan"
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/report"
+)
+
+// Kinds of endpoints.
+const (
+	Instance      = "instance"
+	InstanceGroup = "mig"
+	PSCEndpoint   = "psc"
+	CloudSQL      = "cloudsql"
+	AlloyDB       = "alloydb"
+	Address       = "address"
+)
+
+// Endpoint is a source or destination of traffic.
+type Endpoint struct {
+	Kind    string
+	Name    string
+	Network string
+	Region  string
+	// Prefix is the address of the endpoint, or the range it gets its
+	// address from when the address is not known.
+	Prefix netip.Prefix
+	// Tags are the network tags and service accounts of an instance.
+	Tags []string
+	// Ports are the ports a producer serves, any port when empty.
+	Ports []int
+	// Service describes what serves the ports, e.g. the database version.
+	Service string
+	// Firewalled is set when the ingress rules of the network apply to the
+	// endpoint: instances and addresses, but not PSC endpoints or producers
+	// reached through Private Service Access.
+	Firewalled bool
+	// GlobalAccess is set on PSC endpoints reachable from every region.
+	GlobalAccess bool
+	// PSA is set on producers reached through Private Service Access.
+	PSA  bool
+	File string
+}
+
+// ID returns the qualified name of the endpoint, e.g. psc/sql-1.
+func (e *Endpoint) ID() string {
+	return e.Kind + "/" + e.Name
+}
+
+// Model is the connectivity model of a configuration tree.
+type Model struct {
+	Plan      *ipplan.Plan
+	Firewall  *firewall.Model
+	Endpoints []*Endpoint
+	// exportPSC lists the NCC hubs propagating PSC endpoints.
+	exportPSC map[string]bool
+}
+
+// Build returns the connectivity model of one or more trees, typically one
+// per VPC, with the problems found while loading them. As with
+// ipplan.Collect, file names are prefixed with the tree root when several
+// trees are given.
+func Build(trees ...*config.Tree) (*Model, []report.Finding) {
+	m := &Model{Plan: ipplan.Collect(trees...), Firewall: &firewall.Model{}, exportPSC: map[string]bool{}}
+	b := &builder{model: m, services: map[string]*Endpoint{}}
+	b.findings = append(b.findings, m.Plan.Findings...)
+	for _, t := range trees {
+		b.setTree(t, len(trees) > 1)
+		b.firewall(t)
+		for _, s := range t.Stages {
+			for _, d := range s.Documents {
+				switch v := d.Value.(type) {
+				case *config.VMInstanceConfig:
+					b.instance(v, b.file(d.File))
+				case *config.MIGConfig:
+					b.instanceGroup(v, b.file(d.File))
+				case *config.CloudSQLStruct:
+					b.cloudSQL(v, b.file(d.File))
+				case *config.AlloyDBStruct:
+					b.alloyDB(v, b.file(d.File))
+				case *config.NCCConfig:
+					for _, h := range v.Hubs {
+						if h.ExportPSC != nil && *h.ExportPSC {
+							m.exportPSC[h.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	// PSC endpoints take the ports of their producer, so they go last.
+	for _, t := range trees {
+		b.setTree(t, len(trees) > 1)
+		for _, s := range t.Stages {
+			if v, ok := s.Vars.(*config.ProducerConnectivityVars); ok {
+				for i := range v.PSCEndpoints {
+					b.pscEndpoint(&v.PSCEndpoints[i], b.file(s.VarsFile))
+				}
+			}
+		}
+	}
+	return m, b.findings
+}
+
+type builder struct {
+	model *Model
+	// services maps the qualified name of producers to their ports, for the
+	// PSC endpoints forwarding to them.
+	services map[string]*Endpoint
+	prefix   string
+	findings []report.Finding
+}
+
+func (b *builder) setTree(t *config.Tree, prefixed bool) {
+	b.prefix = ""
+	if prefixed {
+		b.prefix = filepath.ToSlash(t.Root)
+	}
+}
+
+func (b *builder) file(name string) string {
+	if b.prefix == "" {
+		return name
+	}
+	return path.Join(b.prefix, name)
+}
+
+// firewall merges the rules and policies of a tree into the model.
+func (b *builder) firewall(t *config.Tree) {
+	fw, findings := firewall.Load(t)
+	for _, f := range findings {
+		f.File = b.file(f.File)
+		b.findings = append(b.findings, f)
+	}
+	for _, p := range fw.Policies {
+		p.File = b.file(p.File)
+		for _, r := range p.Rules {
+			r.File = b.file(r.File)
+		}
+	}
+	for _, n := range fw.Networks {
+		for _, r := range n.Rules {
+			r.File = b.file(r.File)
+		}
+		if existing := b.model.Firewall.Network(n.Name); existing != nil {
+			existing.Rules = append(existing.Rules, n.Rules...)
+			existing.Policies = append(existing.Policies, n.Policies...)
+			continue
+		}
+		b.model.Firewall.Networks = append(b.model.Firewall.Networks, n)
+	}
+	b.model.Firewall.Policies = append(b.model.Firewall.Policies, fw.Policies...)
+}
+
+func (b *builder) warn(file, resource, format string, args ...any) {
+	b.findings = append(b.findings, report.Finding{
+		Severity: report.Warning, Check: "unresolved", File: file, Resource: resource, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (b *builder) add(e *Endpoint) {
+	b.model.Endpoints = append(b.model.Endpoints, e)
+}
+
+// subnet returns the range and region of a subnet given by name or self
+// link.
+func (b *builder) subnet(network, ref string) (netip.Prefix, string, bool) {
+	name := path.Base(ref)
+	for _, a := range b.model.Plan.Allocations {
+		if a.Network == network && a.Kind == ipplan.Subnet && a.Name == name {
+			return a.Prefix, a.Region, true
+		}
+	}
+	return netip.Prefix{}, "", false
+}
+
+func (b *builder) instance(v *config.VMInstanceConfig, file string) {
+	e := &Endpoint{Kind: Instance, Name: v.Name, Network: ipplan.NetworkName(v.Network), Region: v.Region, Tags: v.Tags, Firewalled: true, File: file}
+	if email, ok := v.ServiceAccount["email"].(string); ok && email != "" {
+		e.Tags = append(append([]string(nil), e.Tags...), "serviceAccount:"+email)
+	}
+	prefix, region, ok := b.subnet(e.Network, v.Subnetwork)
+	if !ok {
+		b.warn(file, fmt.Sprintf("instance %q", v.Name), "subnet %q of network %q is not in the networking configuration", path.Base(v.Subnetwork), e.Network)
+		return
+	}
+	e.Prefix = prefix
+	if e.Region == "" {
+		e.Region = region
+	}
+	b.add(e)
+}
+
+func (b *builder) instanceGroup(v *config.MIGConfig, file string) {
+	e := &Endpoint{Kind: InstanceGroup, Name: v.Name, Network: ipplan.NetworkName(v.VPCName), Firewalled: true, File: file}
+	prefix, region, ok := b.subnet(e.Network, v.SubnetworkName)
+	if !ok {
+		b.warn(file, fmt.Sprintf("instance_group %q", v.Name), "subnet %q of network %q is not in the networking configuration", v.SubnetworkName, e.Network)
+		return
+	}
+	e.Prefix, e.Region = prefix, region
+	b.add(e)
+}
+
+// databasePorts returns the ports served by a database version, with the
+// port of the Auth Proxy connectors.
+func databasePorts(kind, version string) []int {
+	switch v := strings.ToUpper(version); {
+	case kind == AlloyDB:
+		return []int{5432, 5433}
+	case strings.HasPrefix(v, "MYSQL"):
+		return []int{3306, 3307}
+	case strings.HasPrefix(v, "POSTGRES"):
+		return []int{5432, 3307}
+	case strings.HasPrefix(v, "SQLSERVER"):
+		return []int{1433, 3307}
+	}
+	return nil
+}
+
+// psaPrefix returns the PSA range of a network.
+func (b *builder) psaPrefix(network string) (netip.Prefix, bool) {
+	for _, a := range b.model.Plan.Allocations {
+		if a.Network == network && a.Kind == ipplan.PSARange {
+			return a.Prefix, true
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+func (b *builder) cloudSQL(v *config.CloudSQLStruct, file string) {
+	e := &Endpoint{Kind: CloudSQL, Name: v.Name, Region: v.Region,
+		Ports: databasePorts(CloudSQL, v.DatabaseVersion), Service: v.DatabaseVersion, PSA: true, File: file}
+	b.services[e.ID()] = e
+	psa := v.NetworkConfig.Connectivity.PSAConfig
+	if psa == nil || psa.PrivateNetwork == "" {
+		return
+	}
+	e.Network = ipplan.NetworkName(psa.PrivateNetwork)
+	if e.Prefix, _ = b.psaPrefix(e.Network); !e.Prefix.IsValid() {
+		b.warn(file, fmt.Sprintf("instance %q", v.Name), "network %q has no PSA range in the networking configuration", e.Network)
+		return
+	}
+	b.add(e)
+}
+
+func (b *builder) alloyDB(v *config.AlloyDBStruct, file string) {
+	e := &Endpoint{Kind: AlloyDB, Name: v.ClusterID, Network: ipplan.NetworkName(v.NetworkID), Region: v.Region,
+		Ports: databasePorts(AlloyDB, v.DatabaseVersion), Service: "AlloyDB", PSA: true, File: file}
+	b.services[e.ID()] = e
+	if v.NetworkID == "" || strings.EqualFold(v.ConnectivityOptions, "psc") {
+		return
+	}
+	if e.Prefix, _ = b.psaPrefix(e.Network); !e.Prefix.IsValid() {
+		b.warn(file, fmt.Sprintf("cluster %q", v.ClusterID), "network %q has no PSA range in the networking configuration", e.Network)
+		return
+	}
+	b.add(e)
+}
+
+func (b *builder) pscEndpoint(v *config.PSCEndpointConfig, file string) {
+	e := &Endpoint{Kind: PSCEndpoint, Name: v.Producer(), Network: ipplan.NetworkName(v.NetworkName), Region: v.Region,
+		GlobalAccess: v.AllowPSCGlobalAccess != nil && *v.AllowPSCGlobalAccess, File: file}
+	if e.Name == "" {
+		return
+	}
+	resource := fmt.Sprintf("psc_endpoint %q", e.Name)
+	prefix, region, ok := b.subnet(e.Network, v.SubnetworkName)
+	if !ok {
+		b.warn(file, resource, "subnet %q of network %q is not in the networking configuration", v.SubnetworkName, e.Network)
+		return
+	}
+	e.Prefix = prefix
+	if e.Region == "" {
+		e.Region = region
+	}
+	if v.IPAddressLiteral != "" {
+		addr, err := netip.ParseAddr(v.IPAddressLiteral)
+		switch {
+		case err != nil:
+			b.warn(file, resource, "%q is not an IP address", v.IPAddressLiteral)
+		case !prefix.Contains(addr):
+			b.warn(file, resource, "address %s is not in subnet %q (%s)", addr, v.SubnetworkName, prefix)
+		default:
+			e.Prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+	}
+	// The endpoint forwards to the producer, which serves its own ports.
+	var producer *Endpoint
+	switch {
+	case v.ProducerCloudSQL != nil:
+		producer = b.services[CloudSQL+"/"+v.ProducerCloudSQL.InstanceName]
+	case v.ProducerAlloyDB != nil:
+		producer = b.services[AlloyDB+"/"+v.ProducerAlloyDB.ClusterID]
+	}
+	if producer != nil {
+		e.Ports, e.Service = producer.Ports, producer.Service
+	}
+	b.add(e)
+}
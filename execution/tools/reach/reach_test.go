@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reach
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+func loadModel(t *testing.T) *Model {
+	t.Helper()
+	var trees []*config.Tree
+	for _, root := range []string{"testdata/vpc-a", "testdata/vpc-b"} {
+		tree, err := config.LoadTree(root)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", root, err)
+		}
+		trees = append(trees, tree)
+	}
+	m, findings := Build(trees...)
+	for _, f := range findings {
+		t.Errorf("Unexpected finding: %s", f)
+	}
+	return m
+}
+
+func TestEvaluate(t *testing.T) {
+	m := loadModel(t)
+	testCases := []struct {
+		name      string
+		query     Query
+		reachable bool
+		certain   bool
+		// failed is the check that failed, or the last check when reachable.
+		failed string
+		detail string
+	}{
+		{
+			name:      "Allowed by target tag",
+			query:     Query{Source: "client", Destination: "web", Port: 443},
+			reachable: true,
+			certain:   true,
+			failed:    "ingress",
+			detail:    `allowed by the rules of network "vpc-a"`,
+		},
+		{
+			name:    "Implied deny ingress",
+			query:   Query{Source: "client", Destination: "instance/web", Port: 22},
+			certain: true,
+			failed:  "ingress",
+			detail:  `denied by the rules of network "vpc-a"`,
+		},
+		{
+			name:      "Through NCC hub",
+			query:     Query{Source: "bastion", Destination: "web", Port: 22},
+			reachable: true,
+			certain:   true,
+			failed:    "ingress",
+		},
+		{
+			name:      "Cloud SQL through PSA",
+			query:     Query{Source: "client", Destination: "sql-mysql", Port: 3306},
+			reachable: true,
+			certain:   true,
+			failed:    "port",
+			detail:    "MYSQL_8_0 serves tcp:3306",
+		},
+		{
+			name:    "Egress deny rule",
+			query:   Query{Source: "restricted", Destination: "cloudsql/sql-mysql", Port: 3306},
+			certain: true,
+			failed:  "egress",
+			detail:  `denied by the rules of network "vpc-a"`,
+		},
+		{
+			name:    "Port not served",
+			query:   Query{Source: "client", Destination: "sql-mysql", Port: 5432},
+			certain: true,
+			failed:  "port",
+			detail:  "MYSQL_8_0 only serves tcp on ports 3306 3307",
+		},
+		{
+			name:      "PSC endpoint",
+			query:     Query{Source: "client", Destination: "psc/sql-pg", Port: 5432},
+			reachable: true,
+			certain:   true,
+			failed:    "port",
+			detail:    "POSTGRES_15 serves tcp:5432",
+		},
+		{
+			name:    "PSC endpoint without global access",
+			query:   Query{Source: "restricted", Destination: "sql-pg", Port: 5432},
+			certain: true,
+			failed:  "psc-global-access",
+			detail:  "source is in us-east1, PSC endpoint in us-central1 without allow_psc_global_access",
+		},
+		{
+			name:    "PSC endpoint not exported to the hub",
+			query:   Query{Source: "bastion", Destination: "sql-pg", Port: 5432},
+			certain: true,
+			failed:  "route",
+			detail:  `PSC endpoint 10.0.0.50/32 is in network "vpc-a", and NCC hub "hub-1" does not propagate PSC endpoints (export_psc)`,
+		},
+		{
+			name:    "PSA range not exported to the hub",
+			query:   Query{Source: "bastion", Destination: "sql-mysql", Port: 3306},
+			certain: true,
+			failed:  "route",
+			detail:  `no route from network "vpc-b" to 10.0.64.0/20: the networks share no NCC hub where "vpc-a" exports its PSA ranges (linked_producer_vpc_network spoke)`,
+		},
+		{
+			name:   "Rule matching part of the subnet",
+			query:  Query{Source: "mig/workers", Destination: "web", Port: 8080},
+			failed: "ingress",
+			detail: `denied by the rules of network "vpc-a", except for part of the addresses`,
+		},
+		{
+			name:      "Address",
+			query:     Query{Source: "10.0.1.5", Destination: "web", Port: 8080},
+			reachable: true,
+			certain:   true,
+			failed:    "ingress",
+		},
+		{
+			name:    "Unknown endpoint",
+			query:   Query{Source: "client", Destination: "nope", Port: 80},
+			certain: true,
+			failed:  "destination",
+			detail:  `no endpoint named "nope"`,
+		},
+		{
+			name:    "Address outside the plan",
+			query:   Query{Source: "192.168.0.1", Destination: "web", Port: 80},
+			certain: true,
+			failed:  "source",
+			detail:  "192.168.0.1 is in no subnet or PSA range",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := m.Evaluate(tc.query)
+			last := v.Steps[len(v.Steps)-1]
+			if v.Reachable != tc.reachable || v.Certain != tc.certain || last.Check != tc.failed {
+				t.Fatalf("Evaluate() = reachable %t, certain %t, last check %q, want %t, %t, %q\n%s",
+					v.Reachable, v.Certain, last.Check, tc.reachable, tc.certain, tc.failed, v)
+			}
+			if last.OK != tc.reachable {
+				t.Errorf("Last step OK = %t, want %t\n%s", last.OK, tc.reachable, v)
+			}
+			if tc.detail != "" && last.Detail != tc.detail {
+				t.Errorf("Last step detail = %q, want %q", last.Detail, tc.detail)
+			}
+		})
+	}
+}
+
+func TestEvaluatePath(t *testing.T) {
+	v := loadModel(t).Evaluate(Query{Source: "bastion", Destination: "web", Port: 22})
+	got := v.String()
+	for _, want := range []string{
+		"bastion -> web tcp:22: REACHABLE\n",
+		`ok   route             from network "vpc-b" to network "vpc-a" through NCC hub "hub-1"`,
+		"implied allow egress rule",
+		`allow ingress_rule "allow-ssh-from-vpc-b" (priority 1000, testdata/vpc-a/security/gce.tfvars) matches`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Decision path does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestResolveAmbiguous(t *testing.T) {
+	m := loadModel(t)
+	m.Endpoints = append(m.Endpoints, &Endpoint{Kind: InstanceGroup, Name: "web"})
+	_, err := m.Resolve("web")
+	if err == nil || err.Error() != `"web" is ambiguous, use one of instance/web, mig/web` {
+		t.Errorf("Resolve() error = %v, want ambiguous", err)
+	}
+}
+
+func TestExpectations(t *testing.T) {
+	m := loadModel(t)
+	checks, err := ReadExpectations("testdata/checks.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read checks: %v", err)
+	}
+	var unmet []string
+	for _, c := range checks {
+		if !c.Met(m.Evaluate(c.Query)) {
+			unmet = append(unmet, c.Source+" -> "+c.Destination)
+		}
+	}
+	if want := []string{"bastion -> psc/sql-pg"}; !slices.Equal(unmet, want) {
+		t.Errorf("Unmet expectations = %q, want %q", unmet, want)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report is the finding format shared by the configuration
+// validators, with its text and JSON renderings.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Severity ranks findings. The zero value is Info.
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// ParseSeverity parses info, warning or error.
+func ParseSeverity(s string) (Severity, error) {
+	for i, name := range severityNames {
+		if strings.EqualFold(s, name) {
+			return Severity(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unknown severity %q, expected info, warning or error", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(b []byte) error {
+	v, err := ParseSeverity(string(b))
+	*s = v
+	return err
+}
+
+// Finding is an issue found by a validator.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	// Check is the short name of the rule that produced the finding, e.g.
+	// overlap.
+	Check string `json:"check"`
+	// File is the configuration file the resource is declared in, relative
+	// to the configuration root.
+	File string `json:"file,omitempty"`
+	// Resource identifies the resource within the file, e.g.
+	// subnet "us-central1/subnet-a".
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (f Finding) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-7s ", strings.ToUpper(f.Severity.String()))
+	if f.File != "" {
+		b.WriteString(f.File + ": ")
+	}
+	if f.Resource != "" {
+		b.WriteString(f.Resource + ": ")
+	}
+	b.WriteString(f.Message)
+	if f.Check != "" {
+		fmt.Fprintf(&b, " [%s]", f.Check)
+	}
+	return b.String()
+}
+
+// Report is the list of findings of one or more validators.
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Add appends findings to the report.
+func (r *Report) Add(findings ...Finding) {
+	r.Findings = append(r.Findings, findings...)
+}
+
+// Sort orders the findings by file, resource, decreasing severity and check,
+// keeping the validator order otherwise.
+func (r *Report) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		switch {
+		case a.File != b.File:
+			return a.File < b.File
+		case a.Resource != b.Resource:
+			return a.Resource < b.Resource
+		case a.Severity != b.Severity:
+			return a.Severity > b.Severity
+		}
+		return a.Check < b.Check
+	})
+}
+
+// Count returns the number of findings of a severity.
+func (r *Report) Count(s Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Fails reports whether a finding is at least as severe as threshold.
+func (r *Report) Fails(threshold Severity) bool {
+	for _, f := range r.Findings {
+		if f.Severity >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText prints one finding per line followed by a summary.
+func (r *Report) WriteText(w io.Writer) error {
+	for _, f := range r.Findings {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	if len(r.Findings) == 0 {
+		_, err := fmt.Fprintln(w, "No findings.")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d finding(s): %d error(s), %d warning(s), %d info.\n",
+		len(r.Findings), r.Count(Error), r.Count(Warning), r.Count(Info))
+	return err
+}
+
+// WriteJSON prints the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	out := *r
+	if out.Findings == nil {
+		out.Findings = []Finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// Write prints the report in the given format, text or json.
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "json":
+		return r.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown format %q, expected text or json", format)
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	r := &Report{}
+	r.Add(
+		Finding{Severity: Warning, Check: "outside-allowed", File: "networking.tfvars", Resource: `subnet "b"`, Message: "not private"},
+		Finding{Severity: Info, Check: "usage", Message: "10% used"},
+		Finding{Severity: Error, Check: "overlap", File: "networking.tfvars", Resource: `subnet "a"`, Message: "overlaps b"},
+	)
+	r.Sort()
+
+	var text bytes.Buffer
+	if err := r.WriteText(&text); err != nil {
+		t.Fatalf("WriteText() failed: %v", err)
+	}
+	want := `INFO    10% used [usage]
+ERROR   networking.tfvars: subnet "a": overlaps b [overlap]
+WARNING networking.tfvars: subnet "b": not private [outside-allowed]
+
+3 finding(s): 1 error(s), 1 warning(s), 1 info.
+`
+	if text.String() != want {
+		t.Errorf("WriteText() =\n%s\nwant:\n%s", text.String(), want)
+	}
+	if !r.Fails(Warning) || (&Report{Findings: r.Findings[:1]}).Fails(Warning) {
+		t.Errorf("Fails(Warning) does not match the finding severities")
+	}
+
+	var out bytes.Buffer
+	if err := r.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON() failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode the JSON report: %v", err)
+	}
+	if !reflect.DeepEqual(decoded.Findings, r.Findings) {
+		t.Errorf("JSON round trip = %v, want %v", decoded.Findings, r.Findings)
+	}
+	if !bytes.Contains(out.Bytes(), []byte(`"severity": "error"`)) {
+		t.Errorf("Severities should be written as names:\n%s", out.String())
+	}
+}
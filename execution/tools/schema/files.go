@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+// All generates the schema document of every stage with a typed YAML schema,
+// keyed by file name.
+func All(executionDir string) (map[string][]byte, error) {
+	docs := map[string][]byte{}
+	for _, stage := range config.Stages {
+		if !stage.Typed() {
+			continue
+		}
+		descriptions, err := Descriptions(executionDir, stage)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := Generate(stage, descriptions)
+		if err != nil {
+			return nil, err
+		}
+		docs[FileName(stage)] = doc
+	}
+	return docs, nil
+}
+
+// WriteAll writes the schema documents to dir, removing schema files of
+// stages that no longer exist.
+func WriteAll(executionDir, dir string) error {
+	docs, err := All(executionDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.schema.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range existing {
+		if _, ok := docs[filepath.Base(f)]; !ok {
+			if err := os.Remove(f); err != nil {
+				return err
+			}
+		}
+	}
+	for name, doc := range docs {
+		if err := os.WriteFile(filepath.Join(dir, name), doc, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check compares the schema documents in dir with freshly generated ones and
+// returns the files that are missing, stale or no longer generated.
+func Check(executionDir, dir string) ([]string, error) {
+	docs, err := All(executionDir)
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for name, doc := range docs {
+		f := filepath.Join(dir, name)
+		current, err := os.ReadFile(f)
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && !bytes.Equal(current, doc)) {
+			stale = append(stale, f)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.schema.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range existing {
+		if _, ok := docs[filepath.Base(f)]; !ok {
+			stale = append(stale, f)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// Modeline returns the yaml-language-server modeline pointing a stage YAML
+// file at its schema, with a path relative to the YAML file.
+func Modeline(stage config.Stage) string {
+	depth := strings.Count(stage.ConfigDir, "/") + 1
+	return "# yaml-language-server: $schema=" + strings.Repeat("../", depth) + "schemas/" + FileName(stage)
+}
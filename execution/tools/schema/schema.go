@@ -0,0 +1,236 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema generates JSON Schema documents for the stage YAML files from
+// the typed schemas of the config package, for editor completion and inline
+// validation through yaml-language-server.
+//
+// Properties are derived from the yaml tags of the schema structs. A field is
+// required when its yaml tag has no omitempty option. The following struct
+// tags add to the generated schema:
+//
+//	doc:"..."             the property description
+//	enum:"A,B"            the allowed values
+//	type:"string,integer" the JSON types accepted, overriding the Go type
+//	                      (of the items, for lists)
+//	var:"name"            the stage variable the description is read from
+//
+// Top level properties without a doc tag are described with the description
+// of the stage variable of the same name (or the name given in a var tag),
+// read from the stage variables.tf.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+// Draft is the JSON Schema dialect of the generated documents, the most
+// recent one supported by yaml-language-server.
+const Draft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema document or sub-schema.
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           *orderedProperties `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// FileName returns the name of the schema file of a stage, e.g.
+// producer-cloudsql.schema.json.
+func FileName(stage config.Stage) string {
+	return strings.ToLower(strings.NewReplacer("/", "-").Replace(stage.Name)) + ".schema.json"
+}
+
+// Generate returns the indented JSON Schema document of the YAML files of a
+// stage. descriptions maps stage variable names to their description.
+func Generate(stage config.Stage, descriptions map[string]string) ([]byte, error) {
+	if !stage.Typed() {
+		return nil, fmt.Errorf("stage %s has no typed YAML schema", stage.Name)
+	}
+	t := reflect.TypeOf(stage.NewDocument()).Elem()
+	s := fromStruct(t, descriptions)
+	s.SchemaURI = Draft
+	s.ID = FileName(stage)
+	s.Title = fmt.Sprintf("%s configuration (%s)", stage.Name, t.Name())
+	s.Description = fmt.Sprintf("YAML file of the %s stage, read from configuration/%s.", stage.Path, stage.ConfigDir)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var flagType = reflect.TypeOf(config.Flag(false))
+
+func fromType(t reflect.Type) *Schema {
+	if t == flagType {
+		return &Schema{Type: []string{"boolean", "string"}, Enum: []any{true, false, "true", "false"}}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return fromType(t.Elem())
+	case reflect.Struct:
+		return fromStruct(t, nil)
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: fromType(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: fromType(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+	// interface values accept anything.
+	return &Schema{}
+}
+
+func fromStruct(t reflect.Type, descriptions map[string]string) *Schema {
+	s := &Schema{Type: "object", Properties: &orderedProperties{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		p := fromType(f.Type)
+		p.Description = f.Tag.Get("doc")
+		if p.Description == "" && descriptions != nil {
+			key := name
+			if v := f.Tag.Get("var"); v != "" {
+				key = v
+			}
+			p.Description = descriptions[key]
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			for _, v := range strings.Split(enum, ",") {
+				p.Enum = append(p.Enum, enumValue(p.Type, v))
+			}
+		}
+		if types := f.Tag.Get("type"); types != "" {
+			// On lists the override applies to the items.
+			if p.Items != nil {
+				p.Items.Type = strings.Split(types, ",")
+			} else {
+				p.Type = strings.Split(types, ",")
+			}
+		}
+		optional := strings.Contains(opts, "omitempty")
+		if optional {
+			// Optional keys may be written with an explicit null, as in the
+			// example files (e.g. database_flags : null).
+			p.Type = withNull(p.Type)
+			if p.Enum != nil {
+				p.Enum = append(p.Enum, nil)
+			}
+		} else {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties.add(name, p)
+	}
+	return s
+}
+
+// enumValue converts an enum tag value to the JSON type of the property.
+func enumValue(t any, v string) any {
+	if t == "integer" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+func withNull(t any) any {
+	switch t := t.(type) {
+	case string:
+		return []string{t, "null"}
+	case []string:
+		return append(t, "null")
+	}
+	return t
+}
+
+// orderedProperties keeps the properties in struct field order, so that the
+// generated documents read like the example files and diff cleanly.
+type orderedProperties struct {
+	names   []string
+	schemas []*Schema
+}
+
+func (p *orderedProperties) add(name string, s *Schema) {
+	p.names = append(p.names, name)
+	p.schemas = append(p.schemas, s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p *orderedProperties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, name := range p.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := marshal(p.schemas[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshal is json.Marshal without HTML escaping, descriptions often mention
+// placeholders such as <project-id>.
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	executionDir = "../.."
+	schemasDir   = "../../../configuration/schemas"
+)
+
+// TestSchemasInSync fails when configuration/schemas was not regenerated
+// after a change to the config package or to a stage variables.tf.
+func TestSchemasInSync(t *testing.T) {
+	stale, err := Check(executionDir, schemasDir)
+	if err != nil {
+		t.Fatalf("Failed to check the schemas: %v", err)
+	}
+	if len(stale) > 0 {
+		t.Errorf("Schemas are out of date, run `go run ./cmd/config-schema` from execution/tools: %s", strings.Join(stale, ", "))
+	}
+}
+
+type testDoc struct {
+	Name     string            `yaml:"name" doc:"Name of the resource."`
+	Mode     string            `yaml:"mode,omitempty" enum:"A,B"`
+	Count    int               `yaml:"count,omitempty" enum:"2,4"`
+	Ports    []string          `yaml:"ports,omitempty" type:"string,integer"`
+	Region   string            `yaml:"region" var:"location"`
+	Enabled  config.Flag       `yaml:"enabled,omitempty"`
+	Labels   map[string]string `yaml:"labels,omitempty"`
+	internal string
+}
+
+func TestFromStruct(t *testing.T) {
+	s := fromStruct(reflect.TypeOf(testDoc{}), map[string]string{"location": "Region of the resource.", "name": "ignored"})
+	b, err := marshal(s)
+	if err != nil {
+		t.Fatalf("Failed to marshal the schema: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":    map[string]any{"type": "string", "description": "Name of the resource."},
+			"mode":    map[string]any{"type": []any{"string", "null"}, "enum": []any{"A", "B", nil}},
+			"count":   map[string]any{"type": []any{"integer", "null"}, "enum": []any{2.0, 4.0, nil}},
+			"ports":   map[string]any{"type": []any{"array", "null"}, "items": map[string]any{"type": []any{"string", "integer"}}},
+			"region":  map[string]any{"type": "string", "description": "Region of the resource."},
+			"enabled": map[string]any{"type": []any{"boolean", "string", "null"}, "enum": []any{true, false, "true", "false", nil}},
+			"labels":  map[string]any{"type": []any{"object", "null"}, "additionalProperties": map[string]any{"type": "string"}},
+		},
+		"required": []any{"name", "region"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fromStruct() mismatch.\ngot:  %s\nwant: %v", b, want)
+	}
+}
+
+func TestGenerateUntypedStage(t *testing.T) {
+	stage, _ := config.LookupStage("networking")
+	if _, err := Generate(stage, nil); err == nil {
+		t.Errorf("Expected an error for a stage without YAML schema")
+	}
+}
+
+func TestModeline(t *testing.T) {
+	stage, ok := config.LookupStage("producer/cloudsql")
+	if !ok {
+		t.Fatal("producer/cloudsql stage not found")
+	}
+	want := "# yaml-language-server: $schema=../../../schemas/producer-cloudsql.schema.json"
+	if got := Modeline(stage); got != want {
+		t.Errorf("Modeline() = %q, want %q", got, want)
+	}
+	// The modeline must resolve from the stage configuration folder.
+	target := filepath.Join("../../../configuration", stage.ConfigDir, strings.TrimPrefix(Modeline(stage), "# yaml-language-server: $schema="))
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("Modeline does not resolve: %v", err)
+	}
+}
+
+// knownInvalid lists the example files that do not match their stage.
+var knownInvalid = map[string]string{
+	"networking/ncc/config/instance-expanded.yaml.example": "the router_spoke type is not handled by the NCC stage",
+	"consumer/MIG/config/instance.yaml.example":            "inline E.g. comments are not valid YAML",
+}
+
+// TestExamplesMatchSchemas validates the example files of the repository
+// against the generated schemas, covering the required, type and enum
+// keywords the generator emits.
+func TestExamplesMatchSchemas(t *testing.T) {
+	docs, err := All(executionDir)
+	if err != nil {
+		t.Fatalf("Failed to generate the schemas: %v", err)
+	}
+	for _, stage := range config.Stages {
+		if !stage.Typed() {
+			continue
+		}
+		var s map[string]any
+		if err := json.Unmarshal(docs[FileName(stage)], &s); err != nil {
+			t.Fatal(err)
+		}
+		files, _ := filepath.Glob(filepath.Join("../../../configuration", stage.ConfigDir, "*.yaml*"))
+		for _, f := range files {
+			name := filepath.ToSlash(filepath.Join(stage.ConfigDir, filepath.Base(f)))
+			t.Run(name, func(t *testing.T) {
+				if reason, ok := knownInvalid[name]; ok {
+					t.Skip(reason)
+				}
+				b, err := os.ReadFile(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var doc any
+				if err := yaml.Unmarshal(b, &doc); err != nil {
+					t.Fatalf("Failed to parse %s: %v", f, err)
+				}
+				for _, e := range validate(s, doc, "") {
+					t.Errorf("%s: %s", f, e)
+				}
+			})
+		}
+	}
+}
+
+// validate is a minimal validator for the keywords emitted by Generate.
+// Placeholders such as <project-id> match any schema.
+func validate(s map[string]any, v any, path string) []string {
+	if str, ok := v.(string); ok && strings.HasPrefix(str, "<") && strings.HasSuffix(str, ">") {
+		return nil
+	}
+	if types := schemaTypes(s["type"]); len(types) > 0 && !slices.Contains(types, jsonType(v)) &&
+		!(jsonType(v) == "integer" && slices.Contains(types, "number")) {
+		return []string{fmt.Sprintf("%s: %s is not one of %v", path, jsonType(v), types)}
+	}
+	if enum, ok := s["enum"].([]any); ok && !slices.ContainsFunc(enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(v) }) {
+		return []string{fmt.Sprintf("%s: %v is not one of %v", path, v, enum)}
+	}
+	var errs []string
+	switch v := v.(type) {
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		required, _ := s["required"].([]any)
+		for _, r := range required {
+			if _, ok := v[r.(string)]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: missing required property %s", path, r))
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if props != nil {
+				if p, ok := props[k].(map[string]any); ok {
+					errs = append(errs, validate(p, v[k], path+"."+k)...)
+				}
+			} else if p, ok := s["additionalProperties"].(map[string]any); ok {
+				errs = append(errs, validate(p, v[k], path+"."+k)...)
+			}
+		}
+	case []any:
+		if items, ok := s["items"].(map[string]any); ok {
+			for i, e := range v {
+				errs = append(errs, validate(items, e, fmt.Sprintf("%s.%d", path, i))...)
+			}
+		}
+	}
+	return errs
+}
+
+func schemaTypes(t any) []string {
+	switch t := t.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, e := range t {
+			types = append(types, e.(string))
+		}
+		return types
+	}
+	return nil
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
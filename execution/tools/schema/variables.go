@@ -0,0 +1,60 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+var variablesSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{{Type: "variable", LabelNames: []string{"name"}}},
+}
+
+// Descriptions returns the description of every variable declared in the
+// variables.tf of a stage, executionDir being the execution/ directory.
+func Descriptions(executionDir string, stage config.Stage) (map[string]string, error) {
+	file := filepath.Join(executionDir, filepath.FromSlash(stage.Path), "variables.tf")
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	f, diags := hclparse.NewParser().ParseHCL(src, file)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	content, _, diags := f.Body.PartialContent(variablesSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	descriptions := map[string]string{}
+	for _, block := range content.Blocks {
+		attrs, _ := block.Body.JustAttributes()
+		attr, ok := attrs["description"]
+		if !ok {
+			continue
+		}
+		v, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || v.IsNull() {
+			continue
+		}
+		descriptions[block.Labels[0]] = v.AsString()
+	}
+	return descriptions, nil
+}
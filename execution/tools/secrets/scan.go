@@ -0,0 +1,297 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// IgnoreMarker silences the findings of a line, for values that look like
+// secrets but are not.
+const IgnoreMarker = "secrets:ignore"
+
+// Finding is a literal secret found in a configuration file.
+type Finding struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", f.File, f.Line, f.Key, f.Reason)
+}
+
+var (
+	// secretKey matches the names of keys expected to hold a secret.
+	secretKey = regexp.MustCompile(`(?i)(secret|password|passwd|passphrase|token|api_?key|private_?key|credentials?)`)
+	// notSecretKey matches names that refer to a secret without holding it,
+	// e.g. secret_name or encryption_key_name.
+	notSecretKey = regexp.MustCompile(`(?i)(_name|_id|_ids|_path|_file|_version|_versions|_project|_ref|_length|_ttl)$`)
+	// secretValues matches well known secret formats, whatever the key.
+	secretValues = []struct {
+		pattern *regexp.Regexp
+		reason  string
+	}{
+		{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`), "PEM private key"},
+		{regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`), "Google API key"},
+		{regexp.MustCompile(`"type"\s*:\s*"service_account"`), "service account key"},
+	}
+	placeholder = regexp.MustCompile(`^<[^<>]*>$`)
+)
+
+// Scanner reports the secrets written in plain text in configuration files.
+type Scanner struct {
+	// Schemes are the reference schemes, values using them are not secrets.
+	Schemes []string
+	// Warn, when set, receives the errors of the .example and .sample files
+	// that do not parse. Those files are templates, which often hold
+	// placeholders that are not valid YAML, so they are skipped.
+	Warn func(error)
+}
+
+// NewScanner returns a scanner accepting the references of r.
+func NewScanner(r *Resolver) *Scanner {
+	return &Scanner{Schemes: r.Schemes()}
+}
+
+// Scan walks root, a file or a directory, and scans the tfvars and YAML files
+// found, including the .example and .sample files. Files that do not parse
+// are reported in the returned error, or to Warn for the .example and
+// .sample files, and the other files are still scanned.
+func (s *Scanner) Scan(root string) ([]Finding, error) {
+	var findings []Finding
+	var errs []error
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !scanned(path) {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		f, err := s.ScanFile(path, src)
+		switch {
+		case err == nil:
+		case template(path):
+			if s.Warn != nil {
+				s.Warn(err)
+			}
+		default:
+			errs = append(errs, err)
+		}
+		findings = append(findings, f...)
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, errors.Join(errs...)
+}
+
+func scanned(path string) bool {
+	switch filepath.Ext(templateBase(path)) {
+	case ".tfvars", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// template reports whether path is an .example or .sample file.
+func template(path string) bool {
+	return templateBase(path) != path
+}
+
+// templateBase returns path without its .example or .sample extension.
+func templateBase(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(path, ".example"), ".sample")
+}
+
+// ScanFile scans a tfvars or YAML file, the format being taken from its name.
+func (s *Scanner) ScanFile(name string, src []byte) ([]Finding, error) {
+	var findings []Finding
+	report := func(line int, key, value string) {
+		if reason := s.check(key, value); reason != "" && !ignored(src, line) {
+			findings = append(findings, Finding{File: name, Line: line, Key: key, Reason: reason})
+		}
+	}
+	var err error
+	if filepath.Ext(templateBase(name)) == ".tfvars" {
+		err = scanTfvars(name, src, report)
+	} else {
+		err = scanYAML(src, report)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return findings, nil
+}
+
+// check returns why value is reported, or "" when it is not a secret.
+func (s *Scanner) check(key, value string) string {
+	v := strings.TrimSpace(value)
+	if v == "" || placeholder.MatchString(v) {
+		return ""
+	}
+	if _, ok := parseRef(v, "", s.Schemes); ok {
+		return ""
+	}
+	for _, sv := range secretValues {
+		if sv.pattern.MatchString(v) {
+			return sv.reason + " in plain text"
+		}
+	}
+	name := keyName(key)
+	if secretKey.MatchString(name) && !notSecretKey.MatchString(name) {
+		return "literal value, use an env:, file: or secretmanager: reference"
+	}
+	return ""
+}
+
+// keyName returns the nearest named segment of key, skipping the list
+// indexes, so that the items of a passwords list are checked as passwords.
+func keyName(key string) string {
+	segments := strings.Split(key, ".")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, err := strconv.Atoi(segments[i]); err != nil {
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+func ignored(src []byte, line int) bool {
+	lines := bytes.Split(src, []byte("\n"))
+	return line > 0 && line <= len(lines) && bytes.Contains(lines[line-1], []byte(IgnoreMarker))
+}
+
+type reportFunc func(line int, key, value string)
+
+func scanTfvars(name string, src []byte, report reportFunc) error {
+	file, diags := hclsyntax.ParseConfig(src, name, hcl.InitialPos)
+	if diags.HasErrors() {
+		return diags
+	}
+	body := file.Body.(*hclsyntax.Body)
+	for _, attr := range body.Attributes {
+		scanExpr(attr.Name, attr.Expr, report)
+	}
+	return nil
+}
+
+func scanExpr(key string, expr hclsyntax.Expression, report reportFunc) {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		// Numbers can be secrets too, such as PINs; booleans and null cannot.
+		if !e.Val.IsNull() && e.Val.Type().Equals(cty.Number) {
+			report(e.Range().Start.Line, key, e.Val.AsBigFloat().Text('f', -1))
+		}
+	case *hclsyntax.TemplateExpr:
+		if !e.IsStringLiteral() {
+			return
+		}
+		v, diags := e.Value(nil)
+		if !diags.HasErrors() && !v.IsNull() {
+			report(e.Range().Start.Line, key, v.AsString())
+		}
+	case *hclsyntax.TupleConsExpr:
+		for i, item := range e.Exprs {
+			scanExpr(fmt.Sprintf("%s.%d", key, i), item, report)
+		}
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range e.Items {
+			name := hcl.ExprAsKeyword(item.KeyExpr)
+			if name == "" {
+				k, diags := item.KeyExpr.Value(nil)
+				if diags.HasErrors() || k.IsNull() || !k.Type().Equals(cty.String) {
+					continue
+				}
+				name = k.AsString()
+			}
+			scanExpr(key+"."+name, item.ValueExpr, report)
+		}
+	}
+}
+
+func scanYAML(src []byte, report reportFunc) error {
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		scanNode("", &doc, report)
+	}
+}
+
+func scanNode(key string, n *yaml.Node, report reportFunc) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			scanNode(key, c, report)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i].Value
+			if key != "" {
+				k = key + "." + k
+			}
+			scanNode(k, n.Content[i+1], report)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			scanNode(fmt.Sprintf("%s.%d", key, i), c, report)
+		}
+	case yaml.ScalarNode:
+		// Numbers can be secrets too, such as PINs; booleans and null cannot.
+		switch n.Tag {
+		case "!!str", "!!int", "!!float":
+			report(n.Line, key, n.Value)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets resolves secret references in stage configuration files and
+// scans committed configuration for secrets written in plain text.
+//
+// A reference is a string value made of a provider scheme and a target:
+//
+//	env:NAME                                   the NAME environment variable
+//	file:PATH                                  the content of PATH, relative to the referencing file
+//	secretmanager:projects/P/secrets/S         the latest version of a Secret Manager secret
+//	secretmanager:projects/P/secrets/S/versions/V
+//
+// Other schemes are added by registering a Provider with the Resolver.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/command"
+)
+
+// Ref is a secret reference found in a configuration file.
+type Ref struct {
+	Scheme string
+	Target string
+	// Dir is the directory of the file holding the reference.
+	Dir string
+}
+
+func (r Ref) String() string {
+	return r.Scheme + ":" + r.Target
+}
+
+// Provider returns the value of the secrets of a scheme.
+type Provider interface {
+	Resolve(ctx context.Context, ref Ref) (string, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(ctx context.Context, ref Ref) (string, error)
+
+// Resolve implements Provider.
+func (f ProviderFunc) Resolve(ctx context.Context, ref Ref) (string, error) {
+	return f(ctx, ref)
+}
+
+// Env reads secrets from environment variables.
+var Env = ProviderFunc(func(_ context.Context, ref Ref) (string, error) {
+	v, ok := os.LookupEnv(ref.Target)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref.Target)
+	}
+	return v, nil
+})
+
+// File reads secrets from files. A single trailing newline is removed, as
+// most editors add one.
+var File = ProviderFunc(func(_ context.Context, ref Ref) (string, error) {
+	path := ref.Target
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ref.Dir, path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r"), nil
+})
+
+// Command is a provider backed by an external command, which receives the
+// reference target as its last argument and prints the secret on its
+// standard output.
+type Command struct {
+	Name string
+	Args []string
+	Run  command.Runner
+}
+
+// Resolve implements Provider.
+func (c *Command) Resolve(ctx context.Context, ref Ref) (string, error) {
+	run := c.Run
+	if run == nil {
+		run = command.Exec
+	}
+	out, err := run(ctx, "", c.Name, append(append([]string{}, c.Args...), ref.Target)...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// SecretManager reads secrets from Secret Manager with the gcloud CLI, using
+// the credentials the stages are applied with.
+type SecretManager struct {
+	Run command.Runner
+}
+
+// Resolve implements Provider.
+func (s *SecretManager) Resolve(ctx context.Context, ref Ref) (string, error) {
+	parts := strings.Split(ref.Target, "/")
+	version := "latest"
+	switch {
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions":
+		version = parts[5]
+	case len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets":
+	default:
+		return "", fmt.Errorf("invalid secret %q, expected projects/<project>/secrets/<secret>[/versions/<version>]", ref.Target)
+	}
+	run := s.Run
+	if run == nil {
+		run = command.Exec
+	}
+	out, err := run(ctx, "", "gcloud", "secrets", "versions", "access", version, "--secret="+parts[3], "--project="+parts[1])
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Resolver replaces secret references with their value.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver with the env, file and secretmanager
+// providers.
+func NewResolver() *Resolver {
+	r := &Resolver{providers: map[string]Provider{}}
+	r.Register("env", Env)
+	r.Register("file", File)
+	r.Register("secretmanager", &SecretManager{})
+	return r
+}
+
+// Register adds or replaces the provider of a scheme.
+func (r *Resolver) Register(scheme string, p Provider) {
+	r.providers[scheme] = p
+}
+
+// Schemes returns the registered schemes, sorted.
+func (r *Resolver) Schemes() []string {
+	schemes := make([]string, 0, len(r.providers))
+	for s := range r.providers {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Parse returns the reference held by s, if s is a reference to a registered
+// scheme.
+func (r *Resolver) Parse(s, dir string) (Ref, bool) {
+	return parseRef(s, dir, r.Schemes())
+}
+
+func parseRef(s, dir string, schemes []string) (Ref, bool) {
+	scheme, target, ok := strings.Cut(s, ":")
+	if !ok || target == "" || strings.ContainsAny(s, " \t\n") {
+		return Ref{}, false
+	}
+	for _, known := range schemes {
+		if scheme == known {
+			return Ref{Scheme: scheme, Target: target, Dir: dir}, true
+		}
+	}
+	return Ref{}, false
+}
+
+// Resolve returns the value of s, resolved when it is a reference. The
+// returned flag reports whether s was a reference.
+func (r *Resolver) Resolve(ctx context.Context, s, dir string) (string, bool, error) {
+	ref, ok := r.Parse(s, dir)
+	if !ok {
+		return s, false, nil
+	}
+	v, err := r.providers[ref.Scheme].Resolve(ctx, ref)
+	if err != nil {
+		return "", true, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	return v, true, nil
+}
+
+// Value resolves the references held in a plain Go value, as returned by
+// config.ParseTfvars, and returns the number of references found. Maps and
+// slices are copied, v is left untouched.
+func (r *Resolver) Value(ctx context.Context, v any, dir string) (any, int, error) {
+	switch v := v.(type) {
+	case string:
+		s, ok, err := r.Resolve(ctx, v, dir)
+		if !ok || err != nil {
+			return v, 0, err
+		}
+		return s, 1, nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		total := 0
+		for k, e := range v {
+			resolved, n, err := r.Value(ctx, e, dir)
+			if err != nil {
+				return nil, 0, err
+			}
+			out[k] = resolved
+			total += n
+		}
+		return out, total, nil
+	case []any:
+		out := make([]any, len(v))
+		total := 0
+		for i, e := range v {
+			resolved, n, err := r.Value(ctx, e, dir)
+			if err != nil {
+				return nil, 0, err
+			}
+			out[i] = resolved
+			total += n
+		}
+		return out, total, nil
+	}
+	return v, 0, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+)
+
+// fakeRunner records the commands run and returns a canned output.
+type fakeRunner struct {
+	calls  [][]string
+	output string
+}
+
+func (f *fakeRunner) run(_ context.Context, _, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, append([]string{name}, args...))
+	return []byte(f.output), nil
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tunnel.secret"), []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VPN_SECRET", "from-env")
+	sm := &fakeRunner{output: "from-secret-manager"}
+	r := NewResolver()
+	r.Register("secretmanager", &SecretManager{Run: sm.run})
+	vault := &fakeRunner{output: "from-vault\n"}
+	r.Register("vault", &Command{Name: "vault", Args: []string{"kv", "get", "-field=value"}, Run: vault.run})
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    string
+		wantRef bool
+		wantErr string
+	}{
+		{name: "Literal", input: "secret1", want: "secret1"},
+		{name: "UnknownScheme", input: "https://example.com", want: "https://example.com"},
+		{name: "WithSpaces", input: "env: not a reference", want: "env: not a reference"},
+		{name: "Env", input: "env:VPN_SECRET", want: "from-env", wantRef: true},
+		{name: "EnvUnset", input: "env:UNSET_VPN_SECRET", wantRef: true, wantErr: "UNSET_VPN_SECRET is not set"},
+		{name: "File", input: "file:tunnel.secret", want: "from-file", wantRef: true},
+		{name: "SecretManager", input: "secretmanager:projects/p1/secrets/s1", want: "from-secret-manager", wantRef: true},
+		{name: "SecretManagerInvalid", input: "secretmanager:s1", wantRef: true, wantErr: "expected projects/<project>/secrets/<secret>"},
+		{name: "Command", input: "vault:kv/vpn", want: "from-vault", wantRef: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ref, err := r.Resolve(context.Background(), tc.input, dir)
+			if ref != tc.wantRef {
+				t.Errorf("Resolve(%q) reference = %v, want %v", tc.input, ref, tc.wantRef)
+			}
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Resolve(%q) error = %v, want it to contain %q", tc.input, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) failed: %v", tc.input, err)
+			}
+			if got != tc.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+
+	wantCalls := [][]string{{"gcloud", "secrets", "versions", "access", "latest", "--secret=s1", "--project=p1"}}
+	if !reflect.DeepEqual(sm.calls, wantCalls) {
+		t.Errorf("Secret Manager commands = %q, want %q", sm.calls, wantCalls)
+	}
+	wantCalls = [][]string{{"vault", "kv", "get", "-field=value", "kv/vpn"}}
+	if !reflect.DeepEqual(vault.calls, wantCalls) {
+		t.Errorf("Command provider commands = %q, want %q", vault.calls, wantCalls)
+	}
+}
+
+func TestResolveStage(t *testing.T) {
+	root := t.TempDir()
+	stageDir := filepath.Join(root, "execution", "04-producer", "CloudSQL")
+	configDir := filepath.Join(root, "configuration", "producer", "CloudSQL", "config")
+	for _, d := range []string{stageDir, configDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		"configuration/producer/CloudSQL/cloudsql.tfvars": `config_folder_path = "../../../configuration/producer/CloudSQL/config/"
+root_password      = "env:ROOT_PASSWORD"
+`,
+		"configuration/producer/CloudSQL/config/sql-1.yaml": `name: sql-1 # primary
+users:
+  admin:
+    password: env:ADMIN_PASSWORD
+`,
+		"configuration/producer/CloudSQL/config/sql-2.yaml":    "name: sql-2\n",
+		"configuration/producer/CloudSQL/config/_ignored.yaml": "password: env:UNSET_PASSWORD\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("ROOT_PASSWORD", `r"o${o}t`)
+	t.Setenv("ADMIN_PASSWORD", "adm: in")
+
+	o, err := ResolveStage(context.Background(), NewResolver(), stageDir, filepath.Join(root, "configuration/producer/CloudSQL/cloudsql.tfvars"))
+	if err != nil {
+		t.Fatalf("ResolveStage() failed: %v", err)
+	}
+	if o.Refs != 2 {
+		t.Errorf("Refs = %d, want 2", o.Refs)
+	}
+	yamlDir := t.TempDir()
+	out, err := o.WriteTfvars(yamlDir)
+	if err != nil {
+		t.Fatalf("WriteTfvars() failed: %v", err)
+	}
+	vars, err := config.ParseTfvars("overrides.tfvars", out)
+	if err != nil {
+		t.Fatalf("Generated tfvars do not parse: %v\n%s", err, out)
+	}
+	wantVars := map[string]any{"config_folder_path": yamlDir, "root_password": `r"o${o}t`}
+	if !reflect.DeepEqual(vars, wantVars) {
+		t.Errorf("Overrides = %v, want %v", vars, wantVars)
+	}
+
+	got, err := os.ReadFile(filepath.Join(yamlDir, "sql-1.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name: sql-1 # primary\nusers:\n  admin:\n    password: \"adm: in\"\n"
+	if string(got) != want {
+		t.Errorf("Resolved sql-1.yaml = %q, want %q", got, want)
+	}
+	// Files without references are copied as is, ignored files are left out.
+	if got, err := os.ReadFile(filepath.Join(yamlDir, "sql-2.yaml")); err != nil || string(got) != "name: sql-2\n" {
+		t.Errorf("sql-2.yaml = %q, %v, want it copied unchanged", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(yamlDir, "_ignored.yaml")); err == nil {
+		t.Errorf("_ignored.yaml should not be copied")
+	}
+
+	if _, err := o.WriteTfvars(""); err == nil {
+		t.Errorf("Expected an error without a directory for the resolved YAML files")
+	}
+}
+
+func TestResolveStageWithoutReferences(t *testing.T) {
+	tfvars := filepath.Join(t.TempDir(), "networking.tfvars")
+	if err := os.WriteFile(tfvars, []byte("tunnel_1_shared_secret = \"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o, err := ResolveStage(context.Background(), NewResolver(), ".", tfvars)
+	if err != nil {
+		t.Fatalf("ResolveStage() failed: %v", err)
+	}
+	if !o.Empty() {
+		t.Errorf("Expected no reference, got %d", o.Refs)
+	}
+}
+
+func TestScan(t *testing.T) {
+	scanner := NewScanner(NewResolver())
+	var warnings []string
+	scanner.Warn = func(err error) { warnings = append(warnings, err.Error()) }
+	findings, err := scanner.Scan("testdata")
+	if err != nil {
+		t.Fatalf("Scan() failed: %v", err)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "testdata/instance.yaml.example: ") {
+		t.Errorf("Scan() warnings = %q, want the invalid instance.yaml.example", warnings)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	want := []string{
+		"testdata/alloydb.yaml.example:6: cluster_initial_user.password: literal value, use an env:, file: or secretmanager: reference",
+		"testdata/alloydb.yaml.example:9: replica_passwords.0: literal value, use an env:, file: or secretmanager: reference",
+		"testdata/alloydb.yaml.example:11: database_password: literal value, use an env:, file: or secretmanager: reference",
+		"testdata/networking.tfvars:2: tunnel_1_shared_secret: literal value, use an env:, file: or secretmanager: reference",
+		"testdata/networking.tfvars:8: users.admin.password: literal value, use an env:, file: or secretmanager: reference",
+		"testdata/networking.tfvars:12: certificate: PEM private key in plain text",
+		"testdata/networking.tfvars:14: vpn_tokens.0: literal value, use an env:, file: or secretmanager: reference",
+		"testdata/networking.tfvars:15: db_password: literal value, use an env:, file: or secretmanager: reference",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() findings mismatch.\ngot:  %q\nwant: %q", got, want)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "instance.yaml"), []byte("zone : <zone> E.g. : us-central1-a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := scanner.Scan(dir); err == nil {
+		t.Error("Scan() of an invalid YAML file returned no error")
+	}
+}
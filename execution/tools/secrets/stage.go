@@ -0,0 +1,204 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/cloudnetworking-config-solutions/tools/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Overrides holds the resolved configuration of a stage, for the references
+// found in its tfvars file and in the YAML files of its config folder.
+type Overrides struct {
+	// Vars are the tfvars attributes holding references, with their
+	// references resolved.
+	Vars map[string]any
+	// Documents are the YAML files of the config folder, by file name, with
+	// their references resolved. It is empty when no YAML file holds a
+	// reference.
+	Documents map[string][]byte
+	// Refs counts the resolved references.
+	Refs int
+}
+
+// Empty reports whether the stage holds no reference.
+func (o *Overrides) Empty() bool {
+	return o.Refs == 0
+}
+
+// ResolveStage resolves the references of a stage. stageDir is the directory
+// Terraform runs from, config_folder_path being relative to it.
+func ResolveStage(ctx context.Context, r *Resolver, stageDir, tfvarsPath string) (*Overrides, error) {
+	src, err := os.ReadFile(tfvarsPath)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := config.ParseTfvars(tfvarsPath, src)
+	if err != nil {
+		return nil, err
+	}
+	o := &Overrides{Vars: map[string]any{}, Documents: map[string][]byte{}}
+	dir := filepath.Dir(tfvarsPath)
+	for name, v := range vars {
+		resolved, n, err := r.Value(ctx, v, dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", tfvarsPath, name, err)
+		}
+		if n > 0 {
+			o.Vars[name] = resolved
+			o.Refs += n
+		}
+	}
+
+	folder, _ := vars["config_folder_path"].(string)
+	if folder == "" {
+		return o, nil
+	}
+	if !filepath.IsAbs(folder) {
+		folder = filepath.Join(stageDir, folder)
+	}
+	// Same selection as the stages: fileset(path, "[^_]*.yaml").
+	files, err := filepath.Glob(filepath.Join(folder, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	docs := map[string][]byte{}
+	refs := 0
+	for _, f := range files {
+		if strings.HasPrefix(filepath.Base(f), "_") {
+			continue
+		}
+		src, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		resolved, n, err := resolveYAML(ctx, r, src, folder)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		docs[filepath.Base(f)] = resolved
+		refs += n
+	}
+	if refs > 0 {
+		o.Documents = docs
+		o.Refs += refs
+	}
+	return o, nil
+}
+
+// resolveYAML resolves the string scalars of a YAML file, keeping its layout
+// and comments. Files without reference are returned unchanged.
+func resolveYAML(ctx context.Context, r *Resolver, src []byte, dir string) ([]byte, int, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, 0, err
+		}
+		docs = append(docs, &doc)
+	}
+	refs := 0
+	var walk func(n *yaml.Node) error
+	walk = func(n *yaml.Node) error {
+		if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+			v, ok, err := r.Resolve(ctx, n.Value, dir)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n.Line, err)
+			}
+			if ok {
+				n.Value = v
+				n.Style = yaml.DoubleQuotedStyle
+				refs++
+			}
+			return nil
+		}
+		for i, c := range n.Content {
+			// Mapping keys are never references.
+			if n.Kind == yaml.MappingNode && i%2 == 0 {
+				continue
+			}
+			if err := walk(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, doc := range docs {
+		if err := walk(doc); err != nil {
+			return nil, 0, err
+		}
+	}
+	if refs == 0 {
+		return src, 0, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, 0, err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), refs, nil
+}
+
+// WriteTfvars renders the overrides as a tfvars file, to be passed to
+// Terraform after the stage tfvars file so that it takes precedence. When
+// YAML files hold references, they are written to yamlDir, which must be
+// private to the run and should live on an in-memory file system, and
+// config_folder_path is pointed at it.
+func (o *Overrides) WriteTfvars(yamlDir string) ([]byte, error) {
+	vars := make(map[string]any, len(o.Vars)+1)
+	for k, v := range o.Vars {
+		vars[k] = v
+	}
+	if len(o.Documents) > 0 {
+		if yamlDir == "" {
+			return nil, fmt.Errorf("the stage YAML files hold secret references, a directory for the resolved files is required")
+		}
+		names := make([]string, 0, len(o.Documents))
+		for name := range o.Documents {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(yamlDir, name), o.Documents[name], 0600); err != nil {
+				return nil, err
+			}
+		}
+		vars["config_folder_path"] = yamlDir
+	}
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	return config.EncodeTfvars(vars)
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tfplan reads the JSON representation of a Terraform plan, the
+// output of terraform show -json PLANFILE, for the checks run on the plan of
+// a stage before it is applied.
+package tfplan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Plan is the part of the JSON representation of a plan the checks read.
+type Plan struct {
+	FormatVersion   string           `json:"format_version"`
+	ResourceChanges []ResourceChange `json:"resource_changes"`
+	// ResourceDrift are the changes made to resources outside of Terraform,
+	// found when refreshing the state.
+	ResourceDrift []ResourceChange `json:"resource_drift"`
+}
+
+// ResourceChange is a planned change of a resource instance.
+type ResourceChange struct {
+	Address       string `json:"address"`
+	ModuleAddress string `json:"module_address"`
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	ProviderName  string `json:"provider_name"`
+	Change        Change `json:"change"`
+}
+
+// Change holds the actions and the values before and after a change. Values
+// unknown until apply are missing from After and true in AfterUnknown.
+type Change struct {
+	Actions      []string `json:"actions"`
+	Before       any      `json:"before"`
+	After        any      `json:"after"`
+	AfterUnknown any      `json:"after_unknown"`
+}
+
+// Read decodes the JSON representation of a plan.
+func Read(r io.Reader) (*Plan, error) {
+	var p Plan
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, fmt.Errorf("decoding plan: %w", err)
+	}
+	if p.FormatVersion == "" {
+		return nil, errors.New("decoding plan: no format_version, not the output of terraform show -json")
+	}
+	return &p, nil
+}
+
+// ReadFile decodes the JSON representation of a plan from a file, or from
+// the standard input when file is "-".
+func ReadFile(file string) (*Plan, error) {
+	if file == "-" {
+		return Read(os.Stdin)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return p, nil
+}
+
+// Managed reports whether the change is of a managed resource, not a data
+// source.
+func (c ResourceChange) Managed() bool {
+	return c.Mode == "" || c.Mode == "managed"
+}
+
+// Has reports whether the change has one of the actions: create, read,
+// update, delete or no-op. A replacement is a delete and a create.
+func (c ResourceChange) Has(actions ...string) bool {
+	return slices.ContainsFunc(c.Change.Actions, func(a string) bool { return slices.Contains(actions, a) })
+}
+
+// Get returns the value at path in v, a decoded JSON value, following object
+// keys (strings) and list indexes (ints). It returns false when the path
+// does not exist or leads to null.
+func Get(v any, path ...any) (any, bool) {
+	for _, p := range path {
+		switch k := p.(type) {
+		case string:
+			m, ok := v.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			v = m[k]
+		case int:
+			l, ok := v.([]any)
+			if !ok || k < 0 || k >= len(l) {
+				return nil, false
+			}
+			v = l[k]
+		default:
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
+
+// String returns the string at path in v.
+func String(v any, path ...any) (string, bool) {
+	x, _ := Get(v, path...)
+	s, ok := x.(string)
+	return s, ok
+}
+
+// Number returns the number at path in v.
+func Number(v any, path ...any) (float64, bool) {
+	x, _ := Get(v, path...)
+	f, ok := x.(float64)
+	return f, ok
+}
+
+// List returns the list at path in v, nil when there is none.
+func List(v any, path ...any) []any {
+	x, _ := Get(v, path...)
+	l, _ := x.([]any)
+	return l
+}
+
+var zoneSuffix = regexp.MustCompile(`^([a-z]+-[a-z]+[0-9]+)-[a-z]$`)
+
+// Region returns the region of a planned resource, from its region, zone or
+// location attribute, e.g. us-central1 for the zone us-central1-a. It is
+// empty when the resource has none, or when it is unknown until apply, e.g.
+// taken from the provider default.
+func Region(after any) string {
+	for _, attr := range []string{"region", "zone", "location"} {
+		v, ok := String(after, attr)
+		if !ok || v == "" {
+			continue
+		}
+		v = v[strings.LastIndex(v, "/")+1:]
+		if m := zoneSuffix.FindStringSubmatch(v); m != nil {
+			return m[1]
+		}
+		return strings.ToLower(v)
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfplan
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const plan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "module.vm[\"vm-1\"].google_compute_instance.default[0]",
+      "module_address": "module.vm[\"vm-1\"]",
+      "mode": "managed",
+      "type": "google_compute_instance",
+      "name": "default",
+      "change": {
+        "actions": ["delete", "create"],
+        "after": {
+          "zone": "us-central1-a",
+          "machine_type": "e2-medium",
+          "boot_disk": [{"initialize_params": [{"size": 20, "type": null}]}]
+        }
+      }
+    },
+    {
+      "address": "data.google_compute_network.vpc",
+      "mode": "data",
+      "type": "google_compute_network",
+      "name": "vpc",
+      "change": {"actions": ["read"], "after": {}}
+    }
+  ]
+}`
+
+func TestRead(t *testing.T) {
+	p, err := Read(strings.NewReader(plan))
+	if err != nil {
+		t.Fatalf("Failed to read the plan: %v", err)
+	}
+	if len(p.ResourceChanges) != 2 {
+		t.Fatalf("Failed to read the plan: got %d resource changes", len(p.ResourceChanges))
+	}
+	vm, data := p.ResourceChanges[0], p.ResourceChanges[1]
+	if vm.ModuleAddress != `module.vm["vm-1"]` || !reflect.DeepEqual(vm.Change.Actions, []string{"delete", "create"}) {
+		t.Errorf("Failed to read the change: got %+v", vm)
+	}
+	if !vm.Managed() || data.Managed() {
+		t.Error("Failed to tell managed resources from data sources")
+	}
+	if !vm.Has("create", "update") || vm.Has("update", "no-op") {
+		t.Error("Failed to match the actions")
+	}
+	for _, in := range []string{`{"resource_changes": []}`, `{`} {
+		if _, err := Read(strings.NewReader(in)); err == nil {
+			t.Errorf("Failed to reject %s", in)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	p, err := Read(strings.NewReader(plan))
+	if err != nil {
+		t.Fatalf("Failed to read the plan: %v", err)
+	}
+	after := p.ResourceChanges[0].Change.After
+	if v, ok := String(after, "machine_type"); !ok || v != "e2-medium" {
+		t.Errorf("Failed to get the machine type: got %q, %v", v, ok)
+	}
+	if v, ok := Number(after, "boot_disk", 0, "initialize_params", 0, "size"); !ok || v != 20 {
+		t.Errorf("Failed to get the disk size: got %v, %v", v, ok)
+	}
+	for _, path := range [][]any{
+		{"boot_disk", 0, "initialize_params", 0, "type"},
+		{"boot_disk", 1},
+		{"machine_type", "name"},
+		{"missing"},
+	} {
+		if v, ok := Get(after, path...); ok {
+			t.Errorf("Failed to reject the path %v: got %v", path, v)
+		}
+	}
+	if l := List(after, "boot_disk"); len(l) != 1 {
+		t.Errorf("Failed to get the boot disks: got %v", l)
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := []struct {
+		after any
+		want  string
+	}{
+		{map[string]any{"region": "europe-west1"}, "europe-west1"},
+		{map[string]any{"region": nil, "zone": "us-central1-a"}, "us-central1"},
+		{map[string]any{"zone": "projects/p/zones/asia-south1-c"}, "asia-south1"},
+		{map[string]any{"location": "US"}, "us"},
+		{map[string]any{"location": "us-east4-b"}, "us-east4"},
+		{map[string]any{"name": "global-rule"}, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := Region(tt.after); got != tt.want {
+			t.Errorf("Failed to get the region of %v: got %q, want %q", tt.after, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Summary is the coverage of the rules of a directory, or of every rule.
+type Summary struct {
+	Dir     string `json:"dir,omitempty"`
+	Rules   int    `json:"rules"`
+	Covered int    `json:"covered"`
+	// Coverage is the percentage of covered rules, 100 without rules.
+	Coverage float64 `json:"coverage"`
+}
+
+func (s *Summary) add(r Rule) {
+	s.Rules++
+	if r.Covered() {
+		s.Covered++
+	}
+	s.Coverage = 100
+	if s.Rules > 0 {
+		s.Coverage = 100 * float64(s.Covered) / float64(s.Rules)
+	}
+}
+
+// Report is the coverage of the validation rules by directory.
+type Report struct {
+	Total Summary   `json:"total"`
+	Dirs  []Summary `json:"dirs"`
+	Rules []Rule    `json:"rules"`
+}
+
+// NewReport summarizes the coverage of rules, after Match.
+func NewReport(rules []Rule) *Report {
+	r := &Report{Total: Summary{Coverage: 100}, Rules: rules}
+	dirs := map[string]*Summary{}
+	for _, rule := range rules {
+		s := dirs[rule.Dir]
+		if s == nil {
+			s = &Summary{Dir: rule.Dir}
+			dirs[rule.Dir] = s
+		}
+		s.add(rule)
+		r.Total.add(rule)
+	}
+	for _, s := range dirs {
+		r.Dirs = append(r.Dirs, *s)
+	}
+	sort.Slice(r.Dirs, func(i, j int) bool { return r.Dirs[i].Dir < r.Dirs[j].Dir })
+	if r.Rules == nil {
+		r.Rules = []Rule{}
+	}
+	if r.Dirs == nil {
+		r.Dirs = []Summary{}
+	}
+	return r
+}
+
+// Check returns the reasons the coverage is below min in total, or below
+// minDir in a directory.
+func (r *Report) Check(min, minDir float64) []string {
+	var failures []string
+	if r.Total.Coverage < min {
+		failures = append(failures, fmt.Sprintf("total coverage %.1f%% is below %.1f%%", r.Total.Coverage, min))
+	}
+	for _, s := range r.Dirs {
+		if s.Coverage < minDir {
+			failures = append(failures, fmt.Sprintf("%s: coverage %.1f%% is below %.1f%%", s.Dir, s.Coverage, minDir))
+		}
+	}
+	return failures
+}
+
+// WriteText prints the coverage of every directory and the total, followed
+// by the uncovered rules when uncovered is set.
+func (r *Report) WriteText(w io.Writer, uncovered bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DIRECTORY\tRULES\tCOVERED\tCOVERAGE")
+	for _, s := range append(r.Dirs, Summary{Dir: "TOTAL", Rules: r.Total.Rules, Covered: r.Total.Covered, Coverage: r.Total.Coverage}) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\n", s.Dir, s.Rules, s.Covered, s.Coverage)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if !uncovered {
+		return nil
+	}
+	header := false
+	for _, rule := range r.Rules {
+		if rule.Covered() {
+			continue
+		}
+		if !header {
+			if _, err := fmt.Fprintln(w, "\nUncovered rules:"); err != nil {
+				return err
+			}
+			header = true
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d: variable %s: %s\n", rule.File, rule.Line, rule.Variable, collapse(rule.ErrorMessage)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON prints the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is an error of a Terraform output.
+type Diagnostic struct {
+	// Summary is the first line of the diagnostic, e.g. "Invalid value for
+	// variable", and Detail the following lines, with their white space
+	// collapsed.
+	Summary string `json:"summary"`
+	Detail  string `json:"detail,omitempty"`
+	// RuleFile and RuleLine locate the validation rule which reported the
+	// diagnostic, when Terraform names it. RuleFile is relative to the
+	// directory Terraform ran in.
+	RuleFile string `json:"rule_file,omitempty"`
+	RuleLine int    `json:"rule_line,omitempty"`
+	// Test is the test whose output holds the diagnostic, and Stage the
+	// directory of the stage it planned, as logged by the workspace package
+	// of the integration test utilities, empty when the log does not say.
+	Test  string `json:"test,omitempty"`
+	Stage string `json:"stage,omitempty"`
+}
+
+// InvalidValue is the summary of the diagnostics of validation rules.
+const InvalidValue = "Invalid value for variable"
+
+var (
+	// logPrefix is the prefix of the lines terratest logs, e.g.
+	// "TestPlan 2025-01-02T15:04:05Z logger.go:66: ", and of the lines a
+	// test logs, e.g. "    unit_test.go:42: ".
+	logPrefix = regexp.MustCompile(`^\s*(\S+ \d{4}-\d\d-\d\dT\S+ )?[\w.-]+\.go:\d+: ?`)
+	checkedBy = regexp.MustCompile(`checked by the validation rule at (\S+?):(\d+),`)
+	// testEvent starts the lines go test prints about a test, which end the
+	// diagnostic being read, testName the lines naming the test whose output
+	// follows, and packageEnd the lines ending the output of a package.
+	testEvent  = regexp.MustCompile(`^\s*(=== |--- |PASS$|FAIL\b|ok\s)`)
+	testName   = regexp.MustCompile(`^=== (?:RUN|CONT|NAME|PAUSE)\s+(\S+)`)
+	packageEnd = regexp.MustCompile(`^(ok|FAIL)\s+\S+`)
+	// terratestTest is the test name of the prefix of the terratest logger.
+	terratestTest = regexp.MustCompile(`^(\S+) \d{4}-\d\d-\d\dT\S+ [\w.-]+\.go:\d+:`)
+	// stageCopied matches the line workspace.New logs for the stage a test
+	// plans in a copy.
+	stageCopied = regexp.MustCompile(`^Copied stage (\S+) to `)
+)
+
+// ParseDiagnostics returns the errors of the Terraform outputs in a log,
+// with or without the box drawing of recent versions and the prefix of the
+// terratest logger, along with the test and stage of each one.
+func ParseDiagnostics(r io.Reader) ([]Diagnostic, error) {
+	var diags []Diagnostic
+	var current *Diagnostic
+	var detail []string
+	// test is the test whose output is being read, and stages the stages
+	// the tests of the package being read planned. Packages may share test
+	// names, so stages is reset at the end of every package.
+	var test string
+	stages := map[string]string{}
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Stage = stageOf(stages, current.Test)
+		current.Detail = strings.Join(detail, " ")
+		if m := checkedBy.FindStringSubmatch(current.Detail); m != nil {
+			current.RuleFile = m[1]
+			current.RuleLine, _ = strconv.Atoi(m[2])
+		}
+		diags = append(diags, *current)
+		current, detail = nil, nil
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		raw := s.Text()
+		if testEvent.MatchString(raw) {
+			flush()
+			if m := testName.FindStringSubmatch(raw); m != nil {
+				test = m[1]
+			} else if packageEnd.MatchString(raw) {
+				test, stages = "", map[string]string{}
+			}
+			continue
+		}
+		if m := terratestTest.FindStringSubmatch(raw); m != nil {
+			test = m[1]
+		}
+		line := logPrefix.ReplaceAllString(raw, "")
+		if m := stageCopied.FindStringSubmatch(line); m != nil && test != "" {
+			stages[test] = filepath.ToSlash(m[1])
+			continue
+		}
+		end := strings.HasPrefix(strings.TrimSpace(line), "╵")
+		line = collapse(strings.TrimLeft(line, "│╷╵ \t"))
+		switch {
+		case strings.HasPrefix(line, "Error: "):
+			flush()
+			current = &Diagnostic{Summary: strings.TrimPrefix(line, "Error: "), Test: test}
+		case strings.HasPrefix(line, "Warning: "), end:
+			flush()
+		case current != nil && line != "":
+			detail = append(detail, line)
+		}
+	}
+	flush()
+	return diags, s.Err()
+}
+
+// stageOf returns the stage planned by a test or, for a subtest, by its
+// closest parent test which logged one.
+func stageOf(stages map[string]string, test string) string {
+	for test != "" {
+		if stage, ok := stages[test]; ok {
+			return stage
+		}
+		i := strings.LastIndex(test, "/")
+		if i < 0 {
+			break
+		}
+		test = test[:i]
+	}
+	return ""
+}
+
+// Match adds to the hits of the rules the diagnostics each one reported. A
+// diagnostic naming its rule location is matched by location, resolved
+// against the directory of the stage of its test when the log names it, and
+// by the error message otherwise. A diagnostic matching several rules, such
+// as one naming variables.tf:25 in a log without stages, is credited to none
+// of them, as it cannot tell which one reported it.
+func Match(rules []Rule, diags []Diagnostic) {
+	for _, d := range diags {
+		if d.Summary != InvalidValue {
+			continue
+		}
+		var matched []int
+		switch {
+		case d.RuleFile != "" && d.Stage != "":
+			file := path.Join(stageDir(d.Stage), d.RuleFile)
+			for i, r := range rules {
+				if r.Line == d.RuleLine && r.File == file {
+					matched = append(matched, i)
+				}
+			}
+		case d.RuleFile != "":
+			for i, r := range rules {
+				if r.Line == d.RuleLine && sameFile(r.File, d.RuleFile) {
+					matched = append(matched, i)
+				}
+			}
+			if len(matched) > 1 {
+				matched = filter(matched, func(i int) bool { return rules[i].reported(d) })
+			}
+		default:
+			for i, r := range rules {
+				if r.reported(d) {
+					matched = append(matched, i)
+				}
+			}
+			if len(matched) > 1 && d.Stage != "" {
+				inStage := filter(matched, func(i int) bool { return rules[i].Dir == stageDir(d.Stage) })
+				if len(inStage) > 0 {
+					matched = inStage
+				}
+			}
+		}
+		if len(matched) == 1 {
+			rules[matched[0]].Hits++
+		}
+	}
+}
+
+// stageDir returns the path of a stage directory relative to the
+// repository root, from the last execution directory of its path, such as
+// execution/04-producer/CloudSQL.
+func stageDir(stage string) string {
+	if i := strings.LastIndex(stage, "/execution/"); i >= 0 {
+		return stage[i+1:]
+	}
+	return stage
+}
+
+// filter returns the indexes of rules keep is true for.
+func filter(indexes []int, keep func(int) bool) []int {
+	var out []int
+	for _, i := range indexes {
+		if keep(i) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// sameFile reports whether file, relative to the repository root, may be
+// the file a diagnostic names relative to the directory Terraform ran in,
+// e.g. ../../modules/net-vpc/variables.tf, when the log does not name the
+// directory.
+func sameFile(file, name string) bool {
+	name = path.Clean(name)
+	for strings.HasPrefix(name, "../") {
+		name = strings.TrimPrefix(name, "../")
+	}
+	return file == name || strings.HasSuffix(file, "/"+name)
+}
+
+// reported reports whether the detail of a diagnostic holds the literal
+// parts of the error message of the rule, in order.
+func (r Rule) reported(d Diagnostic) bool {
+	if len(r.message) == 0 {
+		return false
+	}
+	detail := d.Detail
+	for _, part := range r.message {
+		i := strings.Index(detail, part)
+		if i < 0 {
+			return false
+		}
+		detail = detail[i+len(part):]
+	}
+	return true
+}
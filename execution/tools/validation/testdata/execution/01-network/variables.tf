@@ -0,0 +1,20 @@
+variable "project_id" {
+  type = string
+}
+
+variable "region" {
+  type = string
+  validation {
+    condition     = can(regex("^[a-z]+-[a-z]+[0-9]$", var.region))
+    error_message = "The region must be a Google Cloud region, e.g. us-central1."
+  }
+}
+
+variable "routing_mode" {
+  type    = string
+  default = "GLOBAL"
+  validation {
+    condition     = contains(["GLOBAL", "REGIONAL"], var.routing_mode)
+    error_message = "The routing mode must be GLOBAL or REGIONAL, not ${var.routing_mode}."
+  }
+}
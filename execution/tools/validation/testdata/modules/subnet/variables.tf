@@ -0,0 +1,7 @@
+variable "ip_cidr_range" {
+  type = string
+  validation {
+    condition     = can(cidrhost(var.ip_cidr_range, 0))
+    error_message = "The IP CIDR range must be a valid IPv4 CIDR range."
+  }
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package validation measures which variable validation rules of the stages
+// and modules the unit tests exercise. It reads the validation blocks of
+// every variables.tf file, and matches them against the Terraform
+// diagnostics of a unit test log, such as the output of
+//
+//	go test -v ./... 2>&1 | tee unit.log
+//
+// run from execution/test/unit. A rule is covered when a diagnostic of the
+// log was reported by it: Terraform names the rule location in the
+// diagnostic ("This was checked by the validation rule at
+// variables.tf:25,3-13."), and older versions print its error message.
+package validation
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Rule is a validation block of a variable.
+type Rule struct {
+	// Dir is the stage or module directory declaring the variable, relative
+	// to the repository root, e.g. execution/04-producer/CloudSQL.
+	Dir string `json:"dir"`
+	// File is the file declaring the variable, relative to the repository
+	// root, and Line the line of the validation block.
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Variable string `json:"variable"`
+	// Condition and ErrorMessage are the source text of the expressions.
+	Condition    string `json:"condition"`
+	ErrorMessage string `json:"error_message"`
+	// Hits is the number of diagnostics of the log reported by the rule.
+	Hits int `json:"hits"`
+
+	// message holds the literal parts of the error message, in order, which
+	// a diagnostic without the rule location must contain.
+	message []string
+}
+
+// Covered reports whether a diagnostic of the log was reported by the rule.
+func (r Rule) Covered() bool {
+	return r.Hits > 0
+}
+
+var (
+	variablesSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "variable", LabelNames: []string{"name"}}},
+	}
+	variableSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "validation"}},
+	}
+	ruleSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "condition"}, {Name: "error_message"}},
+	}
+)
+
+// Load returns the validation rules of the variables.tf files found under
+// the dirs of root, e.g. execution and modules, sorted by file and line.
+// Hidden directories, such as .terraform, and testdata are skipped.
+func Load(root string, dirs ...string) ([]Rule, error) {
+	var rules []Rule
+	for _, dir := range dirs {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); p != filepath.Join(root, dir) && (strings.HasPrefix(name, ".") || name == "testdata") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Name() != "variables.tf" {
+				return nil
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			src, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			file, err := parse(filepath.ToSlash(rel), src)
+			rules = append(rules, file...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].File != rules[j].File {
+			return rules[i].File < rules[j].File
+		}
+		return rules[i].Line < rules[j].Line
+	})
+	return rules, nil
+}
+
+// parse returns the validation rules of a variables.tf file, file being its
+// path relative to the repository root.
+func parse(file string, src []byte) ([]Rule, error) {
+	f, diags := hclparse.NewParser().ParseHCL(src, file)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	content, _, diags := f.Body.PartialContent(variablesSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var rules []Rule
+	for _, variable := range content.Blocks {
+		body, _, _ := variable.Body.PartialContent(variableSchema)
+		for _, block := range body.Blocks {
+			attrs, _, _ := block.Body.PartialContent(ruleSchema)
+			r := Rule{
+				Dir:      path.Dir(file),
+				File:     file,
+				Line:     block.DefRange.Start.Line,
+				Variable: variable.Labels[0],
+			}
+			if a, ok := attrs.Attributes["condition"]; ok {
+				r.Condition = source(src, a.Expr.Range())
+			}
+			if a, ok := attrs.Attributes["error_message"]; ok {
+				r.ErrorMessage = source(src, a.Expr.Range())
+				r.message = literals(a.Expr)
+			}
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
+
+func source(src []byte, r hcl.Range) string {
+	return strings.TrimSpace(string(r.SliceBytes(src)))
+}
+
+// literals returns the literal parts of a string template, each with its
+// whitespace collapsed, leaving out interpolations.
+func literals(expr hcl.Expression) []string {
+	var parts []string
+	add := func(e hclsyntax.Expression) {
+		lit, ok := e.(*hclsyntax.LiteralValueExpr)
+		if !ok || !lit.Val.Type().Equals(cty.String) || lit.Val.IsNull() {
+			return
+		}
+		if s := collapse(lit.Val.AsString()); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	switch e := expr.(type) {
+	case *hclsyntax.TemplateExpr:
+		for _, p := range e.Parts {
+			add(p)
+		}
+	case hclsyntax.Expression:
+		add(e)
+	}
+	return parts
+}
+
+// collapse trims s and replaces its runs of white space with one space, as
+// diagnostics wrap long messages.
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func load(t *testing.T) []Rule {
+	t.Helper()
+	rules, err := Load("testdata", "execution", "modules")
+	if err != nil {
+		t.Fatalf("Failed to load rules: %v", err)
+	}
+	return rules
+}
+
+func parseLog(t *testing.T) []Diagnostic {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", "unit.log"))
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	defer f.Close()
+	diags, err := ParseDiagnostics(f)
+	if err != nil {
+		t.Fatalf("Failed to parse log: %v", err)
+	}
+	return diags
+}
+
+func TestLoad(t *testing.T) {
+	rules := load(t)
+	type rule struct {
+		Dir, File string
+		Line      int
+		Variable  string
+		Condition string
+	}
+	var got []rule
+	for _, r := range rules {
+		got = append(got, rule{r.Dir, r.File, r.Line, r.Variable, r.Condition})
+	}
+	want := []rule{
+		{"execution/01-network", "execution/01-network/variables.tf", 7, "region", `can(regex("^[a-z]+-[a-z]+[0-9]$", var.region))`},
+		{"execution/01-network", "execution/01-network/variables.tf", 16, "routing_mode", `contains(["GLOBAL", "REGIONAL"], var.routing_mode)`},
+		{"modules/subnet", "modules/subnet/variables.tf", 3, "ip_cidr_range", "can(cidrhost(var.ip_cidr_range, 0))"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to load rules:\ngot  %+v\nwant %+v", got, want)
+	}
+	if want := []string{"The routing mode must be GLOBAL or REGIONAL, not", "."}; !reflect.DeepEqual(rules[1].message, want) {
+		t.Errorf("Failed to read the message literals: got %q, want %q", rules[1].message, want)
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	got := parseLog(t)
+	want := []Diagnostic{
+		{
+			Summary:  InvalidValue,
+			Detail:   `on main.tf line 3, in module "subnet": 3: ip_cidr_range = "10.0.0.0/33" The IP CIDR range must be a valid IPv4 CIDR range. This was checked by the validation rule at ../../modules/subnet/variables.tf:3,3-13.`,
+			RuleFile: "../../modules/subnet/variables.tf",
+			RuleLine: 3,
+			Test:     "TestPlanInvalidRegion",
+			Stage:    "/src/repo/execution/01-network",
+		},
+		{
+			Summary: InvalidValue,
+			Detail:  `on variables.tf line 13: 13: variable "routing_mode" { The routing mode must be GLOBAL or REGIONAL, not STATIC.`,
+			Test:    "TestPlanInvalidRoutingMode",
+		},
+		{
+			Summary: "No value for required variable",
+			Detail:  `The root module input variable "project_id" is not set.`,
+			Test:    "TestPlanMissingProject",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed to parse diagnostics:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		diags []Diagnostic
+		want  map[string]int
+	}{
+		{
+			name:  "log",
+			diags: nil,
+			want:  map[string]int{"region": 0, "routing_mode": 1, "ip_cidr_range": 1},
+		},
+		{
+			name:  "location without message",
+			diags: []Diagnostic{{Summary: InvalidValue, RuleFile: "./variables.tf", RuleLine: 7}},
+			want:  map[string]int{"region": 1, "routing_mode": 0, "ip_cidr_range": 0},
+		},
+		{
+			name:  "location resolved against the stage",
+			diags: []Diagnostic{{Summary: InvalidValue, RuleFile: "../../modules/subnet/variables.tf", RuleLine: 3, Stage: "/src/repo/execution/01-network"}},
+			want:  map[string]int{"region": 0, "routing_mode": 0, "ip_cidr_range": 1},
+		},
+		{
+			name:  "location outside the stage",
+			diags: []Diagnostic{{Summary: InvalidValue, RuleFile: "../modules/subnet/variables.tf", RuleLine: 3, Stage: "/src/repo/execution/01-network"}},
+			want:  map[string]int{"region": 0, "routing_mode": 0, "ip_cidr_range": 0},
+		},
+		{
+			name:  "location of another file",
+			diags: []Diagnostic{{Summary: InvalidValue, RuleFile: "../../modules/other/variables.tf", RuleLine: 3}},
+			want:  map[string]int{"region": 0, "routing_mode": 0, "ip_cidr_range": 0},
+		},
+		{
+			name:  "other summary",
+			diags: []Diagnostic{{Summary: "Unsupported argument", Detail: "The IP CIDR range must be a valid IPv4 CIDR range."}},
+			want:  map[string]int{"region": 0, "routing_mode": 0, "ip_cidr_range": 0},
+		},
+		{
+			name:  "message parts out of order",
+			diags: []Diagnostic{{Summary: InvalidValue, Detail: ". The routing mode must be GLOBAL or REGIONAL, not"}},
+			want:  map[string]int{"region": 0, "routing_mode": 0, "ip_cidr_range": 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := load(t)
+			diags := tt.diags
+			if diags == nil {
+				diags = parseLog(t)
+			}
+			Match(rules, diags)
+			got := map[string]int{}
+			for _, r := range rules {
+				got[r.Variable] = r.Hits
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Failed to match diagnostics: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchSameLine(t *testing.T) {
+	rule := func(dir, message string) Rule {
+		return Rule{Dir: dir, File: dir + "/variables.tf", Line: 12, Variable: "region", message: []string{message}}
+	}
+	tests := []struct {
+		name string
+		diag Diagnostic
+		want []int
+	}{
+		{
+			name: "location in the stage",
+			diag: Diagnostic{Summary: InvalidValue, RuleFile: "variables.tf", RuleLine: 12, Stage: "/src/repo/execution/04-producer/CloudSQL"},
+			want: []int{0, 1},
+		},
+		{
+			name: "location without stage",
+			diag: Diagnostic{Summary: InvalidValue, RuleFile: "variables.tf", RuleLine: 12},
+			want: []int{0, 0},
+		},
+		{
+			name: "location told apart by message",
+			diag: Diagnostic{Summary: InvalidValue, Detail: "The Cloud SQL region is invalid.", RuleFile: "variables.tf", RuleLine: 12},
+			want: []int{0, 1},
+		},
+		{
+			name: "message without stage",
+			diag: Diagnostic{Summary: InvalidValue, Detail: "The AlloyDB region is invalid."},
+			want: []int{0, 0},
+		},
+		{
+			name: "message of the stage",
+			diag: Diagnostic{Summary: InvalidValue, Detail: "The AlloyDB region is invalid.", Stage: "/src/repo/execution/04-producer/AlloyDB"},
+			want: []int{1, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []Rule{
+				rule("execution/04-producer/AlloyDB", "The AlloyDB region is invalid."),
+				rule("execution/04-producer/CloudSQL", "region is invalid."),
+			}
+			Match(rules, []Diagnostic{tt.diag})
+			if got := []int{rules[0].Hits, rules[1].Hits}; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Failed to match the diagnostic: got hits %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	rules := load(t)
+	Match(rules, parseLog(t))
+	r := NewReport(rules)
+
+	want := []Summary{
+		{Dir: "execution/01-network", Rules: 2, Covered: 1, Coverage: 50},
+		{Dir: "modules/subnet", Rules: 1, Covered: 1, Coverage: 100},
+	}
+	if !reflect.DeepEqual(r.Dirs, want) {
+		t.Errorf("Failed to summarize directories: got %+v, want %+v", r.Dirs, want)
+	}
+	if r.Total.Rules != 3 || r.Total.Covered != 2 {
+		t.Errorf("Failed to summarize the total: got %+v", r.Total)
+	}
+
+	tests := []struct {
+		min, minDir float64
+		want        int
+	}{
+		{0, 0, 0},
+		{60, 50, 0},
+		{70, 0, 1},
+		{0, 60, 1},
+		{70, 60, 2},
+	}
+	for _, tt := range tests {
+		if got := r.Check(tt.min, tt.minDir); len(got) != tt.want {
+			t.Errorf("Failed to check -min %v -min-dir %v: got %q, want %d failures", tt.min, tt.minDir, got, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf, true); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	for _, s := range []string{
+		"execution/01-network  2      1        50.0%",
+		"TOTAL                 3      2        66.7%",
+		`execution/01-network/variables.tf:7: variable region: "The region must be a Google Cloud region, e.g. us-central1."`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("Failed to find %q in report:\n%s", s, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "routing_mode:") {
+		t.Errorf("Failed to leave covered rules out of the report:\n%s", buf.String())
+	}
+}
+
+func TestEmptyReport(t *testing.T) {
+	r := NewReport(nil)
+	if r.Total.Coverage != 100 || len(r.Check(100, 100)) != 0 {
+		t.Errorf("Failed to report full coverage without rules: got %+v", r.Total)
+	}
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"rules": []`) {
+		t.Errorf("Failed to write empty lists:\n%s", buf.String())
+	}
+}